@@ -23,6 +23,8 @@
           export NEO4J_HOME=$PWD/.neo4j
           mkdir -p $NEO4J_HOME/data
           mkdir -p $NEO4J_HOME/logs
+          export GRPC_REFLECTION=true
+          export NEO4J_PASSWORD=''${NEO4J_PASSWORD:-helloworld}
           echo "Neo4j home: $NEO4J_HOME"
         '';
       };
@@ -0,0 +1,150 @@
+// Package graphclient is the Go client for the graph service. It wraps the
+// generated GraphService stubs with authentication, retries, typed errors
+// and pagination iterators:
+//
+//	client, err := graphclient.New("graph-service:50051",
+//		graphclient.WithAPIKey(key),
+//		graphclient.WithDefaultTenant("acme"),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close()
+//
+//	product, err := client.GetProduct(ctx, &pb.GetProductRequest{Id: id})
+//	if errors.Is(err, graphclient.ErrNotFound) {
+//		...
+//	}
+package graphclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Request headers the service reads.
+const (
+	apiKeyHeader   = "x-api-key"
+	tenantHeader   = "x-tenant-id"
+	bookmarkHeader = "neo4j-bookmark"
+)
+
+// Client calls the graph service. Every GraphService RPC is available on
+// it; errors the service returns are *Error.
+type Client struct {
+	pb.GraphServiceClient
+	conn *grpc.ClientConn
+}
+
+type config struct {
+	tls         *tls.Config
+	apiKey      string
+	tenant      string
+	retries     int
+	backoff     time.Duration
+	dialOptions []grpc.DialOption
+}
+
+// Option configures a Client.
+type Option func(*config)
+
+// WithTLS connects over TLS with cfg. Without it the connection is
+// plaintext, as the service serves it by default.
+func WithTLS(cfg *tls.Config) Option {
+	return func(c *config) {
+		c.tls = cfg
+	}
+}
+
+// WithAPIKey authenticates every call with key.
+func WithAPIKey(key string) Option {
+	return func(c *config) {
+		c.apiKey = key
+	}
+}
+
+// WithDefaultTenant acts on tenant in calls whose context doesn't name
+// one with WithTenant.
+func WithDefaultTenant(tenant string) Option {
+	return func(c *config) {
+		c.tenant = tenant
+	}
+}
+
+// WithRetries retries calls the service reports UNAVAILABLE, e.g. while
+// its database is unreachable, up to attempts times in all, backing off
+// from backoff. A write whose commit the database failed to acknowledge
+// can apply twice. The default is 3 attempts from 100ms; 1 disables
+// retries.
+func WithRetries(attempts int, backoff time.Duration) Option {
+	return func(c *config) {
+		c.retries = attempts
+		c.backoff = backoff
+	}
+}
+
+// WithDialOptions adds gRPC dial options, e.g. a stats handler.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(c *config) {
+		c.dialOptions = append(c.dialOptions, opts...)
+	}
+}
+
+// New returns a client for the service at target, e.g. "localhost:50051".
+// It connects lazily, on the first call.
+func New(target string, opts ...Option) (*Client, error) {
+	cfg := config{retries: 3, backoff: 100 * time.Millisecond}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.retries < 1 || cfg.backoff <= 0 {
+		return nil, fmt.Errorf("graphclient: retries need at least 1 attempt and a positive backoff")
+	}
+
+	creds := insecure.NewCredentials()
+	if cfg.tls != nil {
+		creds = credentials.NewTLS(cfg.tls)
+	}
+	dialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(metadataInterceptor(cfg.apiKey, cfg.tenant), errorInterceptor),
+	}
+	if cfg.retries > 1 {
+		dialOptions = append(dialOptions, grpc.WithDefaultServiceConfig(retryPolicy(cfg.retries, cfg.backoff)))
+	}
+
+	conn, err := grpc.NewClient(target, append(dialOptions, cfg.dialOptions...)...)
+	if err != nil {
+		return nil, fmt.Errorf("graphclient: %w", err)
+	}
+	return &Client{
+		GraphServiceClient: pb.NewGraphServiceClient(conn),
+		conn:               conn,
+	}, nil
+}
+
+// Close closes the connection. Calls in flight fail.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// retryPolicy is the service config retrying UNAVAILABLE calls. gRPC caps
+// attempts at 5.
+func retryPolicy(attempts int, backoff time.Duration) string {
+	return fmt.Sprintf(`{"methodConfig": [{
+		"name": [{"service": %q}],
+		"retryPolicy": {
+			"maxAttempts": %d,
+			"initialBackoff": "%.3fs",
+			"maxBackoff": "%.3fs",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]}`, pb.GraphService_ServiceDesc.ServiceName, attempts, backoff.Seconds(), 10*backoff.Seconds())
+}
@@ -0,0 +1,82 @@
+package graphclient
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type tenantKey struct{}
+
+// WithTenant makes calls with ctx act on tenant, overriding the client's.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+type bookmarksKey struct{}
+
+// Bookmarks gives the calls made with its context read-your-writes
+// consistency: each sees what the calls before it wrote, even on another
+// database cluster member. It is safe for concurrent use.
+type Bookmarks struct {
+	mu     sync.Mutex
+	values []string
+}
+
+// WithBookmarks makes calls with ctx send and update b.
+func WithBookmarks(ctx context.Context, b *Bookmarks) context.Context {
+	return context.WithValue(ctx, bookmarksKey{}, b)
+}
+
+// Values returns the bookmarks of the latest write, e.g. to pass to
+// another process.
+func (b *Bookmarks) Values() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.values...)
+}
+
+// Add merges bookmarks received elsewhere, e.g. from Values.
+func (b *Bookmarks) Add(values ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.values = append(b.values, values...)
+}
+
+func (b *Bookmarks) set(values []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.values = values
+}
+
+// metadataInterceptor adds the API key, tenant and bookmarks to outgoing
+// calls and records the bookmarks they return.
+func metadataInterceptor(apiKey, tenant string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if apiKey != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, apiKeyHeader, apiKey)
+		}
+		if t, ok := ctx.Value(tenantKey{}).(string); ok {
+			tenant = t
+		}
+		if tenant != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, tenantHeader, tenant)
+		}
+
+		bookmarks, _ := ctx.Value(bookmarksKey{}).(*Bookmarks)
+		if bookmarks == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		for _, value := range bookmarks.Values() {
+			ctx = metadata.AppendToOutgoingContext(ctx, bookmarkHeader, value)
+		}
+		var header metadata.MD
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Header(&header))...)
+		if values := header.Get(bookmarkHeader); len(values) > 0 {
+			bookmarks.set(values)
+		}
+		return err
+	}
+}
@@ -0,0 +1,105 @@
+package graphclient
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error is an error the service returned. Match its reason with errors.Is
+// and the sentinels below; status.FromError still works on it.
+type Error struct {
+	Code    codes.Code
+	Message string
+	// Reason is the service's ErrorInfo reason, e.g. "NOT_FOUND"; empty
+	// for errors raised by gRPC itself, such as a missing API key.
+	Reason string
+	// Metadata details the reason, e.g. the sku and the product_id that
+	// owns it for ALREADY_EXISTS.
+	Metadata map[string]string
+	// Violations lists the invalid request fields for INVALID_ARGUMENT
+	// and PAYLOAD_TOO_LARGE.
+	Violations []FieldViolation
+
+	status *status.Status
+}
+
+// FieldViolation is one invalid request field. Field is its proto path,
+// e.g. "product.name".
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+func (e *Error) Error() string {
+	if e.Reason == "" {
+		return "graph service: " + e.Code.String() + ": " + e.Message
+	}
+	return "graph service: " + e.Reason + ": " + e.Message
+}
+
+// Is matches sentinels with the same reason.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.status == nil && t.Reason != "" && t.Reason == e.Reason
+}
+
+// GRPCStatus returns the status the error came from.
+func (e *Error) GRPCStatus() *status.Status {
+	return e.status
+}
+
+// Sentinels for the service's ErrorInfo reasons.
+var (
+	ErrInvalidArgument     = &Error{Reason: "INVALID_ARGUMENT"}
+	ErrNotFound            = &Error{Reason: "NOT_FOUND"}
+	ErrAlreadyExists       = &Error{Reason: "ALREADY_EXISTS"}
+	ErrInsufficientStock   = &Error{Reason: "INSUFFICIENT_STOCK"}
+	ErrInsufficientBalance = &Error{Reason: "INSUFFICIENT_BALANCE"}
+	ErrReservationClosed   = &Error{Reason: "RESERVATION_CLOSED"}
+	ErrPaymentDeclined     = &Error{Reason: "PAYMENT_DECLINED"}
+	ErrOrderDeclined       = &Error{Reason: "ORDER_DECLINED"}
+	ErrCouponNotApplicable = &Error{Reason: "COUPON_NOT_APPLICABLE"}
+	ErrInvalidTransition   = &Error{Reason: "INVALID_TRANSITION"}
+	ErrPayloadTooLarge     = &Error{Reason: "PAYLOAD_TOO_LARGE"}
+	ErrDatabaseUnavailable = &Error{Reason: "DATABASE_UNAVAILABLE"}
+	ErrCircuitOpen         = &Error{Reason: "CIRCUIT_OPEN"}
+	ErrUnsupported         = &Error{Reason: "UNSUPPORTED_BY_BACKEND"}
+	ErrTenantQuota         = &Error{Reason: "TENANT_QUOTA_EXCEEDED"}
+	ErrStreamInterrupted   = &Error{Reason: "STREAM_INTERRUPTED"}
+	ErrNotConfigured       = &Error{Reason: "NOT_CONFIGURED"}
+)
+
+// errorInterceptor turns status errors into *Error. Context errors pass
+// through, so errors.Is(err, context.DeadlineExceeded) keeps working.
+func errorInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return fromStatus(st)
+}
+
+func fromStatus(st *status.Status) *Error {
+	e := &Error{Code: st.Code(), Message: st.Message(), status: st}
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			e.Reason = d.Reason
+			e.Metadata = d.Metadata
+		case *errdetails.BadRequest:
+			for _, v := range d.FieldViolations {
+				e.Violations = append(e.Violations, FieldViolation{Field: v.Field, Description: v.Description})
+			}
+		}
+	}
+	return e
+}
@@ -0,0 +1,84 @@
+package graphclient
+
+import (
+	"context"
+	"iter"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"google.golang.org/protobuf/proto"
+)
+
+// pages yields the items of every page from the one token names on. It
+// stops at the first error, which it yields with a nil item.
+func pages[T any](ctx context.Context, token string, fetch func(ctx context.Context, token string) ([]T, string, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			items, next, err := fetch(ctx, token)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if next == "" {
+				return
+			}
+			token = next
+		}
+	}
+}
+
+// AllProducts iterates over ListProducts from req's page on.
+func (c *Client) AllProducts(ctx context.Context, req *pb.ListProductsRequest) iter.Seq2[*pb.Product, error] {
+	return pages(ctx, req.GetPageToken(), func(ctx context.Context, token string) ([]*pb.Product, string, error) {
+		page := proto.CloneOf(req)
+		page.PageToken = token
+		resp, err := c.ListProducts(ctx, page)
+		return resp.GetProducts(), resp.GetNextPageToken(), err
+	})
+}
+
+// AllProductsUpdatedSince iterates over ListProductsUpdatedSince from
+// req's page on.
+func (c *Client) AllProductsUpdatedSince(ctx context.Context, req *pb.ListProductsUpdatedSinceRequest) iter.Seq2[*pb.Product, error] {
+	return pages(ctx, req.GetPageToken(), func(ctx context.Context, token string) ([]*pb.Product, string, error) {
+		page := proto.CloneOf(req)
+		page.PageToken = token
+		resp, err := c.ListProductsUpdatedSince(ctx, page)
+		return resp.GetProducts(), resp.GetNextPageToken(), err
+	})
+}
+
+// AllNewArrivals iterates over GetNewArrivals from req's page on.
+func (c *Client) AllNewArrivals(ctx context.Context, req *pb.GetNewArrivalsRequest) iter.Seq2[*pb.Product, error] {
+	return pages(ctx, req.GetPageToken(), func(ctx context.Context, token string) ([]*pb.Product, string, error) {
+		page := proto.CloneOf(req)
+		page.PageToken = token
+		resp, err := c.GetNewArrivals(ctx, page)
+		return resp.GetProducts(), resp.GetNextPageToken(), err
+	})
+}
+
+// AllDeals iterates over GetDeals from req's page on.
+func (c *Client) AllDeals(ctx context.Context, req *pb.GetDealsRequest) iter.Seq2[*pb.Deal, error] {
+	return pages(ctx, req.GetPageToken(), func(ctx context.Context, token string) ([]*pb.Deal, string, error) {
+		page := proto.CloneOf(req)
+		page.PageToken = token
+		resp, err := c.GetDeals(ctx, page)
+		return resp.GetDeals(), resp.GetNextPageToken(), err
+	})
+}
+
+// AllDeliveries iterates over ListDeliveries from req's page on.
+func (c *Client) AllDeliveries(ctx context.Context, req *pb.ListDeliveriesRequest) iter.Seq2[*pb.WebhookDelivery, error] {
+	return pages(ctx, req.GetPageToken(), func(ctx context.Context, token string) ([]*pb.WebhookDelivery, string, error) {
+		page := proto.CloneOf(req)
+		page.PageToken = token
+		resp, err := c.ListDeliveries(ctx, page)
+		return resp.GetDeliveries(), resp.GetNextPageToken(), err
+	})
+}
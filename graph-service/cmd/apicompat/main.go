@@ -0,0 +1,41 @@
+// Command apicompat checks the API against its stored baseline, printing
+// every incompatible change, and exits 1 if there are any. With -update
+// it writes the current API as the new baseline instead, for after a
+// release or a deliberate break.
+//
+//	go run ./cmd/apicompat
+//	go run ./cmd/apicompat -update
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/apicompat"
+)
+
+func main() {
+	update := flag.Bool("update", false, "write the current API as the baseline")
+	path := flag.String("baseline", "internal/apicompat/baseline.binpb", "the baseline file, for -update")
+	flag.Parse()
+
+	if *update {
+		data, err := apicompat.Marshal(apicompat.Current())
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*path, data, 0o644); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("wrote %s; rebuild to embed it\n", *path)
+		return
+	}
+
+	if err := apicompat.Verify(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println("the API is compatible with its baseline")
+}
@@ -0,0 +1,52 @@
+// Command catalogsync runs one sync of the configured PIM source now,
+// configured through the same environment as the server. A full sync
+// reads every product the source has, for a first import or to repair a
+// catalog that drifted; otherwise only the changes since the last
+// successful run are read. If a replica is syncing the source, it exits
+// without syncing.
+//
+//	go run ./cmd/catalogsync       # sync the changes since the last run
+//	go run ./cmd/catalogsync -full # sync every product
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/catalogsync"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/config"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/graphdb"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+func main() {
+	full := flag.Bool("full", false, "sync every product rather than the changes since the last run")
+	flag.Parse()
+
+	cfg := config.Load()
+	ctx := context.Background()
+
+	driver, opts, err := graphdb.Open(graphdb.SettingsFrom(cfg))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer driver.Close(ctx)
+	repo := repository.NewProductRepository(driver, opts...)
+
+	syncer, err := catalogsync.FromConfig(cfg, repo)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if syncer == nil {
+		log.Fatal("SYNC_SOURCE is not set")
+	}
+
+	sync := syncer.SyncOnce
+	if *full {
+		sync = syncer.SyncAll
+	}
+	if err := sync(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
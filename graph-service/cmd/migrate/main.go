@@ -0,0 +1,56 @@
+// Command migrate applies pending graph data migrations and brings the
+// search indexes in line with SEARCH_*, configured through the same
+// environment as the server. Migrations are safe to run while the server
+// is up.
+//
+//	go run ./cmd/migrate          # apply pending migrations
+//	go run ./cmd/migrate -dry-run # only list them
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/config"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/graphdb"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "list pending migrations without applying them")
+	flag.Parse()
+
+	cfg := config.Load()
+	ctx := context.Background()
+
+	driver, opts, err := graphdb.Open(graphdb.SettingsFrom(cfg))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer driver.Close(ctx)
+	repo := repository.NewProductRepository(driver, opts...)
+
+	if *dryRun {
+		pending, err := repo.PendingMigrations(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, id := range pending {
+			fmt.Printf("pending  %s\n", id)
+		}
+		return
+	}
+
+	applied, err := repo.Migrate(ctx)
+	for _, id := range applied {
+		fmt.Printf("applied  %s\n", id)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(applied) == 0 {
+		fmt.Println("nothing to migrate")
+	}
+}
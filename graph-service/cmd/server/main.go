@@ -1,46 +1,46 @@
 package main
 
 import (
+	"context"
 	"log"
+	"log/slog"
 	"net"
+	"os"
+	"os/signal"
+	"syscall"
 
-	pb "github.com/navi-prem/ecom-tts/graph-service/api"
-	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
-	"github.com/navi-prem/ecom-tts/graph-service/internal/service"
-
-	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/reflection"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/redact"
+	"github.com/navi-prem/ecom-tts/graph-service/server"
 )
 
 func main() {
-	uri := "bolt://localhost:7687"
-	username := "neo4j"
-	password := "helloworld"
+	// Route logging, the log package's included, through redaction
+	slog.SetDefault(slog.New(redact.NewHandler(slog.NewTextHandler(os.Stderr, nil))))
+
+	cfg := server.LoadConfig()
 
-	driver, err := neo4j.NewDriverWithContext(uri, neo4j.BasicAuth(username, password, ""))
+	srv, err := server.New(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer driver.Close(nil)
-
-	repo := repository.NewProductRepository(driver)
-	productService := service.NewProductService(repo)
+	defer srv.Stop()
 
-	lis, err := net.Listen("tcp", ":50051")
+	lis, err := net.Listen("tcp", cfg.ListenAddr)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	grpcServer := grpc.NewServer()
-
-	pb.RegisterGraphServiceServer(grpcServer, productService)
-
-	// Enable gRPC reflection for grpcurl
-	reflection.Register(grpcServer)
-
-	log.Println("Graph Service running on :50051")
-	if err := grpcServer.Serve(lis); err != nil {
+	// Finish the RPCs in flight on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		log.Printf("Shutting down")
+		srv.Stop()
+	}()
+
+	log.Printf("Graph Service running on %s", cfg.ListenAddr)
+	if err := srv.Serve(lis); err != nil {
 		log.Fatal(err)
 	}
 }
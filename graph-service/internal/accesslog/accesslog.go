@@ -0,0 +1,126 @@
+// Package accesslog records one structured entry per RPC, for incident
+// forensics, apart from the service's debug logging. A sample of entries
+// also capture the request, with personal data scrubbed as redact does
+// for logs.
+package accesslog
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"math/rand/v2"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/metrics"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/redact"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const defaultMaxPayload = 64 << 10
+
+// Entry is the record of one RPC.
+type Entry struct {
+	Time          time.Time `json:"time"`
+	Method        string    `json:"method"`
+	Principal     string    `json:"principal,omitempty"`
+	Tenant        string    `json:"tenant,omitempty"`
+	Peer          string    `json:"peer,omitempty"`
+	TraceID       string    `json:"trace_id,omitempty"`
+	Code          string    `json:"code"`
+	Error         string    `json:"error,omitempty"`
+	DurationMS    float64   `json:"duration_ms"`
+	RequestBytes  int       `json:"request_bytes"`
+	ResponseBytes int       `json:"response_bytes"`
+	// Request is the redacted request as protojson, on captured entries
+	// whose request fits in the logger's MaxPayload.
+	Request json.RawMessage `json:"request,omitempty"`
+	// RequestTooLarge marks captured entries whose request didn't fit.
+	RequestTooLarge bool `json:"request_too_large,omitempty"`
+}
+
+// Sink stores entries. Write is called from the RPC's goroutine, so it
+// must be safe for concurrent use and shouldn't block for long.
+type Sink interface {
+	Write(e Entry) error
+}
+
+// SinkFunc adapts a function to the Sink interface.
+type SinkFunc func(e Entry) error
+
+func (f SinkFunc) Write(e Entry) error {
+	return f(e)
+}
+
+// Logger decides which entries capture their request and hands every
+// entry to its sink.
+type Logger struct {
+	sink  Sink
+	stats *expvar.Map
+
+	// SampleRate is the fraction of calls, from 0 to 1, whose request is
+	// captured. Calls failing with a server fault, Unknown or Internal,
+	// capture theirs whatever the rate.
+	SampleRate float64
+	// MaxPayload is the most bytes of a captured request kept.
+	MaxPayload int
+}
+
+func NewLogger(sink Sink, sampleRate float64) *Logger {
+	l := &Logger{
+		sink:       sink,
+		stats:      metrics.Map("access_log"),
+		SampleRate: sampleRate,
+		MaxPayload: defaultMaxPayload,
+	}
+	l.stats.Add("entries", 0)
+	l.stats.Add("captured", 0)
+	l.stats.Add("write_errors", 0)
+	return l
+}
+
+// Sampled reports whether a call's request should be captured, before
+// knowing how it ends.
+func (l *Logger) Sampled() bool {
+	return l.SampleRate > 0 && rand.Float64() < l.SampleRate
+}
+
+// Capture sets e.Request to req, redacted. Personal data goes the way
+// redact.Message and redact.String take it out of logs.
+func (l *Logger) Capture(e *Entry, req proto.Message) {
+	payload, err := protojson.Marshal(redact.Message(req))
+	if err != nil {
+		return
+	}
+	if len(payload) > l.MaxPayload {
+		e.RequestTooLarge = true
+		return
+	}
+	e.Request = json.RawMessage(redact.String(string(payload)))
+	l.stats.Add("captured", 1)
+}
+
+// Log hands e to the sink. Entries the sink fails to store are counted and
+// dropped; the call they record has already finished.
+func (l *Logger) Log(e Entry) {
+	l.stats.Add("entries", 1)
+	if err := l.sink.Write(e); err != nil {
+		l.stats.Add("write_errors", 1)
+	}
+}
+
+type entryKey struct{}
+
+// WithEntry returns ctx carrying e, for interceptors further down the
+// chain to annotate with SetPrincipal.
+func WithEntry(ctx context.Context, e *Entry) context.Context {
+	return context.WithValue(ctx, entryKey{}, e)
+}
+
+// SetPrincipal records who made the call on the entry ctx carries, if it
+// carries one.
+func SetPrincipal(ctx context.Context, name string) {
+	if e, ok := ctx.Value(entryKey{}).(*Entry); ok {
+		e.Principal = name
+	}
+}
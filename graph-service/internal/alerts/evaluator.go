@@ -0,0 +1,141 @@
+// Package alerts notifies users when new products match their saved
+// searches and when prices they watch drop, and raises low-stock events
+// for merchandisers.
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/notify"
+)
+
+const (
+	defaultInterval   = 5 * time.Minute
+	defaultBatchSize  = 100
+	defaultMaxMatches = 20
+)
+
+// Store holds the saved searches the evaluator runs.
+type Store interface {
+	ListSavedSearchesAfter(ctx context.Context, afterID string, limit int) ([]*pb.SavedSearch, error)
+	MatchNewProducts(ctx context.Context, ss *pb.SavedSearch, until int64, limit int) ([]*pb.Product, error)
+	MarkSavedSearchEvaluated(ctx context.Context, id string, at int64) error
+}
+
+// Match is the Data of a saved_search.matched notification.
+type Match struct {
+	SavedSearchID string   `json:"saved_search_id"`
+	ProductIDs    []string `json:"product_ids"`
+	// More products matched than are listed.
+	Truncated bool `json:"truncated"`
+}
+
+// Evaluator periodically runs every saved search against products created
+// since its last run and notifies the owner of any matches. A search is only
+// marked evaluated once its notification is accepted, so failures are
+// retried on the next pass.
+type Evaluator struct {
+	store    Store
+	notifier notify.Notifier
+
+	Interval   time.Duration
+	BatchSize  int
+	MaxMatches int
+}
+
+func NewEvaluator(store Store, notifier notify.Notifier) *Evaluator {
+	return &Evaluator{
+		store:      store,
+		notifier:   notifier,
+		Interval:   defaultInterval,
+		BatchSize:  defaultBatchSize,
+		MaxMatches: defaultMaxMatches,
+	}
+}
+
+// Run evaluates saved searches until ctx is cancelled.
+func (e *Evaluator) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.Interval)
+	defer ticker.Stop()
+
+	for {
+		e.evaluateAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Evaluator) evaluateAll(ctx context.Context) {
+	afterID := ""
+	for ctx.Err() == nil {
+		batch, err := e.store.ListSavedSearchesAfter(ctx, afterID, e.BatchSize)
+		if err != nil {
+			log.Printf("alerts: list saved searches: %v", err)
+			return
+		}
+
+		for _, ss := range batch {
+			if err := e.evaluate(ctx, ss); err != nil {
+				log.Printf("alerts: saved search %s: %v", ss.Id, err)
+			}
+		}
+
+		if len(batch) < e.BatchSize {
+			return
+		}
+		afterID = batch[len(batch)-1].Id
+	}
+}
+
+func (e *Evaluator) evaluate(ctx context.Context, ss *pb.SavedSearch) error {
+	until := time.Now().UnixMilli()
+
+	// Fetch one extra to know whether the list is truncated
+	products, err := e.store.MatchNewProducts(ctx, ss, until, e.MaxMatches+1)
+	if err != nil {
+		return err
+	}
+
+	if len(products) > 0 {
+		match := Match{SavedSearchID: ss.Id}
+		if len(products) > e.MaxMatches {
+			products = products[:e.MaxMatches]
+			match.Truncated = true
+		}
+		for _, p := range products {
+			match.ProductIDs = append(match.ProductIDs, p.Id)
+		}
+
+		err := e.notifier.Notify(ctx, notify.Notification{
+			UserID:  ss.UserId,
+			Kind:    events.SavedSearchMatched,
+			Subject: subject(ss, len(products), match.Truncated),
+			Data:    match,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return e.store.MarkSavedSearchEvaluated(ctx, ss.Id, until)
+}
+
+func subject(ss *pb.SavedSearch, n int, truncated bool) string {
+	more := ""
+	if truncated {
+		more = "+"
+	}
+	if n == 1 && !truncated {
+		return fmt.Sprintf("1 new product matches %q", ss.Name)
+	}
+	return fmt.Sprintf("%d%s new products match %q", n, more, ss.Name)
+}
@@ -0,0 +1,88 @@
+package alerts
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+const (
+	defaultLowStockInterval  = 15 * time.Minute
+	defaultLowStockThreshold = 5
+	defaultLowStockHorizon   = 14 * 24 * time.Hour
+)
+
+// LowStockStore holds the sizes whose stock the watcher checks.
+type LowStockStore interface {
+	ListStockProjections(ctx context.Context, threshold int32, afterSKU string, limit int) ([]repository.StockProjection, error)
+	SetLowStockAlert(ctx context.Context, p repository.StockProjection, low bool, threshold int32) error
+}
+
+// LowStockWatcher periodically raises stock.low, through the event outbox
+// and so to webhooks, for sizes at or below Threshold or whose imported
+// forecast has them selling out within Horizon. The event carries the
+// projected stockout date when there is one. A size is alerted once, and
+// again only after it recovers.
+type LowStockWatcher struct {
+	store LowStockStore
+
+	Threshold int32
+	Horizon   time.Duration
+	Interval  time.Duration
+	BatchSize int
+}
+
+func NewLowStockWatcher(store LowStockStore) *LowStockWatcher {
+	return &LowStockWatcher{
+		store:     store,
+		Threshold: defaultLowStockThreshold,
+		Horizon:   defaultLowStockHorizon,
+		Interval:  defaultLowStockInterval,
+		BatchSize: defaultBatchSize,
+	}
+}
+
+// Run checks stock until ctx is cancelled.
+func (w *LowStockWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.checkAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *LowStockWatcher) checkAll(ctx context.Context) {
+	horizon := time.Now().Add(w.Horizon).UnixMilli()
+	afterSKU := ""
+	for ctx.Err() == nil {
+		batch, err := w.store.ListStockProjections(ctx, w.Threshold, afterSKU, w.BatchSize)
+		if err != nil {
+			log.Printf("alerts: list stock projections: %v", err)
+			return
+		}
+
+		for _, p := range batch {
+			low := p.Stock <= w.Threshold || (p.StockoutAt > 0 && p.StockoutAt <= horizon)
+			if low == p.Alerted {
+				continue
+			}
+			if err := w.store.SetLowStockAlert(ctx, p, low, w.Threshold); err != nil {
+				log.Printf("alerts: low stock on %s: %v", p.SKU, err)
+			}
+		}
+
+		if len(batch) < w.BatchSize {
+			return
+		}
+		afterSKU = batch[len(batch)-1].SKU
+	}
+}
@@ -0,0 +1,107 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/notify"
+)
+
+const defaultPriceDropInterval = time.Minute
+
+// PriceDropStore holds the price drop subscriptions the watcher checks.
+type PriceDropStore interface {
+	PendingPriceDrops(ctx context.Context, limit int) ([]*pb.PriceDropSubscription, error)
+	RecordPriceDrop(ctx context.Context, sub *pb.PriceDropSubscription) error
+}
+
+// PriceDrop is the Data of a price.dropped notification.
+type PriceDrop struct {
+	ProductID string  `json:"product_id"`
+	OldPrice  float64 `json:"old_price"`
+	NewPrice  float64 `json:"new_price"`
+}
+
+// PriceWatcher periodically notifies subscribers whose product costs less
+// than when they were last told, however the price came down. The new
+// price is only recorded once the notification is accepted, so failures
+// are retried on the next pass and a price that dips twice to the same
+// low alerts once.
+type PriceWatcher struct {
+	store    PriceDropStore
+	notifier notify.Notifier
+
+	Interval  time.Duration
+	BatchSize int
+}
+
+func NewPriceWatcher(store PriceDropStore, notifier notify.Notifier) *PriceWatcher {
+	return &PriceWatcher{
+		store:     store,
+		notifier:  notifier,
+		Interval:  defaultPriceDropInterval,
+		BatchSize: defaultBatchSize,
+	}
+}
+
+// Run checks for price drops until ctx is cancelled.
+func (w *PriceWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.checkAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkAll works through the pending drops a batch at a time. Recorded
+// drops leave the pending set, so it stops at a short batch, or at one
+// with failures, which would otherwise come straight back.
+func (w *PriceWatcher) checkAll(ctx context.Context) {
+	for ctx.Err() == nil {
+		batch, err := w.store.PendingPriceDrops(ctx, w.BatchSize)
+		if err != nil {
+			log.Printf("alerts: list price drops: %v", err)
+			return
+		}
+
+		failed := false
+		for _, sub := range batch {
+			if err := w.notify(ctx, sub); err != nil {
+				log.Printf("alerts: price drop on %s for %s: %v", sub.ProductId, sub.UserId, err)
+				failed = true
+			}
+		}
+
+		if failed || len(batch) < w.BatchSize {
+			return
+		}
+	}
+}
+
+func (w *PriceWatcher) notify(ctx context.Context, sub *pb.PriceDropSubscription) error {
+	err := w.notifier.Notify(ctx, notify.Notification{
+		UserID:  sub.UserId,
+		Kind:    events.PriceDropped,
+		Subject: fmt.Sprintf("%s dropped from %.2f to %.2f", sub.ProductName, sub.Price, sub.CurrentPrice),
+		Data: PriceDrop{
+			ProductID: sub.ProductId,
+			OldPrice:  sub.Price,
+			NewPrice:  sub.CurrentPrice,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return w.store.RecordPriceDrop(ctx, sub)
+}
@@ -0,0 +1,263 @@
+// Package apicompat keeps the served API wire compatible with a stored
+// baseline, so a careless edit or regeneration of the protos can't break
+// clients built against an earlier release.
+//
+// The baseline is a FileDescriptorSet of both API versions, embedded from
+// baseline.binpb and refreshed with go run ./cmd/apicompat -update once
+// an API change is released. Additions are always compatible; so are
+// renames, since names aren't on the wire.
+package apicompat
+
+import (
+	_ "embed"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+//go:embed baseline.binpb
+var baseline []byte
+
+// Current is the descriptor set of the API this binary serves.
+func Current() *descriptorpb.FileDescriptorSet {
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range []protoreflect.FileDescriptor{pb.File_graph_proto, pbv2.File_v2_graph_proto} {
+		file := protodesc.ToFileDescriptorProto(fd)
+		file.SourceCodeInfo = nil
+		set.File = append(set.File, file)
+	}
+	return set
+}
+
+// Baseline is the stored descriptor set the API must stay compatible with.
+func Baseline() (*descriptorpb.FileDescriptorSet, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(baseline, set); err != nil {
+		return nil, fmt.Errorf("api baseline: %w", err)
+	}
+	return set, nil
+}
+
+// Verify compares the served API with the baseline; the error lists every
+// incompatible change.
+func Verify() error {
+	base, err := Baseline()
+	if err != nil {
+		return err
+	}
+	if changes := Compare(base, Current()); len(changes) > 0 {
+		return fmt.Errorf("the API is incompatible with its baseline:\n  %s\n"+
+			"restore the fields and RPCs, or run go run ./cmd/apicompat -update if the break is deliberate",
+			strings.Join(changes, "\n  "))
+	}
+	return nil
+}
+
+// RequireCompatible fails tb unless Verify passes, for tests that guard
+// the API.
+func RequireCompatible(tb testing.TB) {
+	tb.Helper()
+	if err := Verify(); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// Compare lists what current changes incompatibly from base: removed
+// messages, enums, services and RPCs; fields and enum values removed
+// without reserving their number; reserved numbers reused; and field
+// types, cardinality and RPC signatures changed.
+func Compare(base, current *descriptorpb.FileDescriptorSet) []string {
+	cur := index(current)
+	var changes []string
+	report := func(format string, args ...any) {
+		changes = append(changes, fmt.Sprintf(format, args...))
+	}
+
+	old := index(base)
+	for _, name := range slices.Sorted(maps.Keys(old.messages)) {
+		was := old.messages[name]
+		now, ok := cur.messages[name]
+		if !ok {
+			report("message %s was removed", name)
+			continue
+		}
+		fields := make(map[int32]*descriptorpb.FieldDescriptorProto)
+		for _, f := range now.Field {
+			fields[f.GetNumber()] = f
+			if reserved(was.ReservedRange, f.GetNumber()) {
+				report("%s field %d (%s) reuses a reserved number", name, f.GetNumber(), f.GetName())
+			}
+		}
+		for _, f := range was.Field {
+			g, ok := fields[f.GetNumber()]
+			if !ok {
+				if !reserved(now.ReservedRange, f.GetNumber()) {
+					report("%s field %d (%s) was removed without reserving its number", name, f.GetNumber(), f.GetName())
+				}
+				continue
+			}
+			if (f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED) != (g.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED) {
+				report("%s field %d (%s) changed between singular and repeated", name, f.GetNumber(), f.GetName())
+			}
+			if !compatibleTypes(f, g) {
+				report("%s field %d (%s) changed type from %s to %s", name, f.GetNumber(), f.GetName(), typeName(f), typeName(g))
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(old.enums)) {
+		was := old.enums[name]
+		now, ok := cur.enums[name]
+		if !ok {
+			report("enum %s was removed", name)
+			continue
+		}
+		numbers := make(map[int32]bool)
+		for _, v := range now.Value {
+			numbers[v.GetNumber()] = true
+		}
+		for _, v := range was.Value {
+			if !numbers[v.GetNumber()] && !reservedEnum(now.ReservedRange, v.GetNumber()) {
+				report("%s value %d (%s) was removed without reserving its number", name, v.GetNumber(), v.GetName())
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(old.services)) {
+		was := old.services[name]
+		now, ok := cur.services[name]
+		if !ok {
+			report("service %s was removed", name)
+			continue
+		}
+		methods := make(map[string]*descriptorpb.MethodDescriptorProto)
+		for _, m := range now.Method {
+			methods[m.GetName()] = m
+		}
+		for _, m := range was.Method {
+			n, ok := methods[m.GetName()]
+			switch {
+			case !ok:
+				report("%s/%s was removed", name, m.GetName())
+			case m.GetInputType() != n.GetInputType() || m.GetOutputType() != n.GetOutputType():
+				report("%s/%s changed from %s -> %s to %s -> %s", name, m.GetName(),
+					m.GetInputType(), m.GetOutputType(), n.GetInputType(), n.GetOutputType())
+			case m.GetClientStreaming() != n.GetClientStreaming() || m.GetServerStreaming() != n.GetServerStreaming():
+				report("%s/%s changed streaming", name, m.GetName())
+			}
+		}
+	}
+	return changes
+}
+
+// Marshal encodes set deterministically, for writing a baseline.
+func Marshal(set *descriptorpb.FileDescriptorSet) ([]byte, error) {
+	return proto.MarshalOptions{Deterministic: true}.Marshal(set)
+}
+
+type descriptors struct {
+	messages map[string]*descriptorpb.DescriptorProto
+	enums    map[string]*descriptorpb.EnumDescriptorProto
+	services map[string]*descriptorpb.ServiceDescriptorProto
+}
+
+// index maps the set's messages, enums and services, nested ones
+// included, by full name.
+func index(set *descriptorpb.FileDescriptorSet) descriptors {
+	d := descriptors{
+		messages: make(map[string]*descriptorpb.DescriptorProto),
+		enums:    make(map[string]*descriptorpb.EnumDescriptorProto),
+		services: make(map[string]*descriptorpb.ServiceDescriptorProto),
+	}
+	var addMessage func(prefix string, m *descriptorpb.DescriptorProto)
+	addMessage = func(prefix string, m *descriptorpb.DescriptorProto) {
+		name := prefix + "." + m.GetName()
+		d.messages[name] = m
+		for _, n := range m.NestedType {
+			addMessage(name, n)
+		}
+		for _, e := range m.EnumType {
+			d.enums[name+"."+e.GetName()] = e
+		}
+	}
+	for _, file := range set.File {
+		for _, m := range file.MessageType {
+			addMessage(file.GetPackage(), m)
+		}
+		for _, e := range file.EnumType {
+			d.enums[file.GetPackage()+"."+e.GetName()] = e
+		}
+		for _, s := range file.Service {
+			d.services[file.GetPackage()+"."+s.GetName()] = s
+		}
+	}
+	return d
+}
+
+// wireClass groups the scalar types that read each other's encoding.
+func wireClass(t descriptorpb.FieldDescriptorProto_Type) string {
+	switch t {
+	case descriptorpb.FieldDescriptorProto_TYPE_INT32, descriptorpb.FieldDescriptorProto_TYPE_INT64,
+		descriptorpb.FieldDescriptorProto_TYPE_UINT32, descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+		descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		return "varint"
+	case descriptorpb.FieldDescriptorProto_TYPE_SINT32, descriptorpb.FieldDescriptorProto_TYPE_SINT64:
+		return "zigzag"
+	case descriptorpb.FieldDescriptorProto_TYPE_FIXED32, descriptorpb.FieldDescriptorProto_TYPE_SFIXED32:
+		return "fixed32"
+	case descriptorpb.FieldDescriptorProto_TYPE_FIXED64, descriptorpb.FieldDescriptorProto_TYPE_SFIXED64:
+		return "fixed64"
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		return "bytes"
+	}
+	return t.String()
+}
+
+// compatibleTypes reports whether values written as f's type read as g's:
+// scalars of one wire class, or the same message or enum type.
+func compatibleTypes(f, g *descriptorpb.FieldDescriptorProto) bool {
+	switch f.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP,
+		descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		return f.GetType() == g.GetType() && f.GetTypeName() == g.GetTypeName()
+	}
+	return wireClass(f.GetType()) == wireClass(g.GetType())
+}
+
+func typeName(f *descriptorpb.FieldDescriptorProto) string {
+	if f.GetTypeName() != "" {
+		return strings.TrimPrefix(f.GetTypeName(), ".")
+	}
+	return strings.ToLower(strings.TrimPrefix(f.GetType().String(), "TYPE_"))
+}
+
+// reserved reports whether a message reserves number; ranges are
+// end-exclusive.
+func reserved(ranges []*descriptorpb.DescriptorProto_ReservedRange, number int32) bool {
+	for _, r := range ranges {
+		if number >= r.GetStart() && number < r.GetEnd() {
+			return true
+		}
+	}
+	return false
+}
+
+// reservedEnum reports whether an enum reserves number; ranges are
+// end-inclusive.
+func reservedEnum(ranges []*descriptorpb.EnumDescriptorProto_EnumReservedRange, number int32) bool {
+	for _, r := range ranges {
+		if number >= r.GetStart() && number <= r.GetEnd() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// keyringFile is the on-disk format:
+//
+//	{"keys": [
+//	  {"key": "s3cret", "principal": "orchestrator", "roles": {"*": "service"}},
+//	  {"key": "k2", "principal": "acme-editor", "roles": {"acme": "catalog-editor"}},
+//	  {"key": "k3", "principal": "shoebox", "roles": {"acme": "seller"}, "seller": "shoebox"}
+//	]}
+type keyringFile struct {
+	Keys []struct {
+		Key       string          `json:"key"`
+		Principal string          `json:"principal"`
+		Roles     map[string]Role `json:"roles"`
+		Seller    string          `json:"seller"`
+	} `json:"keys"`
+}
+
+// Keyring resolves API keys to principals. Keys are held hashed so lookups
+// don't compare secrets byte by byte.
+type Keyring struct {
+	byHash map[[sha256.Size]byte]Principal
+}
+
+// LoadKeyring reads a keyring from a JSON file.
+func LoadKeyring(filename string) (*Keyring, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var f keyringFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse keyring: %w", err)
+	}
+
+	k := &Keyring{byHash: make(map[[sha256.Size]byte]Principal, len(f.Keys))}
+	for i, entry := range f.Keys {
+		if entry.Key == "" || entry.Principal == "" {
+			return nil, fmt.Errorf("keyring entry %d: key and principal are required", i)
+		}
+		for tenant, role := range entry.Roles {
+			if _, ok := rolePermissions[role]; !ok {
+				return nil, fmt.Errorf("keyring entry %q: unknown role %q for tenant %q", entry.Principal, role, tenant)
+			}
+			if role == RoleSeller && entry.Seller == "" {
+				return nil, fmt.Errorf("keyring entry %q: the seller role needs a seller", entry.Principal)
+			}
+		}
+		hash := sha256.Sum256([]byte(entry.Key))
+		if _, dup := k.byHash[hash]; dup {
+			return nil, fmt.Errorf("keyring entry %q: duplicate key", entry.Principal)
+		}
+		k.byHash[hash] = Principal{Name: entry.Principal, Roles: entry.Roles, Seller: entry.Seller}
+	}
+	return k, nil
+}
+
+// Lookup returns the principal owning key.
+func (k *Keyring) Lookup(key string) (Principal, bool) {
+	p, ok := k.byHash[sha256.Sum256([]byte(key))]
+	return p, ok
+}
@@ -0,0 +1,227 @@
+// Package auth identifies callers by API key and decides which RPCs their
+// roles allow.
+package auth
+
+import (
+	"context"
+	"path"
+)
+
+// Role is granted to a principal per tenant.
+type Role string
+
+const (
+	RoleAdmin         Role = "admin"
+	RoleCatalogEditor Role = "catalog-editor"
+	RoleViewer        Role = "viewer"
+	// RoleService is for backend callers (e.g. the orchestrator) acting on
+	// behalf of shoppers.
+	RoleService Role = "service"
+	// RoleSeller is for a marketplace seller managing its own offers; the
+	// principal's Seller names which.
+	RoleSeller Role = "seller"
+)
+
+// Permission is a class of RPCs.
+type Permission string
+
+const (
+	CatalogRead  Permission = "catalog:read"
+	CatalogWrite Permission = "catalog:write"
+	// CustomerWrite covers shopper actions: orders, stock reservations,
+	// user profiles, measurements, saved searches and voice transcripts.
+	CustomerWrite Permission = "customer:write"
+	// SellerWrite covers a seller's profile, offers and product
+	// submissions; principals bound to a seller hold it for that seller
+	// only.
+	SellerWrite Permission = "seller:write"
+	Admin       Permission = "admin"
+)
+
+var rolePermissions = map[Role]map[Permission]bool{
+	RoleAdmin:         {CatalogRead: true, CatalogWrite: true, CustomerWrite: true, SellerWrite: true, Admin: true},
+	RoleCatalogEditor: {CatalogRead: true, CatalogWrite: true, SellerWrite: true},
+	RoleViewer:        {CatalogRead: true},
+	RoleService:       {CatalogRead: true, CustomerWrite: true},
+	RoleSeller:        {CatalogRead: true, SellerWrite: true},
+}
+
+// methodPermissions maps GraphService method names to the permission they
+// require. Methods missing here require Admin, so new RPCs are closed until
+// they are classified.
+var methodPermissions = map[string]Permission{
+	"GetProduct":                     CatalogRead,
+	"GetAvailability":                CatalogRead,
+	"FindNearbyAvailability":         CatalogRead,
+	"CheckCartPickup":                CatalogRead,
+	"ResolveProduct":                 CatalogRead,
+	"GetProductBySlug":               CatalogRead,
+	"ListProducts":                   CatalogRead,
+	"ListProductsUpdatedSince":       CatalogRead,
+	"SearchProducts":                 CatalogRead,
+	"StreamSearchProducts":           CatalogRead,
+	"SyncChanges":                    CatalogRead,
+	"GetNewArrivals":                 CatalogRead,
+	"GetDeals":                       CatalogRead,
+	"GetCollection":                  CatalogRead,
+	"GetBundle":                      CatalogRead,
+	"GetMerchandisedRecommendations": CatalogRead,
+	"GetSizeChart":                   CatalogRead,
+	"RecommendSize":                  CatalogRead,
+	"GetCategoryTree":                CatalogRead,
+	"ExportSubgraph":                 CatalogRead,
+	"EstimateShipping":               CatalogRead,
+	"PriceCart":                      CatalogRead,
+	"ValidateCoupon":                 CatalogRead,
+	"ListGoesWith":                   CatalogRead,
+	"GetOutfitSuggestions":           CatalogRead,
+	"ListQuestions":                  CatalogRead,
+	"GetSeller":                      CatalogRead,
+	"ListSellers":                    CatalogRead,
+	"ListSellerOffers":               CatalogRead,
+
+	"CreateProduct":               CatalogWrite,
+	"UpdateProduct":               CatalogWrite,
+	"DeleteProduct":               CatalogWrite,
+	"CloneProduct":                CatalogWrite,
+	"SetProductsArchived":         CatalogWrite,
+	"BulkUpdatePrices":            CatalogWrite,
+	"FindDuplicateProducts":       CatalogWrite,
+	"MergeProducts":               CatalogWrite,
+	"UpsertProductTranslation":    CatalogWrite,
+	"UpdateStock":                 CatalogWrite,
+	"UpsertStore":                 CatalogWrite,
+	"SetStoreStock":               CatalogWrite,
+	"AddProductSize":              CatalogWrite,
+	"CreateCollection":            CatalogWrite,
+	"UpdateCollection":            CatalogWrite,
+	"DeleteCollection":            CatalogWrite,
+	"SetCollectionProducts":       CatalogWrite,
+	"AddProductToCollection":      CatalogWrite,
+	"RemoveProductFromCollection": CatalogWrite,
+	"CreateBundle":                CatalogWrite,
+	"UpdateBundle":                CatalogWrite,
+	"DeleteBundle":                CatalogWrite,
+	"SetCrossSell":                CatalogWrite,
+	"SetUpsell":                   CatalogWrite,
+	"SetGoesWith":                 CatalogWrite,
+	"InferGoesWith":               CatalogWrite,
+	"ListPendingModeration":       CatalogWrite,
+	"ApprovePost":                 CatalogWrite,
+	"RejectPost":                  CatalogWrite,
+	"ReviewSubmission":            CatalogWrite,
+	"ReportPolicyViolation":       CatalogWrite,
+	"UpsertSizeChart":             CatalogWrite,
+	"ValidateCatalog":             CatalogWrite,
+
+	"OrderBundle":              CustomerWrite,
+	"ReserveStock":             CustomerWrite,
+	"CommitReservation":        CustomerWrite,
+	"ReleaseReservation":       CustomerWrite,
+	"PlaceOrder":               CustomerWrite,
+	"RedeemCoupon":             CustomerWrite,
+	"GetBalance":               CustomerWrite,
+	"RedeemGiftCard":           CustomerWrite,
+	"GetLoyaltyBalance":        CustomerWrite,
+	"RedeemPoints":             CustomerWrite,
+	"GetOrder":                 CustomerWrite,
+	"CreateReturn":             CustomerWrite,
+	"SetCustomerMeasurements":  CustomerWrite,
+	"CreateUser":               CustomerWrite,
+	"GetUser":                  CustomerWrite,
+	"UpdatePreferences":        CustomerWrite,
+	"SaveSearch":               CustomerWrite,
+	"ListSavedSearches":        CustomerWrite,
+	"SubscribeToPriceDrop":     CustomerWrite,
+	"UnsubscribeFromPriceDrop": CustomerWrite,
+	"DeleteSavedSearch":        CustomerWrite,
+	"AppendTranscript":         CustomerWrite,
+	"PostQuestion":             CustomerWrite,
+	"PostAnswer":               CustomerWrite,
+
+	"UpdateSeller":              SellerWrite,
+	"SetOffer":                  SellerWrite,
+	"DeleteOffer":               SellerWrite,
+	"SubmitProduct":             SellerWrite,
+	"GetSubmission":             SellerWrite,
+	"ListSubmissions":           SellerWrite,
+	"GetSellerSettlementReport": SellerWrite,
+	"RegisterSellerWebhook":     SellerWrite,
+	"ListSellerWebhooks":        SellerWrite,
+	"DeleteSellerWebhook":       SellerWrite,
+
+	"RegisterWebhook":      Admin,
+	"ResetAndSeed":         Admin,
+	"ListDeliveries":       Admin,
+	"ListSyncRuns":         Admin,
+	"ExportStockHistory":   Admin,
+	"ImportForecast":       Admin,
+	"SnapshotInventory":    Admin,
+	"ReconcileInventory":   Admin,
+	"GetCatalogOverview":   Admin,
+	"GetSessionTranscript": Admin,
+	"ApproveReturn":        Admin,
+	"CompleteReturn":       Admin,
+	"ReviewFlaggedOrders":  Admin,
+	"CreateCoupon":         Admin,
+	"IssueGiftCard":        Admin,
+	"ExportUserData":       Admin,
+	"DeleteUserData":       Admin,
+	"CreateSeller":         Admin,
+	"DeleteSeller":         Admin,
+}
+
+// RequiredPermission returns the permission needed to call fullMethod
+// ("/graph.GraphService/GetProduct").
+func RequiredPermission(fullMethod string) Permission {
+	if perm, ok := methodPermissions[path.Base(fullMethod)]; ok {
+		return perm
+	}
+	return Admin
+}
+
+// AnyTenant keys a role that applies to every tenant.
+const AnyTenant = "*"
+
+// Principal is an authenticated caller and its roles by tenant. Seller,
+// if set, binds the caller to one marketplace seller.
+type Principal struct {
+	Name   string
+	Roles  map[string]Role
+	Seller string
+}
+
+// RoleFor returns the principal's role in tenant, falling back to its
+// AnyTenant role.
+func (p Principal) RoleFor(tenant string) (Role, bool) {
+	if role, ok := p.Roles[tenant]; ok {
+		return role, true
+	}
+	role, ok := p.Roles[AnyTenant]
+	return role, ok
+}
+
+// Allowed reports whether the principal holds perm in tenant.
+func (p Principal) Allowed(tenant string, perm Permission) bool {
+	role, ok := p.RoleFor(tenant)
+	return ok && rolePermissions[role][perm]
+}
+
+// ActsFor reports whether the principal may manage seller's profile and
+// offers: principals bound to a seller only their own, others any.
+func (p Principal) ActsFor(seller string) bool {
+	return p.Seller == "" || p.Seller == seller
+}
+
+type principalKey struct{}
+
+// WithPrincipal attaches the authenticated caller to ctx.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the caller attached by WithPrincipal.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
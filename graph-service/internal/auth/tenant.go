@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Scope returns the tenant a call asking for requested is confined to:
+// requested itself when named, else the principal's one tenant. Only
+// principals with an AnyTenant role act across tenants, as "", and only
+// when they don't name one. ok is false for a named tenant the principal
+// has no role in, and for principals with roles in several tenants that
+// don't name one.
+func (p Principal) Scope(requested string) (tenant string, ok bool) {
+	if requested == AnyTenant {
+		return "", false
+	}
+	if requested != "" {
+		_, ok := p.RoleFor(requested)
+		return requested, ok
+	}
+	if _, any := p.Roles[AnyTenant]; any {
+		return "", true
+	}
+	if len(p.Roles) != 1 {
+		return "", false
+	}
+	for tenant := range p.Roles {
+		return tenant, true
+	}
+	return "", false
+}
+
+type tenantKey struct{}
+
+// WithTenant confines the calls made with ctx to tenant; "" doesn't.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFrom returns the tenant ctx is confined to, if any.
+func TenantFrom(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// CheckTenant refuses a call confined to one tenant acting on a resource
+// of another; resources without a tenant belong to none. kind and id name
+// the resource for the audit log.
+func CheckTenant(ctx context.Context, kind, id, tenant string) error {
+	scope, ok := TenantFrom(ctx)
+	if !ok || tenant == scope {
+		return nil
+	}
+	principal, _ := PrincipalFrom(ctx)
+	log.Printf("audit: deny principal=%s tenant=%q %s=%s %s_tenant=%q reason=other_tenant",
+		principal.Name, scope, kind, id, kind, tenant)
+	return status.Errorf(codes.PermissionDenied, "%s %s belongs to another tenant", kind, id)
+}
+
+// CheckAllTenants refuses what spans every tenant, such as running a raw
+// query or reading the global webhook log, to all but admins of every
+// tenant not confined to one. action names it for the error and the audit
+// log.
+func CheckAllTenants(ctx context.Context, action string) error {
+	scope, confined := TenantFrom(ctx)
+	principal, ok := PrincipalFrom(ctx)
+	if !confined && (!ok || principal.Allowed(AnyTenant, Admin)) {
+		return nil
+	}
+	log.Printf("audit: deny principal=%s tenant=%q action=%q reason=cross_tenant", principal.Name, scope, action)
+	return status.Errorf(codes.PermissionDenied, "%s spans tenants; it needs an admin key for every tenant, without a tenant named", action)
+}
+
+// ConfineRequest holds req's tenant_id fields, at any depth, to tenant:
+// those naming another fail, and empty ones are set to it, so creates land
+// in the tenant and filters stay in it.
+func ConfineRequest(req proto.Message, tenant string) error {
+	if tenant == "" {
+		return nil
+	}
+	return confine(req.ProtoReflect(), tenant)
+}
+
+// confine holds msg's own tenant_id to tenant, then that of every message
+// it carries.
+func confine(msg protoreflect.Message, tenant string) error {
+	if fd := msg.Descriptor().Fields().ByName("tenant_id"); fd != nil && fd.Kind() == protoreflect.StringKind && !fd.IsList() {
+		switch named := msg.Get(fd).String(); named {
+		case tenant:
+		case "":
+			msg.Set(fd, protoreflect.ValueOfString(tenant))
+		default:
+			return status.Error(codes.PermissionDenied, fmt.Sprintf("tenant_id %q is outside tenant %q", named, tenant))
+		}
+	}
+
+	var err error
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Kind() == protoreflect.MessageKind {
+				v.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+					err = confine(v.Message(), tenant)
+					return err == nil
+				})
+			}
+		case fd.Kind() != protoreflect.MessageKind && fd.Kind() != protoreflect.GroupKind:
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len() && err == nil; i++ {
+				err = confine(list.Get(i).Message(), tenant)
+			}
+		default:
+			err = confine(v.Message(), tenant)
+		}
+		return err == nil
+	})
+	return err
+}
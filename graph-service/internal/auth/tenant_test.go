@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestScope(t *testing.T) {
+	acme := Principal{Name: "acme-editor", Roles: map[string]Role{"acme": RoleCatalogEditor}}
+	both := Principal{Name: "agency", Roles: map[string]Role{"acme": RoleViewer, "globex": RoleViewer}}
+	global := Principal{Name: "orchestrator", Roles: map[string]Role{AnyTenant: RoleService}}
+
+	tests := []struct {
+		name      string
+		principal Principal
+		requested string
+		want      string
+		wantOK    bool
+	}{
+		{"own tenant by default", acme, "", "acme", true},
+		{"own tenant named", acme, "acme", "acme", true},
+		{"other tenant named", acme, "globex", "globex", false},
+		{"any tenant named", acme, AnyTenant, "", false},
+		{"several tenants, none named", both, "", "", false},
+		{"several tenants, one named", both, "globex", "globex", true},
+		{"several tenants, another named", both, "initech", "initech", false},
+		{"any-tenant role, none named", global, "", "", true},
+		{"any-tenant role, one named", global, "acme", "acme", true},
+		{"any-tenant role, any tenant named", global, AnyTenant, "", false},
+		{"no roles", Principal{Name: "nobody"}, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.principal.Scope(tt.requested)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Scope(%q) = %q, %v, want %q, %v", tt.requested, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCheckTenant(t *testing.T) {
+	acme := Principal{Name: "acme-editor", Roles: map[string]Role{"acme": RoleCatalogEditor}}
+	confined := WithTenant(WithPrincipal(context.Background(), acme), "acme")
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		tenant string
+		want   codes.Code
+	}{
+		{"same tenant", confined, "acme", codes.OK},
+		{"other tenant", confined, "globex", codes.PermissionDenied},
+		{"no tenant", confined, "", codes.PermissionDenied},
+		{"unconfined", WithTenant(context.Background(), ""), "globex", codes.OK},
+		{"no auth", context.Background(), "globex", codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckTenant(tt.ctx, "product", "p1", tt.tenant)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("CheckTenant(%q) = %v, want %v", tt.tenant, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAllTenants(t *testing.T) {
+	admin := Principal{Name: "ops", Roles: map[string]Role{AnyTenant: RoleAdmin}}
+	service := Principal{Name: "orchestrator", Roles: map[string]Role{AnyTenant: RoleService}}
+	acmeAdmin := Principal{Name: "acme-admin", Roles: map[string]Role{"acme": RoleAdmin}}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{"any-tenant admin", WithTenant(WithPrincipal(context.Background(), admin), ""), codes.OK},
+		{"any-tenant admin, tenant named", WithTenant(WithPrincipal(context.Background(), admin), "acme"), codes.PermissionDenied},
+		{"any-tenant non-admin", WithTenant(WithPrincipal(context.Background(), service), ""), codes.PermissionDenied},
+		{"one tenant's admin", WithTenant(WithPrincipal(context.Background(), acmeAdmin), "acme"), codes.PermissionDenied},
+		{"no auth", context.Background(), codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(CheckAllTenants(tt.ctx, "a Cypher query")); got != tt.want {
+				t.Errorf("CheckAllTenants = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfineRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     proto.Message
+		want    proto.Message
+		wantErr bool
+	}{
+		{
+			name: "nested, filled in",
+			req:  &pb.CreateProductRequest{Product: &pb.Product{Id: "p1"}},
+			want: &pb.CreateProductRequest{Product: &pb.Product{Id: "p1", TenantId: "acme"}},
+		},
+		{
+			name: "nested, own tenant",
+			req:  &pb.CreateProductRequest{Product: &pb.Product{TenantId: "acme"}},
+			want: &pb.CreateProductRequest{Product: &pb.Product{TenantId: "acme"}},
+		},
+		{
+			name:    "nested, other tenant",
+			req:     &pb.CreateProductRequest{Product: &pb.Product{TenantId: "globex"}},
+			wantErr: true,
+		},
+		{
+			name: "list, filled in",
+			req:  &pb.ListProductsResponse{Products: []*pb.Product{{Id: "p1"}, {Id: "p2", TenantId: "acme"}}},
+			want: &pb.ListProductsResponse{Products: []*pb.Product{{Id: "p1", TenantId: "acme"}, {Id: "p2", TenantId: "acme"}}},
+		},
+		{
+			name:    "list, other tenant after the first",
+			req:     &pb.ListProductsResponse{Products: []*pb.Product{{Id: "p1"}, {Id: "p2", TenantId: "globex"}}},
+			wantErr: true,
+		},
+		{
+			name: "top level, filled in",
+			req:  &pb.GetCategoryTreeRequest{},
+			want: &pb.GetCategoryTreeRequest{TenantId: "acme"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ConfineRequest(tt.req, "acme")
+			if tt.wantErr {
+				if status.Code(err) != codes.PermissionDenied {
+					t.Fatalf("ConfineRequest = %v, want PermissionDenied", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ConfineRequest = %v", err)
+			}
+			if !proto.Equal(tt.req, tt.want) {
+				t.Errorf("ConfineRequest gave %v, want %v", tt.req, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfineRequestMap(t *testing.T) {
+	holder := mapHolder(t)
+	item := holder.Fields().ByName("items").MapValue().Message()
+
+	newItem := func(tenant string) protoreflect.Value {
+		m := dynamicpb.NewMessage(item)
+		m.Set(item.Fields().ByName("tenant_id"), protoreflect.ValueOfString(tenant))
+		return protoreflect.ValueOfMessage(m)
+	}
+	newHolder := func(tenants ...string) *dynamicpb.Message {
+		m := dynamicpb.NewMessage(holder)
+		items := m.Mutable(holder.Fields().ByName("items")).Map()
+		for i, tenant := range tenants {
+			items.Set(protoreflect.ValueOfString(string(rune('a'+i))).MapKey(), newItem(tenant))
+		}
+		return m
+	}
+
+	filled := newHolder("", "acme")
+	if err := ConfineRequest(filled, "acme"); err != nil {
+		t.Fatalf("ConfineRequest = %v", err)
+	}
+	if want := newHolder("acme", "acme"); !proto.Equal(filled, want) {
+		t.Errorf("ConfineRequest gave %v, want %v", filled, want)
+	}
+
+	if err := ConfineRequest(newHolder("", "globex"), "acme"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ConfineRequest with another tenant in a map = %v, want PermissionDenied", err)
+	}
+}
+
+// mapHolder describes a message with a map of messages carrying a
+// tenant_id, which no API message has yet.
+func mapHolder(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+	msg := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("confine_test.proto"),
+		Package: proto.String("confinetest"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Item"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("tenant_id"), JsonName: proto.String("tenantId"), Number: proto.Int32(1), Type: str, Label: optional},
+				},
+			},
+			{
+				Name: proto.String("Holder"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("items"), JsonName: proto.String("items"), Number: proto.Int32(1), Type: msg, Label: repeated, TypeName: proto.String(".confinetest.Holder.ItemsEntry")},
+				},
+				NestedType: []*descriptorpb.DescriptorProto{
+					{
+						Name: proto.String("ItemsEntry"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							{Name: proto.String("key"), JsonName: proto.String("key"), Number: proto.Int32(1), Type: str, Label: optional},
+							{Name: proto.String("value"), JsonName: proto.String("value"), Number: proto.Int32(2), Type: msg, Label: optional, TypeName: proto.String(".confinetest.Item")},
+						},
+						Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+					},
+				},
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file.Messages().ByName("Holder")
+}
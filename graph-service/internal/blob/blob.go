@@ -0,0 +1,118 @@
+// Package blob stores generated files, such as product feeds, by key and
+// serves them over HTTP. Keys are slash-separated paths like
+// "feeds/google-us.xml".
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned for keys that hold nothing.
+var ErrNotFound = errors.New("blob not found")
+
+// Store holds blobs by key.
+type Store interface {
+	// Put replaces the blob at key. Readers see the old blob or the new
+	// one, never part of it.
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns the blob at key and when it was put.
+	Get(ctx context.Context, key string) ([]byte, time.Time, error)
+}
+
+// Dir stores blobs as files under a directory, which replicas may share
+// over a network file system.
+type Dir string
+
+func (d Dir) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean != "/"+key {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(string(d), filepath.FromSlash(clean)), nil
+}
+
+func (d Dir) Put(ctx context.Context, key string, data []byte) error {
+	name, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	// Write aside and rename, so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+func (d Dir) Get(ctx context.Context, key string) ([]byte, time.Time, error) {
+	name, err := d.path(key)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	info, err := os.Stat(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, time.Time{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return data, info.ModTime(), nil
+}
+
+// Handler serves the blobs under prefix, e.g. "feeds/", at their keys.
+// The content type follows the key's extension, and conditional and range
+// requests are honoured.
+func Handler(store Store, prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		if !strings.HasPrefix(key, prefix) {
+			http.NotFound(w, r)
+			return
+		}
+
+		data, modified, err := store.Get(r.Context(), key)
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, "blob unavailable", http.StatusInternalServerError)
+			return
+		}
+		http.ServeContent(w, r, path.Base(key), modified, bytes.NewReader(data))
+	})
+}
@@ -0,0 +1,143 @@
+// Package breaker implements a consecutive-failure circuit breaker.
+package breaker
+
+import (
+	"errors"
+	"expvar"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/metrics"
+)
+
+// ErrOpen is returned instead of running work while the breaker is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the breaker's position.
+type State int
+
+const (
+	// Closed lets all calls through.
+	Closed State = iota
+	// Open rejects calls until the cooldown has passed.
+	Open
+	// HalfOpen lets a single probe call through; its outcome closes or
+	// re-opens the breaker.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker trips after Threshold consecutive failures and fails fast for
+// Cooldown, then admits one probe to test for recovery.
+//
+// Metrics are published under the breaker's name: state, trips,
+// rejected and consecutive_failures.
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+
+	stats    *expvar.Map
+	stateVar expvar.String
+}
+
+func New(name string, threshold int, cooldown time.Duration) *Breaker {
+	b := &Breaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		stats:     metrics.Map(name),
+	}
+	b.stateVar.Set(Closed.String())
+	b.stats.Set("state", &b.stateVar)
+	b.stats.Add("trips", 0)
+	b.stats.Add("rejected", 0)
+	b.stats.Add("consecutive_failures", 0)
+	return b
+}
+
+// State returns the current state.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be
+// followed by exactly one Record.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			b.stats.Add("rejected", 1)
+			return ErrOpen
+		}
+		b.setState(HalfOpen)
+		b.probing = true
+		return nil
+	case HalfOpen:
+		if b.probing {
+			b.stats.Add("rejected", 1)
+			return ErrOpen
+		}
+		b.probing = true
+		return nil
+	}
+	return nil
+}
+
+// Record reports the outcome of an allowed call. failed should only be true
+// for failures that indicate the dependency is unhealthy.
+func (b *Breaker) Record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.failures = 0
+		b.probing = false
+		if b.state != Closed {
+			b.setState(Closed)
+		}
+		b.stats.Set("consecutive_failures", new(expvar.Int))
+		return
+	}
+
+	b.failures++
+	b.stats.Add("consecutive_failures", 1)
+	if b.state == HalfOpen || b.failures >= b.threshold {
+		b.probing = false
+		b.openedAt = b.now()
+		if b.state != Open {
+			b.stats.Add("trips", 1)
+			b.setState(Open)
+		}
+	}
+}
+
+func (b *Breaker) setState(s State) {
+	log.Printf("breaker %s: %s -> %s", b.name, b.state, s)
+	b.state = s
+	b.stateVar.Set(s.String())
+}
@@ -0,0 +1,143 @@
+package breaker
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// step is one call through the breaker: how long after the last one it
+// comes, and, if it is allowed, whether it fails.
+type step struct {
+	after     time.Duration
+	failed    bool
+	wantAllow bool
+	wantState State
+}
+
+func TestTransitions(t *testing.T) {
+	const cooldown = 10 * time.Second
+
+	tests := []struct {
+		name      string
+		steps     []step
+		wantTrips int64
+	}{
+		{
+			name: "successes stay closed",
+			steps: []step{
+				{wantAllow: true, wantState: Closed},
+				{wantAllow: true, wantState: Closed},
+			},
+		},
+		{
+			name: "failures under the threshold stay closed",
+			steps: []step{
+				{failed: true, wantAllow: true, wantState: Closed},
+				{failed: true, wantAllow: true, wantState: Closed},
+			},
+		},
+		{
+			name: "a success resets the count",
+			steps: []step{
+				{failed: true, wantAllow: true, wantState: Closed},
+				{failed: true, wantAllow: true, wantState: Closed},
+				{wantAllow: true, wantState: Closed},
+				{failed: true, wantAllow: true, wantState: Closed},
+				{failed: true, wantAllow: true, wantState: Closed},
+			},
+		},
+		{
+			name: "trips at the threshold and rejects during the cooldown",
+			steps: []step{
+				{failed: true, wantAllow: true, wantState: Closed},
+				{failed: true, wantAllow: true, wantState: Closed},
+				{failed: true, wantAllow: true, wantState: Open},
+				{after: cooldown - time.Second, wantAllow: false, wantState: Open},
+			},
+			wantTrips: 1,
+		},
+		{
+			name: "a successful probe closes",
+			steps: []step{
+				{failed: true, wantAllow: true, wantState: Closed},
+				{failed: true, wantAllow: true, wantState: Closed},
+				{failed: true, wantAllow: true, wantState: Open},
+				{after: cooldown, wantAllow: true, wantState: Closed},
+				{wantAllow: true, wantState: Closed},
+			},
+			wantTrips: 1,
+		},
+		{
+			name: "a failed probe re-opens for another cooldown",
+			steps: []step{
+				{failed: true, wantAllow: true, wantState: Closed},
+				{failed: true, wantAllow: true, wantState: Closed},
+				{failed: true, wantAllow: true, wantState: Open},
+				{after: cooldown, failed: true, wantAllow: true, wantState: Open},
+				{after: cooldown - time.Second, wantAllow: false, wantState: Open},
+				{after: time.Second, wantAllow: true, wantState: Closed},
+			},
+			wantTrips: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, clock := newTestBreaker(t, 3, cooldown)
+			for i, s := range tt.steps {
+				*clock = clock.Add(s.after)
+				err := b.Allow()
+				if allowed := err == nil; allowed != s.wantAllow {
+					t.Fatalf("step %d: Allow = %v, want allowed %v", i, err, s.wantAllow)
+				}
+				if err == nil {
+					b.Record(s.failed)
+				} else if !errors.Is(err, ErrOpen) {
+					t.Fatalf("step %d: Allow = %v, want ErrOpen", i, err)
+				}
+				if got := b.State(); got != s.wantState {
+					t.Fatalf("step %d: State = %s, want %s", i, got, s.wantState)
+				}
+			}
+			if got := b.stats.Get("trips").String(); got != strconv.FormatInt(tt.wantTrips, 10) {
+				t.Errorf("trips = %s, want %d", got, tt.wantTrips)
+			}
+			if got := b.stats.Get("state").String(); got != `"`+b.State().String()+`"` {
+				t.Errorf("state metric = %s, want %q", got, b.State())
+			}
+		})
+	}
+}
+
+func TestOneProbeAtATime(t *testing.T) {
+	b, clock := newTestBreaker(t, 1, time.Second)
+	b.Allow()
+	b.Record(true)
+
+	*clock = clock.Add(time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("first Allow after the cooldown = %v, want the probe", err)
+	}
+	if got := b.State(); got != HalfOpen {
+		t.Fatalf("State while probing = %s, want half-open", got)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("second Allow while probing = %v, want ErrOpen", err)
+	}
+	b.Record(false)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow after the probe succeeded = %v", err)
+	}
+}
+
+// newTestBreaker returns a breaker with its own metrics and a clock the
+// test moves by hand.
+func newTestBreaker(t *testing.T, threshold int, cooldown time.Duration) (*Breaker, *time.Time) {
+	name := "breaker_test_" + strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	b := New(name, threshold, cooldown)
+	clock := time.Unix(1700000000, 0)
+	b.now = func() time.Time { return clock }
+	return b, &clock
+}
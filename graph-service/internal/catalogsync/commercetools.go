@@ -0,0 +1,235 @@
+package catalogsync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+)
+
+const commercetoolsPageSize = 500
+
+// Commercetools reads a project's published product projections. Variants
+// become sizes, named by their size attribute, with their available
+// quantity as stock; the master variant's first price is the product's.
+// Names and descriptions are read in one locale, and the brand and color
+// attributes fill the product's. Categories are references that would need
+// a lookup each, so they are left to the catalog.
+type Commercetools struct {
+	project      string
+	clientID     string
+	clientSecret string
+	locale       string
+	authURL      string
+	apiURL       string
+	client       *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewCommercetools reads the project in region, e.g.
+// "europe-west1.gcp", with an API client allowed view_products, in
+// locale, e.g. "en".
+func NewCommercetools(project, region, clientID, clientSecret, locale string) *Commercetools {
+	return &Commercetools{
+		project:      project,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		locale:       locale,
+		authURL:      "https://auth." + region + ".commercetools.com",
+		apiURL:       "https://api." + region + ".commercetools.com",
+		client:       &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (c *Commercetools) Name() string {
+	return "commercetools:" + c.project
+}
+
+// accessToken returns a client credentials token, fetching a new one a
+// minute before the current one expires.
+func (c *Commercetools) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+
+	form := url.Values{
+		"grant_type": {"client_credentials"},
+		"scope":      {"view_products:" + c.project},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.authURL+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.clientID, c.clientSecret)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("commercetools: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("commercetools: token: %d", resp.StatusCode)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("commercetools: token: %w", err)
+	}
+	c.token = token.AccessToken
+	c.expires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return c.token, nil
+}
+
+type commercetoolsVariant struct {
+	SKU    string `json:"sku"`
+	Prices []struct {
+		Value struct {
+			CentAmount     int64 `json:"centAmount"`
+			FractionDigits int   `json:"fractionDigits"`
+		} `json:"value"`
+	} `json:"prices"`
+	Images []struct {
+		URL string `json:"url"`
+	} `json:"images"`
+	Attributes []struct {
+		Name  string          `json:"name"`
+		Value json.RawMessage `json:"value"`
+	} `json:"attributes"`
+	Availability struct {
+		AvailableQuantity int32 `json:"availableQuantity"`
+	} `json:"availability"`
+}
+
+type commercetoolsProduct struct {
+	ID             string                 `json:"id"`
+	Name           map[string]string      `json:"name"`
+	Description    map[string]string      `json:"description"`
+	LastModifiedAt time.Time              `json:"lastModifiedAt"`
+	MasterVariant  commercetoolsVariant   `json:"masterVariant"`
+	Variants       []commercetoolsVariant `json:"variants"`
+}
+
+// Fetch pages by the last product's modification time and id, which
+// unlike offsets has no upper bound. Page tokens are "<RFC 3339>|<id>".
+func (c *Commercetools) Fetch(ctx context.Context, since int64, pageToken string) ([]*pb.Product, string, error) {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	where := fmt.Sprintf(`lastModifiedAt >= "%s"`, time.UnixMilli(since).UTC().Format(time.RFC3339Nano))
+	if modified, id, ok := strings.Cut(pageToken, "|"); ok {
+		where = fmt.Sprintf(`lastModifiedAt > "%s" or (lastModifiedAt = "%s" and id > "%s")`, modified, modified, id)
+	}
+	query := url.Values{
+		"staged":    {"false"},
+		"withTotal": {"false"},
+		"limit":     {strconv.Itoa(commercetoolsPageSize)},
+		"sort":      {"lastModifiedAt asc", "id asc"},
+		"where":     {where},
+	}
+	body, _, err := get(ctx, c.client, "commercetools", c.apiURL+"/"+c.project+"/product-projections?"+query.Encode(), func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+token)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var page struct {
+		Results []commercetoolsProduct `json:"results"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, "", fmt.Errorf("commercetools: %w", err)
+	}
+	products := make([]*pb.Product, 0, len(page.Results))
+	for _, cp := range page.Results {
+		products = append(products, c.product(cp))
+	}
+
+	next := ""
+	if n := len(page.Results); n == commercetoolsPageSize {
+		last := page.Results[n-1]
+		next = last.LastModifiedAt.UTC().Format(time.RFC3339Nano) + "|" + last.ID
+	}
+	return products, next, nil
+}
+
+func (c *Commercetools) product(cp commercetoolsProduct) *pb.Product {
+	master := cp.MasterVariant
+	p := &pb.Product{
+		ExternalId:  cp.ID,
+		Name:        cp.Name[c.locale],
+		Description: cp.Description[c.locale],
+		Brand:       c.attribute(master, "brand"),
+		Color:       c.attribute(master, "color"),
+		UpdatedAt:   cp.LastModifiedAt.UnixMilli(),
+	}
+	if len(master.Prices) > 0 {
+		value := master.Prices[0].Value
+		p.Price = float64(value.CentAmount) / math.Pow10(value.FractionDigits)
+	}
+	for _, image := range master.Images {
+		p.Images = append(p.Images, image.URL)
+	}
+
+	for _, v := range append([]commercetoolsVariant{master}, cp.Variants...) {
+		if v.SKU == "" {
+			continue
+		}
+		p.Sizes = append(p.Sizes, &pb.ProductSize{
+			Sku:     v.SKU,
+			Size:    c.attribute(v, "size"),
+			Stock:   v.Availability.AvailableQuantity,
+			InStock: v.Availability.AvailableQuantity > 0,
+		})
+	}
+	return p
+}
+
+// attribute returns a variant attribute as text: a string, a localized
+// string in the source's locale, or an enum's label or key.
+func (c *Commercetools) attribute(v commercetoolsVariant, name string) string {
+	for _, attr := range v.Attributes {
+		if attr.Name != name {
+			continue
+		}
+		var text string
+		if json.Unmarshal(attr.Value, &text) == nil {
+			return text
+		}
+		var localized map[string]string
+		if json.Unmarshal(attr.Value, &localized) == nil && localized[c.locale] != "" {
+			return localized[c.locale]
+		}
+		var enum struct {
+			Key   string          `json:"key"`
+			Label json.RawMessage `json:"label"`
+		}
+		if json.Unmarshal(attr.Value, &enum) == nil {
+			if json.Unmarshal(enum.Label, &text) == nil {
+				return text
+			}
+			if json.Unmarshal(enum.Label, &localized) == nil && localized[c.locale] != "" {
+				return localized[c.locale]
+			}
+			return enum.Key
+		}
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package catalogsync
+
+import (
+	"fmt"
+	"strings"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/config"
+)
+
+// FromConfig returns a syncer for the configured PIM source, or nil when
+// sync is disabled.
+func FromConfig(cfg config.Config, store Store) (*Syncer, error) {
+	var source Source
+	switch cfg.SyncSource {
+	case "":
+		return nil, nil
+	case "rest":
+		if cfg.SyncFeedURL == "" {
+			return nil, fmt.Errorf("SYNC_SOURCE=rest needs SYNC_FEED_URL")
+		}
+		source = NewRESTFeed(cfg.SyncFeedURL, cfg.SyncFeedToken)
+	case "csv":
+		if cfg.SyncCSVDir == "" {
+			return nil, fmt.Errorf("SYNC_SOURCE=csv needs SYNC_CSV_DIR")
+		}
+		source = NewCSVDrop(cfg.SyncCSVDir)
+	case "shopify":
+		if cfg.ShopifyShop == "" || cfg.ShopifyAccessToken == "" {
+			return nil, fmt.Errorf("SYNC_SOURCE=shopify needs SHOPIFY_SHOP and SHOPIFY_ACCESS_TOKEN")
+		}
+		shop := NewShopify(cfg.ShopifyShop, cfg.ShopifyAccessToken)
+		collections, err := parseCollectionCategories(cfg.ShopifyCollectionCategories)
+		if err != nil {
+			return nil, err
+		}
+		shop.Collections = collections
+		source = shop
+	case "commercetools":
+		if cfg.CommercetoolsProject == "" || cfg.CommercetoolsClientID == "" || cfg.CommercetoolsClientSecret == "" {
+			return nil, fmt.Errorf("SYNC_SOURCE=commercetools needs COMMERCETOOLS_PROJECT, COMMERCETOOLS_CLIENT_ID and COMMERCETOOLS_CLIENT_SECRET")
+		}
+		source = NewCommercetools(cfg.CommercetoolsProject, cfg.CommercetoolsRegion,
+			cfg.CommercetoolsClientID, cfg.CommercetoolsClientSecret, cfg.CommercetoolsLocale)
+	default:
+		return nil, fmt.Errorf("unknown SYNC_SOURCE %q (want rest, csv, shopify or commercetools)", cfg.SyncSource)
+	}
+
+	policy, err := ParsePolicy(cfg.SyncConflictPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("SYNC_CONFLICT_POLICY: %w", err)
+	}
+	syncer := NewSyncer(store, source)
+	syncer.Interval = cfg.SyncInterval
+	syncer.Policy = policy
+	syncer.TenantID = cfg.SyncTenant
+	return syncer, nil
+}
+
+// parseCollectionCategories reads "handle=Main/Sub/Type,..." pairs.
+func parseCollectionCategories(s string) (map[string]*pb.ProductCategory, error) {
+	collections := map[string]*pb.ProductCategory{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		handle, path, ok := strings.Cut(pair, "=")
+		parts := strings.Split(path, "/")
+		if !ok || strings.TrimSpace(handle) == "" || strings.TrimSpace(parts[0]) == "" || len(parts) > 3 {
+			return nil, fmt.Errorf("SHOPIFY_COLLECTION_CATEGORIES: %q is not handle=main/sub/specific", pair)
+		}
+		category := &pb.ProductCategory{MainCategory: strings.TrimSpace(parts[0])}
+		if len(parts) > 1 {
+			category.Subcategory = strings.TrimSpace(parts[1])
+		}
+		if len(parts) > 2 {
+			category.SpecificType = strings.TrimSpace(parts[2])
+		}
+		collections[strings.TrimSpace(handle)] = category
+	}
+	return collections, nil
+}
@@ -0,0 +1,222 @@
+package catalogsync
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+)
+
+// CSVDrop reads the CSV files a PIM drops into a directory, typically the
+// landing directory of an SFTP account. Files are read oldest first, one
+// per page, and every product in a file counts as changed when the file
+// was last modified. A file modified as the last run ended is read again
+// by the next, which leaves its products unchanged.
+//
+// The first row names the columns: external_id, name, brand,
+// main_category, subcategory, specific_type, color, price, original_price,
+// description, tags and images ("|"-separated), and sku, size and stock.
+// Each row is one size; a product's fields are taken from its first row.
+type CSVDrop struct {
+	dir string
+}
+
+func NewCSVDrop(dir string) *CSVDrop {
+	return &CSVDrop{dir: dir}
+}
+
+func (d *CSVDrop) Name() string {
+	return "csv:" + d.dir
+}
+
+type dropFile struct {
+	name     string
+	modified int64
+}
+
+// key orders files by modification time, then name. Page tokens are the
+// key of the file last read.
+func (f dropFile) key() string {
+	return fmt.Sprintf("%d:%s", f.modified, f.name)
+}
+
+func (d *CSVDrop) Fetch(ctx context.Context, since int64, pageToken string) ([]*pb.Product, string, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, "", fmt.Errorf("csv drop: %w", err)
+	}
+	var files []dropFile
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".csv") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, "", fmt.Errorf("csv drop: %w", err)
+		}
+		if modified := info.ModTime().UnixMilli(); modified >= since {
+			files = append(files, dropFile{name: entry.Name(), modified: modified})
+		}
+	}
+	slices.SortFunc(files, func(a, b dropFile) int {
+		return cmp.Or(cmp.Compare(a.modified, b.modified), cmp.Compare(a.name, b.name))
+	})
+
+	// Skip the files earlier pages read
+	if pageToken != "" {
+		files = slices.DeleteFunc(files, func(f dropFile) bool {
+			return compareKeys(f, pageToken) <= 0
+		})
+	}
+	if len(files) == 0 {
+		return nil, "", nil
+	}
+
+	products, err := d.read(files[0])
+	if err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if len(files) > 1 {
+		next = files[0].key()
+	}
+	return products, next, nil
+}
+
+// compareKeys compares f with the file a page token names.
+func compareKeys(f dropFile, token string) int {
+	modified, name, _ := strings.Cut(token, ":")
+	ms, _ := strconv.ParseInt(modified, 10, 64)
+	return cmp.Or(cmp.Compare(f.modified, ms), cmp.Compare(f.name, name))
+}
+
+func (d *CSVDrop) read(f dropFile) ([]*pb.Product, error) {
+	file, err := os.Open(filepath.Join(d.dir, f.name))
+	if err != nil {
+		return nil, fmt.Errorf("csv drop: %w", err)
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv drop: %s: header: %w", f.name, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["external_id"]; !ok {
+		return nil, fmt.Errorf("csv drop: %s: no external_id column", f.name)
+	}
+
+	var products []*pb.Product
+	byID := map[string]*pb.Product{}
+	for line := 2; ; line++ {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return products, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv drop: %s: %w", f.name, err)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		id := field("external_id")
+		if id == "" {
+			return nil, fmt.Errorf("csv drop: %s: line %d has no external_id", f.name, line)
+		}
+		p, ok := byID[id]
+		if !ok {
+			p, err = csvProduct(field)
+			if err != nil {
+				return nil, fmt.Errorf("csv drop: %s: line %d: %w", f.name, line, err)
+			}
+			p.UpdatedAt = f.modified
+			byID[id] = p
+			products = append(products, p)
+		}
+
+		if sku := field("sku"); sku != "" {
+			stock, err := parseNumber(field("stock"), strconv.Atoi)
+			if err != nil {
+				return nil, fmt.Errorf("csv drop: %s: line %d: stock: %w", f.name, line, err)
+			}
+			p.Sizes = append(p.Sizes, &pb.ProductSize{
+				Sku:     sku,
+				Size:    field("size"),
+				Stock:   int32(stock),
+				InStock: stock > 0,
+			})
+		}
+	}
+}
+
+func csvProduct(field func(string) string) (*pb.Product, error) {
+	price, err := parseNumber(field("price"), parseFloat)
+	if err != nil {
+		return nil, fmt.Errorf("price: %w", err)
+	}
+	originalPrice, err := parseNumber(field("original_price"), parseFloat)
+	if err != nil {
+		return nil, fmt.Errorf("original_price: %w", err)
+	}
+
+	p := &pb.Product{
+		ExternalId:    field("external_id"),
+		Name:          field("name"),
+		Brand:         field("brand"),
+		Color:         field("color"),
+		Price:         price,
+		OriginalPrice: originalPrice,
+		Description:   field("description"),
+		Tags:          splitList(field("tags")),
+		Images:        splitList(field("images")),
+	}
+	if main := field("main_category"); main != "" {
+		p.Category = &pb.ProductCategory{
+			MainCategory: main,
+			Subcategory:  field("subcategory"),
+			SpecificType: field("specific_type"),
+		}
+	}
+	return p, nil
+}
+
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+// parseNumber parses s, or returns zero if it is empty.
+func parseNumber[T int | float64](s string, parse func(string) (T, error)) (T, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return parse(s)
+}
+
+// splitList splits a "|"-separated cell, dropping empty entries.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,67 @@
+package catalogsync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	// maxResponseSize bounds a page of products.
+	maxResponseSize = 32 << 20
+)
+
+// get fetches url with authorize adding credentials and returns the body
+// and headers. Error responses come back as a *statusError. source names
+// the API in errors.
+func get(ctx context.Context, client *http.Client, source, url string, authorize func(*http.Request)) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	authorize(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", source, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", source, err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, nil, &statusError{source: source, code: resp.StatusCode, body: body, header: resp.Header}
+	}
+	return body, resp.Header, nil
+}
+
+// statusError is an error response from a source.
+type statusError struct {
+	source string
+	code   int
+	body   []byte
+	header http.Header
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s: %d %s", e.source, e.code, bytes.TrimSpace(e.body))
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,80 @@
+package catalogsync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// RESTFeed reads a JSON product feed. It is asked for
+// GET <url>?updated_since=<unix ms>&page_token=<token> and answers
+//
+//	{"products": [...], "next_page_token": "..."}
+//
+// with products in the JSON form of Product, external_id and updated_at
+// set. Unknown fields are ignored.
+type RESTFeed struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewRESTFeed reads the feed at feedURL, sending token, if set, as a
+// bearer token.
+func NewRESTFeed(feedURL, token string) *RESTFeed {
+	return &RESTFeed{
+		url:    feedURL,
+		token:  token,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (f *RESTFeed) Name() string {
+	return "rest:" + f.url
+}
+
+func (f *RESTFeed) Fetch(ctx context.Context, since int64, pageToken string) ([]*pb.Product, string, error) {
+	u, err := url.Parse(f.url)
+	if err != nil {
+		return nil, "", fmt.Errorf("feed url: %w", err)
+	}
+	query := u.Query()
+	query.Set("updated_since", strconv.FormatInt(since, 10))
+	if pageToken != "" {
+		query.Set("page_token", pageToken)
+	}
+	u.RawQuery = query.Encode()
+
+	body, _, err := get(ctx, f.client, "feed", u.String(), func(r *http.Request) {
+		if f.token != "" {
+			r.Header.Set("Authorization", "Bearer "+f.token)
+		}
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var page struct {
+		Products      []json.RawMessage `json:"products"`
+		NextPageToken string            `json:"next_page_token"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, "", fmt.Errorf("feed: %w", err)
+	}
+	unmarshal := protojson.UnmarshalOptions{DiscardUnknown: true}
+	products := make([]*pb.Product, 0, len(page.Products))
+	for i, raw := range page.Products {
+		p := &pb.Product{}
+		if err := unmarshal.Unmarshal(raw, p); err != nil {
+			return nil, "", fmt.Errorf("feed: product %d: %w", i, err)
+		}
+		products = append(products, p)
+	}
+	return products, page.NextPageToken, nil
+}
@@ -0,0 +1,323 @@
+package catalogsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+)
+
+const (
+	shopifyAPIVersion = "2024-07"
+	shopifyPageSize   = 250
+	// shopifyMaxAttempts bounds the tries of a rate-limited call.
+	shopifyMaxAttempts = 5
+	// The REST API admits calls from a leaky bucket that drains two calls
+	// a second. Calls pause when fewer than shopifyCallHeadroom are left.
+	shopifyCallHeadroom = 4
+	shopifyCallDrain    = time.Second
+	// shopifyRetryAfter is the wait when a 429 names none.
+	shopifyRetryAfter = 2 * time.Second
+)
+
+// shopifyNextLink finds the next page's URL in a Link header.
+var shopifyNextLink = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// Shopify reads a shop's products through the Admin REST API. Variants
+// become sizes, named by the variant's Size option (or its first option),
+// and the first variant's price and compare-at price are the product's.
+// Shopify has no category tree: products are put in the category
+// Collections maps one of their collections to, and otherwise keep the
+// catalog's. The product type is kept as the product_type attribute.
+//
+// Calls slow down as the shop's rate limit bucket fills and are retried
+// when Shopify turns them away.
+type Shopify struct {
+	shop    string
+	token   string
+	client  *http.Client
+	baseURL string
+
+	// Collections maps collection handles to categories. A product in
+	// several mapped collections takes the first handle's in sort order.
+	Collections map[string]*pb.ProductCategory
+
+	mu sync.Mutex
+	// categories holds the category of each product in a mapped
+	// collection, by product id, as of the latest sync's first page.
+	categories map[int64]*pb.ProductCategory
+}
+
+// NewShopify reads the shop, e.g. "acme" for acme.myshopify.com, with an
+// Admin API access token allowed read_products and read_inventory.
+func NewShopify(shop, accessToken string) *Shopify {
+	return &Shopify{
+		shop:    shop,
+		token:   accessToken,
+		client:  &http.Client{Timeout: requestTimeout},
+		baseURL: "https://" + shop + ".myshopify.com/admin/api/" + shopifyAPIVersion,
+	}
+}
+
+func (s *Shopify) Name() string {
+	return "shopify:" + s.shop
+}
+
+// get calls the Admin API, pausing when the call limit header shows the
+// bucket nearly full and retrying 429s after their Retry-After.
+func (s *Shopify) get(ctx context.Context, path string, query url.Values) ([]byte, http.Header, error) {
+	for attempt := 1; ; attempt++ {
+		body, header, err := get(ctx, s.client, "shopify", s.baseURL+path+"?"+query.Encode(), func(r *http.Request) {
+			r.Header.Set("X-Shopify-Access-Token", s.token)
+		})
+		var status *statusError
+		if errors.As(err, &status) && status.code == http.StatusTooManyRequests && attempt < shopifyMaxAttempts {
+			wait := shopifyRetryAfter
+			if seconds, err := strconv.ParseFloat(status.header.Get("Retry-After"), 64); err == nil {
+				wait = time.Duration(seconds * float64(time.Second))
+			}
+			if err := sleep(ctx, wait); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// X-Shopify-Shop-Api-Call-Limit is "<used>/<size>"
+		used, size, _ := strings.Cut(header.Get("X-Shopify-Shop-Api-Call-Limit"), "/")
+		u, errUsed := strconv.Atoi(used)
+		n, errSize := strconv.Atoi(size)
+		if errUsed == nil && errSize == nil && n-u < shopifyCallHeadroom {
+			if err := sleep(ctx, shopifyCallDrain); err != nil {
+				return nil, nil, err
+			}
+		}
+		return body, header, nil
+	}
+}
+
+// nextPage returns the page_info of the page after the one whose headers
+// are given, or "" on the last page.
+func nextPage(header http.Header) string {
+	if link := shopifyNextLink.FindStringSubmatch(header.Get("Link")); link != nil {
+		if u, err := url.Parse(link[1]); err == nil {
+			return u.Query().Get("page_info")
+		}
+	}
+	return ""
+}
+
+type shopifyProduct struct {
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	BodyHTML    string `json:"body_html"`
+	Vendor      string `json:"vendor"`
+	ProductType string `json:"product_type"`
+	Tags        string `json:"tags"`
+	UpdatedAt   string `json:"updated_at"`
+	Options     []struct {
+		Name     string `json:"name"`
+		Position int    `json:"position"`
+	} `json:"options"`
+	Variants []struct {
+		SKU               string  `json:"sku"`
+		Price             string  `json:"price"`
+		CompareAtPrice    *string `json:"compare_at_price"`
+		InventoryQuantity int32   `json:"inventory_quantity"`
+		Option1           string  `json:"option1"`
+		Option2           string  `json:"option2"`
+		Option3           string  `json:"option3"`
+	} `json:"variants"`
+	Images []struct {
+		Src string `json:"src"`
+	} `json:"images"`
+}
+
+// Fetch pages through the products updated since, refreshing collection
+// membership on the first page.
+func (s *Shopify) Fetch(ctx context.Context, since int64, pageToken string) ([]*pb.Product, string, error) {
+	// Later pages take no filters besides the page size
+	query := url.Values{"limit": {strconv.Itoa(shopifyPageSize)}}
+	if pageToken != "" {
+		query.Set("page_info", pageToken)
+	} else {
+		if err := s.loadCollections(ctx); err != nil {
+			return nil, "", err
+		}
+		if since > 0 {
+			query.Set("updated_at_min", time.UnixMilli(since).UTC().Format(time.RFC3339))
+		}
+	}
+
+	body, header, err := s.get(ctx, "/products.json", query)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var page struct {
+		Products []shopifyProduct `json:"products"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, "", fmt.Errorf("shopify: %w", err)
+	}
+	products := make([]*pb.Product, 0, len(page.Products))
+	for _, sp := range page.Products {
+		p, err := s.product(sp)
+		if err != nil {
+			return nil, "", fmt.Errorf("shopify: product %d: %w", sp.ID, err)
+		}
+		products = append(products, p)
+	}
+	return products, nextPage(header), nil
+}
+
+// loadCollections looks up which products are in the mapped collections.
+func (s *Shopify) loadCollections(ctx context.Context) error {
+	categories := map[int64]*pb.ProductCategory{}
+	for _, handle := range slices.Sorted(maps.Keys(s.Collections)) {
+		id, err := s.collectionID(ctx, handle)
+		if err != nil {
+			return err
+		}
+		query := url.Values{"limit": {strconv.Itoa(shopifyPageSize)}, "fields": {"id"}}
+		for {
+			body, header, err := s.get(ctx, "/collections/"+strconv.FormatInt(id, 10)+"/products.json", query)
+			if err != nil {
+				return err
+			}
+			var page struct {
+				Products []struct {
+					ID int64 `json:"id"`
+				} `json:"products"`
+			}
+			if err := json.Unmarshal(body, &page); err != nil {
+				return fmt.Errorf("shopify: collection %s: %w", handle, err)
+			}
+			for _, p := range page.Products {
+				if _, ok := categories[p.ID]; !ok {
+					categories[p.ID] = s.Collections[handle]
+				}
+			}
+			next := nextPage(header)
+			if next == "" {
+				break
+			}
+			query = url.Values{"limit": {strconv.Itoa(shopifyPageSize)}, "page_info": {next}}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.categories = categories
+	return nil
+}
+
+// collectionID finds a custom or smart collection by handle.
+func (s *Shopify) collectionID(ctx context.Context, handle string) (int64, error) {
+	for _, kind := range []string{"custom_collections", "smart_collections"} {
+		body, _, err := s.get(ctx, "/"+kind+".json", url.Values{"handle": {handle}, "fields": {"id"}})
+		if err != nil {
+			return 0, err
+		}
+		var found map[string][]struct {
+			ID int64 `json:"id"`
+		}
+		if err := json.Unmarshal(body, &found); err != nil {
+			return 0, fmt.Errorf("shopify: collection %s: %w", handle, err)
+		}
+		if collections := found[kind]; len(collections) > 0 {
+			return collections[0].ID, nil
+		}
+	}
+	return 0, fmt.Errorf("shopify: no collection has handle %q", handle)
+}
+
+// product maps a Shopify product to ours. Products in no mapped
+// collection, or seen before collections were first loaded, have no
+// category, so keep the catalog's.
+func (s *Shopify) product(sp shopifyProduct) (*pb.Product, error) {
+	p := &pb.Product{
+		ExternalId:  strconv.FormatInt(sp.ID, 10),
+		Name:        sp.Title,
+		Brand:       sp.Vendor,
+		Description: sp.BodyHTML,
+		Tags:        splitTags(sp.Tags),
+	}
+	s.mu.Lock()
+	p.Category = s.categories[sp.ID]
+	s.mu.Unlock()
+	if sp.ProductType != "" {
+		p.Attributes = map[string]string{"product_type": sp.ProductType}
+	}
+	if sp.UpdatedAt != "" {
+		updated, err := time.Parse(time.RFC3339, sp.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("updated_at: %w", err)
+		}
+		p.UpdatedAt = updated.UnixMilli()
+	}
+	for _, image := range sp.Images {
+		p.Images = append(p.Images, image.Src)
+	}
+
+	sizeOption := 1
+	for _, option := range sp.Options {
+		if strings.EqualFold(option.Name, "size") {
+			sizeOption = option.Position
+		}
+	}
+	for i, v := range sp.Variants {
+		if i == 0 {
+			var err error
+			if p.Price, err = parseNumber(v.Price, parseFloat); err != nil {
+				return nil, fmt.Errorf("price: %w", err)
+			}
+			if v.CompareAtPrice != nil {
+				if p.OriginalPrice, err = parseNumber(*v.CompareAtPrice, parseFloat); err != nil {
+					return nil, fmt.Errorf("compare_at_price: %w", err)
+				}
+			}
+		}
+		if v.SKU == "" {
+			continue
+		}
+		size := v.Option1
+		switch sizeOption {
+		case 2:
+			size = v.Option2
+		case 3:
+			size = v.Option3
+		}
+		p.Sizes = append(p.Sizes, &pb.ProductSize{
+			Sku:     v.SKU,
+			Size:    size,
+			Stock:   v.InventoryQuantity,
+			InStock: v.InventoryQuantity > 0,
+		})
+	}
+	return p, nil
+}
+
+// splitTags splits Shopify's comma-separated tags.
+func splitTags(tags string) []string {
+	var out []string
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+package catalogsync
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// ShopifyWebhook receives a shop's product webhooks and applies each
+// change as it happens, between the syncer's scheduled runs. Created and
+// updated products are imported; deleted ones are archived. Deliveries
+// that fail get a 500, which has Shopify retry them.
+type ShopifyWebhook struct {
+	shop   *Shopify
+	syncer *Syncer
+	secret []byte
+}
+
+// NewShopifyWebhook accepts deliveries signed with secret, the app's
+// client secret, for syncer, which syncs shop.
+func NewShopifyWebhook(shop *Shopify, syncer *Syncer, secret string) *ShopifyWebhook {
+	return &ShopifyWebhook{shop: shop, syncer: syncer, secret: []byte(secret)}
+}
+
+func (h *ShopifyWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxResponseSize))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if !h.verify(body, r.Header.Get("X-Shopify-Hmac-Sha256")) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	topic := r.Header.Get("X-Shopify-Topic")
+	switch topic {
+	case "products/create", "products/update":
+		var sp shopifyProduct
+		if err := json.Unmarshal(body, &sp); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		p, err := h.shop.product(sp)
+		if err == nil {
+			err = h.syncer.Import(r.Context(), p)
+		}
+		if err != nil {
+			log.Printf("sync: %s: webhook %s: product %d: %v", h.shop.Name(), topic, sp.ID, err)
+			http.Error(w, "import failed", http.StatusInternalServerError)
+			return
+		}
+	case "products/delete":
+		var deleted struct {
+			ID int64 `json:"id"`
+		}
+		if err := json.Unmarshal(body, &deleted); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if err := h.syncer.Archive(r.Context(), strconv.FormatInt(deleted.ID, 10)); err != nil {
+			log.Printf("sync: %s: webhook %s: product %d: %v", h.shop.Name(), topic, deleted.ID, err)
+			http.Error(w, "archive failed", http.StatusInternalServerError)
+			return
+		}
+	}
+	// Topics the syncer doesn't follow are acknowledged so Shopify
+	// doesn't retry them
+	w.WriteHeader(http.StatusOK)
+}
+
+// verify checks the base64 HMAC-SHA256 of the body Shopify signs
+// deliveries with.
+func (h *ShopifyWebhook) verify(body []byte, signature string) bool {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
@@ -0,0 +1,300 @@
+// Package catalogsync keeps the catalog in step with an external product
+// information system. A Syncer pulls the products a Source changed since
+// its last successful run, matches them to catalog products by external
+// id, and creates or updates them under a conflict Policy. Runs and their
+// cursors are recorded by the Store, which also makes sure only one
+// replica syncs a source at a time.
+package catalogsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+const (
+	defaultInterval = 15 * time.Minute
+	// lease is how long a run holds its source without renewing it; runs
+	// renew it after every page.
+	lease = 10 * time.Minute
+	// finishTimeout bounds recording a run's end after ctx is cancelled.
+	finishTimeout = 10 * time.Second
+)
+
+// Source is an external catalog.
+type Source interface {
+	// Name identifies the source in run history.
+	Name() string
+	// Fetch returns a page of the products changed at or after since, in
+	// Unix milliseconds (0 for all of them), and the token of the next
+	// page, "" after the last. Products carry the source's id for them as
+	// ExternalId and when the source last changed them as UpdatedAt.
+	Fetch(ctx context.Context, since int64, pageToken string) ([]*pb.Product, string, error)
+}
+
+// Store holds the catalog and the sync run history.
+type Store interface {
+	ResolveProduct(ctx context.Context, kind, value string) (*pb.Product, string, error)
+	CreateProduct(ctx context.Context, p *pb.Product) error
+	UpdateProduct(ctx context.Context, p *pb.Product) ([]*pb.FieldChange, error)
+	UpdateStock(ctx context.Context, sku string, stock int32) error
+	AddProductSize(ctx context.Context, productID string, size *pb.ProductSize) error
+	SetProductsArchived(ctx context.Context, ids []string, archived bool) (int, error)
+
+	StartSyncRun(ctx context.Context, source string, lease time.Duration) (*pb.SyncRun, int64, error)
+	SaveSyncProgress(ctx context.Context, run *pb.SyncRun, lease time.Duration) error
+	FinishSyncRun(ctx context.Context, run *pb.SyncRun) error
+	LastSyncedAt(ctx context.Context, source string) (int64, error)
+}
+
+// Policy decides whether a source's copy of a product replaces the
+// catalog's.
+type Policy int
+
+const (
+	// NewestWins keeps the catalog's copy when it changed after the
+	// source's did. Products the source gives no time for are taken.
+	NewestWins Policy = iota
+	// SourceWins always takes the source's copy.
+	SourceWins
+	// LocalWins keeps products edited in the catalog since the source's
+	// last successful sync.
+	LocalWins
+)
+
+// ParsePolicy reads a policy name: newest-wins, source-wins or
+// local-wins.
+func ParsePolicy(name string) (Policy, error) {
+	switch name {
+	case "newest-wins":
+		return NewestWins, nil
+	case "source-wins":
+		return SourceWins, nil
+	case "local-wins":
+		return LocalWins, nil
+	default:
+		return 0, fmt.Errorf("unknown sync conflict policy %q (want newest-wins, source-wins or local-wins)", name)
+	}
+}
+
+// Syncer periodically syncs one source into the catalog. Sources that push
+// their changes can also hand single products to Import and Archive.
+type Syncer struct {
+	store  Store
+	source Source
+
+	Interval time.Duration
+	Policy   Policy
+	// TenantID, if set, is given to every product synced.
+	TenantID string
+}
+
+func NewSyncer(store Store, source Source) *Syncer {
+	return &Syncer{
+		store:    store,
+		source:   source,
+		Interval: defaultInterval,
+	}
+}
+
+// Source returns the source the syncer reads.
+func (s *Syncer) Source() Source {
+	return s.source
+}
+
+// Run syncs the source until ctx is cancelled.
+func (s *Syncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.SyncOnce(ctx); err != nil {
+			log.Printf("sync: %s: %v", s.source.Name(), err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncOnce runs one sync of the source, unless another replica is syncing
+// it, and returns the error that failed the run, if any. Product failures
+// are counted and logged but don't fail the run.
+func (s *Syncer) SyncOnce(ctx context.Context) error {
+	return s.run(ctx, false)
+}
+
+// SyncAll is SyncOnce reading every product the source has rather than
+// those changed since the last run, for a first import or to repair drift.
+func (s *Syncer) SyncAll(ctx context.Context) error {
+	return s.run(ctx, true)
+}
+
+func (s *Syncer) run(ctx context.Context, full bool) error {
+	run, lastSynced, err := s.store.StartSyncRun(ctx, s.source.Name(), lease)
+	if err != nil || run == nil {
+		return err
+	}
+	if full {
+		run.CursorFrom = 0
+	}
+
+	runErr := s.sync(ctx, run, lastSynced)
+	run.Status = pb.SyncRunStatus_SYNC_SUCCEEDED
+	if runErr != nil {
+		run.Status = pb.SyncRunStatus_SYNC_FAILED
+		run.Error = runErr.Error()
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancel()
+	if err := s.store.FinishSyncRun(ctx, run); err != nil {
+		return errors.Join(runErr, err)
+	}
+	log.Printf("sync: %s: %s, %d created, %d updated, %d unchanged, %d skipped, %d failed",
+		run.Source, run.Status, run.Created, run.Updated, run.Unchanged, run.Skipped, run.Failed)
+	return runErr
+}
+
+func (s *Syncer) sync(ctx context.Context, run *pb.SyncRun, lastSynced int64) error {
+	pageToken := ""
+	for {
+		products, next, err := s.source.Fetch(ctx, run.CursorFrom, pageToken)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range products {
+			run.CursorTo = max(run.CursorTo, p.UpdatedAt)
+			if err := s.apply(ctx, run, p, lastSynced); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				run.Failed++
+				if run.Error == "" {
+					run.Error = fmt.Sprintf("product %s: %v", p.ExternalId, err)
+				}
+				log.Printf("sync: %s: product %s: %v", run.Source, p.ExternalId, err)
+			}
+		}
+
+		if err := s.store.SaveSyncProgress(ctx, run, lease); err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		pageToken = next
+	}
+}
+
+// Import applies one product the source pushed, under the same policy as
+// a run. It doesn't take part in run history: under LocalWins, the
+// product counts as edited in the catalog until a run finishes after it.
+func (s *Syncer) Import(ctx context.Context, p *pb.Product) error {
+	lastSynced, err := s.store.LastSyncedAt(ctx, s.source.Name())
+	if err != nil {
+		return err
+	}
+	return s.apply(ctx, &pb.SyncRun{Source: s.source.Name()}, p, lastSynced)
+}
+
+// Archive archives the catalog's copy of a product the source deleted.
+// Archiving rather than deleting keeps the orders that refer to it.
+// Products the catalog doesn't have are ignored.
+func (s *Syncer) Archive(ctx context.Context, externalID string) error {
+	existing, _, err := s.store.ResolveProduct(ctx, repository.IdentifierExternalID, externalID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.store.SetProductsArchived(ctx, []string{existing.Id}, true)
+	return err
+}
+
+// apply creates or updates the catalog's copy of p.
+func (s *Syncer) apply(ctx context.Context, run *pb.SyncRun, p *pb.Product, lastSynced int64) error {
+	if p.ExternalId == "" {
+		return errors.New("no external id")
+	}
+	if s.TenantID != "" {
+		p.TenantId = s.TenantID
+	}
+	modified := p.UpdatedAt
+
+	existing, _, err := s.store.ResolveProduct(ctx, repository.IdentifierExternalID, p.ExternalId)
+	if errors.Is(err, repository.ErrNotFound) {
+		p.Id = uuid.NewString()
+		if err := s.store.CreateProduct(ctx, p); err != nil {
+			return err
+		}
+		run.Created++
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case s.Policy == NewestWins && modified != 0 && modified < existing.UpdatedAt,
+		s.Policy == LocalWins && existing.UpdatedAt > lastSynced:
+		run.Skipped++
+		return nil
+	}
+
+	p.Id = existing.Id
+	changes, err := s.store.UpdateProduct(ctx, p)
+	if err != nil {
+		return err
+	}
+	sizesChanged, err := s.syncSizes(ctx, existing, p.Sizes)
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 || sizesChanged {
+		run.Updated++
+	} else {
+		run.Unchanged++
+	}
+	return nil
+}
+
+// syncSizes brings the stock of the product's sizes in line with the
+// source's and adds the sizes it lacks. Sizes the source dropped are kept,
+// as orders and reservations may refer to them.
+func (s *Syncer) syncSizes(ctx context.Context, existing *pb.Product, sizes []*pb.ProductSize) (bool, error) {
+	stock := make(map[string]int32, len(existing.Sizes))
+	for _, size := range existing.Sizes {
+		stock[size.Sku] = size.Stock
+	}
+
+	changed := false
+	for _, size := range sizes {
+		current, ok := stock[size.Sku]
+		switch {
+		case !ok:
+			if err := s.store.AddProductSize(ctx, existing.Id, size); err != nil {
+				return changed, err
+			}
+		case current != size.Stock:
+			if err := s.store.UpdateStock(ctx, size.Sku, size.Stock); err != nil {
+				return changed, err
+			}
+		default:
+			continue
+		}
+		changed = true
+	}
+	return changed, nil
+}
@@ -0,0 +1,30 @@
+// Package commission works out what the marketplace keeps of what its
+// sellers sell and what it pays them out.
+package commission
+
+import "math"
+
+// RateCard sets the share of a sale, from 0 to 1, the marketplace keeps as
+// commission.
+type RateCard struct {
+	// Rate applies to sales whose category has no rate in Categories.
+	Rate       float64
+	Categories map[string]float64
+}
+
+// RateFor returns the commission rate of a main category.
+func (c *RateCard) RateFor(category string) float64 {
+	if rate, ok := c.Categories[category]; ok {
+		return rate
+	}
+	return c.Rate
+}
+
+// Split divides a sale of amount, in the currency's minor units, into the
+// marketplace's commission, rounded to the nearest unit, and the seller's
+// payout.
+func (c *RateCard) Split(category string, amount int64) (rate float64, commission, payout int64) {
+	rate = c.RateFor(category)
+	commission = int64(math.Round(float64(amount) * rate))
+	return rate, commission, amount - commission
+}
@@ -0,0 +1,479 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds the service settings, read from the environment with
+// defaults suited to local development.
+type Config struct {
+	// StorageBackend selects where products live: "neo4j" (the default,
+	// with every feature) or "postgres" (product operations only).
+	StorageBackend string
+	PostgresURL    string
+
+	// GraphProfile tunes the driver and Cypher for the graph database
+	// behind NEO4J_URI: "neo4j" (self-hosted), "aura" or "memgraph".
+	GraphProfile  string
+	Neo4jURI      string
+	Neo4jUsername string
+	// Neo4jPassword has no default: Neo4j and Aura refuse to start without
+	// one. `make run` and the dev shell set the local database's.
+	Neo4jPassword string
+	// Neo4jDatabase selects a database other than the server default.
+	Neo4jDatabase string
+	// Neo4jEncryption upgrades a plain URI to TLS: "tls" or
+	// "tls-self-signed". Aura URIs are always encrypted.
+	Neo4jEncryption string
+
+	// Text search runs against a fulltext index over the product fields in
+	// SearchFields (comma separated) with the SearchAnalyzer, and, for
+	// languages in SearchLocaleAnalyzers ("de=german,fr=french"), one over
+	// their translations with that analyzer. cmd/migrate builds them.
+	// SearchFieldWeights ("name=3,brand=2") ranks matches in heavier
+	// fields higher; unlisted fields weigh 1.
+	SearchAnalyzer        string
+	SearchFields          string
+	SearchLocaleAnalyzers string
+	SearchFieldWeights    string
+
+	ListenAddr string
+
+	// IDGenerator makes the ids of products and SKUs created without one:
+	// "uuid7" (the default), "ulid" or "snowflake". Snowflakes need a
+	// SnowflakeNode from 0 to 1023 that no other replica uses.
+	IDGenerator   string
+	SnowflakeNode int
+
+	// PageTokenSecret signs pagination tokens. Replicas behind the same
+	// load balancer must share it or tokens will not survive a hop.
+	PageTokenSecret string
+
+	// WebhookSecretKey seals webhook signing secrets at rest: 32 random
+	// bytes, base64 encoded. Without it webhooks can't be registered or
+	// delivered.
+	WebhookSecretKey string
+
+	// AuthKeyring is the path of the API key and role file. Empty disables
+	// authentication, which is only meant for local development.
+	AuthKeyring string
+
+	// Reflection registers the gRPC reflection service so tools like
+	// grpcurl can discover the API. It exposes the whole schema, so it's
+	// off unless enabled, as `make run` and the dev shell do.
+	Reflection bool
+
+	// Largest request and response messages, in bytes. Oversized messages
+	// fail with ResourceExhausted before reaching a handler.
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+
+	// The Neo4j circuit breaker opens after BreakerThreshold consecutive
+	// connection failures and probes again after BreakerCooldown.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// QueryCacheTTL keeps the results of hot read queries, such as text
+	// searches and deals, up to QueryCacheMaxEntries of them, for that
+	// long. Zero disables the cache.
+	QueryCacheTTL        time.Duration
+	QueryCacheMaxEntries int
+
+	// Cypher queries run for callers, e.g. generated searches, may walk at
+	// most QueryMaxDepth hops of a variable-length pattern and return at
+	// most QueryMaxRows rows, or QueryMaxStreamRows when streamed; queries
+	// Neo4j estimates will touch more than QueryMaxCost rows at any step
+	// are rejected (zero doesn't check). QueryMaxTraversals of them,
+	// subgraph exports included, run at once. Results estimated at more
+	// than QueryMaxResultBytes fail, or are streamed in chunks that size.
+	QueryMaxDepth       int
+	QueryMaxRows        int
+	QueryMaxStreamRows  int
+	QueryMaxResultBytes int
+	QueryMaxCost        float64
+	QueryMaxTraversals  int
+
+	// WarmUpProducts popular products are read into the caches on startup,
+	// before health checks report the server SERVING. Zero skips them.
+	WarmUpProducts int
+
+	// CatalogOverviewTTL is how long a catalog overview is reused before
+	// it's computed again. Zero computes every request's.
+	CatalogOverviewTTL time.Duration
+
+	// OutfitCategories are the main categories, comma separated, whose
+	// products can be linked as going together and suggested as outfits.
+	OutfitCategories string
+
+	// Each tenant may have TenantMaxInFlight calls running on Neo4j at
+	// once; more wait up to TenantQueueWait for a slot. Tenants that used
+	// TenantNeo4jBudget of query time in the current TenantQuotaWindow are
+	// turned away until the next. Zero disables either limit.
+	TenantMaxInFlight int
+	TenantQueueWait   time.Duration
+	TenantNeo4jBudget time.Duration
+	TenantQuotaWindow time.Duration
+
+	// ReservationTTL is how long a stock reservation holds when the caller
+	// doesn't say; the expiry sweep runs every ReservationSweepInterval.
+	ReservationTTL           time.Duration
+	ReservationSweepInterval time.Duration
+
+	// SyncChanges serves product changes once they are SyncSettle old, so
+	// a write that commits after later ones isn't passed over.
+	SyncSettle time.Duration
+
+	// PaymentProvider takes order payments: "stripe" or "mock", which
+	// approves everything. Empty disables PlaceOrder.
+	PaymentProvider string
+	StripeSecretKey string
+
+	// ShippingProvider quotes shipping: "flat" charges ShippingFlatBase
+	// plus ShippingFlatPerKg per started kilogram, both in minor units of
+	// ShippingCurrency, to ShippingCountries (comma separated; empty means
+	// everywhere). Empty disables EstimateShipping.
+	ShippingProvider  string
+	ShippingFlatBase  int
+	ShippingFlatPerKg int
+	ShippingCurrency  string
+	ShippingCountries string
+
+	// TaxProvider calculates tax on carts and orders: "flat" charges
+	// TaxRate, or the rate TaxClassRates ("reduced=0.07,zero=0") gives the
+	// product's tax class; "taxjar" and "avalara" call those services for
+	// goods shipped from TaxOrigin. Empty charges no tax.
+	TaxProvider        string
+	TaxRate            float64
+	TaxClassRates      string
+	TaxOriginCountry   string
+	TaxOriginRegion    string
+	TaxOriginPostal    string
+	TaxOriginCity      string
+	TaxJarAPIKey       string
+	AvalaraAccountID   string
+	AvalaraLicenseKey  string
+	AvalaraCompanyCode string
+
+	// Orders are scored for fraud before payment: RiskVelocityWeight when
+	// the customer placed RiskVelocityLimit orders within
+	// RiskVelocityWindow, RiskRegionWeight per billing or client country
+	// differing from the shipping country, plus the score of the service
+	// at RiskScorerURL, if set. Scores from RiskReviewThreshold hold the
+	// order for review and from RiskDeclineThreshold decline it; 0
+	// disables either.
+	RiskReviewThreshold  float64
+	RiskDeclineThreshold float64
+	RiskVelocityWindow   time.Duration
+	RiskVelocityLimit    int
+	RiskVelocityWeight   float64
+	RiskRegionWeight     float64
+	RiskScorerURL        string
+	RiskScorerToken      string
+
+	// New questions and answers are screened by the moderation service at
+	// ModerationURL, the one SAFETY_MODERATION_URL points the orchestrator
+	// at, when set. Flagged posts wait for a moderator; clean ones are
+	// approved straight away when ModerationAutoApprove is set.
+	ModerationURL         string
+	ModerationTimeout     time.Duration
+	ModerationAutoApprove bool
+
+	// Placed orders earn LoyaltyEarnRate points per currency unit, or the
+	// rate LoyaltyCategoryRates ("Shoes=2,Accessories=0.5") gives the
+	// product's main category. LoyaltyTiers ("silver=1000,gold=5000")
+	// names the tiers and the lifetime points that reach them. A rate of
+	// 0 with no category rates disables loyalty.
+	LoyaltyEarnRate      float64
+	LoyaltyCategoryRates string
+	LoyaltyTiers         string
+
+	// Lines sold by marketplace sellers settle with the marketplace keeping
+	// CommissionRate of them, or the rate CommissionCategoryRates
+	// ("Shoes=0.12,Accessories=0.2") gives the product's main category.
+	CommissionRate          float64
+	CommissionCategoryRates string
+
+	// SyncSource pulls the products an external PIM changed every
+	// SyncInterval: "rest" from the JSON feed at SyncFeedURL, "csv" from
+	// the files dropped in SyncCSVDir, "shopify" and "commercetools" from
+	// those platforms' APIs. SyncConflictPolicy (newest-wins, source-wins
+	// or local-wins) settles products changed on both sides; SyncTenant,
+	// if set, owns the synced products. Empty disables sync.
+	//
+	// ShopifyCollectionCategories ("summer-shoes=Shoes/Sandals,...") puts
+	// the products of Shopify collections in catalog categories, given as
+	// main/sub/specific. SyncWebhookAddr, if set, serves Shopify's product
+	// webhooks at /webhooks/shopify, signed with ShopifyWebhookSecret, so
+	// changes arrive between runs.
+	SyncSource                  string
+	SyncInterval                time.Duration
+	SyncConflictPolicy          string
+	SyncTenant                  string
+	SyncFeedURL                 string
+	SyncFeedToken               string
+	SyncCSVDir                  string
+	ShopifyShop                 string
+	ShopifyAccessToken          string
+	ShopifyCollectionCategories string
+	ShopifyWebhookSecret        string
+	SyncWebhookAddr             string
+	CommercetoolsProject        string
+	CommercetoolsRegion         string
+	CommercetoolsClientID       string
+	CommercetoolsClientSecret   string
+	CommercetoolsLocale         string
+
+	// FeedsConfig names a JSON file of product feeds (see feeds.Load) to
+	// render every FeedsInterval into BlobDir. Empty disables feeds.
+	FeedsConfig   string
+	FeedsInterval time.Duration
+
+	// SitemapProductURL ("https://shop.example.com/p/{slug}") turns on
+	// sitemaps of the product pages, and SitemapCategoryURL
+	// ("https://shop.example.com/c/{path}") of the category pages,
+	// rendered every SitemapInterval into BlobDir. SitemapBaseURL is where
+	// the storefront serves /sitemaps/; it defaults to the product URL's
+	// origin. SitemapTenant, if set, limits them to its products.
+	SitemapProductURL  string
+	SitemapCategoryURL string
+	SitemapBaseURL     string
+	SitemapTenant      string
+	SitemapInterval    time.Duration
+
+	// BlobDir holds generated files. BlobAddr, if set, serves them:
+	// feeds at /feeds/<name>.<format> for the channels to fetch, and
+	// sitemaps at /sitemaps/sitemap.xml.
+	BlobDir  string
+	BlobAddr string
+
+	// LowStockThreshold is the stock at or below which a size raises
+	// stock.low; LowStockHorizon raises it as well when the imported
+	// forecast has the size selling out within that long.
+	LowStockThreshold int
+	LowStockHorizon   time.Duration
+
+	// MetricsAddr serves expvar metrics at /debug/vars. Empty disables it.
+	MetricsAddr string
+
+	// EnableTestReset serves ResetAndSeed, which wipes the graph and loads
+	// a fixture set, for integration and end-to-end test environments.
+	// Never set it where the data matters.
+	EnableTestReset bool
+
+	// AccessLogPath, if set, records every RPC there as a JSON line, or on
+	// stdout when it's "-". The file rotates at AccessLogMaxBytes, keeping
+	// AccessLogBackups old ones. AccessLogSampleRate of the calls, from 0
+	// to 1, also record their request with personal data redacted.
+	AccessLogPath       string
+	AccessLogMaxBytes   int
+	AccessLogBackups    int
+	AccessLogSampleRate float64
+
+	// OTLPEndpoint is the OpenTelemetry collector traces are exported to
+	// over gRPC. Empty disables export.
+	OTLPEndpoint string
+}
+
+func Load() Config {
+	return Config{
+		StorageBackend:  getEnv("STORAGE_BACKEND", "neo4j"),
+		PostgresURL:     getEnv("POSTGRES_URL", "postgres://localhost:5432/ecom"),
+		GraphProfile:    getEnv("GRAPH_BACKEND_PROFILE", "neo4j"),
+		Neo4jURI:        getEnv("NEO4J_URI", "bolt://localhost:7687"),
+		Neo4jUsername:   getEnv("NEO4J_USERNAME", "neo4j"),
+		Neo4jPassword:   os.Getenv("NEO4J_PASSWORD"),
+		Neo4jDatabase:   os.Getenv("NEO4J_DATABASE"),
+		Neo4jEncryption: os.Getenv("NEO4J_ENCRYPTION"),
+
+		SearchAnalyzer:        getEnv("SEARCH_ANALYZER", "standard-no-stop-words"),
+		SearchFields:          getEnv("SEARCH_FIELDS", "name,description,brand"),
+		SearchLocaleAnalyzers: os.Getenv("SEARCH_LOCALE_ANALYZERS"),
+		SearchFieldWeights:    getEnv("SEARCH_FIELD_WEIGHTS", "name=3,brand=2,description=1"),
+
+		ListenAddr:       getEnv("GRAPH_SERVICE_ADDR", ":50051"),
+		PageTokenSecret:  os.Getenv("PAGE_TOKEN_SECRET"),
+		WebhookSecretKey: os.Getenv("WEBHOOK_SECRET_KEY"),
+		IDGenerator:      getEnv("ID_GENERATOR", "uuid7"),
+		SnowflakeNode:    getInt("SNOWFLAKE_NODE", 0),
+		AuthKeyring:      os.Getenv("AUTH_KEYRING"),
+		Reflection:       getBool("GRPC_REFLECTION", false),
+		MaxRecvMsgSize:   getInt("GRPC_MAX_RECV_BYTES", 4<<20),
+		MaxSendMsgSize:   getInt("GRPC_MAX_SEND_BYTES", 16<<20),
+
+		BreakerThreshold: getInt("NEO4J_BREAKER_THRESHOLD", 5),
+		BreakerCooldown:  getDuration("NEO4J_BREAKER_COOLDOWN", 10*time.Second),
+
+		QueryCacheTTL:        getDuration("QUERY_CACHE_TTL", 0),
+		QueryCacheMaxEntries: getInt("QUERY_CACHE_MAX_ENTRIES", 1000),
+
+		QueryMaxDepth:       getInt("QUERY_MAX_DEPTH", 3),
+		QueryMaxRows:        getInt("QUERY_MAX_ROWS", 100),
+		QueryMaxStreamRows:  getInt("QUERY_MAX_STREAM_ROWS", 10000),
+		QueryMaxResultBytes: getInt("QUERY_MAX_RESULT_BYTES", 8<<20),
+		QueryMaxCost:        getFloat("QUERY_MAX_COST", 0),
+		QueryMaxTraversals:  getInt("QUERY_MAX_TRAVERSALS", 8),
+
+		WarmUpProducts: getInt("WARMUP_PRODUCTS", 100),
+
+		CatalogOverviewTTL: getDuration("CATALOG_OVERVIEW_TTL", 5*time.Minute),
+
+		OutfitCategories: getEnv("OUTFIT_CATEGORIES", "Apparel,Clothing,Footwear,Accessories,Sportswear"),
+
+		TenantMaxInFlight: getInt("TENANT_MAX_IN_FLIGHT", 0),
+		TenantQueueWait:   getDuration("TENANT_QUEUE_WAIT", 200*time.Millisecond),
+		TenantNeo4jBudget: getDuration("TENANT_NEO4J_BUDGET", 0),
+		TenantQuotaWindow: getDuration("TENANT_QUOTA_WINDOW", time.Minute),
+
+		ReservationTTL:           getDuration("RESERVATION_TTL", 15*time.Minute),
+		ReservationSweepInterval: getDuration("RESERVATION_SWEEP_INTERVAL", 30*time.Second),
+
+		SyncSettle: getDuration("SYNC_SETTLE", 5*time.Second),
+
+		PaymentProvider: os.Getenv("PAYMENT_PROVIDER"),
+		StripeSecretKey: os.Getenv("STRIPE_SECRET_KEY"),
+
+		ShippingProvider:  os.Getenv("SHIPPING_PROVIDER"),
+		ShippingFlatBase:  getInt("SHIPPING_FLAT_BASE", 500),
+		ShippingFlatPerKg: getInt("SHIPPING_FLAT_PER_KG", 150),
+		ShippingCurrency:  getEnv("SHIPPING_CURRENCY", "USD"),
+		ShippingCountries: os.Getenv("SHIPPING_COUNTRIES"),
+
+		TaxProvider:        os.Getenv("TAX_PROVIDER"),
+		TaxRate:            getFloat("TAX_RATE", 0),
+		TaxClassRates:      os.Getenv("TAX_CLASS_RATES"),
+		TaxOriginCountry:   os.Getenv("TAX_ORIGIN_COUNTRY"),
+		TaxOriginRegion:    os.Getenv("TAX_ORIGIN_REGION"),
+		TaxOriginPostal:    os.Getenv("TAX_ORIGIN_POSTAL_CODE"),
+		TaxOriginCity:      os.Getenv("TAX_ORIGIN_CITY"),
+		TaxJarAPIKey:       os.Getenv("TAXJAR_API_KEY"),
+		AvalaraAccountID:   os.Getenv("AVALARA_ACCOUNT_ID"),
+		AvalaraLicenseKey:  os.Getenv("AVALARA_LICENSE_KEY"),
+		AvalaraCompanyCode: os.Getenv("AVALARA_COMPANY_CODE"),
+
+		RiskReviewThreshold:  getFloat("RISK_REVIEW_THRESHOLD", 0.5),
+		RiskDeclineThreshold: getFloat("RISK_DECLINE_THRESHOLD", 0.9),
+		RiskVelocityWindow:   getDuration("RISK_VELOCITY_WINDOW", time.Hour),
+		RiskVelocityLimit:    getInt("RISK_VELOCITY_LIMIT", 5),
+		RiskVelocityWeight:   getFloat("RISK_VELOCITY_WEIGHT", 0.6),
+		RiskRegionWeight:     getFloat("RISK_REGION_WEIGHT", 0.3),
+		RiskScorerURL:        os.Getenv("RISK_SCORER_URL"),
+		RiskScorerToken:      os.Getenv("RISK_SCORER_TOKEN"),
+
+		ModerationURL:         os.Getenv("MODERATION_URL"),
+		ModerationTimeout:     getDuration("MODERATION_TIMEOUT", 2*time.Second),
+		ModerationAutoApprove: getBool("MODERATION_AUTO_APPROVE", false),
+
+		LoyaltyEarnRate:      getFloat("LOYALTY_EARN_RATE", 1),
+		LoyaltyCategoryRates: os.Getenv("LOYALTY_CATEGORY_RATES"),
+		LoyaltyTiers:         getEnv("LOYALTY_TIERS", "silver=1000,gold=5000,platinum=20000"),
+
+		CommissionRate:          getFloat("COMMISSION_RATE", 0.1),
+		CommissionCategoryRates: os.Getenv("COMMISSION_CATEGORY_RATES"),
+
+		SyncSource:                  os.Getenv("SYNC_SOURCE"),
+		SyncInterval:                getDuration("SYNC_INTERVAL", 15*time.Minute),
+		SyncConflictPolicy:          getEnv("SYNC_CONFLICT_POLICY", "newest-wins"),
+		SyncTenant:                  os.Getenv("SYNC_TENANT"),
+		SyncFeedURL:                 os.Getenv("SYNC_FEED_URL"),
+		SyncFeedToken:               os.Getenv("SYNC_FEED_TOKEN"),
+		SyncCSVDir:                  os.Getenv("SYNC_CSV_DIR"),
+		ShopifyShop:                 os.Getenv("SHOPIFY_SHOP"),
+		ShopifyAccessToken:          os.Getenv("SHOPIFY_ACCESS_TOKEN"),
+		ShopifyCollectionCategories: os.Getenv("SHOPIFY_COLLECTION_CATEGORIES"),
+		ShopifyWebhookSecret:        os.Getenv("SHOPIFY_WEBHOOK_SECRET"),
+		SyncWebhookAddr:             os.Getenv("SYNC_WEBHOOK_ADDR"),
+		CommercetoolsProject:        os.Getenv("COMMERCETOOLS_PROJECT"),
+		CommercetoolsRegion:         getEnv("COMMERCETOOLS_REGION", "europe-west1.gcp"),
+		CommercetoolsClientID:       os.Getenv("COMMERCETOOLS_CLIENT_ID"),
+		CommercetoolsClientSecret:   os.Getenv("COMMERCETOOLS_CLIENT_SECRET"),
+		CommercetoolsLocale:         getEnv("COMMERCETOOLS_LOCALE", "en"),
+
+		FeedsConfig:   os.Getenv("FEEDS_CONFIG"),
+		FeedsInterval: getDuration("FEEDS_INTERVAL", time.Hour),
+
+		SitemapProductURL:  os.Getenv("SITEMAP_PRODUCT_URL"),
+		SitemapCategoryURL: os.Getenv("SITEMAP_CATEGORY_URL"),
+		SitemapBaseURL:     os.Getenv("SITEMAP_BASE_URL"),
+		SitemapTenant:      os.Getenv("SITEMAP_TENANT"),
+		SitemapInterval:    getDuration("SITEMAP_INTERVAL", 6*time.Hour),
+
+		BlobDir:  getEnv("BLOB_DIR", "blobs"),
+		BlobAddr: os.Getenv("BLOB_ADDR"),
+
+		LowStockThreshold: getInt("LOW_STOCK_THRESHOLD", 5),
+		LowStockHorizon:   getDuration("LOW_STOCK_HORIZON", 14*24*time.Hour),
+
+		MetricsAddr: os.Getenv("METRICS_ADDR"),
+
+		EnableTestReset: getBool("ENABLE_TEST_RESET", false),
+
+		AccessLogPath:       os.Getenv("ACCESS_LOG_PATH"),
+		AccessLogMaxBytes:   getInt("ACCESS_LOG_MAX_BYTES", 100<<20),
+		AccessLogBackups:    getInt("ACCESS_LOG_BACKUPS", 5),
+		AccessLogSampleRate: getFloat("ACCESS_LOG_SAMPLE_RATE", 0.01),
+
+		OTLPEndpoint: os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if val, ok := os.LookupEnv(key); ok && val != "" {
+		return val
+	}
+	return fallback
+}
+
+func getBool(key string, fallback bool) bool {
+	val, ok := os.LookupEnv(key)
+	if !ok || val == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		log.Printf("config: %s=%q is not a boolean, using %t", key, val, fallback)
+		return fallback
+	}
+	return b
+}
+
+func getInt(key string, fallback int) int {
+	val, ok := os.LookupEnv(key)
+	if !ok || val == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil || n <= 0 {
+		log.Printf("config: %s=%q is not a positive integer, using %d", key, val, fallback)
+		return fallback
+	}
+	return n
+}
+
+func getFloat(key string, fallback float64) float64 {
+	val, ok := os.LookupEnv(key)
+	if !ok || val == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil || f < 0 {
+		log.Printf("config: %s=%q is not a non-negative number, using %g", key, val, fallback)
+		return fallback
+	}
+	return f
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	val, ok := os.LookupEnv(key)
+	if !ok || val == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil || d <= 0 {
+		log.Printf("config: %s=%q is not a positive duration, using %s", key, val, fallback)
+		return fallback
+	}
+	return d
+}
@@ -0,0 +1,114 @@
+// Package egress guards requests the service makes to URLs callers give
+// it, such as webhook endpoints, so they can't be aimed at the service's
+// own host, its private network or a cloud metadata endpoint.
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbidden reports a destination that resolves to an address outside
+// the public internet.
+var ErrForbidden = errors.New("egress: destination address is not public")
+
+// blocked holds the special-purpose ranges netip has no predicate for.
+var blocked = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, which maps onto IPv4
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2002::/16"),      // 6to4, which embeds an IPv4
+	netip.MustParsePrefix("2001::/32"),      // Teredo, likewise
+	netip.MustParsePrefix("fec0::/10"),      // deprecated site-local
+}
+
+// Allowed reports whether addr is a public unicast address the service
+// may connect to on a caller's behalf.
+func Allowed(addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+	if addr.Is4In6() {
+		addr = addr.Unmap()
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, p := range blocked {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL rejects raw unless it is an absolute http(s) URL whose host
+// resolves only to allowed addresses. The address can still change before
+// a request is made, so clients must also dial through Control.
+func CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("egress: not an absolute http(s) url")
+	}
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil {
+		if !Allowed(addr) {
+			return ErrForbidden
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("egress: resolve %s: %w", u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if !Allowed(addr) {
+			return ErrForbidden
+		}
+	}
+	return nil
+}
+
+// Control is a net.Dialer Control function that refuses connections to
+// addresses Allowed rejects. It runs after name resolution, on the address
+// actually dialed, so a host re-pointed after CheckURL is still caught.
+func Control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("egress: %w", err)
+	}
+	if !Allowed(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbidden, addrPort.Addr())
+	}
+	return nil
+}
+
+// NewClient returns an HTTP client that only connects to allowed addresses.
+// It ignores proxy settings, since a proxy would dial on its behalf, and
+// doesn't follow redirects: a request goes to the URL it was given or fails.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: Control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,108 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"127.8.8.8", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fd00:ec2::254", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"100.64.0.1", false},
+		{"198.18.0.1", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		{"64:ff9b::a9fe:a9fe", false},
+		{"2002:7f00:1::", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := Allowed(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("Allowed(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url       string
+		forbidden bool
+		invalid   bool
+	}{
+		{url: "https://93.184.216.34/hooks"},
+		{url: "http://127.0.0.1:8080/hooks", forbidden: true},
+		{url: "http://[::1]/hooks", forbidden: true},
+		{url: "http://169.254.169.254/latest/meta-data/", forbidden: true},
+		{url: "http://10.0.0.5/hooks", forbidden: true},
+		{url: "http://localhost/hooks", forbidden: true},
+		{url: "ftp://93.184.216.34/hooks", invalid: true},
+		{url: "/hooks", invalid: true},
+		{url: "http://", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := CheckURL(context.Background(), tt.url)
+			switch {
+			case tt.forbidden:
+				if !errors.Is(err, ErrForbidden) {
+					t.Errorf("CheckURL(%q) = %v, want ErrForbidden", tt.url, err)
+				}
+			case tt.invalid:
+				if err == nil || errors.Is(err, ErrForbidden) {
+					t.Errorf("CheckURL(%q) = %v, want an invalid url error", tt.url, err)
+				}
+			default:
+				if err != nil {
+					t.Errorf("CheckURL(%q) = %v, want nil", tt.url, err)
+				}
+			}
+		})
+	}
+}
+
+func TestControl(t *testing.T) {
+	tests := []struct {
+		address string
+		want    error
+	}{
+		{"93.184.216.34:443", nil},
+		{"127.0.0.1:443", ErrForbidden},
+		{"[::1]:80", ErrForbidden},
+		{"169.254.169.254:80", ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if err := Control("tcp", tt.address, nil); !errors.Is(err, tt.want) {
+				t.Errorf("Control(%q) = %v, want %v", tt.address, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientRefusesPrivateAddresses(t *testing.T) {
+	client := NewClient(0)
+	_, err := client.Get("http://127.0.0.1:1/")
+	if !errors.Is(err, ErrForbidden) {
+		t.Fatalf("Get(loopback) = %v, want ErrForbidden", err)
+	}
+}
@@ -0,0 +1,87 @@
+// Package etag lets reads answer conditional requests. A read tags what
+// it returns; a client that sends the tag back in If-None-Match is told
+// its copy is still current instead of being sent it again, and reads
+// that can tell cheaply skip the database work too.
+package etag
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Of tags an entity by the parts that make up its content, as a strong
+// entity tag (quoted).
+func Of(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// exchange is a call's If-None-Match and the tag its read recorded.
+type exchange struct {
+	ifNoneMatch []string
+	tag         string
+	notModified bool
+}
+
+type exchangeKey struct{}
+
+// WithRequest returns ctx carrying the If-None-Match values of the call,
+// for Check to compare tags against.
+func WithRequest(ctx context.Context, ifNoneMatch []string) context.Context {
+	return context.WithValue(ctx, exchangeKey{}, &exchange{ifNoneMatch: ifNoneMatch})
+}
+
+// Ignore returns ctx without the call's exchange, for reads made on the
+// way to a tag of their own.
+func Ignore(ctx context.Context) context.Context {
+	return context.WithValue(ctx, exchangeKey{}, (*exchange)(nil))
+}
+
+// Conditional reports whether the call sent If-None-Match.
+func Conditional(ctx context.Context) bool {
+	e, _ := ctx.Value(exchangeKey{}).(*exchange)
+	return e != nil && len(e.ifNoneMatch) > 0
+}
+
+// Check records tag as the call's and reports whether the client already
+// has it, in which case the read replies not modified.
+func Check(ctx context.Context, tag string) bool {
+	e, _ := ctx.Value(exchangeKey{}).(*exchange)
+	if e == nil {
+		return false
+	}
+	e.tag = tag
+	e.notModified = matches(e.ifNoneMatch, tag)
+	return e.notModified
+}
+
+// Result returns the tag the call's read recorded, if any, and whether it
+// replied not modified.
+func Result(ctx context.Context) (tag string, notModified bool) {
+	e, _ := ctx.Value(exchangeKey{}).(*exchange)
+	if e == nil {
+		return "", false
+	}
+	return e.tag, e.notModified
+}
+
+// matches compares tags as If-None-Match does: weakly, so W/"x" matches
+// "x", and * matches any.
+func matches(ifNoneMatch []string, tag string) bool {
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, value := range ifNoneMatch {
+		for _, candidate := range strings.Split(value, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
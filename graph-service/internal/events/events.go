@@ -0,0 +1,136 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Catalog event types emitted by the service.
+const (
+	ProductCreated = "product.created"
+	ProductUpdated = "product.updated"
+	ProductDeleted = "product.deleted"
+	StockUpdated   = "stock.updated"
+	SizeAdded      = "size.added"
+	// StockLow is raised when a size falls to the low-stock threshold or
+	// its forecast has it run out soon, and again only after it recovers.
+	StockLow = "stock.low"
+)
+
+// Inventory and order event types, about a reservation or order rather
+// than one product.
+const (
+	ReservationExpired = "reservation.expired"
+	OrderPlaced        = "order.placed"
+	OrderFlagged       = "order.flagged"
+	ReturnCompleted    = "return.completed"
+)
+
+// Notification event types, addressed to a user rather than a product.
+const (
+	SavedSearchMatched = "saved_search.matched"
+	LoyaltyTierChanged = "loyalty.tier_changed"
+	PriceDropped       = "price.dropped"
+)
+
+// Moderation event types, about a customer's question or answer, raised
+// when a moderator or automated screening decides it.
+const (
+	ModerationApproved = "moderation.approved"
+	ModerationRejected = "moderation.rejected"
+)
+
+// Seller event types, addressed to the marketplace seller whose offer or
+// conduct they're about. They carry the seller's id, and only that seller's
+// webhooks and the catalog's own receive them.
+const (
+	OfferBuyBoxWon        = "offer.buy_box_won"
+	OfferBuyBoxLost       = "offer.buy_box_lost"
+	OfferOutOfStock       = "offer.out_of_stock"
+	SellerPolicyViolation = "seller.policy_violation"
+)
+
+// Wildcard subscribes a consumer to every event type.
+const Wildcard = "*"
+
+var knownTypes = map[string]bool{
+	ProductCreated: true,
+	ProductUpdated: true,
+	ProductDeleted: true,
+	StockUpdated:   true,
+	SizeAdded:      true,
+	StockLow:       true,
+
+	ReservationExpired: true,
+	OrderPlaced:        true,
+	OrderFlagged:       true,
+	ReturnCompleted:    true,
+
+	SavedSearchMatched: true,
+	LoyaltyTierChanged: true,
+	PriceDropped:       true,
+
+	ModerationApproved: true,
+	ModerationRejected: true,
+
+	OfferBuyBoxWon:        true,
+	OfferBuyBoxLost:       true,
+	OfferOutOfStock:       true,
+	SellerPolicyViolation: true,
+
+	Wildcard: true,
+}
+
+var sellerTypes = map[string]bool{
+	OfferBuyBoxWon:        true,
+	OfferBuyBoxLost:       true,
+	OfferOutOfStock:       true,
+	SellerPolicyViolation: true,
+}
+
+// IsKnownType reports whether t is an event type consumers can subscribe to.
+func IsKnownType(t string) bool {
+	return knownTypes[t]
+}
+
+// IsSellerType reports whether t is an event type a seller's webhooks can
+// subscribe to.
+func IsSellerType(t string) bool {
+	return sellerTypes[t] || t == Wildcard
+}
+
+// Event is the envelope delivered to downstream consumers.
+type Event struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	ProductID  string `json:"product_id,omitempty"`
+	SKU        string `json:"sku,omitempty"`
+	SellerID   string `json:"seller_id,omitempty"`
+	OccurredAt int64  `json:"occurred_at"`
+	Data       any    `json:"data,omitempty"`
+}
+
+// New builds an event with a fresh ID, stamped with the current time.
+// Consumers can use the ID to discard redeliveries.
+func New(eventType, productID string, data any) Event {
+	return Event{
+		ID:         newID(),
+		Type:       eventType,
+		ProductID:  productID,
+		OccurredAt: time.Now().UnixMilli(),
+		Data:       data,
+	}
+}
+
+// Payload serializes the event to JSON.
+func (e Event) Payload() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
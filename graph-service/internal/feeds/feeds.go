@@ -0,0 +1,207 @@
+// Package feeds publishes the catalog as product feeds for shopping
+// channels: Google Merchant Center and the Facebook (Meta) catalog. A
+// Generator periodically renders every configured feed, as XML or TSV,
+// from the graph and puts it in blob storage, where the channel fetches it.
+package feeds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/blob"
+)
+
+const (
+	defaultInterval  = time.Hour
+	defaultBatchSize = 500
+	// KeyPrefix is where feeds are put in blob storage.
+	KeyPrefix = "feeds/"
+)
+
+// attributeName matches the feed attributes channels define.
+var attributeName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Channel is a shopping channel. It chooses a feed's default fields.
+type Channel string
+
+const (
+	Google   Channel = "google"
+	Facebook Channel = "facebook"
+)
+
+// Format is a feed's file format.
+type Format string
+
+const (
+	// XML is an RSS 2.0 document with the g: namespace both channels read.
+	XML Format = "xml"
+	// TSV is tab-separated values with a header row.
+	TSV Format = "tsv"
+)
+
+// Feed is one generated feed. Products are listed one item per size,
+// grouped by product, or as one item when they have no sized SKUs.
+// Archived products are left out.
+type Feed struct {
+	// Name keys the file in blob storage: feeds/<name>.<format>.
+	Name    string  `json:"name"`
+	Channel Channel `json:"channel"`
+	Format  Format  `json:"format"`
+	// TenantID, if set, limits the feed to the tenant's products.
+	TenantID string `json:"tenant_id"`
+	// Currency labels prices; Rate, if set, converts catalog prices into
+	// it.
+	Currency string  `json:"currency"`
+	Rate     float64 `json:"rate"`
+	// Link is the product page URL, with {id}, {slug} and {sku} filled in.
+	Link string `json:"link"`
+	// MinStock is the stock from which an item counts as in stock; 0
+	// means 1. Availability overrides the values items in and out of
+	// stock report, under the keys in_stock and out_of_stock.
+	MinStock     int32             `json:"min_stock"`
+	Availability map[string]string `json:"availability"`
+	// Fields maps feed attributes to item values, adding to or overriding
+	// the channel's defaults; see itemValues for the values. A value
+	// starting with "=" is a constant, and an empty value drops the
+	// attribute.
+	Fields map[string]string `json:"fields"`
+}
+
+// feedsFile is the on-disk format:
+//
+//	{"feeds": [
+//	  {"name": "google-us", "channel": "google", "format": "xml",
+//	   "currency": "USD", "link": "https://shop.example.com/p/{slug}",
+//	   "fields": {"google_product_category": "attributes.google_category"}}
+//	]}
+type feedsFile struct {
+	Feeds []Feed `json:"feeds"`
+}
+
+// Load reads feed definitions from a JSON file.
+func Load(filename string) ([]Feed, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var f feedsFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse feeds: %w", err)
+	}
+
+	names := map[string]bool{}
+	for i, feed := range f.Feeds {
+		if feed.Name == "" || feed.Currency == "" || feed.Link == "" {
+			return nil, fmt.Errorf("feed %d: name, currency and link are required", i)
+		}
+		if names[feed.Name] {
+			return nil, fmt.Errorf("feed %q: duplicate name", feed.Name)
+		}
+		names[feed.Name] = true
+		if _, ok := defaultFields[feed.Channel]; !ok {
+			return nil, fmt.Errorf("feed %q: unknown channel %q (want google or facebook)", feed.Name, feed.Channel)
+		}
+		if feed.Format != XML && feed.Format != TSV {
+			return nil, fmt.Errorf("feed %q: unknown format %q (want xml or tsv)", feed.Name, feed.Format)
+		}
+		if feed.Rate < 0 {
+			return nil, fmt.Errorf("feed %q: rate must not be negative", feed.Name)
+		}
+		for attr := range feed.Fields {
+			if !attributeName.MatchString(attr) {
+				return nil, fmt.Errorf("feed %q: %q is not an attribute name", feed.Name, attr)
+			}
+		}
+	}
+	return f.Feeds, nil
+}
+
+// Key is where the feed is put in blob storage.
+func (f Feed) Key() string {
+	return KeyPrefix + f.Name + "." + string(f.Format)
+}
+
+// Store lists the catalog the feeds are rendered from.
+type Store interface {
+	ListProducts(ctx context.Context, tenantID, afterID string, limit int, includeArchive bool) ([]*pb.Product, error)
+}
+
+// Generator periodically renders the feeds into blob storage. Each
+// replica renders them all; the last to finish wins, which is harmless as
+// they read the same catalog.
+type Generator struct {
+	store Store
+	blobs blob.Store
+	feeds []Feed
+
+	Interval  time.Duration
+	BatchSize int
+}
+
+func NewGenerator(store Store, blobs blob.Store, feeds []Feed) *Generator {
+	return &Generator{
+		store:     store,
+		blobs:     blobs,
+		feeds:     feeds,
+		Interval:  defaultInterval,
+		BatchSize: defaultBatchSize,
+	}
+}
+
+// Run renders the feeds until ctx is cancelled.
+func (g *Generator) Run(ctx context.Context) {
+	ticker := time.NewTicker(g.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := g.GenerateAll(ctx); err != nil {
+			log.Printf("feeds: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GenerateAll reads the catalog once and renders every feed from it.
+func (g *Generator) GenerateAll(ctx context.Context) error {
+	writers := make([]*writer, len(g.feeds))
+	for i, feed := range g.feeds {
+		writers[i] = newWriter(feed)
+	}
+
+	afterID := ""
+	for {
+		batch, err := g.store.ListProducts(ctx, "", afterID, g.BatchSize, false)
+		if err != nil {
+			return err
+		}
+		for _, p := range batch {
+			for _, w := range writers {
+				w.add(p)
+			}
+		}
+		if len(batch) < g.BatchSize {
+			break
+		}
+		afterID = batch[len(batch)-1].Id
+	}
+
+	for _, w := range writers {
+		if err := g.blobs.Put(ctx, w.feed.Key(), w.finish()); err != nil {
+			return fmt.Errorf("feed %s: %w", w.feed.Name, err)
+		}
+		log.Printf("feeds: wrote %s, %d items", w.feed.Key(), w.items)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package feeds
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+)
+
+// field is a feed attribute and the item value it takes.
+type field struct {
+	attr  string
+	value string
+}
+
+// defaultFields are each channel's attributes, in the order feeds list
+// them.
+var defaultFields = map[Channel][]field{
+	Google: {
+		{"id", "id"},
+		{"item_group_id", "product_id"},
+		{"title", "title"},
+		{"description", "description"},
+		{"link", "link"},
+		{"image_link", "image"},
+		{"additional_image_link", "additional_images"},
+		{"availability", "availability"},
+		{"price", "price"},
+		{"sale_price", "sale_price"},
+		{"brand", "brand"},
+		{"gtin", "gtin"},
+		{"identifier_exists", "identifier_exists"},
+		{"color", "color"},
+		{"size", "size"},
+		{"product_type", "category"},
+		{"condition", "=new"},
+	},
+	Facebook: {
+		{"id", "id"},
+		{"item_group_id", "product_id"},
+		{"title", "title"},
+		{"description", "description"},
+		{"link", "link"},
+		{"image_link", "image"},
+		{"additional_image_link", "additional_images"},
+		{"availability", "availability"},
+		{"quantity_to_sell_on_facebook", "stock"},
+		{"price", "price"},
+		{"sale_price", "sale_price"},
+		{"brand", "brand"},
+		{"gtin", "gtin"},
+		{"color", "color"},
+		{"size", "size"},
+		{"product_type", "category"},
+		{"condition", "=new"},
+	},
+}
+
+// fields resolves the feed's attributes: the channel's defaults with the
+// feed's mapping applied, then the attributes the feed adds, by name.
+func (f Feed) fields() []field {
+	var out []field
+	for _, def := range defaultFields[f.Channel] {
+		if value, ok := f.Fields[def.attr]; ok {
+			def.value = value
+		}
+		if def.value != "" {
+			out = append(out, def)
+		}
+	}
+	for _, attr := range slices.Sorted(maps.Keys(f.Fields)) {
+		known := slices.ContainsFunc(defaultFields[f.Channel], func(def field) bool { return def.attr == attr })
+		if !known && f.Fields[attr] != "" {
+			out = append(out, field{attr, f.Fields[attr]})
+		}
+	}
+	return out
+}
+
+// itemValues returns an item's values: id (the SKU, or the product id for
+// products without sizes), product_id, sku, title, description, brand,
+// gtin, identifier_exists, color, size, slug, category ("Main > Sub >
+// Type"), tags, link, image, additional_images, price, sale_price (set
+// with the original price as price when the product is discounted), stock,
+// availability, and attributes.<name>. size is nil for products without
+// sizes.
+func (f Feed) itemValues(p *pb.Product, size *pb.ProductSize) map[string][]string {
+	one := func(s string) []string {
+		if s == "" {
+			return nil
+		}
+		return []string{s}
+	}
+
+	values := map[string][]string{
+		"id":          one(p.Id),
+		"product_id":  one(p.Id),
+		"title":       one(p.Name),
+		"description": one(p.Description),
+		"brand":       one(p.Brand),
+		"gtin":        one(p.Gtin),
+		"color":       one(p.Color),
+		"slug":        one(p.Slug),
+		"tags":        p.Tags,
+	}
+	values["identifier_exists"] = one("yes")
+	if p.Gtin == "" {
+		values["identifier_exists"] = one("no")
+	}
+	if c := p.GetCategory(); c != nil {
+		var path []string
+		for _, part := range []string{c.MainCategory, c.Subcategory, c.SpecificType} {
+			if part != "" {
+				path = append(path, part)
+			}
+		}
+		values["category"] = one(strings.Join(path, " > "))
+	}
+	if len(p.Images) > 0 {
+		values["image"] = one(p.Images[0])
+		values["additional_images"] = p.Images[1:]
+	}
+	for name, value := range p.Attributes {
+		values["attributes."+name] = one(value)
+	}
+
+	var stock int32
+	sku := ""
+	if size != nil {
+		stock = size.Stock
+		sku = size.Sku
+		values["id"] = one(size.Sku)
+		values["sku"] = one(size.Sku)
+		values["size"] = one(size.Size)
+	}
+	values["stock"] = one(strconv.Itoa(int(max(stock, 0))))
+	availability := "out_of_stock"
+	if stock >= max(f.MinStock, 1) {
+		availability = "in_stock"
+	}
+	values["availability"] = one(f.availability(availability))
+
+	values["price"] = one(f.price(p.Price))
+	if p.OriginalPrice > p.Price {
+		values["price"] = one(f.price(p.OriginalPrice))
+		values["sale_price"] = one(f.price(p.Price))
+	}
+
+	link := strings.NewReplacer("{id}", p.Id, "{slug}", cmp.Or(p.Slug, p.Id), "{sku}", sku).Replace(f.Link)
+	values["link"] = one(link)
+	return values
+}
+
+// availability returns the value the feed reports for in_stock or
+// out_of_stock. Both channels take "in stock" and "out of stock".
+func (f Feed) availability(key string) string {
+	if value := f.Availability[key]; value != "" {
+		return value
+	}
+	return strings.ReplaceAll(key, "_", " ")
+}
+
+func (f Feed) price(amount float64) string {
+	if f.Rate > 0 {
+		amount *= f.Rate
+	}
+	return fmt.Sprintf("%.2f %s", amount, f.Currency)
+}
+
+// writer renders one feed.
+type writer struct {
+	feed   Feed
+	fields []field
+	buf    bytes.Buffer
+	items  int
+}
+
+func newWriter(feed Feed) *writer {
+	w := &writer{feed: feed, fields: feed.fields()}
+	switch feed.Format {
+	case XML:
+		w.buf.WriteString(xml.Header)
+		w.buf.WriteString(`<rss version="2.0" xmlns:g="http://base.google.com/ns/1.0">` + "\n<channel>\n<title>")
+		xml.EscapeText(&w.buf, []byte(feed.Name))
+		w.buf.WriteString("</title>\n")
+	case TSV:
+		for i, field := range w.fields {
+			if i > 0 {
+				w.buf.WriteByte('\t')
+			}
+			w.buf.WriteString(field.attr)
+		}
+		w.buf.WriteByte('\n')
+	}
+	return w
+}
+
+// add lists the product's items, if it belongs in the feed.
+func (w *writer) add(p *pb.Product) {
+	if p.Archived || (w.feed.TenantID != "" && p.TenantId != w.feed.TenantID) {
+		return
+	}
+	var sizes []*pb.ProductSize
+	for _, size := range p.Sizes {
+		if size.Sku != "" {
+			sizes = append(sizes, size)
+		}
+	}
+	if len(sizes) == 0 {
+		w.item(w.feed.itemValues(p, nil))
+		return
+	}
+	for _, size := range sizes {
+		w.item(w.feed.itemValues(p, size))
+	}
+}
+
+func (w *writer) item(values map[string][]string) {
+	w.items++
+	switch w.feed.Format {
+	case XML:
+		w.buf.WriteString("<item>\n")
+		for _, field := range w.fields {
+			for _, value := range w.resolve(field, values) {
+				w.buf.WriteString("<g:" + field.attr + ">")
+				xml.EscapeText(&w.buf, []byte(value))
+				w.buf.WriteString("</g:" + field.attr + ">\n")
+			}
+		}
+		w.buf.WriteString("</item>\n")
+	case TSV:
+		// Tabs and line breaks would end the cell
+		clean := strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+		for i, field := range w.fields {
+			if i > 0 {
+				w.buf.WriteByte('\t')
+			}
+			w.buf.WriteString(clean.Replace(strings.Join(w.resolve(field, values), ",")))
+		}
+		w.buf.WriteByte('\n')
+	}
+}
+
+func (w *writer) resolve(f field, values map[string][]string) []string {
+	if constant, ok := strings.CutPrefix(f.value, "="); ok {
+		return []string{constant}
+	}
+	return values[f.value]
+}
+
+// finish returns the rendered feed.
+func (w *writer) finish() []byte {
+	if w.feed.Format == XML {
+		w.buf.WriteString("</channel>\n</rss>\n")
+	}
+	return w.buf.Bytes()
+}
@@ -0,0 +1,87 @@
+// Package fixtures holds the named data sets test environments are
+// seeded with through ResetAndSeed. Every record has a fixed id, so a
+// suite can refer to fixture products, SKUs and sellers by id.
+package fixtures
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+//go:embed sets/*.json
+var sets embed.FS
+
+// ErrUnknown is returned by Load for a name no set has.
+var ErrUnknown = errors.New("unknown fixture set")
+
+// Set is a named fixture set: catalog products, and marketplace sellers
+// with their offers of the products' SKUs.
+type Set struct {
+	Name     string
+	Products []*pb.Product
+	Sellers  []Seller
+	Offers   []Offer
+}
+
+type Seller struct {
+	ID       string `json:"id"`
+	TenantID string `json:"tenant_id"`
+	Name     string `json:"name"`
+}
+
+type Offer struct {
+	SellerID string  `json:"seller_id"`
+	SKU      string  `json:"sku"`
+	Price    float64 `json:"price"`
+	Stock    int32   `json:"stock"`
+}
+
+// Names lists the sets there are, in name order.
+func Names() []string {
+	entries, _ := fs.ReadDir(sets, "sets")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Load reads the set called name.
+func Load(name string) (*Set, error) {
+	data, err := sets.ReadFile(path.Join("sets", name+".json"))
+	if errors.Is(err, fs.ErrNotExist) || strings.ContainsAny(name, "/.") {
+		return nil, fmt.Errorf("%w %q, want one of %s", ErrUnknown, name, strings.Join(Names(), ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		Products []json.RawMessage `json:"products"`
+		Sellers  []Seller          `json:"sellers"`
+		Offers   []Offer           `json:"offers"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("fixture set %s: %w", name, err)
+	}
+
+	set := &Set{Name: name, Sellers: raw.Sellers, Offers: raw.Offers}
+	for i, msg := range raw.Products {
+		p := &pb.Product{}
+		if err := protojson.Unmarshal(msg, p); err != nil {
+			return nil, fmt.Errorf("fixture set %s: product %d: %w", name, i, err)
+		}
+		set.Products = append(set.Products, p)
+	}
+	return set, nil
+}
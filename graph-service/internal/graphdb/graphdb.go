@@ -0,0 +1,200 @@
+// Package graphdb opens a driver for one of the graph databases the
+// service runs against, tuned for it, along with the repository options
+// that adapt queries to its Cypher dialect.
+package graphdb
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/config"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Graph backend profiles.
+const (
+	// ProfileNeo4j is a self-hosted Neo4j 5 server or cluster.
+	ProfileNeo4j = "neo4j"
+	// ProfileAura is Neo4j Aura, which only accepts encrypted routing
+	// connections.
+	ProfileAura = "aura"
+	// ProfileMemgraph is a single Memgraph instance.
+	ProfileMemgraph = "memgraph"
+)
+
+// Encryption modes for profiles that don't dictate one. They are applied
+// by switching a plain URI to its +s or +ssc scheme.
+const (
+	EncryptionNone          = ""
+	EncryptionTLS           = "tls"
+	EncryptionTLSSelfSigned = "tls-self-signed"
+)
+
+// Aura's load balancers drop connections that sit idle too long, so pooled
+// connections are recycled well before that and checked before reuse.
+const (
+	auraMaxConnectionLifetime = 30 * time.Minute
+	auraLivenessCheckTimeout  = 30 * time.Second
+)
+
+// Settings describe the database to connect to.
+type Settings struct {
+	Profile    string
+	URI        string
+	Username   string
+	Password   string
+	Database   string
+	Encryption string
+
+	// Search configures the fulltext indexes, as in config.Config.
+	SearchAnalyzer        string
+	SearchFields          string
+	SearchLocaleAnalyzers string
+	SearchFieldWeights    string
+}
+
+// SettingsFrom reads the graph database settings out of the service config.
+func SettingsFrom(cfg config.Config) Settings {
+	return Settings{
+		Profile:    cfg.GraphProfile,
+		URI:        cfg.Neo4jURI,
+		Username:   cfg.Neo4jUsername,
+		Password:   cfg.Neo4jPassword,
+		Database:   cfg.Neo4jDatabase,
+		Encryption: cfg.Neo4jEncryption,
+
+		SearchAnalyzer:        cfg.SearchAnalyzer,
+		SearchFields:          cfg.SearchFields,
+		SearchLocaleAnalyzers: cfg.SearchLocaleAnalyzers,
+		SearchFieldWeights:    cfg.SearchFieldWeights,
+	}
+}
+
+// Open creates a driver for s. It does not contact the database; the first
+// session does.
+func Open(s Settings) (neo4j.DriverWithContext, []repository.Option, error) {
+	uri, err := url.Parse(s.URI)
+	if err != nil {
+		return nil, nil, fmt.Errorf("graph database uri: %w", err)
+	}
+	indexes, err := searchIndexes(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.Password == "" && s.Profile != ProfileMemgraph {
+		return nil, nil, fmt.Errorf("the %s profile needs NEO4J_PASSWORD", s.Profile)
+	}
+
+	var (
+		dialect    repository.Dialect
+		configures []func(*neo4j.Config)
+	)
+	switch s.Profile {
+	case ProfileNeo4j:
+		dialect = repository.Neo4jDialect
+		if err := applyEncryption(uri, s.Encryption); err != nil {
+			return nil, nil, err
+		}
+
+	case ProfileAura:
+		dialect = repository.Neo4jDialect
+		if uri.Scheme != "neo4j+s" && uri.Scheme != "neo4j+ssc" {
+			return nil, nil, fmt.Errorf("aura needs a neo4j+s:// uri, got %s://", uri.Scheme)
+		}
+		configures = append(configures, func(c *neo4j.Config) {
+			c.MaxConnectionLifetime = auraMaxConnectionLifetime
+			c.ConnectionLivenessCheckTimeout = auraLivenessCheckTimeout
+		})
+
+	case ProfileMemgraph:
+		dialect = repository.MemgraphDialect
+		// Memgraph has no routing table outside its HA setup
+		if strings.HasPrefix(uri.Scheme, "neo4j") {
+			return nil, nil, fmt.Errorf("memgraph needs a bolt:// uri, got %s://", uri.Scheme)
+		}
+		if err := applyEncryption(uri, s.Encryption); err != nil {
+			return nil, nil, err
+		}
+
+	default:
+		return nil, nil, fmt.Errorf("unknown graph backend profile %q (want %s, %s or %s)",
+			s.Profile, ProfileNeo4j, ProfileAura, ProfileMemgraph)
+	}
+
+	// Memgraph without users configured accepts any credentials
+	driver, err := neo4j.NewDriverWithContext(uri.String(), neo4j.BasicAuth(s.Username, s.Password, ""), configures...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts := []repository.Option{repository.WithDialect(dialect), repository.WithSearchIndexes(indexes)}
+	if s.Database != "" {
+		opts = append(opts, repository.WithDatabase(s.Database))
+	}
+	return driver, opts, nil
+}
+
+// searchIndexes parses the search index settings.
+func searchIndexes(s Settings) (repository.SearchIndexes, error) {
+	indexes := repository.SearchIndexes{
+		Analyzer: s.SearchAnalyzer,
+		Weights:  map[string]float64{},
+		Locales:  map[string]string{},
+	}
+	for _, field := range strings.Split(s.SearchFields, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			indexes.Fields = append(indexes.Fields, field)
+		}
+	}
+	for _, pair := range strings.Split(s.SearchLocaleAnalyzers, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		language, analyzer, ok := strings.Cut(pair, "=")
+		if !ok {
+			return indexes, fmt.Errorf("SEARCH_LOCALE_ANALYZERS: %q is not language=analyzer", pair)
+		}
+		indexes.Locales[strings.ToLower(strings.TrimSpace(language))] = strings.TrimSpace(analyzer)
+	}
+	for _, pair := range strings.Split(s.SearchFieldWeights, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		field, weight, ok := strings.Cut(pair, "=")
+		w, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+		if !ok || err != nil {
+			return indexes, fmt.Errorf("SEARCH_FIELD_WEIGHTS: %q is not field=weight", pair)
+		}
+		indexes.Weights[strings.TrimSpace(field)] = w
+	}
+	if err := indexes.Validate(); err != nil {
+		return indexes, fmt.Errorf("SEARCH_*: %w", err)
+	}
+	return indexes, nil
+}
+
+// applyEncryption switches a plain bolt:// or neo4j:// uri to the scheme
+// for the requested encryption. A uri that already names one is left alone.
+func applyEncryption(uri *url.URL, encryption string) error {
+	switch encryption {
+	case EncryptionNone:
+		return nil
+	case EncryptionTLS, EncryptionTLSSelfSigned:
+	default:
+		return fmt.Errorf("unknown encryption %q (want %s or %s)", encryption, EncryptionTLS, EncryptionTLSSelfSigned)
+	}
+
+	if uri.Scheme != "bolt" && uri.Scheme != "neo4j" {
+		return nil
+	}
+	if encryption == EncryptionTLS {
+		uri.Scheme += "+s"
+	} else {
+		uri.Scheme += "+ssc"
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Package graphexport encodes subgraphs for graph visualization tools, as
+// Cytoscape.js JSON or GraphML.
+package graphexport
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Graph is a set of nodes and the edges between them.
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+	// Truncated reports the walk that built the graph stopped at a limit.
+	Truncated bool
+}
+
+// Node is a node with an id unique within its graph.
+type Node struct {
+	ID     string
+	Labels []string
+	// Caption is a short display name, e.g. a product's name or a SKU.
+	Caption    string
+	Properties map[string]any
+}
+
+// Edge is a directed, typed edge between two of the graph's nodes.
+type Edge struct {
+	Source, Target string
+	Type           string
+	Properties     map[string]any
+}
+
+func (e Edge) id() string {
+	return e.Source + "-" + e.Type + "-" + e.Target
+}
+
+type cytoscapeElement struct {
+	Data map[string]any `json:"data"`
+}
+
+// CytoscapeJSON encodes g in the elements format cytoscape() accepts:
+// {"elements": {"nodes": [{"data": {...}}], "edges": [{"data": {...}}]}}.
+// Node data holds id, labels, name (the caption) and properties; edge data
+// holds id, source, target, label (the type) and properties.
+func CytoscapeJSON(g *Graph) ([]byte, error) {
+	nodes := make([]cytoscapeElement, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		nodes = append(nodes, cytoscapeElement{Data: map[string]any{
+			"id":         n.ID,
+			"labels":     n.Labels,
+			"name":       n.Caption,
+			"properties": n.Properties,
+		}})
+	}
+	edges := make([]cytoscapeElement, 0, len(g.Edges))
+	for _, e := range g.Edges {
+		edges = append(edges, cytoscapeElement{Data: map[string]any{
+			"id":         e.id(),
+			"source":     e.Source,
+			"target":     e.Target,
+			"label":      e.Type,
+			"properties": e.Properties,
+		}})
+	}
+	return json.Marshal(map[string]any{
+		"elements": map[string]any{"nodes": nodes, "edges": edges},
+	})
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// GraphML encodes g as a directed GraphML graph. Every property gets a
+// string key; values other than strings are JSON. Nodes also carry labels,
+// ":"-joined, and name, the caption; edges carry type.
+func GraphML(g *Graph) ([]byte, error) {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "labels", For: "node", Name: "labels", Type: "string"},
+			{ID: "name", For: "node", Name: "name", Type: "string"},
+			{ID: "type", For: "edge", Name: "type", Type: "string"},
+		},
+		Graph: graphMLGraph{ID: "G", EdgeDefault: "directed"},
+	}
+
+	nodeKeys := map[string]bool{}
+	for _, n := range g.Nodes {
+		node := graphMLNode{ID: n.ID, Data: []graphMLData{
+			{Key: "labels", Value: ":" + strings.Join(n.Labels, ":")},
+			{Key: "name", Value: n.Caption},
+		}}
+		data, err := graphMLProperties("n_", n.Properties, nodeKeys)
+		if err != nil {
+			return nil, err
+		}
+		node.Data = append(node.Data, data...)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	edgeKeys := map[string]bool{}
+	for _, e := range g.Edges {
+		edge := graphMLEdge{ID: e.id(), Source: e.Source, Target: e.Target, Data: []graphMLData{
+			{Key: "type", Value: e.Type},
+		}}
+		data, err := graphMLProperties("e_", e.Properties, edgeKeys)
+		if err != nil {
+			return nil, err
+		}
+		edge.Data = append(edge.Data, data...)
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(nodeKeys)) {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: "n_" + name, For: "node", Name: name, Type: "string"})
+	}
+	for _, name := range slices.Sorted(maps.Keys(edgeKeys)) {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: "e_" + name, For: "edge", Name: name, Type: "string"})
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// graphMLProperties returns the properties as data elements, in name
+// order, with keys prefixed, and adds their names to keys.
+func graphMLProperties(prefix string, properties map[string]any, keys map[string]bool) ([]graphMLData, error) {
+	var data []graphMLData
+	for _, name := range slices.Sorted(maps.Keys(properties)) {
+		value, ok := properties[name].(string)
+		if !ok {
+			encoded, err := json.Marshal(properties[name])
+			if err != nil {
+				return nil, fmt.Errorf("property %s: %w", name, err)
+			}
+			value = string(encoded)
+		}
+		keys[name] = true
+		data = append(data, graphMLData{Key: prefix + name, Value: value})
+	}
+	return data, nil
+}
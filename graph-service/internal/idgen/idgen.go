@@ -0,0 +1,143 @@
+// Package idgen makes the ids the server assigns to products and SKUs
+// created without one. Every generator's ids sort, as strings, in the
+// order they were made, and are unique across replicas: at random for
+// ULIDs and UUIDv7, by node number for snowflakes.
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Generator makes ids.
+type Generator interface {
+	NewID() string
+}
+
+// New returns the generator kind names: "uuid7", "ulid" or "snowflake".
+// node tells this replica's snowflakes apart, from 0 to 1023.
+func New(kind string, node int) (Generator, error) {
+	switch kind {
+	case "", "uuid7":
+		return UUIDv7{}, nil
+	case "ulid":
+		return &ULID{}, nil
+	case "snowflake":
+		if node < 0 || node > maxNode {
+			return nil, fmt.Errorf("snowflake node %d is outside 0-%d", node, maxNode)
+		}
+		return &Snowflake{Node: int64(node)}, nil
+	default:
+		return nil, fmt.Errorf("unknown id generator %q (want uuid7, ulid or snowflake)", kind)
+	}
+}
+
+// UUIDv7 makes time-ordered UUIDs (RFC 9562), e.g.
+// 0192a3e4-5b6c-7d8e-9f01-23456789abcd.
+type UUIDv7 struct{}
+
+func (UUIDv7) NewID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID makes ULIDs, e.g. 01J9ZQ4XKX8M2C6V3T1B5N7D9F: a millisecond
+// timestamp and 80 random bits. Ids made in the same millisecond
+// increment the random part, so they still sort in order.
+type ULID struct {
+	mu     sync.Mutex
+	lastMs uint64
+	last   [10]byte
+}
+
+func (g *ULID) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(time.Now().UnixMilli())
+	if ms <= g.lastMs {
+		// Same millisecond, or the clock went back: keep counting from the
+		// last id
+		ms = g.lastMs
+		for i := len(g.last) - 1; i >= 0; i-- {
+			g.last[i]++
+			if g.last[i] != 0 {
+				break
+			}
+		}
+	} else {
+		rand.Read(g.last[:])
+	}
+	g.lastMs = ms
+
+	var id [16]byte
+	id[0], id[1], id[2] = byte(ms>>40), byte(ms>>32), byte(ms>>24)
+	id[3], id[4], id[5] = byte(ms>>16), byte(ms>>8), byte(ms)
+	copy(id[6:], g.last[:])
+	return encodeULID(id)
+}
+
+// encodeULID writes 128 bits as 26 Crockford base32 characters, the
+// first carrying 3 bits.
+func encodeULID(id [16]byte) string {
+	hi, lo := binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:])
+	out := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out)
+}
+
+const (
+	nodeBits     = 10
+	sequenceBits = 12
+	maxNode      = 1<<nodeBits - 1
+	maxSequence  = 1<<sequenceBits - 1
+)
+
+// snowflakeEpoch is where snowflake timestamps count from.
+var snowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+// Snowflake makes 63-bit ids: milliseconds since 2024, the node and a
+// sequence within the millisecond, written as 19 zero-padded digits so
+// they sort as strings. Each node makes up to 4096 a millisecond.
+type Snowflake struct {
+	Node int64
+
+	mu       sync.Mutex
+	lastMs   int64
+	sequence int64
+}
+
+func (g *Snowflake) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := max(time.Now().UnixMilli()-snowflakeEpoch, g.lastMs)
+	if ms == g.lastMs {
+		g.sequence = (g.sequence + 1) & maxSequence
+		if g.sequence == 0 {
+			// The millisecond's ids are used up; wait for the next
+			for ms <= g.lastMs {
+				time.Sleep(100 * time.Microsecond)
+				ms = time.Now().UnixMilli() - snowflakeEpoch
+			}
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastMs = ms
+
+	id := ms<<(nodeBits+sequenceBits) | g.Node<<sequenceBits | g.sequence
+	s := strconv.FormatInt(id, 10)
+	return "0000000000000000000"[len(s):] + s
+}
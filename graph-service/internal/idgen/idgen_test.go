@@ -0,0 +1,155 @@
+package idgen
+
+import (
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		kind    string
+		node    int
+		want    Generator
+		wantErr bool
+	}{
+		{kind: "", want: UUIDv7{}},
+		{kind: "uuid7", want: UUIDv7{}},
+		{kind: "ulid", want: &ULID{}},
+		{kind: "snowflake", node: 0, want: &Snowflake{}},
+		{kind: "snowflake", node: maxNode, want: &Snowflake{Node: maxNode}},
+		{kind: "snowflake", node: -1, wantErr: true},
+		{kind: "snowflake", node: maxNode + 1, wantErr: true},
+		{kind: "uuid4", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind+"/"+strconv.Itoa(tt.node), func(t *testing.T) {
+			got, err := New(tt.kind, tt.node)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New(%q, %d) error = %v, want error %v", tt.kind, tt.node, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("New(%q, %d) = %#v, want %#v", tt.kind, tt.node, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortedAndUnique(t *testing.T) {
+	tests := []struct {
+		name   string
+		gen    Generator
+		format *regexp.Regexp
+	}{
+		{"uuid7", UUIDv7{}, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)},
+		{"ulid", &ULID{}, regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)},
+		{"snowflake", &Snowflake{Node: 7}, regexp.MustCompile(`^[0-9]{19}$`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := make([]string, 5000)
+			for i := range ids {
+				ids[i] = tt.gen.NewID()
+				if !tt.format.MatchString(ids[i]) {
+					t.Fatalf("NewID = %q, want it to match %s", ids[i], tt.format)
+				}
+			}
+			if !slices.IsSorted(ids) {
+				t.Errorf("ids don't sort in the order they were made")
+			}
+			if len(slices.Compact(slices.Clone(ids))) != len(ids) {
+				t.Errorf("ids repeat")
+			}
+		})
+	}
+}
+
+func TestUUIDv7Version(t *testing.T) {
+	id := uuid.MustParse(UUIDv7{}.NewID())
+	if id.Version() != 7 || id.Variant() != uuid.RFC4122 {
+		t.Fatalf("NewID = %s, version %d variant %s", id, id.Version(), id.Variant())
+	}
+}
+
+func TestEncodeULID(t *testing.T) {
+	var ones [16]byte
+	for i := range ones {
+		ones[i] = 0xff
+	}
+	tests := []struct {
+		name string
+		id   [16]byte
+		want string
+	}{
+		{"zero", [16]byte{}, "00000000000000000000000000"},
+		{"max", ones, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ"},
+		{"low bit", [16]byte{15: 1}, "00000000000000000000000001"},
+		{"low five bits", [16]byte{15: 31}, "0000000000000000000000000Z"},
+		{"sixth bit", [16]byte{15: 32}, "00000000000000000000000010"},
+		{"high bit", [16]byte{0: 0x80}, "40000000000000000000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := encodeULID(tt.id); got != tt.want {
+				t.Errorf("encodeULID(%x) = %s, want %s", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestULIDSameMillisecond(t *testing.T) {
+	// A last id from the future stands in for a clock that went back
+	future := uint64(time.Now().Add(time.Hour).UnixMilli())
+	tests := []struct {
+		name string
+		last [10]byte
+		want [10]byte
+	}{
+		{"increments", [10]byte{9: 1}, [10]byte{9: 2}},
+		{"carries", [10]byte{8: 1, 9: 0xff}, [10]byte{8: 2, 9: 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &ULID{lastMs: future, last: tt.last}
+			before := encodeULID(ulidBytes(future, tt.last))
+			got := g.NewID()
+			if want := encodeULID(ulidBytes(future, tt.want)); got != want {
+				t.Errorf("NewID = %s, want %s", got, want)
+			}
+			if got <= before {
+				t.Errorf("NewID = %s, not after %s", got, before)
+			}
+		})
+	}
+}
+
+func TestSnowflakeLayout(t *testing.T) {
+	g := &Snowflake{Node: 5}
+	start := time.Now().UnixMilli() - snowflakeEpoch
+	first, _ := strconv.ParseInt(g.NewID(), 10, 64)
+	second, _ := strconv.ParseInt(g.NewID(), 10, 64)
+
+	if node := first >> sequenceBits & maxNode; node != 5 {
+		t.Errorf("node = %d, want 5", node)
+	}
+	if ms := first >> (nodeBits + sequenceBits); ms < start || ms > start+1000 {
+		t.Errorf("timestamp = %d, want about %d", ms, start)
+	}
+	if first>>sequenceBits == second>>sequenceBits && second&maxSequence != first&maxSequence+1 {
+		t.Errorf("sequence in the same millisecond = %d then %d", first&maxSequence, second&maxSequence)
+	}
+}
+
+func ulidBytes(ms uint64, random [10]byte) [16]byte {
+	var id [16]byte
+	for i := range 6 {
+		id[i] = byte(ms >> (40 - 8*i))
+	}
+	copy(id[6:], random[:])
+	return id
+}
@@ -0,0 +1,60 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/accesslog"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/redact"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// AccessLog records every call with logger: its method, caller, outcome,
+// timing and sizes, and for a sample of calls and those failing with a
+// server fault, the redacted request. Place it first so calls other
+// interceptors reject are recorded too; Authorize fills in the caller.
+func AccessLog(logger *accesslog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		entry := &accesslog.Entry{
+			Time:   time.Now(),
+			Method: info.FullMethod,
+		}
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			entry.Tenant = first(md.Get(TenantHeader))
+		}
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			entry.Peer = p.Addr.String()
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+			entry.TraceID = sc.TraceID().String()
+		}
+		sampled := logger.Sampled()
+
+		resp, err := handler(accesslog.WithEntry(ctx, entry), req)
+
+		entry.DurationMS = float64(time.Since(entry.Time).Microseconds()) / 1000
+		code := status.Code(err)
+		entry.Code = code.String()
+		if err != nil {
+			entry.Error = redact.Error(err)
+		}
+		if m, ok := req.(proto.Message); ok {
+			entry.RequestBytes = proto.Size(m)
+			if sampled || code == codes.Unknown || code == codes.Internal {
+				logger.Capture(entry, m)
+			}
+		}
+		if m, ok := resp.(proto.Message); ok && err == nil {
+			entry.ResponseBytes = proto.Size(m)
+		}
+		logger.Log(*entry)
+
+		return resp, err
+	}
+}
@@ -0,0 +1,88 @@
+package interceptors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func testKeyring(t *testing.T) *auth.Keyring {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	err := os.WriteFile(path, []byte(`{"keys": [
+		{"key": "acme-key", "principal": "acme-editor", "roles": {"acme": "catalog-editor"}},
+		{"key": "ops-key", "principal": "ops", "roles": {"*": "admin"}}
+	]}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := auth.LoadKeyring(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+// TestAuthorizeOtherTenant checks a tenant-A key is refused whichever way
+// it reaches for tenant B: by header, by a tenant_id in the request, or by
+// the id of something of tenant B that the handler looks up.
+func TestAuthorizeOtherTenant(t *testing.T) {
+	interceptor := Authorize(testKeyring(t))
+
+	// owners stands in for the graph: what each id belongs to
+	owners := map[string]string{"p-acme": "acme", "p-globex": "globex"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		id := req.(*pb.GetProductRequest).GetId()
+		if err := auth.CheckTenant(ctx, "product", id, owners[id]); err != nil {
+			return nil, err
+		}
+		return &pb.GetProductResponse{}, nil
+	}
+	create := func(ctx context.Context, req any) (any, error) {
+		return &pb.CreateProductResponse{}, nil
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		tenant  string
+		method  string
+		req     proto.Message
+		handler grpc.UnaryHandler
+		want    codes.Code
+	}{
+		{"own product", "acme-key", "", "GetProduct", &pb.GetProductRequest{Id: "p-acme"}, handler, codes.OK},
+		{"other tenant's product", "acme-key", "", "GetProduct", &pb.GetProductRequest{Id: "p-globex"}, handler, codes.PermissionDenied},
+		{"other tenant's product, own tenant named", "acme-key", "acme", "GetProduct", &pb.GetProductRequest{Id: "p-globex"}, handler, codes.PermissionDenied},
+		{"other tenant named", "acme-key", "globex", "GetProduct", &pb.GetProductRequest{Id: "p-globex"}, handler, codes.PermissionDenied},
+		{"any tenant named", "acme-key", auth.AnyTenant, "GetProduct", &pb.GetProductRequest{Id: "p-globex"}, handler, codes.PermissionDenied},
+		{"other tenant_id in the request", "acme-key", "", "CreateProduct",
+			&pb.CreateProductRequest{Product: &pb.Product{TenantId: "globex"}}, create, codes.PermissionDenied},
+		{"any-tenant key", "ops-key", "", "GetProduct", &pb.GetProductRequest{Id: "p-globex"}, handler, codes.OK},
+		{"any-tenant key, tenant named", "ops-key", "acme", "GetProduct", &pb.GetProductRequest{Id: "p-globex"}, handler, codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := metadata.Pairs(APIKeyHeader, tt.key)
+			if tt.tenant != "" {
+				md.Set(TenantHeader, tt.tenant)
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+			info := &grpc.UnaryServerInfo{FullMethod: "/graph.GraphService/" + tt.method}
+
+			_, err := interceptor(ctx, tt.req, info, tt.handler)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("call = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
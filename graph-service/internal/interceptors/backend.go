@@ -0,0 +1,25 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Only rejects every method not in methods with Unimplemented, naming the
+// storage backend that lacks it.
+func Only(backend string, methods []string) grpc.UnaryServerInterceptor {
+	allowed := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		allowed[m] = true
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !allowed[info.FullMethod] {
+			return nil, status.Errorf(codes.Unimplemented, "%s is not supported by the %s storage backend", info.FullMethod, backend)
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,61 @@
+package interceptors
+
+import (
+	"context"
+	"log"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// BookmarkHeader carries Neo4j bookmarks in both directions. Clients that
+// need read-your-writes send back the values they received from a write.
+const BookmarkHeader = "neo4j-bookmark"
+
+// Bookmarks threads Neo4j bookmarks from request metadata into the
+// repository and returns the resulting bookmarks as response headers.
+func Bookmarks() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var in []string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			in = md.Get(BookmarkHeader)
+		}
+
+		ctx = repository.WithBookmarks(ctx, in)
+		resp, err := handler(ctx, req)
+
+		if out := repository.LastBookmarks(ctx); len(out) > 0 {
+			md := metadata.MD{}
+			md.Append(BookmarkHeader, out...)
+			if headerErr := grpc.SetHeader(ctx, md); headerErr != nil {
+				log.Printf("bookmarks: set header for %s: %v", info.FullMethod, headerErr)
+			}
+		}
+
+		return resp, err
+	}
+}
+
+// StreamBookmarks is Bookmarks for streaming calls. Their headers go out
+// with the first message, before the call is done with the database, so
+// the resulting bookmarks are returned as trailers instead.
+func StreamBookmarks() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		var in []string
+		if md, ok := metadata.FromIncomingContext(ss.Context()); ok {
+			in = md.Get(BookmarkHeader)
+		}
+
+		ctx := repository.WithBookmarks(ss.Context(), in)
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+
+		if out := repository.LastBookmarks(ctx); len(out) > 0 {
+			md := metadata.MD{}
+			md.Append(BookmarkHeader, out...)
+			ss.SetTrailer(md)
+		}
+
+		return err
+	}
+}
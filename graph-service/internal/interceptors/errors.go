@@ -0,0 +1,143 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/breaker"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/payments"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/quota"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/redact"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/risk"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// ErrorDomain is the ErrorInfo domain for reasons raised by this service.
+const ErrorDomain = "graph-service.ecom-tts"
+
+// ErrorInfo reasons clients can switch on.
+const (
+	ReasonInvalidArgument     = "INVALID_ARGUMENT"
+	ReasonNotFound            = "NOT_FOUND"
+	ReasonInsufficientStock   = "INSUFFICIENT_STOCK"
+	ReasonInsufficientBalance = "INSUFFICIENT_BALANCE"
+	ReasonReservationClosed   = "RESERVATION_CLOSED"
+	ReasonPaymentDeclined     = "PAYMENT_DECLINED"
+	ReasonOrderDeclined       = "ORDER_DECLINED"
+	ReasonCouponNotApplicable = "COUPON_NOT_APPLICABLE"
+	ReasonInvalidTransition   = "INVALID_TRANSITION"
+	ReasonPayloadTooLarge     = "PAYLOAD_TOO_LARGE"
+	ReasonDatabaseUnavailable = "DATABASE_UNAVAILABLE"
+	ReasonCircuitOpen         = "CIRCUIT_OPEN"
+	ReasonUnsupported         = "UNSUPPORTED_BY_BACKEND"
+	ReasonAlreadyExists       = "ALREADY_EXISTS"
+	ReasonTenantQuota         = "TENANT_QUOTA_EXCEEDED"
+	ReasonStreamInterrupted   = "STREAM_INTERRUPTED"
+	ReasonNotConfigured       = "NOT_CONFIGURED"
+)
+
+// Errors converts handler errors into gRPC statuses with google.rpc error
+// details: validation failures become InvalidArgument with a BadRequest
+// field violation, and every mapped error carries an ErrorInfo reason.
+// Errors that are already statuses pass through unchanged; anything
+// unrecognised keeps the default Unknown code. Email addresses are masked
+// in messages, which driver errors can quote.
+func Errors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			err = toStatus(err)
+		}
+		return resp, err
+	}
+}
+
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var fieldErr *repository.FieldError
+	var limitErr *repository.LimitError
+	var conflict *repository.ConflictError
+	switch {
+	case errors.As(err, &limitErr):
+		return withDetails(codes.ResourceExhausted, err, ReasonPayloadTooLarge, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       limitErr.Field,
+				Description: redact.Error(err),
+			}},
+		})
+	case errors.As(err, &fieldErr):
+		return withDetails(codes.InvalidArgument, err, ReasonInvalidArgument, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       fieldErr.Field,
+				Description: redact.String(fieldErr.Description),
+			}},
+		})
+	case errors.As(err, &conflict):
+		return withErrorInfo(codes.AlreadyExists, err, &errdetails.ErrorInfo{
+			Reason: ReasonAlreadyExists,
+			Domain: ErrorDomain,
+			Metadata: map[string]string{
+				conflict.Field: conflict.Value,
+				"product_id":   conflict.ProductID,
+			},
+		})
+	case errors.Is(err, repository.ErrNotFound):
+		return withDetails(codes.NotFound, err, ReasonNotFound)
+	case errors.Is(err, repository.ErrInsufficientStock):
+		return withDetails(codes.FailedPrecondition, err, ReasonInsufficientStock)
+	case errors.Is(err, repository.ErrInsufficientBalance):
+		return withDetails(codes.FailedPrecondition, err, ReasonInsufficientBalance)
+	case errors.Is(err, repository.ErrReservationClosed):
+		return withDetails(codes.FailedPrecondition, err, ReasonReservationClosed)
+	case errors.Is(err, repository.ErrCouponNotApplicable):
+		return withDetails(codes.FailedPrecondition, err, ReasonCouponNotApplicable)
+	case errors.Is(err, repository.ErrInvalidTransition):
+		return withDetails(codes.FailedPrecondition, err, ReasonInvalidTransition)
+	case errors.Is(err, payments.ErrDeclined):
+		return withDetails(codes.FailedPrecondition, err, ReasonPaymentDeclined)
+	case errors.Is(err, risk.ErrDeclined):
+		return withDetails(codes.FailedPrecondition, err, ReasonOrderDeclined)
+	case errors.Is(err, repository.ErrStreamInterrupted):
+		return withDetails(codes.Aborted, err, ReasonStreamInterrupted)
+	case errors.Is(err, repository.ErrUnsupported):
+		return withDetails(codes.Unimplemented, err, ReasonUnsupported)
+	case errors.Is(err, repository.ErrNotConfigured):
+		return withDetails(codes.FailedPrecondition, err, ReasonNotConfigured)
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, redact.Error(err))
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, redact.Error(err))
+	case errors.Is(err, quota.ErrExceeded):
+		return withDetails(codes.ResourceExhausted, err, ReasonTenantQuota)
+	case errors.Is(err, breaker.ErrOpen):
+		return withDetails(codes.Unavailable, err, ReasonCircuitOpen)
+	case neo4j.IsConnectivityError(err), neo4j.IsTransactionExecutionLimit(err):
+		return withDetails(codes.Unavailable, err, ReasonDatabaseUnavailable)
+	}
+	return status.Error(codes.Unknown, redact.Error(err))
+}
+
+func withDetails(code codes.Code, err error, reason string, extra ...protoadapt.MessageV1) error {
+	return withErrorInfo(code, err, &errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: ErrorDomain,
+	}, extra...)
+}
+
+func withErrorInfo(code codes.Code, err error, info *errdetails.ErrorInfo, extra ...protoadapt.MessageV1) error {
+	st := status.New(code, redact.Error(err))
+	details := append([]protoadapt.MessageV1{info}, extra...)
+	if withDetails, detailErr := st.WithDetails(details...); detailErr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
@@ -0,0 +1,45 @@
+package interceptors
+
+import (
+	"context"
+	"log"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/etag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Conditional request headers. A read that tags its response returns the
+// tag in ETagHeader; sent back in IfNoneMatchHeader, an unchanged entity
+// is answered with an empty response and NotModifiedHeader set to "true".
+const (
+	IfNoneMatchHeader = "if-none-match"
+	ETagHeader        = "etag"
+	NotModifiedHeader = "x-not-modified"
+)
+
+// ETags threads If-None-Match from request metadata to reads that tag
+// their responses, and returns their tags as response headers.
+func ETags() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var ifNoneMatch []string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			ifNoneMatch = md.Get(IfNoneMatchHeader)
+		}
+
+		ctx = etag.WithRequest(ctx, ifNoneMatch)
+		resp, err := handler(ctx, req)
+
+		if tag, notModified := etag.Result(ctx); tag != "" && err == nil {
+			md := metadata.Pairs(ETagHeader, tag)
+			if notModified {
+				md.Append(NotModifiedHeader, "true")
+			}
+			if headerErr := grpc.SetHeader(ctx, md); headerErr != nil {
+				log.Printf("etag: set header for %s: %v", info.FullMethod, headerErr)
+			}
+		}
+
+		return resp, err
+	}
+}
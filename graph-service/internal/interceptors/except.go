@@ -0,0 +1,24 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// Except runs interceptor on every method but those in methods, which go
+// straight on down the chain. Health checks use it to skip
+// authentication and limits meant for API calls.
+func Except(methods []string, interceptor grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	exempt := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		exempt[m] = true
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if exempt[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		return interceptor(ctx, req, info, handler)
+	}
+}
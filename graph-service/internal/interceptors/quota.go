@@ -0,0 +1,48 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/quota"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Quota admits calls through limiter by who makes them and charges each
+// the Neo4j time it used. Calls over quota fail with ResourceExhausted;
+// place it after Errors so they do, and after Authorize so calls are
+// counted against the authenticated caller and the tenant it authorized,
+// not a header any caller can vary.
+func Quota(limiter *quota.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		done, err := limiter.Admit(ctx, quotaKey(ctx))
+		if err != nil {
+			return nil, err
+		}
+		ctx = repository.WithQueryTimer(ctx)
+		defer func() { done(repository.QueryTime(ctx)) }()
+
+		return handler(ctx, req)
+	}
+}
+
+// quotaKey names the caller's share of the quota: its principal and the
+// tenant it was confined to, or all tenants. Without authentication there
+// is no principal, and the tenant header is all there is.
+func quotaKey(ctx context.Context) string {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		var tenant string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			tenant = first(md.Get(TenantHeader))
+		}
+		return tenant
+	}
+	tenant, ok := auth.TenantFrom(ctx)
+	if !ok {
+		tenant = auth.AnyTenant
+	}
+	return principal.Name + "/" + tenant
+}
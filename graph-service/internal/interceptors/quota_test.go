@@ -0,0 +1,108 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/quota"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestQuotaKey(t *testing.T) {
+	acme := auth.Principal{Name: "acme-editor", Roles: map[string]auth.Role{"acme": auth.RoleCatalogEditor}}
+	ops := auth.Principal{Name: "ops", Roles: map[string]auth.Role{auth.AnyTenant: auth.RoleAdmin}}
+	header := func(tenant string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(TenantHeader, tenant))
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"unauthenticated, no header", context.Background(), ""},
+		{"unauthenticated, by header", header("acme"), "acme"},
+		{"confined", auth.WithTenant(auth.WithPrincipal(context.Background(), acme), "acme"), "acme-editor/acme"},
+		{"header ignored", auth.WithTenant(auth.WithPrincipal(header("globex"), acme), "acme"), "acme-editor/acme"},
+		{"all tenants", auth.WithPrincipal(context.Background(), ops), "ops/*"},
+		{"all tenants, header ignored", auth.WithPrincipal(header("globex"), ops), "ops/*"},
+		{"one tenant of many", auth.WithTenant(auth.WithPrincipal(context.Background(), ops), "acme"), "ops/acme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quotaKey(tt.ctx); got != tt.want {
+				t.Errorf("quotaKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestQuotaByPrincipal makes a second call while a first holds the only
+// slot, and checks the two share a quota exactly when the same principal
+// acts on the same tenant, whatever else their headers say.
+func TestQuotaByPrincipal(t *testing.T) {
+	keys := testKeyring(t)
+	info := &grpc.UnaryServerInfo{FullMethod: "/graph.GraphService/GetCategoryTree"}
+	call := func(limiter *quota.Limiter, md metadata.MD, handler grpc.UnaryHandler) error {
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		_, err := Authorize(keys)(ctx, &pb.GetCategoryTreeRequest{}, info, func(ctx context.Context, req any) (any, error) {
+			return Quota(limiter)(ctx, req, info, handler)
+		})
+		return err
+	}
+
+	tests := []struct {
+		name         string
+		first, next  metadata.MD
+		wantExceeded bool
+	}{
+		{
+			name:         "same key",
+			first:        metadata.Pairs(APIKeyHeader, "acme-key"),
+			next:         metadata.Pairs(APIKeyHeader, "acme-key"),
+			wantExceeded: true,
+		},
+		{
+			name:         "same key, tenant named",
+			first:        metadata.Pairs(APIKeyHeader, "acme-key"),
+			next:         metadata.Pairs(APIKeyHeader, "acme-key", TenantHeader, "acme"),
+			wantExceeded: true,
+		},
+		{
+			name:  "another principal",
+			first: metadata.Pairs(APIKeyHeader, "acme-key"),
+			next:  metadata.Pairs(APIKeyHeader, "ops-key"),
+		},
+		{
+			name:  "same principal, another tenant",
+			first: metadata.Pairs(APIKeyHeader, "ops-key", TenantHeader, "acme"),
+			next:  metadata.Pairs(APIKeyHeader, "ops-key", TenantHeader, "globex"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := quota.New("quota_test/"+tt.name, quota.Limits{MaxInFlight: 1})
+
+			var nextErr error
+			err := call(limiter, tt.first, func(ctx context.Context, req any) (any, error) {
+				nextErr = call(limiter, tt.next, func(ctx context.Context, req any) (any, error) {
+					return &pb.GetCategoryTreeResponse{}, nil
+				})
+				return &pb.GetCategoryTreeResponse{}, nil
+			})
+			if err != nil {
+				t.Fatalf("first call = %v", err)
+			}
+			if tt.wantExceeded && !errors.Is(nextErr, quota.ErrExceeded) {
+				t.Errorf("second call = %v, want %v", nextErr, quota.ErrExceeded)
+			}
+			if !tt.wantExceeded && nextErr != nil {
+				t.Errorf("second call = %v, want it admitted", nextErr)
+			}
+		})
+	}
+}
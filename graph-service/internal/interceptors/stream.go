@@ -0,0 +1,46 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Stream runs a unary interceptor around streaming calls, so they're
+// authenticated, limited and logged as unary calls are. The interceptor
+// sees a nil request and response; the stream's handler runs with the
+// context it passes on.
+func Stream(interceptor grpc.UnaryServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		unaryInfo := &grpc.UnaryServerInfo{Server: srv, FullMethod: info.FullMethod}
+		_, err := interceptor(ss.Context(), nil, unaryInfo, func(ctx context.Context, _ any) (any, error) {
+			return nil, handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		})
+		return err
+	}
+}
+
+// serverStream is a stream with the context interceptors gave it.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecvMsg confines each request the stream receives to the call's tenant,
+// as Authorize does the unary requests it sees.
+func (s *serverStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	tenant, ok := auth.TenantFrom(s.ctx)
+	if msg, isMsg := m.(proto.Message); ok && isMsg {
+		return auth.ConfineRequest(msg, tenant)
+	}
+	return nil
+}
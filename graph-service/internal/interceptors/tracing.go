@@ -0,0 +1,29 @@
+package interceptors
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Trace annotates the RPC span started by the otelgrpc stats handler with
+// the caller's session id and, for calls returning products, how many.
+func Trace() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if session := first(md.Get(tracing.SessionHeader)); session != "" {
+				tracing.Annotate(ctx, tracing.SessionID.String(session))
+			}
+		}
+
+		resp, err := handler(ctx, req)
+
+		if r, ok := resp.(interface{ GetProducts() []*pb.Product }); ok && err == nil {
+			tracing.Annotate(ctx, tracing.ResultCount.Int(len(r.GetProducts())))
+		}
+		return resp, err
+	}
+}
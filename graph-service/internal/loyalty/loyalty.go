@@ -0,0 +1,60 @@
+// Package loyalty works out the points purchases earn and the tiers point
+// totals reach.
+package loyalty
+
+import (
+	"math"
+	"sort"
+)
+
+// Line is one purchased line. Amount is what was paid for it before tax,
+// in the currency's minor units.
+type Line struct {
+	Category string
+	Amount   int64
+}
+
+// Tier is reached once a customer has earned Threshold points in total.
+type Tier struct {
+	Name      string
+	Threshold int64
+}
+
+// Program sets how many points purchases earn, per whole currency unit
+// spent, and the tiers customers climb.
+type Program struct {
+	// Rate applies to lines whose category has no rate in Categories.
+	Rate       float64
+	Categories map[string]float64
+	Tiers      []Tier
+}
+
+// Earn returns the points lines earn. Each line is rounded down on its
+// own, so no line earns for a fraction of a unit.
+func (p *Program) Earn(lines []Line) int64 {
+	var points int64
+	for _, line := range lines {
+		rate, ok := p.Categories[line.Category]
+		if !ok {
+			rate = p.Rate
+		}
+		if rate > 0 && line.Amount > 0 {
+			points += int64(math.Floor(float64(line.Amount) / 100 * rate))
+		}
+	}
+	return points
+}
+
+// Tier returns the highest tier earned reaches and the one after it, if
+// any. Below the first threshold the current tier is empty.
+func (p *Program) Tier(earned int64) (current, next Tier) {
+	tiers := append([]Tier(nil), p.Tiers...)
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].Threshold < tiers[j].Threshold })
+	for _, tier := range tiers {
+		if earned < tier.Threshold {
+			return current, tier
+		}
+		current = tier
+	}
+	return current, Tier{}
+}
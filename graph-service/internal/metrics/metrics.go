@@ -0,0 +1,34 @@
+// Package metrics publishes service metrics through expvar. Serve exposes
+// them as JSON at /debug/vars.
+package metrics
+
+import (
+	"expvar"
+	"net/http"
+	"sync"
+)
+
+var mu sync.Mutex
+
+// Map returns the published map with the given name, creating it on first
+// use so independent components can share a name without panicking.
+func Map(name string) *expvar.Map {
+	mu.Lock()
+	defer mu.Unlock()
+	if v, ok := expvar.Get(name).(*expvar.Map); ok {
+		return v
+	}
+	return expvar.NewMap(name)
+}
+
+// Serve listens on addr and serves /debug/vars until the listener fails.
+func Serve(addr string) error {
+	return http.ListenAndServe(addr, Handler())
+}
+
+// Handler serves the metrics at /debug/vars.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
@@ -0,0 +1,69 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTP screens texts with the moderation service the orchestrator's
+// safety filter uses: the text is POSTed as {"text": "..."} and the
+// service answers {"flagged": true, "categories": ["..."]}.
+type HTTP struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTP screens texts with the service at url, giving up after timeout.
+func NewHTTP(url string, timeout time.Duration) *HTTP {
+	return &HTTP{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (h *HTTP) Screen(ctx context.Context, text string) (Verdict, error) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return Verdict{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return Verdict{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("moderation service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Verdict{}, fmt.Errorf("moderation service: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return Verdict{}, fmt.Errorf("moderation service: %d %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	var v struct {
+		Flagged    bool     `json:"flagged"`
+		Categories []string `json:"categories"`
+	}
+	if err := json.Unmarshal(respBody, &v); err != nil {
+		return Verdict{}, fmt.Errorf("moderation service: %w", err)
+	}
+	if !v.Flagged {
+		return Verdict{}, nil
+	}
+	// Like the safety filter, a flag without categories is still a flag
+	if len(v.Categories) == 0 {
+		v.Categories = []string{"flagged"}
+	}
+	return Verdict{Flagged: true, Categories: v.Categories}, nil
+}
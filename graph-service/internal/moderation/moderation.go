@@ -0,0 +1,25 @@
+// Package moderation screens what customers post before a moderator sees
+// it, so posts an automated check flags can be held back and clean ones
+// approved without waiting.
+package moderation
+
+import "context"
+
+// Verdict is what screening made of a text: flagged, and for what, or
+// clean.
+type Verdict struct {
+	Flagged    bool
+	Categories []string
+}
+
+// Screener checks a text.
+type Screener interface {
+	Screen(ctx context.Context, text string) (Verdict, error)
+}
+
+// ScreenerFunc adapts a function to Screener.
+type ScreenerFunc func(ctx context.Context, text string) (Verdict, error)
+
+func (f ScreenerFunc) Screen(ctx context.Context, text string) (Verdict, error) {
+	return f(ctx, text)
+}
@@ -0,0 +1,52 @@
+// Package notify delivers user-facing notifications.
+package notify
+
+import (
+	"context"
+	"errors"
+	"log"
+)
+
+// Notification is a message for one user. Kind is an event type from the
+// events package, so notifications can travel the same pipelines as
+// catalog events.
+type Notification struct {
+	UserID  string `json:"user_id"`
+	Kind    string `json:"kind"`
+	Subject string `json:"subject"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// Notifier delivers notifications to users.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NotifierFunc adapts a function to the Notifier interface.
+type NotifierFunc func(ctx context.Context, n Notification) error
+
+func (f NotifierFunc) Notify(ctx context.Context, n Notification) error {
+	return f(ctx, n)
+}
+
+// Log writes notifications to the standard logger.
+type Log struct{}
+
+func (Log) Notify(ctx context.Context, n Notification) error {
+	log.Printf("notify: %s to %s: %s", n.Kind, n.UserID, n.Subject)
+	return nil
+}
+
+// Multi sends every notification to each notifier, returning their
+// combined errors.
+func Multi(notifiers ...Notifier) Notifier {
+	return NotifierFunc(func(ctx context.Context, n Notification) error {
+		var errs []error
+		for _, notifier := range notifiers {
+			if err := notifier.Notify(ctx, n); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
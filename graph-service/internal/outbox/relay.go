@@ -0,0 +1,104 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+)
+
+// Store is the transactional outbox the relay drains.
+type Store interface {
+	ListUnpublishedEvents(ctx context.Context, limit int) ([]repository.OutboxEvent, error)
+	MarkEventPublished(ctx context.Context, id string) error
+}
+
+// Publisher hands a committed event to a downstream transport.
+type Publisher interface {
+	Publish(ctx context.Context, eventType string, payload []byte) error
+}
+
+// PublisherFunc adapts a function to the Publisher interface.
+type PublisherFunc func(ctx context.Context, eventType string, payload []byte) error
+
+func (f PublisherFunc) Publish(ctx context.Context, eventType string, payload []byte) error {
+	return f(ctx, eventType, payload)
+}
+
+// Relay publishes outbox events in commit order and marks them published.
+// Delivery is at-least-once: a crash between publish and mark replays the
+// event, so consumers should dedupe on the event ID.
+type Relay struct {
+	store      Store
+	publishers []Publisher
+
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+func NewRelay(store Store, publishers ...Publisher) *Relay {
+	return &Relay{
+		store:        store,
+		publishers:   publishers,
+		PollInterval: defaultPollInterval,
+		BatchSize:    defaultBatchSize,
+	}
+}
+
+// Run relays events until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		batch, err := r.store.ListUnpublishedEvents(ctx, r.BatchSize)
+		if err != nil {
+			log.Printf("outbox: list events: %v", err)
+			return
+		}
+
+		for _, ev := range batch {
+			// Stop at the first failure so later events are never
+			// published ahead of an earlier one.
+			if err := r.publish(ctx, ev); err != nil {
+				log.Printf("outbox: publish %s %s: %v", ev.Type, ev.ID, err)
+				return
+			}
+			if err := r.store.MarkEventPublished(ctx, ev.ID); err != nil {
+				log.Printf("outbox: mark %s published: %v", ev.ID, err)
+				return
+			}
+		}
+
+		if len(batch) < r.BatchSize {
+			return
+		}
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, ev repository.OutboxEvent) error {
+	for _, p := range r.publishers {
+		if err := p.Publish(ctx, ev.Type, ev.Payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
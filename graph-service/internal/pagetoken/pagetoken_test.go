@@ -0,0 +1,118 @@
+package pagetoken
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fixedCodec(secret string, now time.Time) *Codec {
+	c := NewCodec(secret)
+	c.now = func() time.Time { return now }
+	return c
+}
+
+func TestRoundTrip(t *testing.T) {
+	c := NewCodec("secret")
+	cur := Cursor{Scope: "products:t1", LastID: "p42", LastTime: 1700000000000, LastRank: 0.5}
+
+	got, err := c.Decode(c.Encode(cur), "products:t1")
+	if err != nil || got != cur {
+		t.Fatalf("Decode(Encode(%+v)) = %+v, %v", cur, got, err)
+	}
+
+	got, err = c.Decode("", "products:t1")
+	if err != nil || got != (Cursor{Scope: "products:t1"}) {
+		t.Fatalf("Decode(\"\") = %+v, %v, want the first page", got, err)
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	issued := time.Unix(1700000000, 0)
+	c := fixedCodec("secret", issued)
+	cur := Cursor{Scope: "products:t1", LastID: "p42"}
+	token := c.Encode(cur)
+	body, sig, _ := strings.Cut(token, ".")
+
+	// A body re-signed with another key, naming a different position
+	forged := fixedCodec("other", issued).Encode(Cursor{Scope: "products:t1", LastID: "p99"})
+	forgedBody, _, _ := strings.Cut(forged, ".")
+
+	tests := []struct {
+		name  string
+		token string
+		scope string
+		at    time.Time
+		want  error
+	}{
+		{"scope mismatch", token, "products:t2", issued, ErrInvalid},
+		{"scope prefix", token, "products:t", issued, ErrInvalid},
+		{"tampered body", forgedBody + "." + sig, "products:t1", issued, ErrInvalid},
+		{"other key", forged, "products:t1", issued, ErrInvalid},
+		{"truncated signature", body + "." + sig[:len(sig)-4], "products:t1", issued, ErrInvalid},
+		{"no signature", body, "products:t1", issued, ErrInvalid},
+		{"not base64", "!!." + sig, "products:t1", issued, ErrInvalid},
+		{"signed non-json", signed(c, "not json"), "products:t1", issued, ErrInvalid},
+		{"expired", token, "products:t1", issued.Add(DefaultTTL), ErrExpired},
+		{"long expired", token, "products:t1", issued.Add(30 * 24 * time.Hour), ErrExpired},
+		{"no expiry", signed(c, `{"s":"products:t1","id":"p42"}`), "products:t1", issued, ErrExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.now = func() time.Time { return tt.at }
+			got, err := c.Decode(tt.token, tt.scope)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Decode = %+v, %v, want %v", got, err, tt.want)
+			}
+			if got != (Cursor{}) {
+				t.Errorf("Decode = %+v, want the zero cursor on error", got)
+			}
+		})
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	issued := time.Unix(1700000000, 0)
+	c := fixedCodec("secret", issued)
+	c.TTL = time.Hour
+	cur := Cursor{Scope: "products", LastID: "p1"}
+	token := c.Encode(cur)
+	kept := c.EncodeKept(cur)
+
+	tests := []struct {
+		name  string
+		token string
+		at    time.Time
+		want  error
+	}{
+		{"fresh", token, issued, nil},
+		{"just before ttl", token, issued.Add(time.Hour - time.Second), nil},
+		{"at ttl", token, issued.Add(time.Hour), ErrExpired},
+		{"kept, a year on", kept, issued.Add(365 * 24 * time.Hour), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.now = func() time.Time { return tt.at }
+			if _, err := c.Decode(tt.token, "products"); !errors.Is(err, tt.want) {
+				t.Errorf("Decode = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRandomKeyPerCodec(t *testing.T) {
+	a, b := NewCodec(""), NewCodec("")
+	token := a.Encode(Cursor{Scope: "products", LastID: "p1"})
+	if _, err := b.Decode(token, "products"); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Decode with another random key = %v, want ErrInvalid", err)
+	}
+}
+
+// signed returns body as a correctly signed token, to reach the checks
+// made after the signature.
+func signed(c *Codec, body string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(body)) + "." +
+		base64.RawURLEncoding.EncodeToString(c.sign([]byte(body)))
+}
@@ -0,0 +1,136 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// DeclinedMethod is a payment method the mock provider always declines,
+// named after Stripe's test card.
+const DeclinedMethod = "pm_card_chargeDeclined"
+
+// Mock is an in-memory provider for development and tests. It approves
+// every payment method except DeclinedMethod and enforces the same
+// authorize, capture, void and refund sequence a real gateway does.
+type Mock struct {
+	mu       sync.Mutex
+	next     int
+	payments map[string]*mockPayment
+	byKey    map[string]mockAuthorization
+	refunds  map[string]string
+}
+
+// mockAuthorization is the hold an authorize idempotency key placed.
+type mockAuthorization struct {
+	id      string
+	orderID string
+}
+
+type mockPayment struct {
+	amount   int64
+	captured bool
+	voided   bool
+	refunded int64
+}
+
+func NewMock() *Mock {
+	return &Mock{
+		payments: map[string]*mockPayment{},
+		byKey:    map[string]mockAuthorization{},
+		refunds:  map[string]string{},
+	}
+}
+
+func (m *Mock) Authorize(ctx context.Context, req AuthorizeRequest) (string, error) {
+	if req.PaymentMethod == DeclinedMethod {
+		return "", fmt.Errorf("%w: card was declined", ErrDeclined)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := authorizeKey(req)
+	if auth, ok := m.byKey[key]; ok {
+		// Like Stripe, a key reused for another order is refused rather
+		// than replayed.
+		if auth.orderID != req.OrderID {
+			return "", fmt.Errorf("payments: idempotency key %s was used for order %s", key, auth.orderID)
+		}
+		return auth.id, nil
+	}
+	id := m.newID("auth")
+	m.payments[id] = &mockPayment{amount: req.Amount}
+	m.byKey[key] = mockAuthorization{id: id, orderID: req.OrderID}
+	return id, nil
+}
+
+func (m *Mock) Capture(ctx context.Context, authorizationID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, err := m.payment(authorizationID)
+	if err != nil {
+		return err
+	}
+	if p.voided {
+		return fmt.Errorf("payments: authorization %s was voided", authorizationID)
+	}
+	p.captured = true
+	return nil
+}
+
+func (m *Mock) Void(ctx context.Context, authorizationID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, err := m.payment(authorizationID)
+	if err != nil {
+		return err
+	}
+	if p.captured {
+		return fmt.Errorf("payments: authorization %s was captured", authorizationID)
+	}
+	p.voided = true
+	return nil
+}
+
+func (m *Mock) Refund(ctx context.Context, req RefundRequest) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if id, ok := m.refunds[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		return id, nil
+	}
+	p, err := m.payment(req.AuthorizationID)
+	if err != nil {
+		return "", err
+	}
+	if !p.captured {
+		return "", fmt.Errorf("payments: authorization %s was not captured", req.AuthorizationID)
+	}
+	if req.Amount <= 0 || p.refunded+req.Amount > p.amount {
+		return "", fmt.Errorf("payments: refund of %d exceeds the %d left on %s", req.Amount, p.amount-p.refunded, req.AuthorizationID)
+	}
+	p.refunded += req.Amount
+
+	id := m.newID("refund")
+	if req.IdempotencyKey != "" {
+		m.refunds[req.IdempotencyKey] = id
+	}
+	return id, nil
+}
+
+func (m *Mock) payment(id string) (*mockPayment, error) {
+	p, ok := m.payments[id]
+	if !ok {
+		return nil, fmt.Errorf("payments: unknown authorization %s", id)
+	}
+	return p, nil
+}
+
+func (m *Mock) newID(prefix string) string {
+	m.next++
+	return "mock_" + prefix + "_" + strconv.Itoa(m.next)
+}
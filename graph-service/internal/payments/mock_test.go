@@ -0,0 +1,56 @@
+package payments
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMockAuthorizeRetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		first, next AuthorizeRequest
+		same        bool
+		wantErr     bool
+	}{
+		{
+			name:  "same order",
+			first: AuthorizeRequest{OrderID: "o1", Amount: 100},
+			next:  AuthorizeRequest{OrderID: "o1", Amount: 100},
+			same:  true,
+		},
+		{
+			name:  "other order",
+			first: AuthorizeRequest{OrderID: "o1", Amount: 100},
+			next:  AuthorizeRequest{OrderID: "o2", Amount: 100},
+		},
+		{
+			name:  "same key",
+			first: AuthorizeRequest{OrderID: "o1", Amount: 100, IdempotencyKey: "k1"},
+			next:  AuthorizeRequest{OrderID: "o1", Amount: 100, IdempotencyKey: "k1"},
+			same:  true,
+		},
+		{
+			name:    "key reused for another order",
+			first:   AuthorizeRequest{OrderID: "o1", Amount: 100, IdempotencyKey: "k1"},
+			next:    AuthorizeRequest{OrderID: "o2", Amount: 100, IdempotencyKey: "k1"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMock()
+			ctx := context.Background()
+			first, err := m.Authorize(ctx, tt.first)
+			if err != nil {
+				t.Fatalf("Authorize = %v", err)
+			}
+			next, err := m.Authorize(ctx, tt.next)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("retry error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && (next == first) != tt.same {
+				t.Errorf("retry authorized %s after %s, want the same hold %v", next, first, tt.same)
+			}
+		})
+	}
+}
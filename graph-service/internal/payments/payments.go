@@ -0,0 +1,67 @@
+// Package payments authorizes, settles and refunds order payments through
+// a payment provider.
+package payments
+
+import (
+	"context"
+	"errors"
+	"math"
+)
+
+// ErrDeclined reports a payment the provider refused, such as a declined
+// card. Any other error means the provider could not be asked.
+var ErrDeclined = errors.New("payment declined")
+
+// AuthorizeRequest asks for a hold on the shopper's payment method.
+// Amounts throughout are in the currency's minor units, e.g. cents.
+type AuthorizeRequest struct {
+	OrderID  string
+	Amount   int64
+	Currency string
+	// PaymentMethod is the provider's token for the shopper's card or
+	// wallet, obtained client-side.
+	PaymentMethod string
+	// IdempotencyKey makes retries of the same authorization safe: a
+	// repeat returns the first hold instead of placing a second. Empty
+	// means the order id.
+	IdempotencyKey string
+}
+
+// authorizeKey is the idempotency key req is authorized under.
+func authorizeKey(req AuthorizeRequest) string {
+	if req.IdempotencyKey != "" {
+		return req.IdempotencyKey
+	}
+	return "authorize-" + req.OrderID
+}
+
+// RefundRequest returns Amount of a captured authorization.
+type RefundRequest struct {
+	AuthorizationID string
+	Amount          int64
+	// IdempotencyKey makes retries of the same refund safe.
+	IdempotencyKey string
+}
+
+// Provider is a payment gateway. An order's payment is authorized first,
+// which holds the funds, then captured once the order is committed. An
+// authorization that is never captured is voided; a captured payment is
+// refunded, in full or in parts.
+type Provider interface {
+	// Authorize holds the amount on the payment method and returns the
+	// authorization id.
+	Authorize(ctx context.Context, req AuthorizeRequest) (string, error)
+	// Capture settles an authorization in full.
+	Capture(ctx context.Context, authorizationID string) error
+	// Void releases an authorization that was not captured.
+	Void(ctx context.Context, authorizationID string) error
+	// Refund returns part or all of a captured payment and returns the
+	// refund id.
+	Refund(ctx context.Context, req RefundRequest) (string, error)
+}
+
+// MinorUnits converts an amount in major units, as prices are stored, to
+// minor units. Every currency is assumed to have two decimal places.
+func MinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
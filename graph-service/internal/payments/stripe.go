@@ -0,0 +1,131 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	stripeAPI            = "https://api.stripe.com"
+	stripeRequestTimeout = 30 * time.Second
+)
+
+// Stripe authorizes payments as manually captured PaymentIntents.
+// Payment methods that need the shopper to act, such as 3-D Secure, are
+// reported as declined: the order flow has no way to hand them back.
+type Stripe struct {
+	secretKey string
+	client    *http.Client
+	baseURL   string
+}
+
+func NewStripe(secretKey string) *Stripe {
+	return &Stripe{
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: stripeRequestTimeout},
+		baseURL:   stripeAPI,
+	}
+}
+
+type stripeObject struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+func (s *Stripe) Authorize(ctx context.Context, req AuthorizeRequest) (string, error) {
+	form := url.Values{
+		"amount":                             {strconv.FormatInt(req.Amount, 10)},
+		"currency":                           {strings.ToLower(req.Currency)},
+		"payment_method":                     {req.PaymentMethod},
+		"capture_method":                     {"manual"},
+		"confirm":                            {"true"},
+		"metadata[order_id]":                 {req.OrderID},
+		"automatic_payment_methods[enabled]": {"true"},
+		"automatic_payment_methods[allow_redirects]": {"never"},
+	}
+
+	var intent stripeObject
+	if err := s.post(ctx, "/v1/payment_intents", form, authorizeKey(req), &intent); err != nil {
+		return "", err
+	}
+	if intent.Status != "requires_capture" {
+		if err := s.Void(ctx, intent.ID); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("%w: payment intent is %s", ErrDeclined, intent.Status)
+	}
+	return intent.ID, nil
+}
+
+func (s *Stripe) Capture(ctx context.Context, authorizationID string) error {
+	return s.post(ctx, "/v1/payment_intents/"+url.PathEscape(authorizationID)+"/capture", nil, "capture-"+authorizationID, nil)
+}
+
+func (s *Stripe) Void(ctx context.Context, authorizationID string) error {
+	return s.post(ctx, "/v1/payment_intents/"+url.PathEscape(authorizationID)+"/cancel", nil, "void-"+authorizationID, nil)
+}
+
+func (s *Stripe) Refund(ctx context.Context, req RefundRequest) (string, error) {
+	form := url.Values{
+		"payment_intent": {req.AuthorizationID},
+		"amount":         {strconv.FormatInt(req.Amount, 10)},
+	}
+
+	var refund stripeObject
+	if err := s.post(ctx, "/v1/refunds", form, req.IdempotencyKey, &refund); err != nil {
+		return "", err
+	}
+	return refund.ID, nil
+}
+
+// post calls the Stripe API and decodes the response into out. Card
+// errors become ErrDeclined.
+func (s *Stripe) post(ctx context.Context, path string, form url.Values, idempotencyKey string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.secretKey, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("stripe: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("stripe: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Type    string `json:"type"`
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.Unmarshal(body, &apiErr)
+		if apiErr.Error.Type == "card_error" {
+			return fmt.Errorf("%w: %s", ErrDeclined, apiErr.Error.Message)
+		}
+		return fmt.Errorf("stripe: %s %s: %d %s", path, apiErr.Error.Code, resp.StatusCode, apiErr.Error.Message)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,45 @@
+package payments
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStripeAuthorizeKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{"order id", "", "authorize-o1"},
+		{"caller's key", "order-t1-retry-7", "order-t1-retry-7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Idempotency-Key")
+				w.Write([]byte(`{"id": "pi_1", "status": "requires_capture"}`))
+			}))
+			defer srv.Close()
+
+			s := NewStripe("sk_test")
+			s.baseURL = srv.URL
+			id, err := s.Authorize(context.Background(), AuthorizeRequest{
+				OrderID:        "o1",
+				Amount:         1999,
+				Currency:       "USD",
+				PaymentMethod:  "pm_card_visa",
+				IdempotencyKey: tt.key,
+			})
+			if err != nil || id != "pi_1" {
+				t.Fatalf("Authorize = %q, %v", id, err)
+			}
+			if got != tt.want {
+				t.Errorf("Idempotency-Key = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Package quota shares Neo4j between tenants, so one tenant's broad
+// searches can't starve the others.
+package quota
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/metrics"
+)
+
+// ErrExceeded is returned instead of admitting a call whose tenant is at
+// its limit.
+var ErrExceeded = errors.New("tenant query quota exceeded")
+
+// maxTenants caps the tenants a limiter tracks. Past it, tenants idle
+// for longer than idleAfter are dropped; calls of new tenants share one
+// entry while none are.
+const (
+	maxTenants = 10000
+	idleAfter  = 10 * time.Minute
+	overflow   = "(overflow)"
+)
+
+// Limits apply to each tenant separately. Zero disables a limit.
+type Limits struct {
+	// MaxInFlight caps a tenant's concurrent calls. Calls over it wait up
+	// to QueueWait for one to finish.
+	MaxInFlight int
+	QueueWait   time.Duration
+	// Budget caps the Neo4j time a tenant's calls use per Window. Calls
+	// over it are rejected until the window rolls over.
+	Budget time.Duration
+	Window time.Duration
+}
+
+// Limiter admits calls within their tenant's Limits.
+//
+// Metrics are published under the limiter's name, per tenant: in_flight,
+// queued (calls that had to wait), rejected and neo4j_ms. A tenant's
+// metrics go with it when it is dropped for being idle.
+type Limiter struct {
+	limits     Limits
+	stats      *expvar.Map
+	maxTenants int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	tenants map[string]*tenant
+}
+
+type tenant struct {
+	// slots holds a token per call in flight; nil without MaxInFlight.
+	slots chan struct{}
+	stats *expvar.Map
+
+	// Guarded by the limiter's mu. calls counts the calls holding t,
+	// admitted or waiting; t is dropped only without any.
+	windowStart time.Time
+	used        time.Duration
+	calls       int
+	lastUsed    time.Time
+}
+
+func New(name string, limits Limits) *Limiter {
+	return &Limiter{
+		limits:     limits,
+		stats:      metrics.Map(name),
+		maxTenants: maxTenants,
+		now:        time.Now,
+		tenants:    map[string]*tenant{},
+	}
+}
+
+// Admit admits a call for tenantID, the key a tenant's calls share,
+// waiting for a slot if the tenant has none free. The call must report
+// the Neo4j time it used to done. Admit
+// fails with ErrExceeded when the tenant is over budget or no slot frees
+// up in time, and with ctx's error if ctx ends first.
+func (l *Limiter) Admit(ctx context.Context, tenantID string) (done func(used time.Duration), err error) {
+	t := l.tenant(tenantID)
+	defer func() {
+		if err != nil {
+			l.release(t)
+		}
+	}()
+	if l.overBudget(t) {
+		t.stats.Add("rejected", 1)
+		return nil, ErrExceeded
+	}
+
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		default:
+			t.stats.Add("queued", 1)
+			timer := time.NewTimer(l.limits.QueueWait)
+			defer timer.Stop()
+			select {
+			case t.slots <- struct{}{}:
+			case <-timer.C:
+				t.stats.Add("rejected", 1)
+				return nil, ErrExceeded
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	t.stats.Add("in_flight", 1)
+	return func(used time.Duration) {
+		if t.slots != nil {
+			<-t.slots
+		}
+		t.stats.Add("in_flight", -1)
+		t.stats.Add("neo4j_ms", used.Milliseconds())
+		l.charge(t, used)
+		l.release(t)
+	}, nil
+}
+
+// tenant returns id's entry, held for a call until release.
+func (l *Limiter) tenant(id string) *tenant {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	t, ok := l.tenants[id]
+	if !ok {
+		if len(l.tenants) >= l.maxTenants {
+			l.evict()
+		}
+		if len(l.tenants) >= l.maxTenants {
+			id = overflow
+			t, ok = l.tenants[id]
+		}
+		if !ok {
+			t = l.add(id)
+		}
+	}
+	t.calls++
+	t.lastUsed = l.now()
+	return t
+}
+
+// release lets go of a call's hold on t.
+func (l *Limiter) release(t *tenant) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t.calls--
+	t.lastUsed = l.now()
+}
+
+// evict drops the tenants without calls that have been idle longer than
+// idleAfter, or their window if longer, so their budget had rolled over.
+// The caller must hold mu.
+func (l *Limiter) evict() {
+	idle := max(idleAfter, l.limits.Window)
+	for id, t := range l.tenants {
+		if t.calls == 0 && l.now().Sub(t.lastUsed) > idle {
+			delete(l.tenants, id)
+			l.stats.Delete(statsName(id))
+		}
+	}
+}
+
+// add tracks a new tenant. The caller must hold mu.
+func (l *Limiter) add(id string) *tenant {
+	t := &tenant{stats: new(expvar.Map).Init(), windowStart: l.now()}
+	if l.limits.MaxInFlight > 0 {
+		t.slots = make(chan struct{}, l.limits.MaxInFlight)
+	}
+	for _, key := range []string{"in_flight", "queued", "rejected", "neo4j_ms"} {
+		t.stats.Add(key, 0)
+	}
+	l.tenants[id] = t
+	l.stats.Set(statsName(id), t.stats)
+	return t
+}
+
+func statsName(id string) string {
+	if id == "" {
+		return "(none)"
+	}
+	return id
+}
+
+func (l *Limiter) overBudget(t *tenant) bool {
+	if l.limits.Budget <= 0 {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll(t)
+	return t.used >= l.limits.Budget
+}
+
+func (l *Limiter) charge(t *tenant, used time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll(t)
+	t.used += used
+}
+
+// roll starts a new window for t once its current one is over.
+func (l *Limiter) roll(t *tenant) {
+	if now := l.now(); l.limits.Window > 0 && now.Sub(t.windowStart) >= l.limits.Window {
+		t.windowStart, t.used = now, 0
+	}
+}
@@ -0,0 +1,92 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestEvict(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  time.Duration
+		idle    time.Duration
+		holdA   bool
+		want    []string
+		wantNew string
+	}{
+		{name: "idle tenants make room", idle: idleAfter + time.Second, want: []string{"c"}, wantNew: "c"},
+		{name: "recent tenants are kept", idle: idleAfter - time.Second, want: []string{overflow, "a", "b"}, wantNew: overflow},
+		{name: "a tenant with a call is kept", idle: idleAfter + time.Second, holdA: true, want: []string{"a", "c"}, wantNew: "c"},
+		{name: "kept for a longer window", window: 2 * idleAfter, idle: idleAfter + time.Second, want: []string{overflow, "a", "b"}, wantNew: overflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(1_700_000_000, 0)
+			l := New("quota_test_evict/"+tt.name, Limits{Window: tt.window})
+			l.maxTenants = 2
+			l.now = func() time.Time { return now }
+
+			doneA, err := l.Admit(context.Background(), "a")
+			if err != nil {
+				t.Fatalf("Admit(a) = %v", err)
+			}
+			if !tt.holdA {
+				doneA(0)
+			}
+			doneB, err := l.Admit(context.Background(), "b")
+			if err != nil {
+				t.Fatalf("Admit(b) = %v", err)
+			}
+			doneB(0)
+
+			now = now.Add(tt.idle)
+			doneC, err := l.Admit(context.Background(), "c")
+			if err != nil {
+				t.Fatalf("Admit(c) = %v", err)
+			}
+			if got := l.tenants[tt.wantNew]; got == nil || got.calls != 1 {
+				t.Errorf("c's call is not counted against %q", tt.wantNew)
+			}
+			doneC(0)
+
+			var got []string
+			for id := range l.tenants {
+				got = append(got, id)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("tracking %q, want %q", got, tt.want)
+			}
+			for _, id := range []string{"a", "b"} {
+				if tracked := slices.Contains(tt.want, id); (l.stats.Get(id) != nil) != tracked {
+					t.Errorf("metrics of %q published %v, want %v", id, !tracked, tracked)
+				}
+			}
+		})
+	}
+}
+
+func TestOverflowShared(t *testing.T) {
+	l := New("quota_test_overflow", Limits{MaxInFlight: 1})
+	l.maxTenants = 1
+
+	doneA, err := l.Admit(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("Admit(a) = %v", err)
+	}
+	defer doneA(0)
+
+	doneB, err := l.Admit(context.Background(), "b")
+	if err != nil {
+		t.Fatalf("Admit(b) = %v", err)
+	}
+	defer doneB(0)
+
+	// c has no room of its own either, and b's call holds the shared slot
+	if _, err := l.Admit(context.Background(), "c"); !errors.Is(err, ErrExceeded) {
+		t.Errorf("Admit(c) = %v, want %v", err, ErrExceeded)
+	}
+}
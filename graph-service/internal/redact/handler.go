@@ -0,0 +1,87 @@
+package redact
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// SensitiveKeys are the log attribute keys masked by default.
+var SensitiveKeys = []string{"email", "name", "customer_name", "address", "street", "city", "postal_code", "phone"}
+
+// Handler is a slog.Handler that redacts records before passing them on.
+// Attributes with a sensitive key are masked, proto messages are scrubbed
+// with Message, and email addresses are masked in the message and in
+// string and error values. Installed with slog.SetDefault it also covers
+// the standard log package.
+type Handler struct {
+	next slog.Handler
+	keys map[string]bool
+}
+
+// NewHandler redacts records for next, masking attributes named by keys,
+// or SensitiveKeys if none are given. Keys match case-insensitively.
+func NewHandler(next slog.Handler, keys ...string) *Handler {
+	if len(keys) == 0 {
+		keys = SensitiveKeys
+	}
+	h := &Handler{next: next, keys: make(map[string]bool, len(keys))}
+	for _, key := range keys {
+		h.keys[strings.ToLower(key)] = true
+	}
+	return h
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	clean := slog.NewRecord(record.Time, record.Level, String(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		clean.AddAttrs(h.attr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, clean)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clean := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		clean[i] = h.attr(attr)
+	}
+	return &Handler{next: h.next.WithAttrs(clean), keys: h.keys}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), keys: h.keys}
+}
+
+func (h *Handler) attr(attr slog.Attr) slog.Attr {
+	if h.keys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, String(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		clean := make([]any, len(group))
+		for i, member := range group {
+			clean[i] = h.attr(member)
+		}
+		return slog.Group(attr.Key, clean...)
+	case slog.KindAny:
+		switch v := value.Any().(type) {
+		case proto.Message:
+			return slog.Any(attr.Key, Message(v))
+		case error:
+			return slog.String(attr.Key, Error(v))
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
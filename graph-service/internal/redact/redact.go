@@ -0,0 +1,85 @@
+// Package redact keeps personal data out of logs, traces and error
+// messages. Proto fields marked [debug_redact = true] are scrubbed from
+// messages, log attributes with sensitive keys are masked, and email
+// addresses are masked wherever they appear in free text.
+package redact
+
+import (
+	"regexp"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Redacted replaces scrubbed values.
+const Redacted = "[REDACTED]"
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// String masks the email addresses in s.
+func String(s string) string {
+	return emailPattern.ReplaceAllString(s, Redacted)
+}
+
+// Error returns err's message with email addresses masked.
+func Error(err error) string {
+	if err == nil {
+		return ""
+	}
+	return String(err.Error())
+}
+
+// Message returns a copy of m with its debug_redact fields, at any depth,
+// set to Redacted if they are strings and cleared otherwise. m itself is
+// left untouched.
+func Message(m proto.Message) proto.Message {
+	if m == nil {
+		return nil
+	}
+	clone := proto.Clone(m)
+	scrub(clone.ProtoReflect())
+	return clone
+}
+
+// scrub redacts m in place. Fields are collected first since m can't be
+// changed while ranging over it.
+func scrub(m protoreflect.Message) {
+	var redacted []protoreflect.FieldDescriptor
+	var nested []protoreflect.Message
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case sensitive(fd):
+			redacted = append(redacted, fd)
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				nested = append(nested, list.Get(i).Message())
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				nested = append(nested, mv.Message())
+				return true
+			})
+		case !fd.IsList() && !fd.IsMap() && fd.Message() != nil:
+			nested = append(nested, v.Message())
+		}
+		return true
+	})
+
+	for _, fd := range redacted {
+		if fd.Kind() == protoreflect.StringKind && !fd.IsList() && !fd.IsMap() {
+			m.Set(fd, protoreflect.ValueOfString(Redacted))
+		} else {
+			m.Clear(fd)
+		}
+	}
+	for _, n := range nested {
+		scrub(n)
+	}
+}
+
+func sensitive(fd protoreflect.FieldDescriptor) bool {
+	opts, ok := fd.Options().(*descriptorpb.FieldOptions)
+	return ok && opts.GetDebugRedact()
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// activeLabel marks the products in the active season. Default searches
+// and listings match on it, so they scan only those; archived products
+// lack it.
+const activeLabel = "Active"
+
+// backfillBatchSize is how many products backfillActiveLabels labels per
+// transaction.
+const backfillBatchSize = 10000
+
+// SetProductsArchived archives the products, or returns them to the
+// active season, and returns how many it changed. Each raises
+// product.updated.
+func (r *ProductRepository) SetProductsArchived(ctx context.Context, ids []string, archived bool) (int, error) {
+	if len(ids) == 0 {
+		return 0, fieldErrorf("product_ids", "at least one product id is required")
+	}
+
+	change := "SET p:" + activeLabel
+	if archived {
+		change = "REMOVE p:" + activeLabel
+	}
+	now := time.Now().UnixMilli()
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			UNWIND $ids AS id
+			MATCH (p:Product {id: id})
+			WHERE (p:`+activeLabel+`) = $archived
+			`+change+`
+			SET p.updated_at = $now
+			WITH p
+			OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+			OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+			RETURN p, c, collect(s) as sizes
+		`, map[string]any{"ids": ids, "archived": archived, "now": now})
+		if err != nil {
+			return nil, err
+		}
+
+		var updated []*pb.Product
+		for res.Next(ctx) {
+			updated = append(updated, productFromRecord(res.Record()))
+		}
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+
+		for _, p := range updated {
+			update := productUpdate{Product: p, Changes: []*pb.FieldChange{{
+				Field:    "archived",
+				OldValue: jsonValue(!archived),
+				NewValue: jsonValue(archived),
+			}}}
+			if err := writeEvent(ctx, tx, events.New(events.ProductUpdated, p.Id, update)); err != nil {
+				return nil, err
+			}
+		}
+		return len(updated), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.(int), nil
+}
+
+// backfillActiveLabels puts every product in the active season, a batch
+// per transaction. It must finish before products are archived, since
+// archiving also removes the label.
+func (r *ProductRepository) backfillActiveLabels(ctx context.Context) error {
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	for {
+		result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			res, err := tx.Run(ctx, `
+				MATCH (p:Product)
+				WHERE NOT p:`+activeLabel+`
+				WITH p LIMIT $batch
+				SET p:`+activeLabel+`
+				RETURN count(p) AS labeled
+			`, map[string]any{"batch": backfillBatchSize})
+			if err != nil {
+				return nil, err
+			}
+			record, err := res.Single(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return getInt64(record.AsMap(), "labeled"), nil
+		})
+		if err != nil {
+			return err
+		}
+		if result.(int64) == 0 {
+			return nil
+		}
+	}
+}
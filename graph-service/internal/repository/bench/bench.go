@@ -0,0 +1,12 @@
+// Package bench benchmarks variants of the repository's Cypher against a
+// live Neo4j, so a change of query shape can be judged on numbers: how
+// product sizes are written, how listings page, and what the query cache
+// saves GetProduct. The benchmarks are ordinary Benchmark functions, so
+// go test -bench runs them and benchstat compares their output. They
+// are skipped unless BENCH_NEO4J is set, and then run against the Neo4j
+// configured through the same environment as the server. They create and
+// delete their own products; don't point them at production.
+//
+//	BENCH_NEO4J=1 go test -run '^$' -bench . -count 10 ./internal/repository/bench > new.txt
+//	BENCH_NEO4J=1 go test -run '^$' -bench 'ListProducts/' ./internal/repository/bench -args -products 10000
+package bench
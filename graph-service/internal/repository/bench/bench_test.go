@@ -0,0 +1,276 @@
+package bench
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/config"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/graphdb"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// options size the benchmarks' data.
+var options struct {
+	// products are seeded for the listing and GetProduct benchmarks.
+	products int
+	// sizes each created or seeded product has.
+	sizes int
+	// pageSize is the listing benchmarks' page.
+	pageSize int
+	// batch is how many products the batched create writes per statement.
+	batch int
+}
+
+func init() {
+	flag.IntVar(&options.products, "products", 1000, "products seeded for the listing and read benchmarks")
+	flag.IntVar(&options.sizes, "sizes", 5, "sizes per product")
+	flag.IntVar(&options.pageSize, "page-size", 50, "listing page size")
+	flag.IntVar(&options.batch, "batch", 50, "products per statement in batched writes")
+}
+
+var (
+	// shared is the suite every benchmark runs on, seeded by the first.
+	shared     *suite
+	sharedErr  error
+	sharedOnce sync.Once
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if options.products <= 0 || options.sizes <= 0 || options.pageSize <= 0 || options.batch <= 0 {
+		log.Fatal("-products, -sizes, -page-size and -batch must be positive")
+	}
+	if flag.Lookup("test.bench").Value.String() != "" {
+		// benchstat keeps "key: value" lines as configuration
+		fmt.Printf("products: %d\nsizes: %d\npage-size: %d\nbatch: %d\n",
+			options.products, options.sizes, options.pageSize, options.batch)
+	}
+
+	code := m.Run()
+	if shared != nil {
+		shared.close(context.Background())
+	}
+	os.Exit(code)
+}
+
+// benchSuite returns the shared suite, opening the database and seeding it
+// on first use. It skips b unless BENCH_NEO4J is set.
+func benchSuite(b *testing.B) *suite {
+	b.Helper()
+	if os.Getenv("BENCH_NEO4J") == "" {
+		b.Skip("set BENCH_NEO4J to benchmark the Neo4j the server's environment configures")
+	}
+	sharedOnce.Do(func() {
+		shared, sharedErr = open(context.Background(), graphdb.SettingsFrom(config.Load()))
+	})
+	if sharedErr != nil {
+		b.Fatal(sharedErr)
+	}
+	return shared
+}
+
+// run runs fn as b's sub-benchmark name, failing it on fn's error.
+func run(b *testing.B, name string, fn func(ctx context.Context, b *testing.B) error) {
+	b.Run(name, func(b *testing.B) {
+		b.ReportAllocs()
+		if err := fn(context.Background(), b); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+// BenchmarkCreateProduct times creating a product with its sizes.
+func BenchmarkCreateProduct(b *testing.B) {
+	s := benchSuite(b)
+	run(b, "per-size", s.createPerSize)
+	run(b, "unwind-sizes", s.createUnwindSizes)
+	run(b, "unwind-products", s.createUnwindProducts)
+	run(b, "repository", s.createRepository)
+}
+
+// BenchmarkListProducts times reading the full listing of the seeded
+// products, page by page.
+func BenchmarkListProducts(b *testing.B) {
+	s := benchSuite(b)
+	run(b, "skip-limit", s.listSkipLimit)
+	run(b, "keyset", s.listKeyset)
+}
+
+// BenchmarkGetProduct times reading one product.
+func BenchmarkGetProduct(b *testing.B) {
+	s := benchSuite(b)
+	run(b, "uncached", s.getUncached)
+	run(b, "cached", s.getCached)
+}
+
+type suite struct {
+	driver   neo4j.DriverWithContext
+	database string
+	prefix   string
+	plain    *repository.ProductRepository
+	cached   *repository.ProductRepository
+
+	// created counts the products the create benchmarks made, for unique
+	// ids across their runs.
+	created int
+}
+
+// open connects to the database settings point at, migrates it and seeds
+// a throwaway tenant's products, which close deletes.
+func open(ctx context.Context, settings graphdb.Settings) (*suite, error) {
+	driver, opts, err := graphdb.Open(settings)
+	if err != nil {
+		return nil, err
+	}
+	if err := driver.VerifyConnectivity(ctx); err != nil {
+		driver.Close(ctx)
+		return nil, fmt.Errorf("connecting to %s: %w", settings.URI, err)
+	}
+	s := &suite{
+		driver:   driver,
+		database: settings.Database,
+		prefix:   fmt.Sprintf("bench-%d", time.Now().UnixNano()),
+	}
+	s.plain = repository.NewProductRepository(driver, opts...)
+	s.cached = repository.NewProductRepository(driver, append(opts,
+		repository.WithQueryCache(repository.NewQueryCache(time.Hour, options.products)))...)
+
+	if _, err := s.plain.Migrate(ctx); err != nil {
+		driver.Close(ctx)
+		return nil, fmt.Errorf("migrating: %w", err)
+	}
+	if err := s.setup(ctx); err != nil {
+		s.close(ctx)
+		return nil, fmt.Errorf("seeding: %w", err)
+	}
+	return s, nil
+}
+
+// close deletes what the suite created and closes the driver.
+func (s *suite) close(ctx context.Context) {
+	s.cleanup(ctx)
+	s.driver.Close(ctx)
+}
+
+func (s *suite) session(ctx context.Context) neo4j.SessionWithContext {
+	return s.driver.NewSession(ctx, neo4j.SessionConfig{
+		AccessMode:   neo4j.AccessModeWrite,
+		DatabaseName: s.database,
+	})
+}
+
+// product makes the benchmark tenant's product with a name-derived id,
+// slug and SKUs.
+func (s *suite) product(name string) *pb.Product {
+	id := s.prefix + "-" + name
+	p := &pb.Product{
+		Id:       id,
+		Name:     "Bench runner " + name,
+		Brand:    "Bench",
+		TenantId: s.prefix,
+		Slug:     id,
+		Color:    "Black",
+		Price:    99.5,
+		Category: &pb.ProductCategory{
+			MainCategory: "Footwear",
+			Subcategory:  "Sneakers",
+			SpecificType: s.prefix,
+		},
+		Tags:        []string{"bench"},
+		Description: "A product made by the repository benchmarks.",
+	}
+	for i := range options.sizes {
+		p.Sizes = append(p.Sizes, &pb.ProductSize{
+			Size:    fmt.Sprintf("%d", 36+i),
+			Sku:     fmt.Sprintf("%s-%02d", id, i),
+			Stock:   10,
+			InStock: true,
+		})
+	}
+	return p
+}
+
+// next makes a product no earlier run created.
+func (s *suite) next() *pb.Product {
+	s.created++
+	return s.product(fmt.Sprintf("c%07d", s.created))
+}
+
+func seededID(prefix string, i int) string {
+	return fmt.Sprintf("%s-s%07d", prefix, i)
+}
+
+// setup creates the benchmark tenant's category, through the repository
+// so it has the usual shape, and seeds its products in batches.
+func (s *suite) setup(ctx context.Context) error {
+	if err := s.plain.CreateProduct(ctx, s.product("category")); err != nil {
+		return err
+	}
+
+	session := s.session(ctx)
+	defer session.Close(ctx)
+
+	for start := 0; start < options.products; start += options.batch {
+		var batch []*pb.Product
+		for i := start; i < min(start+options.batch, options.products); i++ {
+			batch = append(batch, s.product(fmt.Sprintf("s%07d", i)))
+		}
+		if _, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			return nil, s.writeProducts(ctx, tx, batch)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanup deletes the benchmark tenant's products, their sizes, the
+// events creating them raised and their category, in batches.
+func (s *suite) cleanup(ctx context.Context) {
+	session := s.session(ctx)
+	defer session.Close(ctx)
+
+	for _, cypher := range []string{`
+		MATCH (p:Product {tenant_id: $prefix})
+		WITH p LIMIT 1000
+		OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+		DETACH DELETE p, s
+		RETURN count(*) AS deleted
+	`, `
+		MATCH (e:Event)
+		WHERE e.product_id STARTS WITH $prefix
+		WITH e LIMIT 1000
+		DELETE e
+		RETURN count(*) AS deleted
+	`, `
+		MATCH (c:Category {name: $prefix})
+		DETACH DELETE c
+		RETURN count(*) AS deleted
+	`} {
+		for {
+			deleted, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+				res, err := tx.Run(ctx, cypher, map[string]any{"prefix": s.prefix})
+				if err != nil {
+					return nil, err
+				}
+				record, err := res.Single(ctx)
+				if err != nil {
+					return nil, err
+				}
+				n, _ := record.Values[0].(int64)
+				return n, nil
+			})
+			if err != nil || deleted.(int64) == 0 {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// The create variants write the same product, category link and sizes;
+// only the repository one also merges the category path, checks for
+// conflicts and raises an event.
+
+const createProduct = `
+	MATCH (c:Category:SpecificType {name: $category})
+	CREATE (p:Product:Active {
+		id: $id, name: $name, brand: $brand, color: $color, price: $price,
+		description: $description, tags: $tags, tenant_id: $tenant_id,
+		slug: $slug, created_at: timestamp(), updated_at: timestamp()
+	})
+	CREATE (p)-[:BELONGS_TO]->(c)
+`
+
+func productParams(p *pb.Product) map[string]any {
+	sizes := make([]map[string]any, 0, len(p.Sizes))
+	for _, size := range p.Sizes {
+		sizes = append(sizes, map[string]any{
+			"sku":      size.Sku,
+			"size":     size.Size,
+			"stock":    size.Stock,
+			"in_stock": size.InStock,
+		})
+	}
+	return map[string]any{
+		"id":          p.Id,
+		"name":        p.Name,
+		"brand":       p.Brand,
+		"color":       p.Color,
+		"price":       p.Price,
+		"description": p.Description,
+		"tags":        p.Tags,
+		"tenant_id":   p.TenantId,
+		"slug":        p.Slug,
+		"category":    p.GetCategory().GetSpecificType(),
+		"sizes":       sizes,
+	}
+}
+
+// createPerSize writes a product, then each of its sizes with a statement
+// of its own, in one transaction.
+func (s *suite) createPerSize(ctx context.Context, b *testing.B) error {
+	session := s.session(ctx)
+	defer session.Close(ctx)
+
+	for range b.N {
+		p := s.next()
+		_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			params := productParams(p)
+			if _, err := tx.Run(ctx, createProduct, params); err != nil {
+				return nil, err
+			}
+			for _, size := range params["sizes"].([]map[string]any) {
+				_, err := tx.Run(ctx, `
+					MATCH (p:Product {id: $id})
+					CREATE (p)-[:HAS_SIZE]->(:Size {
+						sku: $size.sku, size: $size.size, stock: $size.stock,
+						in_stock: $size.in_stock, created_at: timestamp(), updated_at: timestamp()
+					})
+				`, map[string]any{"id": p.Id, "size": size})
+				if err != nil {
+					return nil, err
+				}
+			}
+			return nil, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createUnwindSizes writes a product and its sizes in one statement, as
+// the repository does.
+func (s *suite) createUnwindSizes(ctx context.Context, b *testing.B) error {
+	session := s.session(ctx)
+	defer session.Close(ctx)
+
+	for range b.N {
+		p := s.next()
+		_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			_, err := tx.Run(ctx, createProduct+`
+				WITH p
+				UNWIND $sizes AS size
+				CREATE (p)-[:HAS_SIZE]->(:Size {
+					sku: size.sku, size: size.size, stock: size.stock,
+					in_stock: size.in_stock, created_at: timestamp(), updated_at: timestamp()
+				})
+			`, productParams(p))
+			return nil, err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createUnwindProducts writes Batch products and their sizes per
+// statement.
+func (s *suite) createUnwindProducts(ctx context.Context, b *testing.B) error {
+	session := s.session(ctx)
+	defer session.Close(ctx)
+
+	for done := 0; done < b.N; done += options.batch {
+		var batch []*pb.Product
+		for range min(options.batch, b.N-done) {
+			batch = append(batch, s.next())
+		}
+		_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			return nil, s.writeProducts(ctx, tx, batch)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeProducts writes products and their sizes in one statement.
+func (s *suite) writeProducts(ctx context.Context, tx neo4j.ManagedTransaction, products []*pb.Product) error {
+	params := make([]map[string]any, 0, len(products))
+	for _, p := range products {
+		params = append(params, productParams(p))
+	}
+	_, err := tx.Run(ctx, `
+		MATCH (c:Category:SpecificType {name: $category})
+		UNWIND $products AS product
+		CREATE (p:Product:Active {
+			id: product.id, name: product.name, brand: product.brand,
+			color: product.color, price: product.price,
+			description: product.description, tags: product.tags,
+			tenant_id: product.tenant_id, slug: product.slug,
+			created_at: timestamp(), updated_at: timestamp()
+		})
+		CREATE (p)-[:BELONGS_TO]->(c)
+		WITH p, product
+		UNWIND product.sizes AS size
+		CREATE (p)-[:HAS_SIZE]->(:Size {
+			sku: size.sku, size: size.size, stock: size.stock,
+			in_stock: size.in_stock, created_at: timestamp(), updated_at: timestamp()
+		})
+	`, map[string]any{"category": s.prefix, "products": params})
+	return err
+}
+
+// createRepository creates products through the repository.
+func (s *suite) createRepository(ctx context.Context, b *testing.B) error {
+	for range b.N {
+		if err := s.plain.CreateProduct(ctx, s.next()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The listing variants page through the seeded products in id order, as
+// ListProducts does, with its page shape.
+const listPage = `
+	WITH p
+	ORDER BY p.id
+	%s
+	OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+	OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+	RETURN p.id AS id, p, c, collect(s) AS sizes
+	ORDER BY p.id
+`
+
+// listSkipLimit pages with SKIP and LIMIT, rereading every earlier page
+// to skip it.
+func (s *suite) listSkipLimit(ctx context.Context, b *testing.B) error {
+	return s.walk(ctx, b, `
+		MATCH (p:Product:Active)
+		WHERE p.tenant_id = $tenant_id AND p.id STARTS WITH $seeded
+	`+page("SKIP $skip LIMIT $limit"))
+}
+
+// listKeyset pages after the last id seen, as ListProducts does.
+func (s *suite) listKeyset(ctx context.Context, b *testing.B) error {
+	return s.walk(ctx, b, `
+		MATCH (p:Product:Active)
+		WHERE p.tenant_id = $tenant_id AND p.id STARTS WITH $seeded AND p.id > $after
+	`+page("LIMIT $limit"))
+}
+
+func page(limit string) string {
+	return fmt.Sprintf(listPage, limit)
+}
+
+// walk reads every page of the seeded products with cypher per operation.
+func (s *suite) walk(ctx context.Context, b *testing.B, cypher string) error {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{
+		AccessMode:   neo4j.AccessModeRead,
+		DatabaseName: s.database,
+	})
+	defer session.Close(ctx)
+
+	for range b.N {
+		read, after := 0, ""
+		for {
+			result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+				res, err := tx.Run(ctx, cypher, map[string]any{
+					"tenant_id": s.prefix,
+					"seeded":    s.prefix + "-s",
+					"skip":      read,
+					"after":     after,
+					"limit":     options.pageSize,
+				})
+				if err != nil {
+					return nil, err
+				}
+				var ids []string
+				for res.Next(ctx) {
+					id, _ := res.Record().Values[0].(string)
+					ids = append(ids, id)
+				}
+				return ids, res.Err()
+			})
+			if err != nil {
+				return err
+			}
+			ids := result.([]string)
+			if len(ids) == 0 {
+				break
+			}
+			read, after = read+len(ids), ids[len(ids)-1]
+		}
+	}
+	return nil
+}
+
+func (s *suite) getUncached(ctx context.Context, b *testing.B) error {
+	return s.get(ctx, b, s.plain)
+}
+
+// getCached reads through a query cache big enough for every seeded
+// product, so after the first pass every read is a hit.
+func (s *suite) getCached(ctx context.Context, b *testing.B) error {
+	return s.get(ctx, b, s.cached)
+}
+
+// get reads the seeded products in turn.
+func (s *suite) get(ctx context.Context, b *testing.B, repo *repository.ProductRepository) error {
+	for i := range b.N {
+		if _, err := repo.GetProduct(ctx, seededID(s.prefix, i%options.products)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
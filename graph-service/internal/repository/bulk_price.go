@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const (
+	defaultPriceBatchSize = 500
+	maxPriceBatchSize     = 5000
+	priceChangePreview    = 20
+)
+
+// ProductFilter selects products by catalog fields. Empty fields match
+// everything; lists match any of their values, case-insensitively.
+type ProductFilter struct {
+	Catalog  *pb.CatalogFilter
+	Brands   []string
+	Colors   []string
+	Sizes    []string
+	MinPrice float64
+	MaxPrice float64
+	// InStock matches products with an in-stock size, of one of Sizes
+	// when those are set.
+	InStock bool
+}
+
+// PriceAdjustment changes prices by Percent, or else by Amount.
+type PriceAdjustment struct {
+	Percent float64
+	Amount  float64
+}
+
+// PriceChange is one product's price before and after an update.
+type PriceChange struct {
+	ProductID string
+	Name      string
+	OldPrice  float64
+	NewPrice  float64
+}
+
+// BulkPriceUpdate reports a bulk price update: how many products it
+// changed, in how many transactions, and the first changes by product id.
+type BulkPriceUpdate struct {
+	Affected int
+	Batches  int
+	Preview  []PriceChange
+}
+
+// BulkUpdatePrices adjusts the price of every priced product f matches,
+// up to batchSize products per transaction in product id order, raising
+// product.updated for each. Matches are counted first: if any price
+// would fall to zero or below nothing is changed. On a dry run only the
+// count and preview are returned.
+func (r *ProductRepository) BulkUpdatePrices(ctx context.Context, f ProductFilter, adj PriceAdjustment, batchSize int, dryRun bool) (*BulkPriceUpdate, error) {
+	if adj.Percent == 0 && adj.Amount == 0 {
+		return nil, fieldErrorf("adjustment", "a percent or amount is required")
+	}
+	if adj.Percent <= -100 {
+		return nil, fieldErrorf("adjustment.percent", "percent must be above -100")
+	}
+	if batchSize <= 0 {
+		batchSize = defaultPriceBatchSize
+	}
+	if batchSize > maxPriceBatchSize {
+		return nil, fieldErrorf("batch_size", "batch size must be at most %d", maxPriceBatchSize)
+	}
+
+	params := map[string]any{
+		"factor":  1 + adj.Percent/100,
+		"amount":  adj.Amount,
+		"preview": priceChangePreview,
+	}
+	if adj.Percent != 0 {
+		params["amount"] = 0.0
+	}
+	// Unpriced products stay unpriced
+	where := "p.price > 0 AND " + productFilterClause(r.dialect, f, params)
+	newPrice := "round((p.price * $factor + $amount) * 100) / 100.0"
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	// Count and preview the whole update before changing anything
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (p:Product)
+			WHERE `+where+`
+			WITH p, `+newPrice+` AS new_price
+			ORDER BY p.id
+			WITH collect({id: p.id, name: p.name, old_price: p.price, new_price: new_price}) AS changes
+			RETURN size(changes) AS affected,
+				size([c IN changes WHERE c.new_price <= 0]) AS nonpositive,
+				changes[..$preview] AS preview
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		values := record.AsMap()
+		if n := getInt64(values, "nonpositive"); n > 0 {
+			return nil, fieldErrorf("adjustment", "the adjustment would bring %d prices to zero or below", n)
+		}
+		update := &BulkPriceUpdate{Affected: int(getInt64(values, "affected"))}
+		preview, _ := values["preview"].([]any)
+		for _, item := range preview {
+			change, _ := item.(map[string]any)
+			oldPrice, _ := change["old_price"].(float64)
+			newPrice, _ := change["new_price"].(float64)
+			update.Preview = append(update.Preview, PriceChange{
+				ProductID: getString(change, "id"),
+				Name:      getString(change, "name"),
+				OldPrice:  oldPrice,
+				NewPrice:  newPrice,
+			})
+		}
+		return update, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	update := result.(*BulkPriceUpdate)
+	if dryRun || update.Affected == 0 {
+		return update, nil
+	}
+
+	// Walk the matches by id, so products a batch changed aren't matched
+	// again by a price range
+	params["batch"] = batchSize
+	params["after_id"] = ""
+	params["now"] = time.Now().UnixMilli()
+	update.Affected = 0
+	for {
+		result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			res, err := tx.Run(ctx, `
+				MATCH (p:Product)
+				WHERE p.id > $after_id AND `+where+`
+				WITH p
+				ORDER BY p.id
+				LIMIT $batch
+				WITH p, p.price AS old_price, `+newPrice+` AS new_price
+				WHERE new_price > 0
+				SET p.price = new_price, p.updated_at = $now
+				WITH p, old_price
+				OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+				OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+				RETURN p, c, collect(s) as sizes, old_price
+				ORDER BY p.id
+			`, params)
+			if err != nil {
+				return nil, err
+			}
+
+			var changed []*pb.Product
+			for res.Next(ctx) {
+				p := productFromRecord(res.Record())
+				old, _ := res.Record().Get("old_price")
+				oldPrice, _ := old.(float64)
+				change := productUpdate{Product: p, Changes: []*pb.FieldChange{{
+					Field:    "price",
+					OldValue: jsonValue(oldPrice),
+					NewValue: jsonValue(p.Price),
+				}}}
+				if err := writeEvent(ctx, tx, events.New(events.ProductUpdated, p.Id, change)); err != nil {
+					return nil, err
+				}
+				changed = append(changed, p)
+			}
+			return changed, res.Err()
+		})
+		if err != nil {
+			return update, err
+		}
+
+		changed := result.([]*pb.Product)
+		if len(changed) == 0 {
+			return update, nil
+		}
+		update.Affected += len(changed)
+		update.Batches++
+		params["after_id"] = changed[len(changed)-1].Id
+	}
+}
+
+// productFilterClause returns a WHERE condition on p matching f, adding
+// its parameters to params.
+func productFilterClause(d Dialect, f ProductFilter, params map[string]any) string {
+	conds := []string{catalogFilterClause(d, f.Catalog, params)}
+
+	if len(f.Brands) > 0 {
+		conds = append(conds, "toLower(p.brand) IN $filter_brands")
+		params["filter_brands"] = lowerAll(f.Brands)
+	}
+	if len(f.Colors) > 0 {
+		conds = append(conds, "toLower(p.color) IN $filter_colors")
+		params["filter_colors"] = lowerAll(f.Colors)
+	}
+	if f.MinPrice > 0 {
+		conds = append(conds, "p.price >= $filter_min_price")
+		params["filter_min_price"] = f.MinPrice
+	}
+	if f.MaxPrice > 0 {
+		conds = append(conds, "p.price <= $filter_max_price")
+		params["filter_max_price"] = f.MaxPrice
+	}
+	if len(f.Sizes) > 0 || f.InStock {
+		conds = append(conds, `any(s IN [(p)-[:HAS_SIZE]->(size:Size) | size]
+			WHERE ($filter_sizes = [] OR toLower(s.size) IN $filter_sizes) AND (NOT $filter_in_stock OR s.in_stock))`)
+		params["filter_sizes"] = lowerAll(f.Sizes)
+		params["filter_in_stock"] = f.InStock
+	}
+	return strings.Join(conds, " AND ")
+}
+
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, v := range values {
+		lowered[i] = strings.ToLower(v)
+	}
+	return lowered
+}
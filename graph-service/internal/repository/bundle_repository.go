@@ -0,0 +1,345 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+var errBundleNotFound = notFoundf("bundle not found")
+
+func validateBundle(b *pb.Bundle) error {
+	if b.GetName() == "" {
+		return fieldErrorf("bundle.name", "bundle name is required")
+	}
+	if b.GetPrice() <= 0 {
+		return fieldErrorf("bundle.price", "bundle price must be positive")
+	}
+	if len(b.GetComponents()) == 0 {
+		return fieldErrorf("bundle.components", "bundle needs at least one component")
+	}
+	seen := make(map[string]bool, len(b.Components))
+	for _, c := range b.Components {
+		if c.GetSku() == "" || c.GetQuantity() <= 0 {
+			return fieldErrorf("bundle.components", "bundle components need a sku and a positive quantity")
+		}
+		if seen[c.Sku] {
+			return fieldErrorf("bundle.components", "sku %q listed more than once", c.Sku)
+		}
+		seen[c.Sku] = true
+	}
+	return nil
+}
+
+func componentParams(components []*pb.BundleComponent) []map[string]any {
+	params := make([]map[string]any, 0, len(components))
+	for _, c := range components {
+		params = append(params, map[string]any{
+			"sku":      c.Sku,
+			"quantity": c.Quantity,
+		})
+	}
+	return params
+}
+
+func (r *ProductRepository) CreateBundle(ctx context.Context, b *pb.Bundle) (string, error) {
+	if err := validateBundle(b); err != nil {
+		return "", err
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		if err := checkSkusExist(ctx, tx, componentSkus(b.Components)); err != nil {
+			return nil, err
+		}
+
+		res, err := tx.Run(ctx, `
+			CREATE (b:Bundle {
+				id: $id,
+				tenant_id: $tenant_id,
+				name: $name,
+				description: $description,
+				price: $price,
+				created_at: $now,
+				updated_at: $now
+			})
+			WITH b
+			UNWIND $components AS component
+			MATCH (s:Size {sku: component.sku})
+			CREATE (b)-[:CONTAINS {quantity: component.quantity}]->(s)
+			RETURN DISTINCT b.id AS id
+		`, map[string]any{
+			"id":          idOrNew(b.Id),
+			"tenant_id":   b.TenantId,
+			"name":        b.Name,
+			"description": b.Description,
+			"price":       b.Price,
+			"components":  componentParams(b.Components),
+			"now":         time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		id, _ := record.Get("id")
+		return id, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	id, _ := result.(string)
+	return id, nil
+}
+
+// GetBundle returns the bundle with availability derived from its scarcest
+// component: floor(stock / quantity), minimised over components.
+func (r *ProductRepository) GetBundle(ctx context.Context, id string) (*pb.Bundle, error) {
+	if id == "" {
+		return nil, fieldErrorf("id", "bundle id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (b:Bundle {id: $id})
+			OPTIONAL MATCH (b)-[c:CONTAINS]->(s:Size)
+			RETURN b,
+				collect({sku: s.sku, quantity: c.quantity}) AS components,
+				min(CASE WHEN s.stock > 0 THEN s.stock / c.quantity ELSE 0 END) AS available
+		`, map[string]any{"id": id})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			return nil, errBundleNotFound
+		}
+
+		record := res.Record()
+		bNode, _ := record.Values[0].(neo4j.Node)
+		components, _ := record.Values[1].([]any)
+		available, _ := record.Values[2].(int64)
+
+		props := bNode.Props
+		bundle := &pb.Bundle{
+			Id:                getString(props, "id"),
+			TenantId:          getString(props, "tenant_id"),
+			Name:              getString(props, "name"),
+			Description:       getString(props, "description"),
+			AvailableQuantity: int32(available),
+			Available:         available > 0,
+			CreatedAt:         getInt64(props, "created_at"),
+			UpdatedAt:         getInt64(props, "updated_at"),
+		}
+		if price, ok := props["price"].(float64); ok {
+			bundle.Price = price
+		}
+		for _, item := range components {
+			m, ok := item.(map[string]any)
+			if !ok || m["sku"] == nil {
+				continue
+			}
+			bundle.Components = append(bundle.Components, &pb.BundleComponent{
+				Sku:      getString(m, "sku"),
+				Quantity: int32(getInt64(m, "quantity")),
+			})
+		}
+
+		return bundle, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.Bundle), nil
+}
+
+// UpdateBundle rewrites the bundle's fields and replaces its components.
+func (r *ProductRepository) UpdateBundle(ctx context.Context, b *pb.Bundle) error {
+	if b.GetId() == "" {
+		return fieldErrorf("bundle.id", "bundle id is required")
+	}
+	if err := validateBundle(b); err != nil {
+		return err
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		if err := checkSkusExist(ctx, tx, componentSkus(b.Components)); err != nil {
+			return nil, err
+		}
+
+		res, err := tx.Run(ctx, `
+			MATCH (b:Bundle {id: $id})
+			SET b.name = $name,
+				b.description = $description,
+				b.price = $price,
+				b.updated_at = $now
+			WITH b
+			OPTIONAL MATCH (b)-[old:CONTAINS]->()
+			DELETE old
+			WITH DISTINCT b
+			UNWIND $components AS component
+			MATCH (s:Size {sku: component.sku})
+			CREATE (b)-[:CONTAINS {quantity: component.quantity}]->(s)
+			RETURN DISTINCT b.id
+		`, map[string]any{
+			"id":          b.Id,
+			"name":        b.Name,
+			"description": b.Description,
+			"price":       b.Price,
+			"components":  componentParams(b.Components),
+			"now":         time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			return nil, errBundleNotFound
+		}
+		return nil, nil
+	})
+
+	return err
+}
+
+func (r *ProductRepository) DeleteBundle(ctx context.Context, id string) error {
+	if id == "" {
+		return fieldErrorf("id", "bundle id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			MATCH (b:Bundle {id: $id})
+			DETACH DELETE b
+		`, map[string]any{"id": id})
+		return nil, err
+	})
+
+	return err
+}
+
+// OrderBundle decrements every component's stock for quantity bundles. The
+// decrement happens first so the write locks serialise concurrent orders;
+// if any component goes negative the transaction is rolled back.
+func (r *ProductRepository) OrderBundle(ctx context.Context, id string, quantity int32) error {
+	if id == "" {
+		return fieldErrorf("bundle_id", "bundle id is required")
+	}
+	if quantity <= 0 {
+		return fieldErrorf("quantity", "quantity must be positive")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (b:Bundle {id: $id})-[c:CONTAINS]->(s:Size)
+			SET s.stock = s.stock - c.quantity * $quantity
+			SET s.in_stock = s.stock > 0,
+				s.updated_at = $now
+			WITH s
+			OPTIONAL MATCH (p:Product)-[:HAS_SIZE]->(s)
+			SET p.updated_at = $now
+			RETURN s.sku AS sku, s.stock AS stock, p.id AS product_id
+		`, map[string]any{
+			"id":       id,
+			"quantity": quantity,
+			"now":      time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		records, err := res.Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return nil, errBundleNotFound
+		}
+
+		var short []string
+		for _, record := range records {
+			sku, _ := record.Values[0].(string)
+			if stock, _ := record.Values[1].(int64); stock < 0 {
+				short = append(short, sku)
+			}
+		}
+		if len(short) > 0 {
+			return nil, fmt.Errorf("%w for %s", ErrInsufficientStock, strings.Join(short, ", "))
+		}
+
+		for _, record := range records {
+			sku, _ := record.Values[0].(string)
+			stock, _ := record.Values[1].(int64)
+			productID, _ := record.Values[2].(string)
+			ev := events.New(events.StockUpdated, productID, map[string]int64{"stock": stock})
+			ev.SKU = sku
+			if err := writeEvent(ctx, tx, ev); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+
+	return err
+}
+
+func componentSkus(components []*pb.BundleComponent) []string {
+	skus := make([]string, 0, len(components))
+	for _, c := range components {
+		skus = append(skus, c.Sku)
+	}
+	return skus
+}
+
+// checkSkusExist returns an error naming any SKU with no Size node.
+func checkSkusExist(ctx context.Context, tx neo4j.ManagedTransaction, skus []string) error {
+	res, err := tx.Run(ctx, `
+		UNWIND $skus AS sku
+		OPTIONAL MATCH (s:Size {sku: sku})
+		WITH sku, s
+		WHERE s IS NULL
+		RETURN collect(sku) AS missing
+	`, map[string]any{"skus": skus})
+	if err != nil {
+		return err
+	}
+
+	record, err := res.Single(ctx)
+	if err != nil {
+		return err
+	}
+
+	missing, _ := record.Values[0].([]any)
+	if len(missing) == 0 {
+		return nil
+	}
+	unknown := make([]string, 0, len(missing))
+	for _, m := range missing {
+		if s, ok := m.(string); ok {
+			unknown = append(unknown, s)
+		}
+	}
+	return notFoundf("unknown skus: %s", strings.Join(unknown, ", "))
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const (
+	defaultOverviewTTL    = 5 * time.Minute
+	defaultOverviewMonths = 12
+	maxOverviewMonths     = 36
+	monthLayout           = "2006-01"
+)
+
+// CatalogOverview sums up a tenant's catalog, or every tenant's, for the
+// admin dashboard.
+type CatalogOverview struct {
+	ActiveProducts   int
+	ArchivedProducts int
+	SKUs             int
+	// OutOfStockPercent is the share of active products' SKUs out of
+	// stock, from 0 to 100.
+	OutOfStockPercent float64
+	// Categories are active priced products by main category, in
+	// category order.
+	Categories []CategoryPrices
+	// Growth has a month per month asked for, oldest first, the current
+	// month included.
+	Growth     []CatalogMonth
+	ComputedAt int64
+}
+
+// CategoryPrices is the average price of a category's products.
+type CategoryPrices struct {
+	Category     string
+	Products     int
+	AveragePrice float64
+}
+
+// CatalogMonth is how many products a month ("2006-01") added, and how
+// many there were at its end.
+type CatalogMonth struct {
+	Month   string
+	Created int
+	Total   int
+}
+
+// overviewCache keeps catalog overviews for a TTL. Unlike the query cache
+// it isn't cleared by writes: overviews cost a scan of the catalog, and a
+// dashboard can show them a few minutes old.
+type overviewCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]overviewEntry
+}
+
+type overviewEntry struct {
+	overview *CatalogOverview
+	expires  time.Time
+}
+
+// WithOverviewTTL caches catalog overviews for ttl; zero computes each one
+// afresh. The default is five minutes.
+func WithOverviewTTL(ttl time.Duration) Option {
+	return func(r *ProductRepository) {
+		r.overviews = nil
+		if ttl > 0 {
+			r.overviews = &overviewCache{ttl: ttl, entries: map[string]overviewEntry{}}
+		}
+	}
+}
+
+func (c *overviewCache) get(key string) (*CatalogOverview, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.overview, true
+}
+
+func (c *overviewCache) put(key string, overview *CatalogOverview) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = overviewEntry{overview: overview, expires: now.Add(c.ttl)}
+}
+
+func (c *overviewCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+// CatalogOverview returns the overview of tenantID's catalog, or of every
+// tenant's when it's empty, with growth over the last months months. It
+// may be cached; callers must not change it.
+func (r *ProductRepository) CatalogOverview(ctx context.Context, tenantID string, months int) (*CatalogOverview, error) {
+	if months <= 0 {
+		months = defaultOverviewMonths
+	}
+	if months > maxOverviewMonths {
+		return nil, fieldErrorf("months", "at most %d months of growth can be shown", maxOverviewMonths)
+	}
+
+	key := tenantID + "\x00" + strconv.Itoa(months)
+	if r.overviews != nil {
+		if overview, ok := r.overviews.get(key); ok {
+			return overview, nil
+		}
+	}
+
+	now := time.Now().UTC()
+	first := time.Date(now.Year(), now.Month()-time.Month(months-1), 1, 0, 0, 0, 0, time.UTC)
+	params := map[string]any{
+		"tenant_id": tenantID,
+		"from":      first.UnixMilli(),
+		"day":       dayMillis,
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		overview := &CatalogOverview{ComputedAt: now.UnixMilli()}
+
+		res, err := tx.Run(ctx, `
+			MATCH (p:Product)
+			WHERE $tenant_id = '' OR p.tenant_id = $tenant_id
+			OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+			WITH p, count(s) AS skus, count(CASE WHEN s IS NOT NULL AND NOT coalesce(s.in_stock, false) THEN 1 END) AS out_of_stock
+			RETURN (p:`+activeLabel+`) AS active, count(p) AS products, sum(skus) AS skus, sum(out_of_stock) AS out_of_stock
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+		for res.Next(ctx) {
+			row := res.Record().AsMap()
+			products, skus := int(getInt64(row, "products")), int(getInt64(row, "skus"))
+			overview.SKUs += skus
+			if active, _ := row["active"].(bool); !active {
+				overview.ArchivedProducts = products
+				continue
+			}
+			overview.ActiveProducts = products
+			if skus > 0 {
+				overview.OutOfStockPercent = float64(getInt64(row, "out_of_stock")) * 100 / float64(skus)
+			}
+		}
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+
+		res, err = tx.Run(ctx, `
+			MATCH (p:`+activeLabel+`:Product)-[:BELONGS_TO]->(c:Category)
+			WHERE ($tenant_id = '' OR p.tenant_id = $tenant_id) AND p.price > 0
+			RETURN c.main_category AS category, count(p) AS products, avg(p.price) AS average_price
+			ORDER BY category
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+		for res.Next(ctx) {
+			row := res.Record().AsMap()
+			average, _ := row["average_price"].(float64)
+			overview.Categories = append(overview.Categories, CategoryPrices{
+				Category:     getString(row, "category"),
+				Products:     int(getInt64(row, "products")),
+				AveragePrice: average,
+			})
+		}
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+
+		// Products are counted by the day they were created, and days
+		// before the first month all as day 0
+		res, err = tx.Run(ctx, `
+			MATCH (p:Product)
+			WHERE $tenant_id = '' OR p.tenant_id = $tenant_id
+			WITH coalesce(p.created_at, 0) AS created_at
+			WITH CASE WHEN created_at < $from THEN 0 ELSE created_at - created_at % $day END AS day
+			RETURN day, count(*) AS created
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+		overview.Growth = make([]CatalogMonth, months)
+		for i := range overview.Growth {
+			overview.Growth[i].Month = first.AddDate(0, i, 0).Format(monthLayout)
+		}
+		before := 0
+		for res.Next(ctx) {
+			row := res.Record().AsMap()
+			day, created := getInt64(row, "day"), int(getInt64(row, "created"))
+			if day == 0 {
+				before += created
+				continue
+			}
+			at := time.UnixMilli(day).UTC()
+			i := (at.Year()-first.Year())*12 + int(at.Month()-first.Month())
+			if i >= 0 && i < months {
+				overview.Growth[i].Created += created
+			}
+		}
+		for i := range overview.Growth {
+			before += overview.Growth[i].Created
+			overview.Growth[i].Total = before
+		}
+		return overview, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	overview := result.(*CatalogOverview)
+	if r.overviews != nil {
+		r.overviews.put(key, overview)
+	}
+	return overview, nil
+}
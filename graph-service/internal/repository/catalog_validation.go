@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// defaultValidationSamples is how many ids ValidateCatalog lists per check
+// when the caller doesn't say.
+const defaultValidationSamples = 10
+
+// catalogCheck is a ValidateCatalog check. Its query binds the offending
+// ids, in order, to id and returns their count and the first $samples.
+type catalogCheck struct {
+	name  string
+	query func(d Dialect) string
+}
+
+const tenantProducts = `
+	MATCH (p:Product)
+	WHERE ($tenant_id = '' OR p.tenant_id = $tenant_id)`
+
+const checkSamples = `
+	RETURN count(id) AS count, collect(id)[..$samples] AS samples`
+
+var catalogChecks = []catalogCheck{
+	{"no_images", func(Dialect) string {
+		return tenantProducts + ` AND size(coalesce(p.images, [])) = 0
+			WITH p.id AS id ORDER BY id` + checkSamples
+	}},
+	{"zero_price", func(Dialect) string {
+		return tenantProducts + ` AND coalesce(p.price, 0) <= 0
+			WITH p.id AS id ORDER BY id` + checkSamples
+	}},
+	{"missing_category", func(d Dialect) string {
+		return tenantProducts + ` AND NOT ` + d.exists("(p)-[:BELONGS_TO]->(:Category)") + `
+			WITH p.id AS id ORDER BY id` + checkSamples
+	}},
+	{"duplicate_name", func(Dialect) string {
+		return tenantProducts + `
+			WITH p.tenant_id AS tenant, toLower(coalesce(p.brand, '')) AS brand, toLower(p.name) AS name, collect(p.id) AS ids
+			WHERE size(ids) > 1
+			UNWIND ids AS id
+			WITH id ORDER BY id` + checkSamples
+	}},
+	{"negative_stock", func(Dialect) string {
+		return tenantProducts + `
+			MATCH (p)-[:HAS_SIZE]->(s:Size)
+			WHERE s.stock < 0
+			WITH s.sku AS id ORDER BY id` + checkSamples
+	}},
+}
+
+// ValidateCatalog runs the catalog checks over the tenant's products, or
+// every product without a tenant, archived ones included. Every check is
+// reported, in a fixed order, with up to sampleSize of the ids it flagged.
+func (r *ProductRepository) ValidateCatalog(ctx context.Context, tenantID string, sampleSize int) (*pb.ValidateCatalogResponse, error) {
+	if sampleSize <= 0 {
+		sampleSize = defaultValidationSamples
+	}
+	params := map[string]any{"tenant_id": tenantID, "samples": sampleSize}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, tenantProducts+`
+			RETURN count(p) AS products
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		report := &pb.ValidateCatalogResponse{ProductsChecked: getInt64(record.AsMap(), "products")}
+
+		for _, check := range catalogChecks {
+			res, err := tx.Run(ctx, check.query(r.dialect), params)
+			if err != nil {
+				return nil, err
+			}
+			record, err := res.Single(ctx)
+			if err != nil {
+				return nil, err
+			}
+			row := record.AsMap()
+			report.Issues = append(report.Issues, &pb.CatalogIssue{
+				Check:     check.name,
+				Count:     getInt64(row, "count"),
+				SampleIds: getStrings(row, "samples"),
+			})
+		}
+		return report, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*pb.ValidateCatalogResponse), nil
+}
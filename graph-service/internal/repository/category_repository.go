@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+/*
+NEED TO RUN ONCE IN NEO4J
+
+CREATE INDEX main_category_name IF NOT EXISTS
+FOR (m:MainCategory) ON (m.name);
+
+CREATE INDEX subcategory_name IF NOT EXISTS
+FOR (sc:Subcategory) ON (sc.name);
+*/
+
+// Categories form a tree:
+//
+//	(:MainCategory)-[:HAS_SUBCATEGORY]->(:Subcategory)-[:HAS_TYPE]->(:Category:SpecificType)
+//
+// Each level is MERGEd under its parent, so a name is shared by every
+// product under the same parent. Products belong to the leaf, which also
+// keeps the full path as properties so product queries and filters don't
+// have to walk the tree.
+
+// GetCategoryTree returns every category with its product count, main
+// categories first and each level sorted by name. A count covers the node's
+// whole subtree. With a tenant, only that tenant's products are counted and
+// categories it has no products in are left out.
+func (r *ProductRepository) GetCategoryTree(ctx context.Context, tenantID string) ([]*pb.CategoryNode, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (m:MainCategory)-[:HAS_SUBCATEGORY]->(sc:Subcategory)-[:HAS_TYPE]->(c:SpecificType)
+			OPTIONAL MATCH (p:Product)-[:BELONGS_TO]->(c)
+			WHERE $tenant_id = '' OR p.tenant_id = $tenant_id
+			WITH m.name AS main, sc.name AS sub, c.name AS type, count(p) AS products
+			WHERE $tenant_id = '' OR products > 0
+			RETURN main, sub, type, products
+			ORDER BY main, sub, type
+		`, map[string]any{"tenant_id": tenantID})
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			tree      []*pb.CategoryNode
+			main, sub *pb.CategoryNode
+		)
+		for res.Next(ctx) {
+			row := res.Record().AsMap()
+			count := getInt64(row, "products")
+
+			if main == nil || main.Name != getString(row, "main") {
+				main = &pb.CategoryNode{Name: getString(row, "main")}
+				tree = append(tree, main)
+				sub = nil
+			}
+			if sub == nil || sub.Name != getString(row, "sub") {
+				sub = &pb.CategoryNode{Name: getString(row, "sub")}
+				main.Children = append(main.Children, sub)
+			}
+			sub.Children = append(sub.Children, &pb.CategoryNode{
+				Name:         getString(row, "type"),
+				ProductCount: int32(count),
+			})
+			sub.ProductCount += int32(count)
+			main.ProductCount += int32(count)
+		}
+		return tree, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]*pb.CategoryNode), nil
+}
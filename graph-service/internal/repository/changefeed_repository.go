@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// ProductChange is an outbox event that changed a product, with the
+// product as it is now. Product is nil once the product is deleted.
+type ProductChange struct {
+	EventID    string
+	Type       string
+	ProductID  string
+	OccurredAt int64
+	Product    *pb.Product
+}
+
+// productChangeTypes are the event types that change what a product read
+// returns.
+var productChangeTypes = []string{
+	events.ProductCreated,
+	events.ProductUpdated,
+	events.ProductDeleted,
+	events.StockUpdated,
+	events.SizeAdded,
+}
+
+// ListProductChanges returns up to limit product changes ordered by
+// (occurred_at, event id), resuming after the cursor and stopping at
+// until. A cursor without an id starts at its time. Outbox events are
+// kept once published, so the log goes back to the first event.
+func (r *ProductRepository) ListProductChanges(ctx context.Context, after pagetoken.Cursor, until int64, limit int) ([]ProductChange, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+
+		res, err := tx.Run(ctx, `
+			MATCH (e:Event)
+			WHERE (e.occurred_at > $after_time
+				OR (e.occurred_at = $after_time AND e.id > $after_id))
+				AND e.occurred_at <= $until
+				AND e.type IN $types
+				AND e.product_id <> ''
+			WITH e
+			ORDER BY e.occurred_at, e.id
+			LIMIT $limit
+			OPTIONAL MATCH (p:Product {id: e.product_id})`+productReturn(ctx, `,
+				e.id AS event_id, e.type AS type, e.product_id AS product_id, e.occurred_at AS occurred_at`)+`
+			ORDER BY occurred_at, event_id
+		`, map[string]any{
+			"after_time": after.LastTime,
+			"after_id":   after.LastID,
+			"until":      until,
+			"types":      productChangeTypes,
+			"limit":      limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		changes := []ProductChange{}
+		for res.Next(ctx) {
+			record := res.Record()
+			props := record.AsMap()
+			change := ProductChange{
+				EventID:    getString(props, "event_id"),
+				Type:       getString(props, "type"),
+				ProductID:  getString(props, "product_id"),
+				OccurredAt: getInt64(props, "occurred_at"),
+			}
+			if props["p"] != nil {
+				change.Product = productFromRecord(record)
+			}
+			changes = append(changes, change)
+		}
+		return changes, res.Err()
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]ProductChange), nil
+}
@@ -0,0 +1,360 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+var errCollectionNotFound = notFoundf("collection not found")
+
+func (r *ProductRepository) CreateCollection(ctx context.Context, c *pb.Collection) (string, error) {
+	if c.GetName() == "" {
+		return "", fieldErrorf("collection.name", "collection name is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			CREATE (col:Collection {
+				id: $id,
+				tenant_id: $tenant_id,
+				name: $name,
+				description: $description,
+				created_at: $now,
+				updated_at: $now
+			})
+			RETURN col.id AS id
+		`, map[string]any{
+			"id":          idOrNew(c.Id),
+			"tenant_id":   c.TenantId,
+			"name":        c.Name,
+			"description": c.Description,
+			"now":         time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		id, _ := record.Get("id")
+		return id, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	id, _ := result.(string)
+	return id, nil
+}
+
+// GetCollection returns the collection and its products ordered by position.
+func (r *ProductRepository) GetCollection(ctx context.Context, id string) (*pb.Collection, []*pb.Product, error) {
+	if id == "" {
+		return nil, nil, fieldErrorf("id", "collection id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	type collectionResult struct {
+		collection *pb.Collection
+		products   []*pb.Product
+	}
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (col:Collection {id: $id})
+			RETURN col
+		`, map[string]any{"id": id})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			return nil, errCollectionNotFound
+		}
+		colNode, _ := res.Record().Values[0].(neo4j.Node)
+
+		res, err = tx.Run(ctx, `
+			MATCH (:Collection {id: $id})-[inc:INCLUDES]->(p:Product)
+			OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+			OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+			RETURN p, c, collect(s) as sizes, inc.position AS position
+			ORDER BY position
+		`, map[string]any{"id": id})
+		if err != nil {
+			return nil, err
+		}
+
+		products := []*pb.Product{}
+		for res.Next(ctx) {
+			products = append(products, productFromRecord(res.Record()))
+		}
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+
+		return collectionResult{
+			collection: collectionFromNode(colNode),
+			products:   products,
+		}, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := result.(collectionResult)
+	return cr.collection, cr.products, nil
+}
+
+func (r *ProductRepository) UpdateCollection(ctx context.Context, c *pb.Collection) error {
+	if c.GetId() == "" {
+		return fieldErrorf("collection.id", "collection id is required")
+	}
+	if c.GetName() == "" {
+		return fieldErrorf("collection.name", "collection name is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (col:Collection {id: $id})
+			SET col.name = $name,
+				col.description = $description,
+				col.updated_at = $now
+			RETURN col.id
+		`, map[string]any{
+			"id":          c.Id,
+			"name":        c.Name,
+			"description": c.Description,
+			"now":         time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			return nil, errCollectionNotFound
+		}
+		return nil, nil
+	})
+
+	return err
+}
+
+func (r *ProductRepository) DeleteCollection(ctx context.Context, id string) error {
+	if id == "" {
+		return fieldErrorf("id", "collection id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			MATCH (col:Collection {id: $id})
+			DETACH DELETE col
+		`, map[string]any{"id": id})
+		return nil, err
+	})
+
+	return err
+}
+
+// SetCollectionProducts replaces the collection's contents, assigning
+// positions in list order.
+func (r *ProductRepository) SetCollectionProducts(ctx context.Context, id string, productIDs []string) error {
+	if id == "" {
+		return fieldErrorf("collection_id", "collection id is required")
+	}
+	seen := make(map[string]bool, len(productIDs))
+	for _, pid := range productIDs {
+		if seen[pid] {
+			return fieldErrorf("product_ids", "product %q listed more than once", pid)
+		}
+		seen[pid] = true
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (col:Collection {id: $id})
+			RETURN col.id
+		`, map[string]any{"id": id})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			return nil, errCollectionNotFound
+		}
+
+		if err := checkProductsExist(ctx, tx, productIDs); err != nil {
+			return nil, err
+		}
+
+		res, err = tx.Run(ctx, `
+			MATCH (col:Collection {id: $id})
+			SET col.updated_at = $now
+			WITH col
+			OPTIONAL MATCH (col)-[old:INCLUDES]->()
+			DELETE old
+			WITH DISTINCT col
+			UNWIND range(0, size($product_ids) - 1) AS i
+			MATCH (p:Product {id: $product_ids[i]})
+			CREATE (col)-[:INCLUDES {position: i}]->(p)
+			RETURN count(*) AS linked
+		`, map[string]any{
+			"id":          id,
+			"product_ids": productIDs,
+			"now":         time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		_, err = res.Consume(ctx)
+		return nil, err
+	})
+
+	return err
+}
+
+// AddProductToCollection inserts (or moves) a product at position, shifting
+// later entries down so positions stay dense.
+func (r *ProductRepository) AddProductToCollection(ctx context.Context, collectionID, productID string, position int32) error {
+	if collectionID == "" || productID == "" {
+		return fieldErrorf("product_id", "collection id and product id are required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (col:Collection {id: $collection_id}), (p:Product {id: $product_id})
+			RETURN col.id
+		`, map[string]any{
+			"collection_id": collectionID,
+			"product_id":    productID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			return nil, notFoundf("collection or product not found")
+		}
+
+		// Moving an existing entry: take it out first so shifting stays simple
+		if err := removeFromCollection(ctx, tx, collectionID, productID); err != nil {
+			return nil, err
+		}
+
+		_, err = tx.Run(ctx, `
+			MATCH (col:Collection {id: $collection_id}), (p:Product {id: $product_id})
+			OPTIONAL MATCH (col)-[existing:INCLUDES]->()
+			WITH col, p, count(existing) AS n
+			WITH col, p, CASE WHEN $position < 0 OR $position > n THEN n ELSE $position END AS pos
+			OPTIONAL MATCH (col)-[later:INCLUDES]->()
+			WHERE later.position >= pos
+			SET later.position = later.position + 1
+			WITH DISTINCT col, p, pos
+			CREATE (col)-[:INCLUDES {position: pos}]->(p)
+			SET col.updated_at = $now
+		`, map[string]any{
+			"collection_id": collectionID,
+			"product_id":    productID,
+			"position":      position,
+			"now":           time.Now().UnixMilli(),
+		})
+		return nil, err
+	})
+
+	return err
+}
+
+func (r *ProductRepository) RemoveProductFromCollection(ctx context.Context, collectionID, productID string) error {
+	if collectionID == "" || productID == "" {
+		return fieldErrorf("product_id", "collection id and product id are required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return nil, removeFromCollection(ctx, tx, collectionID, productID)
+	})
+
+	return err
+}
+
+// removeFromCollection unlinks a product and closes the gap it leaves.
+func removeFromCollection(ctx context.Context, tx neo4j.ManagedTransaction, collectionID, productID string) error {
+	_, err := tx.Run(ctx, `
+		MATCH (col:Collection {id: $collection_id})-[inc:INCLUDES]->(:Product {id: $product_id})
+		WITH col, inc, inc.position AS pos
+		DELETE inc
+		SET col.updated_at = $now
+		WITH col, pos
+		MATCH (col)-[later:INCLUDES]->()
+		WHERE later.position > pos
+		SET later.position = later.position - 1
+	`, map[string]any{
+		"collection_id": collectionID,
+		"product_id":    productID,
+		"now":           time.Now().UnixMilli(),
+	})
+	return err
+}
+
+// checkProductsExist returns an error naming any ids with no Product node.
+func checkProductsExist(ctx context.Context, tx neo4j.ManagedTransaction, productIDs []string) error {
+	res, err := tx.Run(ctx, `
+		UNWIND $product_ids AS pid
+		OPTIONAL MATCH (p:Product {id: pid})
+		WITH pid, p
+		WHERE p IS NULL
+		RETURN collect(pid) AS missing
+	`, map[string]any{"product_ids": productIDs})
+	if err != nil {
+		return err
+	}
+
+	record, err := res.Single(ctx)
+	if err != nil {
+		return err
+	}
+
+	missing, _ := record.Values[0].([]any)
+	if len(missing) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(missing))
+	for _, m := range missing {
+		if s, ok := m.(string); ok {
+			ids = append(ids, s)
+		}
+	}
+	return notFoundf("unknown products: %s", strings.Join(ids, ", "))
+}
+
+func collectionFromNode(node neo4j.Node) *pb.Collection {
+	props := node.Props
+	return &pb.Collection{
+		Id:          getString(props, "id"),
+		TenantId:    getString(props, "tenant_id"),
+		Name:        getString(props, "name"),
+		Description: getString(props, "description"),
+		CreatedAt:   getInt64(props, "created_at"),
+		UpdatedAt:   getInt64(props, "updated_at"),
+	}
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// resolveConflict turns a constraint violation from a write that raced
+// past its conflict check into the ConflictError that lookup finds once
+// the other write has committed. Other errors pass through.
+func (r *ProductRepository) resolveConflict(ctx context.Context, err error, lookup func(tx neo4j.ManagedTransaction) error) error {
+	if !isConstraintViolation(err) {
+		return err
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	_, lookupErr := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return nil, lookup(tx)
+	})
+	var conflict *ConflictError
+	if errors.As(lookupErr, &conflict) {
+		return conflict
+	}
+	return err
+}
+
+// isConstraintViolation reports whether err is a uniqueness constraint
+// failure, as reported by Neo4j or Memgraph.
+func isConstraintViolation(err error) bool {
+	var dbErr *neo4j.Neo4jError
+	if !errors.As(err, &dbErr) {
+		return false
+	}
+	return dbErr.Code == "Neo.ClientError.Schema.ConstraintValidationFailed" ||
+		strings.Contains(dbErr.Msg, "unique constraint violation")
+}
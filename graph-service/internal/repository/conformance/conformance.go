@@ -0,0 +1,652 @@
+// Package conformance checks that a repository.Repository implementation
+// behaves like the Neo4j reference: same validation errors, not-found
+// handling, pagination order and translation fallback. TestConformance
+// runs it against the backends named in CONFORMANCE_BACKENDS.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"google.golang.org/protobuf/proto"
+)
+
+// Check is one named conformance check.
+type Check struct {
+	Name string
+	Err  error
+}
+
+// Run exercises repo against a throwaway tenant and returns every check
+// with its outcome. Products it creates are deleted before it returns.
+// It writes to the store, so never point it at production data.
+func Run(ctx context.Context, repo repository.Repository) []Check {
+	s := &suite{
+		repo:   repo,
+		prefix: fmt.Sprintf("conformance-%d", time.Now().UnixNano()),
+		start:  time.Now().UnixMilli() - 1,
+	}
+	s.tenant = s.prefix
+	defer s.cleanup(ctx)
+
+	var checks []Check
+	for _, c := range []struct {
+		name string
+		fn   func(context.Context) error
+	}{
+		{"create and get", s.createAndGet},
+		{"create validation", s.createValidation},
+		{"get missing", s.getMissing},
+		{"update", s.update},
+		{"update stock", s.updateStock},
+		{"sku uniqueness", s.skuUniqueness},
+		{"identifiers", s.identifiers},
+		{"generated slugs", s.generatedSlugs},
+		{"list products", s.listProducts},
+		{"list updated since", s.listUpdatedSince},
+		{"new arrivals", s.newArrivals},
+		{"deals", s.deals},
+		{"translations", s.translations},
+		{"delete", s.delete},
+	} {
+		checks = append(checks, Check{Name: c.name, Err: c.fn(ctx)})
+	}
+	return checks
+}
+
+type suite struct {
+	repo    repository.Repository
+	prefix  string
+	tenant  string
+	start   int64
+	created []string
+}
+
+func (s *suite) id(name string) string { return s.prefix + "-" + name }
+
+func (s *suite) product(name string, price, originalPrice float64, inStock bool) *pb.Product {
+	stock := int32(0)
+	if inStock {
+		stock = 5
+	}
+	return &pb.Product{
+		Id:            s.id(name),
+		Name:          "Conformance " + name,
+		Brand:         "Acme",
+		Color:         "blue",
+		Price:         price,
+		OriginalPrice: originalPrice,
+		Description:   "A product created by the conformance suite",
+		Tags:          []string{"conformance", name},
+		Images:        []string{"https://example.com/" + name + ".jpg"},
+		Attributes:    map[string]string{"material": "cotton"},
+		TenantId:      s.tenant,
+		Category: &pb.ProductCategory{
+			MainCategory: "Apparel",
+			Subcategory:  "Shirts",
+			SpecificType: "T-Shirt",
+		},
+		TaxClass: "clothing",
+		Shipping: &pb.ShippingProfile{WeightGrams: 250, LengthMm: 300, WidthMm: 200, HeightMm: 30},
+		Sizes: []*pb.ProductSize{
+			{Sku: s.id(name) + "-m", Size: "M", Stock: stock, InStock: inStock, Variants: []string{"slim"}},
+			{Sku: s.id(name) + "-l", Size: "L", Stock: 0, InStock: false},
+		},
+	}
+}
+
+func (s *suite) create(ctx context.Context, p *pb.Product) error {
+	if err := s.repo.CreateProduct(ctx, p); err != nil {
+		return fmt.Errorf("create %s: %w", p.Id, err)
+	}
+	s.created = append(s.created, p.Id)
+	return nil
+}
+
+func (s *suite) cleanup(ctx context.Context) {
+	for _, id := range s.created {
+		s.repo.DeleteProduct(ctx, id)
+	}
+}
+
+func (s *suite) createAndGet(ctx context.Context) error {
+	want := s.product("a", 80, 100, true)
+	if err := s.create(ctx, want); err != nil {
+		return err
+	}
+
+	got, err := s.repo.GetProduct(ctx, want.Id)
+	if err != nil {
+		return err
+	}
+	return sameProduct(got, want)
+}
+
+func (s *suite) createValidation(ctx context.Context) error {
+	for field, p := range map[string]*pb.Product{
+		"product.id":    {Name: "x", Brand: "x"},
+		"product.name":  {Id: s.id("invalid"), Brand: "x"},
+		"product.brand": {Id: s.id("invalid"), Name: "x"},
+	} {
+		var fieldErr *repository.FieldError
+		err := s.repo.CreateProduct(ctx, p)
+		if !errors.As(err, &fieldErr) || fieldErr.Field != field {
+			return fmt.Errorf("missing %s: got %v, want a FieldError on %s", field, err, field)
+		}
+	}
+
+	tooMany := s.product("huge", 1, 1, true)
+	tooMany.Tags = make([]string, 1000)
+	if err := s.repo.CreateProduct(ctx, tooMany); !errors.Is(err, repository.ErrTooLarge) {
+		return fmt.Errorf("oversized product: got %v, want ErrTooLarge", err)
+	}
+	return nil
+}
+
+func (s *suite) getMissing(ctx context.Context) error {
+	if _, err := s.repo.GetProduct(ctx, s.id("missing")); !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("got %v, want ErrNotFound", err)
+	}
+	return nil
+}
+
+func (s *suite) update(ctx context.Context) error {
+	p := s.product("a", 70, 100, true)
+	p.Name = "Conformance a (renamed)"
+	p.Tags = []string{"renamed"}
+	p.Shipping = nil
+	changes, err := s.repo.UpdateProduct(ctx, p)
+	if err != nil {
+		return err
+	}
+	var changed []string
+	for _, change := range changes {
+		changed = append(changed, change.Field)
+	}
+	if !slices.Equal(changed, []string{"name", "price", "tags"}) {
+		return fmt.Errorf("update changed %v, want [name price tags]", changed)
+	}
+
+	got, err := s.repo.GetProduct(ctx, p.Id)
+	if err != nil {
+		return err
+	}
+	if got.Name != p.Name || got.Price != 70 || !slices.Equal(got.Tags, p.Tags) {
+		return fmt.Errorf("after update got name=%q price=%v tags=%v", got.Name, got.Price, got.Tags)
+	}
+	if want := s.product("a", 0, 0, true).Shipping; !proto.Equal(got.Shipping, want) {
+		return fmt.Errorf("update without a shipping profile changed it to %v", got.Shipping)
+	}
+	if got.UpdatedAt < got.CreatedAt {
+		return fmt.Errorf("updated_at %d is before created_at %d", got.UpdatedAt, got.CreatedAt)
+	}
+
+	// A new category moves the product; a nil one leaves it where it is
+	want := s.product("a", 0, 0, true).Category
+	p.Category = &pb.ProductCategory{MainCategory: "Apparel", Subcategory: "Shirts", SpecificType: "Polo"}
+	if changes, err := s.repo.UpdateProduct(ctx, p); err != nil {
+		return err
+	} else if len(changes) != 1 || changes[0].Field != "category" {
+		return fmt.Errorf("moving to Polo changed %v, want only the category", changes)
+	}
+	p.Category = nil
+	if changes, err := s.repo.UpdateProduct(ctx, p); err != nil || len(changes) > 0 {
+		return fmt.Errorf("repeating an update: got changes %v, err %v", changes, err)
+	}
+	if got, err = s.repo.GetProduct(ctx, p.Id); err != nil {
+		return err
+	}
+	if got.GetCategory().GetSpecificType() != "Polo" {
+		return fmt.Errorf("after moving to Polo got category %v", got.Category)
+	}
+	p.Category = want
+	if _, err := s.repo.UpdateProduct(ctx, p); err != nil {
+		return err
+	}
+
+	// Updating a product that doesn't exist is a no-op, not an error
+	changes, err = s.repo.UpdateProduct(ctx, s.product("missing", 1, 1, true))
+	if err == nil && len(changes) > 0 {
+		return fmt.Errorf("updating a missing product changed %v", changes)
+	}
+	return err
+}
+
+func (s *suite) updateStock(ctx context.Context) error {
+	sku := s.id("a") + "-m"
+	if err := s.repo.UpdateStock(ctx, sku, 0); err != nil {
+		return err
+	}
+	got, err := s.repo.GetProduct(ctx, s.id("a"))
+	if err != nil {
+		return err
+	}
+	size := findSize(got, sku)
+	if size == nil || size.Stock != 0 || size.InStock {
+		return fmt.Errorf("after setting stock to 0 got %v", size)
+	}
+
+	if err := s.repo.UpdateStock(ctx, sku, 3); err != nil {
+		return err
+	}
+	got, err = s.repo.GetProduct(ctx, s.id("a"))
+	if err != nil {
+		return err
+	}
+	if size := findSize(got, sku); size == nil || size.Stock != 3 || !size.InStock {
+		return fmt.Errorf("after setting stock to 3 got %v", size)
+	}
+
+	var fieldErr *repository.FieldError
+	if err := s.repo.UpdateStock(ctx, "", 1); !errors.As(err, &fieldErr) {
+		return fmt.Errorf("empty sku: got %v, want a FieldError", err)
+	}
+	return nil
+}
+
+func (s *suite) skuUniqueness(ctx context.Context) error {
+	owner := s.id("a")
+	added := &pb.ProductSize{Sku: owner + "-xl", Size: "XL", Stock: 2, InStock: true}
+	if err := s.repo.AddProductSize(ctx, owner, added); err != nil {
+		return fmt.Errorf("add size: %w", err)
+	}
+	got, err := s.repo.GetProduct(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if size := findSize(got, added.Sku); size == nil || size.Size != "XL" || size.Stock != 2 {
+		return fmt.Errorf("after adding a size got %v", size)
+	}
+
+	var conflict *repository.ConflictError
+	err = s.repo.AddProductSize(ctx, owner, &pb.ProductSize{Sku: owner + "-m", Size: "M"})
+	if !errors.As(err, &conflict) || conflict.ProductID != owner {
+		return fmt.Errorf("adding a taken sku: got %v, want a ConflictError naming %s", err, owner)
+	}
+
+	duplicate := s.product("dup", 10, 10, true)
+	duplicate.Sizes[0].Sku = owner + "-m"
+	err = s.repo.CreateProduct(ctx, duplicate)
+	if !errors.As(err, &conflict) || conflict.Field != "sku" || conflict.Value != owner+"-m" || conflict.ProductID != owner {
+		return fmt.Errorf("creating with a taken sku: got %v, want a ConflictError naming %s", err, owner)
+	}
+	if _, err := s.repo.GetProduct(ctx, duplicate.Id); !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("conflicting product was created: got %v, want ErrNotFound", err)
+	}
+
+	err = s.repo.AddProductSize(ctx, s.id("missing"), &pb.ProductSize{Sku: s.id("missing") + "-m", Size: "M"})
+	if !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("adding a size to a missing product: got %v, want ErrNotFound", err)
+	}
+	return nil
+}
+
+func (s *suite) identifiers(ctx context.Context) error {
+	// Products here live in their own tenant, out of the listing checks
+	product := func(name string) *pb.Product {
+		p := s.product(name, 30, 30, true)
+		p.TenantId = s.tenant + "-ids"
+		return p
+	}
+
+	p := product("ids")
+	p.Slug = s.id("ids")
+	p.ExternalId = "erp-" + s.id("ids")
+	p.Gtin = s.gtin()
+	if err := s.create(ctx, p); err != nil {
+		return err
+	}
+
+	// The 13-digit form a scanner reads resolves the stored GTIN-14
+	for kind, value := range map[string]string{
+		repository.IdentifierID:         p.Id,
+		repository.IdentifierSlug:       p.Slug,
+		repository.IdentifierGTIN:       p.Gtin[1:],
+		repository.IdentifierExternalID: p.ExternalId,
+	} {
+		for _, asked := range []string{kind, ""} {
+			got, matched, err := s.repo.ResolveProduct(ctx, asked, value)
+			if err != nil {
+				return fmt.Errorf("resolve %s %q: %w", kind, value, err)
+			}
+			if got.Id != p.Id || matched != kind {
+				return fmt.Errorf("resolve %s %q: got %s by %s", kind, value, got.Id, matched)
+			}
+		}
+	}
+	if _, _, err := s.repo.ResolveProduct(ctx, repository.IdentifierSlug, s.id("no-such-slug")); !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("resolve missing slug: got %v, want ErrNotFound", err)
+	}
+
+	var fieldErr *repository.FieldError
+	invalid := product("bad-gtin")
+	invalid.Gtin = "4006381333932"
+	if err := s.repo.CreateProduct(ctx, invalid); !errors.As(err, &fieldErr) || fieldErr.Field != "product.gtin" {
+		return fmt.Errorf("bad check digit: got %v, want a FieldError on product.gtin", err)
+	}
+
+	var conflict *repository.ConflictError
+	other := product("ids-other")
+	other.Gtin = p.Gtin
+	if err := s.repo.CreateProduct(ctx, other); !errors.As(err, &conflict) || conflict.Field != repository.IdentifierGTIN || conflict.ProductID != p.Id {
+		return fmt.Errorf("create with a taken gtin: got %v, want a ConflictError naming %s", err, p.Id)
+	}
+
+	// An update without identifiers keeps them
+	update := product("ids")
+	if _, err := s.repo.UpdateProduct(ctx, update); err != nil {
+		return err
+	}
+	got, err := s.repo.GetProduct(ctx, p.Id)
+	if err != nil {
+		return err
+	}
+	if got.Slug != p.Slug || got.Gtin != p.Gtin || got.ExternalId != p.ExternalId {
+		return fmt.Errorf("after update got slug=%q gtin=%q external_id=%q", got.Slug, got.Gtin, got.ExternalId)
+	}
+
+	owner := product("slug-owner")
+	owner.Slug = s.id("slug-owner")
+	if err := s.create(ctx, owner); err != nil {
+		return err
+	}
+	update.Slug = owner.Slug
+	if _, err := s.repo.UpdateProduct(ctx, update); !errors.As(err, &conflict) || conflict.Field != repository.IdentifierSlug {
+		return fmt.Errorf("update to a taken slug: got %v, want a ConflictError on slug", err)
+	}
+	return nil
+}
+
+func (s *suite) generatedSlugs(ctx context.Context) error {
+	// Same name and brand twice; the run prefix keeps the base slug free
+	var slugs []string
+	for _, name := range []string{"slug-first", "slug-second"} {
+		p := s.product(name, 30, 30, true)
+		p.TenantId = s.tenant + "-ids"
+		p.Name = "Ça Va Tee " + s.prefix
+		p.Brand = "Acmé"
+		if err := s.create(ctx, p); err != nil {
+			return err
+		}
+		got, _, err := s.repo.ResolveProduct(ctx, repository.IdentifierSlug, p.Slug)
+		if err != nil {
+			return fmt.Errorf("resolve generated slug %q: %w", p.Slug, err)
+		}
+		if got.Id != p.Id {
+			return fmt.Errorf("generated slug %q resolved %s, want %s", p.Slug, got.Id, p.Id)
+		}
+		slugs = append(slugs, got.Slug)
+	}
+
+	want := "ca-va-tee-" + s.prefix + "-acme"
+	if slugs[0] != want || slugs[1] != want+"-2" {
+		return fmt.Errorf("generated slugs %q, want %q and %q", slugs, want, want+"-2")
+	}
+	return nil
+}
+
+// gtin returns a GTIN-14 unique to this run, in the restricted-circulation
+// "2" prefix range so it can't collide with real products.
+func (s *suite) gtin() string {
+	digits := fmt.Sprintf("02%011d", time.Now().UnixNano()%100_000_000_000)
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return digits + strconv.Itoa((10-sum%10)%10)
+}
+
+func (s *suite) listProducts(ctx context.Context) error {
+	if err := s.create(ctx, s.product("b", 50, 100, true)); err != nil {
+		return err
+	}
+	if err := s.create(ctx, s.product("c", 90, 90, false)); err != nil {
+		return err
+	}
+
+	// Ids are ordered, so paging from the prefix walks a, b, c
+	first, err := s.repo.ListProducts(ctx, "", s.prefix, 2, false)
+	if err != nil {
+		return err
+	}
+	if got := ids(first); !slices.Equal(got, []string{s.id("a"), s.id("b")}) {
+		return fmt.Errorf("first page: got %v", got)
+	}
+	second, err := s.repo.ListProducts(ctx, "", s.id("b"), 1, false)
+	if err != nil {
+		return err
+	}
+	if got := ids(second); !slices.Equal(got, []string{s.id("c")}) {
+		return fmt.Errorf("second page: got %v", got)
+	}
+	return s.archive(ctx)
+}
+
+// archive checks archived products drop out of listings unless asked for.
+func (s *suite) archive(ctx context.Context) error {
+	archived := []string{s.id("b"), s.id("missing")}
+	if n, err := s.repo.SetProductsArchived(ctx, archived, true); err != nil || n != 1 {
+		return fmt.Errorf("archive b: got %d updated, err %v; want 1", n, err)
+	}
+	defer s.repo.SetProductsArchived(ctx, archived, false)
+
+	active, err := s.repo.ListProducts(ctx, "", s.prefix, 2, false)
+	if err != nil {
+		return err
+	}
+	if got := ids(active); !slices.Equal(got, []string{s.id("a"), s.id("c")}) {
+		return fmt.Errorf("active products: got %v", got)
+	}
+	all, err := s.repo.ListProducts(ctx, "", s.prefix, 2, true)
+	if err != nil {
+		return err
+	}
+	if got := ids(all); !slices.Equal(got, []string{s.id("a"), s.id("b")}) {
+		return fmt.Errorf("products including the archive: got %v", got)
+	}
+	if got, err := s.repo.GetProduct(ctx, s.id("b")); err != nil || !got.Archived {
+		return fmt.Errorf("archived product: got %v, err %v", got, err)
+	}
+	return nil
+}
+
+func (s *suite) listUpdatedSince(ctx context.Context) error {
+	var seen []string
+	after := pagetoken.Cursor{}
+	for page := 0; page < 100; page++ {
+		products, err := s.repo.ListProductsUpdatedSince(ctx, "", s.start, after, 50)
+		if err != nil {
+			return err
+		}
+		for i, p := range products {
+			if i > 0 && (p.UpdatedAt < products[i-1].UpdatedAt ||
+				p.UpdatedAt == products[i-1].UpdatedAt && p.Id <= products[i-1].Id) {
+				return fmt.Errorf("not ordered by (updated_at, id) at %s", p.Id)
+			}
+			if p.TenantId == s.tenant {
+				seen = append(seen, p.Id)
+			}
+		}
+		if len(products) < 50 {
+			break
+		}
+		last := products[len(products)-1]
+		after = pagetoken.Cursor{LastID: last.Id, LastTime: last.UpdatedAt}
+	}
+
+	slices.Sort(seen)
+	if want := []string{s.id("a"), s.id("b"), s.id("c")}; !slices.Equal(seen, want) {
+		return fmt.Errorf("got %v, want %v", seen, want)
+	}
+	return nil
+}
+
+func (s *suite) newArrivals(ctx context.Context) error {
+	filter := &pb.CatalogFilter{TenantId: s.tenant, MainCategory: "Apparel"}
+
+	// c has no stock, so only a and b qualify, newest first
+	products, err := s.repo.GetNewArrivals(ctx, filter, time.Hour, pagetoken.Cursor{}, 10)
+	if err != nil {
+		return err
+	}
+	if got := ids(products); !slices.Equal(got, []string{s.id("b"), s.id("a")}) {
+		return fmt.Errorf("got %v", got)
+	}
+
+	next, err := s.repo.GetNewArrivals(ctx, filter, time.Hour, pagetoken.Cursor{
+		LastID:   products[0].Id,
+		LastTime: products[0].CreatedAt,
+	}, 10)
+	if err != nil {
+		return err
+	}
+	if got := ids(next); !slices.Equal(got, []string{s.id("a")}) {
+		return fmt.Errorf("after cursor got %v", got)
+	}
+
+	other, err := s.repo.GetNewArrivals(ctx, &pb.CatalogFilter{TenantId: s.tenant, MainCategory: "Footwear"}, time.Hour, pagetoken.Cursor{}, 10)
+	if err != nil {
+		return err
+	}
+	if len(other) != 0 {
+		return fmt.Errorf("category filter let through %v", ids(other))
+	}
+	return nil
+}
+
+func (s *suite) deals(ctx context.Context) error {
+	filter := &pb.CatalogFilter{TenantId: s.tenant}
+
+	// b is 50% off, a 30% off, c isn't discounted
+	deals, err := s.repo.GetDeals(ctx, filter, pagetoken.Cursor{}, 10)
+	if err != nil {
+		return err
+	}
+	var got []string
+	for _, d := range deals {
+		got = append(got, fmt.Sprintf("%s:%.0f", d.Product.Id, d.DiscountPercent))
+	}
+	if want := []string{s.id("b") + ":50", s.id("a") + ":30"}; !slices.Equal(got, want) {
+		return fmt.Errorf("got %v, want %v", got, want)
+	}
+
+	next, err := s.repo.GetDeals(ctx, filter, pagetoken.Cursor{
+		LastID:   deals[0].Product.Id,
+		LastRank: deals[0].DiscountPercent,
+	}, 10)
+	if err != nil {
+		return err
+	}
+	if len(next) != 1 || next[0].Product.Id != s.id("a") {
+		return fmt.Errorf("after cursor got %d deals", len(next))
+	}
+	return nil
+}
+
+func (s *suite) translations(ctx context.Context) error {
+	if err := s.repo.UpsertProductTranslation(ctx, s.id("a"), "FR", "Produit a", ""); err != nil {
+		return err
+	}
+	if err := s.repo.UpsertProductTranslation(ctx, s.id("a"), "fr_CA", "", "Description québécoise"); err != nil {
+		return err
+	}
+
+	products := []*pb.Product{
+		{Id: s.id("a"), Name: "default", Description: "default"},
+		{Id: s.id("b"), Name: "default", Description: "default"},
+	}
+	if err := s.repo.LocalizeProducts(ctx, products, "fr-CA"); err != nil {
+		return err
+	}
+	a, b := products[0], products[1]
+	if a.Name != "Produit a" || a.Description != "Description québécoise" || a.Locale != "fr" {
+		return fmt.Errorf("a localized to name=%q description=%q locale=%q", a.Name, a.Description, a.Locale)
+	}
+	if b.Name != "default" || b.Locale != "" {
+		return fmt.Errorf("untranslated b changed to name=%q locale=%q", b.Name, b.Locale)
+	}
+
+	if err := s.repo.UpsertProductTranslation(ctx, s.id("missing"), "fr", "x", ""); !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("missing product: got %v, want ErrNotFound", err)
+	}
+	var fieldErr *repository.FieldError
+	if err := s.repo.UpsertProductTranslation(ctx, s.id("a"), "fr", "", ""); !errors.As(err, &fieldErr) {
+		return fmt.Errorf("empty translation: got %v, want a FieldError", err)
+	}
+	return nil
+}
+
+func (s *suite) delete(ctx context.Context) error {
+	if err := s.repo.DeleteProduct(ctx, s.id("a")); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetProduct(ctx, s.id("a")); !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("after delete got %v, want ErrNotFound", err)
+	}
+	// Deleting again is a no-op
+	return s.repo.DeleteProduct(ctx, s.id("a"))
+}
+
+// sameProduct compares the stored fields of got against what was created.
+// Size order is not part of the contract.
+func sameProduct(got, want *pb.Product) error {
+	switch {
+	case got.Id != want.Id, got.Name != want.Name, got.Brand != want.Brand, got.Color != want.Color:
+		return fmt.Errorf("identity fields differ: got %s/%q/%q/%q", got.Id, got.Name, got.Brand, got.Color)
+	case got.Price != want.Price, got.OriginalPrice != want.OriginalPrice:
+		return fmt.Errorf("prices differ: got %v/%v", got.Price, got.OriginalPrice)
+	case got.Description != want.Description, got.TenantId != want.TenantId:
+		return fmt.Errorf("description or tenant differ")
+	case !slices.Equal(got.Tags, want.Tags), !slices.Equal(got.Images, want.Images):
+		return fmt.Errorf("tags or images differ: got %v %v", got.Tags, got.Images)
+	case len(got.Attributes) != len(want.Attributes) || got.Attributes["material"] != want.Attributes["material"]:
+		return fmt.Errorf("attributes differ: got %v", got.Attributes)
+	case got.GetCategory().GetMainCategory() != want.Category.MainCategory,
+		got.GetCategory().GetSubcategory() != want.Category.Subcategory,
+		got.GetCategory().GetSpecificType() != want.Category.SpecificType:
+		return fmt.Errorf("category differs: got %v", got.Category)
+	case got.TaxClass != want.TaxClass:
+		return fmt.Errorf("tax class differs: got %q", got.TaxClass)
+	case !proto.Equal(got.Shipping, want.Shipping):
+		return fmt.Errorf("shipping profile differs: got %v", got.Shipping)
+	case got.CreatedAt == 0 || got.UpdatedAt != got.CreatedAt:
+		return fmt.Errorf("timestamps: created_at=%d updated_at=%d", got.CreatedAt, got.UpdatedAt)
+	case len(got.Sizes) != len(want.Sizes):
+		return fmt.Errorf("got %d sizes, want %d", len(got.Sizes), len(want.Sizes))
+	}
+	for _, w := range want.Sizes {
+		g := findSize(got, w.Sku)
+		if g == nil || g.Size != w.Size || g.Stock != w.Stock || g.InStock != w.InStock || !slices.Equal(g.Variants, w.Variants) {
+			return fmt.Errorf("size %s: got %v", w.Sku, g)
+		}
+	}
+	return nil
+}
+
+func findSize(p *pb.Product, sku string) *pb.ProductSize {
+	for _, size := range p.Sizes {
+		if size.Sku == sku {
+			return size
+		}
+	}
+	return nil
+}
+
+func ids(products []*pb.Product) []string {
+	out := make([]string, 0, len(products))
+	for _, p := range products {
+		out = append(out, p.Id)
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/config"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/graphdb"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+// TestConformance runs the checks against each backend named in
+// CONFORMANCE_BACKENDS, configured through the same environment as the
+// server, and is skipped when it is unset. It creates and deletes its own
+// products; don't point it at production.
+//
+//	CONFORMANCE_BACKENDS=neo4j,postgres go test ./internal/repository/conformance
+func TestConformance(t *testing.T) {
+	backends := os.Getenv("CONFORMANCE_BACKENDS")
+	if backends == "" {
+		t.Skip("set CONFORMANCE_BACKENDS, e.g. neo4j,postgres, to run against live stores")
+	}
+	cfg := config.Load()
+
+	for _, backend := range strings.Split(backends, ",") {
+		backend = strings.TrimSpace(backend)
+		t.Run(backend, func(t *testing.T) {
+			ctx := context.Background()
+			repo, closeRepo, err := open(ctx, backend, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer closeRepo()
+
+			for _, check := range Run(ctx, repo) {
+				t.Run(check.Name, func(t *testing.T) {
+					if check.Err != nil {
+						t.Error(check.Err)
+					}
+				})
+			}
+		})
+	}
+}
+
+func open(ctx context.Context, backend string, cfg config.Config) (repository.Repository, func(), error) {
+	switch backend {
+	case "neo4j":
+		driver, opts, err := graphdb.Open(graphdb.SettingsFrom(cfg))
+		if err != nil {
+			return nil, nil, err
+		}
+		return repository.NewProductRepository(driver, opts...), func() { driver.Close(ctx) }, nil
+
+	case "postgres":
+		pool, err := pgxpool.New(ctx, cfg.PostgresURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		repo := repository.NewPostgresRepository(pool)
+		if err := repo.EnsureSchema(ctx); err != nil {
+			pool.Close()
+			return nil, nil, err
+		}
+		return repo, pool.Close, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown backend %q", backend)
+	}
+}
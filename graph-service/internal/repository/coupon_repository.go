@@ -0,0 +1,456 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const (
+	maxCouponCodeLen     = 64
+	maxCouponProducts    = 500
+	maxCouponCategories  = 100
+	maxCouponCategoryLen = 128
+)
+
+var couponCodePattern = regexp.MustCompile(`^[A-Z0-9_-]+$`)
+
+var errCouponNotFound = notFoundf("coupon not found")
+
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func checkCoupon(c *pb.Coupon) error {
+	c.Code = normalizeCouponCode(c.Code)
+	if c.Code == "" {
+		return fieldErrorf("coupon.code", "code is required")
+	}
+	if len(c.Code) > maxCouponCodeLen {
+		return &LimitError{Field: "coupon.code", Limit: maxCouponCodeLen, Actual: len(c.Code)}
+	}
+	if !couponCodePattern.MatchString(c.Code) {
+		return fieldErrorf("coupon.code", "code may only contain letters, digits, - and _")
+	}
+
+	switch {
+	case c.PercentOff < 0 || c.PercentOff > 100:
+		return fieldErrorf("coupon.percent_off", "percent_off must be between 0 and 100")
+	case c.AmountOff < 0:
+		return fieldErrorf("coupon.amount_off", "amount_off can't be negative")
+	case (c.PercentOff > 0) == (c.AmountOff > 0):
+		return fieldErrorf("coupon.percent_off", "exactly one of percent_off and amount_off is required")
+	case c.MaxUses < 0 || c.MaxUsesPerCustomer < 0:
+		return fieldErrorf("coupon.max_uses", "usage limits can't be negative")
+	case c.MinSubtotal < 0:
+		return fieldErrorf("coupon.min_subtotal", "min_subtotal can't be negative")
+	case c.StartsAt > 0 && c.ExpiresAt > 0 && c.ExpiresAt <= c.StartsAt:
+		return fieldErrorf("coupon.expires_at", "expires_at must be after starts_at")
+	}
+
+	if len(c.ProductIds) > maxCouponProducts {
+		return &LimitError{Field: "coupon.product_ids", Limit: maxCouponProducts, Actual: len(c.ProductIds)}
+	}
+	if len(c.Categories) > maxCouponCategories {
+		return &LimitError{Field: "coupon.categories", Limit: maxCouponCategories, Actual: len(c.Categories)}
+	}
+	for _, category := range c.Categories {
+		if category == "" || len(category) > maxCouponCategoryLen {
+			return fieldErrorf("coupon.categories", "category names are 1 to %d characters", maxCouponCategoryLen)
+		}
+	}
+	return nil
+}
+
+// CreateCoupon stores a new coupon with no uses and fills in its
+// timestamps.
+func (r *ProductRepository) CreateCoupon(ctx context.Context, c *pb.Coupon) error {
+	if err := checkCoupon(c); err != nil {
+		return err
+	}
+	productIDs, categories := c.ProductIds, c.Categories
+	if productIDs == nil {
+		productIDs = []string{}
+	}
+	if categories == nil {
+		categories = []string{}
+	}
+
+	now := time.Now().UnixMilli()
+	c.Uses = 0
+	c.CreatedAt, c.UpdatedAt = now, now
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			CREATE (:Coupon {
+				code: $code,
+				tenant_id: $tenant_id,
+				percent_off: $percent_off,
+				amount_off: $amount_off,
+				max_uses: $max_uses,
+				max_uses_per_customer: $max_uses_per_customer,
+				uses: 0,
+				starts_at: $starts_at,
+				expires_at: $expires_at,
+				product_ids: $product_ids,
+				categories: $categories,
+				min_subtotal: $min_subtotal,
+				created_at: $now,
+				updated_at: $now
+			})
+		`, map[string]any{
+			"code":                  c.Code,
+			"tenant_id":             c.TenantId,
+			"percent_off":           c.PercentOff,
+			"amount_off":            c.AmountOff,
+			"max_uses":              c.MaxUses,
+			"max_uses_per_customer": c.MaxUsesPerCustomer,
+			"starts_at":             c.StartsAt,
+			"expires_at":            c.ExpiresAt,
+			"product_ids":           productIDs,
+			"categories":            categories,
+			"min_subtotal":          c.MinSubtotal,
+			"now":                   now,
+		})
+		return nil, err
+	})
+	if isConstraintViolation(err) {
+		return fieldErrorf("coupon.code", "coupon %q already exists", c.Code)
+	}
+	return err
+}
+
+// ApplyCoupon sets the discount the coupon takes off each line of o, a
+// priced order, and returns the coupon. It fails with
+// ErrCouponNotApplicable if the coupon can't be used on o. customerID,
+// if set, is checked against the per-customer limit.
+func (r *ProductRepository) ApplyCoupon(ctx context.Context, code, customerID string, o *pb.Order) (*pb.Coupon, error) {
+	code = normalizeCouponCode(code)
+	if code == "" {
+		return nil, fieldErrorf("coupon_code", "coupon code is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		coupon, err := readCoupon(ctx, tx, code)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkRedeemable(ctx, tx, coupon, customerID); err != nil {
+			return nil, err
+		}
+
+		eligible, err := eligibleLines(ctx, tx, coupon, o.Lines)
+		if err != nil {
+			return nil, err
+		}
+		if err := discountLines(coupon, eligible); err != nil {
+			return nil, err
+		}
+		return coupon, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.Coupon), nil
+}
+
+// RedeemCoupon counts a use of the coupon for the order, failing with
+// ErrCouponNotApplicable once a usage limit is reached. The coupon is
+// locked while its uses are counted, so concurrent redemptions can't
+// overrun the limits. Redeeming for the same order twice counts once.
+func (r *ProductRepository) RedeemCoupon(ctx context.Context, code, customerID, orderID string) (*pb.Coupon, error) {
+	code = normalizeCouponCode(code)
+	if code == "" {
+		return nil, fieldErrorf("code", "coupon code is required")
+	}
+	if orderID == "" {
+		return nil, fieldErrorf("order_id", "order id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return redeemCoupon(ctx, tx, code, customerID, orderID, time.Now().UnixMilli())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.Coupon), nil
+}
+
+// redeemCoupon counts a use of the coupon, its code normalized, for the
+// order, as RedeemCoupon describes.
+func redeemCoupon(ctx context.Context, tx neo4j.ManagedTransaction, code, customerID, orderID string, now int64) (*pb.Coupon, error) {
+	params := map[string]any{
+		"code":        code,
+		"customer_id": customerID,
+		"order_id":    orderID,
+		"now":         now,
+	}
+	res, err := tx.Run(ctx, `
+		MATCH (c:Coupon {code: $code})
+		SET c.updated_at = $now
+		WITH c
+		OPTIONAL MATCH (done:Redemption {order_id: $order_id})-[:OF_COUPON]->(c)
+		RETURN c.code AS code, count(done) > 0 AS redeemed
+	`, params)
+	if err != nil {
+		return nil, err
+	}
+	if !res.Next(ctx) {
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errCouponNotFound
+	}
+	if redeemed, _ := res.Record().Values[1].(bool); redeemed {
+		return readCoupon(ctx, tx, code)
+	}
+
+	coupon, err := readCoupon(ctx, tx, code)
+	if err != nil {
+		return nil, err
+	}
+	if coupon.MaxUsesPerCustomer > 0 && customerID == "" {
+		return nil, fieldErrorf("customer_id", "coupon %s is limited per customer and needs a customer id", code)
+	}
+	if err := checkRedeemable(ctx, tx, coupon, customerID); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Run(ctx, `
+		MATCH (c:Coupon {code: $code})
+		SET c.uses = c.uses + 1
+		CREATE (:Redemption {
+			order_id: $order_id,
+			customer_id: $customer_id,
+			redeemed_at: $now
+		})-[:OF_COUPON]->(c)
+	`, params)
+	if err != nil {
+		return nil, err
+	}
+	return readCoupon(ctx, tx, code)
+}
+
+// applyOrderCoupon redeems the coupon for o, a priced order, and sets the
+// discount it takes off each line, so the discount and the use it counts
+// commit or roll back together.
+func applyOrderCoupon(ctx context.Context, tx neo4j.ManagedTransaction, code string, o *pb.Order, now int64) (*pb.Coupon, error) {
+	code = normalizeCouponCode(code)
+	if code == "" {
+		return nil, fieldErrorf("coupon_code", "coupon code is required")
+	}
+	coupon, err := redeemCoupon(ctx, tx, code, o.CustomerId, o.Id, now)
+	if err != nil {
+		return nil, err
+	}
+	eligible, err := eligibleLines(ctx, tx, coupon, o.Lines)
+	if err != nil {
+		return nil, err
+	}
+	if err := discountLines(coupon, eligible); err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// releaseCoupons gives back the uses the order's coupon redemptions
+// counted, for an order that was never placed.
+func releaseCoupons(ctx context.Context, tx neo4j.ManagedTransaction, orderID string, now int64) error {
+	_, err := tx.Run(ctx, `
+		MATCH (done:Redemption {order_id: $order_id})-[:OF_COUPON]->(c:Coupon)
+		SET c.uses = c.uses - 1, c.updated_at = $now
+		DETACH DELETE done
+	`, map[string]any{"order_id": orderID, "now": now})
+	return err
+}
+
+// checkRedeemable fails with ErrCouponNotApplicable if the coupon is
+// outside its validity window or has reached a usage limit.
+func checkRedeemable(ctx context.Context, tx neo4j.ManagedTransaction, c *pb.Coupon, customerID string) error {
+	now := time.Now().UnixMilli()
+	switch {
+	case c.StartsAt > 0 && now < c.StartsAt:
+		return fmt.Errorf("%w: coupon %s is not valid yet", ErrCouponNotApplicable, c.Code)
+	case c.ExpiresAt > 0 && now >= c.ExpiresAt:
+		return fmt.Errorf("%w: coupon %s has expired", ErrCouponNotApplicable, c.Code)
+	case c.MaxUses > 0 && c.Uses >= c.MaxUses:
+		return fmt.Errorf("%w: coupon %s has been used up", ErrCouponNotApplicable, c.Code)
+	}
+	if c.MaxUsesPerCustomer == 0 || customerID == "" {
+		return nil
+	}
+
+	res, err := tx.Run(ctx, `
+		MATCH (done:Redemption {customer_id: $customer_id})-[:OF_COUPON]->(:Coupon {code: $code})
+		RETURN count(done) AS uses
+	`, map[string]any{"customer_id": customerID, "code": c.Code})
+	if err != nil {
+		return err
+	}
+	record, err := res.Single(ctx)
+	if err != nil {
+		return err
+	}
+	if getInt64(record.AsMap(), "uses") >= int64(c.MaxUsesPerCustomer) {
+		return fmt.Errorf("%w: customer has already used coupon %s %d times", ErrCouponNotApplicable, c.Code, c.MaxUsesPerCustomer)
+	}
+	return nil
+}
+
+// eligibleLines returns the lines of products the coupon covers.
+func eligibleLines(ctx context.Context, tx neo4j.ManagedTransaction, c *pb.Coupon, lines []*pb.OrderLine) ([]*pb.OrderLine, error) {
+	skus := make([]string, 0, len(lines))
+	for _, line := range lines {
+		skus = append(skus, line.Sku)
+	}
+
+	res, err := tx.Run(ctx, `
+		UNWIND $skus AS sku
+		MATCH (p:Product)-[:HAS_SIZE]->(:Size {sku: sku})
+		OPTIONAL MATCH (p)-[:BELONGS_TO]->(cat:Category)
+		RETURN sku, p.id AS product_id, coalesce(p.tenant_id, '') AS tenant_id,
+			[cat.main_category, cat.subcategory, cat.specific_type] AS categories
+	`, map[string]any{"skus": skus})
+	if err != nil {
+		return nil, err
+	}
+	records, err := res.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	covered := make(map[string]bool, len(records))
+	for _, record := range records {
+		row := record.AsMap()
+		if c.TenantId != "" && getString(row, "tenant_id") != c.TenantId {
+			continue
+		}
+		ok := len(c.ProductIds) == 0 && len(c.Categories) == 0
+		ok = ok || slices.Contains(c.ProductIds, getString(row, "product_id"))
+		if names, isList := row["categories"].([]any); isList && !ok {
+			for _, name := range names {
+				if s, isString := name.(string); isString && slices.ContainsFunc(c.Categories, func(category string) bool {
+					return strings.EqualFold(category, s)
+				}) {
+					ok = true
+					break
+				}
+			}
+		}
+		covered[getString(row, "sku")] = ok
+	}
+
+	var eligible []*pb.OrderLine
+	for _, line := range lines {
+		if covered[line.Sku] {
+			eligible = append(eligible, line)
+		}
+	}
+	return eligible, nil
+}
+
+// discountLines sets the coupon's discount on the eligible lines. An
+// amount off is spread in proportion to the lines' totals, with the last
+// line taking the rounding remainder.
+func discountLines(c *pb.Coupon, eligible []*pb.OrderLine) error {
+	if len(eligible) == 0 {
+		return fmt.Errorf("%w: coupon %s covers nothing in the cart", ErrCouponNotApplicable, c.Code)
+	}
+	var subtotal int64
+	for _, line := range eligible {
+		subtotal += cents(line.Total)
+	}
+	if subtotal < cents(c.MinSubtotal) {
+		return fmt.Errorf("%w: coupon %s needs eligible items worth at least %.2f", ErrCouponNotApplicable, c.Code, c.MinSubtotal)
+	}
+
+	if c.PercentOff > 0 {
+		for _, line := range eligible {
+			line.Discount = float64(int64(math.Round(float64(cents(line.Total))*c.PercentOff/100))) / 100
+		}
+		return nil
+	}
+
+	off := min(cents(c.AmountOff), subtotal)
+	left := off
+	for i, line := range eligible {
+		share := left
+		if i < len(eligible)-1 && subtotal > 0 {
+			share = int64(math.Round(float64(off) * float64(cents(line.Total)) / float64(subtotal)))
+			share = min(share, left)
+		}
+		line.Discount = float64(share) / 100
+		left -= share
+	}
+	return nil
+}
+
+// cents converts an amount to whole cents.
+func cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func readCoupon(ctx context.Context, tx neo4j.ManagedTransaction, code string) (*pb.Coupon, error) {
+	res, err := tx.Run(ctx, `
+		MATCH (c:Coupon {code: $code})
+		RETURN c
+	`, map[string]any{"code": code})
+	if err != nil {
+		return nil, err
+	}
+	if !res.Next(ctx) {
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errCouponNotFound
+	}
+
+	node, _ := res.Record().Values[0].(neo4j.Node)
+	props := node.Props
+	c := &pb.Coupon{
+		Code:               getString(props, "code"),
+		TenantId:           getString(props, "tenant_id"),
+		MaxUses:            int32(getInt64(props, "max_uses")),
+		MaxUsesPerCustomer: int32(getInt64(props, "max_uses_per_customer")),
+		Uses:               int32(getInt64(props, "uses")),
+		StartsAt:           getInt64(props, "starts_at"),
+		ExpiresAt:          getInt64(props, "expires_at"),
+		CreatedAt:          getInt64(props, "created_at"),
+		UpdatedAt:          getInt64(props, "updated_at"),
+	}
+	c.PercentOff, _ = props["percent_off"].(float64)
+	c.AmountOff, _ = props["amount_off"].(float64)
+	c.MinSubtotal, _ = props["min_subtotal"].(float64)
+	c.ProductIds = getStrings(props, "product_ids")
+	c.Categories = getStrings(props, "categories")
+	return c, nil
+}
+
+func getStrings(props map[string]any, key string) []string {
+	list, _ := props[key].([]any)
+	var strs []string
+	for _, val := range list {
+		if str, ok := val.(string); ok {
+			strs = append(strs, str)
+		}
+	}
+	return strs
+}
@@ -0,0 +1,75 @@
+package repository
+
+// Dialect describes the Cypher a graph database accepts. Queries stick to
+// the subset Neo4j 5 and Memgraph share, plain Cypher without APOC, and
+// consult the dialect where the two differ.
+type Dialect struct {
+	Name string
+
+	// ExistsSubqueries reports support for EXISTS { MATCH ... }; without it
+	// patterns are tested with the exists() function.
+	ExistsSubqueries bool
+
+	// Fulltext reports support for db.index.fulltext indexes. Search and
+	// text saved searches fail with ErrUnsupported without them.
+	Fulltext bool
+
+	// Bookmarks reports support for causal-consistency bookmarks.
+	Bookmarks bool
+}
+
+var (
+	// Neo4jDialect covers self-hosted Neo4j 5 and Neo4j Aura.
+	Neo4jDialect = Dialect{Name: "neo4j", ExistsSubqueries: true, Fulltext: true, Bookmarks: true}
+
+	// MemgraphDialect covers Memgraph 2.x without the MAGE modules.
+	MemgraphDialect = Dialect{Name: "memgraph"}
+)
+
+// Patterns tested with Dialect.exists. Property maps rather than WHERE
+// clauses keep them valid in both forms.
+const inStockPattern = "(p)-[:HAS_SIZE]->(:Size {in_stock: true})"
+
+// exists returns a predicate that is true when pattern matches.
+func (d Dialect) exists(pattern string) string {
+	if d.ExistsSubqueries {
+		return "EXISTS { MATCH " + pattern + " }"
+	}
+	return "exists(" + pattern + ")"
+}
+
+// nodeID returns an expression for the id of variable's node, valid for
+// the rest of the transaction: elementId() on Neo4j, which deprecates
+// id(), and id() on Memgraph, which lacks elementId().
+func (d Dialect) nodeID(variable string) string {
+	if d.Name == MemgraphDialect.Name {
+		return "id(" + variable + ")"
+	}
+	return "elementId(" + variable + ")"
+}
+
+// index returns the statement creating an index on label.property.
+func (d Dialect) index(name, label, property string) string {
+	if d.Name == MemgraphDialect.Name {
+		return "CREATE INDEX ON :" + label + "(" + property + ")"
+	}
+	return "CREATE INDEX " + name + " IF NOT EXISTS FOR (n:" + label + ") ON (n." + property + ")"
+}
+
+// pointIndex returns the statement creating a spatial index on the point
+// property label.property, for distance filters.
+func (d Dialect) pointIndex(name, label, property string) string {
+	if d.Name == MemgraphDialect.Name {
+		return "CREATE POINT INDEX ON :" + label + "(" + property + ")"
+	}
+	return "CREATE POINT INDEX " + name + " IF NOT EXISTS FOR (n:" + label + ") ON (n." + property + ")"
+}
+
+// uniqueConstraint returns the statement creating a uniqueness constraint
+// on label.property.
+func (d Dialect) uniqueConstraint(name, label, property string) string {
+	if d.Name == MemgraphDialect.Name {
+		return "CREATE CONSTRAINT ON (n:" + label + ") ASSERT n." + property + " IS UNIQUE"
+	}
+	return "CREATE CONSTRAINT " + name + " IF NOT EXISTS FOR (n:" + label + ") REQUIRE n." + property + " IS UNIQUE"
+}
@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// FindDuplicateProducts defaults.
+const (
+	defaultDuplicateSimilarity = 0.8
+	defaultDuplicateClusters   = 100
+)
+
+// duplicateCandidate is a product as FindDuplicateProducts compares it.
+type duplicateCandidate struct {
+	id    string
+	name  map[string]bool
+	attrs map[string]bool
+}
+
+// words splits s into lowercase letter and digit runs.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// newDuplicateCandidate normalizes a product's name to its words, less
+// those of its brand, which feeds often prefix, and its color and
+// attributes to lowercase key=value pairs.
+func newDuplicateCandidate(id, name, brand, color string, attributes map[string]string) duplicateCandidate {
+	c := duplicateCandidate{id: id, name: map[string]bool{}, attrs: map[string]bool{}}
+	brandWords := words(brand)
+	for _, word := range words(name) {
+		if !slices.Contains(brandWords, word) {
+			c.name[word] = true
+		}
+	}
+	if color != "" {
+		c.attrs["color="+strings.ToLower(strings.TrimSpace(color))] = true
+	}
+	for key, value := range attributes {
+		c.attrs[strings.ToLower(strings.TrimSpace(key))+"="+strings.ToLower(strings.TrimSpace(value))] = true
+	}
+	return c
+}
+
+// jaccard is |a ∩ b| / |a ∪ b|, or 0 when both are empty.
+func jaccard(a, b map[string]bool) float64 {
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+	union := len(a) + len(b) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+func (c duplicateCandidate) similarity(other duplicateCandidate) float64 {
+	name := jaccard(c.name, other.name)
+	if len(c.attrs) == 0 || len(other.attrs) == 0 {
+		return name
+	}
+	return 0.7*name + 0.3*jaccard(c.attrs, other.attrs)
+}
+
+// clusterDuplicates links the candidates pairwise at minSimilarity or more
+// and returns the clusters of two or more, each with the lowest
+// similarity among the links that formed it.
+func clusterDuplicates(candidates []duplicateCandidate, minSimilarity float64) []*pb.DuplicateCluster {
+	parent := make([]int, len(candidates))
+	lowest := make([]float64, len(candidates))
+	for i := range parent {
+		parent[i] = i
+		lowest[i] = 1
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			similarity := candidates[i].similarity(candidates[j])
+			if similarity < minSimilarity {
+				continue
+			}
+			a, b := root(i), root(j)
+			if a == b {
+				continue
+			}
+			parent[b] = a
+			lowest[a] = min(lowest[a], lowest[b], similarity)
+		}
+	}
+
+	members := map[int][]string{}
+	for i, c := range candidates {
+		r := root(i)
+		members[r] = append(members[r], c.id)
+	}
+	var clusters []*pb.DuplicateCluster
+	for r, ids := range members {
+		if len(ids) < 2 {
+			continue
+		}
+		slices.Sort(ids)
+		clusters = append(clusters, &pb.DuplicateCluster{ProductIds: ids, Similarity: lowest[r]})
+	}
+	return clusters
+}
+
+// FindDuplicateProducts clusters the tenant's products, or every tenant's
+// without one, that look like duplicates: same tenant and brand, and
+// similar names and attributes. Only products sharing a brand are
+// compared, so the work grows with the square of the largest brand.
+func (r *ProductRepository) FindDuplicateProducts(ctx context.Context, tenantID string, minSimilarity float64, limit int) ([]*pb.DuplicateCluster, error) {
+	if minSimilarity <= 0 {
+		minSimilarity = defaultDuplicateSimilarity
+	}
+	if minSimilarity > 1 {
+		return nil, fieldErrorf("min_similarity", "min similarity must be at most 1")
+	}
+	if limit <= 0 {
+		limit = defaultDuplicateClusters
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (p:Product)
+			WHERE $tenant_id = '' OR p.tenant_id = $tenant_id
+			RETURN p.id AS id, p.tenant_id AS tenant_id, p.name AS name, p.brand AS brand,
+				p.color AS color, p.attributes AS attributes
+			ORDER BY id
+		`, map[string]any{"tenant_id": tenantID})
+		if err != nil {
+			return nil, err
+		}
+
+		blocks := map[string][]duplicateCandidate{}
+		for res.Next(ctx) {
+			row := res.Record().AsMap()
+			var attributes map[string]string
+			if encoded := getString(row, "attributes"); encoded != "" {
+				json.Unmarshal([]byte(encoded), &attributes)
+			}
+			brand := getString(row, "brand")
+			block := getString(row, "tenant_id") + "\x00" + strings.Join(words(brand), " ")
+			blocks[block] = append(blocks[block], newDuplicateCandidate(
+				getString(row, "id"), getString(row, "name"), brand, getString(row, "color"), attributes))
+		}
+		return blocks, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters []*pb.DuplicateCluster
+	for _, candidates := range result.(map[string][]duplicateCandidate) {
+		clusters = append(clusters, clusterDuplicates(candidates, minSimilarity)...)
+	}
+	slices.SortFunc(clusters, func(a, b *pb.DuplicateCluster) int {
+		return cmp.Or(
+			cmp.Compare(len(b.ProductIds), len(a.ProductIds)),
+			cmp.Compare(a.ProductIds[0], b.ProductIds[0]),
+		)
+	})
+	if len(clusters) > limit {
+		clusters = clusters[:limit]
+	}
+	return clusters, nil
+}
+
+// mergeStatements fold $duplicate into $survivor, in order. Each sees the
+// previous ones' writes; the last deletes the duplicate.
+func mergeStatements(d Dialect) []string {
+	statements := []string{
+		// Sizes the survivor lacks move over.
+		`MATCH (p:Product {id: $survivor}), (:Product {id: $duplicate})-[h:HAS_SIZE]->(s:Size)
+		WHERE NOT ` + d.exists("(p)-[:HAS_SIZE]->(:Size {size: s.size})") + `
+		DELETE h
+		CREATE (p)-[:HAS_SIZE]->(s)
+		SET s.updated_at = $now`,
+		// The rest fold into the survivor's size of the same name, which
+		// takes over what referenced them.
+		`MATCH (:Product {id: $survivor})-[:HAS_SIZE]->(keep:Size), (:Product {id: $duplicate})-[:HAS_SIZE]->(s:Size {size: keep.size})
+		WITH keep, s, keep.stock + s.stock AS stock
+		SET keep.stock = stock,
+			keep.in_stock = stock > 0,
+			keep.variants = coalesce(keep.variants, []) + [v IN coalesce(s.variants, []) WHERE NOT v IN coalesce(keep.variants, [])],
+			keep.updated_at = $now`,
+		`MATCH (:Product {id: $survivor})-[:HAS_SIZE]->(keep:Size), (:Product {id: $duplicate})-[:HAS_SIZE]->(s:Size {size: keep.size})
+		MATCH (res:Reservation)-[old:HOLDS]->(s)
+		MERGE (res)-[h:HOLDS]->(keep)
+		ON CREATE SET h.quantity = 0
+		SET h.quantity = h.quantity + old.quantity
+		DELETE old`,
+		`MATCH (:Product {id: $survivor})-[:HAS_SIZE]->(keep:Size), (:Product {id: $duplicate})-[:HAS_SIZE]->(s:Size {size: keep.size})
+		MATCH (b:Bundle)-[old:CONTAINS]->(s)
+		MERGE (b)-[c:CONTAINS]->(keep)
+		ON CREATE SET c.quantity = 0
+		SET c.quantity = c.quantity + old.quantity
+		DELETE old`,
+		`MATCH (:Product {id: $survivor})-[:HAS_SIZE]->(keep:Size), (:Product {id: $duplicate})-[:HAS_SIZE]->(s:Size {size: keep.size})
+		MATCH (line:OrderLine)-[old:OF_SIZE]->(s)
+		CREATE (line)-[:OF_SIZE]->(keep)
+		DELETE old`,
+		`MATCH (:Product {id: $duplicate})-[:HAS_SIZE]->(s:Size)
+		DETACH DELETE s`,
+
+		`MATCH (p:Product {id: $survivor}), (:Product {id: $duplicate})-[:BELONGS_TO]->(c:Category)
+		WHERE NOT ` + d.exists("(p)-[:BELONGS_TO]->(:Category)") + `
+		MERGE (p)-[:BELONGS_TO]->(c)`,
+		`MATCH (p:Product {id: $survivor}), (:Product {id: $duplicate})-[h:HAS_TRANSLATION]->(t:Translation)
+		WHERE NOT ` + d.exists("(p)-[:HAS_TRANSLATION]->(:Translation {locale: t.locale})") + `
+		DELETE h
+		CREATE (p)-[:HAS_TRANSLATION]->(t)`,
+
+		// Collections with both keep the survivor's place and close the
+		// duplicate's gap; the others swap it for the survivor.
+		`MATCH (col:Collection)-[inc:INCLUDES]->(:Product {id: $duplicate})
+		WHERE ` + d.exists("(col)-[:INCLUDES]->(:Product {id: $survivor})") + `
+		WITH col, inc, inc.position AS pos
+		DELETE inc
+		SET col.updated_at = $now
+		WITH col, pos
+		MATCH (col)-[later:INCLUDES]->()
+		WHERE later.position > pos
+		SET later.position = later.position - 1`,
+		`MATCH (p:Product {id: $survivor}), (col:Collection)-[inc:INCLUDES]->(:Product {id: $duplicate})
+		CREATE (col)-[:INCLUDES {position: inc.position}]->(p)
+		DELETE inc
+		SET col.updated_at = $now`,
+
+		`MATCH (p:Product {id: $survivor}), (c:Customer)-[old:WATCHES_PRICE]->(:Product {id: $duplicate})
+		MERGE (c)-[w:WATCHES_PRICE]->(p)
+		ON CREATE SET w += properties(old)`,
+		`MATCH (c:Coupon)
+		WHERE $duplicate IN c.product_ids
+		SET c.product_ids = [id IN c.product_ids WHERE id <> $duplicate] +
+			CASE WHEN $survivor IN c.product_ids THEN [] ELSE [$survivor] END,
+			c.updated_at = $now`,
+	}
+
+	for _, rel := range []string{RelCrossSell, RelUpsell} {
+		statements = append(statements,
+			`MATCH (p:Product {id: $survivor}), (:Product {id: $duplicate})-[old:`+rel+`]->(other:Product)
+			WHERE other <> p
+			MERGE (p)-[n:`+rel+`]->(other)
+			ON CREATE SET n += properties(old)`,
+			`MATCH (p:Product {id: $survivor}), (other:Product)-[old:`+rel+`]->(:Product {id: $duplicate})
+			WHERE other <> p
+			MERGE (other)-[n:`+rel+`]->(p)
+			ON CREATE SET n += properties(old)`,
+		)
+	}
+
+	return append(statements, `
+		MATCH (d:Product {id: $duplicate})
+		OPTIONAL MATCH (d)-[:HAS_TRANSLATION]->(t:Translation)
+		WITH d, collect(t) AS translations
+		DETACH DELETE d
+		FOREACH (t IN translations | DELETE t)`)
+}
+
+// MergeProducts folds the duplicates into the survivor, as described on
+// MergeProductsRequest, and returns the survivor as merged. The products
+// must exist and belong to one tenant.
+func (r *ProductRepository) MergeProducts(ctx context.Context, survivorID string, duplicateIDs []string) (*pb.Product, error) {
+	if survivorID == "" {
+		return nil, fieldErrorf("survivor_id", "survivor id is required")
+	}
+	duplicates := slices.Compact(slices.Sorted(slices.Values(duplicateIDs)))
+	if len(duplicates) == 0 || duplicates[0] == "" {
+		return nil, fieldErrorf("duplicate_ids", "at least one duplicate id is required")
+	}
+	if slices.Contains(duplicates, survivorID) {
+		return nil, fieldErrorf("duplicate_ids", "the survivor can't be one of its duplicates")
+	}
+	now := time.Now().UnixMilli()
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		// Locked in id order so concurrent merges can't deadlock.
+		locked := map[string]*pb.Product{}
+		for _, id := range slices.Sorted(slices.Values(append([]string{survivorID}, duplicates...))) {
+			p, err := lockProduct(ctx, tx, id, now)
+			if err != nil {
+				return nil, err
+			}
+			if p == nil {
+				return nil, notFoundf("product %s not found", id)
+			}
+			locked[id] = p
+		}
+		before := locked[survivorID]
+		for _, id := range duplicates {
+			if locked[id].TenantId != before.TenantId {
+				return nil, fieldErrorf("duplicate_ids", "product %s belongs to another tenant", id)
+			}
+		}
+
+		for _, id := range duplicates {
+			params := map[string]any{"survivor": survivorID, "duplicate": id, "now": now}
+			for _, statement := range mergeStatements(r.dialect) {
+				if _, err := tx.Run(ctx, statement, params); err != nil {
+					return nil, err
+				}
+			}
+			if err := writeEvent(ctx, tx, events.New(events.ProductDeleted, id, nil)); err != nil {
+				return nil, err
+			}
+		}
+
+		merged, err := lockProduct(ctx, tx, survivorID, now)
+		if err != nil {
+			return nil, err
+		}
+		update := productUpdate{Product: merged, Changes: productChanges(before, merged)}
+		return merged, writeEvent(ctx, tx, events.New(events.ProductUpdated, merged.Id, update))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*pb.Product), nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is matched (via errors.Is) by every error reporting a missing
+// product, collection, bundle or other entity.
+var ErrNotFound = errors.New("not found")
+
+// ErrInsufficientStock reports a stock decrement that would go negative.
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// ErrInsufficientBalance reports a gift card redemption larger than the
+// card's balance.
+var ErrInsufficientBalance = errors.New("insufficient gift card balance")
+
+// ErrReservationClosed reports a commit or release of a reservation that
+// was already settled the other way, or has expired.
+var ErrReservationClosed = errors.New("reservation is closed")
+
+// ErrInvalidTransition reports an operation the entity's status doesn't
+// allow, such as completing a return that was never approved.
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// ErrCouponNotApplicable reports a coupon that can't be used on a cart or
+// redeemed: expired, used up or with no eligible lines.
+var ErrCouponNotApplicable = errors.New("coupon not applicable")
+
+// ErrUnsupported reports a feature the configured graph database lacks,
+// such as fulltext search on Memgraph.
+var ErrUnsupported = errors.New("not supported by this graph backend")
+
+// ErrNotConfigured reports a feature this server was started without the
+// settings for, such as webhooks without a secret key.
+var ErrNotConfigured = errors.New("not configured on this server")
+
+// ErrStreamInterrupted reports a streamed read that failed part way, after
+// results had been sent, and can only be retried from the start.
+var ErrStreamInterrupted = errors.New("stream interrupted")
+
+// ErrAlreadyExists is matched by every error reporting a unique key that
+// another entity already holds.
+var ErrAlreadyExists = errors.New("already exists")
+
+// ConflictError reports a unique product key, such as a SKU or barcode,
+// that already belongs to another product. Field names the key ("sku",
+// "slug", "gtin" or "external_id"). ProductID is empty if the owning size
+// is not attached to a product.
+type ConflictError struct {
+	Field     string
+	Value     string
+	ProductID string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %q already belongs to product %q", e.Field, e.Value, e.ProductID)
+}
+
+func (e *ConflictError) Is(target error) bool { return target == ErrAlreadyExists }
+
+type notFoundError struct{ msg string }
+
+func (e notFoundError) Error() string        { return e.msg }
+func (e notFoundError) Is(target error) bool { return target == ErrNotFound }
+
+func notFoundf(format string, args ...any) error {
+	return notFoundError{msg: fmt.Sprintf(format, args...)}
+}
+
+// FieldError is a validation failure on one request field. Field uses the
+// request's proto field path, e.g. "product.name".
+type FieldError struct {
+	Field       string
+	Description string
+}
+
+func (e *FieldError) Error() string { return e.Description }
+
+func fieldErrorf(field, format string, args ...any) error {
+	return &FieldError{Field: field, Description: fmt.Sprintf(format, args...)}
+}
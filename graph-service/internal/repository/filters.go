@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"strings"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+)
+
+// catalogFilterClause builds WHERE conditions on the product variable p for
+// the non-empty fields of f, adding their values to params. Archived
+// products are left out unless f includes them. It returns "true" when
+// nothing is filtered so callers can always AND it in.
+func catalogFilterClause(d Dialect, f *pb.CatalogFilter, params map[string]any) string {
+	var conds []string
+
+	if !f.GetIncludeArchive() {
+		conds = append(conds, "p:"+activeLabel)
+	}
+
+	if f.GetTenantId() != "" {
+		conds = append(conds, "p.tenant_id = $filter_tenant_id")
+		params["filter_tenant_id"] = f.GetTenantId()
+	}
+
+	var categoryConds []string
+	if f.GetMainCategory() != "" {
+		categoryConds = append(categoryConds, "main_category: $filter_main_category")
+		params["filter_main_category"] = f.GetMainCategory()
+	}
+	if f.GetSubcategory() != "" {
+		categoryConds = append(categoryConds, "subcategory: $filter_subcategory")
+		params["filter_subcategory"] = f.GetSubcategory()
+	}
+	if f.GetSpecificType() != "" {
+		categoryConds = append(categoryConds, "specific_type: $filter_specific_type")
+		params["filter_specific_type"] = f.GetSpecificType()
+	}
+	if len(categoryConds) > 0 {
+		conds = append(conds, d.exists("(p)-[:BELONGS_TO]->(:Category {"+
+			strings.Join(categoryConds, ", ")+"})"))
+	}
+
+	if len(conds) == 0 {
+		return "true"
+	}
+	return strings.Join(conds, " AND ")
+}
@@ -0,0 +1,370 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"slices"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const (
+	defaultHistoryDays  = 90
+	maxHistoryDays      = 730
+	maxForecastDays     = 365
+	maxForecastsPerCall = 1000
+
+	dayMillis = int64(24 * time.Hour / time.Millisecond)
+	dayLayout = "2006-01-02"
+)
+
+// soldOrderStatuses are the statuses of orders whose units were sold,
+// whether or not they came back since.
+var soldOrderStatuses = []string{orderPlaced, orderReturning, orderPartlyReturned, orderReturned}
+
+// startOfDay returns the UTC midnight at or before t, in Unix milliseconds.
+func startOfDay(t time.Time) int64 {
+	ms := t.UnixMilli()
+	return ms - ms%dayMillis
+}
+
+// ExportStockHistory returns the daily sales and closing stock of up to
+// limit SKUs after afterSKU, over the last days days ending today. Closing
+// stock comes from the stock.updated events the outbox keeps, which form
+// the stock ledger: a SKU whose stock never changed has had its current
+// stock throughout.
+func (r *ProductRepository) ExportStockHistory(ctx context.Context, tenantID string, days int, afterSKU string, limit int) ([]*pb.SkuStockHistory, error) {
+	if days <= 0 {
+		days = defaultHistoryDays
+	}
+	if days > maxHistoryDays {
+		return nil, fieldErrorf("days", "at most %d days of history can be exported", maxHistoryDays)
+	}
+	from := startOfDay(time.Now()) - int64(days-1)*dayMillis
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (p:Product)-[:HAS_SIZE]->(s:Size)
+			WHERE ($tenant_id = '' OR p.tenant_id = $tenant_id) AND s.sku > $after
+			WITH p, s
+			ORDER BY s.sku
+			LIMIT $limit
+			OPTIONAL MATCH (s)<-[:OF_SIZE]-(l:OrderLine)<-[:HAS_LINE]-(o:Order)
+			WHERE o.created_at >= $from AND o.status IN $sold
+			WITH p, s, collect([o.created_at, l.quantity]) AS sales
+			OPTIONAL MATCH (e:Event {type: $stock_updated, sku: s.sku})
+			WHERE e.occurred_at >= $from
+			WITH p, s, sales, collect([e.occurred_at, e.payload]) AS changes
+			OPTIONAL MATCH (b:Event {type: $stock_updated, sku: s.sku})
+			WHERE b.occurred_at < $from
+			WITH p, s, sales, changes, b
+			ORDER BY b.occurred_at DESC
+			WITH p, s, sales, changes, head(collect(b.payload)) AS opening
+			RETURN s.sku AS sku, p.id AS product_id, s.stock AS stock, sales, changes, opening
+			ORDER BY sku
+		`, map[string]any{
+			"tenant_id":     tenantID,
+			"after":         afterSKU,
+			"limit":         limit,
+			"from":          from,
+			"sold":          soldOrderStatuses,
+			"stock_updated": events.StockUpdated,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		histories := []*pb.SkuStockHistory{}
+		for res.Next(ctx) {
+			row := res.Record().AsMap()
+			histories = append(histories, stockHistory(row, from, days))
+		}
+		return histories, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]*pb.SkuStockHistory), nil
+}
+
+// stockHistory lays a SKU's sales and stock changes out by day.
+func stockHistory(row map[string]any, from int64, days int) *pb.SkuStockHistory {
+	h := &pb.SkuStockHistory{
+		Sku:       getString(row, "sku"),
+		ProductId: getString(row, "product_id"),
+		Days:      make([]*pb.StockHistoryDay, days),
+	}
+	for i := range h.Days {
+		h.Days[i] = &pb.StockHistoryDay{
+			Day:          time.UnixMilli(from + int64(i)*dayMillis).UTC().Format(dayLayout),
+			ClosingStock: -1,
+		}
+	}
+	day := func(at int64) int {
+		return min(int((at-from)/dayMillis), days-1)
+	}
+
+	sales, _ := row["sales"].([]any)
+	for _, sale := range sales {
+		pair, _ := sale.([]any)
+		at, ok := pair[0].(int64)
+		quantity, _ := pair[1].(int64)
+		if ok {
+			h.Days[day(at)].UnitsSold += int32(quantity)
+		}
+	}
+
+	type change struct {
+		at    int64
+		stock int32
+	}
+	var changes []change
+	raw, _ := row["changes"].([]any)
+	for _, c := range raw {
+		pair, _ := c.([]any)
+		at, ok := pair[0].(int64)
+		payload, _ := pair[1].(string)
+		if stock, parsed := eventStock(payload); ok && parsed {
+			changes = append(changes, change{at, stock})
+		}
+	}
+	slices.SortFunc(changes, func(a, b change) int { return int(a.at - b.at) })
+
+	// Without an earlier ledger entry the stock before the first change
+	// is unknown, unless nothing ever changed it
+	stock := int32(-1)
+	if opening, ok := eventStock(getString(row, "opening")); ok {
+		stock = opening
+	} else if len(changes) == 0 {
+		stock = int32(getInt64(row, "stock"))
+	}
+	next := 0
+	for i, d := range h.Days {
+		for next < len(changes) && day(changes[next].at) <= i {
+			stock = changes[next].stock
+			next++
+		}
+		d.ClosingStock = stock
+	}
+	return h
+}
+
+// eventStock reads the stock a stock.updated event's payload reports.
+func eventStock(payload string) (int32, bool) {
+	var ev struct {
+		Data struct {
+			Stock *int32 `json:"stock"`
+		} `json:"data"`
+	}
+	if payload == "" || json.Unmarshal([]byte(payload), &ev) != nil || ev.Data.Stock == nil {
+		return 0, false
+	}
+	return *ev.Data.Stock, true
+}
+
+// ImportForecast replaces the demand forecasts of the SKUs given and
+// returns how many it stored and the SKUs no size has.
+func (r *ProductRepository) ImportForecast(ctx context.Context, model string, forecasts []*pb.SkuForecast) (int, []string, error) {
+	if len(forecasts) == 0 {
+		return 0, nil, fieldErrorf("forecasts", "at least one forecast is required")
+	}
+	if len(forecasts) > maxForecastsPerCall {
+		return 0, nil, fieldErrorf("forecasts", "at most %d forecasts can be imported at once", maxForecastsPerCall)
+	}
+
+	params := make([]map[string]any, 0, len(forecasts))
+	for i, f := range forecasts {
+		if f.Sku == "" {
+			return 0, nil, fieldErrorf("forecasts", "forecast %d has no sku", i)
+		}
+		start, err := time.Parse(dayLayout, f.StartDay)
+		if err != nil {
+			return 0, nil, fieldErrorf("forecasts", "forecast for %s: start_day must be YYYY-MM-DD", f.Sku)
+		}
+		if len(f.DailyDemand) == 0 || len(f.DailyDemand) > maxForecastDays {
+			return 0, nil, fieldErrorf("forecasts", "forecast for %s: daily_demand must have 1 to %d days", f.Sku, maxForecastDays)
+		}
+		for _, units := range f.DailyDemand {
+			if units < 0 || math.IsNaN(units) || math.IsInf(units, 0) {
+				return 0, nil, fieldErrorf("forecasts", "forecast for %s: daily_demand must be non-negative numbers", f.Sku)
+			}
+		}
+		params = append(params, map[string]any{
+			"sku":    f.Sku,
+			"start":  start.UnixMilli(),
+			"demand": f.DailyDemand,
+		})
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			UNWIND $forecasts AS f
+			MATCH (s:Size {sku: f.sku})
+			SET s.forecast_start = f.start,
+				s.forecast_demand = f.demand,
+				s.forecast_model = $model,
+				s.forecast_imported_at = $now
+			RETURN s.sku AS sku
+		`, map[string]any{
+			"forecasts": params,
+			"model":     model,
+			"now":       time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		stored := map[string]bool{}
+		for res.Next(ctx) {
+			sku, _ := res.Record().Values[0].(string)
+			stored[sku] = true
+		}
+		return stored, res.Err()
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	stored := result.(map[string]bool)
+	var unknown []string
+	for _, f := range forecasts {
+		if !stored[f.Sku] && !slices.Contains(unknown, f.Sku) {
+			unknown = append(unknown, f.Sku)
+		}
+	}
+	return len(stored), unknown, nil
+}
+
+// StockProjection is a size's stock and when its forecast has it run out.
+type StockProjection struct {
+	SKU       string
+	ProductID string
+	Stock     int32
+	// StockoutAt is the UTC day, in Unix milliseconds, the forecast demand
+	// first reaches the stock; 0 when the forecast doesn't reach it or
+	// there is none.
+	StockoutAt int64
+	// Model is the forecast's model.
+	Model string
+	// Alerted reports that stock.low was raised and not yet cleared.
+	Alerted bool
+}
+
+// projectStockout walks the forecast from today, starting with today's
+// demand, and returns the day cumulative demand reaches stock.
+func projectStockout(stock int64, start int64, demand []float64, now time.Time) int64 {
+	today := startOfDay(now)
+	if stock <= 0 {
+		return today
+	}
+	var total float64
+	for i := max(int((today-start)/dayMillis), 0); i < len(demand); i++ {
+		total += demand[i]
+		if total >= float64(stock) {
+			return start + int64(i)*dayMillis
+		}
+	}
+	return 0
+}
+
+// ListStockProjections returns up to limit sizes after afterSKU, in SKU
+// order, that may need a low-stock alert raised or cleared: those at or
+// below threshold, those with a forecast, and those alerted.
+func (r *ProductRepository) ListStockProjections(ctx context.Context, threshold int32, afterSKU string, limit int) ([]StockProjection, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (p:Product)-[:HAS_SIZE]->(s:Size)
+			WHERE s.sku > $after
+				AND (s.stock <= $threshold OR s.forecast_demand IS NOT NULL OR s.low_stock_alerted_at IS NOT NULL)
+			RETURN s.sku AS sku, p.id AS product_id, s.stock AS stock,
+				s.forecast_start AS forecast_start, s.forecast_demand AS forecast_demand,
+				s.forecast_model AS forecast_model, s.low_stock_alerted_at IS NOT NULL AS alerted
+			ORDER BY sku
+			LIMIT $limit
+		`, map[string]any{
+			"after":     afterSKU,
+			"threshold": threshold,
+			"limit":     limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		var projections []StockProjection
+		for res.Next(ctx) {
+			row := res.Record().AsMap()
+			p := StockProjection{
+				SKU:       getString(row, "sku"),
+				ProductID: getString(row, "product_id"),
+				Stock:     int32(getInt64(row, "stock")),
+				Model:     getString(row, "forecast_model"),
+			}
+			p.Alerted, _ = row["alerted"].(bool)
+			if raw, ok := row["forecast_demand"].([]any); ok {
+				demand := make([]float64, 0, len(raw))
+				for _, units := range raw {
+					f, _ := units.(float64)
+					demand = append(demand, f)
+				}
+				p.StockoutAt = projectStockout(int64(p.Stock), getInt64(row, "forecast_start"), demand, now)
+			}
+			projections = append(projections, p)
+		}
+		return projections, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	projections, _ := result.([]StockProjection)
+	return projections, nil
+}
+
+// SetLowStockAlert raises stock.low for the size, or clears its alert so
+// the next shortage raises another.
+func (r *ProductRepository) SetLowStockAlert(ctx context.Context, p StockProjection, low bool, threshold int32) error {
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		if !low {
+			_, err := tx.Run(ctx, `
+				MATCH (s:Size {sku: $sku})
+				REMOVE s.low_stock_alerted_at
+			`, map[string]any{"sku": p.SKU})
+			return nil, err
+		}
+
+		_, err := tx.Run(ctx, `
+			MATCH (s:Size {sku: $sku})
+			SET s.low_stock_alerted_at = $now
+		`, map[string]any{"sku": p.SKU, "now": time.Now().UnixMilli()})
+		if err != nil {
+			return nil, err
+		}
+		data := map[string]any{"stock": p.Stock, "threshold": threshold}
+		if p.StockoutAt > 0 {
+			data["projected_stockout_at"] = p.StockoutAt
+			data["forecast_model"] = p.Model
+		}
+		ev := events.New(events.StockLow, p.ProductID, data)
+		ev.SKU = p.SKU
+		return nil, writeEvent(ctx, tx, ev)
+	})
+	return err
+}
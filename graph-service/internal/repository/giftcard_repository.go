@@ -0,0 +1,412 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Gift card transaction kinds as stored on GiftCardTransaction nodes.
+const (
+	giftCardIssue      = "issue"
+	giftCardRedemption = "redemption"
+	giftCardRefund     = "refund"
+)
+
+var giftCardKinds = map[string]pb.GiftCardTransactionKind{
+	giftCardIssue:      pb.GiftCardTransactionKind_ISSUE,
+	giftCardRedemption: pb.GiftCardTransactionKind_REDEMPTION,
+	giftCardRefund:     pb.GiftCardTransactionKind_REFUND,
+}
+
+const (
+	giftCardCodeLen    = 16
+	maxGiftCardCodeLen = 64
+	// Generated codes leave out 0/O and 1/I, which shoppers mistype
+	giftCardAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+var giftCardCodePattern = regexp.MustCompile(`^[A-Z0-9-]+$`)
+
+var errGiftCardNotFound = notFoundf("gift card not found")
+
+func newGiftCardCode() (string, error) {
+	code := make([]byte, giftCardCodeLen)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(giftCardAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = giftCardAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+func normalizeGiftCardCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IssueGiftCard stores a new card with its initial balance and fills in
+// its id, code, balance and timestamps.
+func (r *ProductRepository) IssueGiftCard(ctx context.Context, card *pb.GiftCard) error {
+	card.Code = normalizeGiftCardCode(card.Code)
+	if card.Code == "" {
+		code, err := newGiftCardCode()
+		if err != nil {
+			return err
+		}
+		card.Code = code
+	}
+	switch {
+	case len(card.Code) > maxGiftCardCodeLen:
+		return &LimitError{Field: "card.code", Limit: maxGiftCardCodeLen, Actual: len(card.Code)}
+	case !giftCardCodePattern.MatchString(card.Code):
+		return fieldErrorf("card.code", "code may only contain letters, digits and -")
+	case cents(card.InitialBalance) <= 0:
+		return fieldErrorf("card.initial_balance", "initial balance must be positive")
+	}
+
+	now := time.Now().UnixMilli()
+	card.Id = idOrNew(card.Id)
+	card.InitialBalance = roundCents(card.InitialBalance)
+	card.Balance = card.InitialBalance
+	card.CreatedAt, card.UpdatedAt = now, now
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			CREATE (c:GiftCard {
+				id: $id,
+				code: $code,
+				tenant_id: $tenant_id,
+				currency: $currency,
+				initial_balance: $balance,
+				balance: $balance,
+				expires_at: $expires_at,
+				created_at: $now,
+				updated_at: $now
+			})
+			CREATE (:GiftCardTransaction {
+				id: $transaction_id,
+				kind: $kind,
+				amount: $balance,
+				balance: $balance,
+				order_id: '',
+				reference: 'issue',
+				created_at: $now
+			})-[:ON_CARD]->(c)
+		`, map[string]any{
+			"id":             card.Id,
+			"code":           card.Code,
+			"tenant_id":      card.TenantId,
+			"currency":       card.Currency,
+			"balance":        card.Balance,
+			"expires_at":     card.ExpiresAt,
+			"transaction_id": newID(),
+			"kind":           giftCardIssue,
+			"now":            now,
+		})
+		return nil, err
+	})
+	if isConstraintViolation(err) {
+		return fieldErrorf("card.code", "a gift card with this code or id already exists")
+	}
+	return err
+}
+
+// GetGiftCard returns the card with its transactions, newest first.
+func (r *ProductRepository) GetGiftCard(ctx context.Context, code string) (*pb.GiftCard, []*pb.GiftCardTransaction, error) {
+	code = normalizeGiftCardCode(code)
+	if code == "" {
+		return nil, nil, fieldErrorf("code", "gift card code is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	var transactions []*pb.GiftCardTransaction
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		card, err := readGiftCard(ctx, tx, code)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := tx.Run(ctx, `
+			MATCH (t:GiftCardTransaction)-[:ON_CARD]->(:GiftCard {code: $code})
+			RETURN t
+			ORDER BY t.created_at DESC
+		`, map[string]any{"code": code})
+		if err != nil {
+			return nil, err
+		}
+		records, err := res.Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		transactions = make([]*pb.GiftCardTransaction, 0, len(records))
+		for _, record := range records {
+			node, _ := record.Values[0].(neo4j.Node)
+			transactions = append(transactions, giftCardTransactionFromNode(node))
+		}
+		return card, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result.(*pb.GiftCard), transactions, nil
+}
+
+// RedeemGiftCard takes amount, in currency, off the card's balance for the
+// order and records it on the order. A balance short of amount fails with
+// ErrInsufficientBalance unless partial is set, when the card gives what
+// it has. The card is locked while its balance is read and decremented,
+// so concurrent redemptions can't overdraw it. Redeeming for the same
+// order twice returns the first redemption.
+func (r *ProductRepository) RedeemGiftCard(ctx context.Context, code, orderID, currency string, amount float64, partial bool) (*pb.GiftCard, *pb.GiftCardTransaction, error) {
+	code = normalizeGiftCardCode(code)
+	switch {
+	case code == "":
+		return nil, nil, fieldErrorf("code", "gift card code is required")
+	case orderID == "":
+		return nil, nil, fieldErrorf("order_id", "order id is required")
+	case cents(amount) <= 0:
+		return nil, nil, fieldErrorf("amount", "amount must be positive")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	var transaction *pb.GiftCardTransaction
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		now := time.Now().UnixMilli()
+		card, err := lockGiftCard(ctx, tx, code, now)
+		if err != nil {
+			return nil, err
+		}
+
+		reference := "order-" + orderID
+		transaction, err = findGiftCardTransaction(ctx, tx, card.Id, reference)
+		if err != nil || transaction != nil {
+			return card, err
+		}
+
+		switch {
+		case card.ExpiresAt > 0 && now >= card.ExpiresAt:
+			return nil, fmt.Errorf("%w: gift card has expired", ErrInsufficientBalance)
+		case card.Currency != currency:
+			return nil, fieldErrorf("currency", "gift card is in %s, not %s", card.Currency, currency)
+		}
+		take := cents(amount)
+		if balance := cents(card.Balance); balance < take {
+			if !partial || balance == 0 {
+				return nil, fmt.Errorf("%w: %.2f %s left", ErrInsufficientBalance, card.Balance, card.Currency)
+			}
+			take = balance
+		}
+
+		transaction, err = writeGiftCardTransaction(ctx, tx, card, giftCardRedemption, -take, orderID, reference, now)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.Run(ctx, `
+			MATCH (o:Order {id: $order_id})
+			SET o.gift_card_id = $card_id, o.gift_card_amount = $amount
+		`, map[string]any{"order_id": orderID, "card_id": card.Id, "amount": float64(take) / 100})
+		if err != nil {
+			return nil, err
+		}
+		return card, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result.(*pb.GiftCard), transaction, nil
+}
+
+// RefundGiftCard credits back to the card an order was paid with up to
+// amount of what was redeemed for it and not yet refunded, or all of it
+// when amount is 0. reference keys the refund, so retrying it credits
+// once. Orders not paid by gift card are left alone.
+func (r *ProductRepository) RefundGiftCard(ctx context.Context, orderID, reference string, amount float64) error {
+	if orderID == "" || reference == "" {
+		return fieldErrorf("order_id", "order id and reference are required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (:GiftCardTransaction {order_id: $order_id, kind: $redemption})-[:ON_CARD]->(c:GiftCard)
+			RETURN DISTINCT c.code AS code
+		`, map[string]any{"order_id": orderID, "redemption": giftCardRedemption})
+		if err != nil {
+			return nil, err
+		}
+		records, err := res.Collect(ctx)
+		if err != nil || len(records) == 0 {
+			return nil, err
+		}
+
+		now := time.Now().UnixMilli()
+		card, err := lockGiftCard(ctx, tx, getString(records[0].AsMap(), "code"), now)
+		if err != nil {
+			return nil, err
+		}
+		done, err := findGiftCardTransaction(ctx, tx, card.Id, reference)
+		if err != nil || done != nil {
+			return nil, err
+		}
+
+		// Redemptions are negative and refunds positive, so the sum is
+		// what the order still holds of the card, negated
+		res, err = tx.Run(ctx, `
+			MATCH (t:GiftCardTransaction {order_id: $order_id})-[:ON_CARD]->(:GiftCard {id: $card_id})
+			RETURN sum(t.amount) AS net
+		`, map[string]any{"order_id": orderID, "card_id": card.Id})
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		net, _ := record.AsMap()["net"].(float64)
+		credit := -cents(net)
+		if amount > 0 {
+			credit = min(credit, cents(amount))
+		}
+		if credit <= 0 {
+			return nil, nil
+		}
+
+		_, err = writeGiftCardTransaction(ctx, tx, card, giftCardRefund, credit, orderID, reference, now)
+		return nil, err
+	})
+
+	return err
+}
+
+// lockGiftCard write-locks the card and returns it as of the lock.
+func lockGiftCard(ctx context.Context, tx neo4j.ManagedTransaction, code string, now int64) (*pb.GiftCard, error) {
+	_, err := tx.Run(ctx, `
+		MATCH (c:GiftCard {code: $code})
+		SET c.updated_at = $now
+	`, map[string]any{"code": code, "now": now})
+	if err != nil {
+		return nil, err
+	}
+	return readGiftCard(ctx, tx, code)
+}
+
+func findGiftCardTransaction(ctx context.Context, tx neo4j.ManagedTransaction, cardID, reference string) (*pb.GiftCardTransaction, error) {
+	res, err := tx.Run(ctx, `
+		MATCH (t:GiftCardTransaction {reference: $reference})-[:ON_CARD]->(:GiftCard {id: $card_id})
+		RETURN t
+	`, map[string]any{"card_id": cardID, "reference": reference})
+	if err != nil {
+		return nil, err
+	}
+	if !res.Next(ctx) {
+		return nil, res.Err()
+	}
+	node, _ := res.Record().Values[0].(neo4j.Node)
+	return giftCardTransactionFromNode(node), nil
+}
+
+// writeGiftCardTransaction adjusts the card's balance by amount, in minor
+// units, and records the transaction. card is updated to match.
+func writeGiftCardTransaction(ctx context.Context, tx neo4j.ManagedTransaction, card *pb.GiftCard, kind string, amount int64, orderID, reference string, now int64) (*pb.GiftCardTransaction, error) {
+	card.Balance = float64(cents(card.Balance)+amount) / 100
+	card.UpdatedAt = now
+	t := &pb.GiftCardTransaction{
+		Id:        newID(),
+		Kind:      giftCardKinds[kind],
+		Amount:    float64(amount) / 100,
+		Balance:   card.Balance,
+		OrderId:   orderID,
+		CreatedAt: now,
+	}
+
+	_, err := tx.Run(ctx, `
+		MATCH (c:GiftCard {id: $card_id})
+		SET c.balance = $balance
+		CREATE (:GiftCardTransaction {
+			id: $id,
+			kind: $kind,
+			amount: $amount,
+			balance: $balance,
+			order_id: $order_id,
+			reference: $reference,
+			created_at: $now
+		})-[:ON_CARD]->(c)
+	`, map[string]any{
+		"card_id":   card.Id,
+		"balance":   card.Balance,
+		"id":        t.Id,
+		"kind":      kind,
+		"amount":    t.Amount,
+		"order_id":  orderID,
+		"reference": reference,
+		"now":       now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func readGiftCard(ctx context.Context, tx neo4j.ManagedTransaction, code string) (*pb.GiftCard, error) {
+	res, err := tx.Run(ctx, `
+		MATCH (c:GiftCard {code: $code})
+		RETURN c
+	`, map[string]any{"code": code})
+	if err != nil {
+		return nil, err
+	}
+	if !res.Next(ctx) {
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errGiftCardNotFound
+	}
+
+	node, _ := res.Record().Values[0].(neo4j.Node)
+	props := node.Props
+	card := &pb.GiftCard{
+		Id:        getString(props, "id"),
+		Code:      getString(props, "code"),
+		TenantId:  getString(props, "tenant_id"),
+		Currency:  getString(props, "currency"),
+		ExpiresAt: getInt64(props, "expires_at"),
+		CreatedAt: getInt64(props, "created_at"),
+		UpdatedAt: getInt64(props, "updated_at"),
+	}
+	card.InitialBalance, _ = props["initial_balance"].(float64)
+	card.Balance, _ = props["balance"].(float64)
+	return card, nil
+}
+
+func giftCardTransactionFromNode(node neo4j.Node) *pb.GiftCardTransaction {
+	props := node.Props
+	t := &pb.GiftCardTransaction{
+		Id:        getString(props, "id"),
+		Kind:      giftCardKinds[getString(props, "kind")],
+		OrderId:   getString(props, "order_id"),
+		CreatedAt: getInt64(props, "created_at"),
+	}
+	t.Amount, _ = props["amount"].(float64)
+	t.Balance, _ = props["balance"].(float64)
+	return t
+}
@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/slug"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Product identifier kinds. Besides the id, a product can carry a URL
+// slug, a GTIN barcode (UPC, EAN) and the id it has in an external ERP.
+// Each alternate is optional and unique across the catalog when set; it is
+// stored as a product property (or column) of the same name.
+const (
+	IdentifierID         = "id"
+	IdentifierSlug       = "slug"
+	IdentifierGTIN       = "gtin"
+	IdentifierExternalID = "external_id"
+)
+
+// identifierKinds is the order ResolveProduct tries when not told which
+// kind a value is.
+var identifierKinds = []string{IdentifierID, IdentifierSlug, IdentifierGTIN, IdentifierExternalID}
+
+const (
+	maxSlugLen       = 128
+	maxExternalIDLen = 128
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// checkIdentifiers validates the product's alternate identifiers and
+// normalizes its GTIN to 14 digits, so a UPC-A scanned as 12 digits finds
+// the product stored under its 13-digit EAN.
+func checkIdentifiers(p *pb.Product) error {
+	if p.Slug != "" && (len(p.Slug) > maxSlugLen || !slugPattern.MatchString(p.Slug)) {
+		return fieldErrorf("product.slug", "slug must be lowercase letters and digits separated by single hyphens, at most %d characters", maxSlugLen)
+	}
+	if p.Gtin != "" {
+		gtin, ok := normalizeGTIN(p.Gtin)
+		if !ok {
+			return fieldErrorf("product.gtin", "gtin must be 8, 12, 13 or 14 digits with a valid check digit")
+		}
+		p.Gtin = gtin
+	}
+	if len(p.ExternalId) > maxExternalIDLen {
+		return fieldErrorf("product.external_id", "external id is longer than %d characters", maxExternalIDLen)
+	}
+	return nil
+}
+
+// normalizeGTIN zero-pads a GTIN-8, -12, -13 or -14 to 14 digits after
+// verifying its check digit.
+func normalizeGTIN(gtin string) (string, bool) {
+	switch len(gtin) {
+	case 8, 12, 13, 14:
+	default:
+		return "", false
+	}
+	for _, c := range gtin {
+		if c < '0' || c > '9' {
+			return "", false
+		}
+	}
+
+	// Weights alternate 3, 1, ... from the digit left of the check digit
+	sum := 0
+	for i := len(gtin) - 2; i >= 0; i-- {
+		d := int(gtin[i] - '0')
+		if (len(gtin)-2-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	if (10-sum%10)%10 != int(gtin[len(gtin)-1]-'0') {
+		return "", false
+	}
+	return strings.Repeat("0", 14-len(gtin)) + gtin, true
+}
+
+// normalizeIdentifier puts a lookup value in the form identifiers of kind
+// are stored in. ok is false if no product can have it.
+func normalizeIdentifier(kind, value string) (string, bool) {
+	if kind == IdentifierGTIN {
+		return normalizeGTIN(value)
+	}
+	return value, true
+}
+
+// Attempts at creating a product whose generated slug keeps being taken
+// by concurrent writes.
+const maxSlugAttempts = 3
+
+// baseSlug is the slug a product gets before collision suffixes.
+func baseSlug(p *pb.Product) string {
+	if base := slug.Make(p.Name, p.Brand); base != "" {
+		return base
+	}
+	return "product"
+}
+
+// nextFreeSlug returns base, or base with the lowest free numeric suffix.
+func nextFreeSlug(ctx context.Context, tx neo4j.ManagedTransaction, base string) (string, error) {
+	res, err := tx.Run(ctx, `
+		MATCH (p:Product)
+		WHERE p.slug = $base OR p.slug STARTS WITH $prefix
+		RETURN p.slug AS slug
+	`, map[string]any{"base": base, "prefix": base + "-"})
+	if err != nil {
+		return "", err
+	}
+
+	var taken []string
+	for res.Next(ctx) {
+		taken = append(taken, getString(res.Record().AsMap(), "slug"))
+	}
+	if err := res.Err(); err != nil {
+		return "", err
+	}
+	return slug.Next(base, taken), nil
+}
+
+// slugBackfillBatch is how many products backfillSlugs updates per
+// transaction.
+const slugBackfillBatch = 100
+
+// backfillSlugs generates a slug for every product without one, a batch
+// per transaction.
+func (r *ProductRepository) backfillSlugs(ctx context.Context) error {
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	for {
+		result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			res, err := tx.Run(ctx, `
+				MATCH (p:Product)
+				WHERE p.slug IS NULL
+				RETURN p.id AS id, p.name AS name, p.brand AS brand
+				LIMIT $limit
+			`, map[string]any{"limit": slugBackfillBatch})
+			if err != nil {
+				return nil, err
+			}
+			records, err := res.Collect(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, record := range records {
+				row := record.AsMap()
+				handle, err := nextFreeSlug(ctx, tx, baseSlug(&pb.Product{
+					Name:  getString(row, "name"),
+					Brand: getString(row, "brand"),
+				}))
+				if err != nil {
+					return nil, err
+				}
+
+				_, err = tx.Run(ctx, `
+					MATCH (p:Product {id: $id})
+					SET p.slug = $slug
+				`, map[string]any{"id": getString(row, "id"), "slug": handle})
+				if err != nil {
+					return nil, err
+				}
+			}
+			return len(records), nil
+		})
+		if err != nil {
+			return err
+		}
+		if result.(int) < slugBackfillBatch {
+			return nil
+		}
+	}
+}
+
+// nullIfEmpty maps an unset identifier to null, which uniqueness
+// constraints ignore.
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// findIdentifierConflict returns a ConflictError for the first of p's
+// alternate identifiers that another product already has, or nil.
+func findIdentifierConflict(ctx context.Context, tx neo4j.ManagedTransaction, p *pb.Product) error {
+	if p.Slug == "" && p.Gtin == "" && p.ExternalId == "" {
+		return nil
+	}
+
+	res, err := tx.Run(ctx, `
+		MATCH (p:Product)
+		WHERE p.id <> $id
+			AND (p.slug = $slug OR p.gtin = $gtin OR p.external_id = $external_id)
+		RETURN p.id AS product_id, p.slug AS slug, p.gtin AS gtin, p.external_id AS external_id
+		LIMIT 1
+	`, map[string]any{
+		"id":          p.Id,
+		"slug":        nullIfEmpty(p.Slug),
+		"gtin":        nullIfEmpty(p.Gtin),
+		"external_id": nullIfEmpty(p.ExternalId),
+	})
+	if err != nil {
+		return err
+	}
+
+	if !res.Next(ctx) {
+		return res.Err()
+	}
+	return identifierConflict(p, res.Record().AsMap())
+}
+
+// identifierConflict names which of p's identifiers the conflicting
+// product row holds.
+func identifierConflict(p *pb.Product, row map[string]any) error {
+	for _, id := range []struct{ field, value string }{
+		{IdentifierSlug, p.Slug},
+		{IdentifierGTIN, p.Gtin},
+		{IdentifierExternalID, p.ExternalId},
+	} {
+		if id.value != "" && getString(row, id.field) == id.value {
+			return &ConflictError{Field: id.field, Value: id.value, ProductID: getString(row, "product_id")}
+		}
+	}
+	return nil
+}
+
+// findProductConflict checks both the sizes and the identifiers of a new
+// product.
+func findProductConflict(ctx context.Context, tx neo4j.ManagedTransaction, p *pb.Product) error {
+	if err := findSkuConflict(ctx, tx, sizeSkus(p.Sizes)); err != nil {
+		return err
+	}
+	return findIdentifierConflict(ctx, tx, p)
+}
+
+// ResolveProduct finds a product by an identifier of the given kind, or
+// by the first kind in identifierKinds that matches when kind is empty.
+// It returns the product and the kind that matched.
+func (r *ProductRepository) ResolveProduct(ctx context.Context, kind, value string) (*pb.Product, string, error) {
+	kinds, err := resolveKinds(kind, value)
+	if err != nil {
+		return nil, "", err
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	type match struct {
+		product *pb.Product
+		kind    string
+	}
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		for _, kind := range kinds {
+			lookup, ok := normalizeIdentifier(kind, value)
+			if !ok {
+				continue
+			}
+
+			// kind comes from identifierKinds, never from the caller
+			res, err := tx.Run(ctx, `
+				MATCH (p:Product)
+				WHERE p.`+kind+` = $value
+				WITH p
+				LIMIT 1
+				OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+				OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+				RETURN p, c, collect(s) as sizes
+			`, map[string]any{"value": lookup})
+			if err != nil {
+				return nil, err
+			}
+			if res.Next(ctx) {
+				return match{productFromRecord(res.Record()), kind}, nil
+			}
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+		}
+		return nil, notFoundf("no product has %s %q", describeKinds(kind), value)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	m := result.(match)
+	return m.product, m.kind, nil
+}
+
+// resolveKinds validates a ResolveProduct request and returns the kinds
+// to try, in order.
+func resolveKinds(kind, value string) ([]string, error) {
+	if value == "" {
+		return nil, fieldErrorf("value", "identifier value is required")
+	}
+	if kind == "" {
+		return identifierKinds, nil
+	}
+	for _, k := range identifierKinds {
+		if k == kind {
+			return []string{k}, nil
+		}
+	}
+	return nil, fieldErrorf("type", "unknown identifier type %q", kind)
+}
+
+func describeKinds(kind string) string {
+	if kind == "" {
+		return "identifier"
+	}
+	return kind
+}
@@ -0,0 +1,17 @@
+package repository
+
+import "github.com/google/uuid"
+
+// newID returns a random id. Ids are generated here rather than with
+// randomUUID(), which Memgraph lacks.
+func newID() string {
+	return uuid.NewString()
+}
+
+// idOrNew returns id, or a new one when it is empty.
+func idOrNew(id string) string {
+	if id == "" {
+		return newID()
+	}
+	return id
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const (
+	maxStockCountsPerCall = 1000
+	stocktakeReason       = "stocktake"
+)
+
+// InventoryLevel is a size's stock. Stock is what can still be sold; Held
+// is what open reservations have taken off it but is still on the shelf.
+type InventoryLevel struct {
+	SKU       string
+	ProductID string
+	Stock     int32
+	Held      int32
+	UpdatedAt int64
+}
+
+// StockCount is the quantity of a SKU found on the shelf in a stocktake.
+type StockCount struct {
+	SKU     string
+	Counted int32
+}
+
+// StockDiscrepancy is a SKU whose count differs from its stock on hand,
+// Stock plus Held. Stock is its stock after the adjustment.
+type StockDiscrepancy struct {
+	SKU       string
+	ProductID string
+	Expected  int32
+	Counted   int32
+	Stock     int32
+}
+
+// Reconciliation reports a stocktake: the SKUs whose counts differed, how
+// many of them were adjusted, and the SKUs no size has.
+type Reconciliation struct {
+	Discrepancies []StockDiscrepancy
+	Adjusted      int
+	UnknownSKUs   []string
+}
+
+// SnapshotInventory returns the stock of up to limit SKUs after afterSKU,
+// in SKU order.
+func (r *ProductRepository) SnapshotInventory(ctx context.Context, tenantID, afterSKU string, limit int) ([]InventoryLevel, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (p:Product)-[:HAS_SIZE]->(s:Size)
+			WHERE ($tenant_id = '' OR p.tenant_id = $tenant_id) AND s.sku > $after
+			WITH p, s
+			ORDER BY s.sku
+			LIMIT $limit
+			`+heldQuantity+`
+			RETURN s.sku AS sku, p.id AS product_id, s.stock AS stock, held, s.updated_at AS updated_at
+			ORDER BY sku
+		`, map[string]any{
+			"tenant_id": tenantID,
+			"after":     afterSKU,
+			"limit":     limit,
+			"reserved":  reservationReserved,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		levels := []InventoryLevel{}
+		for res.Next(ctx) {
+			row := res.Record().AsMap()
+			levels = append(levels, InventoryLevel{
+				SKU:       getString(row, "sku"),
+				ProductID: getString(row, "product_id"),
+				Stock:     int32(getInt64(row, "stock")),
+				Held:      int32(getInt64(row, "held")),
+				UpdatedAt: getInt64(row, "updated_at"),
+			})
+		}
+		return levels, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]InventoryLevel), nil
+}
+
+// heldQuantity adds a held column to the rows of s: the quantity open
+// reservations hold of it.
+const heldQuantity = `
+	OPTIONAL MATCH (r:Reservation {status: $reserved})-[h:HOLDS]->(s)
+	WITH p, s, coalesce(sum(h.quantity), 0) AS held`
+
+// ReconcileInventory compares a stocktake's counts with the stock on hand
+// of their SKUs, stock plus held, and, unless dryRun, sets the stock of
+// every SKU that differs so that its stock on hand matches the count,
+// writing a stock.updated ledger entry for each with the count and
+// reason. Held units stay held: a SKU counted below what is held is left
+// with no stock. All adjustments are made in one transaction.
+func (r *ProductRepository) ReconcileInventory(ctx context.Context, counts []StockCount, reason string, dryRun bool) (*Reconciliation, error) {
+	if len(counts) == 0 {
+		return nil, fieldErrorf("counts", "at least one count is required")
+	}
+	if len(counts) > maxStockCountsPerCall {
+		return nil, fieldErrorf("counts", "at most %d counts can be reconciled at once", maxStockCountsPerCall)
+	}
+	if reason == "" {
+		reason = stocktakeReason
+	}
+
+	params := make([]map[string]any, 0, len(counts))
+	skus := make([]string, 0, len(counts))
+	seen := make(map[string]bool, len(counts))
+	for i, c := range counts {
+		if c.SKU == "" {
+			return nil, fieldErrorf("counts", "count %d has no sku", i)
+		}
+		if c.Counted < 0 {
+			return nil, fieldErrorf("counts", "count for %s must not be negative", c.SKU)
+		}
+		if seen[c.SKU] {
+			return nil, fieldErrorf("counts", "%s is counted more than once", c.SKU)
+		}
+		seen[c.SKU] = true
+		params = append(params, map[string]any{"sku": c.SKU, "counted": c.Counted})
+		skus = append(skus, c.SKU)
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		// Setting each size's stock to itself locks it before its holds are
+		// read, so reservations can't move stock between the comparison
+		// and the adjustment
+		res, err := tx.Run(ctx, `
+			UNWIND $counts AS c
+			MATCH (p:Product)-[:HAS_SIZE]->(s:Size {sku: c.sku})
+			FOREACH (_ IN CASE WHEN $dry_run THEN [] ELSE [1] END | SET s.stock = s.stock)
+			WITH c, p, s
+			`+heldQuantity+`, c
+			WITH c, p, s, held, s.stock + held AS expected
+			WHERE c.counted <> expected
+			WITH c, p, s, held, expected, s.stock AS before
+			FOREACH (_ IN CASE WHEN $dry_run THEN [] ELSE [1] END |
+				SET s.stock = CASE WHEN c.counted > held THEN c.counted - held ELSE 0 END,
+					s.in_stock = c.counted > held,
+					s.updated_at = $now,
+					p.updated_at = $now
+			)
+			RETURN s.sku AS sku, p.id AS product_id, expected, c.counted AS counted,
+				before, CASE WHEN c.counted > held THEN c.counted - held ELSE 0 END AS stock
+			ORDER BY sku
+		`, map[string]any{
+			"counts":   params,
+			"reserved": reservationReserved,
+			"dry_run":  dryRun,
+			"now":      time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		records, err := res.Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		rec := &Reconciliation{}
+		for _, record := range records {
+			row := record.AsMap()
+			d := StockDiscrepancy{
+				SKU:       getString(row, "sku"),
+				ProductID: getString(row, "product_id"),
+				Expected:  int32(getInt64(row, "expected")),
+				Counted:   int32(getInt64(row, "counted")),
+				Stock:     int32(getInt64(row, "stock")),
+			}
+			rec.Discrepancies = append(rec.Discrepancies, d)
+			if dryRun || int64(d.Stock) == getInt64(row, "before") {
+				continue
+			}
+			ev := events.New(events.StockUpdated, d.ProductID, map[string]any{
+				"stock":    d.Stock,
+				"reason":   reason,
+				"counted":  d.Counted,
+				"expected": d.Expected,
+			})
+			ev.SKU = d.SKU
+			if err := writeEvent(ctx, tx, ev); err != nil {
+				return nil, err
+			}
+			rec.Adjusted++
+		}
+
+		// Counts that match return no row, so SKUs no size has are looked
+		// up separately
+		res, err = tx.Run(ctx, `
+			UNWIND $skus AS sku
+			OPTIONAL MATCH (p:Product)-[:HAS_SIZE]->(s:Size {sku: sku})
+			WITH sku, s
+			WHERE s IS NULL
+			RETURN sku
+		`, map[string]any{"skus": skus})
+		if err != nil {
+			return nil, err
+		}
+		for res.Next(ctx) {
+			sku, _ := res.Record().Values[0].(string)
+			rec.UnknownSKUs = append(rec.UnknownSKUs, sku)
+		}
+		return rec, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*Reconciliation), nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+)
+
+// Per-product content limits. They keep single products (and the Cypher
+// parameters built from them) to a size Neo4j and clients handle
+// comfortably.
+const (
+	maxProductImages      = 50
+	maxProductTags        = 100
+	maxProductSizes       = 200
+	maxProductAttributes  = 100
+	maxProductNameLen     = 512
+	maxProductDescription = 20000
+	maxAttributeValueLen  = 2048
+	maxTaxClassLen        = 64
+	maxMetaTitleLen       = 256
+	maxMetaDescriptionLen = 1024
+)
+
+// ErrTooLarge is matched by errors reporting content over a size limit.
+var ErrTooLarge = errors.New("payload too large")
+
+// LimitError reports a field whose size exceeds its limit.
+type LimitError struct {
+	Field  string
+	Limit  int
+	Actual int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s has %d entries or characters, limit is %d", e.Field, e.Actual, e.Limit)
+}
+
+func (e *LimitError) Is(target error) bool { return target == ErrTooLarge }
+
+// checkProductLimits rejects products over the content limits.
+func checkProductLimits(p *pb.Product) error {
+	checks := []struct {
+		field         string
+		actual, limit int
+	}{
+		{"product.name", len(p.Name), maxProductNameLen},
+		{"product.description", len(p.Description), maxProductDescription},
+		{"product.images", len(p.Images), maxProductImages},
+		{"product.tags", len(p.Tags), maxProductTags},
+		{"product.sizes", len(p.Sizes), maxProductSizes},
+		{"product.attributes", len(p.Attributes), maxProductAttributes},
+		{"product.tax_class", len(p.TaxClass), maxTaxClassLen},
+		{"product.meta_title", len(p.MetaTitle), maxMetaTitleLen},
+		{"product.meta_description", len(p.MetaDescription), maxMetaDescriptionLen},
+	}
+	for _, c := range checks {
+		if c.actual > c.limit {
+			return &LimitError{Field: c.field, Limit: c.limit, Actual: c.actual}
+		}
+	}
+	for key, value := range p.Attributes {
+		if len(value) > maxAttributeValueLen {
+			return &LimitError{Field: "product.attributes[" + key + "]", Limit: maxAttributeValueLen, Actual: len(value)}
+		}
+	}
+	return nil
+}
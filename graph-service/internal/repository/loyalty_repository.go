@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/loyalty"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Loyalty transaction kinds as stored on LoyaltyTransaction nodes.
+const (
+	loyaltyEarn   = "earn"
+	loyaltyRedeem = "redeem"
+)
+
+const maxLoyaltyReferenceLen = 256
+
+var errCustomerNotFound = notFoundf("customer not found")
+
+// TierChange is the Data of a loyalty.tier_changed event.
+type TierChange struct {
+	CustomerID     string `json:"customer_id"`
+	PreviousTier   string `json:"previous_tier"`
+	Tier           string `json:"tier"`
+	LifetimePoints int64  `json:"lifetime_points"`
+}
+
+// GetLoyaltyBalance returns the customer's points and tier.
+func (r *ProductRepository) GetLoyaltyBalance(ctx context.Context, customerID string) (*pb.LoyaltyBalance, error) {
+	if customerID == "" {
+		return nil, fieldErrorf("customer_id", "customer id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (cu:Customer {id: $id})
+			RETURN cu.loyalty_points AS points, cu.loyalty_lifetime_points AS lifetime, cu.loyalty_tier AS tier
+		`, map[string]any{"id": customerID})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, errCustomerNotFound
+		}
+		return loyaltyBalance(customerID, res.Record().AsMap()), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.LoyaltyBalance), nil
+}
+
+// AwardOrderPoints credits the customer who placed the order with the
+// points program gives its lines, each at the rate for its product's main
+// category, and raises a loyalty.tier_changed event when the credit takes
+// them to a new tier. Awarding an order twice credits it once; orders
+// without a customer earn nothing.
+func (r *ProductRepository) AwardOrderPoints(ctx context.Context, orderID string, program *loyalty.Program) (*pb.LoyaltyBalance, error) {
+	if orderID == "" {
+		return nil, fieldErrorf("order_id", "order id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (cu:Customer)-[:PLACED]->(o:Order {id: $id})-[:HAS_LINE]->(l:OrderLine)
+			OPTIONAL MATCH (:Product {id: l.product_id})-[:BELONGS_TO]->(c:Category)
+			WITH cu, l, head(collect(c.main_category)) AS category
+			RETURN cu.id AS customer_id, l.total AS total, category
+		`, map[string]any{"id": orderID})
+		if err != nil {
+			return nil, err
+		}
+		records, err := res.Collect(ctx)
+		if err != nil || len(records) == 0 {
+			return nil, err
+		}
+
+		customerID := getString(records[0].AsMap(), "customer_id")
+		lines := make([]loyalty.Line, 0, len(records))
+		for _, record := range records {
+			row := record.AsMap()
+			total, _ := row["total"].(float64)
+			lines = append(lines, loyalty.Line{Category: getString(row, "category"), Amount: cents(total)})
+		}
+
+		balance, err := adjustPoints(ctx, tx, customerID, loyaltyEarn, program.Earn(lines), "order-"+orderID)
+		if err != nil {
+			return nil, err
+		}
+
+		current, _ := program.Tier(balance.LifetimePoints)
+		if current.Name == balance.Tier {
+			return balance, nil
+		}
+		change := TierChange{
+			CustomerID:     customerID,
+			PreviousTier:   balance.Tier,
+			Tier:           current.Name,
+			LifetimePoints: balance.LifetimePoints,
+		}
+		_, err = tx.Run(ctx, `
+			MATCH (cu:Customer {id: $id})
+			SET cu.loyalty_tier = $tier
+		`, map[string]any{"id": customerID, "tier": current.Name})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeEvent(ctx, tx, events.New(events.LoyaltyTierChanged, "", change)); err != nil {
+			return nil, err
+		}
+		balance.Tier = current.Name
+		return balance, nil
+	})
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	return result.(*pb.LoyaltyBalance), nil
+}
+
+// RedeemPoints spends the customer's points. Fewer points than asked for
+// fails with ErrInsufficientBalance. reference keys the redemption, so
+// retrying it spends once.
+func (r *ProductRepository) RedeemPoints(ctx context.Context, customerID string, points int64, reference string) (*pb.LoyaltyBalance, error) {
+	switch {
+	case customerID == "":
+		return nil, fieldErrorf("customer_id", "customer id is required")
+	case points <= 0:
+		return nil, fieldErrorf("points", "points must be positive")
+	case reference == "":
+		return nil, fieldErrorf("reference", "reference is required")
+	case len(reference) > maxLoyaltyReferenceLen:
+		return nil, &LimitError{Field: "reference", Limit: maxLoyaltyReferenceLen, Actual: len(reference)}
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return adjustPoints(ctx, tx, customerID, loyaltyRedeem, -points, reference)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.LoyaltyBalance), nil
+}
+
+// adjustPoints locks the customer, then adds points, negative to spend
+// them, and records the transaction under reference. A reference already
+// recorded leaves the balance alone.
+func adjustPoints(ctx context.Context, tx neo4j.ManagedTransaction, customerID, kind string, points int64, reference string) (*pb.LoyaltyBalance, error) {
+	now := time.Now().UnixMilli()
+	res, err := tx.Run(ctx, `
+		MATCH (cu:Customer {id: $id})
+		SET cu.updated_at = $now
+		WITH cu
+		OPTIONAL MATCH (t:LoyaltyTransaction {reference: $reference})-[:FOR_CUSTOMER]->(cu)
+		RETURN cu.loyalty_points AS points, cu.loyalty_lifetime_points AS lifetime, cu.loyalty_tier AS tier,
+			count(t) > 0 AS recorded
+	`, map[string]any{"id": customerID, "reference": reference, "now": now})
+	if err != nil {
+		return nil, err
+	}
+	if !res.Next(ctx) {
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errCustomerNotFound
+	}
+	row := res.Record().AsMap()
+	balance := loyaltyBalance(customerID, row)
+	if recorded, _ := row["recorded"].(bool); recorded || points == 0 {
+		return balance, nil
+	}
+
+	if balance.Points+points < 0 {
+		return nil, fmt.Errorf("%w: %d points left", ErrInsufficientBalance, balance.Points)
+	}
+	balance.Points += points
+	if points > 0 {
+		balance.LifetimePoints += points
+	}
+
+	_, err = tx.Run(ctx, `
+		MATCH (cu:Customer {id: $id})
+		SET cu.loyalty_points = $points, cu.loyalty_lifetime_points = $lifetime
+		CREATE (:LoyaltyTransaction {
+			id: $transaction_id,
+			customer_id: $id,
+			kind: $kind,
+			points: $delta,
+			balance: $points,
+			reference: $reference,
+			created_at: $now
+		})-[:FOR_CUSTOMER]->(cu)
+	`, map[string]any{
+		"id":             customerID,
+		"points":         balance.Points,
+		"lifetime":       balance.LifetimePoints,
+		"transaction_id": newID(),
+		"kind":           kind,
+		"delta":          points,
+		"reference":      reference,
+		"now":            now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+func loyaltyBalance(customerID string, row map[string]any) *pb.LoyaltyBalance {
+	return &pb.LoyaltyBalance{
+		CustomerId:     customerID,
+		Points:         getInt64(row, "points"),
+		LifetimePoints: getInt64(row, "lifetime"),
+		Tier:           getString(row, "tier"),
+	}
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// GetNewArrivals returns in-stock products created within maxAge, newest
+// first, resuming after the cursor.
+func (r *ProductRepository) GetNewArrivals(ctx context.Context, filter *pb.CatalogFilter, maxAge time.Duration, after pagetoken.Cursor, limit int) ([]*pb.Product, error) {
+	params := map[string]any{
+		"created_after": time.Now().Add(-maxAge).UnixMilli(),
+		"after_time":    after.LastTime,
+		"after_id":      after.LastID,
+		"limit":         limit,
+	}
+
+	query := `
+		MATCH (p:Product)
+		WHERE p.created_at >= $created_after
+			AND ` + catalogFilterClause(r.dialect, filter, params) + `
+			AND ` + r.dialect.exists(inStockPattern) + `
+			AND ($after_id = ''
+				OR p.created_at < $after_time
+				OR (p.created_at = $after_time AND p.id < $after_id))
+		WITH p
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT $limit
+		OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+		OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+		RETURN p, c, collect(s) as sizes
+		ORDER BY p.created_at DESC, p.id DESC
+	`
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+
+		products := []*pb.Product{}
+		for res.Next(ctx) {
+			products = append(products, productFromRecord(res.Record()))
+		}
+		return products, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]*pb.Product), nil
+}
+
+// GetDeals returns discounted products ordered by discount percentage
+// (highest first), resuming after the cursor.
+func (r *ProductRepository) GetDeals(ctx context.Context, filter *pb.CatalogFilter, after pagetoken.Cursor, limit int) ([]*pb.Deal, error) {
+	params := map[string]any{
+		"after_discount": after.LastRank,
+		"after_id":       after.LastID,
+		"limit":          limit,
+	}
+
+	query := `
+		MATCH (p:Product)
+		WHERE p.original_price > p.price
+			AND ` + catalogFilterClause(r.dialect, filter, params) + `
+		WITH p, (p.original_price - p.price) * 100.0 / p.original_price AS discount
+		WHERE $after_id = ''
+			OR discount < $after_discount
+			OR (discount = $after_discount AND p.id > $after_id)
+		WITH p, discount
+		ORDER BY discount DESC, p.id
+		LIMIT $limit
+		OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+		OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+		RETURN p, c, collect(s) as sizes, discount
+		ORDER BY discount DESC, p.id
+	`
+
+	return cachedRead(ctx, r, query, params, func() ([]*pb.Deal, error) {
+		session := r.newSession(ctx, neo4j.AccessModeRead)
+		defer r.closeSession(ctx, session)
+
+		result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			res, err := tx.Run(ctx, query, params)
+			if err != nil {
+				return nil, err
+			}
+
+			deals := []*pb.Deal{}
+			for res.Next(ctx) {
+				record := res.Record()
+				discount, _ := record.Values[3].(float64)
+				deals = append(deals, &pb.Deal{
+					Product:         productFromRecord(record),
+					DiscountPercent: discount,
+				})
+			}
+			return deals, res.Err()
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return result.([]*pb.Deal), nil
+	})
+}
@@ -0,0 +1,396 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// A migration reshapes existing graph data. Its statements run in order in
+// one write transaction, which also records the migration as applied, so a
+// failed migration leaves nothing behind and is retried on the next run.
+// Statements must be safe on data already in the new shape.
+type migration struct {
+	id string
+
+	// blockers, if set, returns one row per problem an operator has to
+	// fix before the migration can run, with a "problem" column.
+	blockers string
+
+	// schema returns index and constraint statements. Each runs in its
+	// own transaction, ahead of statements, since Neo4j can't mix schema
+	// and data changes in one transaction; they must be safe to repeat.
+	schema func(Dialect) []string
+
+	// backfill runs after schema and before statements for data changes
+	// too large for one transaction, or that need Go to compute. It
+	// commits as it goes, so it must pick up where an earlier failed run
+	// stopped.
+	backfill func(*ProductRepository, context.Context) error
+
+	statements []string
+}
+
+// migrations are applied in order. Never edit or reorder an applied one;
+// add a new migration instead.
+var migrations = []migration{
+	{
+		// Category nodes used to be MERGEd on the whole (main, sub,
+		// specific) triple. Merge any duplicate triples, then hang the
+		// existing nodes under MainCategory and Subcategory parents.
+		id: "0001_category_hierarchy",
+		statements: []string{`
+			MATCH (c:Category)
+			WITH c.main_category AS main, c.subcategory AS sub, c.specific_type AS type, collect(c) AS nodes
+			WHERE size(nodes) > 1
+			WITH nodes[0] AS keep, nodes[1..] AS duplicates
+			UNWIND duplicates AS duplicate
+			OPTIONAL MATCH (p:Product)-[:BELONGS_TO]->(duplicate)
+			WITH keep, duplicate, collect(p) AS products
+			FOREACH (p IN products | MERGE (p)-[:BELONGS_TO]->(keep))
+			DETACH DELETE duplicate
+		`, `
+			MATCH (c:Category)
+			WHERE NOT c:SpecificType
+			MERGE (m:MainCategory {name: c.main_category})
+			ON CREATE SET m.created_at = $now, m.updated_at = $now
+			MERGE (m)-[:HAS_SUBCATEGORY]->(sc:Subcategory {name: c.subcategory})
+			ON CREATE SET sc.created_at = $now, sc.updated_at = $now
+			SET c:SpecificType, c.name = c.specific_type
+			MERGE (sc)-[:HAS_TYPE]->(c)
+		`},
+	},
+	{
+		// Two products must not share a SKU
+		id: "0002_unique_size_sku",
+		blockers: `
+			MATCH (s:Size)
+			WHERE s.sku IS NOT NULL
+			WITH s.sku AS sku, count(*) AS sizes
+			WHERE sizes > 1
+			RETURN 'sku "' + sku + '" is used by ' + toString(sizes) + ' sizes' AS problem
+			ORDER BY sku
+			LIMIT 20
+		`,
+		schema: func(d Dialect) []string {
+			return []string{d.uniqueConstraint("size_sku", "Size", "sku")}
+		},
+	},
+	{
+		// Alternate product identifiers are unique when set
+		id: "0003_unique_product_identifiers",
+		schema: func(d Dialect) []string {
+			return []string{
+				d.uniqueConstraint("product_slug", "Product", "slug"),
+				d.uniqueConstraint("product_gtin", "Product", "gtin"),
+				d.uniqueConstraint("product_external_id", "Product", "external_id"),
+			}
+		},
+	},
+	{
+		// Products created before slugs were generated get one from
+		// their name and brand
+		id:       "0004_backfill_product_slugs",
+		backfill: (*ProductRepository).backfillSlugs,
+	},
+	{
+		id: "0005_unique_reservation_id",
+		schema: func(d Dialect) []string {
+			return []string{d.uniqueConstraint("reservation_id", "Reservation", "id")}
+		},
+	},
+	{
+		id: "0006_unique_order_id",
+		schema: func(d Dialect) []string {
+			return []string{d.uniqueConstraint("order_id", "Order", "id")}
+		},
+	},
+	{
+		id: "0007_unique_return_id",
+		schema: func(d Dialect) []string {
+			return []string{d.uniqueConstraint("return_id", "Return", "id")}
+		},
+	},
+	{
+		// Customers become users, identified by id or email
+		id: "0008_unique_customer",
+		blockers: `
+			MATCH (cu:Customer)
+			WITH cu.id AS id, count(*) AS customers
+			WHERE customers > 1
+			RETURN 'customer id "' + id + '" is used by ' + toString(customers) + ' customers' AS problem
+			ORDER BY id
+			LIMIT 20
+		`,
+		schema: func(d Dialect) []string {
+			return []string{
+				d.uniqueConstraint("customer_id", "Customer", "id"),
+				d.uniqueConstraint("customer_email", "Customer", "email"),
+			}
+		},
+	},
+	{
+		id: "0009_unique_coupon_code",
+		schema: func(d Dialect) []string {
+			return []string{d.uniqueConstraint("coupon_code", "Coupon", "code")}
+		},
+	},
+	{
+		id: "0010_unique_gift_card",
+		schema: func(d Dialect) []string {
+			return []string{
+				d.uniqueConstraint("gift_card_id", "GiftCard", "id"),
+				d.uniqueConstraint("gift_card_code", "GiftCard", "code"),
+			}
+		},
+	},
+	{
+		// Translations carry a label per language for the per-language
+		// search indexes to cover
+		id:       "0011_translation_language_labels",
+		backfill: (*ProductRepository).backfillTranslationLabels,
+	},
+	{
+		// Default searches and listings only match products labeled as
+		// in the active season; every existing product starts in it. Run
+		// before deploying the servers that filter on the label.
+		id:       "0012_active_product_label",
+		backfill: (*ProductRepository).backfillActiveLabels,
+	},
+	{
+		id: "0013_unique_sync_run",
+		schema: func(d Dialect) []string {
+			return []string{
+				d.uniqueConstraint("sync_source_name", "SyncSource", "name"),
+				d.uniqueConstraint("sync_run_id", "SyncRun", "id"),
+			}
+		},
+	},
+	{
+		// Stock history reads each SKU's stock.updated events
+		id: "0014_event_sku_index",
+		schema: func(d Dialect) []string {
+			return []string{d.index("event_sku", "Event", "sku")}
+		},
+	},
+	{
+		id: "0015_voice_transcripts",
+		schema: func(d Dialect) []string {
+			return []string{
+				d.uniqueConstraint("voice_session_id", "VoiceSession", "id"),
+				d.index("voice_session_customer", "VoiceSession", "customer_id"),
+			}
+		},
+	},
+	{
+		// Questions and answers are looked up by author for data requests
+		id: "0016_product_qa",
+		schema: func(d Dialect) []string {
+			return []string{
+				d.uniqueConstraint("question_id", "Question", "id"),
+				d.uniqueConstraint("answer_id", "Answer", "id"),
+				d.index("question_author", "Question", "author_id"),
+				d.index("answer_author", "Answer", "author_id"),
+			}
+		},
+	},
+	{
+		id: "0017_sellers",
+		schema: func(d Dialect) []string {
+			return []string{
+				d.uniqueConstraint("seller_id", "Seller", "id"),
+				d.index("seller_tenant", "Seller", "tenant_id"),
+			}
+		},
+	},
+	{
+		id: "0018_product_submissions",
+		schema: func(d Dialect) []string {
+			return []string{
+				d.uniqueConstraint("submission_id", "Submission", "id"),
+				d.index("submission_status", "Submission", "status"),
+			}
+		},
+	},
+	{
+		// Entry ids are the order's and line's, so a line settles once
+		id: "0019_settlements",
+		schema: func(d Dialect) []string {
+			return []string{
+				d.uniqueConstraint("settlement_entry_id", "SettlementEntry", "id"),
+			}
+		},
+	},
+	{
+		id: "0020_seller_notifications",
+		schema: func(d Dialect) []string {
+			return []string{
+				d.index("webhook_seller", "Webhook", "seller_id"),
+				d.uniqueConstraint("policy_violation_id", "PolicyViolation", "id"),
+			}
+		},
+	},
+	{
+		// SyncChanges pages through the outbox in occurred_at order
+		id: "0021_event_occurred_at_index",
+		schema: func(d Dialect) []string {
+			return []string{d.index("event_occurred_at", "Event", "occurred_at")}
+		},
+	},
+	{
+		// Pickup lookups filter stores by their distance from the shopper
+		id: "0022_stores",
+		schema: func(d Dialect) []string {
+			return []string{
+				d.uniqueConstraint("store_id", "Store", "id"),
+				d.pointIndex("store_location", "Store", "location"),
+			}
+		},
+	},
+	{
+		// The relay reads unpublished events in sequence order, and the
+		// constraint keeps concurrent writers on one sequence node
+		id: "0023_event_outbox_sequence",
+		schema: func(d Dialect) []string {
+			return []string{
+				d.uniqueConstraint("outbox_sequence_id", "OutboxSequence", "id"),
+				d.index("event_published", "Event", "published"),
+				d.index("event_seq", "Event", "seq"),
+			}
+		},
+	},
+}
+
+// Migrate applies the migrations not yet recorded in the database, then
+// brings the search indexes in line with their configuration. It returns
+// the ids it applied followed by the index changes.
+func (r *ProductRepository) Migrate(ctx context.Context) ([]string, error) {
+	applied, err := r.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []string
+	for _, m := range migrations {
+		if applied[m.id] {
+			continue
+		}
+		if err := r.applyMigration(ctx, m); err != nil {
+			return ran, err
+		}
+		ran = append(ran, m.id)
+	}
+
+	changes, err := r.syncSearchIndexes(ctx)
+	return append(ran, changes...), err
+}
+
+// PendingMigrations returns the ids of migrations Migrate would apply,
+// followed by the search index changes it would make.
+func (r *ProductRepository) PendingMigrations(ctx context.Context) ([]string, error) {
+	applied, err := r.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, m := range migrations {
+		if !applied[m.id] {
+			pending = append(pending, m.id)
+		}
+	}
+
+	_, changes, err := r.searchIndexChanges(ctx)
+	return append(pending, changes...), err
+}
+
+func (r *ProductRepository) appliedMigrations(ctx context.Context) (map[string]bool, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (m:SchemaMigration)
+			RETURN m.id AS id
+		`, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		applied := map[string]bool{}
+		for res.Next(ctx) {
+			applied[getString(res.Record().AsMap(), "id")] = true
+		}
+		return applied, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(map[string]bool), nil
+}
+
+func (r *ProductRepository) applyMigration(ctx context.Context, m migration) error {
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	if m.blockers != "" {
+		result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			res, err := tx.Run(ctx, m.blockers, nil)
+			if err != nil {
+				return nil, err
+			}
+
+			var problems []string
+			for res.Next(ctx) {
+				problems = append(problems, getString(res.Record().AsMap(), "problem"))
+			}
+			return problems, res.Err()
+		})
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", m.id, err)
+		}
+		if problems := result.([]string); len(problems) > 0 {
+			return fmt.Errorf("migration %s is blocked until these are fixed: %s", m.id, strings.Join(problems, "; "))
+		}
+	}
+
+	if m.schema != nil {
+		for _, statement := range m.schema(r.dialect) {
+			_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+				_, err := tx.Run(ctx, statement, nil)
+				return nil, err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %s: %w", m.id, err)
+			}
+		}
+	}
+
+	if m.backfill != nil {
+		if err := m.backfill(r, ctx); err != nil {
+			return fmt.Errorf("migration %s: %w", m.id, err)
+		}
+	}
+
+	now := time.Now().UnixMilli()
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		for _, statement := range m.statements {
+			if _, err := tx.Run(ctx, statement, map[string]any{"now": now}); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err := tx.Run(ctx, `
+			MERGE (m:SchemaMigration {id: $id})
+			ON CREATE SET m.applied_at = $now
+		`, map[string]any{"id": m.id, "now": now})
+		return nil, err
+	})
+
+	return err
+}
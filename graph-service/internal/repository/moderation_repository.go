@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const (
+	maxModerationReason = 1024
+	maxFlagCategories   = 20
+)
+
+// PendingPost is a question or answer waiting for moderation.
+type PendingPost struct {
+	Kind       string
+	ID         string
+	ProductID  string
+	QuestionID string
+	AuthorID   string
+	Text       string
+	CreatedAt  int64
+	// FlaggedCategories are what automated screening flagged the post
+	// for, empty when it wasn't screened or passed.
+	FlaggedCategories []string
+}
+
+// ModerationDecision approves or rejects a post. Automated decisions are
+// the screening's rather than a moderator's.
+type ModerationDecision struct {
+	Approve   bool
+	Reason    string
+	Moderator string
+	Automated bool
+}
+
+// ListPendingModeration returns up to limit questions and answers waiting
+// for moderation, of tenantID or of every tenant when it's empty, oldest
+// first, resuming after the cursor.
+func (r *ProductRepository) ListPendingModeration(ctx context.Context, tenantID string, after pagetoken.Cursor, limit int) ([]PendingPost, error) {
+	params := map[string]any{
+		"tenant_id":  tenantID,
+		"pending":    QAPending,
+		"after_time": after.LastTime,
+		"after_id":   after.LastID,
+		"limit":      limit,
+	}
+	page := `
+		WHERE ($tenant_id = '' OR n.tenant_id = $tenant_id)
+			AND ($after_id = ''
+				OR n.created_at > $after_time
+				OR (n.created_at = $after_time AND n.id > $after_id))
+		WITH n, p, q
+		ORDER BY n.created_at, n.id
+		LIMIT $limit
+		RETURN n, p.id AS product_id, q.id AS question_id
+	`
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		var posts []PendingPost
+		for kind, match := range map[string]string{
+			QuestionPost: `MATCH (p:Product)-[:HAS_QUESTION]->(n:Question {status: $pending}) WITH n, p, n AS q`,
+			AnswerPost:   `MATCH (p:Product)-[:HAS_QUESTION]->(q:Question)-[:HAS_ANSWER]->(n:Answer {status: $pending})`,
+		} {
+			res, err := tx.Run(ctx, match+page, params)
+			if err != nil {
+				return nil, err
+			}
+			for res.Next(ctx) {
+				row := res.Record().AsMap()
+				node, _ := row["n"].(neo4j.Node)
+				posts = append(posts, PendingPost{
+					Kind:              kind,
+					ID:                getString(node.Props, "id"),
+					ProductID:         getString(row, "product_id"),
+					QuestionID:        getString(row, "question_id"),
+					AuthorID:          getString(node.Props, "author_id"),
+					Text:              getString(node.Props, "text"),
+					CreatedAt:         getInt64(node.Props, "created_at"),
+					FlaggedCategories: getStrings(node.Props, "flagged_categories"),
+				})
+			}
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+		}
+		return posts, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	posts, _ := result.([]PendingPost)
+	slices.SortFunc(posts, func(a, b PendingPost) int {
+		if a.CreatedAt != b.CreatedAt {
+			return int(a.CreatedAt - b.CreatedAt)
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	if len(posts) > limit {
+		posts = posts[:limit]
+	}
+	return posts, nil
+}
+
+// DecidePost approves or rejects the question or answer id and returns
+// which of the two it was. Decided posts can be decided again, e.g. to
+// take down an approved answer. Each decision raises a moderation event.
+func (r *ProductRepository) DecidePost(ctx context.Context, id string, d ModerationDecision) (string, error) {
+	if id == "" {
+		return "", fieldErrorf("id", "id is required")
+	}
+	if len(d.Reason) > maxModerationReason {
+		return "", &LimitError{Field: "reason", Limit: maxModerationReason, Actual: len(d.Reason)}
+	}
+	status, eventType := QARejected, events.ModerationRejected
+	if d.Approve {
+		status, eventType = QAApproved, events.ModerationApproved
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			OPTIONAL MATCH (qp:Product)-[:HAS_QUESTION]->(q:Question {id: $id})
+			OPTIONAL MATCH (ap:Product)-[:HAS_QUESTION]->(aq:Question)-[:HAS_ANSWER]->(a:Answer {id: $id})
+			WITH coalesce(q, a) AS n, coalesce(qp, ap) AS p, coalesce(q, aq) AS question, q IS NOT NULL AS is_question
+			WHERE n IS NOT NULL
+			WITH n, p, question, is_question, n.status AS previous
+			SET n.status = $status,
+				n.moderated_by = $moderator,
+				n.moderation_reason = $reason,
+				n.moderated_at = $now,
+				n.updated_at = $now
+			RETURN is_question, p.id AS product_id, question.id AS question_id, n.author_id AS author_id, previous
+		`, map[string]any{
+			"id":        id,
+			"status":    status,
+			"moderator": d.Moderator,
+			"reason":    d.Reason,
+			"now":       time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, notFoundf("question or answer not found")
+		}
+		row := res.Record().AsMap()
+
+		kind := AnswerPost
+		if question, _ := row["is_question"].(bool); question {
+			kind = QuestionPost
+		}
+		err = writeEvent(ctx, tx, events.New(eventType, getString(row, "product_id"), map[string]any{
+			"kind":        kind,
+			"id":          id,
+			"question_id": getString(row, "question_id"),
+			"author_id":   getString(row, "author_id"),
+			"previous":    getString(row, "previous"),
+			"reason":      d.Reason,
+			"moderator":   d.Moderator,
+			"automated":   d.Automated,
+		}))
+		if err != nil {
+			return nil, err
+		}
+		return kind, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return result.(string), nil
+}
+
+// FlagPost records what automated screening flagged the pending question
+// or answer id for, leaving it for a moderator to decide.
+func (r *ProductRepository) FlagPost(ctx context.Context, id string, categories []string) error {
+	if len(categories) > maxFlagCategories {
+		categories = categories[:maxFlagCategories]
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			OPTIONAL MATCH (q:Question {id: $id})
+			OPTIONAL MATCH (a:Answer {id: $id})
+			WITH coalesce(q, a) AS n
+			WHERE n IS NOT NULL AND n.status = $pending
+			SET n.flagged_categories = $categories,
+				n.screened_at = $now
+		`, map[string]any{
+			"id":         id,
+			"pending":    QAPending,
+			"categories": categories,
+			"now":        time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		_, err = res.Consume(ctx)
+		return nil, err
+	})
+	return err
+}
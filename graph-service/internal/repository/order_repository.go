@@ -0,0 +1,517 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Order statuses as stored on Order nodes.
+const (
+	orderPendingPayment = "pending_payment"
+	orderPlaced         = "placed"
+	orderPaymentFailed  = "payment_failed"
+	orderReturning      = "return_in_progress"
+	orderPartlyReturned = "partially_returned"
+	orderReturned       = "returned"
+	orderUnderReview    = "under_review"
+	orderDeclined       = "declined"
+)
+
+var orderStatuses = map[string]pb.OrderStatus{
+	orderPendingPayment: pb.OrderStatus_PENDING_PAYMENT,
+	orderPlaced:         pb.OrderStatus_PLACED,
+	orderPaymentFailed:  pb.OrderStatus_PAYMENT_FAILED,
+	orderReturning:      pb.OrderStatus_RETURN_IN_PROGRESS,
+	orderPartlyReturned: pb.OrderStatus_PARTIALLY_RETURNED,
+	orderReturned:       pb.OrderStatus_RETURNED,
+	orderUnderReview:    pb.OrderStatus_UNDER_REVIEW,
+	orderDeclined:       pb.OrderStatus_DECLINED,
+}
+
+var errOrderNotFound = notFoundf("order not found")
+
+func validateOrderLines(lines []*pb.OrderLine) error {
+	if len(lines) == 0 {
+		return fieldErrorf("lines", "order needs at least one line")
+	}
+	seen := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		if line.GetSku() == "" || line.GetQuantity() <= 0 {
+			return fieldErrorf("lines", "order lines need a sku and a positive quantity")
+		}
+		if seen[line.Sku] {
+			return fieldErrorf("lines", "sku %q listed more than once", line.Sku)
+		}
+		seen[line.Sku] = true
+	}
+	return nil
+}
+
+// CreateOrder prices the order's lines from the catalog, reserves their
+// stock and records the order as pending payment, all in one transaction.
+// The reservation shares the order's id and expires after ttl like any
+// other, so an order whose payment never settles gives its stock back.
+// It fills in the order's id, prices, totals, status and timestamps.
+// A couponCode, if set, is redeemed for the order in the same transaction
+// and its discount set on the lines and taken off the total; the coupon is
+// returned.
+func (r *ProductRepository) CreateOrder(ctx context.Context, o *pb.Order, couponCode string, ttl time.Duration) (*pb.Coupon, error) {
+	if err := validateOrderLines(o.GetLines()); err != nil {
+		return nil, err
+	}
+
+	res := &pb.Reservation{Id: o.Id, TenantId: o.TenantId}
+	for _, line := range o.Lines {
+		res.Items = append(res.Items, &pb.ReservationItem{Sku: line.Sku, Quantity: line.Quantity})
+	}
+	now := time.Now().UnixMilli()
+	if err := r.prepareReservation(res, ttl, now); err != nil {
+		return nil, err
+	}
+	o.Id = res.Id
+	o.Status = pb.OrderStatus_PENDING_PAYMENT
+	o.CreatedAt, o.UpdatedAt = now, now
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		if err := reserveStock(ctx, tx, res); err != nil {
+			return nil, err
+		}
+		sellers, err := priceOrder(ctx, tx, o)
+		if err != nil {
+			return nil, err
+		}
+		var coupon *pb.Coupon
+		if couponCode != "" {
+			if coupon, err = applyOrderCoupon(ctx, tx, couponCode, o, now); err != nil {
+				return nil, err
+			}
+			o.Total = roundCents(o.Subtotal - orderDiscount(o))
+		}
+
+		lines := make([]map[string]any, 0, len(o.Lines))
+		for _, line := range o.Lines {
+			lines = append(lines, map[string]any{
+				"sku":          line.Sku,
+				"quantity":     line.Quantity,
+				"product_id":   line.ProductId,
+				"product_name": line.ProductName,
+				"unit_price":   line.UnitPrice,
+				"total":        line.Total,
+				"discount":     line.Discount,
+				"tax_class":    line.TaxClass,
+				"seller_id":    sellers[line.Sku],
+			})
+		}
+
+		_, err = tx.Run(ctx, `
+			MATCH (r:Reservation {id: $id})
+			CREATE (o:Order {
+				id: $id,
+				tenant_id: $tenant_id,
+				customer_id: $customer_id,
+				currency: $currency,
+				subtotal: $subtotal,
+				discount: $discount,
+				coupon_code: $coupon_code,
+				tax: 0.0,
+				total: $total,
+				status: $status,
+				payment_id: '',
+				failure_reason: '',
+				created_at: $now,
+				updated_at: $now
+			})-[:RESERVED]->(r)
+			WITH o
+			UNWIND $lines AS line
+			MATCH (s:Size {sku: line.sku})
+			CREATE (o)-[:HAS_LINE]->(l:OrderLine {
+				sku: line.sku,
+				quantity: line.quantity,
+				product_id: line.product_id,
+				product_name: line.product_name,
+				unit_price: line.unit_price,
+				total: line.total,
+				discount: line.discount,
+				tax: 0.0,
+				tax_rate: 0.0,
+				tax_class: line.tax_class,
+				seller_id: line.seller_id
+			})-[:OF_SIZE]->(s)
+			WITH l
+			MATCH (sel:Seller {id: l.seller_id})
+			CREATE (l)-[:SOLD_BY]->(sel)
+		`, map[string]any{
+			"id":          o.Id,
+			"tenant_id":   o.TenantId,
+			"customer_id": o.CustomerId,
+			"currency":    o.Currency,
+			"subtotal":    o.Subtotal,
+			"discount":    orderDiscount(o),
+			"coupon_code": coupon.GetCode(),
+			"total":       o.Total,
+			"status":      orderPendingPayment,
+			"lines":       lines,
+			"now":         now,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if o.CustomerId == "" {
+			return coupon, nil
+		}
+		_, err = tx.Run(ctx, `
+			MATCH (o:Order {id: $id})
+			MERGE (cu:Customer {id: $customer_id})
+			ON CREATE SET cu.created_at = $now, cu.updated_at = $now
+			CREATE (cu)-[:PLACED]->(o)
+		`, map[string]any{"id": o.Id, "customer_id": o.CustomerId, "now": now})
+		return coupon, err
+	})
+	if isConstraintViolation(err) {
+		return nil, fieldErrorf("id", "order %q already exists", o.Id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	coupon, _ := result.(*pb.Coupon)
+	return coupon, nil
+}
+
+// orderDiscount adds up the discounts on the order's lines.
+func orderDiscount(o *pb.Order) float64 {
+	var discount float64
+	for _, line := range o.Lines {
+		discount += line.Discount
+	}
+	return roundCents(discount)
+}
+
+// PriceOrder prices the order's lines as CreateOrder would, without
+// reserving anything.
+func (r *ProductRepository) PriceOrder(ctx context.Context, o *pb.Order) error {
+	if err := validateOrderLines(o.GetLines()); err != nil {
+		return err
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := priceOrder(ctx, tx, o)
+		return nil, err
+	})
+	return err
+}
+
+// priceOrder sets each line's product, unit price, total and tax class
+// from the catalog, and the order's subtotal and total. Tax and discounts
+// are left at zero for the caller to add. Lines of SKUs with a seller offer in the buy
+// box are sold by that seller at its price; the sellers are returned by
+// SKU.
+func priceOrder(ctx context.Context, tx neo4j.ManagedTransaction, o *pb.Order) (map[string]string, error) {
+	skus := make([]string, 0, len(o.Lines))
+	for _, line := range o.Lines {
+		skus = append(skus, line.Sku)
+	}
+
+	res, err := tx.Run(ctx, `
+		UNWIND $skus AS sku
+		MATCH (p:Product)-[:HAS_SIZE]->(s:Size {sku: sku})
+		OPTIONAL MATCH (s)<-[offer:OFFERS]-(sel:Seller)
+		WHERE offer.stock > 0 AND NOT coalesce(sel.suspended, false)
+		WITH p, s, offer, sel
+		ORDER BY offer.price, offer.stock DESC, sel.id
+		WITH p, s, head(collect(CASE WHEN sel IS NULL THEN NULL ELSE [sel.id, offer.price] END)) AS best
+		RETURN s.sku AS sku, p.id AS product_id, p.name AS name, p.price AS price,
+			p.tax_class AS tax_class, best[0] AS seller_id, best[1] AS offer_price
+	`, map[string]any{"skus": skus})
+	if err != nil {
+		return nil, err
+	}
+	records, err := res.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[string]map[string]any, len(records))
+	for _, record := range records {
+		row := record.AsMap()
+		products[getString(row, "sku")] = row
+	}
+
+	sellers := map[string]string{}
+	o.Subtotal = 0
+	for _, line := range o.Lines {
+		row, ok := products[line.Sku]
+		if !ok {
+			return nil, notFoundf("sku %s is not sold by any product", line.Sku)
+		}
+		price, _ := row["price"].(float64)
+		if seller := getString(row, "seller_id"); seller != "" {
+			sellers[line.Sku] = seller
+			price, _ = row["offer_price"].(float64)
+		}
+
+		line.ProductId = getString(row, "product_id")
+		line.ProductName = getString(row, "name")
+		line.UnitPrice = price
+		line.Total = roundCents(price * float64(line.Quantity))
+		line.TaxClass = getString(row, "tax_class")
+		line.Tax, line.TaxRate, line.Discount = 0, 0, 0
+		o.Subtotal += line.Total
+	}
+	o.Subtotal = roundCents(o.Subtotal)
+	o.Tax = 0
+	o.Total = o.Subtotal
+	return sellers, nil
+}
+
+// SetOrderTax records the tax set on the lines of o, an order pending
+// payment, and sets its tax and total to match.
+func (r *ProductRepository) SetOrderTax(ctx context.Context, o *pb.Order) error {
+	o.Tax = 0
+	lines := make([]map[string]any, 0, len(o.Lines))
+	for _, line := range o.Lines {
+		line.Tax = roundCents(line.Tax)
+		o.Tax += line.Tax
+		lines = append(lines, map[string]any{
+			"sku":      line.Sku,
+			"tax":      line.Tax,
+			"tax_rate": line.TaxRate,
+		})
+	}
+	o.Tax = roundCents(o.Tax)
+	o.Total = roundCents(o.Subtotal - orderDiscount(o) + o.Tax)
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (o:Order {id: $id, status: $pending})
+			SET o.tax = $tax, o.total = o.subtotal - coalesce(o.discount, 0.0) + $tax, o.updated_at = $now
+			WITH o
+			UNWIND $lines AS line
+			MATCH (o)-[:HAS_LINE]->(l:OrderLine {sku: line.sku})
+			SET l.tax = line.tax, l.tax_rate = line.tax_rate
+			RETURN count(l) AS updated
+		`, map[string]any{
+			"id":      o.Id,
+			"pending": orderPendingPayment,
+			"tax":     o.Tax,
+			"lines":   lines,
+			"now":     time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) || getInt64(res.Record().AsMap(), "updated") == 0 {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: order %s is not pending payment", ErrInvalidTransition, o.Id)
+		}
+		return nil, nil
+	})
+	return err
+}
+
+// ConfirmOrder commits the order's reserved stock and marks it placed
+// with its payment. It fails with ErrReservationClosed if the reservation
+// expired first.
+func (r *ProductRepository) ConfirmOrder(ctx context.Context, id, paymentID string) error {
+	if id == "" {
+		return fieldErrorf("id", "order id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		now := time.Now().UnixMilli()
+		if err := commitReservation(ctx, tx, id, now); err != nil {
+			return nil, err
+		}
+
+		order, err := setOrderStatus(ctx, tx, id, orderPlaced, paymentID, "", now)
+		if err != nil {
+			return nil, err
+		}
+		return nil, writeEvent(ctx, tx, events.New(events.OrderPlaced, "", order))
+	})
+
+	return err
+}
+
+// FailOrder returns the order's reserved stock, if it still holds any,
+// gives back its coupon uses and marks it failed with reason.
+func (r *ProductRepository) FailOrder(ctx context.Context, id, paymentID, reason string) error {
+	if id == "" {
+		return fieldErrorf("id", "order id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		now := time.Now().UnixMilli()
+		if err := releaseReservation(ctx, tx, id, now); err != nil {
+			return nil, err
+		}
+		if err := releaseCoupons(ctx, tx, id, now); err != nil {
+			return nil, err
+		}
+
+		_, err := setOrderStatus(ctx, tx, id, orderPaymentFailed, paymentID, reason, now)
+		return nil, err
+	})
+
+	return err
+}
+
+func (r *ProductRepository) GetOrder(ctx context.Context, id string) (*pb.Order, error) {
+	if id == "" {
+		return nil, fieldErrorf("id", "order id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return readOrder(ctx, tx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.Order), nil
+}
+
+// lockOrder write-locks the order and returns its status as of the lock.
+func lockOrder(ctx context.Context, tx neo4j.ManagedTransaction, id string, now int64) (string, error) {
+	res, err := tx.Run(ctx, `
+		MATCH (o:Order {id: $id})
+		SET o.updated_at = $now
+		RETURN o.status AS status
+	`, map[string]any{"id": id, "now": now})
+	if err != nil {
+		return "", err
+	}
+	if !res.Next(ctx) {
+		if err := res.Err(); err != nil {
+			return "", err
+		}
+		return "", errOrderNotFound
+	}
+	return getString(res.Record().AsMap(), "status"), nil
+}
+
+// setOrderStatus updates the order and returns it as updated.
+func setOrderStatus(ctx context.Context, tx neo4j.ManagedTransaction, id, status, paymentID, reason string, now int64) (*pb.Order, error) {
+	res, err := tx.Run(ctx, `
+		MATCH (o:Order {id: $id})
+		SET o.status = $status,
+			o.payment_id = $payment_id,
+			o.failure_reason = $reason,
+			o.updated_at = $now
+	`, map[string]any{
+		"id":         id,
+		"status":     status,
+		"payment_id": paymentID,
+		"reason":     reason,
+		"now":        now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := res.Consume(ctx); err != nil {
+		return nil, err
+	}
+
+	return readOrder(ctx, tx, id)
+}
+
+func readOrder(ctx context.Context, tx neo4j.ManagedTransaction, id string) (*pb.Order, error) {
+	res, err := tx.Run(ctx, `
+		MATCH (o:Order {id: $id})
+		OPTIONAL MATCH (o)-[:HAS_LINE]->(l:OrderLine)
+		WITH o, l
+		ORDER BY l.sku
+		RETURN o, collect(l) AS lines
+	`, map[string]any{"id": id})
+	if err != nil {
+		return nil, err
+	}
+	if !res.Next(ctx) {
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errOrderNotFound
+	}
+
+	record := res.Record()
+	node, _ := record.Values[0].(neo4j.Node)
+	lines, _ := record.Values[1].([]any)
+
+	props := node.Props
+	order := &pb.Order{
+		Id:            getString(props, "id"),
+		TenantId:      getString(props, "tenant_id"),
+		CustomerId:    getString(props, "customer_id"),
+		Currency:      getString(props, "currency"),
+		Status:        orderStatuses[getString(props, "status")],
+		PaymentId:     getString(props, "payment_id"),
+		FailureReason: getString(props, "failure_reason"),
+		CreatedAt:     getInt64(props, "created_at"),
+		UpdatedAt:     getInt64(props, "updated_at"),
+	}
+	order.Subtotal, _ = props["subtotal"].(float64)
+	order.Tax, _ = props["tax"].(float64)
+	order.Total, _ = props["total"].(float64)
+	order.RiskScore, _ = props["risk_score"].(float64)
+	order.GiftCardAmount, _ = props["gift_card_amount"].(float64)
+	if reasons, ok := props["risk_reasons"].([]any); ok {
+		for _, reason := range reasons {
+			if s, ok := reason.(string); ok {
+				order.RiskReasons = append(order.RiskReasons, s)
+			}
+		}
+	}
+
+	for _, l := range lines {
+		lineNode, ok := l.(neo4j.Node)
+		if !ok {
+			continue
+		}
+		lp := lineNode.Props
+		line := &pb.OrderLine{
+			Sku:         getString(lp, "sku"),
+			Quantity:    int32(getInt64(lp, "quantity")),
+			ProductId:   getString(lp, "product_id"),
+			ProductName: getString(lp, "product_name"),
+			TaxClass:    getString(lp, "tax_class"),
+		}
+		line.UnitPrice, _ = lp["unit_price"].(float64)
+		line.Total, _ = lp["total"].(float64)
+		line.Tax, _ = lp["tax"].(float64)
+		line.TaxRate, _ = lp["tax_rate"].(float64)
+		line.Discount, _ = lp["discount"].(float64)
+		order.Lines = append(order.Lines, line)
+	}
+	return order, nil
+}
+
+// roundCents rounds an amount to two decimal places.
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
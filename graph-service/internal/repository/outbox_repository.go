@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// OutboxEvent is a committed event waiting to be published.
+type OutboxEvent struct {
+	ID      string
+	Type    string
+	Payload []byte
+}
+
+// writeEvent records ev in the outbox as part of tx, so the event exists if
+// and only if the mutation that produced it commits. It numbers the event
+// from a single sequence node, whose write lock is held until tx commits,
+// so sequence order is commit order even when clocks or timestamps tie.
+func writeEvent(ctx context.Context, tx neo4j.ManagedTransaction, ev events.Event) error {
+	if t, ok := tx.(eventTx); ok {
+		*t.raised = true
+	}
+	payload, err := ev.Payload()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Run(ctx, `
+		MERGE (seq:OutboxSequence {id: 'events'})
+		ON CREATE SET seq.value = 0
+		SET seq.value = seq.value + 1
+		CREATE (e:Event {
+			id: $id,
+			seq: seq.value,
+			type: $type,
+			product_id: $product_id,
+			sku: $sku,
+			payload: $payload,
+			occurred_at: $occurred_at,
+			published: false,
+			published_at: 0
+		})
+	`, map[string]any{
+		"id":          ev.ID,
+		"type":        ev.Type,
+		"product_id":  ev.ProductID,
+		"sku":         ev.SKU,
+		"payload":     string(payload),
+		"occurred_at": ev.OccurredAt,
+	})
+	return err
+}
+
+// ListUnpublishedEvents returns the events the relay has not yet published,
+// in commit order. Events written before they were numbered come first.
+func (r *ProductRepository) ListUnpublishedEvents(ctx context.Context, limit int) ([]OutboxEvent, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (e:Event {published: false})
+			RETURN e.id AS id, e.type AS type, e.payload AS payload
+			ORDER BY coalesce(e.seq, 0), e.occurred_at
+			LIMIT $limit
+		`, map[string]any{"limit": limit})
+		if err != nil {
+			return nil, err
+		}
+
+		var pending []OutboxEvent
+		for res.Next(ctx) {
+			record := res.Record()
+			id, _ := record.Values[0].(string)
+			eventType, _ := record.Values[1].(string)
+			payload, _ := record.Values[2].(string)
+			pending = append(pending, OutboxEvent{
+				ID:      id,
+				Type:    eventType,
+				Payload: []byte(payload),
+			})
+		}
+		return pending, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]OutboxEvent), nil
+}
+
+func (r *ProductRepository) MarkEventPublished(ctx context.Context, id string) error {
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			MATCH (e:Event {id: $id})
+			SET e.published = true,
+				e.published_at = $now
+		`, map[string]any{
+			"id":  id,
+			"now": time.Now().UnixMilli(),
+		})
+		return nil, err
+	})
+
+	return err
+}
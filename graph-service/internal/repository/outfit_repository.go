@@ -0,0 +1,394 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// GOES_WITH links apparel products of different categories that can be
+// worn together. A pair has at most one link, in either direction, which
+// is curated by a merchandiser or inferred from orders buying both.
+const (
+	goesWithCurated  = "curated"
+	goesWithInferred = "inferred"
+
+	maxGoesWithLinks      = 50
+	defaultMinCoPurchases = 2
+	defaultOutfitItems    = 3
+	maxOutfitItems        = 8
+	outfitCandidates      = 200
+)
+
+// DefaultOutfitCategories are the main categories counted as apparel
+// unless WithOutfitCategories sets others.
+var DefaultOutfitCategories = []string{"Apparel", "Clothing", "Footwear", "Accessories", "Sportswear"}
+
+// neutralColors go with any color in an outfit.
+var neutralColors = []string{"black", "white", "grey", "gray", "navy", "beige", "cream", "denim"}
+
+// WithOutfitCategories sets the main categories whose products can be
+// linked with GOES_WITH and put in outfits, matched case-insensitively.
+func WithOutfitCategories(mainCategories []string) Option {
+	return func(r *ProductRepository) {
+		r.outfitCategories = lowerAll(mainCategories)
+	}
+}
+
+// GoesWithLink is a product linked to another by GOES_WITH. Score is how
+// many orders bought both, for inferred links.
+type GoesWithLink struct {
+	Product *pb.Product
+	Curated bool
+	Score   int
+}
+
+// OutfitItem is a product suggested to wear with an outfit's anchor, and
+// the in-stock size of it that fits.
+type OutfitItem struct {
+	Product *pb.Product
+	SKU     string
+	Curated bool
+}
+
+// SetGoesWith replaces the curated GOES_WITH links of the product with
+// links to relatedIDs, in list order. The related products must be apparel
+// of the product's tenant in other categories than it. A curated link
+// replaces an inferred one between the same pair.
+func (r *ProductRepository) SetGoesWith(ctx context.Context, productID string, relatedIDs []string) error {
+	if productID == "" {
+		return fieldErrorf("product_id", "product id is required")
+	}
+	if len(relatedIDs) > maxGoesWithLinks {
+		return fieldErrorf("related_product_ids", "at most %d products can be linked", maxGoesWithLinks)
+	}
+	seen := make(map[string]bool, len(relatedIDs))
+	for _, rid := range relatedIDs {
+		if rid == productID {
+			return fieldErrorf("related_product_ids", "a product cannot be linked to itself")
+		}
+		if seen[rid] {
+			return fieldErrorf("related_product_ids", "product %q listed more than once", rid)
+		}
+		seen[rid] = true
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		if err := checkProductsExist(ctx, tx, append([]string{productID}, relatedIDs...)); err != nil {
+			return nil, err
+		}
+
+		res, err := tx.Run(ctx, `
+			MATCH (src:Product {id: $id})
+			OPTIONAL MATCH (src)-[:BELONGS_TO]->(sc:Category)
+			RETURN toLower(coalesce(sc.main_category, '')) IN $apparel AS apparel
+		`, map[string]any{"id": productID, "apparel": r.outfitCategories})
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if apparel, _ := record.Values[0].(bool); !apparel && len(relatedIDs) > 0 {
+			return nil, fieldErrorf("product_id", "only apparel products can be linked")
+		}
+
+		res, err = tx.Run(ctx, `
+			MATCH (src:Product {id: $id})
+			OPTIONAL MATCH (src)-[:BELONGS_TO]->(sc:Category)
+			UNWIND $related_ids AS rid
+			MATCH (p:Product {id: rid})
+			OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+			WITH rid, coalesce(p.tenant_id, '') = coalesce(src.tenant_id, '') AS same_tenant,
+				toLower(coalesce(c.main_category, '')) IN $apparel AS apparel,
+				c.main_category = sc.main_category AND c.subcategory = sc.subcategory AS same_category
+			WHERE NOT same_tenant OR NOT apparel OR coalesce(same_category, false)
+			RETURN rid, same_tenant, apparel
+			LIMIT 1
+		`, map[string]any{"id": productID, "related_ids": relatedIDs, "apparel": r.outfitCategories})
+		if err != nil {
+			return nil, err
+		}
+		if res.Next(ctx) {
+			row := res.Record().AsMap()
+			rid := getString(row, "rid")
+			sameTenant, _ := row["same_tenant"].(bool)
+			apparel, _ := row["apparel"].(bool)
+			switch {
+			case !sameTenant:
+				return nil, fieldErrorf("related_product_ids", "product %q belongs to another tenant", rid)
+			case !apparel:
+				return nil, fieldErrorf("related_product_ids", "product %q is not apparel", rid)
+			default:
+				return nil, fieldErrorf("related_product_ids", "product %q is in the same category", rid)
+			}
+		}
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+
+		res, err = tx.Run(ctx, `
+			MATCH (src:Product {id: $id})
+			OPTIONAL MATCH (src)-[old:GOES_WITH]-(other:Product)
+			WHERE old.source = $curated OR other.id IN $related_ids
+			DELETE old
+			WITH DISTINCT src
+			UNWIND range(0, size($related_ids) - 1) AS i
+			MATCH (p:Product {id: $related_ids[i]})
+			CREATE (src)-[:GOES_WITH {source: $curated, position: i, created_at: $now}]->(p)
+			RETURN count(*) AS linked
+		`, map[string]any{
+			"id":          productID,
+			"related_ids": relatedIDs,
+			"curated":     goesWithCurated,
+			"now":         time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		_, err = res.Consume(ctx)
+		return nil, err
+	})
+
+	return err
+}
+
+// ListGoesWith returns the products linked to productID by GOES_WITH:
+// curated links in position order, then inferred ones by score.
+func (r *ProductRepository) ListGoesWith(ctx context.Context, productID string) ([]GoesWithLink, error) {
+	if productID == "" {
+		return nil, fieldErrorf("product_id", "product id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		if err := checkProductsExist(ctx, tx, []string{productID}); err != nil {
+			return nil, err
+		}
+
+		res, err := tx.Run(ctx, `
+			MATCH (:Product {id: $id})-[g:GOES_WITH]-(p:Product)
+			WITH p, g.source = $curated AS curated, coalesce(g.position, 0) AS position, coalesce(g.score, 0) AS score
+			OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+			OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+			RETURN p, c, collect(s) as sizes, curated, position, score
+			ORDER BY curated DESC, position, score DESC, p.id
+		`, map[string]any{"id": productID, "curated": goesWithCurated})
+		if err != nil {
+			return nil, err
+		}
+
+		links := []GoesWithLink{}
+		for res.Next(ctx) {
+			row := res.Record().AsMap()
+			curated, _ := row["curated"].(bool)
+			links = append(links, GoesWithLink{
+				Product: productFromRecord(res.Record()),
+				Curated: curated,
+				Score:   int(getInt64(row, "score")),
+			})
+		}
+		return links, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]GoesWithLink), nil
+}
+
+// InferGoesWith replaces the inferred GOES_WITH links of tenantID's
+// products, or every tenant's when it's empty, with links between apparel
+// products of different categories that at least minCoPurchases sold
+// orders bought together. Curated pairs are left as they are. It returns
+// how many links it made.
+func (r *ProductRepository) InferGoesWith(ctx context.Context, tenantID string, minCoPurchases int) (int, error) {
+	if minCoPurchases <= 0 {
+		minCoPurchases = defaultMinCoPurchases
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		params := map[string]any{
+			"tenant_id": tenantID,
+			"inferred":  goesWithInferred,
+			"apparel":   r.outfitCategories,
+			"sold":      soldOrderStatuses,
+			"min":       minCoPurchases,
+			"now":       time.Now().UnixMilli(),
+		}
+
+		res, err := tx.Run(ctx, `
+			MATCH (a:Product)-[g:GOES_WITH {source: $inferred}]->(:Product)
+			WHERE $tenant_id = '' OR a.tenant_id = $tenant_id
+			DELETE g
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := res.Consume(ctx); err != nil {
+			return nil, err
+		}
+
+		res, err = tx.Run(ctx, `
+			MATCH (a:Product)-[:HAS_SIZE]->(:Size)<-[:OF_SIZE]-(:OrderLine)<-[:HAS_LINE]-(o:Order)
+				-[:HAS_LINE]->(:OrderLine)-[:OF_SIZE]->(:Size)<-[:HAS_SIZE]-(b:Product)
+			WHERE a.id < b.id AND o.status IN $sold
+				AND ($tenant_id = '' OR a.tenant_id = $tenant_id)
+				AND coalesce(a.tenant_id, '') = coalesce(b.tenant_id, '')
+			WITH a, b, count(DISTINCT o) AS orders
+			WHERE orders >= $min
+			MATCH (a)-[:BELONGS_TO]->(ca:Category), (b)-[:BELONGS_TO]->(cb:Category)
+			WHERE toLower(ca.main_category) IN $apparel AND toLower(cb.main_category) IN $apparel
+				AND (ca.main_category <> cb.main_category OR ca.subcategory <> cb.subcategory)
+				AND NOT `+r.dialect.exists("(a)-[:GOES_WITH]-(b)")+`
+			CREATE (a)-[:GOES_WITH {source: $inferred, score: orders, created_at: $now}]->(b)
+			RETURN count(*) AS linked
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return int(getInt64(record.AsMap(), "linked")), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.(int), nil
+}
+
+// GetOutfitSuggestions puts together up to items products to wear with
+// productID, at most one per category and none from its own. Candidates
+// are products up to two GOES_WITH links away, nearest first, then
+// curated, then most bought together. Each must have an in-stock size,
+// one of sizes when given, and a color in colors, or the anchor's color
+// when colors is empty, or a neutral one.
+func (r *ProductRepository) GetOutfitSuggestions(ctx context.Context, productID string, sizes, colors []string, items int) (*pb.Product, []OutfitItem, error) {
+	if productID == "" {
+		return nil, nil, fieldErrorf("product_id", "product id is required")
+	}
+	if items <= 0 {
+		items = defaultOutfitItems
+	}
+	if items > maxOutfitItems {
+		return nil, nil, fieldErrorf("items", "at most %d items can be suggested", maxOutfitItems)
+	}
+	sizes, colors = lowerAll(sizes), lowerAll(colors)
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	type outfit struct {
+		anchor *pb.Product
+		items  []OutfitItem
+	}
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (p:Product {id: $id})
+			OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+			OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+			RETURN p, c, collect(s) as sizes
+		`, map[string]any{"id": productID})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, notFoundf("product not found")
+		}
+		anchor := productFromRecord(res.Record())
+		if !slices.Contains(r.outfitCategories, strings.ToLower(anchor.GetCategory().GetMainCategory())) {
+			return nil, fieldErrorf("product_id", "outfits are only suggested for apparel")
+		}
+
+		palette := colors
+		if len(palette) == 0 && anchor.Color != "" {
+			palette = []string{strings.ToLower(anchor.Color)}
+		}
+		res, err = tx.Run(ctx, `
+			MATCH path = (src:Product {id: $id})-[:GOES_WITH*1..2]-(p:Product)
+			WHERE p.id <> src.id
+				AND coalesce(p.tenant_id, '') = coalesce(src.tenant_id, '')
+				AND ($palette = [] OR coalesce(p.color, '') = ''
+					OR toLower(p.color) IN $palette OR toLower(p.color) IN $neutral)
+				AND any(s IN [(p)-[:HAS_SIZE]->(size:Size) | size]
+					WHERE s.in_stock AND ($sizes = [] OR toLower(s.size) IN $sizes))
+			WITH p, length(path) AS hops,
+				all(g IN relationships(path) WHERE g.source = $curated) AS curated,
+				reduce(score = 0, g IN relationships(path) | score + coalesce(g.score, 0)) AS score
+			ORDER BY hops, curated DESC, score DESC
+			WITH p, head(collect({hops: hops, curated: curated, score: score})) AS best
+			ORDER BY best.hops, best.curated DESC, best.score DESC, p.id
+			LIMIT $candidates
+			OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+			OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+			RETURN p, c, collect(s) as sizes, best.curated AS curated
+			ORDER BY best.hops, best.curated DESC, best.score DESC, p.id
+		`, map[string]any{
+			"id":         productID,
+			"palette":    palette,
+			"neutral":    neutralColors,
+			"sizes":      sizes,
+			"curated":    goesWithCurated,
+			"candidates": outfitCandidates,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		o := &outfit{anchor: anchor}
+		taken := map[string]bool{outfitSlot(anchor): true}
+		for res.Next(ctx) && len(o.items) < items {
+			p := productFromRecord(res.Record())
+			slot := outfitSlot(p)
+			if taken[slot] || !slices.Contains(r.outfitCategories, strings.ToLower(p.GetCategory().GetMainCategory())) {
+				continue
+			}
+			taken[slot] = true
+			curated, _ := res.Record().AsMap()["curated"].(bool)
+			o.items = append(o.items, OutfitItem{Product: p, SKU: fittingSKU(p, sizes), Curated: curated})
+		}
+		return o, res.Err()
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	o := result.(*outfit)
+	return o.anchor, o.items, nil
+}
+
+// outfitSlot is the category an outfit has one product of.
+func outfitSlot(p *pb.Product) string {
+	c := p.GetCategory()
+	return strings.ToLower(c.GetMainCategory()) + "\x00" + strings.ToLower(c.GetSubcategory())
+}
+
+// fittingSKU is p's first in-stock size that is one of sizes, or any
+// in-stock size when sizes is empty.
+func fittingSKU(p *pb.Product, sizes []string) string {
+	for _, s := range p.Sizes {
+		if s.InStock && (len(sizes) == 0 || slices.Contains(sizes, strings.ToLower(s.Size))) {
+			return s.Sku
+		}
+	}
+	return ""
+}
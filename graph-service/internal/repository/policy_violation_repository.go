@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Policy violations are recorded against the seller that committed them,
+// (:Seller)-[:VIOLATED]->(:PolicyViolation), and raise a policy violation
+// event for the seller.
+
+const (
+	maxPolicyLen           = 64
+	maxViolationDetailsLen = 2048
+)
+
+// PolicyViolation is a marketplace policy a seller broke, optionally with
+// one of its offers.
+type PolicyViolation struct {
+	ID         string
+	SellerID   string
+	SKU        string
+	ProductID  string
+	Policy     string
+	Details    string
+	ReportedBy string
+	CreatedAt  int64
+}
+
+// RecordPolicyViolation stores v with a new id. When it names a SKU, the
+// seller must offer it.
+func (r *ProductRepository) RecordPolicyViolation(ctx context.Context, v PolicyViolation) (*PolicyViolation, error) {
+	if v.SellerID == "" {
+		return nil, fieldErrorf("seller_id", "seller id is required")
+	}
+	if v.Policy == "" {
+		return nil, fieldErrorf("policy", "policy is required")
+	}
+	if len(v.Policy) > maxPolicyLen {
+		return nil, &LimitError{Field: "policy", Limit: maxPolicyLen, Actual: len(v.Policy)}
+	}
+	if len(v.Details) > maxViolationDetailsLen {
+		return nil, &LimitError{Field: "details", Limit: maxViolationDetailsLen, Actual: len(v.Details)}
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	v.ID = newID()
+	v.CreatedAt = time.Now().UnixMilli()
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			OPTIONAL MATCH (sel:Seller {id: $seller_id})
+			OPTIONAL MATCH (sel)-[:OFFERS]->(:Size {sku: $sku})<-[:HAS_SIZE]-(p:Product)
+			WITH sel, p
+			WHERE sel IS NOT NULL AND ($sku = '' OR p IS NOT NULL)
+			CREATE (sel)-[:VIOLATED]->(:PolicyViolation {
+				id: $id,
+				seller_id: $seller_id,
+				sku: $sku,
+				product_id: coalesce(p.id, ''),
+				policy: $policy,
+				details: $details,
+				reported_by: $reported_by,
+				created_at: $now
+			})
+			RETURN coalesce(p.id, '') AS product_id
+		`, map[string]any{
+			"id":          v.ID,
+			"seller_id":   v.SellerID,
+			"sku":         v.SKU,
+			"policy":      v.Policy,
+			"details":     v.Details,
+			"reported_by": v.ReportedBy,
+			"now":         v.CreatedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			if v.SKU == "" {
+				return nil, errSellerNotFound
+			}
+			return nil, notFoundf("seller %s has no offer for sku %s", v.SellerID, v.SKU)
+		}
+		v.ProductID = getString(res.Record().AsMap(), "product_id")
+
+		ev := events.New(events.SellerPolicyViolation, v.ProductID, map[string]any{
+			"id":      v.ID,
+			"policy":  v.Policy,
+			"details": v.Details,
+		})
+		ev.SKU, ev.SellerID = v.SKU, v.SellerID
+		return nil, writeEvent(ctx, tx, ev)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
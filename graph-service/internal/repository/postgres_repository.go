@@ -0,0 +1,1016 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/slug"
+)
+
+// postgresSchema models the product graph with join tables: a product
+// belongs to one shared category row and owns its size and translation
+// rows. Every statement is idempotent, so EnsureSchema runs it at startup.
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS products (
+	id             text PRIMARY KEY,
+	name           text NOT NULL,
+	brand          text NOT NULL,
+	color          text NOT NULL DEFAULT '',
+	price          double precision NOT NULL DEFAULT 0,
+	original_price double precision NOT NULL DEFAULT 0,
+	description    text NOT NULL DEFAULT '',
+	tags           text[] NOT NULL DEFAULT '{}',
+	images         text[] NOT NULL DEFAULT '{}',
+	attributes     jsonb NOT NULL DEFAULT '{}',
+	tenant_id      text NOT NULL DEFAULT '',
+	created_at     bigint NOT NULL,
+	updated_at     bigint NOT NULL
+);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS slug text;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS gtin text;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS external_id text;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS weight_grams integer;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS length_mm integer;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS width_mm integer;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS height_mm integer;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_class text;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS archived boolean NOT NULL DEFAULT false;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS meta_title text;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS meta_description text;
+CREATE UNIQUE INDEX IF NOT EXISTS products_slug_key ON products (slug);
+CREATE UNIQUE INDEX IF NOT EXISTS products_gtin_key ON products (gtin);
+CREATE UNIQUE INDEX IF NOT EXISTS products_external_id_key ON products (external_id);
+CREATE INDEX IF NOT EXISTS products_updated_at ON products (updated_at, id);
+CREATE INDEX IF NOT EXISTS products_created_at ON products (created_at, id);
+CREATE INDEX IF NOT EXISTS products_active ON products (id) WHERE NOT archived;
+
+CREATE TABLE IF NOT EXISTS categories (
+	id            bigserial PRIMARY KEY,
+	main_category text NOT NULL,
+	subcategory   text NOT NULL,
+	specific_type text NOT NULL,
+	created_at    bigint NOT NULL,
+	updated_at    bigint NOT NULL,
+	UNIQUE (main_category, subcategory, specific_type)
+);
+
+CREATE TABLE IF NOT EXISTS product_categories (
+	product_id  text NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+	category_id bigint NOT NULL REFERENCES categories (id),
+	PRIMARY KEY (product_id, category_id)
+);
+
+CREATE TABLE IF NOT EXISTS sizes (
+	id         bigserial PRIMARY KEY,
+	product_id text NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+	sku        text NOT NULL,
+	size       text NOT NULL,
+	stock      integer NOT NULL DEFAULT 0,
+	in_stock   boolean NOT NULL DEFAULT false,
+	variants   text[] NOT NULL DEFAULT '{}',
+	created_at bigint NOT NULL,
+	updated_at bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS sizes_product_id ON sizes (product_id);
+CREATE UNIQUE INDEX IF NOT EXISTS sizes_sku_key ON sizes (sku);
+DROP INDEX IF EXISTS sizes_sku;
+
+CREATE TABLE IF NOT EXISTS product_translations (
+	product_id  text NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+	locale      text NOT NULL,
+	name        text NOT NULL DEFAULT '',
+	description text NOT NULL DEFAULT '',
+	created_at  bigint NOT NULL,
+	updated_at  bigint NOT NULL,
+	PRIMARY KEY (product_id, locale)
+);
+`
+
+const productColumns = `p.id, p.name, p.brand, p.color, p.price, p.original_price, p.description,
+	p.tags, p.images, p.attributes, p.tenant_id, p.created_at, p.updated_at,
+	coalesce(p.slug, ''), coalesce(p.gtin, ''), coalesce(p.external_id, ''), coalesce(p.tax_class, ''),
+	p.weight_grams, p.length_mm, p.width_mm, p.height_mm, p.archived,
+	coalesce(p.meta_title, ''), coalesce(p.meta_description, '')`
+
+// PostgresRepository stores products in Postgres for deployments that
+// can't run Neo4j. It does not write outbox events, so webhooks and saved
+// search alerts are unavailable on this backend.
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// EnsureSchema creates the tables and indexes if they don't exist and gives
+// products without a slug one.
+func (r *PostgresRepository) EnsureSchema(ctx context.Context) error {
+	if _, err := r.pool.Exec(ctx, postgresSchema); err != nil {
+		return err
+	}
+	return r.backfillSlugs(ctx)
+}
+
+// backfillSlugs is ProductRepository.backfillSlugs for the products table.
+func (r *PostgresRepository) backfillSlugs(ctx context.Context) error {
+	for {
+		var updated int
+		err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+			rows, err := tx.Query(ctx, `
+				SELECT id, name, brand FROM products
+				WHERE slug IS NULL
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			`, slugBackfillBatch)
+			if err != nil {
+				return err
+			}
+			products, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*pb.Product, error) {
+				p := &pb.Product{}
+				return p, row.Scan(&p.Id, &p.Name, &p.Brand)
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, p := range products {
+				handle, err := nextFreePostgresSlug(ctx, tx, baseSlug(p))
+				if err != nil {
+					return err
+				}
+				if _, err := tx.Exec(ctx, `UPDATE products SET slug = $2 WHERE id = $1`, p.Id, handle); err != nil {
+					return err
+				}
+			}
+			updated = len(products)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if updated < slugBackfillBatch {
+			return nil
+		}
+	}
+}
+
+func (r *PostgresRepository) CreateProduct(ctx context.Context, p *pb.Product) error {
+	// Validate required fields
+	if p.Id == "" {
+		return fieldErrorf("product.id", "product id is required")
+	}
+	if p.Name == "" {
+		return fieldErrorf("product.name", "product name is required")
+	}
+	if p.Brand == "" {
+		return fieldErrorf("product.brand", "product brand is required")
+	}
+	if err := checkProductLimits(p); err != nil {
+		return err
+	}
+	if err := checkSizes("product.sizes", p.Sizes); err != nil {
+		return err
+	}
+	if err := checkIdentifiers(p); err != nil {
+		return err
+	}
+	if err := checkShipping(p); err != nil {
+		return err
+	}
+
+	attributesJSON, err := json.Marshal(p.Attributes)
+	if err != nil {
+		return fmt.Errorf("failed to serialize attributes: %w", err)
+	}
+
+	now := time.Now().UnixMilli()
+	p.CreatedAt, p.UpdatedAt = now, now
+
+	// A generated slug can lose a race for the same handle with another
+	// product; generate the next free one and try again.
+	generateSlug := p.Slug == ""
+	for attempt := 1; ; attempt++ {
+		err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+			if generateSlug {
+				handle, err := nextFreePostgresSlug(ctx, tx, baseSlug(p))
+				if err != nil {
+					return err
+				}
+				p.Slug = handle
+			}
+			if err := findPostgresProductConflict(ctx, tx, p); err != nil {
+				return err
+			}
+
+			args := []any{p.Id, p.Name, p.Brand, p.Color, p.Price, p.OriginalPrice, p.Description,
+				nonNil(p.Tags), nonNil(p.Images), attributesJSON, p.TenantId,
+				nullIfEmpty(p.Slug), nullIfEmpty(p.Gtin), nullIfEmpty(p.ExternalId), nullIfEmpty(p.TaxClass)}
+			args = append(args, shippingColumns(p.Shipping)...)
+			args = append(args, nullIfEmpty(p.MetaTitle), nullIfEmpty(p.MetaDescription))
+
+			_, err := tx.Exec(ctx, `
+				INSERT INTO products (id, name, brand, color, price, original_price, description,
+					tags, images, attributes, tenant_id, slug, gtin, external_id, tax_class,
+					weight_grams, length_mm, width_mm, height_mm, meta_title, meta_description,
+					created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19,
+					$20, $21, $22, $22)
+			`, append(args, now)...)
+			if err != nil {
+				return err
+			}
+
+			if err := linkPostgresCategory(ctx, tx, p, now); err != nil {
+				return err
+			}
+
+			for _, size := range p.Sizes {
+				_, err := tx.Exec(ctx, `
+					INSERT INTO sizes (product_id, sku, size, stock, in_stock, variants, created_at, updated_at)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+				`, p.Id, size.Sku, size.Size, size.Stock, size.InStock, nonNil(size.Variants), now)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			if pgErr.ConstraintName == "products_pkey" {
+				return fieldErrorf("product.id", "product %q already exists", p.Id)
+			}
+			err = r.resolveConflict(ctx, err, func(q postgresQuerier) error {
+				return findPostgresProductConflict(ctx, q, p)
+			})
+		}
+
+		var conflict *ConflictError
+		if generateSlug && attempt < maxSlugAttempts && errors.As(err, &conflict) && conflict.Field == IdentifierSlug {
+			continue
+		}
+		return err
+	}
+}
+
+func (r *PostgresRepository) GetProduct(ctx context.Context, id string) (*pb.Product, error) {
+	if id == "" {
+		return nil, fieldErrorf("id", "product id is required")
+	}
+
+	products, err := r.queryProducts(ctx, `
+		SELECT `+productColumns+`
+		FROM products p
+		WHERE p.id = $1
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return nil, notFoundf("product not found")
+	}
+
+	return products[0], nil
+}
+
+// ListProducts returns up to limit products ordered by id, starting after afterID.
+func (r *PostgresRepository) ResolveProduct(ctx context.Context, kind, value string) (*pb.Product, string, error) {
+	kinds, err := resolveKinds(kind, value)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, k := range kinds {
+		lookup, ok := normalizeIdentifier(k, value)
+		if !ok {
+			continue
+		}
+
+		// k comes from identifierKinds, never from the caller
+		products, err := r.queryProducts(ctx, `
+			SELECT `+productColumns+`
+			FROM products p
+			WHERE p.`+k+` = $1
+		`, lookup)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(products) > 0 {
+			return products[0], k, nil
+		}
+	}
+	return nil, "", notFoundf("no product has %s %q", describeKinds(kind), value)
+}
+
+func (r *PostgresRepository) ListProducts(ctx context.Context, tenantID, afterID string, limit int, includeArchive bool) ([]*pb.Product, error) {
+	return r.queryProducts(ctx, `
+		SELECT `+productColumns+`
+		FROM products p
+		WHERE ($4 = '' OR p.tenant_id = $4)
+			AND ($1 = '' OR p.id > $1) AND ($3 OR NOT p.archived)
+		ORDER BY p.id
+		LIMIT $2
+	`, afterID, limit, includeArchive, tenantID)
+}
+
+// SetProductsArchived is ProductRepository.SetProductsArchived for the
+// products table, without the events.
+func (r *PostgresRepository) SetProductsArchived(ctx context.Context, ids []string, archived bool) (int, error) {
+	if len(ids) == 0 {
+		return 0, fieldErrorf("product_ids", "at least one product id is required")
+	}
+
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE products
+		SET archived = $2, updated_at = $3
+		WHERE id = ANY($1) AND archived <> $2
+	`, ids, archived, time.Now().UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// ProductTenants returns the tenant of each product named in ids or
+// owning a SKU in skus, by product id. Products that don't exist are left
+// out.
+func (r *PostgresRepository) ProductTenants(ctx context.Context, ids, skus []string) (map[string]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT p.id, p.tenant_id
+		FROM products p
+		WHERE p.id = ANY($1)
+			OR p.id IN (SELECT product_id FROM sizes WHERE sku = ANY($2))
+	`, nonNil(ids), nonNil(skus))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenants := map[string]string{}
+	for rows.Next() {
+		var id, tenant string
+		if err := rows.Scan(&id, &tenant); err != nil {
+			return nil, err
+		}
+		tenants[id] = tenant
+	}
+	return tenants, rows.Err()
+}
+
+// ListProductsUpdatedSince returns products changed at or after since, of
+// tenantID's unless it is empty, ordered by (updated_at, id) and resuming
+// after the cursor.
+func (r *PostgresRepository) ListProductsUpdatedSince(ctx context.Context, tenantID string, since int64, after pagetoken.Cursor, limit int) ([]*pb.Product, error) {
+	afterTime := since
+	if after.LastID != "" {
+		afterTime = after.LastTime
+	}
+
+	return r.queryProducts(ctx, `
+		SELECT `+productColumns+`
+		FROM products p
+		WHERE ($4 = '' OR p.tenant_id = $4)
+			AND (p.updated_at > $1
+				OR (p.updated_at = $1 AND p.id > $2))
+		ORDER BY p.updated_at, p.id
+		LIMIT $3
+	`, afterTime, after.LastID, limit, tenantID)
+}
+
+// GetNewArrivals returns in-stock products created within maxAge, newest
+// first, resuming after the cursor.
+func (r *PostgresRepository) GetNewArrivals(ctx context.Context, filter *pb.CatalogFilter, maxAge time.Duration, after pagetoken.Cursor, limit int) ([]*pb.Product, error) {
+	args := []any{time.Now().Add(-maxAge).UnixMilli(), after.LastTime, after.LastID, limit}
+
+	return r.queryProducts(ctx, `
+		SELECT `+productColumns+`
+		FROM products p
+		WHERE p.created_at >= $1
+			AND `+postgresFilterClause(filter, &args)+`
+			AND EXISTS (SELECT 1 FROM sizes st WHERE st.product_id = p.id AND st.in_stock)
+			AND ($3 = ''
+				OR p.created_at < $2
+				OR (p.created_at = $2 AND p.id < $3))
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT $4
+	`, args...)
+}
+
+// GetDeals returns discounted products ordered by discount percentage
+// (highest first), resuming after the cursor.
+func (r *PostgresRepository) GetDeals(ctx context.Context, filter *pb.CatalogFilter, after pagetoken.Cursor, limit int) ([]*pb.Deal, error) {
+	args := []any{after.LastRank, after.LastID, limit}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+productColumns+`, d.discount
+		FROM products p
+		CROSS JOIN LATERAL (
+			SELECT (p.original_price - p.price) * 100.0 / p.original_price AS discount
+		) d
+		WHERE p.original_price > p.price
+			AND `+postgresFilterClause(filter, &args)+`
+			AND ($2 = ''
+				OR d.discount < $1
+				OR (d.discount = $1 AND p.id > $2))
+		ORDER BY d.discount DESC, p.id
+		LIMIT $3
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	deals := []*pb.Deal{}
+	products := []*pb.Product{}
+	for rows.Next() {
+		deal := &pb.Deal{}
+		product, err := scanProduct(rows, &deal.DiscountPercent)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		deal.Product = product
+		deals = append(deals, deal)
+		products = append(products, product)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadProductDetails(ctx, products); err != nil {
+		return nil, err
+	}
+	return deals, nil
+}
+
+func (r *PostgresRepository) UpdateProduct(ctx context.Context, p *pb.Product) ([]*pb.FieldChange, error) {
+	if err := checkProductLimits(p); err != nil {
+		return nil, err
+	}
+	if err := checkIdentifiers(p); err != nil {
+		return nil, err
+	}
+	if err := checkShipping(p); err != nil {
+		return nil, err
+	}
+
+	attributesJSON, err := json.Marshal(p.Attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize attributes: %w", err)
+	}
+
+	now := time.Now().UnixMilli()
+	p.UpdatedAt = now
+
+	var changes []*pb.FieldChange
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if err := findPostgresIdentifierConflict(ctx, tx, p); err != nil {
+			return err
+		}
+		old, err := lockPostgresProduct(ctx, tx, p.Id)
+		if err != nil {
+			return err
+		}
+		changes = productChanges(old, p)
+
+		args := []any{p.Id, p.Name, p.Brand, p.Color, p.Price, p.OriginalPrice, p.Description,
+			nonNil(p.Tags), nonNil(p.Images), attributesJSON,
+			nullIfEmpty(p.Slug), nullIfEmpty(p.Gtin), nullIfEmpty(p.ExternalId), nullIfEmpty(p.TaxClass)}
+		args = append(args, shippingColumns(p.Shipping)...)
+		args = append(args, nullIfEmpty(p.MetaTitle), nullIfEmpty(p.MetaDescription))
+
+		// Unset identifiers, tax class, shipping profile, SEO metadata and
+		// category keep their stored value
+		tag, err := tx.Exec(ctx, `
+			UPDATE products
+			SET name = $2,
+				brand = $3,
+				color = $4,
+				price = $5,
+				original_price = $6,
+				description = $7,
+				tags = $8,
+				images = $9,
+				attributes = $10,
+				slug = coalesce($11, slug),
+				gtin = coalesce($12, gtin),
+				external_id = coalesce($13, external_id),
+				tax_class = coalesce($14, tax_class),
+				weight_grams = coalesce($15, weight_grams),
+				length_mm = coalesce($16, length_mm),
+				width_mm = coalesce($17, width_mm),
+				height_mm = coalesce($18, height_mm),
+				meta_title = coalesce($19, meta_title),
+				meta_description = coalesce($20, meta_description),
+				updated_at = $21
+			WHERE id = $1
+		`, append(args, now)...)
+		if err != nil || p.Category == nil || tag.RowsAffected() == 0 {
+			return err
+		}
+		return linkPostgresCategory(ctx, tx, p, now)
+	})
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return nil, r.resolveConflict(ctx, err, func(q postgresQuerier) error {
+			return findPostgresIdentifierConflict(ctx, q, p)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// lockPostgresProduct is lockProduct for the products table. The product
+// comes without its sizes.
+func lockPostgresProduct(ctx context.Context, tx pgx.Tx, id string) (*pb.Product, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT `+productColumns+`
+		FROM products p
+		WHERE p.id = $1
+		FOR UPDATE
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	var product *pb.Product
+	if rows.Next() {
+		product, err = scanProduct(rows)
+	}
+	rows.Close()
+	if err == nil {
+		err = rows.Err()
+	}
+	if err != nil || product == nil {
+		return nil, err
+	}
+
+	category := &pb.ProductCategory{}
+	err = tx.QueryRow(ctx, `
+		SELECT c.main_category, c.subcategory, c.specific_type
+		FROM product_categories pc
+		JOIN categories c ON c.id = pc.category_id
+		WHERE pc.product_id = $1
+	`, id).Scan(&category.MainCategory, &category.Subcategory, &category.SpecificType)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return product, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	product.Category = category
+	return product, nil
+}
+
+// linkPostgresCategory links the product to its category, creating the
+// category row if needed, and unlinks it from any other.
+func linkPostgresCategory(ctx context.Context, tx pgx.Tx, p *pb.Product, now int64) error {
+	// Category rows are shared, like the MERGEd Category nodes
+	_, err := tx.Exec(ctx, `
+		WITH c AS (
+			INSERT INTO categories (main_category, subcategory, specific_type, created_at, updated_at)
+			VALUES ($2, $3, $4, $5, $5)
+			ON CONFLICT (main_category, subcategory, specific_type)
+			DO UPDATE SET main_category = EXCLUDED.main_category
+			RETURNING id
+		),
+		unlinked AS (
+			DELETE FROM product_categories
+			WHERE product_id = $1 AND category_id NOT IN (SELECT id FROM c)
+		)
+		INSERT INTO product_categories (product_id, category_id)
+		SELECT $1, id FROM c
+		ON CONFLICT DO NOTHING
+	`, p.Id, p.GetCategory().GetMainCategory(), p.GetCategory().GetSubcategory(),
+		p.GetCategory().GetSpecificType(), now)
+	return err
+}
+
+func (r *PostgresRepository) DeleteProduct(ctx context.Context, id string) error {
+	if id == "" {
+		return fieldErrorf("id", "product id is required")
+	}
+
+	// Sizes, translations and the category link cascade
+	_, err := r.pool.Exec(ctx, `DELETE FROM products WHERE id = $1`, id)
+	return err
+}
+
+func (r *PostgresRepository) UpdateStock(ctx context.Context, sku string, stock int32) error {
+	if sku == "" {
+		return fieldErrorf("sku", "sku is required")
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		WITH s AS (
+			UPDATE sizes
+			SET stock = $2,
+				in_stock = $2 > 0,
+				updated_at = $3
+			WHERE sku = $1
+			RETURNING product_id
+		)
+		UPDATE products
+		SET updated_at = $3
+		WHERE id IN (SELECT product_id FROM s)
+	`, sku, stock, time.Now().UnixMilli())
+
+	return err
+}
+
+func (r *PostgresRepository) AddProductSize(ctx context.Context, productID string, size *pb.ProductSize) error {
+	if productID == "" {
+		return fieldErrorf("product_id", "product id is required")
+	}
+	if err := checkSizes("size", []*pb.ProductSize{size}); err != nil {
+		return err
+	}
+
+	now := time.Now().UnixMilli()
+
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var n int
+		err := tx.QueryRow(ctx, `
+			SELECT count(s.id)
+			FROM products p
+			LEFT JOIN sizes s ON s.product_id = p.id
+			WHERE p.id = $1
+			GROUP BY p.id
+		`, productID).Scan(&n)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return notFoundf("product not found")
+		}
+		if err != nil {
+			return err
+		}
+		if n >= maxProductSizes {
+			return &LimitError{Field: "product.sizes", Limit: maxProductSizes, Actual: n + 1}
+		}
+
+		if err := findPostgresSkuConflict(ctx, tx, []string{size.Sku}); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO sizes (product_id, sku, size, stock, in_stock, variants, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		`, productID, size.Sku, size.Size, size.Stock, size.InStock, nonNil(size.Variants), now)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `UPDATE products SET updated_at = $2 WHERE id = $1`, productID, now)
+		return err
+	})
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return r.resolveConflict(ctx, err, func(q postgresQuerier) error {
+			return findPostgresSkuConflict(ctx, q, []string{size.Sku})
+		})
+	}
+	return err
+}
+
+func (r *PostgresRepository) UpsertProductTranslation(ctx context.Context, productID, locale, name, description string) error {
+	locale = normalizeLocale(locale)
+	if productID == "" || locale == "" {
+		return fieldErrorf("locale", "product id and locale are required")
+	}
+	if name == "" && description == "" {
+		return fieldErrorf("name", "translation needs a name or a description")
+	}
+
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO product_translations (product_id, locale, name, description, created_at, updated_at)
+		SELECT id, $2, $3, $4, $5, $5 FROM products WHERE id = $1
+		ON CONFLICT (product_id, locale)
+		DO UPDATE SET name = EXCLUDED.name,
+			description = EXCLUDED.description,
+			updated_at = EXCLUDED.updated_at
+	`, productID, locale, name, description, time.Now().UnixMilli())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return notFoundf("product not found")
+	}
+
+	return nil
+}
+
+// LocalizeProducts overlays translated names and descriptions onto products
+// in place, with the same fallback rules as the Neo4j implementation.
+func (r *PostgresRepository) LocalizeProducts(ctx context.Context, products []*pb.Product, locale string) error {
+	chain := localeChain(locale)
+	if len(chain) == 0 || len(products) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*pb.Product, len(products))
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		byID[p.Id] = p
+		ids = append(ids, p.Id)
+	}
+
+	// Most specific locale first for each product
+	rows, err := r.pool.Query(ctx, `
+		SELECT product_id, locale, name, description
+		FROM product_translations
+		WHERE product_id = ANY($1) AND locale = ANY($2)
+		ORDER BY product_id, length(locale) DESC
+	`, ids, chain)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	nameSet := map[string]bool{}
+	descSet := map[string]bool{}
+	for rows.Next() {
+		var id, tLocale, name, description string
+		if err := rows.Scan(&id, &tLocale, &name, &description); err != nil {
+			return err
+		}
+		p := byID[id]
+		if !nameSet[id] && name != "" {
+			p.Name, nameSet[id] = name, true
+			p.Locale = tLocale
+		}
+		if !descSet[id] && description != "" {
+			p.Description, descSet[id] = description, true
+			if p.Locale == "" {
+				p.Locale = tLocale
+			}
+		}
+	}
+
+	return rows.Err()
+}
+
+// queryProducts runs a query selecting productColumns and returns the
+// products in row order with their categories and sizes.
+func (r *PostgresRepository) queryProducts(ctx context.Context, query string, args ...any) ([]*pb.Product, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	products := []*pb.Product{}
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadProductDetails(ctx, products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// loadProductDetails fills in categories and sizes for products.
+func (r *PostgresRepository) loadProductDetails(ctx context.Context, products []*pb.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*pb.Product, len(products))
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		byID[p.Id] = p
+		ids = append(ids, p.Id)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT pc.product_id, c.main_category, c.subcategory, c.specific_type
+		FROM product_categories pc
+		JOIN categories c ON c.id = pc.category_id
+		WHERE pc.product_id = ANY($1)
+	`, ids)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id string
+		category := &pb.ProductCategory{}
+		if err := rows.Scan(&id, &category.MainCategory, &category.Subcategory, &category.SpecificType); err != nil {
+			rows.Close()
+			return err
+		}
+		byID[id].Category = category
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = r.pool.Query(ctx, `
+		SELECT product_id, sku, size, stock, in_stock, variants, created_at, updated_at
+		FROM sizes
+		WHERE product_id = ANY($1)
+		ORDER BY product_id, id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id string
+		size := &pb.ProductSize{}
+		err := rows.Scan(&id, &size.Sku, &size.Size, &size.Stock, &size.InStock, &size.Variants,
+			&size.CreatedAt, &size.UpdatedAt)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		p := byID[id]
+		p.Sizes = append(p.Sizes, size)
+	}
+	return rows.Err()
+}
+
+// scanProduct scans productColumns, followed by extra destinations, from
+// the current row.
+func scanProduct(rows pgx.Rows, extra ...any) (*pb.Product, error) {
+	var product pb.Product
+	var attributes []byte
+	var weight, length, width, height *int32
+
+	dest := []any{
+		&product.Id, &product.Name, &product.Brand, &product.Color, &product.Price,
+		&product.OriginalPrice, &product.Description, &product.Tags, &product.Images,
+		&attributes, &product.TenantId, &product.CreatedAt, &product.UpdatedAt,
+		&product.Slug, &product.Gtin, &product.ExternalId, &product.TaxClass,
+		&weight, &length, &width, &height, &product.Archived,
+		&product.MetaTitle, &product.MetaDescription,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if weight != nil {
+		product.Shipping = &pb.ShippingProfile{
+			WeightGrams: *weight,
+			LengthMm:    valueOrZero(length),
+			WidthMm:     valueOrZero(width),
+			HeightMm:    valueOrZero(height),
+		}
+	}
+
+	product.Attributes = make(map[string]string)
+	json.Unmarshal(attributes, &product.Attributes)
+
+	return &product, nil
+}
+
+// postgresFilterClause is catalogFilterClause for the products table
+// aliased p, appending values to args as positional parameters.
+func postgresFilterClause(f *pb.CatalogFilter, args *[]any) string {
+	param := func(v any) string {
+		*args = append(*args, v)
+		return fmt.Sprintf("$%d", len(*args))
+	}
+
+	var conds []string
+
+	if !f.GetIncludeArchive() {
+		conds = append(conds, "NOT p.archived")
+	}
+	if f.GetTenantId() != "" {
+		conds = append(conds, "p.tenant_id = "+param(f.GetTenantId()))
+	}
+
+	var categoryConds []string
+	if f.GetMainCategory() != "" {
+		categoryConds = append(categoryConds, "fc.main_category = "+param(f.GetMainCategory()))
+	}
+	if f.GetSubcategory() != "" {
+		categoryConds = append(categoryConds, "fc.subcategory = "+param(f.GetSubcategory()))
+	}
+	if f.GetSpecificType() != "" {
+		categoryConds = append(categoryConds, "fc.specific_type = "+param(f.GetSpecificType()))
+	}
+	if len(categoryConds) > 0 {
+		conds = append(conds, `EXISTS (
+			SELECT 1 FROM product_categories fpc
+			JOIN categories fc ON fc.id = fpc.category_id
+			WHERE fpc.product_id = p.id AND `+strings.Join(categoryConds, " AND ")+`)`)
+	}
+
+	if len(conds) == 0 {
+		return "true"
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// nonNil keeps nil slices from being written as NULL into NOT NULL arrays.
+// postgresQuerier is satisfied by both the pool and a transaction.
+type postgresQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// findPostgresSkuConflict is findSkuConflict for the sizes table.
+func findPostgresSkuConflict(ctx context.Context, q postgresQuerier, skus []string) error {
+	conflict := ConflictError{Field: "sku"}
+	err := q.QueryRow(ctx, `
+		SELECT sku, product_id FROM sizes WHERE sku = ANY($1) LIMIT 1
+	`, skus).Scan(&conflict.Value, &conflict.ProductID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return &conflict
+}
+
+// findPostgresIdentifierConflict is findIdentifierConflict for the
+// products table.
+func findPostgresIdentifierConflict(ctx context.Context, q postgresQuerier, p *pb.Product) error {
+	if p.Slug == "" && p.Gtin == "" && p.ExternalId == "" {
+		return nil
+	}
+
+	var id, slug, gtin, externalID string
+	err := q.QueryRow(ctx, `
+		SELECT id, coalesce(slug, ''), coalesce(gtin, ''), coalesce(external_id, '')
+		FROM products
+		WHERE id <> $1 AND (slug = $2 OR gtin = $3 OR external_id = $4)
+		LIMIT 1
+	`, p.Id, nullIfEmpty(p.Slug), nullIfEmpty(p.Gtin), nullIfEmpty(p.ExternalId)).Scan(&id, &slug, &gtin, &externalID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return identifierConflict(p, map[string]any{
+		"product_id":         id,
+		IdentifierSlug:       slug,
+		IdentifierGTIN:       gtin,
+		IdentifierExternalID: externalID,
+	})
+}
+
+// nextFreePostgresSlug is nextFreeSlug for the products table. Generated
+// slugs never contain LIKE wildcards.
+func nextFreePostgresSlug(ctx context.Context, tx pgx.Tx, base string) (string, error) {
+	rows, err := tx.Query(ctx, `SELECT slug FROM products WHERE slug = $1 OR slug LIKE $2`, base, base+"-%")
+	if err != nil {
+		return "", err
+	}
+	taken, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return "", err
+	}
+	return slug.Next(base, taken), nil
+}
+
+func findPostgresProductConflict(ctx context.Context, q postgresQuerier, p *pb.Product) error {
+	if err := findPostgresSkuConflict(ctx, q, sizeSkus(p.Sizes)); err != nil {
+		return err
+	}
+	return findPostgresIdentifierConflict(ctx, q, p)
+}
+
+// resolveConflict turns a unique violation from a write that raced past
+// its conflict check into the ConflictError lookup finds.
+func (r *PostgresRepository) resolveConflict(ctx context.Context, err error, lookup func(q postgresQuerier) error) error {
+	var conflict *ConflictError
+	if errors.As(lookup(r.pool), &conflict) {
+		return conflict
+	}
+	return err
+}
+
+func valueOrZero(v *int32) int32 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
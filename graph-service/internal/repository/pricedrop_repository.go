@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const priceDropFields = `
+	cu.id AS user_id, p.id AS product_id, p.name AS product_name,
+	w.price AS price, p.price AS current_price,
+	w.created_at AS created_at, w.notified_at AS notified_at
+`
+
+// SubscribeToPriceDrop watches the product's price for the user from its
+// current price. An existing subscription is returned unchanged.
+func (r *ProductRepository) SubscribeToPriceDrop(ctx context.Context, userID, productID string) (*pb.PriceDropSubscription, error) {
+	if userID == "" || productID == "" {
+		return nil, fieldErrorf("product_id", "user id and product id are required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (cu:Customer {id: $user_id}), (p:Product {id: $product_id})
+			MERGE (cu)-[w:WATCHES_PRICE]->(p)
+			ON CREATE SET w.price = p.price, w.created_at = $now, w.notified_at = 0
+			RETURN `+priceDropFields, map[string]any{
+			"user_id":    userID,
+			"product_id": productID,
+			"now":        time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, notFoundf("user or product not found")
+		}
+		return priceDropFromRow(res.Record().AsMap()), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.PriceDropSubscription), nil
+}
+
+func (r *ProductRepository) UnsubscribeFromPriceDrop(ctx context.Context, userID, productID string) error {
+	if userID == "" || productID == "" {
+		return fieldErrorf("product_id", "user id and product id are required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (:Customer {id: $user_id})-[w:WATCHES_PRICE]->(:Product {id: $product_id})
+			DELETE w
+			RETURN count(w) AS deleted
+		`, map[string]any{"user_id": userID, "product_id": productID})
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if getInt64(record.AsMap(), "deleted") == 0 {
+			return nil, notFoundf("price drop subscription not found")
+		}
+		return nil, nil
+	})
+
+	return err
+}
+
+// PendingPriceDrops returns up to limit subscriptions whose product now
+// costs less than the subscription's price, oldest subscription first.
+func (r *ProductRepository) PendingPriceDrops(ctx context.Context, limit int) ([]*pb.PriceDropSubscription, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (cu:Customer)-[w:WATCHES_PRICE]->(p:Product)
+			WHERE p.price < w.price
+			RETURN `+priceDropFields+`
+			ORDER BY w.created_at, cu.id, p.id
+			LIMIT $limit
+		`, map[string]any{"limit": limit})
+		if err != nil {
+			return nil, err
+		}
+
+		drops := []*pb.PriceDropSubscription{}
+		for res.Next(ctx) {
+			drops = append(drops, priceDropFromRow(res.Record().AsMap()))
+		}
+		return drops, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]*pb.PriceDropSubscription), nil
+}
+
+// RecordPriceDrop records that the subscriber was told of the drop from
+// sub.Price to sub.CurrentPrice, which becomes the price later drops are
+// measured from. It does nothing if the subscription moved on meanwhile.
+func (r *ProductRepository) RecordPriceDrop(ctx context.Context, sub *pb.PriceDropSubscription) error {
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			MATCH (:Customer {id: $user_id})-[w:WATCHES_PRICE]->(:Product {id: $product_id})
+			WHERE w.price = $old_price
+			SET w.price = $new_price, w.notified_at = $now
+			CREATE (:PriceDropNotification {
+				id: $id,
+				user_id: $user_id,
+				product_id: $product_id,
+				old_price: $old_price,
+				new_price: $new_price,
+				notified_at: $now
+			})
+		`, map[string]any{
+			"id":         newID(),
+			"user_id":    sub.UserId,
+			"product_id": sub.ProductId,
+			"old_price":  sub.Price,
+			"new_price":  sub.CurrentPrice,
+			"now":        time.Now().UnixMilli(),
+		})
+		return nil, err
+	})
+
+	return err
+}
+
+func priceDropFromRow(row map[string]any) *pb.PriceDropSubscription {
+	sub := &pb.PriceDropSubscription{
+		UserId:      getString(row, "user_id"),
+		ProductId:   getString(row, "product_id"),
+		ProductName: getString(row, "product_name"),
+		CreatedAt:   getInt64(row, "created_at"),
+		NotifiedAt:  getInt64(row, "notified_at"),
+	}
+	sub.Price, _ = row["price"].(float64)
+	sub.CurrentPrice, _ = row["current_price"].(float64)
+	return sub
+}
@@ -0,0 +1,332 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Data request kinds recorded on DataRequest nodes.
+const (
+	dataRequestExport = "export"
+	dataRequestDelete = "delete"
+)
+
+const maxDataRequestReasonLen = 1024
+
+// UserDataDeletion summarizes a DeleteUserData call.
+type UserDataDeletion struct {
+	// Pseudonym replaces the user id on retained orders; empty when the
+	// user had none.
+	Pseudonym      string
+	OrdersRetained int
+	NodesDeleted   int
+	RequestID      string
+}
+
+// exportedNode and exportedRelationship make up the export bundle.
+type exportedNode struct {
+	Labels     []string       `json:"labels"`
+	Properties map[string]any `json:"properties"`
+}
+
+type exportedRelationship struct {
+	Type       string         `json:"type"`
+	Start      exportRef      `json:"start"`
+	End        exportRef      `json:"end"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+// exportRef names a node by its labels and natural key.
+type exportRef struct {
+	Labels []string `json:"labels"`
+	Key    string   `json:"key"`
+}
+
+type userDataBundle struct {
+	UserID        string                 `json:"user_id"`
+	ExportedAt    int64                  `json:"exported_at"`
+	Nodes         []exportedNode         `json:"nodes"`
+	Relationships []exportedRelationship `json:"relationships"`
+}
+
+// subjectHash identifies a user on the audit trail without storing their id.
+func subjectHash(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return hex.EncodeToString(sum[:])
+}
+
+func nodeRef(n neo4j.Node) exportRef {
+	ref := exportRef{Labels: n.Labels}
+	for _, key := range []string{"id", "sku", "code", "order_id", "name"} {
+		if val := getString(n.Props, key); val != "" {
+			ref.Key = val
+			break
+		}
+	}
+	return ref
+}
+
+// ExportUserData returns the user's nodes and their relationships as a JSON
+// bundle and records the export on the audit trail.
+func (r *ProductRepository) ExportUserData(ctx context.Context, userID, requestedBy string) ([]byte, string, error) {
+	if userID == "" {
+		return nil, "", fieldErrorf("user_id", "user id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	requestID := newID()
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		now := time.Now().UnixMilli()
+		res, err := tx.Run(ctx, `
+			OPTIONAL MATCH (cu:Customer {id: $id})
+			OPTIONAL MATCH (cu)-[:PLACED]->(o:Order)
+			OPTIONAL MATCH (o)-[:HAS_LINE|RESERVED]->(part)
+			OPTIONAL MATCH (rt:Return)-[:FOR_ORDER]->(o)
+			WITH collect(DISTINCT cu) + collect(DISTINCT o) + collect(DISTINCT part) + collect(DISTINCT rt) AS owned
+			OPTIONAL MATCH (ss:SavedSearch {user_id: $id})
+			WITH owned + collect(ss) AS owned
+			OPTIONAL MATCH (rd:Redemption {customer_id: $id})
+			WITH owned + collect(rd) AS owned
+			OPTIONAL MATCH (lt:LoyaltyTransaction {customer_id: $id})
+			WITH owned + collect(lt) AS owned
+			OPTIONAL MATCH (pd:PriceDropNotification {user_id: $id})
+			WITH owned + collect(pd) AS owned
+			OPTIONAL MATCH (q:Question {author_id: $id})
+			WITH owned + collect(q) AS owned
+			OPTIONAL MATCH (an:Answer {author_id: $id})
+			WITH owned + collect(an) AS owned
+			OPTIONAL MATCH (vs:VoiceSession {customer_id: $id})
+			OPTIONAL MATCH (vs)-[:HAS_ENTRY]->(te:TranscriptEntry)
+			WITH owned + collect(DISTINCT vs) + collect(te) AS nodes
+			UNWIND nodes AS n
+			OPTIONAL MATCH (n)-[rel]-()
+			RETURN n, collect([rel, startNode(rel), endNode(rel)]) AS rels
+		`, map[string]any{"id": userID})
+		if err != nil {
+			return nil, err
+		}
+
+		bundle := userDataBundle{UserID: userID, ExportedAt: now}
+		seen := make(map[string]bool)
+		for res.Next(ctx) {
+			record := res.Record()
+			node, _ := record.Values[0].(neo4j.Node)
+			bundle.Nodes = append(bundle.Nodes, exportedNode{Labels: node.Labels, Properties: node.Props})
+
+			rels, _ := record.Values[1].([]any)
+			for _, raw := range rels {
+				triple, _ := raw.([]any)
+				if len(triple) != 3 {
+					continue
+				}
+				rel, ok := triple[0].(neo4j.Relationship)
+				if !ok || seen[rel.ElementId] {
+					continue
+				}
+				seen[rel.ElementId] = true
+				start, _ := triple[1].(neo4j.Node)
+				end, _ := triple[2].(neo4j.Node)
+				bundle.Relationships = append(bundle.Relationships, exportedRelationship{
+					Type:       rel.Type,
+					Start:      nodeRef(start),
+					End:        nodeRef(end),
+					Properties: rel.Props,
+				})
+			}
+		}
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		if len(bundle.Nodes) == 0 {
+			return nil, errUserNotFound
+		}
+
+		data, err := json.Marshal(bundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize user data: %w", err)
+		}
+		err = recordDataRequest(ctx, tx, map[string]any{
+			"id":           requestID,
+			"kind":         dataRequestExport,
+			"subject_hash": subjectHash(userID),
+			"requested_by": requestedBy,
+			"reason":       "",
+			"nodes":        len(bundle.Nodes),
+			"now":          now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return data, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return result.([]byte), requestID, nil
+}
+
+// DeleteUserData erases the user's profile, saved searches, voice
+// transcripts and product questions and answers, taking the answers to
+// their questions with them. Their orders
+// and coupon redemptions are kept for accounting with customer_id replaced
+// by a random pseudonym, and return reasons, which are free text, are
+// cleared. The deletion is recorded on the audit trail.
+func (r *ProductRepository) DeleteUserData(ctx context.Context, userID, requestedBy, reason string) (*UserDataDeletion, error) {
+	if userID == "" {
+		return nil, fieldErrorf("user_id", "user id is required")
+	}
+	if len(reason) > maxDataRequestReasonLen {
+		return nil, &LimitError{Field: "reason", Limit: maxDataRequestReasonLen, Actual: len(reason)}
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		params := map[string]any{
+			"id":        userID,
+			"pseudonym": "anon-" + newID(),
+			"now":       time.Now().UnixMilli(),
+		}
+		deletion := &UserDataDeletion{RequestID: newID()}
+
+		_, err := tx.Run(ctx, `
+			MATCH (:Customer {id: $id})-[:PLACED]->(:Order)<-[:FOR_ORDER]-(rt:Return)
+			SET rt.reason = '', rt.updated_at = $now
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+
+		counts := []struct {
+			n     *int
+			query string
+		}{
+			{&deletion.OrdersRetained, `
+				MATCH (:Customer {id: $id})-[:PLACED]->(o:Order)
+				SET o.customer_id = $pseudonym, o.updated_at = $now
+				RETURN count(o)
+			`},
+			{new(int), `
+				MATCH (rd:Redemption {customer_id: $id})
+				SET rd.customer_id = $pseudonym
+				RETURN count(rd)
+			`},
+			{&deletion.NodesDeleted, `
+				MATCH (ss:SavedSearch {user_id: $id})
+				DETACH DELETE ss
+				RETURN count(ss)
+			`},
+			{&deletion.NodesDeleted, `
+				MATCH (lt:LoyaltyTransaction {customer_id: $id})
+				DETACH DELETE lt
+				RETURN count(lt)
+			`},
+			{&deletion.NodesDeleted, `
+				MATCH (pd:PriceDropNotification {user_id: $id})
+				DETACH DELETE pd
+				RETURN count(pd)
+			`},
+			{&deletion.NodesDeleted, `
+				MATCH (q:Question {author_id: $id})
+				OPTIONAL MATCH (q)-[:HAS_ANSWER]->(an:Answer)
+				WITH collect(DISTINCT q) + collect(an) AS nodes
+				UNWIND nodes AS n
+				DETACH DELETE n
+				RETURN count(n)
+			`},
+			{&deletion.NodesDeleted, `
+				MATCH (an:Answer {author_id: $id})
+				DETACH DELETE an
+				RETURN count(an)
+			`},
+			{&deletion.NodesDeleted, `
+				MATCH (vs:VoiceSession {customer_id: $id})
+				OPTIONAL MATCH (vs)-[:HAS_ENTRY]->(te:TranscriptEntry)
+				WITH collect(DISTINCT vs) + collect(te) AS nodes
+				UNWIND nodes AS n
+				DETACH DELETE n
+				RETURN count(n)
+			`},
+		}
+		for _, c := range counts {
+			res, err := tx.Run(ctx, c.query, params)
+			if err != nil {
+				return nil, err
+			}
+			record, err := res.Single(ctx)
+			if err != nil {
+				return nil, err
+			}
+			n, _ := record.Values[0].(int64)
+			*c.n += int(n)
+		}
+
+		// Deleting the customer drops its PLACED relationships, leaving the
+		// orders tied to the user by the pseudonym alone
+		res, err := tx.Run(ctx, `
+			MATCH (cu:Customer {id: $id})
+			DETACH DELETE cu
+			RETURN count(cu)
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		customers, _ := record.Values[0].(int64)
+		deletion.NodesDeleted += int(customers)
+
+		if deletion.NodesDeleted == 0 {
+			return nil, errUserNotFound
+		}
+		if deletion.OrdersRetained > 0 {
+			deletion.Pseudonym = params["pseudonym"].(string)
+		}
+
+		err = recordDataRequest(ctx, tx, map[string]any{
+			"id":           deletion.RequestID,
+			"kind":         dataRequestDelete,
+			"subject_hash": subjectHash(userID),
+			"requested_by": requestedBy,
+			"reason":       reason,
+			"nodes":        deletion.NodesDeleted,
+			"now":          params["now"],
+		})
+		if err != nil {
+			return nil, err
+		}
+		return deletion, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*UserDataDeletion), nil
+}
+
+func recordDataRequest(ctx context.Context, tx neo4j.ManagedTransaction, params map[string]any) error {
+	_, err := tx.Run(ctx, `
+		CREATE (:DataRequest {
+			id: $id,
+			kind: $kind,
+			subject_hash: $subject_hash,
+			requested_by: $requested_by,
+			reason: $reason,
+			nodes: $nodes,
+			created_at: $now
+		})
+	`, params)
+	return err
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"google.golang.org/protobuf/proto"
+)
+
+// productUpdate is the product.updated payload: the product as updated,
+// plus the fields the update changed.
+type productUpdate struct {
+	*pb.Product
+	Changes []*pb.FieldChange `json:"changes"`
+}
+
+// productChanges lists the fields updating old to p changes, in Product
+// field order. Fields UpdateProduct keeps when p leaves them unset are
+// skipped when unset. A nil old, a product that doesn't exist, has none.
+func productChanges(old, p *pb.Product) []*pb.FieldChange {
+	if old == nil {
+		return nil
+	}
+
+	var changes []*pb.FieldChange
+	add := func(field string, before, after any, same bool) {
+		if same {
+			return
+		}
+		changes = append(changes, &pb.FieldChange{
+			Field:    field,
+			OldValue: jsonValue(before),
+			NewValue: jsonValue(after),
+		})
+	}
+
+	add("name", old.Name, p.Name, old.Name == p.Name)
+	add("brand", old.Brand, p.Brand, old.Brand == p.Brand)
+	add("category", old.Category, p.Category, p.Category == nil || proto.Equal(old.Category, p.Category))
+	add("color", old.Color, p.Color, old.Color == p.Color)
+	add("price", old.Price, p.Price, old.Price == p.Price)
+	add("original_price", old.OriginalPrice, p.OriginalPrice, old.OriginalPrice == p.OriginalPrice)
+	add("tags", old.Tags, p.Tags, slices.Equal(old.Tags, p.Tags))
+	add("attributes", old.Attributes, p.Attributes, maps.Equal(old.Attributes, p.Attributes))
+	add("description", old.Description, p.Description, old.Description == p.Description)
+	add("images", old.Images, p.Images, slices.Equal(old.Images, p.Images))
+	add("slug", old.Slug, p.Slug, p.Slug == "" || old.Slug == p.Slug)
+	add("gtin", old.Gtin, p.Gtin, p.Gtin == "" || old.Gtin == p.Gtin)
+	add("external_id", old.ExternalId, p.ExternalId, p.ExternalId == "" || old.ExternalId == p.ExternalId)
+	add("shipping", old.Shipping, p.Shipping, p.Shipping == nil || proto.Equal(old.Shipping, p.Shipping))
+	add("tax_class", old.TaxClass, p.TaxClass, p.TaxClass == "" || old.TaxClass == p.TaxClass)
+	add("meta_title", old.MetaTitle, p.MetaTitle, p.MetaTitle == "" || old.MetaTitle == p.MetaTitle)
+	add("meta_description", old.MetaDescription, p.MetaDescription, p.MetaDescription == "" || old.MetaDescription == p.MetaDescription)
+	return changes
+}
+
+// jsonValue encodes a field value for a FieldChange. Strings, numbers,
+// lists, maps and the field messages always encode, so it can't fail.
+func jsonValue(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -5,34 +5,114 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/breaker"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/secretbox"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"google.golang.org/protobuf/proto"
 )
 
 type ProductRepository struct {
-	driver neo4j.DriverWithContext
+	driver   neo4j.DriverWithContext
+	breaker  *breaker.Breaker
+	dialect  Dialect
+	database string
+
+	reservationTTL time.Duration
+	searchIndexes  SearchIndexes
+	queryCache     *QueryCache
+	queryLimits    QueryLimits
+	traversals     chan struct{}
+	overviews      *overviewCache
+
+	outfitCategories []string
+	webhookSecrets   *secretbox.Box
 }
 
-func NewProductRepository(driver neo4j.DriverWithContext) *ProductRepository {
-	return &ProductRepository{driver: driver}
+// Option configures a ProductRepository.
+type Option func(*ProductRepository)
+
+// WithBreaker guards every Neo4j transaction with b, so calls fail fast
+// with breaker.ErrOpen while the database is unreachable.
+func WithBreaker(b *breaker.Breaker) Option {
+	return func(r *ProductRepository) {
+		r.breaker = b
+	}
+}
+
+// WithDialect adapts queries to a database other than Neo4j 5, which is
+// the default.
+func WithDialect(d Dialect) Option {
+	return func(r *ProductRepository) {
+		r.dialect = d
+	}
+}
+
+// WithDatabase runs sessions against the named database instead of the
+// server's default one.
+func WithDatabase(name string) Option {
+	return func(r *ProductRepository) {
+		r.database = name
+	}
+}
+
+func NewProductRepository(driver neo4j.DriverWithContext, opts ...Option) *ProductRepository {
+	r := &ProductRepository{driver: driver, dialect: Neo4jDialect, reservationTTL: defaultReservationTTL, searchIndexes: DefaultSearchIndexes}
+	WithQueryLimits(DefaultQueryLimits)(r)
+	WithOverviewTTL(defaultOverviewTTL)(r)
+	WithOutfitCategories(DefaultOutfitCategories)(r)
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func sizeParams(sizes []*pb.ProductSize) []map[string]any {
+	params := make([]map[string]any, 0, len(sizes))
+	for _, size := range sizes {
+		params = append(params, map[string]any{
+			"sku":      size.Sku,
+			"size":     size.Size,
+			"stock":    size.Stock,
+			"in_stock": size.InStock,
+			"variants": size.Variants,
+		})
+	}
+	return params
 }
 
 func (r *ProductRepository) CreateProduct(ctx context.Context, p *pb.Product) error {
 	// Validate required fields
 	if p.Id == "" {
-		return errors.New("product id is required")
+		return fieldErrorf("product.id", "product id is required")
 	}
 	if p.Name == "" {
-		return errors.New("product name is required")
+		return fieldErrorf("product.name", "product name is required")
 	}
 	if p.Brand == "" {
-		return errors.New("product brand is required")
+		return fieldErrorf("product.brand", "product brand is required")
+	}
+	if err := checkProductLimits(p); err != nil {
+		return err
+	}
+	if err := checkSizes("product.sizes", p.Sizes); err != nil {
+		return err
+	}
+	if err := checkIdentifiers(p); err != nil {
+		return err
+	}
+	if err := checkShipping(p); err != nil {
+		return err
 	}
 
-	session := r.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
-	defer session.Close(ctx)
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
 
 	// Serialize attributes to JSON string
 	attributesJSON, err := json.Marshal(p.Attributes)
@@ -40,210 +120,303 @@ func (r *ProductRepository) CreateProduct(ctx context.Context, p *pb.Product) er
 		return fmt.Errorf("failed to serialize attributes: %w", err)
 	}
 
-	_, err = session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
-
-		// Create Product
-		_, err := tx.Run(ctx, `
-			CREATE (p:Product {
-				id: $id,
-				name: $name,
-				brand: $brand,
-				color: $color,
-				price: $price,
-				original_price: $original_price,
-				description: $description,
-				tags: $tags,
-				images: $images,
-				attributes: $attributes
-			})
-		`, map[string]any{
-			"id":             p.Id,
-			"name":           p.Name,
-			"brand":          p.Brand,
-			"color":          p.Color,
-			"price":          p.Price,
-			"original_price": p.OriginalPrice,
-			"description":    p.Description,
-			"tags":           p.Tags,
-			"images":         p.Images,
-			"attributes":     string(attributesJSON),
-		})
-		if err != nil {
-			return nil, err
-		}
-
-		// Category
-		_, err = tx.Run(ctx, `
-			MATCH (p:Product {id: $id})
-			MERGE (c:Category {
-				main_category: $main_category,
-				subcategory: $subcategory,
-				specific_type: $specific_type
-			})
-			MERGE (p)-[:BELONGS_TO]->(c)
-		`, map[string]any{
-			"id":            p.Id,
-			"main_category": p.Category.MainCategory,
-			"subcategory":   p.Category.Subcategory,
-			"specific_type": p.Category.SpecificType,
-		})
-		if err != nil {
-			return nil, err
-		}
+	now := time.Now().UnixMilli()
+	p.CreatedAt, p.UpdatedAt = now, now
+
+	// A generated slug can lose a race for the same handle with another
+	// product; generate the next free one and try again.
+	generateSlug := p.Slug == ""
+	for attempt := 1; ; attempt++ {
+		_, err = session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			if generateSlug {
+				handle, err := nextFreeSlug(ctx, tx, baseSlug(p))
+				if err != nil {
+					return nil, err
+				}
+				p.Slug = handle
+			}
+			if err := findProductConflict(ctx, tx, p); err != nil {
+				return nil, err
+			}
 
-		// Sizes
-		for _, size := range p.Sizes {
+			// Product, category and sizes in one round trip
 			_, err := tx.Run(ctx, `
-				MATCH (p:Product {id: $id})
+				CREATE (p:Product:Active {
+					id: $id,
+					name: $name,
+					brand: $brand,
+					color: $color,
+					price: $price,
+					original_price: $original_price,
+					description: $description,
+					tags: $tags,
+					images: $images,
+					attributes: $attributes,
+					tenant_id: $tenant_id,
+					slug: $slug,
+					gtin: $gtin,
+					external_id: $external_id,
+					tax_class: $tax_class,
+					meta_title: $meta_title,
+					meta_description: $meta_description,
+					created_at: $now,
+					updated_at: $now
+				})
+				SET p += $shipping`+mergeCategory+`
+				CREATE (p)-[:BELONGS_TO]->(c)
+				WITH p
+				UNWIND $sizes AS size
 				CREATE (s:Size {
-					sku: $sku,
-					size: $size,
-					stock: $stock,
-					in_stock: $in_stock,
-					variants: $variants
+					sku: size.sku,
+					size: size.size,
+					stock: size.stock,
+					in_stock: size.in_stock,
+					variants: size.variants,
+					created_at: $now,
+					updated_at: $now
 				})
-				MERGE (p)-[:HAS_SIZE]->(s)
+				CREATE (p)-[:HAS_SIZE]->(s)
 			`, map[string]any{
-				"id":       p.Id,
-				"sku":      size.Sku,
-				"size":     size.Size,
-				"stock":    size.Stock,
-				"in_stock": size.InStock,
-				"variants": size.Variants,
+				"id":               p.Id,
+				"name":             p.Name,
+				"brand":            p.Brand,
+				"color":            p.Color,
+				"price":            p.Price,
+				"original_price":   p.OriginalPrice,
+				"description":      p.Description,
+				"tags":             p.Tags,
+				"images":           p.Images,
+				"attributes":       string(attributesJSON),
+				"tenant_id":        p.TenantId,
+				"slug":             nullIfEmpty(p.Slug),
+				"gtin":             nullIfEmpty(p.Gtin),
+				"external_id":      nullIfEmpty(p.ExternalId),
+				"tax_class":        nullIfEmpty(p.TaxClass),
+				"meta_title":       nullIfEmpty(p.MetaTitle),
+				"meta_description": nullIfEmpty(p.MetaDescription),
+				"shipping":         shippingProps(p.Shipping),
+				"main_category":    p.GetCategory().GetMainCategory(),
+				"subcategory":      p.GetCategory().GetSubcategory(),
+				"specific_type":    p.GetCategory().GetSpecificType(),
+				"sizes":            sizeParams(p.Sizes),
+				"now":              now,
 			})
 			if err != nil {
 				return nil, err
 			}
-		}
 
-		return nil, nil
-	})
+			return nil, writeEvent(ctx, tx, events.New(events.ProductCreated, p.Id, p))
+		})
 
-	return err
+		err = r.resolveConflict(ctx, err, func(tx neo4j.ManagedTransaction) error {
+			return findProductConflict(ctx, tx, p)
+		})
+
+		var conflict *ConflictError
+		if generateSlug && attempt < maxSlugAttempts && errors.As(err, &conflict) && conflict.Field == IdentifierSlug {
+			continue
+		}
+		return err
+	}
 }
 
 func (r *ProductRepository) GetProduct(ctx context.Context, id string) (*pb.Product, error) {
 	if id == "" {
-		return nil, errors.New("product id is required")
+		return nil, fieldErrorf("id", "product id is required")
 	}
 
-	session := r.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close(ctx)
+	cypher := `
+		MATCH (p:Product {id: $id})` + productReturn(ctx, "") + `
+	`
+	params := map[string]any{"id": id}
 
-	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	products, err := cachedRead(ctx, r, cypher, params, func() ([]*pb.Product, error) {
+		session := r.newSession(ctx, neo4j.AccessModeRead)
+		defer r.closeSession(ctx, session)
+
+		result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+
+			res, err := tx.Run(ctx, cypher, params)
+			if err != nil {
+				return nil, err
+			}
+
+			if !res.Next(ctx) {
+				return nil, notFoundf("product not found")
+			}
+
+			return []*pb.Product{productFromRecord(res.Record())}, nil
+		})
 
-		res, err := tx.Run(ctx, `
-			MATCH (p:Product {id: $id})
-			OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
-			OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
-			RETURN p, c, collect(s) as sizes
-		`, map[string]any{"id": id})
 		if err != nil {
 			return nil, err
 		}
 
-		if !res.Next(ctx) {
-			return nil, errors.New("product not found")
-		}
+		return result.([]*pb.Product), nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		record := res.Record()
+	return products[0], nil
+}
 
-		pNode := record.Values[0].(neo4j.Node)
-		cNode, _ := record.Values[1].(neo4j.Node)
-		sizesList, _ := record.Values[2].([]interface{})
+// ListProducts returns up to limit products ordered by id, starting after
+// afterID, of tenantID's unless it is empty. Archived products are left out
+// unless includeArchive is set.
+func (r *ProductRepository) ListProducts(ctx context.Context, tenantID, afterID string, limit int, includeArchive bool) ([]*pb.Product, error) {
+	labels := "Product"
+	if !includeArchive {
+		labels += ":" + activeLabel
+	}
 
-		var product pb.Product
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
 
-		props := pNode.Props
-		product.Id = getString(props, "id")
-		product.Name = getString(props, "name")
-		product.Brand = getString(props, "brand")
-		product.Color = getString(props, "color")
-		if price, ok := props["price"].(float64); ok {
-			product.Price = price
-		}
-		if origPrice, ok := props["original_price"].(float64); ok {
-			product.OriginalPrice = origPrice
-		}
-		product.Description = getString(props, "description")
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 
-		if tags, ok := props["tags"].([]interface{}); ok {
-			for _, tag := range tags {
-				if str, ok := tag.(string); ok {
-					product.Tags = append(product.Tags, str)
-				}
-			}
+		res, err := tx.Run(ctx, `
+			MATCH (p:`+labels+`)
+			WHERE ($tenant_id = '' OR p.tenant_id = $tenant_id)
+				AND ($after_id = '' OR p.id > $after_id)
+			WITH p
+			ORDER BY p.id
+			LIMIT $limit`+productReturn(ctx, "")+`
+			ORDER BY p.id
+		`, map[string]any{
+			"tenant_id": tenantID,
+			"after_id":  afterID,
+			"limit":     limit,
+		})
+		if err != nil {
+			return nil, err
 		}
 
-		if images, ok := props["images"].([]interface{}); ok {
-			for _, img := range images {
-				if str, ok := img.(string); ok {
-					product.Images = append(product.Images, str)
-				}
-			}
+		products := []*pb.Product{}
+		for res.Next(ctx) {
+			products = append(products, productFromRecord(res.Record()))
 		}
+		return products, res.Err()
+	})
 
-		if attrsStr, ok := props["attributes"].(string); ok {
-			product.Attributes = make(map[string]string)
-			json.Unmarshal([]byte(attrsStr), &product.Attributes)
-		}
+	if err != nil {
+		return nil, err
+	}
 
-		if cNode.Props != nil {
-			product.Category = &pb.ProductCategory{
-				MainCategory:  getString(cNode.Props, "main_category"),
-				Subcategory:   getString(cNode.Props, "subcategory"),
-				SpecificType:  getString(cNode.Props, "specific_type"),
-			}
-		}
+	return result.([]*pb.Product), nil
+}
 
-		for _, sizeItem := range sizesList {
-			if sizeNode, ok := sizeItem.(neo4j.Node); ok {
-				sProps := sizeNode.Props
-				size := &pb.ProductSize{
-					Sku:     getString(sProps, "sku"),
-					Size:    getString(sProps, "size"),
-				}
-				if stock, ok := sProps["stock"].(int64); ok {
-					size.Stock = int32(stock)
-				}
-				if inStock, ok := sProps["in_stock"].(bool); ok {
-					size.InStock = inStock
-				}
-				if variants, ok := sProps["variants"].([]interface{}); ok {
-					for _, v := range variants {
-						if str, ok := v.(string); ok {
-							size.Variants = append(size.Variants, str)
-						}
-					}
-				}
-				product.Sizes = append(product.Sizes, size)
-			}
+/*
+NEED TO RUN ONCE
+
+CREATE INDEX productUpdatedAt IF NOT EXISTS
+FOR (p:Product)
+ON (p.updated_at)
+*/
+// ListProductsUpdatedSince returns products changed at or after since, of
+// tenantID's unless it is empty, ordered by (updated_at, id) and resuming
+// after the cursor.
+func (r *ProductRepository) ListProductsUpdatedSince(ctx context.Context, tenantID string, since int64, after pagetoken.Cursor, limit int) ([]*pb.Product, error) {
+	afterTime := since
+	if after.LastID != "" {
+		afterTime = after.LastTime
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+
+		res, err := tx.Run(ctx, `
+			MATCH (p:Product)
+			WHERE ($tenant_id = '' OR p.tenant_id = $tenant_id)
+				AND (p.updated_at > $after_time
+					OR (p.updated_at = $after_time AND p.id > $after_id))
+			WITH p
+			ORDER BY p.updated_at, p.id
+			LIMIT $limit
+			OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+			OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+			RETURN p, c, collect(s) as sizes
+			ORDER BY p.updated_at, p.id
+		`, map[string]any{
+			"tenant_id":  tenantID,
+			"after_time": afterTime,
+			"after_id":   after.LastID,
+			"limit":      limit,
+		})
+		if err != nil {
+			return nil, err
 		}
 
-		return &product, nil
+		products := []*pb.Product{}
+		for res.Next(ctx) {
+			products = append(products, productFromRecord(res.Record()))
+		}
+		return products, res.Err()
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	return result.(*pb.Product), nil
+	return result.([]*pb.Product), nil
 }
 
-func (r *ProductRepository) UpdateProduct(ctx context.Context, p *pb.Product) error {
+// mergeCategory continues a query with p in scope, binding c to the
+// category $main_category > $subcategory > $specific_type and creating
+// the hierarchy down to it as needed.
+const mergeCategory = `
+	MERGE (m:MainCategory {name: $main_category})
+	ON CREATE SET m.created_at = $now, m.updated_at = $now
+	MERGE (m)-[:HAS_SUBCATEGORY]->(sc:Subcategory {name: $subcategory})
+	ON CREATE SET sc.created_at = $now, sc.updated_at = $now
+	MERGE (sc)-[:HAS_TYPE]->(c:Category:SpecificType {name: $specific_type})
+	ON CREATE SET c.main_category = $main_category,
+		c.subcategory = $subcategory,
+		c.specific_type = $specific_type,
+		c.created_at = $now,
+		c.updated_at = $now
+`
+
+// UpdateProduct overwrites the product's fields and returns those that
+// changed. A product given a different category moves to it.
+func (r *ProductRepository) UpdateProduct(ctx context.Context, p *pb.Product) ([]*pb.FieldChange, error) {
+	if err := checkProductLimits(p); err != nil {
+		return nil, err
+	}
+	if err := checkIdentifiers(p); err != nil {
+		return nil, err
+	}
+	if err := checkShipping(p); err != nil {
+		return nil, err
+	}
 
-	session := r.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
-	defer session.Close(ctx)
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
 
 	// Serialize attributes to JSON string
 	attributesJSON, err := json.Marshal(p.Attributes)
 	if err != nil {
-		return fmt.Errorf("failed to serialize attributes: %w", err)
+		return nil, fmt.Errorf("failed to serialize attributes: %w", err)
 	}
 
-	_, err = session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+	now := time.Now().UnixMilli()
+	p.UpdatedAt = now
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		if err := findIdentifierConflict(ctx, tx, p); err != nil {
+			return nil, err
+		}
+		old, err := lockProduct(ctx, tx, p.Id, now)
+		if err != nil {
+			return nil, err
+		}
+		changes := productChanges(old, p)
 
-		_, err := tx.Run(ctx, `
+		// Unset identifiers, tax class, shipping profile and category keep
+		// their stored value
+		query := `
 			MATCH (p:Product {id: $id})
 			SET p.name = $name,
 				p.brand = $brand,
@@ -253,39 +426,113 @@ func (r *ProductRepository) UpdateProduct(ctx context.Context, p *pb.Product) er
 				p.description = $description,
 				p.tags = $tags,
 				p.images = $images,
-				p.attributes = $attributes
-		`, map[string]any{
-			"id":             p.Id,
-			"name":           p.Name,
-			"brand":          p.Brand,
-			"color":          p.Color,
-			"price":          p.Price,
-			"original_price": p.OriginalPrice,
-			"description":    p.Description,
-			"tags":           p.Tags,
-			"images":         p.Images,
-			"attributes":     string(attributesJSON),
+				p.attributes = $attributes,
+				p.slug = coalesce($slug, p.slug),
+				p.gtin = coalesce($gtin, p.gtin),
+				p.external_id = coalesce($external_id, p.external_id),
+				p.tax_class = coalesce($tax_class, p.tax_class),
+				p.meta_title = coalesce($meta_title, p.meta_title),
+				p.meta_description = coalesce($meta_description, p.meta_description),
+				p.updated_at = $now
+			SET p += $shipping
+		`
+		if p.Category != nil {
+			query += mergeCategory + `
+			MERGE (p)-[:BELONGS_TO]->(c)
+			WITH p, c
+			OPTIONAL MATCH (p)-[old:BELONGS_TO]->(previous:Category)
+			WHERE previous <> c
+			DELETE old
+			`
+		}
+		_, err = tx.Run(ctx, query, map[string]any{
+			"id":               p.Id,
+			"name":             p.Name,
+			"brand":            p.Brand,
+			"color":            p.Color,
+			"price":            p.Price,
+			"original_price":   p.OriginalPrice,
+			"description":      p.Description,
+			"tags":             p.Tags,
+			"images":           p.Images,
+			"attributes":       string(attributesJSON),
+			"slug":             nullIfEmpty(p.Slug),
+			"gtin":             nullIfEmpty(p.Gtin),
+			"external_id":      nullIfEmpty(p.ExternalId),
+			"tax_class":        nullIfEmpty(p.TaxClass),
+			"meta_title":       nullIfEmpty(p.MetaTitle),
+			"meta_description": nullIfEmpty(p.MetaDescription),
+			"shipping":         shippingProps(p.Shipping),
+			"main_category":    p.GetCategory().GetMainCategory(),
+			"subcategory":      p.GetCategory().GetSubcategory(),
+			"specific_type":    p.GetCategory().GetSpecificType(),
+			"now":              now,
 		})
-		return nil, err
+		if err != nil {
+			return nil, err
+		}
+
+		update := productUpdate{Product: p, Changes: changes}
+		return changes, writeEvent(ctx, tx, events.New(events.ProductUpdated, p.Id, update))
 	})
+	if err != nil {
+		return nil, r.resolveConflict(ctx, err, func(tx neo4j.ManagedTransaction) error {
+			return findIdentifierConflict(ctx, tx, p)
+		})
+	}
+	return result.([]*pb.FieldChange), nil
+}
 
-	return err
+// lockProduct write-locks the product for the rest of the transaction and
+// returns it as stored, or nil if it doesn't exist.
+func lockProduct(ctx context.Context, tx neo4j.ManagedTransaction, id string, now int64) (*pb.Product, error) {
+	res, err := tx.Run(ctx, `
+		MATCH (p:Product {id: $id})
+		SET p.updated_at = $now
+		WITH p
+		OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+		OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+		RETURN p, c, collect(s) as sizes
+	`, map[string]any{"id": id, "now": now})
+	if err != nil {
+		return nil, err
+	}
+	if !res.Next(ctx) {
+		return nil, res.Err()
+	}
+	return productFromRecord(res.Record()), nil
 }
 
 func (r *ProductRepository) DeleteProduct(ctx context.Context, id string) error {
 	if id == "" {
-		return errors.New("product id is required")
+		return fieldErrorf("id", "product id is required")
 	}
 
-	session := r.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
-	defer session.Close(ctx)
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
 
 	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
-		_, err := tx.Run(ctx, `
+		res, err := tx.Run(ctx, `
 			MATCH (p:Product {id: $id})
+			OPTIONAL MATCH (p)-[:HAS_TRANSLATION]->(t:Translation)
+			WITH p, collect(t) AS translations
 			DETACH DELETE p
+			FOREACH (t IN translations | DELETE t)
+			RETURN count(*) AS deleted
 		`, map[string]any{"id": id})
-		return nil, err
+		if err != nil {
+			return nil, err
+		}
+
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if deleted, _ := record.Values[0].(int64); deleted == 0 {
+			return nil, nil
+		}
+
+		return nil, writeEvent(ctx, tx, events.New(events.ProductDeleted, id, nil))
 	})
 
 	return err
@@ -293,27 +540,216 @@ func (r *ProductRepository) DeleteProduct(ctx context.Context, id string) error
 
 func (r *ProductRepository) UpdateStock(ctx context.Context, sku string, stock int32) error {
 	if sku == "" {
-		return errors.New("sku is required")
+		return fieldErrorf("sku", "sku is required")
 	}
 
-	session := r.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
-	defer session.Close(ctx)
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
 
 	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
-		_, err := tx.Run(ctx, `
+		res, err := tx.Run(ctx, `
 			MATCH (s:Size {sku: $sku})
 			SET s.stock = $stock,
-				s.in_stock = CASE WHEN $stock > 0 THEN true ELSE false END
+				s.in_stock = CASE WHEN $stock > 0 THEN true ELSE false END,
+				s.updated_at = $now
+			WITH s
+			OPTIONAL MATCH (p:Product)-[:HAS_SIZE]->(s)
+			SET p.updated_at = $now
+			RETURN p.id AS product_id
 		`, map[string]any{
 			"sku":   sku,
 			"stock": stock,
+			"now":   time.Now().UnixMilli(),
 		})
-		return nil, err
+		if err != nil {
+			return nil, err
+		}
+
+		records, err := res.Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			productID, _ := record.Values[0].(string)
+			ev := events.New(events.StockUpdated, productID, map[string]int32{"stock": stock})
+			ev.SKU = sku
+			if err := writeEvent(ctx, tx, ev); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
 	})
 
 	return err
 }
 
+// AddProductSize adds a size to an existing product.
+func (r *ProductRepository) AddProductSize(ctx context.Context, productID string, size *pb.ProductSize) error {
+	if productID == "" {
+		return fieldErrorf("product_id", "product id is required")
+	}
+	if err := checkSizes("size", []*pb.ProductSize{size}); err != nil {
+		return err
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (p:Product {id: $product_id})
+			OPTIONAL MATCH (p)-[:HAS_SIZE]->(existing:Size)
+			RETURN count(existing) AS sizes
+		`, map[string]any{"product_id": productID})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			return nil, notFoundf("product not found")
+		}
+		if n := int(getInt64(res.Record().AsMap(), "sizes")); n >= maxProductSizes {
+			return nil, &LimitError{Field: "product.sizes", Limit: maxProductSizes, Actual: n + 1}
+		}
+
+		if err := findSkuConflict(ctx, tx, []string{size.Sku}); err != nil {
+			return nil, err
+		}
+
+		_, err = tx.Run(ctx, `
+			MATCH (p:Product {id: $product_id})
+			CREATE (s:Size {
+				sku: $sku,
+				size: $size,
+				stock: $stock,
+				in_stock: $in_stock,
+				variants: $variants,
+				created_at: $now,
+				updated_at: $now
+			})
+			CREATE (p)-[:HAS_SIZE]->(s)
+			SET p.updated_at = $now
+		`, map[string]any{
+			"product_id": productID,
+			"sku":        size.Sku,
+			"size":       size.Size,
+			"stock":      size.Stock,
+			"in_stock":   size.InStock,
+			"variants":   size.Variants,
+			"now":        time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		ev := events.New(events.SizeAdded, productID, size)
+		ev.SKU = size.Sku
+		return nil, writeEvent(ctx, tx, ev)
+	})
+
+	return r.resolveConflict(ctx, err, func(tx neo4j.ManagedTransaction) error {
+		return findSkuConflict(ctx, tx, []string{size.Sku})
+	})
+}
+
+// productFromRecord maps a (p, c, sizes) record onto a Product message.
+// p may be narrowed by productReturn.
+func productFromRecord(record *neo4j.Record) *pb.Product {
+	cNode, _ := record.Values[1].(neo4j.Node)
+	sizesList, _ := record.Values[2].([]interface{})
+
+	var product pb.Product
+
+	// p is a node, or a map of some of its properties when projected
+	var props map[string]any
+	switch p := record.Values[0].(type) {
+	case neo4j.Node:
+		props = p.Props
+		product.Archived = !slices.Contains(p.Labels, activeLabel)
+	case map[string]any:
+		props = p
+		product.Archived, _ = p["archived"].(bool)
+	}
+	product.Id = getString(props, "id")
+	product.Name = getString(props, "name")
+	product.Brand = getString(props, "brand")
+	product.Color = getString(props, "color")
+	if price, ok := props["price"].(float64); ok {
+		product.Price = price
+	}
+	if origPrice, ok := props["original_price"].(float64); ok {
+		product.OriginalPrice = origPrice
+	}
+	product.Description = getString(props, "description")
+	product.TenantId = getString(props, "tenant_id")
+	product.Slug = getString(props, "slug")
+	product.Gtin = getString(props, "gtin")
+	product.ExternalId = getString(props, "external_id")
+	product.TaxClass = getString(props, "tax_class")
+	product.MetaTitle = getString(props, "meta_title")
+	product.MetaDescription = getString(props, "meta_description")
+	product.Shipping = shippingFromProps(props)
+	product.CreatedAt = getInt64(props, "created_at")
+	product.UpdatedAt = getInt64(props, "updated_at")
+
+	if tags, ok := props["tags"].([]interface{}); ok {
+		for _, tag := range tags {
+			if str, ok := tag.(string); ok {
+				product.Tags = append(product.Tags, str)
+			}
+		}
+	}
+
+	if images, ok := props["images"].([]interface{}); ok {
+		for _, img := range images {
+			if str, ok := img.(string); ok {
+				product.Images = append(product.Images, str)
+			}
+		}
+	}
+
+	if attrsStr, ok := props["attributes"].(string); ok {
+		product.Attributes = make(map[string]string)
+		json.Unmarshal([]byte(attrsStr), &product.Attributes)
+	}
+
+	if cNode.Props != nil {
+		product.Category = &pb.ProductCategory{
+			MainCategory:  getString(cNode.Props, "main_category"),
+			Subcategory:   getString(cNode.Props, "subcategory"),
+			SpecificType:  getString(cNode.Props, "specific_type"),
+		}
+	}
+
+	for _, sizeItem := range sizesList {
+		if sizeNode, ok := sizeItem.(neo4j.Node); ok {
+			sProps := sizeNode.Props
+			size := &pb.ProductSize{
+				Sku:     getString(sProps, "sku"),
+				Size:    getString(sProps, "size"),
+			}
+			if stock, ok := sProps["stock"].(int64); ok {
+				size.Stock = int32(stock)
+			}
+			if inStock, ok := sProps["in_stock"].(bool); ok {
+				size.InStock = inStock
+			}
+			size.CreatedAt = getInt64(sProps, "created_at")
+			size.UpdatedAt = getInt64(sProps, "updated_at")
+			if variants, ok := sProps["variants"].([]interface{}); ok {
+				for _, v := range variants {
+					if str, ok := v.(string); ok {
+						size.Variants = append(size.Variants, str)
+					}
+				}
+			}
+			product.Sizes = append(product.Sizes, size)
+		}
+	}
+
+	return &product
+}
+
 // Helper
 func getString(props map[string]any, key string) string {
 	if val, ok := props[key]; ok {
@@ -324,6 +760,13 @@ func getString(props map[string]any, key string) string {
 	return ""
 }
 
+func getInt64(props map[string]any, key string) int64 {
+	if val, ok := props[key].(int64); ok {
+		return val
+	}
+	return 0
+}
+
 // validateCypherQuery checks if the query is safe to execute
 func validateCypherQuery(query string) error {
 	// Convert to uppercase for case-insensitive checking
@@ -337,87 +780,187 @@ func validateCypherQuery(query string) error {
 	
 	for _, keyword := range dangerousKeywords {
 		if strings.Contains(upperQuery, keyword) {
-			return fmt.Errorf("unsafe query: contains forbidden keyword '%s'", keyword)
+			return fieldErrorf("query", "unsafe query: contains forbidden keyword '%s'", keyword)
 		}
 	}
 	
 	// Ensure query starts with MATCH
 	trimmed := strings.TrimSpace(upperQuery)
 	if !strings.HasPrefix(trimmed, "MATCH") {
-		return fmt.Errorf("unsafe query: must start with MATCH")
+		return fieldErrorf("query", "unsafe query: must start with MATCH")
 	}
 	
 	// Ensure query contains RETURN
 	if !strings.Contains(upperQuery, "RETURN") {
-		return fmt.Errorf("unsafe query: must contain RETURN clause")
+		return fieldErrorf("query", "unsafe query: must contain RETURN clause")
 	}
 	
 	return nil
 }
 
-/*
-NEED TO RUN ONCE
+// SearchProducts runs a caller-written query returning products as p,
+// within the repository's QueryLimits. It reads at most MaxRows products,
+// and fails with a LimitError rather than hold more than MaxResultBytes
+// of them.
+func (r *ProductRepository) SearchProducts(ctx context.Context, queryStr string) ([]*pb.Product, error) {
+	queryStr, traversal, err := r.checkSearchQuery(queryStr, r.queryLimits.MaxRows)
+	if err != nil {
+		return nil, err
+	}
 
-CREATE FULLTEXT INDEX productSearch
-FOR (p:Product)
-ON EACH [p.name, p.description, p.brand]
-*/
-func (r *ProductRepository) SearchProducts(
-    ctx context.Context,
-    queryStr string,
-) ([]*pb.Product, error) {
-    // Validate the query for safety
-    if err := validateCypherQuery(queryStr); err != nil {
-        return nil, err
-    }
-
-    session := r.driver.NewSession(ctx, neo4j.SessionConfig{
-        AccessMode: neo4j.AccessModeRead,
-    })
-    defer session.Close(ctx)
-
-    result, err := session.ExecuteRead(ctx,
-        func(tx neo4j.ManagedTransaction) (any, error) {
-
-            res, err := tx.Run(ctx, queryStr, nil)
-            if err != nil {
-                return nil, err
-            }
-
-            var products []*pb.Product
-
-            for res.Next(ctx) {
-                record := res.Record()
-
-                // Expect AI to return: RETURN p
-                nodeValue, ok := record.Get("p")
-                if !ok {
-                    continue
-                }
-
-                node, ok := nodeValue.(neo4j.Node)
-                if !ok {
-                    continue
-                }
-
-                props := node.Props
-
-                product := &pb.Product{
-                    Id:          getString(props, "id"),
-                    Name:        getString(props, "name"),
-                    Brand:       getString(props, "brand"),
-                    Description: getString(props, "description"),
-                }
-
-                products = append(products, product)
-            }
-
-            return products, nil
-        })
-
-    if err != nil {
-        return nil, err
-    }
-
-    return result.([]*pb.Product), nil
+	return cachedRead(ctx, r, queryStr, nil, func() ([]*pb.Product, error) {
+		var products []*pb.Product
+		size := 0
+		err := r.readSearch(ctx, queryStr, traversal, func(p *pb.Product) error {
+			if len(products) == r.queryLimits.MaxRows {
+				return errStopSearch
+			}
+			size += proto.Size(p)
+			if size > r.queryLimits.MaxResultBytes {
+				return &LimitError{Field: "query", Limit: r.queryLimits.MaxResultBytes, Actual: size}
+			}
+			products = append(products, p)
+			return nil
+		}, func() error {
+			products, size = nil, 0
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return products, nil
+	})
+}
+
+// StreamSearchProducts runs a query as SearchProducts does, but hands its
+// products to send as they're read, in batches of at most batchSize
+// products and MaxResultBytes, so only one batch is held at a time. It
+// reads at most MaxStreamRows products and never caches them. A failure
+// the driver would retry fails with ErrStreamInterrupted instead once a
+// batch has been sent, as the retry would send it again.
+func (r *ProductRepository) StreamSearchProducts(ctx context.Context, queryStr string, batchSize int, send func([]*pb.Product) error) error {
+	queryStr, traversal, err := r.checkSearchQuery(queryStr, r.queryLimits.MaxStreamRows)
+	if err != nil {
+		return err
+	}
+
+	var batch []*pb.Product
+	size, sent := 0, 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := send(batch); err != nil {
+			return err
+		}
+		sent += len(batch)
+		batch, size = nil, 0
+		return nil
+	}
+	err = r.readSearch(ctx, queryStr, traversal, func(p *pb.Product) error {
+		n := proto.Size(p)
+		if n > r.queryLimits.MaxResultBytes {
+			return &LimitError{Field: "query", Limit: r.queryLimits.MaxResultBytes, Actual: n}
+		}
+		if len(batch) == batchSize || size+n > r.queryLimits.MaxResultBytes {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		batch = append(batch, p)
+		size += n
+		return nil
+	}, func() error {
+		if sent > 0 {
+			return fmt.Errorf("search failed after %d products were sent: %w", sent, ErrStreamInterrupted)
+		}
+		batch, size = nil, 0
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// errStopSearch ends a search read early without failing it.
+var errStopSearch = errors.New("stop search")
+
+// checkSearchQuery checks a caller-written query is read-only and within
+// the query limits, and returns it with a row limit of maxRows.
+func (r *ProductRepository) checkSearchQuery(query string, maxRows int) (string, bool, error) {
+	if err := validateCypherQuery(query); err != nil {
+		return "", false, err
+	}
+	return r.guardQuery(query, maxRows)
+}
+
+// readSearch runs a checked search query and hands each product it returns
+// to each, which can end the read with errStopSearch. Records without a p
+// node are skipped. When the driver retries the read after a transient
+// failure, restart is called first, to drop what the failed attempt
+// handed over or refuse the retry.
+func (r *ProductRepository) readSearch(ctx context.Context, query string, traversal bool, each func(*pb.Product) error, restart func() error) error {
+	if traversal {
+		release, err := r.acquireTraversal(ctx)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	attempts := 0
+	_, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		if attempts++; attempts > 1 {
+			if err := restart(); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := r.checkQueryCost(ctx, tx, query); err != nil {
+			return nil, err
+		}
+
+		res, err := tx.Run(ctx, query, nil)
+		if err != nil {
+			return nil, err
+		}
+		for res.Next(ctx) {
+			p, ok := searchProduct(res.Record())
+			if !ok {
+				continue
+			}
+			if err := each(p); errors.Is(err, errStopSearch) {
+				return nil, nil
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		return nil, res.Err()
+	})
+	return err
+}
+
+// searchProduct reads the product a search query returned as p.
+func searchProduct(record *neo4j.Record) (*pb.Product, bool) {
+	// Expect AI to return: RETURN p
+	nodeValue, ok := record.Get("p")
+	if !ok {
+		return nil, false
+	}
+	node, ok := nodeValue.(neo4j.Node)
+	if !ok {
+		return nil, false
+	}
+
+	props := node.Props
+	return &pb.Product{
+		Id:          getString(props, "id"),
+		Name:        getString(props, "name"),
+		Brand:       getString(props, "brand"),
+		Description: getString(props, "description"),
+	}, true
 }
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// ProductStamp reads what tells one version of a product from another,
+// without its category, sizes or other content: its id, updated_at, which
+// every write to the product or its sizes moves, and the name and
+// description translations overlay, and its tenant, whose callers alone
+// may read it. It bypasses the query cache, so a stamp is never older
+// than the product.
+func (r *ProductRepository) ProductStamp(ctx context.Context, id string) (*pb.Product, error) {
+	if id == "" {
+		return nil, fieldErrorf("id", "product id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (p:Product {id: $id})
+			RETURN p.name AS name, p.description AS description, p.updated_at AS updated_at,
+				p.tenant_id AS tenant_id
+		`, map[string]any{"id": id})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			return nil, notFoundf("product not found")
+		}
+
+		props := res.Record().AsMap()
+		return &pb.Product{
+			Id:          id,
+			TenantId:    getString(props, "tenant_id"),
+			Name:        getString(props, "name"),
+			Description: getString(props, "description"),
+			UpdatedAt:   getInt64(props, "updated_at"),
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*pb.Product), nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// productProperties are the Product node properties behind each product
+// field, by its v1 name. Fields missing here are read from elsewhere:
+// category and sizes from their own nodes, archived from the labels.
+var productProperties = map[string][]string{
+	"id":               {"id"},
+	"tenant_id":        {"tenant_id"},
+	"name":             {"name"},
+	"brand":            {"brand"},
+	"color":            {"color"},
+	"price":            {"price"},
+	"original_price":   {"original_price"},
+	"tags":             {"tags"},
+	"attributes":       {"attributes"},
+	"description":      {"description"},
+	"images":           {"images"},
+	"slug":             {"slug"},
+	"gtin":             {"gtin"},
+	"external_id":      {"external_id"},
+	"shipping":         {"weight_grams", "length_mm", "width_mm", "height_mm"},
+	"tax_class":        {"tax_class"},
+	"meta_title":       {"meta_title"},
+	"meta_description": {"meta_description"},
+	"created_at":       {"created_at"},
+	"updated_at":       {"updated_at"},
+}
+
+type projectionKey struct{}
+
+// WithProjection narrows the product reads made with ctx that support it,
+// GetProduct, ListProducts and FulltextSearch, to the fields named, by
+// their v1 names; the rest come back empty. id is always read. Fields
+// other reads or later steps depend on must be named too.
+func WithProjection(ctx context.Context, fields []string) context.Context {
+	projection := map[string]bool{"id": true}
+	for _, f := range fields {
+		projection[f] = true
+	}
+	return context.WithValue(ctx, projectionKey{}, projection)
+}
+
+// productReturn is the end of a product read: the matches of p's category
+// and sizes, and the RETURN of p, c and sizes, then returned, narrowed to
+// the projection ctx carries. A narrowed p is a map of the properties
+// productFromRecord needs, which reads both.
+func productReturn(ctx context.Context, returned string) string {
+	projection, ok := ctx.Value(projectionKey{}).(map[string]bool)
+	if !ok {
+		return `
+		OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+		OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+		RETURN p, c, collect(s) as sizes` + returned
+	}
+
+	var cypher strings.Builder
+	c, sizes := "null AS c", "[] AS sizes"
+	if projection["category"] {
+		cypher.WriteString("\n\t\tOPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)")
+		c = "c"
+	}
+	if projection["sizes"] {
+		cypher.WriteString("\n\t\tOPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)")
+		sizes = "collect(s) AS sizes"
+	}
+
+	var props []string
+	for field, names := range productProperties {
+		if projection[field] {
+			for _, name := range names {
+				props = append(props, "."+name)
+			}
+		}
+	}
+	// In a fixed order, so the query and its cache key are too
+	slices.Sort(props)
+	if projection["archived"] {
+		props = append(props, "archived: NOT p:"+activeLabel)
+	}
+	cypher.WriteString("\n\t\tRETURN p {" + strings.Join(props, ", ") + "} AS p, " + c + ", " + sizes + returned)
+	return cypher.String()
+}
@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Customers ask questions about products, (p:Product)-[:HAS_QUESTION]->
+// (q:Question)-[:ASKED_BY]->(:Customer), and answer them,
+// (q)-[:HAS_ANSWER]->(a:Answer)-[:ANSWERED_BY]->(:Customer). Both wait for
+// a moderator to approve them before they are shown.
+const (
+	QAPending  = "pending"
+	QAApproved = "approved"
+	QARejected = "rejected"
+
+	// Kinds of posts
+	QuestionPost = "question"
+	AnswerPost   = "answer"
+
+	maxQATextLen        = 2000
+	answersPerQuestion  = 20
+	defaultTopQuestions = 3
+)
+
+var errQuestionNotFound = notFoundf("question not found")
+
+// Question is a customer's question about a product, with its approved
+// answers, oldest first, when listed.
+type Question struct {
+	ID        string
+	ProductID string
+	TenantID  string
+	AuthorID  string
+	Text      string
+	Status    string
+	CreatedAt int64
+	Answers   []Answer
+}
+
+// Answer is a customer's answer to a question.
+type Answer struct {
+	ID         string
+	QuestionID string
+	AuthorID   string
+	Text       string
+	Status     string
+	CreatedAt  int64
+}
+
+func checkQAText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fieldErrorf("text", "text is required")
+	}
+	if len(text) > maxQATextLen {
+		return "", &LimitError{Field: "text", Limit: maxQATextLen, Actual: len(text)}
+	}
+	return text, nil
+}
+
+// PostQuestion stores authorID's question about productID, pending
+// moderation.
+func (r *ProductRepository) PostQuestion(ctx context.Context, productID, authorID, text string) (*Question, error) {
+	if productID == "" {
+		return nil, fieldErrorf("product_id", "product id is required")
+	}
+	if authorID == "" {
+		return nil, fieldErrorf("user_id", "user id is required")
+	}
+	text, err := checkQAText(text)
+	if err != nil {
+		return nil, err
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	q := &Question{
+		ID:        newID(),
+		ProductID: productID,
+		AuthorID:  authorID,
+		Text:      text,
+		Status:    QAPending,
+		CreatedAt: time.Now().UnixMilli(),
+	}
+	_, err = session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			OPTIONAL MATCH (p:Product {id: $product_id})
+			OPTIONAL MATCH (cu:Customer {id: $author_id})
+			FOREACH (_ IN CASE WHEN p IS NULL OR cu IS NULL THEN [] ELSE [1] END |
+				CREATE (p)-[:HAS_QUESTION]->(q:Question {
+					id: $id,
+					tenant_id: coalesce(p.tenant_id, ''),
+					author_id: $author_id,
+					text: $text,
+					status: $status,
+					created_at: $now,
+					updated_at: $now
+				})-[:ASKED_BY]->(cu)
+			)
+			RETURN p IS NOT NULL AS product, cu IS NOT NULL AS author, coalesce(p.tenant_id, '') AS tenant_id
+		`, map[string]any{
+			"id":         q.ID,
+			"product_id": productID,
+			"author_id":  authorID,
+			"text":       text,
+			"status":     QAPending,
+			"now":        q.CreatedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		row := record.AsMap()
+		if product, _ := row["product"].(bool); !product {
+			return nil, notFoundf("product not found")
+		}
+		if author, _ := row["author"].(bool); !author {
+			return nil, errUserNotFound
+		}
+		q.TenantID = getString(row, "tenant_id")
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// PostAnswer stores authorID's answer to an approved question, pending
+// moderation.
+func (r *ProductRepository) PostAnswer(ctx context.Context, questionID, authorID, text string) (*Answer, error) {
+	if questionID == "" {
+		return nil, fieldErrorf("question_id", "question id is required")
+	}
+	if authorID == "" {
+		return nil, fieldErrorf("user_id", "user id is required")
+	}
+	text, err := checkQAText(text)
+	if err != nil {
+		return nil, err
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	a := &Answer{
+		ID:         newID(),
+		QuestionID: questionID,
+		AuthorID:   authorID,
+		Text:       text,
+		Status:     QAPending,
+		CreatedAt:  time.Now().UnixMilli(),
+	}
+	_, err = session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			OPTIONAL MATCH (q:Question {id: $question_id})
+			OPTIONAL MATCH (cu:Customer {id: $author_id})
+			FOREACH (_ IN CASE WHEN q.status = $approved AND cu IS NOT NULL THEN [1] ELSE [] END |
+				CREATE (q)-[:HAS_ANSWER]->(a:Answer {
+					id: $id,
+					tenant_id: q.tenant_id,
+					author_id: $author_id,
+					text: $text,
+					status: $status,
+					created_at: $now,
+					updated_at: $now
+				})-[:ANSWERED_BY]->(cu)
+			)
+			RETURN q.status AS question_status, cu IS NOT NULL AS author
+		`, map[string]any{
+			"id":          a.ID,
+			"question_id": questionID,
+			"author_id":   authorID,
+			"text":        text,
+			"status":      QAPending,
+			"approved":    QAApproved,
+			"now":         a.CreatedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		row := record.AsMap()
+		status := getString(row, "question_status")
+		if status == "" {
+			return nil, errQuestionNotFound
+		}
+		if author, _ := row["author"].(bool); !author {
+			return nil, errUserNotFound
+		}
+		if status != QAApproved {
+			return nil, fmt.Errorf("%w: question %s is %s", ErrInvalidTransition, questionID, status)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// ListQuestions returns up to limit approved questions about productID,
+// newest first, resuming after the cursor.
+func (r *ProductRepository) ListQuestions(ctx context.Context, productID string, after pagetoken.Cursor, limit int) ([]Question, error) {
+	if productID == "" {
+		return nil, fieldErrorf("product_id", "product id is required")
+	}
+	return r.listQuestions(ctx, `
+		MATCH (:Product {id: $product_id})-[:HAS_QUESTION]->(q:Question {status: $approved})
+		WHERE $after_id = ''
+			OR q.created_at < $after_time
+			OR (q.created_at = $after_time AND q.id < $after_id)
+		WITH q, 0 AS rank
+		ORDER BY q.created_at DESC, q.id DESC
+		LIMIT $limit
+	`, map[string]any{
+		"product_id": productID,
+		"after_time": after.LastTime,
+		"after_id":   after.LastID,
+		"limit":      limit,
+	})
+}
+
+// TopQuestions returns up to limit approved questions about productID
+// that have approved answers, the most answered first.
+func (r *ProductRepository) TopQuestions(ctx context.Context, productID string, limit int) ([]Question, error) {
+	if limit <= 0 {
+		limit = defaultTopQuestions
+	}
+	return r.listQuestions(ctx, `
+		MATCH (:Product {id: $product_id})-[:HAS_QUESTION]->(q:Question {status: $approved})
+			-[:HAS_ANSWER]->(a:Answer {status: $approved})
+		WITH q, count(a) AS rank
+		ORDER BY rank DESC, q.created_at DESC, q.id DESC
+		LIMIT $limit
+	`, map[string]any{
+		"product_id": productID,
+		"limit":      limit,
+	})
+}
+
+// listQuestions completes match, which leaves rows of questions q ranked
+// by rank, then newest first, with their approved answers.
+func (r *ProductRepository) listQuestions(ctx context.Context, match string, params map[string]any) ([]Question, error) {
+	params["approved"] = QAApproved
+	params["answers"] = answersPerQuestion
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, match+`
+			OPTIONAL MATCH (q)-[:HAS_ANSWER]->(a:Answer {status: $approved})
+			WITH q, rank, a
+			ORDER BY a.created_at, a.id
+			WITH q, rank, collect(a)[..$answers] AS answers
+			RETURN q, answers
+			ORDER BY rank DESC, q.created_at DESC, q.id DESC
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+
+		questions := []Question{}
+		for res.Next(ctx) {
+			record := res.Record()
+			node, _ := record.Values[0].(neo4j.Node)
+			q := questionFromNode(node, getString(params, "product_id"))
+			answers, _ := record.Values[1].([]any)
+			for _, raw := range answers {
+				if n, ok := raw.(neo4j.Node); ok {
+					q.Answers = append(q.Answers, answerFromNode(n, q.ID))
+				}
+			}
+			questions = append(questions, q)
+		}
+		return questions, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]Question), nil
+}
+
+func questionFromNode(node neo4j.Node, productID string) Question {
+	props := node.Props
+	return Question{
+		ID:        getString(props, "id"),
+		ProductID: productID,
+		TenantID:  getString(props, "tenant_id"),
+		AuthorID:  getString(props, "author_id"),
+		Text:      getString(props, "text"),
+		Status:    getString(props, "status"),
+		CreatedAt: getInt64(props, "created_at"),
+	}
+}
+
+func answerFromNode(node neo4j.Node, questionID string) Answer {
+	props := node.Props
+	return Answer{
+		ID:         getString(props, "id"),
+		QuestionID: questionID,
+		AuthorID:   getString(props, "author_id"),
+		Text:       getString(props, "text"),
+		Status:     getString(props, "status"),
+		CreatedAt:  getInt64(props, "created_at"),
+	}
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// QueryLimits bound the Cypher queries SearchProducts runs for callers, so
+// a generated query can't walk the whole graph:
+//
+//   - variable-length relationships (-[*1..3]-) and quantified path
+//     patterns ({1,3}) need an upper bound of at most MaxDepth hops;
+//   - results are capped at MaxRows, by lowering the query's trailing
+//     LIMIT or adding one, or at MaxStreamRows when streamed;
+//   - SearchProducts stops reading, failing, once the products it has
+//     assembled are estimated at more than MaxResultBytes; streamed
+//     results are sent in chunks of at most that size instead;
+//   - with MaxCost set, queries Neo4j plans to touch more than that many
+//     rows at any step are turned away before they run;
+//   - at most MaxTraversals queries with variable-length patterns, and
+//     subgraph exports, run at once; more wait for a slot.
+type QueryLimits struct {
+	MaxDepth       int
+	MaxRows        int
+	MaxStreamRows  int
+	MaxResultBytes int
+	MaxCost        float64
+	MaxTraversals  int
+}
+
+// DefaultQueryLimits are used unless WithQueryLimits sets others. Cost is
+// not checked by default.
+var DefaultQueryLimits = QueryLimits{
+	MaxDepth:       3,
+	MaxRows:        100,
+	MaxStreamRows:  10000,
+	MaxResultBytes: 8 << 20,
+	MaxTraversals:  8,
+}
+
+// WithQueryLimits bounds caller-written queries by l. Zero fields take
+// their DefaultQueryLimits value, except MaxCost, where zero turns the
+// check off.
+func WithQueryLimits(l QueryLimits) Option {
+	return func(r *ProductRepository) {
+		if l.MaxDepth <= 0 {
+			l.MaxDepth = DefaultQueryLimits.MaxDepth
+		}
+		if l.MaxRows <= 0 {
+			l.MaxRows = DefaultQueryLimits.MaxRows
+		}
+		if l.MaxStreamRows <= 0 {
+			l.MaxStreamRows = DefaultQueryLimits.MaxStreamRows
+		}
+		if l.MaxResultBytes <= 0 {
+			l.MaxResultBytes = DefaultQueryLimits.MaxResultBytes
+		}
+		if l.MaxTraversals <= 0 {
+			l.MaxTraversals = DefaultQueryLimits.MaxTraversals
+		}
+		r.queryLimits = l
+		r.traversals = make(chan struct{}, l.MaxTraversals)
+	}
+}
+
+var (
+	// -[r:SIMILAR_TO*1..3]-, -[*]-, -[*2]- and the like
+	varLengthPattern = regexp.MustCompile(`-\s*\[[^\]]*\*\s*(\d*)\s*(\.\.)?\s*(\d*)\s*\]`)
+	// ((a)-->(b)){1,3} and ((a)-->(b)){2,}
+	quantifierPattern = regexp.MustCompile(`\)\s*\{\s*(\d*)\s*(,)?\s*(\d*)\s*\}`)
+	// ((a)-->(b))+ and ((a)-->(b))*, which have no bound
+	unboundedQuantifier = regexp.MustCompile(`(?i)\)\s*[+*]\s*(?:[(<-]|(?:WHERE|RETURN|WITH|MATCH|OPTIONAL)\b)`)
+	unionPattern        = regexp.MustCompile(`(?i)\bUNION\b`)
+	trailingLimit       = regexp.MustCompile(`(?i)\bLIMIT\s+([^\s;]+)\s*;?\s*$`)
+)
+
+// guardQuery checks query against the repository's limits and returns it
+// with a row limit of maxRows applied. traversal reports whether it walks
+// variable-length patterns.
+func (r *ProductRepository) guardQuery(query string, maxRows int) (guarded string, traversal bool, err error) {
+	limits := r.queryLimits
+
+	for _, pattern := range []*regexp.Regexp{varLengthPattern, quantifierPattern} {
+		for _, m := range pattern.FindAllStringSubmatch(query, -1) {
+			lower, ranged, upper := m[1], m[2] != "", m[3]
+			if !ranged {
+				// A fixed length, *3 or {3}; a bare * has none
+				upper = lower
+			}
+			if upper == "" {
+				return "", false, fieldErrorf("query", "unbounded traversal %q: give it an upper bound of at most %d hops", m[0], limits.MaxDepth)
+			}
+			if depth, _ := strconv.Atoi(upper); depth > limits.MaxDepth {
+				return "", false, fieldErrorf("query", "traversal %q is deeper than %d hops", m[0], limits.MaxDepth)
+			}
+			traversal = true
+		}
+	}
+
+	if m := unboundedQuantifier.FindString(query); m != "" {
+		return "", false, fieldErrorf("query", "unbounded traversal %q: give it an upper bound of at most %d hops", m, limits.MaxDepth)
+	}
+
+	// A LIMIT after a UNION only bounds its last part
+	if unionPattern.MatchString(query) {
+		return "", false, fieldErrorf("query", "UNION queries are not supported")
+	}
+
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	m := trailingLimit.FindStringSubmatchIndex(query)
+	if m == nil {
+		return query + "\nLIMIT " + strconv.Itoa(maxRows), traversal, nil
+	}
+	n, err := strconv.Atoi(query[m[2]:m[3]])
+	if err != nil || n < 0 {
+		return "", false, fieldErrorf("query", "LIMIT must be a whole number up to %d", maxRows)
+	}
+	if n > maxRows {
+		query = query[:m[2]] + strconv.Itoa(maxRows) + query[m[3]:]
+	}
+	return query, traversal, nil
+}
+
+// checkQueryCost plans query with EXPLAIN and rejects it when a step is
+// estimated to produce more than MaxCost rows.
+func (r *ProductRepository) checkQueryCost(ctx context.Context, tx neo4j.ManagedTransaction, query string) error {
+	if r.queryLimits.MaxCost <= 0 {
+		return nil
+	}
+	res, err := tx.Run(ctx, "EXPLAIN "+query, nil)
+	if err != nil {
+		return err
+	}
+	summary, err := res.Consume(ctx)
+	if err != nil {
+		return err
+	}
+	if plan := summary.Plan(); plan != nil {
+		if cost := estimatedRows(plan); cost > r.queryLimits.MaxCost {
+			return fieldErrorf("query", "query is estimated to touch %.0f rows, more than the limit of %.0f", cost, r.queryLimits.MaxCost)
+		}
+	}
+	return nil
+}
+
+// estimatedRows is the largest row estimate of any step in plan.
+func estimatedRows(plan neo4j.Plan) float64 {
+	rows, _ := plan.Arguments()["EstimatedRows"].(float64)
+	for _, child := range plan.Children() {
+		rows = max(rows, estimatedRows(child))
+	}
+	return rows
+}
+
+// acquireTraversal waits for a traversal slot, or for ctx to end. The
+// returned func gives the slot back.
+func (r *ProductRepository) acquireTraversal(ctx context.Context) (release func(), err error) {
+	select {
+	case r.traversals <- struct{}{}:
+		return func() { <-r.traversals }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
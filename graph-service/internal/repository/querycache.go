@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/metrics"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"google.golang.org/protobuf/proto"
+)
+
+// QueryCache keeps the results of hot read queries, such as the home
+// page's category searches, for a short TTL. Entries are keyed by a
+// fingerprint of the Cypher and its parameters. Writes that raise an
+// event clear the cache once they commit; other writes, e.g. holding
+// stock for a checkout, can be missed for up to the TTL.
+//
+// Metrics are published under query_cache: hits, misses, invalidations
+// and entries.
+type QueryCache struct {
+	ttl        time.Duration
+	maxEntries int
+	stats      *expvar.Map
+
+	mu         sync.Mutex
+	generation uint64
+	entries    map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+func NewQueryCache(ttl time.Duration, maxEntries int) *QueryCache {
+	c := &QueryCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		stats:      metrics.Map("query_cache"),
+		entries:    map[string]cacheEntry{},
+	}
+	for _, key := range []string{"hits", "misses", "invalidations", "entries"} {
+		c.stats.Add(key, 0)
+	}
+	return c
+}
+
+// WithQueryCache caches hot read queries in c.
+func WithQueryCache(c *QueryCache) Option {
+	return func(r *ProductRepository) {
+		r.queryCache = c
+	}
+}
+
+// Invalidate drops every entry, including those of reads still running.
+func (c *QueryCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	clear(c.entries)
+	c.stats.Add("invalidations", 1)
+	c.stats.Set("entries", new(expvar.Int))
+}
+
+func (c *QueryCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		c.stats.Add("misses", 1)
+		return nil, false
+	}
+	c.stats.Add("hits", 1)
+	return entry.value, true
+}
+
+// put stores a result read since generation, unless a write invalidated
+// the cache meanwhile. A full cache makes room by dropping expired
+// entries, then an arbitrary one.
+func (c *QueryCache) put(key string, value any, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		now := time.Now()
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < c.maxEntries {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry{value: value, expires: time.Now().Add(c.ttl)}
+	c.stats.Set("entries", intVar(len(c.entries)))
+}
+
+func (c *QueryCache) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+func intVar(n int) *expvar.Int {
+	v := new(expvar.Int)
+	v.Set(int64(n))
+	return v
+}
+
+// queryFingerprint identifies a query's results by its database, Cypher
+// and parameters.
+func queryFingerprint(database, cypher string, params map[string]any) (string, error) {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(database), []byte(cypher), encoded} {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cachedRead returns what read returns for cypher with params, from the
+// cache when a recent run's result is there. Callers get their own
+// copies, free to localize or otherwise change. Reads that must see a
+// write, inside a unit of work or after the client's bookmarks, skip the
+// cache.
+func cachedRead[T proto.Message](ctx context.Context, r *ProductRepository, cypher string, params map[string]any, read func() ([]T, error)) ([]T, error) {
+	if r.queryCache == nil || !cacheable(ctx) {
+		return read()
+	}
+	key, err := queryFingerprint(r.database, cypher, params)
+	if err != nil {
+		return read()
+	}
+	if cached, ok := r.queryCache.get(key); ok {
+		return cloneAll(cached.([]T)), nil
+	}
+
+	generation := r.queryCache.currentGeneration()
+	result, err := read()
+	if err != nil {
+		return nil, err
+	}
+	r.queryCache.put(key, cloneAll(result), generation)
+	return result, nil
+}
+
+func cacheable(ctx context.Context) bool {
+	if _, ok := ctx.Value(unitKey{}).(*unit); ok {
+		return false
+	}
+	carrier, ok := ctx.Value(bookmarksKey{}).(*bookmarkCarrier)
+	return !ok || len(carrier.in) == 0
+}
+
+func cloneAll[T proto.Message](messages []T) []T {
+	clones := make([]T, len(messages))
+	for i, m := range messages {
+		clones[i] = proto.CloneOf(m)
+	}
+	return clones
+}
+
+// invalidatingSession clears the query cache after write transactions
+// that raised an event commit.
+type invalidatingSession struct {
+	neo4j.SessionWithContext
+	cache *QueryCache
+}
+
+func (s invalidatingSession) ExecuteWrite(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	raised := false
+	result, err := s.SessionWithContext.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return work(eventTx{ManagedTransaction: tx, raised: &raised})
+	}, configurers...)
+	if err == nil && raised {
+		s.cache.Invalidate()
+	}
+	return result, err
+}
+
+// eventTx notes whether writeEvent ran in the transaction.
+type eventTx struct {
+	neo4j.ManagedTransaction
+	raised *bool
+}
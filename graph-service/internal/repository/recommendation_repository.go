@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Curated relationship types between products. These are interpolated into
+// Cypher, so only ever pass one of these constants.
+const (
+	RelCrossSell = "CROSS_SELL"
+	RelUpsell    = "UPSELL"
+)
+
+// Algorithmic fallbacks, keyed by relationship type. Both only consider
+// in-stock products of the same tenant; %[1]s is the dialect's in-stock
+// predicate.
+var fallbackQueries = map[string]string{
+	// Complements: same main category, different subcategory, same brand first.
+	RelCrossSell: `
+		MATCH (src:Product {id: $id})-[:BELONGS_TO]->(sc:Category)
+		MATCH (p:Product)-[:BELONGS_TO]->(c:Category)
+		WHERE c.main_category = sc.main_category
+			AND c.subcategory <> sc.subcategory
+			AND coalesce(p.tenant_id, '') = coalesce(src.tenant_id, '')
+			AND NOT p.id IN $exclude
+			AND %[1]s
+		WITH p, c, CASE WHEN p.brand = src.brand THEN 0 ELSE 1 END AS brand_rank
+		ORDER BY brand_rank, p.created_at DESC, p.id
+		LIMIT $limit
+		OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+		RETURN p, c, collect(s) as sizes, brand_rank
+		ORDER BY brand_rank, p.created_at DESC, p.id
+	`,
+	// Step-ups: same category, pricier, closest price first.
+	RelUpsell: `
+		MATCH (src:Product {id: $id})-[:BELONGS_TO]->(c:Category)
+		MATCH (p:Product)-[:BELONGS_TO]->(c)
+		WHERE p.price > src.price
+			AND coalesce(p.tenant_id, '') = coalesce(src.tenant_id, '')
+			AND NOT p.id IN $exclude
+			AND %[1]s
+		WITH p, c
+		ORDER BY p.price, p.id
+		LIMIT $limit
+		OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+		RETURN p, c, collect(s) as sizes
+		ORDER BY p.price, p.id
+	`,
+}
+
+// SetMerchandisingLinks replaces the product's outgoing relType edges,
+// assigning positions in list order.
+func (r *ProductRepository) SetMerchandisingLinks(ctx context.Context, relType, productID string, relatedIDs []string) error {
+	if _, ok := fallbackQueries[relType]; !ok {
+		return fieldErrorf("type", "unknown relationship type %q", relType)
+	}
+	if productID == "" {
+		return fieldErrorf("product_id", "product id is required")
+	}
+	seen := make(map[string]bool, len(relatedIDs))
+	for _, rid := range relatedIDs {
+		if rid == productID {
+			return fieldErrorf("related_product_ids", "a product cannot be linked to itself")
+		}
+		if seen[rid] {
+			return fieldErrorf("related_product_ids", "product %q listed more than once", rid)
+		}
+		seen[rid] = true
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		if err := checkProductsExist(ctx, tx, append([]string{productID}, relatedIDs...)); err != nil {
+			return nil, err
+		}
+
+		res, err := tx.Run(ctx, `
+			MATCH (src:Product {id: $id})
+			OPTIONAL MATCH (src)-[old:`+relType+`]->()
+			DELETE old
+			WITH DISTINCT src
+			UNWIND range(0, size($related_ids) - 1) AS i
+			MATCH (p:Product {id: $related_ids[i]})
+			CREATE (src)-[:`+relType+` {position: i, created_at: $now}]->(p)
+			RETURN count(*) AS linked
+		`, map[string]any{
+			"id":          productID,
+			"related_ids": relatedIDs,
+			"now":         time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		_, err = res.Consume(ctx)
+		return nil, err
+	})
+
+	return err
+}
+
+// GetMerchandisedRecommendations returns up to limit products related to
+// productID: curated relType edges in position order, topped up with the
+// algorithmic fallback for that type.
+func (r *ProductRepository) GetMerchandisedRecommendations(ctx context.Context, relType, productID string, limit int) ([]*pb.Recommendation, error) {
+	fallback, ok := fallbackQueries[relType]
+	if !ok {
+		return nil, fieldErrorf("type", "unknown relationship type %q", relType)
+	}
+	if productID == "" {
+		return nil, fieldErrorf("product_id", "product id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (src:Product {id: $id})
+			RETURN src.id
+		`, map[string]any{"id": productID})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			return nil, notFoundf("product not found")
+		}
+
+		res, err = tx.Run(ctx, `
+			MATCH (:Product {id: $id})-[rel:`+relType+`]->(p:Product)
+			WITH p, rel.position AS position
+			ORDER BY position
+			LIMIT $limit
+			OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+			OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+			RETURN p, c, collect(s) as sizes, position
+			ORDER BY position
+		`, map[string]any{
+			"id":    productID,
+			"limit": limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		recs := []*pb.Recommendation{}
+		exclude := []string{productID}
+		for res.Next(ctx) {
+			product := productFromRecord(res.Record())
+			recs = append(recs, &pb.Recommendation{Product: product, Curated: true})
+			exclude = append(exclude, product.Id)
+		}
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		if len(recs) >= limit {
+			return recs, nil
+		}
+
+		res, err = tx.Run(ctx, fmt.Sprintf(fallback, r.dialect.exists(inStockPattern)), map[string]any{
+			"id":      productID,
+			"exclude": exclude,
+			"limit":   limit - len(recs),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for res.Next(ctx) {
+			recs = append(recs, &pb.Recommendation{Product: productFromRecord(res.Record())})
+		}
+		return recs, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]*pb.Recommendation), nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+)
+
+// Repository is the storage contract for product operations. The Neo4j
+// ProductRepository and PostgresRepository both implement it; the
+// conformance package checks that they behave the same.
+//
+// Collections, bundles, recommendations, search and the other graph
+// features are only available on Neo4j.
+type Repository interface {
+	CreateProduct(ctx context.Context, p *pb.Product) error
+	GetProduct(ctx context.Context, id string) (*pb.Product, error)
+	ResolveProduct(ctx context.Context, kind, value string) (*pb.Product, string, error)
+	UpdateProduct(ctx context.Context, p *pb.Product) ([]*pb.FieldChange, error)
+	DeleteProduct(ctx context.Context, id string) error
+	UpdateStock(ctx context.Context, sku string, stock int32) error
+	AddProductSize(ctx context.Context, productID string, size *pb.ProductSize) error
+	SetProductsArchived(ctx context.Context, ids []string, archived bool) (int, error)
+	ProductTenants(ctx context.Context, ids, skus []string) (map[string]string, error)
+
+	ListProducts(ctx context.Context, tenantID, afterID string, limit int, includeArchive bool) ([]*pb.Product, error)
+	ListProductsUpdatedSince(ctx context.Context, tenantID string, since int64, after pagetoken.Cursor, limit int) ([]*pb.Product, error)
+	GetNewArrivals(ctx context.Context, filter *pb.CatalogFilter, maxAge time.Duration, after pagetoken.Cursor, limit int) ([]*pb.Product, error)
+	GetDeals(ctx context.Context, filter *pb.CatalogFilter, after pagetoken.Cursor, limit int) ([]*pb.Deal, error)
+
+	UpsertProductTranslation(ctx context.Context, productID, locale, name, description string) error
+	LocalizeProducts(ctx context.Context, products []*pb.Product, locale string) error
+}
+
+var (
+	_ Repository = (*ProductRepository)(nil)
+	_ Repository = (*PostgresRepository)(nil)
+)
@@ -0,0 +1,409 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+/*
+NEED TO RUN ONCE IN NEO4J
+
+CREATE INDEX reservation_status_expires IF NOT EXISTS
+FOR (r:Reservation) ON (r.status, r.expires_at);
+*/
+
+// Reservation statuses as stored on Reservation nodes.
+const (
+	reservationReserved  = "reserved"
+	reservationCommitted = "committed"
+	reservationReleased  = "released"
+	reservationExpired   = "expired"
+)
+
+var reservationStatuses = map[string]pb.ReservationStatus{
+	reservationReserved:  pb.ReservationStatus_RESERVED,
+	reservationCommitted: pb.ReservationStatus_COMMITTED,
+	reservationReleased:  pb.ReservationStatus_RELEASED,
+	reservationExpired:   pb.ReservationStatus_EXPIRED,
+}
+
+const (
+	defaultReservationTTL = 15 * time.Minute
+	maxReservationTTL     = 24 * time.Hour
+)
+
+var errReservationNotFound = notFoundf("reservation not found")
+
+// WithReservationTTL sets how long a reservation holds its stock when the
+// caller doesn't say.
+func WithReservationTTL(ttl time.Duration) Option {
+	return func(r *ProductRepository) {
+		r.reservationTTL = ttl
+	}
+}
+
+func validateReservation(res *pb.Reservation) error {
+	if len(res.GetItems()) == 0 {
+		return fieldErrorf("items", "reservation needs at least one item")
+	}
+	seen := make(map[string]bool, len(res.Items))
+	for _, item := range res.Items {
+		if item.GetSku() == "" || item.GetQuantity() <= 0 {
+			return fieldErrorf("items", "reservation items need a sku and a positive quantity")
+		}
+		if seen[item.Sku] {
+			return fieldErrorf("items", "sku %q listed more than once", item.Sku)
+		}
+		seen[item.Sku] = true
+	}
+	return nil
+}
+
+func reservationItemParams(items []*pb.ReservationItem) []map[string]any {
+	params := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		params = append(params, map[string]any{
+			"sku":      item.Sku,
+			"quantity": item.Quantity,
+		})
+	}
+	return params
+}
+
+// ReserveStock takes every item's quantity off stock and records it
+// against the reservation until it is committed, released or expires
+// after ttl (the repository default when zero). Like OrderBundle it
+// decrements first and rolls back if any SKU would go negative. It fills
+// in the reservation's id, status and timestamps.
+func (r *ProductRepository) ReserveStock(ctx context.Context, res *pb.Reservation, ttl time.Duration) error {
+	if err := r.prepareReservation(res, ttl, time.Now().UnixMilli()); err != nil {
+		return err
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return nil, reserveStock(ctx, tx, res)
+	})
+	if isConstraintViolation(err) {
+		return fieldErrorf("id", "reservation %q already exists", res.Id)
+	}
+	return err
+}
+
+// CommitReservation makes a reservation's stock decrement permanent, as
+// when its order is placed. Committing twice is a no-op; a reservation
+// that was released or has expired can't be committed.
+func (r *ProductRepository) CommitReservation(ctx context.Context, id string) error {
+	if id == "" {
+		return fieldErrorf("id", "reservation id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return nil, commitReservation(ctx, tx, id, time.Now().UnixMilli())
+	})
+
+	return err
+}
+
+// ReleaseReservation returns a reservation's stock, as when its checkout
+// is abandoned. Releasing twice, or after expiry, is a no-op; a committed
+// reservation can't be released.
+func (r *ProductRepository) ReleaseReservation(ctx context.Context, id string) error {
+	if id == "" {
+		return fieldErrorf("id", "reservation id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return nil, releaseReservation(ctx, tx, id, time.Now().UnixMilli())
+	})
+
+	return err
+}
+
+// ExpireReservations returns the stock of up to limit reservations past
+// their expiry, marks them expired, fails any order still waiting on them
+// and emits a reservation.expired event for each, all in one transaction. It returns the expired ids; fewer
+// than limit means none are left.
+func (r *ProductRepository) ExpireReservations(ctx context.Context, limit int) ([]string, error) {
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		now := time.Now().UnixMilli()
+
+		// Touching updated_at locks each candidate before its status is
+		// read again, so a concurrent commit or release wins cleanly.
+		res, err := tx.Run(ctx, `
+			MATCH (r:Reservation {status: $reserved})
+			WHERE r.expires_at <= $now
+			WITH r
+			ORDER BY r.expires_at
+			LIMIT $limit
+			SET r.updated_at = $now
+			WITH r
+			WHERE r.status = $reserved
+			RETURN r
+		`, map[string]any{"reserved": reservationReserved, "now": now, "limit": limit})
+		if err != nil {
+			return nil, err
+		}
+		records, err := res.Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make([]string, 0, len(records))
+		for _, record := range records {
+			node, _ := record.Values[0].(neo4j.Node)
+			expired := reservationFromNode(node)
+			expired.Status = pb.ReservationStatus_EXPIRED
+
+			expired.Items, err = restock(ctx, tx, expired.Id, reservationExpired, now)
+			if err != nil {
+				return nil, err
+			}
+
+			// An order still waiting on payment has lost its stock
+			_, err = tx.Run(ctx, `
+				MATCH (o:Order {status: $pending})-[:RESERVED]->(:Reservation {id: $id})
+				SET o.status = $failed,
+					o.failure_reason = 'reservation expired',
+					o.updated_at = $now
+			`, map[string]any{"id": expired.Id, "pending": orderPendingPayment, "failed": orderPaymentFailed, "now": now})
+			if err != nil {
+				return nil, err
+			}
+
+			ev := events.New(events.ReservationExpired, "", expired)
+			if err := writeEvent(ctx, tx, ev); err != nil {
+				return nil, err
+			}
+			ids = append(ids, expired.Id)
+		}
+		return ids, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]string), nil
+}
+
+// prepareReservation validates res and sets its id, status, expiry and
+// timestamps.
+func (r *ProductRepository) prepareReservation(res *pb.Reservation, ttl time.Duration, now int64) error {
+	if err := validateReservation(res); err != nil {
+		return err
+	}
+	if ttl == 0 {
+		ttl = r.reservationTTL
+	}
+	if ttl < 0 || ttl > maxReservationTTL {
+		return fieldErrorf("ttl_seconds", "reservation ttl must be between 0 and %s", maxReservationTTL)
+	}
+
+	res.Id = idOrNew(res.Id)
+	res.Status = pb.ReservationStatus_RESERVED
+	res.ExpiresAt = now + ttl.Milliseconds()
+	res.CreatedAt, res.UpdatedAt = now, now
+	return nil
+}
+
+// reserveStock creates res, prepared by prepareReservation, and takes its
+// items off stock.
+func reserveStock(ctx context.Context, tx neo4j.ManagedTransaction, res *pb.Reservation) error {
+	skus := make([]string, 0, len(res.Items))
+	for _, item := range res.Items {
+		skus = append(skus, item.Sku)
+	}
+	if err := checkSkusExist(ctx, tx, skus); err != nil {
+		return err
+	}
+
+	result, err := tx.Run(ctx, `
+		CREATE (r:Reservation {
+			id: $id,
+			tenant_id: $tenant_id,
+			status: $status,
+			expires_at: $expires_at,
+			created_at: $now,
+			updated_at: $now
+		})
+		WITH r
+		UNWIND $items AS item
+		MATCH (s:Size {sku: item.sku})
+		SET s.stock = s.stock - item.quantity
+		SET s.in_stock = s.stock > 0,
+			s.updated_at = $now
+		CREATE (r)-[:HOLDS {quantity: item.quantity}]->(s)
+		WITH s
+		OPTIONAL MATCH (p:Product)-[:HAS_SIZE]->(s)
+		SET p.updated_at = $now
+		RETURN s.sku AS sku, s.stock AS stock, p.id AS product_id
+	`, map[string]any{
+		"id":         res.Id,
+		"tenant_id":  res.TenantId,
+		"status":     reservationReserved,
+		"expires_at": res.ExpiresAt,
+		"items":      reservationItemParams(res.Items),
+		"now":        res.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	records, err := result.Collect(ctx)
+	if err != nil {
+		return err
+	}
+
+	var short []string
+	for _, record := range records {
+		if stock := getInt64(record.AsMap(), "stock"); stock < 0 {
+			short = append(short, getString(record.AsMap(), "sku"))
+		}
+	}
+	if len(short) > 0 {
+		return fmt.Errorf("%w for %s", ErrInsufficientStock, strings.Join(short, ", "))
+	}
+
+	return writeStockEvents(ctx, tx, records)
+}
+
+// commitReservation is CommitReservation inside tx.
+func commitReservation(ctx context.Context, tx neo4j.ManagedTransaction, id string, now int64) error {
+	status, expiresAt, err := lockReservation(ctx, tx, id, now)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status == reservationCommitted:
+		return nil
+	case status != reservationReserved:
+		return fmt.Errorf("%w: reservation %s is %s", ErrReservationClosed, id, status)
+	case expiresAt <= now:
+		return fmt.Errorf("%w: reservation %s has expired", ErrReservationClosed, id)
+	}
+
+	_, err = tx.Run(ctx, `
+		MATCH (r:Reservation {id: $id})
+		SET r.status = $status
+	`, map[string]any{"id": id, "status": reservationCommitted})
+	return err
+}
+
+// releaseReservation is ReleaseReservation inside tx.
+func releaseReservation(ctx context.Context, tx neo4j.ManagedTransaction, id string, now int64) error {
+	status, _, err := lockReservation(ctx, tx, id, now)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case reservationReleased, reservationExpired:
+		return nil
+	case reservationCommitted:
+		return fmt.Errorf("%w: reservation %s is %s", ErrReservationClosed, id, status)
+	}
+
+	_, err = restock(ctx, tx, id, reservationReleased, now)
+	return err
+}
+
+// lockReservation write-locks the reservation and returns its status and
+// expiry as of the lock.
+func lockReservation(ctx context.Context, tx neo4j.ManagedTransaction, id string, now int64) (string, int64, error) {
+	res, err := tx.Run(ctx, `
+		MATCH (r:Reservation {id: $id})
+		SET r.updated_at = $now
+		RETURN r.status AS status, r.expires_at AS expires_at
+	`, map[string]any{"id": id, "now": now})
+	if err != nil {
+		return "", 0, err
+	}
+	if !res.Next(ctx) {
+		if err := res.Err(); err != nil {
+			return "", 0, err
+		}
+		return "", 0, errReservationNotFound
+	}
+
+	row := res.Record().AsMap()
+	return getString(row, "status"), getInt64(row, "expires_at"), nil
+}
+
+// restock returns a reservation's held quantities to stock and closes it
+// with status. It returns the items that were held.
+func restock(ctx context.Context, tx neo4j.ManagedTransaction, id, status string, now int64) ([]*pb.ReservationItem, error) {
+	res, err := tx.Run(ctx, `
+		MATCH (r:Reservation {id: $id})
+		SET r.status = $status
+		WITH r
+		MATCH (r)-[h:HOLDS]->(s:Size)
+		SET s.stock = s.stock + h.quantity
+		SET s.in_stock = s.stock > 0,
+			s.updated_at = $now
+		WITH h, s
+		OPTIONAL MATCH (p:Product)-[:HAS_SIZE]->(s)
+		SET p.updated_at = $now
+		RETURN s.sku AS sku, h.quantity AS quantity, s.stock AS stock, p.id AS product_id
+	`, map[string]any{"id": id, "status": status, "now": now})
+	if err != nil {
+		return nil, err
+	}
+	records, err := res.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*pb.ReservationItem, 0, len(records))
+	for _, record := range records {
+		row := record.AsMap()
+		items = append(items, &pb.ReservationItem{
+			Sku:      getString(row, "sku"),
+			Quantity: int32(getInt64(row, "quantity")),
+		})
+	}
+	return items, writeStockEvents(ctx, tx, records)
+}
+
+// writeStockEvents emits a stock.updated event per record with sku, stock
+// and product_id columns.
+func writeStockEvents(ctx context.Context, tx neo4j.ManagedTransaction, records []*neo4j.Record) error {
+	for _, record := range records {
+		row := record.AsMap()
+		ev := events.New(events.StockUpdated, getString(row, "product_id"), map[string]int64{"stock": getInt64(row, "stock")})
+		ev.SKU = getString(row, "sku")
+		if err := writeEvent(ctx, tx, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reservationFromNode(node neo4j.Node) *pb.Reservation {
+	props := node.Props
+	return &pb.Reservation{
+		Id:        getString(props, "id"),
+		TenantId:  getString(props, "tenant_id"),
+		Status:    reservationStatuses[getString(props, "status")],
+		ExpiresAt: getInt64(props, "expires_at"),
+		CreatedAt: getInt64(props, "created_at"),
+		UpdatedAt: getInt64(props, "updated_at"),
+	}
+}
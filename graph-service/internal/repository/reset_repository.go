@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const resetBatchSize = 10000
+
+// ResetGraph deletes every node and relationship but the record of
+// applied migrations, which with the schema is left in place, and
+// returns how many nodes went. It's meant for test environments; batches
+// keep each transaction small on a large graph.
+func (r *ProductRepository) ResetGraph(ctx context.Context) (int64, error) {
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	var total int64
+	for {
+		result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			res, err := tx.Run(ctx, `
+				MATCH (n)
+				WHERE NOT n:SchemaMigration
+				WITH n LIMIT $batch
+				DETACH DELETE n
+				RETURN count(*) AS deleted
+			`, map[string]any{"batch": resetBatchSize})
+			if err != nil {
+				return nil, err
+			}
+			record, err := res.Single(ctx)
+			if err != nil {
+				return nil, err
+			}
+			deleted, _ := record.Values[0].(int64)
+			return deleted, nil
+		})
+		if err != nil {
+			return total, err
+		}
+		deleted := result.(int64)
+		total += deleted
+		if deleted < resetBatchSize {
+			break
+		}
+	}
+
+	if r.queryCache != nil {
+		r.queryCache.Invalidate()
+	}
+	if r.overviews != nil {
+		r.overviews.clear()
+	}
+	return total, nil
+}
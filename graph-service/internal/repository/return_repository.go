@@ -0,0 +1,387 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Return statuses as stored on Return nodes.
+const (
+	returnRequested = "requested"
+	returnApproved  = "approved"
+	returnCompleted = "completed"
+)
+
+var returnStatuses = map[string]pb.ReturnStatus{
+	returnRequested: pb.ReturnStatus_RETURN_REQUESTED,
+	returnApproved:  pb.ReturnStatus_RETURN_APPROVED,
+	returnCompleted: pb.ReturnStatus_RETURN_COMPLETED,
+}
+
+var errReturnNotFound = notFoundf("return not found")
+
+func validateReturn(ret *pb.Return) error {
+	if ret.GetOrderId() == "" {
+		return fieldErrorf("order_id", "order id is required")
+	}
+	if len(ret.GetLines()) == 0 {
+		return fieldErrorf("lines", "return needs at least one line")
+	}
+	seen := make(map[string]bool, len(ret.Lines))
+	for _, line := range ret.Lines {
+		if line.GetSku() == "" || line.GetQuantity() <= 0 {
+			return fieldErrorf("lines", "return lines need a sku and a positive quantity")
+		}
+		if seen[line.Sku] {
+			return fieldErrorf("lines", "sku %q listed more than once", line.Sku)
+		}
+		seen[line.Sku] = true
+	}
+	return nil
+}
+
+// CreateReturn records a requested return of some of an order's lines and
+// prices its refund at what was paid. Each line can only claim what the
+// order's other returns haven't. It fills in the return's id, status,
+// refund amount and timestamps.
+func (r *ProductRepository) CreateReturn(ctx context.Context, ret *pb.Return) error {
+	if err := validateReturn(ret); err != nil {
+		return err
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	now := time.Now().UnixMilli()
+	ret.Id = idOrNew(ret.Id)
+	ret.Status = pb.ReturnStatus_RETURN_REQUESTED
+	ret.RefundId = ""
+	ret.CreatedAt, ret.UpdatedAt = now, now
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		// Lock the order so concurrent returns can't claim the same units
+		res, err := tx.Run(ctx, `
+			MATCH (o:Order {id: $id})
+			SET o.updated_at = $now
+			RETURN o.status AS status
+		`, map[string]any{"id": ret.OrderId, "now": now})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, errOrderNotFound
+		}
+		switch status := getString(res.Record().AsMap(), "status"); status {
+		case orderPlaced, orderReturning, orderPartlyReturned:
+		default:
+			return nil, fmt.Errorf("%w: order %s is %s", ErrInvalidTransition, ret.OrderId, status)
+		}
+
+		res, err = tx.Run(ctx, `
+			MATCH (o:Order {id: $id})-[:HAS_LINE]->(l:OrderLine)
+			OPTIONAL MATCH (other:Return)-[c:RETURNS]->(l)
+			RETURN l.sku AS sku, l.quantity AS quantity, l.unit_price AS unit_price,
+				coalesce(l.discount, 0.0) AS discount, coalesce(l.tax, 0.0) AS tax,
+				sum(c.quantity) AS claimed
+		`, map[string]any{"id": ret.OrderId})
+		if err != nil {
+			return nil, err
+		}
+		records, err := res.Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		lines := make(map[string]map[string]any, len(records))
+		for _, record := range records {
+			row := record.AsMap()
+			lines[getString(row, "sku")] = row
+		}
+
+		ret.RefundAmount = 0
+		for _, line := range ret.Lines {
+			row, ok := lines[line.Sku]
+			if !ok {
+				return nil, fieldErrorf("lines", "sku %q is not in order %s", line.Sku, ret.OrderId)
+			}
+			left := getInt64(row, "quantity") - getInt64(row, "claimed")
+			if int64(line.Quantity) > left {
+				return nil, fieldErrorf("lines", "only %d of sku %q can still be returned", max(left, 0), line.Sku)
+			}
+			// What was paid for the line, after its discount and with its
+			// tax, is refunded pro rata
+			price, _ := row["unit_price"].(float64)
+			discount, _ := row["discount"].(float64)
+			tax, _ := row["tax"].(float64)
+			ret.RefundAmount += (price + (tax-discount)/float64(getInt64(row, "quantity"))) * float64(line.Quantity)
+		}
+		ret.RefundAmount = roundCents(ret.RefundAmount)
+
+		_, err = tx.Run(ctx, `
+			MATCH (o:Order {id: $order_id})
+			CREATE (rt:Return {
+				id: $id,
+				order_id: $order_id,
+				reason: $reason,
+				status: $status,
+				refund_amount: $refund_amount,
+				refund_id: '',
+				created_at: $now,
+				updated_at: $now
+			})-[:FOR_ORDER]->(o)
+			SET o.status = $returning
+			WITH rt, o
+			UNWIND $lines AS line
+			MATCH (o)-[:HAS_LINE]->(l:OrderLine {sku: line.sku})
+			CREATE (rt)-[:RETURNS {quantity: line.quantity}]->(l)
+		`, map[string]any{
+			"id":            ret.Id,
+			"order_id":      ret.OrderId,
+			"reason":        ret.Reason,
+			"status":        returnRequested,
+			"refund_amount": ret.RefundAmount,
+			"returning":     orderReturning,
+			"lines":         returnLineParams(ret.Lines),
+			"now":           now,
+		})
+		return nil, err
+	})
+	if isConstraintViolation(err) {
+		return fieldErrorf("id", "return %q already exists", ret.Id)
+	}
+	return err
+}
+
+func returnLineParams(lines []*pb.ReturnLine) []map[string]any {
+	params := make([]map[string]any, 0, len(lines))
+	for _, line := range lines {
+		params = append(params, map[string]any{
+			"sku":      line.Sku,
+			"quantity": line.Quantity,
+		})
+	}
+	return params
+}
+
+// ApproveReturn accepts a requested return. Approving twice is a no-op.
+func (r *ProductRepository) ApproveReturn(ctx context.Context, id string) (*pb.Return, error) {
+	if id == "" {
+		return nil, fieldErrorf("id", "return id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		now := time.Now().UnixMilli()
+		status, err := lockReturn(ctx, tx, id, now)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case returnRequested:
+			_, err := tx.Run(ctx, `
+				MATCH (rt:Return {id: $id})
+				SET rt.status = $status
+			`, map[string]any{"id": id, "status": returnApproved})
+			if err != nil {
+				return nil, err
+			}
+		case returnApproved:
+		default:
+			return nil, fmt.Errorf("%w: return %s is %s", ErrInvalidTransition, id, status)
+		}
+		return readReturn(ctx, tx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.Return), nil
+}
+
+// CompleteReturn puts an approved return's units back in stock, records
+// its refund and updates its order's status, which becomes returned once
+// every line has come back and no other return is open. Completing twice
+// is a no-op.
+func (r *ProductRepository) CompleteReturn(ctx context.Context, id, refundID string) (*pb.Return, error) {
+	if id == "" {
+		return nil, fieldErrorf("id", "return id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		now := time.Now().UnixMilli()
+		status, err := lockReturn(ctx, tx, id, now)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status {
+		case returnCompleted:
+			return readReturn(ctx, tx, id)
+		case returnApproved:
+		default:
+			return nil, fmt.Errorf("%w: return %s is %s, not approved", ErrInvalidTransition, id, status)
+		}
+
+		// Sizes deleted since the order was placed have nothing to restock
+		res, err := tx.Run(ctx, `
+			MATCH (rt:Return {id: $id})
+			SET rt.status = $status, rt.refund_id = $refund_id
+			WITH rt
+			MATCH (rt)-[c:RETURNS]->(:OrderLine)-[:OF_SIZE]->(s:Size)
+			SET s.stock = s.stock + c.quantity
+			SET s.in_stock = s.stock > 0,
+				s.updated_at = $now
+			WITH s
+			OPTIONAL MATCH (p:Product)-[:HAS_SIZE]->(s)
+			SET p.updated_at = $now
+			RETURN s.sku AS sku, s.stock AS stock, p.id AS product_id
+		`, map[string]any{"id": id, "status": returnCompleted, "refund_id": refundID, "now": now})
+		if err != nil {
+			return nil, err
+		}
+		records, err := res.Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeStockEvents(ctx, tx, records); err != nil {
+			return nil, err
+		}
+
+		_, err = tx.Run(ctx, `
+			MATCH (:Return {id: $id})-[:FOR_ORDER]->(o:Order)-[:HAS_LINE]->(l:OrderLine)
+			OPTIONAL MATCH (done:Return {status: $completed})-[c:RETURNS]->(l)
+			WITH o, l, sum(c.quantity) AS returned
+			WITH o, collect(returned >= l.quantity) AS full
+			OPTIONAL MATCH (open:Return)-[:FOR_ORDER]->(o)
+			WHERE open.status IN [$requested, $approved]
+			WITH o, full, count(open) AS open
+			SET o.status = CASE
+					WHEN open > 0 THEN $returning
+					WHEN all(f IN full WHERE f) THEN $returned
+					ELSE $partly_returned
+				END,
+				o.updated_at = $now
+		`, map[string]any{
+			"id":              id,
+			"completed":       returnCompleted,
+			"requested":       returnRequested,
+			"approved":        returnApproved,
+			"returning":       orderReturning,
+			"returned":        orderReturned,
+			"partly_returned": orderPartlyReturned,
+			"now":             now,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		ret, err := readReturn(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		return ret, writeEvent(ctx, tx, events.New(events.ReturnCompleted, "", ret))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.Return), nil
+}
+
+func (r *ProductRepository) GetReturn(ctx context.Context, id string) (*pb.Return, error) {
+	if id == "" {
+		return nil, fieldErrorf("id", "return id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return readReturn(ctx, tx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.Return), nil
+}
+
+// lockReturn write-locks the return and returns its status as of the lock.
+func lockReturn(ctx context.Context, tx neo4j.ManagedTransaction, id string, now int64) (string, error) {
+	res, err := tx.Run(ctx, `
+		MATCH (rt:Return {id: $id})
+		SET rt.updated_at = $now
+		RETURN rt.status AS status
+	`, map[string]any{"id": id, "now": now})
+	if err != nil {
+		return "", err
+	}
+	if !res.Next(ctx) {
+		if err := res.Err(); err != nil {
+			return "", err
+		}
+		return "", errReturnNotFound
+	}
+	return getString(res.Record().AsMap(), "status"), nil
+}
+
+func readReturn(ctx context.Context, tx neo4j.ManagedTransaction, id string) (*pb.Return, error) {
+	res, err := tx.Run(ctx, `
+		MATCH (rt:Return {id: $id})
+		OPTIONAL MATCH (rt)-[c:RETURNS]->(l:OrderLine)
+		WITH rt, c, l
+		ORDER BY l.sku
+		RETURN rt, collect({sku: l.sku, quantity: c.quantity}) AS lines
+	`, map[string]any{"id": id})
+	if err != nil {
+		return nil, err
+	}
+	if !res.Next(ctx) {
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errReturnNotFound
+	}
+
+	record := res.Record()
+	node, _ := record.Values[0].(neo4j.Node)
+	lines, _ := record.Values[1].([]any)
+
+	props := node.Props
+	ret := &pb.Return{
+		Id:        getString(props, "id"),
+		OrderId:   getString(props, "order_id"),
+		Reason:    getString(props, "reason"),
+		Status:    returnStatuses[getString(props, "status")],
+		RefundId:  getString(props, "refund_id"),
+		CreatedAt: getInt64(props, "created_at"),
+		UpdatedAt: getInt64(props, "updated_at"),
+	}
+	ret.RefundAmount, _ = props["refund_amount"].(float64)
+
+	for _, item := range lines {
+		m, ok := item.(map[string]any)
+		if !ok || m["sku"] == nil {
+			continue
+		}
+		ret.Lines = append(ret.Lines, &pb.ReturnLine{
+			Sku:      getString(m, "sku"),
+			Quantity: int32(getInt64(m, "quantity")),
+		})
+	}
+	return ret, nil
+}
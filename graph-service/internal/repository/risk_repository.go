@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const defaultFlaggedLimit = 50
+
+// CountRecentOrders counts the customer's orders, in any status, created
+// at or after since.
+func (r *ProductRepository) CountRecentOrders(ctx context.Context, customerID string, since int64) (int, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (:Customer {id: $id})-[:PLACED]->(o:Order)
+			WHERE o.created_at >= $since
+			RETURN count(o) AS orders
+		`, map[string]any{"id": customerID, "since": since})
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return getInt64(record.AsMap(), "orders"), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.(int64)), nil
+}
+
+// HoldOrder sets aside the stock of an order pending payment, whose
+// payment is authorized but not captured, and holds it for review with
+// its risk assessment.
+func (r *ProductRepository) HoldOrder(ctx context.Context, id, paymentID string, score float64, reasons []string) (*pb.Order, error) {
+	return r.closeRiskCheck(ctx, id, paymentID, orderUnderReview, "", score, reasons)
+}
+
+// DeclineOrder returns the stock of an order pending payment and marks it
+// declined with its risk assessment.
+func (r *ProductRepository) DeclineOrder(ctx context.Context, id, reason string, score float64, reasons []string) (*pb.Order, error) {
+	return r.closeRiskCheck(ctx, id, "", orderDeclined, reason, score, reasons)
+}
+
+func (r *ProductRepository) closeRiskCheck(ctx context.Context, id, paymentID, status, reason string, score float64, reasons []string) (*pb.Order, error) {
+	if id == "" {
+		return nil, fieldErrorf("id", "order id is required")
+	}
+	if reasons == nil {
+		reasons = []string{}
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		now := time.Now().UnixMilli()
+		current, err := lockOrder(ctx, tx, id, now)
+		if err != nil {
+			return nil, err
+		}
+		if current != orderPendingPayment {
+			return nil, fmt.Errorf("%w: order %s is %s, not pending payment", ErrInvalidTransition, id, current)
+		}
+
+		// Held stock is committed so the reservation can't expire while
+		// the order waits for a reviewer
+		if status == orderUnderReview {
+			err = commitReservation(ctx, tx, id, now)
+		} else {
+			err = releaseReservation(ctx, tx, id, now)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if status == orderDeclined {
+			if err := releaseCoupons(ctx, tx, id, now); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err = tx.Run(ctx, `
+			MATCH (o:Order {id: $id})
+			SET o.risk_score = $score, o.risk_reasons = $reasons
+		`, map[string]any{"id": id, "score": score, "reasons": reasons})
+		if err != nil {
+			return nil, err
+		}
+		order, err := setOrderStatus(ctx, tx, id, status, paymentID, reason, now)
+		if err != nil {
+			return nil, err
+		}
+		if status == orderUnderReview {
+			if err := writeEvent(ctx, tx, events.New(events.OrderFlagged, "", order)); err != nil {
+				return nil, err
+			}
+		}
+		return order, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.Order), nil
+}
+
+// ApproveOrder places an order held for review, once its payment has been
+// captured.
+func (r *ProductRepository) ApproveOrder(ctx context.Context, id string) (*pb.Order, error) {
+	return r.closeReview(ctx, id, orderPlaced, "")
+}
+
+// RejectOrder returns the stock of an order held for review and marks it
+// declined with reason.
+func (r *ProductRepository) RejectOrder(ctx context.Context, id, reason string) (*pb.Order, error) {
+	return r.closeReview(ctx, id, orderDeclined, reason)
+}
+
+func (r *ProductRepository) closeReview(ctx context.Context, id, status, reason string) (*pb.Order, error) {
+	if id == "" {
+		return nil, fieldErrorf("order_id", "order id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		now := time.Now().UnixMilli()
+		current, err := lockOrder(ctx, tx, id, now)
+		if err != nil {
+			return nil, err
+		}
+		if current != orderUnderReview {
+			return nil, fmt.Errorf("%w: order %s is %s, not under review", ErrInvalidTransition, id, current)
+		}
+		order, err := readOrder(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if status == orderDeclined {
+			if _, err := restock(ctx, tx, id, reservationReleased, now); err != nil {
+				return nil, err
+			}
+			if err := releaseCoupons(ctx, tx, id, now); err != nil {
+				return nil, err
+			}
+		}
+
+		order, err = setOrderStatus(ctx, tx, id, status, order.PaymentId, reason, now)
+		if err != nil {
+			return nil, err
+		}
+		if status == orderPlaced {
+			if err := writeEvent(ctx, tx, events.New(events.OrderPlaced, "", order)); err != nil {
+				return nil, err
+			}
+		}
+		return order, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.Order), nil
+}
+
+// FlaggedOrders returns up to limit orders awaiting review, oldest first,
+// from one tenant or, with an empty tenantID, all of them.
+func (r *ProductRepository) FlaggedOrders(ctx context.Context, tenantID string, limit int) ([]*pb.Order, error) {
+	if limit <= 0 {
+		limit = defaultFlaggedLimit
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (o:Order {status: $status})
+			WHERE $tenant_id = '' OR o.tenant_id = $tenant_id
+			RETURN o.id AS id
+			ORDER BY o.created_at
+			LIMIT $limit
+		`, map[string]any{"status": orderUnderReview, "tenant_id": tenantID, "limit": limit})
+		if err != nil {
+			return nil, err
+		}
+		records, err := res.Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		orders := make([]*pb.Order, 0, len(records))
+		for _, record := range records {
+			order, err := readOrder(ctx, tx, getString(record.AsMap(), "id"))
+			if err != nil {
+				return nil, err
+			}
+			orders = append(orders, order)
+		}
+		return orders, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]*pb.Order), nil
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/notify"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/search"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// SaveSearch stores a user's search. Only products created from now on
+// will trigger alerts.
+func (r *ProductRepository) SaveSearch(ctx context.Context, ss *pb.SavedSearch) (string, error) {
+	if ss.GetUserId() == "" {
+		return "", fieldErrorf("saved_search.user_id", "user id is required")
+	}
+	if ss.GetName() == "" {
+		return "", fieldErrorf("saved_search.name", "saved search name is required")
+	}
+	if ss.Text != "" && len(search.Terms(ss.Text)) == 0 {
+		return "", fieldErrorf("saved_search.text", "search text has no searchable terms")
+	}
+	if ss.Text != "" && !r.dialect.Fulltext {
+		return "", fmt.Errorf("saved search text: %w", ErrUnsupported)
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			CREATE (ss:SavedSearch {
+				id: $id,
+				user_id: $user_id,
+				name: $name,
+				text: $text,
+				tenant_id: $tenant_id,
+				main_category: $main_category,
+				subcategory: $subcategory,
+				specific_type: $specific_type,
+				created_at: $now,
+				updated_at: $now,
+				last_evaluated_at: $now
+			})
+			RETURN ss.id AS id
+		`, map[string]any{
+			"id":            idOrNew(ss.Id),
+			"user_id":       ss.UserId,
+			"name":          ss.Name,
+			"text":          ss.Text,
+			"tenant_id":     ss.GetFilter().GetTenantId(),
+			"main_category": ss.GetFilter().GetMainCategory(),
+			"subcategory":   ss.GetFilter().GetSubcategory(),
+			"specific_type": ss.GetFilter().GetSpecificType(),
+			"now":           time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		id, _ := record.Get("id")
+		return id, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	id, _ := result.(string)
+	return id, nil
+}
+
+func (r *ProductRepository) ListSavedSearches(ctx context.Context, userID string) ([]*pb.SavedSearch, error) {
+	if userID == "" {
+		return nil, fieldErrorf("user_id", "user id is required")
+	}
+
+	return r.listSavedSearches(ctx, `
+		MATCH (ss:SavedSearch {user_id: $user_id})
+		RETURN ss
+		ORDER BY ss.created_at, ss.id
+	`, map[string]any{"user_id": userID})
+}
+
+// ListSavedSearchesAfter pages through every saved search by id, for the
+// alert evaluator.
+func (r *ProductRepository) ListSavedSearchesAfter(ctx context.Context, afterID string, limit int) ([]*pb.SavedSearch, error) {
+	return r.listSavedSearches(ctx, `
+		MATCH (ss:SavedSearch)
+		WHERE ss.id > $after_id
+		RETURN ss
+		ORDER BY ss.id
+		LIMIT $limit
+	`, map[string]any{
+		"after_id": afterID,
+		"limit":    limit,
+	})
+}
+
+func (r *ProductRepository) listSavedSearches(ctx context.Context, query string, params map[string]any) ([]*pb.SavedSearch, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+
+		searches := []*pb.SavedSearch{}
+		for res.Next(ctx) {
+			node, _ := res.Record().Values[0].(neo4j.Node)
+			searches = append(searches, savedSearchFromNode(node))
+		}
+		return searches, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]*pb.SavedSearch), nil
+}
+
+// DeleteSavedSearch removes the search if it belongs to userID.
+func (r *ProductRepository) DeleteSavedSearch(ctx context.Context, userID, id string) error {
+	if userID == "" || id == "" {
+		return fieldErrorf("id", "user id and saved search id are required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			MATCH (ss:SavedSearch {id: $id, user_id: $user_id})
+			DELETE ss
+		`, map[string]any{
+			"id":      id,
+			"user_id": userID,
+		})
+		return nil, err
+	})
+
+	return err
+}
+
+// MatchNewProducts returns up to limit products matching the saved search
+// that were created after it was last evaluated and no later than until,
+// oldest first.
+func (r *ProductRepository) MatchNewProducts(ctx context.Context, ss *pb.SavedSearch, until int64, limit int) ([]*pb.Product, error) {
+	params := map[string]any{
+		"since": ss.LastEvaluatedAt,
+		"until": until,
+		"limit": limit,
+	}
+
+	match := "MATCH (p:Product)"
+	if ss.Text != "" {
+		if !r.dialect.Fulltext {
+			return nil, fmt.Errorf("saved search text: %w", ErrUnsupported)
+		}
+		match = "CALL db.index.fulltext.queryNodes('productSearch', $search) YIELD node AS p"
+		params["search"] = search.Query(search.Terms(ss.Text))
+	}
+
+	query := match + `
+		WHERE p.created_at > $since
+			AND p.created_at <= $until
+			AND ` + catalogFilterClause(r.dialect, ss.Filter, params) + `
+		WITH p
+		ORDER BY p.created_at, p.id
+		LIMIT $limit
+		OPTIONAL MATCH (p)-[:BELONGS_TO]->(c:Category)
+		OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)
+		RETURN p, c, collect(s) as sizes
+		ORDER BY p.created_at, p.id
+	`
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+
+		products := []*pb.Product{}
+		for res.Next(ctx) {
+			products = append(products, productFromRecord(res.Record()))
+		}
+		return products, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]*pb.Product), nil
+}
+
+func (r *ProductRepository) MarkSavedSearchEvaluated(ctx context.Context, id string, at int64) error {
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			MATCH (ss:SavedSearch {id: $id})
+			SET ss.last_evaluated_at = $at
+		`, map[string]any{
+			"id": id,
+			"at": at,
+		})
+		return nil, err
+	})
+
+	return err
+}
+
+// EnqueueNotification writes the notification to the outbox so it reaches
+// webhooks subscribed to its kind like any other event.
+func (r *ProductRepository) EnqueueNotification(ctx context.Context, n notify.Notification) error {
+	if !events.IsKnownType(n.Kind) {
+		return errors.New("unknown notification kind " + n.Kind)
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return nil, writeEvent(ctx, tx, events.New(n.Kind, "", n))
+	})
+
+	return err
+}
+
+func savedSearchFromNode(node neo4j.Node) *pb.SavedSearch {
+	props := node.Props
+	return &pb.SavedSearch{
+		Id:     getString(props, "id"),
+		UserId: getString(props, "user_id"),
+		Name:   getString(props, "name"),
+		Text:   getString(props, "text"),
+		Filter: &pb.CatalogFilter{
+			TenantId:     getString(props, "tenant_id"),
+			MainCategory: getString(props, "main_category"),
+			Subcategory:  getString(props, "subcategory"),
+			SpecificType: getString(props, "specific_type"),
+		},
+		CreatedAt:       getInt64(props, "created_at"),
+		UpdatedAt:       getInt64(props, "updated_at"),
+		LastEvaluatedAt: getInt64(props, "last_evaluated_at"),
+	}
+}
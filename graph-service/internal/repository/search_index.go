@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// productSearchIndex is the fulltext index over products' default content.
+// Each language with its own analyzer gets an index over the translations
+// in it, named productSearch_<language>.
+const productSearchIndex = "productSearch"
+
+// translatedFields are the product fields translations carry.
+var translatedFields = []string{"name", "description"}
+
+var (
+	searchFieldPattern    = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	searchAnalyzerPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+	languagePattern       = regexp.MustCompile(`^[a-z]{2,3}$`)
+)
+
+// SearchIndexes configures the fulltext indexes text searches run against.
+// Migrate creates them and rebuilds any whose configuration changed.
+type SearchIndexes struct {
+	// Analyzer and Fields configure the index over the default content;
+	// Fields are Product properties.
+	Analyzer string
+	Fields   []string
+	// Weights boosts matches per field, e.g. name above description;
+	// fields without one weigh 1.
+	Weights map[string]float64
+	// Locales maps a language, e.g. "de", to the analyzer for its
+	// translations, regional variants such as de-AT included. Searches in
+	// other languages only see the default content.
+	Locales map[string]string
+}
+
+// DefaultSearchIndexes is Neo4j's default analyzer over a product's name,
+// description and brand, weighted in that order, with no per-language
+// indexes.
+var DefaultSearchIndexes = SearchIndexes{
+	Analyzer: "standard-no-stop-words",
+	Fields:   []string{"name", "description", "brand"},
+	Weights:  map[string]float64{"name": 3, "brand": 2, "description": 1},
+}
+
+// Validate checks the analyzer names, fields and languages are safe to
+// build index statements from. It does not check the server knows the
+// analyzers; creating the index does.
+func (s SearchIndexes) Validate() error {
+	if len(s.Fields) == 0 {
+		return fmt.Errorf("search indexes need at least one field")
+	}
+	for _, field := range s.Fields {
+		if !searchFieldPattern.MatchString(field) {
+			return fmt.Errorf("search field %q is not a property name", field)
+		}
+	}
+	for field, weight := range s.Weights {
+		if !slices.Contains(s.Fields, field) {
+			return fmt.Errorf("search weight for %q, which is not a search field", field)
+		}
+		if weight <= 0 {
+			return fmt.Errorf("search weight for %s must be positive", field)
+		}
+	}
+	if !searchAnalyzerPattern.MatchString(s.Analyzer) {
+		return fmt.Errorf("search analyzer %q is not an analyzer name", s.Analyzer)
+	}
+	for language, analyzer := range s.Locales {
+		if !languagePattern.MatchString(language) {
+			return fmt.Errorf("search language %q is not an ISO 639 code", language)
+		}
+		if !searchAnalyzerPattern.MatchString(analyzer) {
+			return fmt.Errorf("search analyzer %q for %s is not an analyzer name", analyzer, language)
+		}
+	}
+	return nil
+}
+
+// WithSearchIndexes replaces DefaultSearchIndexes. s must be valid.
+func WithSearchIndexes(s SearchIndexes) Option {
+	return func(r *ProductRepository) {
+		r.searchIndexes = s
+	}
+}
+
+// fulltextIndex is a fulltext index as configured or as found on the
+// server.
+type fulltextIndex struct {
+	name     string
+	label    string
+	fields   []string
+	analyzer string
+}
+
+func (i fulltextIndex) create() string {
+	properties := make([]string, 0, len(i.fields))
+	for _, field := range i.fields {
+		properties = append(properties, "n."+field)
+	}
+	return fmt.Sprintf("CREATE FULLTEXT INDEX %s IF NOT EXISTS FOR (n:%s) ON EACH [%s] OPTIONS {indexConfig: {`fulltext.analyzer`: '%s'}}",
+		i.name, i.label, strings.Join(properties, ", "), i.analyzer)
+}
+
+func (i fulltextIndex) matches(other fulltextIndex) bool {
+	return i.label == other.label && i.analyzer == other.analyzer && slices.Equal(i.fields, other.fields)
+}
+
+// translationLanguage returns the language of a normalized locale, or ""
+// if it doesn't start with an ISO 639 code.
+func translationLanguage(locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+	if !languagePattern.MatchString(language) {
+		return ""
+	}
+	return language
+}
+
+// translationLabel is the extra label Translation nodes in language carry,
+// which its fulltext index covers.
+func translationLabel(language string) string {
+	return "Translation_" + language
+}
+
+// localeSearchIndex returns the index for translations into locale, or ""
+// when its language has none.
+func (r *ProductRepository) localeSearchIndex(locale string) string {
+	language := translationLanguage(normalizeLocale(locale))
+	if _, ok := r.searchIndexes.Locales[language]; !ok || language == "" || len(r.translatedSearchFields()) == 0 {
+		return ""
+	}
+	return productSearchIndex + "_" + language
+}
+
+// LocalizedSearch reports whether text searches in locale also match the
+// product translations in it.
+func (r *ProductRepository) LocalizedSearch(locale string) bool {
+	return r.dialect.Fulltext && r.localeSearchIndex(locale) != ""
+}
+
+func (r *ProductRepository) translatedSearchFields() []string {
+	var fields []string
+	for _, field := range r.searchIndexes.Fields {
+		if slices.Contains(translatedFields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// wantedSearchIndexes returns the configured fulltext indexes by name.
+func (r *ProductRepository) wantedSearchIndexes() map[string]fulltextIndex {
+	wanted := map[string]fulltextIndex{
+		productSearchIndex: {
+			name:     productSearchIndex,
+			label:    "Product",
+			fields:   r.searchIndexes.Fields,
+			analyzer: r.searchIndexes.Analyzer,
+		},
+	}
+	fields := r.translatedSearchFields()
+	if len(fields) == 0 {
+		return wanted
+	}
+	for language, analyzer := range r.searchIndexes.Locales {
+		name := productSearchIndex + "_" + language
+		wanted[name] = fulltextIndex{
+			name:     name,
+			label:    translationLabel(language),
+			fields:   fields,
+			analyzer: analyzer,
+		}
+	}
+	return wanted
+}
+
+// searchIndexChanges compares the configured fulltext indexes with the
+// server's and returns the statements that bring it in line, each
+// described for the migration log.
+func (r *ProductRepository) searchIndexChanges(ctx context.Context) ([]string, []string, error) {
+	if !r.dialect.Fulltext {
+		return nil, nil, nil
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			SHOW FULLTEXT INDEXES YIELD name, labelsOrTypes, properties, options
+			WHERE name = $name OR name STARTS WITH $name + '_'
+			RETURN name, labelsOrTypes, properties, options.indexConfig['fulltext.analyzer'] AS analyzer
+		`, map[string]any{"name": productSearchIndex})
+		if err != nil {
+			return nil, err
+		}
+
+		existing := map[string]fulltextIndex{}
+		for res.Next(ctx) {
+			row := res.Record().AsMap()
+			index := fulltextIndex{name: getString(row, "name"), analyzer: getString(row, "analyzer")}
+			if labels := getStrings(row, "labelsOrTypes"); len(labels) == 1 {
+				index.label = labels[0]
+			}
+			index.fields = getStrings(row, "properties")
+			existing[index.name] = index
+		}
+		return existing, res.Err()
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	existing := result.(map[string]fulltextIndex)
+
+	var statements, changes []string
+	wanted := r.wantedSearchIndexes()
+	names := make([]string, 0, len(wanted)+len(existing))
+	for name := range wanted {
+		names = append(names, name)
+	}
+	for name := range existing {
+		if _, ok := wanted[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		want, configured := wanted[name]
+		have, found := existing[name]
+		switch {
+		case !configured:
+			statements = append(statements, "DROP INDEX "+name+" IF EXISTS")
+			changes = append(changes, "search index "+name+": drop")
+		case !found:
+			statements = append(statements, want.create())
+			changes = append(changes, "search index "+name+": create")
+		case !have.matches(want):
+			statements = append(statements, "DROP INDEX "+name+" IF EXISTS", want.create())
+			changes = append(changes, "search index "+name+": rebuild")
+		}
+	}
+	return statements, changes, nil
+}
+
+// syncSearchIndexes applies searchIndexChanges and returns their
+// descriptions. Neo4j populates new indexes in the background; searches
+// see partial results until it finishes.
+func (r *ProductRepository) syncSearchIndexes(ctx context.Context) ([]string, error) {
+	statements, changes, err := r.searchIndexChanges(ctx)
+	if err != nil || len(statements) == 0 {
+		return nil, err
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	for _, statement := range statements {
+		_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			_, err := tx.Run(ctx, statement, nil)
+			return nil, err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("search indexes: %w", err)
+		}
+	}
+	log.Printf("search indexes: %s", strings.Join(changes, "; "))
+	return changes, nil
+}
+
+// backfillTranslationLabels labels existing translations with their
+// language, one language per transaction.
+func (r *ProductRepository) backfillTranslationLabels(ctx context.Context) error {
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (t:Translation)
+			RETURN DISTINCT t.locale AS locale
+		`, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		languages := map[string]bool{}
+		for res.Next(ctx) {
+			if language := translationLanguage(getString(res.Record().AsMap(), "locale")); language != "" {
+				languages[language] = true
+			}
+		}
+		return languages, res.Err()
+	})
+	if err != nil {
+		return err
+	}
+
+	for language := range result.(map[string]bool) {
+		_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			_, err := tx.Run(ctx, `
+				MATCH (t:Translation)
+				WHERE t.locale = $language OR t.locale STARTS WITH $language + '-'
+				SET t:`+translationLabel(language), map[string]any{"language": language})
+			return nil, err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/search"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Fuzzy matches sampled when building a did-you-mean suggestion.
+const suggestionSampleSize = 10
+
+// FulltextSearch runs a query against the productSearch fulltext index
+// (see SearchIndexes), best match first. When locale's language has its
+// own index, the query also runs against the product translations in the
+// locale, and a product scores its better match. Matches are weighted by
+// field as SearchIndexes.Weights says.
+func (r *ProductRepository) FulltextSearch(ctx context.Context, query, locale string, filter *pb.CatalogFilter, limit int) ([]*pb.Product, error) {
+	if !r.dialect.Fulltext {
+		return nil, fmt.Errorf("fulltext search: %w", ErrUnsupported)
+	}
+	params := map[string]any{
+		"search": search.Boost(query, r.searchIndexes.Fields, r.searchIndexes.Weights),
+		"limit":  limit,
+	}
+
+	match := "CALL db.index.fulltext.queryNodes('productSearch', $search) YIELD node AS p, score"
+	if index := r.localeSearchIndex(locale); index != "" {
+		params["index"] = index
+		params["chain"] = localeChain(locale)
+		match = `
+			CALL {
+				CALL db.index.fulltext.queryNodes('productSearch', $search) YIELD node AS p, score
+				RETURN p, score
+				UNION ALL
+				CALL db.index.fulltext.queryNodes($index, $search) YIELD node AS t, score
+				WHERE t.locale IN $chain
+				MATCH (p:Product)-[:HAS_TRANSLATION]->(t)
+				RETURN p, score
+			}
+			WITH p, max(score) AS score`
+	}
+
+	cypher := match + `
+		WHERE ` + catalogFilterClause(r.dialect, filter, params) + `
+		WITH p, score
+		ORDER BY score DESC, p.id
+		LIMIT $limit` + productReturn(ctx, ", score") + `
+		ORDER BY score DESC, p.id
+	`
+
+	return cachedRead(ctx, r, cypher, params, func() ([]*pb.Product, error) {
+		session := r.newSession(ctx, neo4j.AccessModeRead)
+		defer r.closeSession(ctx, session)
+
+		result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			res, err := tx.Run(ctx, cypher, params)
+			if err != nil {
+				return nil, err
+			}
+
+			products := []*pb.Product{}
+			for res.Next(ctx) {
+				products = append(products, productFromRecord(res.Record()))
+			}
+			return products, res.Err()
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return result.([]*pb.Product), nil
+	})
+}
+
+// SuggestQuery retries terms with fuzzy matching and returns a corrected
+// query built from the words the fuzzy matches contain, or "" if the retry
+// finds nothing worth suggesting. Products are sampled in locale, so the
+// words come from their translations where the search matches those.
+func (r *ProductRepository) SuggestQuery(ctx context.Context, terms []string, locale string, filter *pb.CatalogFilter) (string, error) {
+	products, err := r.FulltextSearch(ctx, search.FuzzyQuery(terms), locale, filter, suggestionSampleSize)
+	if err != nil {
+		return "", err
+	}
+	if r.LocalizedSearch(locale) {
+		err = r.LocalizeProducts(ctx, products, locale)
+	}
+	if err != nil {
+		return "", err
+	}
+	return search.Suggest(terms, products), nil
+}
@@ -0,0 +1,562 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// In marketplace mode sellers offer SKUs alongside the catalog's own
+// stock, (:Seller)-[:OFFERS {price, stock}]->(:Size), each at its own
+// price and with its own stock. Changes to offers and sellers that move a
+// SKU's buy box raise won and lost events for the sellers concerned.
+
+var errSellerNotFound = notFoundf("seller not found")
+
+// Seller is a marketplace seller. Suspended sellers' offers are kept but
+// never win the buy box.
+type Seller struct {
+	ID        string
+	TenantID  string
+	Name      string
+	Suspended bool
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+// Offer is a seller's price and stock for a SKU.
+type Offer struct {
+	SellerID   string
+	SellerName string
+	SKU        string
+	ProductID  string
+	Price      float64
+	Stock      int32
+	UpdatedAt  int64
+}
+
+// CreateSeller stores a new seller, with a new id unless it has one.
+func (r *ProductRepository) CreateSeller(ctx context.Context, s Seller) (*Seller, error) {
+	if s.Name == "" {
+		return nil, fieldErrorf("seller.name", "seller name is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	s.ID = idOrNew(s.ID)
+	s.CreatedAt = time.Now().UnixMilli()
+	s.UpdatedAt = s.CreatedAt
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			CREATE (:Seller {
+				id: $id,
+				tenant_id: $tenant_id,
+				name: $name,
+				suspended: $suspended,
+				created_at: $now,
+				updated_at: $now
+			})
+		`, map[string]any{
+			"id":        s.ID,
+			"tenant_id": s.TenantID,
+			"name":      s.Name,
+			"suspended": s.Suspended,
+			"now":       s.CreatedAt,
+		})
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (r *ProductRepository) GetSeller(ctx context.Context, id string) (*Seller, error) {
+	if id == "" {
+		return nil, fieldErrorf("id", "seller id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (sel:Seller {id: $id})
+			RETURN sel
+		`, map[string]any{"id": id})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, errSellerNotFound
+		}
+		node, _ := res.Record().Values[0].(neo4j.Node)
+		seller := sellerFromNode(node)
+		return &seller, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*Seller), nil
+}
+
+// ListSellers returns up to limit sellers of tenantID, or of every tenant
+// when it's empty, in id order after afterID.
+func (r *ProductRepository) ListSellers(ctx context.Context, tenantID, afterID string, limit int) ([]Seller, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (sel:Seller)
+			WHERE ($tenant_id = '' OR sel.tenant_id = $tenant_id) AND sel.id > $after
+			RETURN sel
+			ORDER BY sel.id
+			LIMIT $limit
+		`, map[string]any{
+			"tenant_id": tenantID,
+			"after":     afterID,
+			"limit":     limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sellers := []Seller{}
+		for res.Next(ctx) {
+			node, _ := res.Record().Values[0].(neo4j.Node)
+			sellers = append(sellers, sellerFromNode(node))
+		}
+		return sellers, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]Seller), nil
+}
+
+// UpdateSeller sets the seller's name and suspension. Its tenant can't
+// change.
+func (r *ProductRepository) UpdateSeller(ctx context.Context, s Seller) (*Seller, error) {
+	if s.ID == "" {
+		return nil, fieldErrorf("seller.id", "seller id is required")
+	}
+	if s.Name == "" {
+		return nil, fieldErrorf("seller.name", "seller name is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		skus, err := offeredSKUs(ctx, tx, s.ID)
+		if err != nil {
+			return nil, err
+		}
+		before, err := buyBoxWinners(ctx, tx, skus)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := tx.Run(ctx, `
+			MATCH (sel:Seller {id: $id})
+			SET sel.name = $name,
+				sel.suspended = $suspended,
+				sel.updated_at = $now
+			RETURN sel
+		`, map[string]any{
+			"id":        s.ID,
+			"name":      s.Name,
+			"suspended": s.Suspended,
+			"now":       time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, errSellerNotFound
+		}
+		node, _ := res.Record().Values[0].(neo4j.Node)
+		seller := sellerFromNode(node)
+
+		after, err := buyBoxWinners(ctx, tx, skus)
+		if err != nil {
+			return nil, err
+		}
+		if err := raiseBuyBoxChanges(ctx, tx, skus, before, after); err != nil {
+			return nil, err
+		}
+		return &seller, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*Seller), nil
+}
+
+// DeleteSeller deletes the seller and its offers, and deactivates its
+// webhooks.
+func (r *ProductRepository) DeleteSeller(ctx context.Context, id string) error {
+	if id == "" {
+		return fieldErrorf("id", "seller id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		skus, err := offeredSKUs(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		before, err := buyBoxWinners(ctx, tx, skus)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.Run(ctx, `
+			MATCH (sel:Seller {id: $id})
+			DETACH DELETE sel
+			WITH count(*) AS deleted
+			MATCH (w:Webhook {seller_id: $id})
+			SET w.active = false,
+				w.updated_at = $now
+		`, map[string]any{"id": id, "now": time.Now().UnixMilli()})
+		if err != nil {
+			return nil, err
+		}
+
+		after, err := buyBoxWinners(ctx, tx, skus)
+		if err != nil {
+			return nil, err
+		}
+		return nil, raiseBuyBoxChanges(ctx, tx, skus, before, after)
+	})
+
+	return err
+}
+
+// SetOffer creates or replaces the seller's offer for a SKU. A seller of
+// a tenant can only offer that tenant's products. An offer that runs out
+// of stock raises an out of stock event for the seller.
+func (r *ProductRepository) SetOffer(ctx context.Context, o Offer) (*Offer, error) {
+	if o.SellerID == "" {
+		return nil, fieldErrorf("offer.seller_id", "seller id is required")
+	}
+	if o.SKU == "" {
+		return nil, fieldErrorf("offer.sku", "sku is required")
+	}
+	if o.Price <= 0 {
+		return nil, fieldErrorf("offer.price", "price must be positive")
+	}
+	if o.Stock < 0 {
+		return nil, fieldErrorf("offer.stock", "stock cannot be negative")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	o.UpdatedAt = time.Now().UnixMilli()
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		skus := []string{o.SKU}
+		before, err := buyBoxWinners(ctx, tx, skus)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := tx.Run(ctx, `
+			OPTIONAL MATCH (sel:Seller {id: $seller_id})
+			OPTIONAL MATCH (p:Product)-[:HAS_SIZE]->(s:Size {sku: $sku})
+			OPTIONAL MATCH (sel)-[previous:OFFERS]->(s)
+			WITH sel, p, s, coalesce(previous.stock, 0) AS previous_stock,
+				sel IS NOT NULL AND s IS NOT NULL
+					AND (sel.tenant_id = '' OR sel.tenant_id = coalesce(p.tenant_id, '')) AS allowed
+			FOREACH (_ IN CASE WHEN allowed THEN [1] ELSE [] END |
+				MERGE (sel)-[o:OFFERS]->(s)
+				ON CREATE SET o.created_at = $now
+				SET o.price = $price,
+					o.stock = $stock,
+					o.updated_at = $now
+			)
+			RETURN sel IS NOT NULL AS seller, s IS NOT NULL AS sku, allowed,
+				sel.name AS seller_name, p.id AS product_id, previous_stock
+		`, map[string]any{
+			"seller_id": o.SellerID,
+			"sku":       o.SKU,
+			"price":     o.Price,
+			"stock":     o.Stock,
+			"now":       o.UpdatedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		row := record.AsMap()
+		if seller, _ := row["seller"].(bool); !seller {
+			return nil, errSellerNotFound
+		}
+		if sku, _ := row["sku"].(bool); !sku {
+			return nil, notFoundf("sku %s not found", o.SKU)
+		}
+		if allowed, _ := row["allowed"].(bool); !allowed {
+			return nil, fieldErrorf("offer.sku", "sku %s belongs to another tenant's product", o.SKU)
+		}
+		o.SellerName = getString(row, "seller_name")
+		o.ProductID = getString(row, "product_id")
+
+		if o.Stock == 0 && getInt64(row, "previous_stock") > 0 {
+			ev := events.New(events.OfferOutOfStock, o.ProductID, map[string]any{"price": o.Price})
+			ev.SKU, ev.SellerID = o.SKU, o.SellerID
+			if err := writeEvent(ctx, tx, ev); err != nil {
+				return nil, err
+			}
+		}
+
+		after, err := buyBoxWinners(ctx, tx, skus)
+		if err != nil {
+			return nil, err
+		}
+		return nil, raiseBuyBoxChanges(ctx, tx, skus, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+// DeleteOffer withdraws the seller's offer for sku.
+func (r *ProductRepository) DeleteOffer(ctx context.Context, sellerID, sku string) error {
+	if sellerID == "" {
+		return fieldErrorf("seller_id", "seller id is required")
+	}
+	if sku == "" {
+		return fieldErrorf("sku", "sku is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		skus := []string{sku}
+		before, err := buyBoxWinners(ctx, tx, skus)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := tx.Run(ctx, `
+			MATCH (:Seller {id: $seller_id})-[o:OFFERS]->(:Size {sku: $sku})
+			DELETE o
+			RETURN count(*) AS deleted
+		`, map[string]any{
+			"seller_id": sellerID,
+			"sku":       sku,
+		})
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if deleted, _ := record.Values[0].(int64); deleted == 0 {
+			return nil, notFoundf("offer not found")
+		}
+
+		after, err := buyBoxWinners(ctx, tx, skus)
+		if err != nil {
+			return nil, err
+		}
+		return nil, raiseBuyBoxChanges(ctx, tx, skus, before, after)
+	})
+
+	return err
+}
+
+// ListSellerOffers returns up to limit of the seller's offers, in SKU
+// order after afterSKU.
+func (r *ProductRepository) ListSellerOffers(ctx context.Context, sellerID, afterSKU string, limit int) ([]Offer, error) {
+	if sellerID == "" {
+		return nil, fieldErrorf("seller_id", "seller id is required")
+	}
+	return r.listOffers(ctx, `
+		MATCH (sel:Seller {id: $seller_id})-[o:OFFERS]->(s:Size)<-[:HAS_SIZE]-(p:Product)
+		WHERE s.sku > $after
+		WITH sel, o, s, p
+		ORDER BY s.sku
+		LIMIT $limit
+	`, map[string]any{
+		"seller_id": sellerID,
+		"after":     afterSKU,
+		"limit":     limit,
+	})
+}
+
+// BuyBox returns the winning offer for each of the product's SKUs that
+// has one: the lowest priced offer in stock from a seller that isn't
+// suspended, then the one with the most stock, in SKU order.
+func (r *ProductRepository) BuyBox(ctx context.Context, productID string) ([]Offer, error) {
+	return r.listOffers(ctx, `
+		MATCH (p:Product {id: $product_id})-[:HAS_SIZE]->(s:Size)<-[o:OFFERS]-(sel:Seller)
+		WHERE o.stock > 0 AND NOT coalesce(sel.suspended, false)
+		WITH p, s, o, sel
+		ORDER BY o.price, o.stock DESC, sel.id
+		WITH p, s, collect([o, sel])[0] AS best
+		WITH p, s, best[0] AS o, best[1] AS sel
+		ORDER BY s.sku
+	`, map[string]any{"product_id": productID})
+}
+
+// buyBoxWinner is the offer winning a SKU's buy box.
+type buyBoxWinner struct {
+	SellerID  string
+	ProductID string
+	Price     float64
+}
+
+// buyBoxWinners returns the winner of each of skus' buy box, picked as
+// BuyBox picks it, leaving out SKUs no offer wins.
+func buyBoxWinners(ctx context.Context, tx neo4j.ManagedTransaction, skus []string) (map[string]buyBoxWinner, error) {
+	winners := map[string]buyBoxWinner{}
+	if len(skus) == 0 {
+		return winners, nil
+	}
+
+	res, err := tx.Run(ctx, `
+		UNWIND $skus AS sku
+		MATCH (p:Product)-[:HAS_SIZE]->(s:Size {sku: sku})<-[o:OFFERS]-(sel:Seller)
+		WHERE o.stock > 0 AND NOT coalesce(sel.suspended, false)
+		WITH p, s, o, sel
+		ORDER BY o.price, o.stock DESC, sel.id
+		WITH p, s, collect([sel.id, o.price])[0] AS best
+		RETURN s.sku AS sku, p.id AS product_id, best[0] AS seller_id, best[1] AS price
+	`, map[string]any{"skus": skus})
+	if err != nil {
+		return nil, err
+	}
+	for res.Next(ctx) {
+		row := res.Record().AsMap()
+		price, _ := row["price"].(float64)
+		winners[getString(row, "sku")] = buyBoxWinner{
+			SellerID:  getString(row, "seller_id"),
+			ProductID: getString(row, "product_id"),
+			Price:     price,
+		}
+	}
+	return winners, res.Err()
+}
+
+// raiseBuyBoxChanges raises a lost event for the previous winner and a won
+// event for the new one of each of skus whose buy box changed hands
+// between before and after.
+func raiseBuyBoxChanges(ctx context.Context, tx neo4j.ManagedTransaction, skus []string, before, after map[string]buyBoxWinner) error {
+	for _, sku := range skus {
+		was, now := before[sku], after[sku]
+		if was.SellerID == now.SellerID {
+			continue
+		}
+		if was.SellerID != "" {
+			ev := events.New(events.OfferBuyBoxLost, was.ProductID, map[string]any{
+				"price":         was.Price,
+				"winning_price": now.Price,
+			})
+			ev.SKU, ev.SellerID = sku, was.SellerID
+			if err := writeEvent(ctx, tx, ev); err != nil {
+				return err
+			}
+		}
+		if now.SellerID != "" {
+			ev := events.New(events.OfferBuyBoxWon, now.ProductID, map[string]any{"price": now.Price})
+			ev.SKU, ev.SellerID = sku, now.SellerID
+			if err := writeEvent(ctx, tx, ev); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// offeredSKUs returns the SKUs the seller offers.
+func offeredSKUs(ctx context.Context, tx neo4j.ManagedTransaction, sellerID string) ([]string, error) {
+	res, err := tx.Run(ctx, `
+		MATCH (:Seller {id: $seller_id})-[:OFFERS]->(s:Size)
+		RETURN s.sku AS sku
+		ORDER BY sku
+	`, map[string]any{"seller_id": sellerID})
+	if err != nil {
+		return nil, err
+	}
+	var skus []string
+	for res.Next(ctx) {
+		sku, _ := res.Record().Values[0].(string)
+		skus = append(skus, sku)
+	}
+	return skus, res.Err()
+}
+
+// listOffers completes match, which leaves rows of offers o by sellers
+// sel for sizes s of products p, in order.
+func (r *ProductRepository) listOffers(ctx context.Context, match string, params map[string]any) ([]Offer, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, match+`
+			RETURN sel.id AS seller_id, sel.name AS seller_name, s.sku AS sku, p.id AS product_id,
+				o.price AS price, o.stock AS stock, o.updated_at AS updated_at
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+
+		offers := []Offer{}
+		for res.Next(ctx) {
+			row := res.Record().AsMap()
+			price, _ := row["price"].(float64)
+			offers = append(offers, Offer{
+				SellerID:   getString(row, "seller_id"),
+				SellerName: getString(row, "seller_name"),
+				SKU:        getString(row, "sku"),
+				ProductID:  getString(row, "product_id"),
+				Price:      price,
+				Stock:      int32(getInt64(row, "stock")),
+				UpdatedAt:  getInt64(row, "updated_at"),
+			})
+		}
+		return offers, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]Offer), nil
+}
+
+func sellerFromNode(node neo4j.Node) Seller {
+	props := node.Props
+	suspended, _ := props["suspended"].(bool)
+	return Seller{
+		ID:        getString(props, "id"),
+		TenantID:  getString(props, "tenant_id"),
+		Name:      getString(props, "name"),
+		Suspended: suspended,
+		CreatedAt: getInt64(props, "created_at"),
+		UpdatedAt: getInt64(props, "updated_at"),
+	}
+}
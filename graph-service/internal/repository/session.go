@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/breaker"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/redact"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/tracing"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type bookmarksKey struct{}
+
+// bookmarkCarrier threads causal-consistency bookmarks through a request:
+// sessions start from the bookmarks the client sent and report back the
+// bookmarks of the transactions they ran.
+type bookmarkCarrier struct {
+	in neo4j.Bookmarks
+
+	mu  sync.Mutex
+	out neo4j.Bookmarks
+}
+
+// WithBookmarks returns a context whose sessions wait until the database has
+// caught up with the given bookmarks, and which collects the bookmarks
+// produced while serving the request.
+func WithBookmarks(ctx context.Context, bookmarks neo4j.Bookmarks) context.Context {
+	return context.WithValue(ctx, bookmarksKey{}, &bookmarkCarrier{in: bookmarks})
+}
+
+// LastBookmarks returns the bookmarks produced by sessions run under ctx.
+func LastBookmarks(ctx context.Context) neo4j.Bookmarks {
+	carrier, ok := ctx.Value(bookmarksKey{}).(*bookmarkCarrier)
+	if !ok {
+		return nil
+	}
+	carrier.mu.Lock()
+	defer carrier.mu.Unlock()
+	return carrier.out
+}
+
+type queryTimerKey struct{}
+
+// WithQueryTimer returns a context whose sessions add up the time their
+// transactions take, for QueryTime.
+func WithQueryTimer(ctx context.Context) context.Context {
+	return context.WithValue(ctx, queryTimerKey{}, new(atomic.Int64))
+}
+
+// QueryTime returns the time transactions run under ctx took, retries
+// included.
+func QueryTime(ctx context.Context) time.Duration {
+	total, ok := ctx.Value(queryTimerKey{}).(*atomic.Int64)
+	if !ok {
+		return 0
+	}
+	return time.Duration(total.Load())
+}
+
+// newSession opens a session for one repository call, or joins the unit
+// of work ctx carries, if any (see InTransaction).
+func (r *ProductRepository) newSession(ctx context.Context, mode neo4j.AccessMode) neo4j.SessionWithContext {
+	if u, ok := ctx.Value(unitKey{}).(*unit); ok {
+		return unitSession{SessionWithContext: u.session, tx: u.tx}
+	}
+	config := neo4j.SessionConfig{AccessMode: mode, DatabaseName: r.database}
+	if carrier, ok := ctx.Value(bookmarksKey{}).(*bookmarkCarrier); ok && r.dialect.Bookmarks {
+		carrier.mu.Lock()
+		config.Bookmarks = neo4j.CombineBookmarks(carrier.in, carrier.out)
+		carrier.mu.Unlock()
+	}
+	var session neo4j.SessionWithContext = tracedSession{r.driver.NewSession(ctx, config)}
+	if total, ok := ctx.Value(queryTimerKey{}).(*atomic.Int64); ok {
+		session = timedSession{SessionWithContext: session, total: total}
+	}
+	if r.breaker != nil {
+		session = guardedSession{SessionWithContext: session, breaker: r.breaker}
+	}
+	if r.queryCache != nil && mode == neo4j.AccessModeWrite {
+		session = invalidatingSession{SessionWithContext: session, cache: r.queryCache}
+	}
+	return deadlineSession{session}
+}
+
+func (r *ProductRepository) closeSession(ctx context.Context, session neo4j.SessionWithContext) {
+	if _, ok := session.(unitSession); ok {
+		return
+	}
+	if carrier, ok := ctx.Value(bookmarksKey{}).(*bookmarkCarrier); ok && r.dialect.Bookmarks {
+		if last := session.LastBookmarks(); len(last) > 0 {
+			carrier.mu.Lock()
+			carrier.out = last
+			carrier.mu.Unlock()
+		}
+	}
+	session.Close(ctx)
+}
+
+// deadlineSession refuses to start transactions once the request's context
+// is done, and gives the rest a server-side timeout of whatever time the
+// request has left, so Neo4j abandons work as soon as the client gives up
+// instead of finishing it for nobody.
+type deadlineSession struct {
+	neo4j.SessionWithContext
+}
+
+func (s deadlineSession) ExecuteRead(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	configurers, err := withDeadline(ctx, configurers)
+	if err != nil {
+		return nil, err
+	}
+	return s.SessionWithContext.ExecuteRead(ctx, work, configurers...)
+}
+
+func (s deadlineSession) ExecuteWrite(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	configurers, err := withDeadline(ctx, configurers)
+	if err != nil {
+		return nil, err
+	}
+	return s.SessionWithContext.ExecuteWrite(ctx, work, configurers...)
+}
+
+// withDeadline adds a transaction timeout matching ctx's deadline, if it
+// has one, to configurers. It fails with ctx's error once ctx is done.
+func withDeadline(ctx context.Context, configurers []func(*neo4j.TransactionConfig)) ([]func(*neo4j.TransactionConfig), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return configurers, nil
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return nil, context.DeadlineExceeded
+	}
+	return append(slices.Clip(configurers), neo4j.WithTxTimeout(remaining)), nil
+}
+
+// timedSession adds the time its transactions take to a WithQueryTimer
+// total.
+type timedSession struct {
+	neo4j.SessionWithContext
+	total *atomic.Int64
+}
+
+func (s timedSession) ExecuteRead(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	defer s.time(time.Now())
+	return s.SessionWithContext.ExecuteRead(ctx, work, configurers...)
+}
+
+func (s timedSession) ExecuteWrite(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	defer s.time(time.Now())
+	return s.SessionWithContext.ExecuteWrite(ctx, work, configurers...)
+}
+
+func (s timedSession) time(start time.Time) {
+	s.total.Add(int64(time.Since(start)))
+}
+
+// guardedSession runs transactions through the repository's circuit
+// breaker. Only failures to reach the database count against it; query
+// and validation errors mean the database is up, and a request that ran
+// out of time says nothing about it.
+type guardedSession struct {
+	neo4j.SessionWithContext
+	breaker *breaker.Breaker
+}
+
+func (s guardedSession) ExecuteRead(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	if err := s.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	result, err := s.SessionWithContext.ExecuteRead(ctx, work, configurers...)
+	s.breaker.Record(isUnavailable(err) && ctx.Err() == nil)
+	return result, err
+}
+
+func (s guardedSession) ExecuteWrite(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	if err := s.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	result, err := s.SessionWithContext.ExecuteWrite(ctx, work, configurers...)
+	s.breaker.Record(isUnavailable(err) && ctx.Err() == nil)
+	return result, err
+}
+
+// isUnavailable reports whether err means Neo4j could not be reached,
+// either directly or after the driver exhausted its retries.
+func isUnavailable(err error) bool {
+	var connErr *neo4j.ConnectivityError
+	var limitErr *neo4j.TransactionExecutionLimit
+	return errors.As(err, &connErr) || errors.As(err, &limitErr)
+}
+
+// tracedSession records a span per transaction, and a child span per query
+// run in it. Queries are identified by hash, not text, since LLM-generated
+// Cypher can embed what the customer said.
+type tracedSession struct {
+	neo4j.SessionWithContext
+}
+
+func (s tracedSession) ExecuteRead(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	ctx, span := startSpan(ctx, "neo4j.ExecuteRead")
+	result, err := s.SessionWithContext.ExecuteRead(ctx, traceWork(ctx, work), configurers...)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s tracedSession) ExecuteWrite(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	ctx, span := startSpan(ctx, "neo4j.ExecuteWrite")
+	result, err := s.SessionWithContext.ExecuteWrite(ctx, traceWork(ctx, work), configurers...)
+	endSpan(span, err)
+	return result, err
+}
+
+func traceWork(ctx context.Context, work neo4j.ManagedTransactionWork) neo4j.ManagedTransactionWork {
+	return func(tx neo4j.ManagedTransaction) (any, error) {
+		return work(tracedTx{ManagedTransaction: tx, parent: trace.SpanFromContext(ctx)})
+	}
+}
+
+// tracedTx parents query spans on the transaction span; the work function
+// runs queries with the caller's context, which doesn't carry it.
+type tracedTx struct {
+	neo4j.ManagedTransaction
+	parent trace.Span
+}
+
+func (tx tracedTx) Run(ctx context.Context, cypher string, params map[string]any) (neo4j.ResultWithContext, error) {
+	_, span := startSpan(trace.ContextWithSpan(ctx, tx.parent), "neo4j.Run", tracing.QueryHash.String(tracing.HashText(cypher)))
+	result, err := tx.ManagedTransaction.Run(ctx, cypher, params)
+	endSpan(span, err)
+	return result, err
+}
+
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("db.system.name", "neo4j"))
+	return tracing.Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endSpan ends span, recording err without the personal data database
+// errors can quote, such as the email a constraint rejected.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		msg := redact.Error(err)
+		span.RecordError(errors.New(msg))
+		span.SetStatus(codes.Error, msg)
+	}
+	span.End()
+}
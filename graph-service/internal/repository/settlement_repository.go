@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/commission"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// A placed order's lines sold by marketplace sellers,
+// (:OrderLine)-[:SOLD_BY]->(:Seller), are settled with an entry each,
+// (:OrderLine)-[:SETTLED_IN]->(:SettlementEntry)-[:PAYS]->(:Seller),
+// splitting the line into the marketplace's commission and the seller's
+// payout.
+
+// SettlementEntry is what an order line sold by a seller earns it.
+type SettlementEntry struct {
+	ID         string
+	OrderID    string
+	SKU        string
+	SellerID   string
+	TenantID   string
+	Category   string
+	Currency   string
+	Quantity   int32
+	Gross      float64
+	Rate       float64
+	Commission float64
+	Payout     float64
+	CreatedAt  int64
+}
+
+// SettlementTotal sums a seller's entries in one currency.
+type SettlementTotal struct {
+	Currency   string
+	Lines      int
+	Gross      float64
+	Commission float64
+	Payout     float64
+}
+
+// SettlementReport is a seller's settlement over a period: totals by
+// currency, and a page of its entries.
+type SettlementReport struct {
+	Totals  []SettlementTotal
+	Entries []SettlementEntry
+}
+
+// RecordSettlement records an entry for each line of the placed order
+// sold by a seller, at the rate card's rate for the product's main
+// category. Lines already settled are left alone, so settling an order
+// twice records it once.
+func (r *ProductRepository) RecordSettlement(ctx context.Context, orderID string, card *commission.RateCard) ([]SettlementEntry, error) {
+	if orderID == "" {
+		return nil, fieldErrorf("order_id", "order id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (o:Order {id: $id})-[:HAS_LINE]->(l:OrderLine)-[:SOLD_BY]->(sel:Seller)
+			WHERE o.status = $placed AND NOT (l)-[:SETTLED_IN]->(:SettlementEntry)
+			OPTIONAL MATCH (:Product {id: l.product_id})-[:BELONGS_TO]->(c:Category)
+			WITH o, l, sel, head(collect(c.main_category)) AS category
+			RETURN o.tenant_id AS tenant_id, o.currency AS currency, l.sku AS sku,
+				l.quantity AS quantity, l.total AS total, sel.id AS seller_id, category
+		`, map[string]any{"id": orderID, "placed": orderPlaced})
+		if err != nil {
+			return nil, err
+		}
+		records, err := res.Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now().UnixMilli()
+		entries := make([]SettlementEntry, 0, len(records))
+		params := make([]map[string]any, 0, len(records))
+		for _, record := range records {
+			row := record.AsMap()
+			total, _ := row["total"].(float64)
+			category := getString(row, "category")
+			rate, fee, payout := card.Split(category, cents(total))
+			e := SettlementEntry{
+				ID:         orderID + ":" + getString(row, "sku"),
+				OrderID:    orderID,
+				SKU:        getString(row, "sku"),
+				SellerID:   getString(row, "seller_id"),
+				TenantID:   getString(row, "tenant_id"),
+				Category:   category,
+				Currency:   getString(row, "currency"),
+				Quantity:   int32(getInt64(row, "quantity")),
+				Gross:      float64(cents(total)) / 100,
+				Rate:       rate,
+				Commission: float64(fee) / 100,
+				Payout:     float64(payout) / 100,
+				CreatedAt:  now,
+			}
+			entries = append(entries, e)
+			params = append(params, map[string]any{
+				"id":         e.ID,
+				"sku":        e.SKU,
+				"seller_id":  e.SellerID,
+				"tenant_id":  e.TenantID,
+				"category":   e.Category,
+				"currency":   e.Currency,
+				"quantity":   e.Quantity,
+				"gross":      e.Gross,
+				"rate":       e.Rate,
+				"commission": e.Commission,
+				"payout":     e.Payout,
+			})
+		}
+		if len(entries) == 0 {
+			return entries, nil
+		}
+
+		_, err = tx.Run(ctx, `
+			MATCH (o:Order {id: $order_id})
+			UNWIND $entries AS entry
+			MATCH (o)-[:HAS_LINE]->(l:OrderLine {sku: entry.sku})-[:SOLD_BY]->(sel:Seller {id: entry.seller_id})
+			CREATE (l)-[:SETTLED_IN]->(e:SettlementEntry {
+				id: entry.id,
+				order_id: $order_id,
+				sku: entry.sku,
+				seller_id: entry.seller_id,
+				tenant_id: entry.tenant_id,
+				category: entry.category,
+				currency: entry.currency,
+				quantity: entry.quantity,
+				gross: entry.gross,
+				rate: entry.rate,
+				commission: entry.commission,
+				payout: entry.payout,
+				created_at: $now
+			})-[:PAYS]->(sel)
+		`, map[string]any{
+			"order_id": orderID,
+			"entries":  params,
+			"now":      now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return entries, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]SettlementEntry), nil
+}
+
+// SellerSettlementReport returns the seller's settlement for entries
+// recorded from from up to but not including to, Unix milliseconds, with
+// up to limit entries, oldest first, resuming after the cursor. Totals
+// cover the whole period whatever the page.
+func (r *ProductRepository) SellerSettlementReport(ctx context.Context, sellerID string, from, to int64, after pagetoken.Cursor, limit int) (*SettlementReport, error) {
+	if sellerID == "" {
+		return nil, fieldErrorf("seller_id", "seller id is required")
+	}
+	if to <= from {
+		return nil, fieldErrorf("to", "the period must end after it starts")
+	}
+	params := map[string]any{
+		"seller_id":  sellerID,
+		"from":       from,
+		"to":         to,
+		"after_time": after.LastTime,
+		"after_id":   after.LastID,
+		"limit":      limit,
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (sel:Seller {id: $seller_id})
+			OPTIONAL MATCH (e:SettlementEntry)-[:PAYS]->(sel)
+			WHERE e.created_at >= $from AND e.created_at < $to
+			RETURN sel IS NOT NULL AS seller, e.currency AS currency, count(e) AS lines,
+				sum(e.gross) AS gross, sum(e.commission) AS commission, sum(e.payout) AS payout
+			ORDER BY currency
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+		records, err := res.Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return nil, errSellerNotFound
+		}
+
+		report := &SettlementReport{}
+		for _, record := range records {
+			row := record.AsMap()
+			lines := int(getInt64(row, "lines"))
+			if lines == 0 {
+				continue
+			}
+			total := SettlementTotal{Currency: getString(row, "currency"), Lines: lines}
+			total.Gross, _ = row["gross"].(float64)
+			total.Commission, _ = row["commission"].(float64)
+			total.Payout, _ = row["payout"].(float64)
+			total.Gross, total.Commission, total.Payout = roundCents(total.Gross), roundCents(total.Commission), roundCents(total.Payout)
+			report.Totals = append(report.Totals, total)
+		}
+
+		res, err = tx.Run(ctx, `
+			MATCH (e:SettlementEntry)-[:PAYS]->(:Seller {id: $seller_id})
+			WHERE e.created_at >= $from AND e.created_at < $to
+				AND ($after_id = ''
+					OR e.created_at > $after_time
+					OR (e.created_at = $after_time AND e.id > $after_id))
+			RETURN e
+			ORDER BY e.created_at, e.id
+			LIMIT $limit
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+		for res.Next(ctx) {
+			node, _ := res.Record().Values[0].(neo4j.Node)
+			report.Entries = append(report.Entries, settlementEntryFromNode(node))
+		}
+		return report, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*SettlementReport), nil
+}
+
+func settlementEntryFromNode(node neo4j.Node) SettlementEntry {
+	props := node.Props
+	e := SettlementEntry{
+		ID:        getString(props, "id"),
+		OrderID:   getString(props, "order_id"),
+		SKU:       getString(props, "sku"),
+		SellerID:  getString(props, "seller_id"),
+		TenantID:  getString(props, "tenant_id"),
+		Category:  getString(props, "category"),
+		Currency:  getString(props, "currency"),
+		Quantity:  int32(getInt64(props, "quantity")),
+		CreatedAt: getInt64(props, "created_at"),
+	}
+	e.Gross, _ = props["gross"].(float64)
+	e.Rate, _ = props["rate"].(float64)
+	e.Commission, _ = props["commission"].(float64)
+	e.Payout, _ = props["payout"].(float64)
+	return e
+}
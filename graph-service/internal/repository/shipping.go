@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// A product's shipping profile is stored flat, as the weight_grams,
+// length_mm, width_mm and height_mm properties (or columns).
+func checkShipping(p *pb.Product) error {
+	s := p.GetShipping()
+	if s == nil {
+		return nil
+	}
+	if s.WeightGrams < 0 || s.LengthMm < 0 || s.WidthMm < 0 || s.HeightMm < 0 {
+		return fieldErrorf("product.shipping", "weight and dimensions can't be negative")
+	}
+	return nil
+}
+
+// shippingProps flattens the profile into the properties it is stored
+// as, to be set with +=. Without a profile there are none, so an update
+// keeps the stored ones.
+func shippingProps(s *pb.ShippingProfile) map[string]any {
+	if s == nil {
+		return map[string]any{}
+	}
+	return map[string]any{
+		"weight_grams": s.WeightGrams,
+		"length_mm":    s.LengthMm,
+		"width_mm":     s.WidthMm,
+		"height_mm":    s.HeightMm,
+	}
+}
+
+// shippingColumns is shippingProps for the products table: the column
+// values in order, all null without a profile.
+func shippingColumns(s *pb.ShippingProfile) []any {
+	if s == nil {
+		return []any{nil, nil, nil, nil}
+	}
+	return []any{s.WeightGrams, s.LengthMm, s.WidthMm, s.HeightMm}
+}
+
+// shippingFromProps returns the stored profile, or nil if the product
+// never had one.
+func shippingFromProps(props map[string]any) *pb.ShippingProfile {
+	if props["weight_grams"] == nil {
+		return nil
+	}
+	return &pb.ShippingProfile{
+		WeightGrams: int32(getInt64(props, "weight_grams")),
+		LengthMm:    int32(getInt64(props, "length_mm")),
+		WidthMm:     int32(getInt64(props, "width_mm")),
+		HeightMm:    int32(getInt64(props, "height_mm")),
+	}
+}
+
+// ShippingProfiles returns the shipping profile of the product selling
+// each SKU. SKUs no product sells are left out, and products without a
+// profile map to an empty one.
+func (r *ProductRepository) ShippingProfiles(ctx context.Context, skus []string) (map[string]*pb.ShippingProfile, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			UNWIND $skus AS sku
+			MATCH (p:Product)-[:HAS_SIZE]->(s:Size {sku: sku})
+			RETURN s.sku AS sku, p.weight_grams AS weight_grams, p.length_mm AS length_mm,
+				p.width_mm AS width_mm, p.height_mm AS height_mm
+		`, map[string]any{"skus": skus})
+		if err != nil {
+			return nil, err
+		}
+		records, err := res.Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		profiles := make(map[string]*pb.ShippingProfile, len(records))
+		for _, record := range records {
+			row := record.AsMap()
+			profile := shippingFromProps(row)
+			if profile == nil {
+				profile = &pb.ShippingProfile{}
+			}
+			profiles[getString(row, "sku")] = profile
+		}
+		return profiles, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(map[string]*pb.ShippingProfile), nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+var errSizeChartNotFound = notFoundf("size chart not found")
+
+/*
+NEED TO RUN ONCE IN NEO4J
+
+CREATE INDEX size_chart_key IF NOT EXISTS
+FOR (sc:SizeChart) ON (sc.brand, sc.main_category, sc.subcategory);
+*/
+
+// UpsertSizeChart creates or replaces the chart for its brand and category.
+// Rows are stored as a JSON string, like product attributes.
+func (r *ProductRepository) UpsertSizeChart(ctx context.Context, chart *pb.SizeChart) error {
+	if chart.GetBrand() == "" || chart.GetMainCategory() == "" {
+		return fieldErrorf("chart.brand", "size chart brand and main category are required")
+	}
+	if len(chart.GetRows()) == 0 {
+		return fieldErrorf("chart.rows", "size chart needs at least one row")
+	}
+	seen := make(map[string]bool, len(chart.Rows))
+	for _, row := range chart.Rows {
+		if row.GetSize() == "" {
+			return fieldErrorf("chart.rows", "size chart rows need a size")
+		}
+		if seen[row.Size] {
+			return fieldErrorf("chart.rows", "size %q listed more than once", row.Size)
+		}
+		seen[row.Size] = true
+	}
+
+	rowsJSON, err := json.Marshal(chart.Rows)
+	if err != nil {
+		return fmt.Errorf("failed to serialize size chart rows: %w", err)
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err = session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			MERGE (sc:SizeChart {brand: $brand, main_category: $main_category, subcategory: $subcategory})
+			ON CREATE SET sc.created_at = $now
+			SET sc.unit = $unit,
+				sc.rows = $rows,
+				sc.fit_notes = $fit_notes,
+				sc.updated_at = $now
+		`, map[string]any{
+			"brand":         chart.Brand,
+			"main_category": chart.MainCategory,
+			"subcategory":   chart.Subcategory,
+			"unit":          chart.Unit,
+			"rows":          string(rowsJSON),
+			"fit_notes":     chart.FitNotes,
+			"now":           time.Now().UnixMilli(),
+		})
+		return nil, err
+	})
+
+	return err
+}
+
+// GetSizeChart returns the chart for brand and category, preferring a
+// subcategory-specific chart over the main-category one.
+func (r *ProductRepository) GetSizeChart(ctx context.Context, brand, mainCategory, subcategory string) (*pb.SizeChart, error) {
+	if brand == "" || mainCategory == "" {
+		return nil, fieldErrorf("brand", "brand and main category are required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (sc:SizeChart {brand: $brand, main_category: $main_category})
+			WHERE sc.subcategory IN [$subcategory, '']
+			RETURN sc
+			ORDER BY sc.subcategory DESC
+			LIMIT 1
+		`, map[string]any{
+			"brand":         brand,
+			"main_category": mainCategory,
+			"subcategory":   subcategory,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			return nil, errSizeChartNotFound
+		}
+
+		node, _ := res.Record().Values[0].(neo4j.Node)
+		return sizeChartFromNode(node)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.SizeChart), nil
+}
+
+// SetCustomerMeasurements stores the customer's measurements, creating the
+// customer if needed.
+func (r *ProductRepository) SetCustomerMeasurements(ctx context.Context, customerID, unit string, measurements map[string]float64) error {
+	if customerID == "" {
+		return fieldErrorf("customer_id", "customer id is required")
+	}
+	for name, v := range measurements {
+		if v <= 0 {
+			return fieldErrorf("measurements", "measurement %q must be positive", name)
+		}
+	}
+
+	measurementsJSON, err := json.Marshal(measurements)
+	if err != nil {
+		return fmt.Errorf("failed to serialize measurements: %w", err)
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err = session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			MERGE (cu:Customer {id: $id})
+			ON CREATE SET cu.created_at = $now
+			SET cu.unit = $unit,
+				cu.measurements = $measurements,
+				cu.updated_at = $now
+		`, map[string]any{
+			"id":           customerID,
+			"unit":         unit,
+			"measurements": string(measurementsJSON),
+			"now":          time.Now().UnixMilli(),
+		})
+		return nil, err
+	})
+
+	return err
+}
+
+// GetCustomerMeasurements returns the customer's stored measurements and
+// their unit.
+func (r *ProductRepository) GetCustomerMeasurements(ctx context.Context, customerID string) (map[string]float64, string, error) {
+	if customerID == "" {
+		return nil, "", fieldErrorf("customer_id", "customer id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	type measurementsResult struct {
+		measurements map[string]float64
+		unit         string
+	}
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (cu:Customer {id: $id})
+			RETURN cu
+		`, map[string]any{"id": customerID})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			return nil, notFoundf("customer not found")
+		}
+
+		node, _ := res.Record().Values[0].(neo4j.Node)
+		mr := measurementsResult{unit: getString(node.Props, "unit")}
+		if raw := getString(node.Props, "measurements"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &mr.measurements); err != nil {
+				return nil, fmt.Errorf("failed to parse measurements: %w", err)
+			}
+		}
+		return mr, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	mr := result.(measurementsResult)
+	return mr.measurements, mr.unit, nil
+}
+
+func sizeChartFromNode(node neo4j.Node) (*pb.SizeChart, error) {
+	props := node.Props
+	chart := &pb.SizeChart{
+		Brand:        getString(props, "brand"),
+		MainCategory: getString(props, "main_category"),
+		Subcategory:  getString(props, "subcategory"),
+		Unit:         getString(props, "unit"),
+		FitNotes:     getString(props, "fit_notes"),
+		CreatedAt:    getInt64(props, "created_at"),
+		UpdatedAt:    getInt64(props, "updated_at"),
+	}
+	if raw := getString(props, "rows"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &chart.Rows); err != nil {
+			return nil, fmt.Errorf("failed to parse size chart rows: %w", err)
+		}
+	}
+	return chart, nil
+}
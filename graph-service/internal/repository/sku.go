@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// SKUs are unique across the catalog. Writes check for a conflict first so
+// they can name the owning product; the size_sku constraint (migration
+// 0002_unique_size_sku) catches writes that race past the check, and
+// resolveConflict looks up the owner for those.
+
+// checkSizes rejects sizes without a SKU or repeating one, so a request
+// never conflicts with itself.
+func checkSizes(field string, sizes []*pb.ProductSize) error {
+	seen := make(map[string]bool, len(sizes))
+	for _, size := range sizes {
+		if size.GetSku() == "" {
+			return fieldErrorf(field, "every size needs a sku")
+		}
+		if seen[size.Sku] {
+			return fieldErrorf(field, "sku %q listed more than once", size.Sku)
+		}
+		seen[size.Sku] = true
+	}
+	return nil
+}
+
+func sizeSkus(sizes []*pb.ProductSize) []string {
+	skus := make([]string, 0, len(sizes))
+	for _, size := range sizes {
+		skus = append(skus, size.Sku)
+	}
+	return skus
+}
+
+// findSkuConflict returns a ConflictError for the first of skus that is
+// already taken, or nil.
+func findSkuConflict(ctx context.Context, tx neo4j.ManagedTransaction, skus []string) error {
+	res, err := tx.Run(ctx, `
+		UNWIND $skus AS sku
+		MATCH (s:Size {sku: sku})
+		OPTIONAL MATCH (p:Product)-[:HAS_SIZE]->(s)
+		RETURN sku, p.id AS product_id
+		LIMIT 1
+	`, map[string]any{"skus": skus})
+	if err != nil {
+		return err
+	}
+
+	if !res.Next(ctx) {
+		return res.Err()
+	}
+	row := res.Record().AsMap()
+	return &ConflictError{Field: "sku", Value: getString(row, "sku"), ProductID: getString(row, "product_id")}
+}
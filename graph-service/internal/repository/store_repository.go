@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Physical stores keep their own stock of SKUs, apart from the catalog's
+// own: (:Size)-[:STOCKED_AT {stock}]->(:Store). A store's location is a
+// WGS-84 point, so distances come back in meters.
+
+var errStoreNotFound = notFoundf("store not found")
+
+// Store is a physical store. Stores that don't take pickups still show
+// their stock.
+type Store struct {
+	ID        string
+	TenantID  string
+	Name      string
+	Address   string
+	Latitude  float64
+	Longitude float64
+	Pickup    bool
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+// StoreStock is a store's stock of a SKU.
+type StoreStock struct {
+	SKU   string
+	Size  string
+	Stock int32
+}
+
+// StoreAvailability is a store's stock of a product's SKUs, and how far
+// it is from where it was looked up from.
+type StoreAvailability struct {
+	Store    Store
+	Distance float64
+	Stock    []StoreStock
+}
+
+// PickupLine is a cart line to collect from a store.
+type PickupLine struct {
+	SKU      string
+	Quantity int32
+}
+
+// PickupStore is a store that takes pickups, how far it is, and the SKUs
+// it lacks enough stock of for the cart; none when it can serve it all.
+type PickupStore struct {
+	Store    Store
+	Distance float64
+	Short    []string
+}
+
+// UpsertStore creates the store, with a new id unless it has one, or
+// replaces the one with its id.
+func (r *ProductRepository) UpsertStore(ctx context.Context, st Store) (*Store, error) {
+	if st.Name == "" {
+		return nil, fieldErrorf("store.name", "store name is required")
+	}
+	if st.Latitude < -90 || st.Latitude > 90 {
+		return nil, fieldErrorf("store.latitude", "latitude must be between -90 and 90")
+	}
+	if st.Longitude < -180 || st.Longitude > 180 {
+		return nil, fieldErrorf("store.longitude", "longitude must be between -180 and 180")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	st.ID = idOrNew(st.ID)
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MERGE (store:Store {id: $id})
+			ON CREATE SET store.created_at = $now
+			SET store.tenant_id = $tenant_id,
+				store.name = $name,
+				store.address = $address,
+				store.location = point({latitude: $latitude, longitude: $longitude}),
+				store.pickup = $pickup,
+				store.updated_at = $now
+			RETURN store
+		`, map[string]any{
+			"id":        st.ID,
+			"tenant_id": st.TenantID,
+			"name":      st.Name,
+			"address":   st.Address,
+			"latitude":  st.Latitude,
+			"longitude": st.Longitude,
+			"pickup":    st.Pickup,
+			"now":       time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		node, _ := record.Values[0].(neo4j.Node)
+		store := storeFromNode(node)
+		return &store, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*Store), nil
+}
+
+// SetStoreStock sets the store's stock of each SKU in stock. The SKUs
+// must exist and, for a tenant's store, be the tenant's; none is set
+// unless all are.
+func (r *ProductRepository) SetStoreStock(ctx context.Context, storeID string, stock []StoreStock) error {
+	if storeID == "" {
+		return fieldErrorf("store_id", "store id is required")
+	}
+	rows := make([]map[string]any, 0, len(stock))
+	for _, s := range stock {
+		if s.SKU == "" {
+			return fieldErrorf("stock.sku", "sku is required")
+		}
+		if s.Stock < 0 {
+			return fieldErrorf("stock.stock", "stock cannot be negative")
+		}
+		rows = append(rows, map[string]any{"sku": s.SKU, "stock": s.Stock})
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			OPTIONAL MATCH (store:Store {id: $store_id})
+			WITH store
+			UNWIND $rows AS row
+			OPTIONAL MATCH (p:Product)-[:HAS_SIZE]->(s:Size {sku: row.sku})
+			RETURN store IS NOT NULL AS store, row.sku AS sku, s IS NOT NULL AS found,
+				store.tenant_id = '' OR store.tenant_id = coalesce(p.tenant_id, '') AS allowed
+		`, map[string]any{
+			"store_id": storeID,
+			"rows":     rows,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for res.Next(ctx) {
+			row := res.Record().AsMap()
+			if found, _ := row["store"].(bool); !found {
+				return nil, errStoreNotFound
+			}
+			if found, _ := row["found"].(bool); !found {
+				return nil, notFoundf("sku %s not found", getString(row, "sku"))
+			}
+			if allowed, _ := row["allowed"].(bool); !allowed {
+				return nil, fieldErrorf("stock.sku", "sku %s belongs to another tenant's product", getString(row, "sku"))
+			}
+		}
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+
+		_, err = tx.Run(ctx, `
+			MATCH (store:Store {id: $store_id})
+			UNWIND $rows AS row
+			MATCH (s:Size {sku: row.sku})
+			MERGE (s)-[st:STOCKED_AT]->(store)
+			ON CREATE SET st.created_at = $now
+			SET st.stock = row.stock,
+				st.updated_at = $now
+		`, map[string]any{
+			"store_id": storeID,
+			"rows":     rows,
+			"now":      time.Now().UnixMilli(),
+		})
+		return nil, err
+	})
+
+	return err
+}
+
+// NearbyAvailability returns up to limit stores within radius meters of
+// (latitude, longitude) that have the product in stock, nearest first,
+// with the stock of each of its SKUs they have.
+func (r *ProductRepository) NearbyAvailability(ctx context.Context, productID string, latitude, longitude, radius float64, limit int) ([]StoreAvailability, error) {
+	if productID == "" {
+		return nil, fieldErrorf("product_id", "product id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (p:Product {id: $product_id})
+			OPTIONAL MATCH (p)-[:HAS_SIZE]->(s:Size)-[st:STOCKED_AT]->(store:Store)
+			WHERE st.stock > 0
+				AND point.distance(store.location, point({latitude: $latitude, longitude: $longitude})) <= $radius
+			WITH store, collect({sku: s.sku, size: s.size, stock: st.stock}) AS stock
+			RETURN store, point.distance(store.location, point({latitude: $latitude, longitude: $longitude})) AS distance, stock
+			ORDER BY distance, store.id
+			LIMIT $limit
+		`, map[string]any{
+			"product_id": productID,
+			"latitude":   latitude,
+			"longitude":  longitude,
+			"radius":     radius,
+			"limit":      limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// A product without nearby stock still matches once, without a store
+		found := false
+		stores := []StoreAvailability{}
+		for res.Next(ctx) {
+			found = true
+			row := res.Record().AsMap()
+			node, ok := row["store"].(neo4j.Node)
+			if !ok {
+				continue
+			}
+			availability := StoreAvailability{Store: storeFromNode(node)}
+			availability.Distance, _ = row["distance"].(float64)
+			list, _ := row["stock"].([]any)
+			for _, item := range list {
+				props, _ := item.(map[string]any)
+				availability.Stock = append(availability.Stock, StoreStock{
+					SKU:   getString(props, "sku"),
+					Size:  getString(props, "size"),
+					Stock: int32(getInt64(props, "stock")),
+				})
+			}
+			stores = append(stores, availability)
+		}
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, notFoundf("product not found")
+		}
+		return stores, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]StoreAvailability), nil
+}
+
+// PickupStores returns up to limit stores taking pickups within radius
+// meters of (latitude, longitude), those that can serve every line first,
+// then by the fewest SKUs they're short of, nearest first.
+func (r *ProductRepository) PickupStores(ctx context.Context, lines []PickupLine, latitude, longitude, radius float64, limit int) ([]PickupStore, error) {
+	rows := make([]map[string]any, 0, len(lines))
+	for _, l := range lines {
+		rows = append(rows, map[string]any{"sku": l.SKU, "quantity": l.Quantity})
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (store:Store {pickup: true})
+			WITH store, point.distance(store.location, point({latitude: $latitude, longitude: $longitude})) AS distance
+			WHERE distance <= $radius
+			UNWIND $lines AS line
+			OPTIONAL MATCH (:Size {sku: line.sku})-[st:STOCKED_AT]->(store)
+			WITH store, distance,
+				collect(CASE WHEN coalesce(st.stock, 0) < line.quantity THEN line.sku END) AS short
+			RETURN store, distance, short
+			ORDER BY size(short), distance, store.id
+			LIMIT $limit
+		`, map[string]any{
+			"lines":     rows,
+			"latitude":  latitude,
+			"longitude": longitude,
+			"radius":    radius,
+			"limit":     limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		stores := []PickupStore{}
+		for res.Next(ctx) {
+			row := res.Record().AsMap()
+			node, _ := row["store"].(neo4j.Node)
+			pickup := PickupStore{Store: storeFromNode(node), Short: getStrings(row, "short")}
+			pickup.Distance, _ = row["distance"].(float64)
+			stores = append(stores, pickup)
+		}
+		return stores, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]PickupStore), nil
+}
+
+func storeFromNode(node neo4j.Node) Store {
+	props := node.Props
+	pickup, _ := props["pickup"].(bool)
+	location, _ := props["location"].(neo4j.Point2D)
+	return Store{
+		ID:        getString(props, "id"),
+		TenantID:  getString(props, "tenant_id"),
+		Name:      getString(props, "name"),
+		Address:   getString(props, "address"),
+		Latitude:  location.Y,
+		Longitude: location.X,
+		Pickup:    pickup,
+		CreatedAt: getInt64(props, "created_at"),
+		UpdatedAt: getInt64(props, "updated_at"),
+	}
+}
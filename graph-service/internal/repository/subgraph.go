@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/graphexport"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// subgraphRelationships are the merchandising relationships ExportSubgraph
+// walks. Customer and order data is left out, so exports carry nothing
+// personal.
+var subgraphRelationships = []string{
+	"BELONGS_TO", "HAS_SUBCATEGORY", "HAS_TYPE", "HAS_SIZE", "INCLUDES", "CONTAINS", RelCrossSell, RelUpsell,
+}
+
+// ExportSubgraph limits.
+const (
+	defaultSubgraphDepth = 2
+	maxSubgraphDepth     = 4
+	defaultSubgraphNodes = 200
+	maxSubgraphNodes     = 2000
+	// subgraphRowsPerNode caps the relationships each hop reads per node
+	// allowed, so a hop through a large category stays bounded.
+	subgraphRowsPerNode = 10
+)
+
+// subgraphCaption picks a node's display name.
+func subgraphCaption(n neo4j.Node) string {
+	for _, key := range []string{"name", "sku", "code", "id"} {
+		if val := getString(n.Props, key); val != "" {
+			return val
+		}
+	}
+	return ""
+}
+
+// subgraphStart returns the query matching the start node as n: the
+// product, or the deepest level of the category path given.
+func subgraphStart(productID string, category *pb.ProductCategory) (string, error) {
+	switch {
+	case productID != "" && category != nil:
+		return "", fieldErrorf("category", "give a product id or a category, not both")
+	case productID != "":
+		return "MATCH (n:Product {id: $product_id})", nil
+	case category.GetMainCategory() == "":
+		return "", fieldErrorf("product_id", "a product id or a main category is required")
+	case category.SpecificType != "":
+		return "MATCH (n:Category {main_category: $main_category, subcategory: $subcategory, specific_type: $specific_type})", nil
+	case category.Subcategory != "":
+		return "MATCH (:MainCategory {name: $main_category})-[:HAS_SUBCATEGORY]->(n:Subcategory {name: $subcategory})", nil
+	default:
+		return "MATCH (n:MainCategory {name: $main_category})", nil
+	}
+}
+
+// ExportSubgraph walks out from a product or category, breadth first, for
+// up to depth hops and maxNodes nodes, and returns the nodes reached with
+// the relationships walked between them. With tenantID set, it doesn't
+// step onto nodes of other tenants.
+func (r *ProductRepository) ExportSubgraph(ctx context.Context, tenantID, productID string, category *pb.ProductCategory, depth, maxNodes int) (*graphexport.Graph, error) {
+	start, err := subgraphStart(productID, category)
+	if err != nil {
+		return nil, err
+	}
+	if depth <= 0 {
+		depth = defaultSubgraphDepth
+	}
+	if depth > maxSubgraphDepth {
+		return nil, fieldErrorf("depth", "depth must be at most %d", maxSubgraphDepth)
+	}
+	if maxNodes <= 0 {
+		maxNodes = defaultSubgraphNodes
+	}
+	if maxNodes > maxSubgraphNodes {
+		return nil, fieldErrorf("max_nodes", "max nodes must be at most %d", maxSubgraphNodes)
+	}
+	release, err := r.acquireTraversal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, start+`
+			RETURN `+r.dialect.nodeID("n")+` AS key, n
+			LIMIT 1
+		`, map[string]any{
+			"product_id":    productID,
+			"main_category": category.GetMainCategory(),
+			"subcategory":   category.GetSubcategory(),
+			"specific_type": category.GetSpecificType(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			if productID != "" {
+				return nil, notFoundf("product %s not found", productID)
+			}
+			return nil, notFoundf("category %s not found", strings.Join([]string{category.MainCategory, category.Subcategory, category.SpecificType}, "/"))
+		}
+
+		graph := &graphexport.Graph{}
+		seen := map[string]bool{}
+		edges := map[string]bool{}
+		add := func(key any, n neo4j.Node) {
+			id := fmt.Sprint(key)
+			seen[id] = true
+			graph.Nodes = append(graph.Nodes, graphexport.Node{
+				ID:         id,
+				Labels:     n.Labels,
+				Caption:    subgraphCaption(n),
+				Properties: n.Props,
+			})
+		}
+
+		row := res.Record().AsMap()
+		node, _ := row["n"].(neo4j.Node)
+		add(row["key"], node)
+		frontier := []any{row["key"]}
+
+		rows := maxNodes * subgraphRowsPerNode
+		for hop := 1; hop <= depth && len(frontier) > 0; hop++ {
+			res, err := tx.Run(ctx, `
+				UNWIND $frontier AS key
+				MATCH (n)-[rel:`+strings.Join(subgraphRelationships, "|")+`]-(m)
+				WHERE `+r.dialect.nodeID("n")+` = key
+					AND ($tenant_id = '' OR m.tenant_id IS NULL OR m.tenant_id = $tenant_id)
+				RETURN `+r.dialect.nodeID("startNode(rel)")+` AS source, `+r.dialect.nodeID("endNode(rel)")+` AS target,
+					rel, `+r.dialect.nodeID("m")+` AS key, m
+				LIMIT $rows
+			`, map[string]any{"frontier": frontier, "rows": rows, "tenant_id": tenantID})
+			if err != nil {
+				return nil, err
+			}
+
+			var next []any
+			read := 0
+			for res.Next(ctx) {
+				read++
+				row := res.Record().AsMap()
+				if key := fmt.Sprint(row["key"]); !seen[key] {
+					if len(graph.Nodes) == maxNodes {
+						graph.Truncated = true
+						continue
+					}
+					m, _ := row["m"].(neo4j.Node)
+					add(row["key"], m)
+					next = append(next, row["key"])
+				}
+
+				rel, _ := row["rel"].(neo4j.Relationship)
+				edge := graphexport.Edge{
+					Source:     fmt.Sprint(row["source"]),
+					Target:     fmt.Sprint(row["target"]),
+					Type:       rel.Type,
+					Properties: rel.Props,
+				}
+				id := edge.Source + "-" + edge.Type + "-" + edge.Target
+				if !edges[id] {
+					edges[id] = true
+					graph.Edges = append(graph.Edges, edge)
+				}
+			}
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			if read == rows {
+				graph.Truncated = true
+			}
+			frontier = next
+		}
+		return graph, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*graphexport.Graph), nil
+}
@@ -0,0 +1,484 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Sellers submit products, (:Seller)-[:SUBMITTED]->(:Submission), which
+// are mapped onto the catalog's: by GTIN, or by name and attributes among
+// the products of the same brand. A submission that matches one product
+// is mapped to it, (:Submission)-[:MAPS_TO]->(:Product); one that could be
+// any of several waits for a reviewer as ambiguous, and one like nothing
+// in the catalog waits for approval as a new product. The products it was
+// compared with are kept as (:Submission)-[:CANDIDATE {score, reason}]->.
+const (
+	SubmissionMatched         = "matched"
+	SubmissionAmbiguous       = "ambiguous"
+	SubmissionPendingApproval = "pending_approval"
+	SubmissionCreated         = "created"
+	SubmissionRejected        = "rejected"
+
+	// Why a candidate was proposed
+	MatchGTIN       = "gtin"
+	MatchAttributes = "attributes"
+
+	// Candidates at autoMatchSimilarity or more are taken as the product,
+	// if there is only one; those at reviewSimilarity or more are shown
+	// to a reviewer.
+	autoMatchSimilarity  = 0.9
+	reviewSimilarity     = 0.6
+	maxMappingCandidates = 5
+)
+
+// openSubmissionStatuses are those a reviewer can still decide.
+var openSubmissionStatuses = []string{SubmissionAmbiguous, SubmissionPendingApproval}
+
+var errSubmissionNotFound = notFoundf("submission not found")
+
+// Submission is a product a seller submitted and what became of it.
+// ProductID is the catalog product it maps to, once matched or created.
+type Submission struct {
+	ID         string
+	SellerID   string
+	TenantID   string
+	Status     string
+	Product    *pb.Product
+	ProductID  string
+	Candidates []MappingCandidate
+	Reason     string
+	ReviewedBy string
+	CreatedAt  int64
+	UpdatedAt  int64
+}
+
+// MappingCandidate is a catalog product a submission may be, with how
+// similar it is, from 0 to 1, and why it was proposed.
+type MappingCandidate struct {
+	ProductID string
+	Name      string
+	Score     float64
+	Reason    string
+}
+
+// mapSubmission decides a submission's status from the candidates found
+// for it, best first.
+func mapSubmission(candidates []MappingCandidate) (status, productID string) {
+	if len(candidates) == 0 {
+		return SubmissionPendingApproval, ""
+	}
+	if candidates[0].Reason == MatchGTIN {
+		return SubmissionMatched, candidates[0].ProductID
+	}
+	if candidates[0].Score >= autoMatchSimilarity &&
+		(len(candidates) == 1 || candidates[1].Score < autoMatchSimilarity) {
+		return SubmissionMatched, candidates[0].ProductID
+	}
+	return SubmissionAmbiguous, ""
+}
+
+// SubmitProduct maps the product sellerID submits onto the catalog of the
+// seller's tenant and stores the submission. The product's own id is
+// ignored; it gets one if a reviewer approves it as new.
+func (r *ProductRepository) SubmitProduct(ctx context.Context, sellerID string, p *pb.Product) (*Submission, error) {
+	if sellerID == "" {
+		return nil, fieldErrorf("seller_id", "seller id is required")
+	}
+	if p == nil {
+		return nil, fieldErrorf("product", "product is required")
+	}
+	if p.Name == "" {
+		return nil, fieldErrorf("product.name", "product name is required")
+	}
+	if p.Brand == "" {
+		return nil, fieldErrorf("product.brand", "product brand is required")
+	}
+	p = proto.CloneOf(p)
+	p.Id = ""
+	if err := checkIdentifiers(p); err != nil {
+		return nil, err
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	sub := &Submission{
+		ID:        newID(),
+		SellerID:  sellerID,
+		CreatedAt: time.Now().UnixMilli(),
+	}
+	sub.UpdatedAt = sub.CreatedAt
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (sel:Seller {id: $seller_id})
+			RETURN coalesce(sel.tenant_id, '') AS tenant_id
+		`, map[string]any{"seller_id": sellerID})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, errSellerNotFound
+		}
+		sub.TenantID = getString(res.Record().AsMap(), "tenant_id")
+		p.TenantId = sub.TenantID
+
+		sub.Candidates, err = findMappingCandidates(ctx, tx, p)
+		if err != nil {
+			return nil, err
+		}
+		sub.Status, sub.ProductID = mapSubmission(sub.Candidates)
+		sub.Product = p
+
+		encoded, err := protojson.Marshal(p)
+		if err != nil {
+			return nil, err
+		}
+		candidates := make([]map[string]any, 0, len(sub.Candidates))
+		for _, c := range sub.Candidates {
+			candidates = append(candidates, map[string]any{
+				"product_id": c.ProductID,
+				"score":      c.Score,
+				"reason":     c.Reason,
+			})
+		}
+		_, err = tx.Run(ctx, `
+			MATCH (sel:Seller {id: $seller_id})
+			CREATE (sel)-[:SUBMITTED]->(sub:Submission {
+				id: $id,
+				seller_id: $seller_id,
+				tenant_id: $tenant_id,
+				status: $status,
+				product: $product,
+				name: $name,
+				created_at: $now,
+				updated_at: $now
+			})
+			WITH sub
+			UNWIND $candidates AS candidate
+			MATCH (p:Product {id: candidate.product_id})
+			CREATE (sub)-[:CANDIDATE {score: candidate.score, reason: candidate.reason}]->(p)
+			WITH sub, p
+			WHERE p.id = $product_id
+			CREATE (sub)-[:MAPS_TO]->(p)
+		`, map[string]any{
+			"id":         sub.ID,
+			"seller_id":  sellerID,
+			"tenant_id":  sub.TenantID,
+			"status":     sub.Status,
+			"product":    string(encoded),
+			"name":       p.Name,
+			"now":        sub.CreatedAt,
+			"candidates": candidates,
+			"product_id": sub.ProductID,
+		})
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// findMappingCandidates returns the products of p's tenant p may be, best
+// first: the one with its GTIN, or those of its brand that are similar
+// enough for a reviewer to consider.
+func findMappingCandidates(ctx context.Context, tx neo4j.ManagedTransaction, p *pb.Product) ([]MappingCandidate, error) {
+	if p.Gtin != "" {
+		res, err := tx.Run(ctx, `
+			MATCH (p:Product {gtin: $gtin})
+			WHERE $tenant_id = '' OR p.tenant_id = $tenant_id
+			RETURN p.id AS id, p.name AS name
+			LIMIT 1
+		`, map[string]any{"gtin": p.Gtin, "tenant_id": p.TenantId})
+		if err != nil {
+			return nil, err
+		}
+		if res.Next(ctx) {
+			row := res.Record().AsMap()
+			return []MappingCandidate{{
+				ProductID: getString(row, "id"),
+				Name:      getString(row, "name"),
+				Score:     1,
+				Reason:    MatchGTIN,
+			}}, nil
+		}
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := tx.Run(ctx, `
+		MATCH (p:Product)
+		WHERE ($tenant_id = '' OR p.tenant_id = $tenant_id) AND toLower(p.brand) = $brand
+		RETURN p.id AS id, p.name AS name, p.brand AS brand, p.color AS color, p.attributes AS attributes
+	`, map[string]any{
+		"tenant_id": p.TenantId,
+		"brand":     strings.ToLower(p.Brand),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	submitted := newDuplicateCandidate("", p.Name, p.Brand, p.Color, p.Attributes)
+	var candidates []MappingCandidate
+	for res.Next(ctx) {
+		row := res.Record().AsMap()
+		var attributes map[string]string
+		if encoded := getString(row, "attributes"); encoded != "" {
+			json.Unmarshal([]byte(encoded), &attributes)
+		}
+		existing := newDuplicateCandidate(getString(row, "id"), getString(row, "name"),
+			getString(row, "brand"), getString(row, "color"), attributes)
+		if score := submitted.similarity(existing); score >= reviewSimilarity {
+			candidates = append(candidates, MappingCandidate{
+				ProductID: existing.id,
+				Name:      getString(row, "name"),
+				Score:     score,
+				Reason:    MatchAttributes,
+			})
+		}
+	}
+	if err := res.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(candidates, func(a, b MappingCandidate) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.ProductID, b.ProductID))
+	})
+	if len(candidates) > maxMappingCandidates {
+		candidates = candidates[:maxMappingCandidates]
+	}
+	return candidates, nil
+}
+
+// submissionReturn completes a query leaving a row per submission sub,
+// in order.
+const submissionReturn = `
+	OPTIONAL MATCH (sub)-[:MAPS_TO]->(mapped:Product)
+	OPTIONAL MATCH (sub)-[c:CANDIDATE]->(cp:Product)
+	WITH sub, mapped, c, cp
+	ORDER BY c.score DESC, cp.id
+	WITH sub, mapped, collect(CASE WHEN cp IS NULL THEN NULL ELSE
+		{product_id: cp.id, name: cp.name, score: c.score, reason: c.reason} END) AS candidates
+	RETURN sub, mapped.id AS product_id, candidates
+	ORDER BY sub.created_at, sub.id
+`
+
+func (r *ProductRepository) GetSubmission(ctx context.Context, id string) (*Submission, error) {
+	if id == "" {
+		return nil, fieldErrorf("id", "submission id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return getSubmission(ctx, tx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*Submission), nil
+}
+
+func getSubmission(ctx context.Context, tx neo4j.ManagedTransaction, id string) (*Submission, error) {
+	res, err := tx.Run(ctx, `
+		MATCH (sub:Submission {id: $id})
+	`+submissionReturn, map[string]any{"id": id})
+	if err != nil {
+		return nil, err
+	}
+	if !res.Next(ctx) {
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errSubmissionNotFound
+	}
+	return submissionFromRecord(res.Record())
+}
+
+// ListSubmissions returns up to limit submissions in one of statuses, or
+// in any when there are none, of tenantID and sellerID, or of every tenant
+// or seller when they're empty, oldest first, resuming after the cursor.
+func (r *ProductRepository) ListSubmissions(ctx context.Context, tenantID, sellerID string, statuses []string, after pagetoken.Cursor, limit int) ([]Submission, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (sub:Submission)
+			WHERE ($tenant_id = '' OR sub.tenant_id = $tenant_id)
+				AND ($seller_id = '' OR sub.seller_id = $seller_id)
+				AND (size($statuses) = 0 OR sub.status IN $statuses)
+				AND ($after_id = ''
+					OR sub.created_at > $after_time
+					OR (sub.created_at = $after_time AND sub.id > $after_id))
+			WITH sub
+			ORDER BY sub.created_at, sub.id
+			LIMIT $limit
+		`+submissionReturn, map[string]any{
+			"tenant_id":  tenantID,
+			"seller_id":  sellerID,
+			"statuses":   statuses,
+			"after_time": after.LastTime,
+			"after_id":   after.LastID,
+			"limit":      limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		submissions := []Submission{}
+		for res.Next(ctx) {
+			sub, err := submissionFromRecord(res.Record())
+			if err != nil {
+				return nil, err
+			}
+			submissions = append(submissions, *sub)
+		}
+		return submissions, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]Submission), nil
+}
+
+// ReviewSubmission settles an ambiguous or pending submission: status
+// matched or created maps it to productID, which must be of the
+// submission's tenant, and rejected turns it down.
+func (r *ProductRepository) ReviewSubmission(ctx context.Context, id, status, productID, reviewer, reason string) (*Submission, error) {
+	if id == "" {
+		return nil, fieldErrorf("id", "submission id is required")
+	}
+	if len(reason) > maxModerationReason {
+		return nil, &LimitError{Field: "reason", Limit: maxModerationReason, Actual: len(reason)}
+	}
+	switch status {
+	case SubmissionMatched, SubmissionCreated:
+		if productID == "" {
+			return nil, fieldErrorf("product_id", "product id is required")
+		}
+	case SubmissionRejected:
+		productID = ""
+	default:
+		return nil, fieldErrorf("status", "unknown review status %q", status)
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (sub:Submission {id: $id})
+			OPTIONAL MATCH (p:Product {id: $product_id})
+			RETURN sub.status AS status, p IS NOT NULL AS product,
+				sub.tenant_id = '' OR sub.tenant_id = coalesce(p.tenant_id, '') AS same_tenant
+		`, map[string]any{
+			"id":         id,
+			"product_id": productID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, errSubmissionNotFound
+		}
+		row := res.Record().AsMap()
+		if current := getString(row, "status"); !slices.Contains(openSubmissionStatuses, current) {
+			return nil, fmt.Errorf("%w: submission %s is %s", ErrInvalidTransition, id, current)
+		}
+		if productID != "" {
+			if found, _ := row["product"].(bool); !found {
+				return nil, notFoundf("product not found")
+			}
+			if same, _ := row["same_tenant"].(bool); !same {
+				return nil, fieldErrorf("product_id", "product %s belongs to another tenant", productID)
+			}
+		}
+
+		_, err = tx.Run(ctx, `
+			MATCH (sub:Submission {id: $id})
+			SET sub.status = $status,
+				sub.reviewed_by = $reviewer,
+				sub.review_reason = $reason,
+				sub.reviewed_at = $now,
+				sub.updated_at = $now
+			WITH sub
+			MATCH (p:Product {id: $product_id})
+			CREATE (sub)-[:MAPS_TO]->(p)
+		`, map[string]any{
+			"id":         id,
+			"status":     status,
+			"product_id": productID,
+			"reviewer":   reviewer,
+			"reason":     reason,
+			"now":        time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return getSubmission(ctx, tx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*Submission), nil
+}
+
+func submissionFromRecord(record *neo4j.Record) (*Submission, error) {
+	row := record.AsMap()
+	node, _ := row["sub"].(neo4j.Node)
+	props := node.Props
+	sub := &Submission{
+		ID:         getString(props, "id"),
+		SellerID:   getString(props, "seller_id"),
+		TenantID:   getString(props, "tenant_id"),
+		Status:     getString(props, "status"),
+		ProductID:  getString(row, "product_id"),
+		Reason:     getString(props, "review_reason"),
+		ReviewedBy: getString(props, "reviewed_by"),
+		CreatedAt:  getInt64(props, "created_at"),
+		UpdatedAt:  getInt64(props, "updated_at"),
+		Product:    &pb.Product{},
+	}
+	if err := protojson.Unmarshal([]byte(getString(props, "product")), sub.Product); err != nil {
+		return nil, fmt.Errorf("submission %s: %w", sub.ID, err)
+	}
+	candidates, _ := row["candidates"].([]any)
+	for _, raw := range candidates {
+		c, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		score, _ := c["score"].(float64)
+		sub.Candidates = append(sub.Candidates, MappingCandidate{
+			ProductID: getString(c, "product_id"),
+			Name:      getString(c, "name"),
+			Score:     score,
+			Reason:    getString(c, "reason"),
+		})
+	}
+	return sub, nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const defaultSyncRunsLimit = 20
+
+// StartSyncRun records a new run of the named sync source and leases the
+// source to it, so replicas don't sync it at once. It returns the run,
+// resuming from the source's cursor, and when the source's last successful
+// run finished, in Unix milliseconds (0 for never). The run is nil while
+// another run holds an unexpired lease.
+func (r *ProductRepository) StartSyncRun(ctx context.Context, source string, lease time.Duration) (*pb.SyncRun, int64, error) {
+	if source == "" {
+		return nil, 0, fieldErrorf("source", "sync source is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	type started struct {
+		run        *pb.SyncRun
+		lastSynced int64
+	}
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		// Writing updated_at locks the source before its lease is read
+		res, err := tx.Run(ctx, `
+			MERGE (src:SyncSource {name: $source})
+			ON CREATE SET src.cursor = 0, src.created_at = $now
+			SET src.updated_at = $now
+			WITH src
+			WHERE coalesce(src.lease_until, 0) < $now
+			SET src.lease_until = $now + $lease
+			CREATE (run:SyncRun {
+				id: $id,
+				source: $source,
+				status: $status,
+				started_at: $now,
+				cursor_from: src.cursor,
+				cursor_to: src.cursor,
+				created: 0, updated: 0, unchanged: 0, skipped: 0, failed: 0,
+				error: ''
+			})-[:OF_SOURCE]->(src)
+			RETURN run, coalesce(src.last_synced_at, 0) AS last_synced_at
+		`, map[string]any{
+			"source": source,
+			"id":     newID(),
+			"status": pb.SyncRunStatus_SYNC_RUNNING.String(),
+			"lease":  lease.Milliseconds(),
+			"now":    time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			return started{}, res.Err()
+		}
+		record := res.Record()
+		runNode, _ := record.Values[0].(neo4j.Node)
+		lastSynced, _ := record.Values[1].(int64)
+		return started{syncRunFromNode(runNode), lastSynced}, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s := result.(started)
+	return s.run, s.lastSynced, nil
+}
+
+// syncRunProgress is what a run records as it goes.
+func syncRunProgress(run *pb.SyncRun) map[string]any {
+	return map[string]any{
+		"cursor_to": run.CursorTo,
+		"created":   run.Created,
+		"updated":   run.Updated,
+		"unchanged": run.Unchanged,
+		"skipped":   run.Skipped,
+		"failed":    run.Failed,
+		"error":     run.Error,
+	}
+}
+
+// SaveSyncProgress records the run's counts so far and renews its lease on
+// the source.
+func (r *ProductRepository) SaveSyncProgress(ctx context.Context, run *pb.SyncRun, lease time.Duration) error {
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			MATCH (run:SyncRun {id: $id})-[:OF_SOURCE]->(src:SyncSource)
+			SET run += $progress,
+				src.lease_until = $now + $lease
+		`, map[string]any{
+			"id":       run.Id,
+			"progress": syncRunProgress(run),
+			"lease":    lease.Milliseconds(),
+			"now":      time.Now().UnixMilli(),
+		})
+		return nil, err
+	})
+	return err
+}
+
+// FinishSyncRun records how the run ended and releases its source. A
+// successful run moves the source's cursor to the run's, so the next run
+// resumes from there.
+func (r *ProductRepository) FinishSyncRun(ctx context.Context, run *pb.SyncRun) error {
+	now := time.Now().UnixMilli()
+	run.FinishedAt = now
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			MATCH (run:SyncRun {id: $id})-[:OF_SOURCE]->(src:SyncSource)
+			SET run += $progress,
+				run.status = $status,
+				run.finished_at = $now,
+				src.lease_until = null,
+				src.updated_at = $now
+			WITH run, src
+			WHERE $succeeded
+			SET src.cursor = run.cursor_to,
+				src.last_synced_at = $now
+		`, map[string]any{
+			"id":        run.Id,
+			"progress":  syncRunProgress(run),
+			"status":    run.Status.String(),
+			"succeeded": run.Status == pb.SyncRunStatus_SYNC_SUCCEEDED,
+			"now":       now,
+		})
+		return nil, err
+	})
+	return err
+}
+
+// LastSyncedAt returns when the named source's last successful run
+// finished, in Unix milliseconds (0 for never).
+func (r *ProductRepository) LastSyncedAt(ctx context.Context, source string) (int64, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (src:SyncSource {name: $source})
+			RETURN coalesce(src.last_synced_at, 0)
+		`, map[string]any{"source": source})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			return int64(0), res.Err()
+		}
+		lastSynced, _ := res.Record().Values[0].(int64)
+		return lastSynced, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.(int64), nil
+}
+
+// ListSyncRuns returns up to limit runs, newest first, of the named source
+// or of every source.
+func (r *ProductRepository) ListSyncRuns(ctx context.Context, source string, limit int) ([]*pb.SyncRun, error) {
+	if limit <= 0 {
+		limit = defaultSyncRunsLimit
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (run:SyncRun)
+			WHERE $source = '' OR run.source = $source
+			RETURN run
+			ORDER BY run.started_at DESC, run.id DESC
+			LIMIT $limit
+		`, map[string]any{"source": source, "limit": limit})
+		if err != nil {
+			return nil, err
+		}
+
+		runs := []*pb.SyncRun{}
+		for res.Next(ctx) {
+			runNode, _ := res.Record().Values[0].(neo4j.Node)
+			runs = append(runs, syncRunFromNode(runNode))
+		}
+		return runs, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]*pb.SyncRun), nil
+}
+
+func syncRunFromNode(node neo4j.Node) *pb.SyncRun {
+	props := node.Props
+	return &pb.SyncRun{
+		Id:         getString(props, "id"),
+		Source:     getString(props, "source"),
+		Status:     pb.SyncRunStatus(pb.SyncRunStatus_value[getString(props, "status")]),
+		StartedAt:  getInt64(props, "started_at"),
+		FinishedAt: getInt64(props, "finished_at"),
+		CursorFrom: getInt64(props, "cursor_from"),
+		CursorTo:   getInt64(props, "cursor_to"),
+		Created:    int32(getInt64(props, "created")),
+		Updated:    int32(getInt64(props, "updated")),
+		Unchanged:  int32(getInt64(props, "unchanged")),
+		Skipped:    int32(getInt64(props, "skipped")),
+		Failed:     int32(getInt64(props, "failed")),
+		Error:      getString(props, "error"),
+	}
+}
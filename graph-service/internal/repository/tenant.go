@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// ProductTenants returns the tenant of each product named in ids or
+// owning a SKU in skus, by product id. Products that don't exist are left
+// out.
+func (r *ProductRepository) ProductTenants(ctx context.Context, ids, skus []string) (map[string]string, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (p:Product)
+			WHERE p.id IN $ids
+			RETURN p.id AS id, coalesce(p.tenant_id, '') AS tenant_id
+			UNION
+			MATCH (p:Product)-[:HAS_SIZE]->(s:Size)
+			WHERE s.sku IN $skus
+			RETURN p.id AS id, coalesce(p.tenant_id, '') AS tenant_id
+		`, map[string]any{
+			"ids":  nonNil(ids),
+			"skus": nonNil(skus),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		tenants := map[string]string{}
+		for res.Next(ctx) {
+			row := res.Record().AsMap()
+			tenants[getString(row, "id")] = getString(row, "tenant_id")
+		}
+		return tenants, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(map[string]string), nil
+}
+
+// Node labels TenantOf looks up.
+const (
+	CollectionLabel  = "Collection"
+	BundleLabel      = "Bundle"
+	OrderLabel       = "Order"
+	StoreLabel       = "Store"
+	CustomerLabel    = "Customer"
+	ReservationLabel = "Reservation"
+	QuestionLabel    = "Question"
+	SellerLabel      = "Seller"
+	SubmissionLabel  = "Submission"
+	SessionLabel     = "VoiceSession"
+	CouponLabel      = "Coupon"
+)
+
+// TenantOf returns the tenant of the node labeled label with id (a code
+// for coupons), one of the labels above; found is false when there is
+// none.
+func (r *ProductRepository) TenantOf(ctx context.Context, label, id string) (tenant string, found bool, err error) {
+	key := "id"
+	if label == CouponLabel {
+		key, id = "code", normalizeCouponCode(id)
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	type owner struct {
+		tenant string
+		found  bool
+	}
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (n:`+label+` {`+key+`: $id})
+			RETURN coalesce(n.tenant_id, '') AS tenant_id
+		`, map[string]any{"id": id})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			return owner{}, res.Err()
+		}
+		return owner{tenant: getString(res.Record().AsMap(), "tenant_id"), found: true}, nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	o := result.(owner)
+	return o.tenant, o.found, nil
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const (
+	maxTranscriptEntriesPerCall = 500
+	maxTranscriptTextLen        = 4096
+	maxTranscriptDataLen        = 64 * 1024
+)
+
+var errVoiceSessionNotFound = notFoundf("voice session not found")
+
+// transcriptKinds are the kinds of entry a transcript can hold.
+var transcriptKinds = map[string]bool{
+	"utterance": true,
+	"query":     true,
+	"results":   true,
+	"action":    true,
+	"speech":    true,
+}
+
+func validateTranscriptEntries(entries []*pb.TranscriptEntry) error {
+	if len(entries) == 0 {
+		return fieldErrorf("entries", "at least one entry is required")
+	}
+	if len(entries) > maxTranscriptEntriesPerCall {
+		return &LimitError{Field: "entries", Limit: maxTranscriptEntriesPerCall, Actual: len(entries)}
+	}
+	for i, e := range entries {
+		field := fmt.Sprintf("entries[%d]", i)
+		if !transcriptKinds[e.GetKind()] {
+			return fieldErrorf(field+".kind", "unknown transcript entry kind %q", e.GetKind())
+		}
+		if len(e.GetText()) > maxTranscriptTextLen {
+			return &LimitError{Field: field + ".text", Limit: maxTranscriptTextLen, Actual: len(e.GetText())}
+		}
+		if len(e.GetData()) > maxTranscriptDataLen {
+			return &LimitError{Field: field + ".data", Limit: maxTranscriptDataLen, Actual: len(e.GetData())}
+		}
+		if e.GetData() != "" && !json.Valid([]byte(e.GetData())) {
+			return fieldErrorf(field+".data", "data must be JSON")
+		}
+	}
+	return nil
+}
+
+// AppendTranscript adds entries to the end of a voice session's
+// transcript, numbering them after the ones it has, and returns the last
+// number. The session's transcript is started on its first append.
+func (r *ProductRepository) AppendTranscript(ctx context.Context, sessionID, tenantID, customerID string, entries []*pb.TranscriptEntry) (int64, error) {
+	if sessionID == "" {
+		return 0, fieldErrorf("session_id", "session id is required")
+	}
+	if err := validateTranscriptEntries(entries); err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UnixMilli()
+	rows := make([]map[string]any, len(entries))
+	for i, e := range entries {
+		occurredAt := e.GetOccurredAt()
+		if occurredAt == 0 {
+			occurredAt = now
+		}
+		rows[i] = map[string]any{
+			"kind":        e.GetKind(),
+			"text":        e.GetText(),
+			"data":        e.GetData(),
+			"occurred_at": occurredAt,
+		}
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		// Setting updated_at locks the session before last_seq is read, so
+		// concurrent appends number their entries one after the other
+		res, err := tx.Run(ctx, `
+			MERGE (vs:VoiceSession {id: $session_id})
+			ON CREATE SET vs.tenant_id = $tenant_id, vs.started_at = $now, vs.last_seq = 0
+			SET vs.updated_at = $now
+			SET vs.customer_id = CASE WHEN $customer_id <> '' THEN $customer_id ELSE vs.customer_id END
+			WITH vs, vs.last_seq AS base
+			SET vs.last_seq = base + size($entries)
+			WITH vs, base
+			UNWIND range(0, size($entries) - 1) AS i
+			WITH vs, base + i + 1 AS seq, $entries[i] AS e
+			CREATE (vs)-[:HAS_ENTRY]->(:TranscriptEntry {
+				id: vs.id + '/' + toString(seq),
+				session_id: vs.id,
+				seq: seq,
+				kind: e.kind,
+				text: e.text,
+				data: e.data,
+				occurred_at: e.occurred_at
+			})
+			RETURN max(seq) AS last_seq
+		`, map[string]any{
+			"session_id":  sessionID,
+			"tenant_id":   tenantID,
+			"customer_id": customerID,
+			"entries":     rows,
+			"now":         now,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		lastSeq, _ := record.Get("last_seq")
+		return lastSeq, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	lastSeq, _ := result.(int64)
+	return lastSeq, nil
+}
+
+// GetSessionTranscript returns a voice session with up to limit of its
+// entries after afterSeq, in order.
+func (r *ProductRepository) GetSessionTranscript(ctx context.Context, sessionID string, afterSeq int64, limit int) (*pb.SessionTranscript, error) {
+	if sessionID == "" {
+		return nil, fieldErrorf("session_id", "session id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (vs:VoiceSession {id: $session_id})
+			OPTIONAL MATCH (vs)-[:HAS_ENTRY]->(te:TranscriptEntry)
+			WHERE te.seq > $after_seq
+			WITH vs, te
+			ORDER BY te.seq
+			WITH vs, collect(te)[..$limit] AS entries
+			RETURN vs, entries
+		`, map[string]any{
+			"session_id": sessionID,
+			"after_seq":  afterSeq,
+			"limit":      limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, errVoiceSessionNotFound
+		}
+		record := res.Record()
+		node, _ := record.Values[0].(neo4j.Node)
+		transcript := &pb.SessionTranscript{
+			SessionId:  getString(node.Props, "id"),
+			TenantId:   getString(node.Props, "tenant_id"),
+			CustomerId: getString(node.Props, "customer_id"),
+			StartedAt:  getInt64(node.Props, "started_at"),
+			UpdatedAt:  getInt64(node.Props, "updated_at"),
+		}
+		entries, _ := record.Values[1].([]any)
+		for _, raw := range entries {
+			entry, _ := raw.(neo4j.Node)
+			transcript.Entries = append(transcript.Entries, &pb.TranscriptEntry{
+				Seq:        getInt64(entry.Props, "seq"),
+				Kind:       getString(entry.Props, "kind"),
+				Text:       getString(entry.Props, "text"),
+				Data:       getString(entry.Props, "data"),
+				OccurredAt: getInt64(entry.Props, "occurred_at"),
+			})
+		}
+		return transcript, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.SessionTranscript), nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// normalizeLocale lower-cases a locale tag and uses '-' as the separator,
+// so "fr_CA" and "fr-ca" name the same translation.
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// localeChain returns the locales to try in order: the locale itself, then
+// its base language ("fr-ca" -> "fr").
+func localeChain(locale string) []string {
+	locale = normalizeLocale(locale)
+	if locale == "" {
+		return nil
+	}
+	chain := []string{locale}
+	if base, _, found := strings.Cut(locale, "-"); found && base != "" {
+		chain = append(chain, base)
+	}
+	return chain
+}
+
+func (r *ProductRepository) UpsertProductTranslation(ctx context.Context, productID, locale, name, description string) error {
+	locale = normalizeLocale(locale)
+	if productID == "" || locale == "" {
+		return fieldErrorf("locale", "product id and locale are required")
+	}
+	if name == "" && description == "" {
+		return fieldErrorf("name", "translation needs a name or a description")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	// The language label puts the translation in its language's search
+	// index, if it has one
+	label := ""
+	if language := translationLanguage(locale); language != "" {
+		label = ", t:" + translationLabel(language)
+	}
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (p:Product {id: $product_id})
+			MERGE (p)-[:HAS_TRANSLATION]->(t:Translation {locale: $locale})
+			ON CREATE SET t.created_at = $now
+			SET t.name = $name,
+				t.description = $description,
+				t.updated_at = $now`+label+`
+			RETURN p.id
+		`, map[string]any{
+			"product_id":  productID,
+			"locale":      locale,
+			"name":        name,
+			"description": description,
+			"now":         time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			return nil, notFoundf("product not found")
+		}
+		return nil, nil
+	})
+
+	return err
+}
+
+// LocalizeProducts overlays translated names and descriptions onto products
+// in place. Fields with no translation in the locale chain keep the default
+// content; Locale is set on products that got at least one translated field.
+func (r *ProductRepository) LocalizeProducts(ctx context.Context, products []*pb.Product, locale string) error {
+	chain := localeChain(locale)
+	if len(chain) == 0 || len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.Id)
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	type translation struct {
+		locale, name, description string
+	}
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		// One row per product and locale, most specific locale first
+		res, err := tx.Run(ctx, `
+			UNWIND $ids AS id
+			MATCH (:Product {id: id})-[:HAS_TRANSLATION]->(t:Translation)
+			WHERE t.locale IN $chain
+			RETURN id, t.locale AS locale, t.name AS name, t.description AS description
+			ORDER BY id, size(t.locale) DESC
+		`, map[string]any{
+			"ids":   ids,
+			"chain": chain,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		byID := map[string][]translation{}
+		for res.Next(ctx) {
+			record := res.Record()
+			id, _ := record.Values[0].(string)
+			t := translation{}
+			t.locale, _ = record.Values[1].(string)
+			t.name, _ = record.Values[2].(string)
+			t.description, _ = record.Values[3].(string)
+			byID[id] = append(byID[id], t)
+		}
+		return byID, res.Err()
+	})
+	if err != nil {
+		return err
+	}
+
+	byID := result.(map[string][]translation)
+	for _, p := range products {
+		var nameSet, descSet bool
+		for _, t := range byID[p.Id] {
+			if !nameSet && t.name != "" {
+				p.Name, nameSet = t.name, true
+				p.Locale = t.locale
+			}
+			if !descSet && t.description != "" {
+				p.Description, descSet = t.description, true
+				if p.Locale == "" {
+					p.Locale = t.locale
+				}
+			}
+		}
+	}
+
+	return nil
+}
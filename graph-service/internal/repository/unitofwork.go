@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+type unitKey struct{}
+
+// unit is the transaction repository calls made under one context share.
+type unit struct {
+	session neo4j.SessionWithContext
+	tx      neo4j.ManagedTransaction
+}
+
+// InTransaction runs fn in one write transaction that every repository
+// call fn makes with the context it's given joins, so they share a session
+// and connection and commit or roll back together. A call nested in
+// another unit joins the outer one.
+//
+// The driver retries fn whole on transient failures, so fn must not act
+// outside the database, e.g. take payments. A failed query leaves the
+// transaction unusable, so fn should return the first error it gets.
+func (r *ProductRepository) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.inUnit(ctx, neo4j.AccessModeWrite, fn)
+}
+
+// InReadTransaction is InTransaction for fn that only reads, which runs
+// on any cluster member. Repository calls that write fail in it.
+func (r *ProductRepository) InReadTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.inUnit(ctx, neo4j.AccessModeRead, fn)
+}
+
+func (r *ProductRepository) inUnit(ctx context.Context, mode neo4j.AccessMode, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(unitKey{}).(*unit); ok {
+		return fn(ctx)
+	}
+
+	session := r.newSession(ctx, mode)
+	defer r.closeSession(ctx, session)
+
+	work := func(tx neo4j.ManagedTransaction) (any, error) {
+		return nil, fn(context.WithValue(ctx, unitKey{}, &unit{session: session, tx: tx}))
+	}
+	var err error
+	if mode == neo4j.AccessModeRead {
+		_, err = session.ExecuteRead(ctx, work)
+	} else {
+		_, err = session.ExecuteWrite(ctx, work)
+	}
+	return err
+}
+
+// unitSession runs transaction work in its unit's transaction. Closing it
+// leaves the unit's session open for the calls still to come.
+type unitSession struct {
+	neo4j.SessionWithContext
+	tx neo4j.ManagedTransaction
+}
+
+func (s unitSession) ExecuteRead(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return work(s.tx)
+}
+
+func (s unitSession) ExecuteWrite(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return work(s.tx)
+}
+
+func (s unitSession) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Users are stored as the Customer nodes orders and measurements already
+// hang off, with a profile and preferences.
+const (
+	maxUserNameLen       = 256
+	maxEmailLen          = 320
+	maxFavoriteBrands    = 50
+	maxSizePreferences   = 50
+	maxPreferenceNameLen = 128
+)
+
+var errUserNotFound = notFoundf("user not found")
+
+func checkUser(u *pb.User) error {
+	u.Email = strings.ToLower(strings.TrimSpace(u.GetEmail()))
+	if u.Email == "" {
+		return fieldErrorf("user.email", "email is required")
+	}
+	if local, domain, ok := strings.Cut(u.Email, "@"); !ok || local == "" || !strings.Contains(domain, ".") || len(u.Email) > maxEmailLen {
+		return fieldErrorf("user.email", "email is not a valid address")
+	}
+	if len(u.Name) > maxUserNameLen {
+		return &LimitError{Field: "user.name", Limit: maxUserNameLen, Actual: len(u.Name)}
+	}
+	return checkPreferences("user.preferences", u.Preferences)
+}
+
+// checkPreferences validates prefs and normalizes its locale and brands.
+func checkPreferences(field string, prefs *pb.UserPreferences) error {
+	if prefs == nil {
+		return nil
+	}
+	if len(prefs.Sizes) > maxSizePreferences {
+		return &LimitError{Field: field + ".sizes", Limit: maxSizePreferences, Actual: len(prefs.Sizes)}
+	}
+	for category, size := range prefs.Sizes {
+		if category == "" || size == "" || len(category) > maxPreferenceNameLen || len(size) > maxPreferenceNameLen {
+			return fieldErrorf(field+".sizes", "sizes map category names to sizes, neither empty nor longer than %d characters", maxPreferenceNameLen)
+		}
+	}
+
+	if len(prefs.FavoriteBrands) > maxFavoriteBrands {
+		return &LimitError{Field: field + ".favorite_brands", Limit: maxFavoriteBrands, Actual: len(prefs.FavoriteBrands)}
+	}
+	brands := make([]string, 0, len(prefs.FavoriteBrands))
+	seen := make(map[string]bool, len(prefs.FavoriteBrands))
+	for _, brand := range prefs.FavoriteBrands {
+		brand = strings.TrimSpace(brand)
+		if brand == "" || seen[strings.ToLower(brand)] {
+			continue
+		}
+		if len(brand) > maxPreferenceNameLen {
+			return fieldErrorf(field+".favorite_brands", "brand names are at most %d characters", maxPreferenceNameLen)
+		}
+		seen[strings.ToLower(brand)] = true
+		brands = append(brands, brand)
+	}
+	prefs.FavoriteBrands = brands
+
+	prefs.Locale = normalizeLocale(prefs.Locale)
+	return nil
+}
+
+// preferenceParams flattens prefs into the size_preferences,
+// favorite_brands and locale properties.
+func preferenceParams(prefs *pb.UserPreferences) (map[string]any, error) {
+	sizes, err := json.Marshal(prefs.GetSizes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize size preferences: %w", err)
+	}
+	brands := prefs.GetFavoriteBrands()
+	if brands == nil {
+		brands = []string{}
+	}
+	return map[string]any{
+		"size_preferences": string(sizes),
+		"favorite_brands":  brands,
+		"locale":           prefs.GetLocale(),
+	}, nil
+}
+
+// CreateUser registers a user. An existing customer without a profile,
+// known only from orders or measurements, becomes the user; one with a
+// profile is a conflict. It fills in the user's id and timestamps.
+func (r *ProductRepository) CreateUser(ctx context.Context, u *pb.User) error {
+	if err := checkUser(u); err != nil {
+		return err
+	}
+	params, err := preferenceParams(u.Preferences)
+	if err != nil {
+		return err
+	}
+	u.Id = idOrNew(u.Id)
+	params["id"] = u.Id
+	params["tenant_id"] = u.TenantId
+	params["email"] = u.Email
+	params["name"] = u.Name
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		params["now"] = time.Now().UnixMilli()
+		res, err := tx.Run(ctx, `
+			MERGE (cu:Customer {id: $id})
+			ON CREATE SET cu.created_at = $now
+			RETURN cu.email IS NOT NULL AS registered
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if registered, _ := record.Values[0].(bool); registered {
+			return nil, fieldErrorf("user.id", "user %q already exists", u.Id)
+		}
+
+		_, err = tx.Run(ctx, `
+			MATCH (cu:Customer {id: $id})
+			SET cu.tenant_id = $tenant_id,
+				cu.email = $email,
+				cu.name = $name,
+				cu.size_preferences = $size_preferences,
+				cu.favorite_brands = $favorite_brands,
+				cu.locale = $locale,
+				cu.updated_at = $now
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+		return readUser(ctx, tx, u.Id)
+	})
+	if isConstraintViolation(err) {
+		return fieldErrorf("user.email", "email %q is already registered", u.Email)
+	}
+	if err != nil {
+		return err
+	}
+
+	created := result.(*pb.User)
+	u.CreatedAt, u.UpdatedAt = created.CreatedAt, created.UpdatedAt
+	u.Preferences = created.Preferences
+	return nil
+}
+
+// GetUser returns the user, or the bare customer if they never registered.
+func (r *ProductRepository) GetUser(ctx context.Context, id string) (*pb.User, error) {
+	if id == "" {
+		return nil, fieldErrorf("id", "user id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return readUser(ctx, tx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.User), nil
+}
+
+// UpdatePreferences replaces the user's preferences.
+func (r *ProductRepository) UpdatePreferences(ctx context.Context, userID string, prefs *pb.UserPreferences) (*pb.User, error) {
+	if userID == "" {
+		return nil, fieldErrorf("user_id", "user id is required")
+	}
+	if err := checkPreferences("preferences", prefs); err != nil {
+		return nil, err
+	}
+	params, err := preferenceParams(prefs)
+	if err != nil {
+		return nil, err
+	}
+	params["id"] = userID
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		params["now"] = time.Now().UnixMilli()
+		res, err := tx.Run(ctx, `
+			MATCH (cu:Customer {id: $id})
+			SET cu.size_preferences = $size_preferences,
+				cu.favorite_brands = $favorite_brands,
+				cu.locale = $locale,
+				cu.updated_at = $now
+			RETURN cu.id AS id
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, errUserNotFound
+		}
+		return readUser(ctx, tx, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*pb.User), nil
+}
+
+func readUser(ctx context.Context, tx neo4j.ManagedTransaction, id string) (*pb.User, error) {
+	res, err := tx.Run(ctx, `
+		MATCH (cu:Customer {id: $id})
+		RETURN cu
+	`, map[string]any{"id": id})
+	if err != nil {
+		return nil, err
+	}
+	if !res.Next(ctx) {
+		if err := res.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errUserNotFound
+	}
+
+	node, _ := res.Record().Values[0].(neo4j.Node)
+	props := node.Props
+	u := &pb.User{
+		Id:        getString(props, "id"),
+		TenantId:  getString(props, "tenant_id"),
+		Email:     getString(props, "email"),
+		Name:      getString(props, "name"),
+		CreatedAt: getInt64(props, "created_at"),
+		UpdatedAt: getInt64(props, "updated_at"),
+		Preferences: &pb.UserPreferences{
+			Locale: getString(props, "locale"),
+		},
+	}
+	if brands, ok := props["favorite_brands"].([]any); ok {
+		for _, brand := range brands {
+			if s, ok := brand.(string); ok {
+				u.Preferences.FavoriteBrands = append(u.Preferences.FavoriteBrands, s)
+			}
+		}
+	}
+	if raw := getString(props, "size_preferences"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &u.Preferences.Sizes); err != nil {
+			return nil, fmt.Errorf("failed to parse size preferences: %w", err)
+		}
+	}
+	return u, nil
+}
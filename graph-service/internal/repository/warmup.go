@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// VerifyConnectivity checks that the graph database can be reached.
+func (r *ProductRepository) VerifyConnectivity(ctx context.Context) error {
+	return r.driver.VerifyConnectivity(ctx)
+}
+
+// PopularProductIDs returns the ids of up to limit active products
+// ordered most often, most first.
+func (r *ProductRepository) PopularProductIDs(ctx context.Context, limit int) ([]string, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (p:Product:`+activeLabel+`)-[:HAS_SIZE]->(:Size)<-[:OF_SIZE]-(l:OrderLine)
+			WITH p, count(l) AS lines
+			ORDER BY lines DESC, p.id
+			LIMIT $limit
+			RETURN p.id AS id
+		`, map[string]any{"limit": limit})
+		if err != nil {
+			return nil, err
+		}
+
+		ids := []string{}
+		for res.Next(ctx) {
+			id, _ := res.Record().Get("id")
+			if s, ok := id.(string); ok {
+				ids = append(ids, s)
+			}
+		}
+		return ids, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]string), nil
+}
+
+// PrimeSearch runs a throwaway query against the productSearch fulltext
+// index, so the database has it open before the first real search.
+func (r *ProductRepository) PrimeSearch(ctx context.Context) error {
+	if !r.dialect.Fulltext {
+		return fmt.Errorf("prime search: %w", ErrUnsupported)
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			CALL db.index.fulltext.queryNodes($index, 'warmup') YIELD node
+			RETURN count(node) AS matches
+		`, map[string]any{"index": productSearchIndex})
+		if err != nil {
+			return nil, err
+		}
+		return res.Consume(ctx)
+	})
+	return err
+}
@@ -0,0 +1,557 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/egress"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/secretbox"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Delivery statuses stored on WebhookDelivery nodes.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WithWebhookSecrets seals webhook signing secrets with box before they are
+// stored. Without it webhooks can't be registered.
+func WithWebhookSecrets(box *secretbox.Box) Option {
+	return func(r *ProductRepository) {
+		r.webhookSecrets = box
+	}
+}
+
+// PendingDelivery is a claimed delivery together with the endpoint it
+// targets. Secret is still sealed; the dispatcher opens it to sign.
+type PendingDelivery struct {
+	Delivery *pb.WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// maxSellerWebhooks caps how many active webhooks a seller can register.
+const maxSellerWebhooks = 10
+
+// SellerWebhook is a webhook registered by a marketplace seller. It only
+// receives seller events about that seller.
+type SellerWebhook struct {
+	ID         string
+	SellerID   string
+	URL        string
+	EventTypes []string
+	CreatedAt  int64
+}
+
+func validateWebhook(endpoint, secret string, eventTypes []string, known func(string) bool) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fieldErrorf("url", "webhook url must be an absolute http(s) url")
+	}
+	if secret == "" {
+		return fieldErrorf("secret", "webhook secret is required")
+	}
+	if len(eventTypes) == 0 {
+		return fieldErrorf("event_types", "at least one event type is required")
+	}
+	for _, t := range eventTypes {
+		if !known(t) {
+			return fieldErrorf("event_types", "unknown event type %q", t)
+		}
+	}
+	return nil
+}
+
+// prepareWebhook validates a webhook, checks its url doesn't point into a
+// private network and seals its secret for storage.
+func (r *ProductRepository) prepareWebhook(ctx context.Context, endpoint, secret string, eventTypes []string, known func(string) bool) (string, error) {
+	if err := validateWebhook(endpoint, secret, eventTypes, known); err != nil {
+		return "", err
+	}
+	if err := egress.CheckURL(ctx, endpoint); err != nil {
+		if errors.Is(err, egress.ErrForbidden) {
+			return "", fieldErrorf("url", "webhook url must resolve to a public address")
+		}
+		return "", fieldErrorf("url", "webhook url host does not resolve")
+	}
+	if r.webhookSecrets == nil {
+		return "", fmt.Errorf("webhook secret key: %w", ErrNotConfigured)
+	}
+	return r.webhookSecrets.Seal(secret)
+}
+
+// SealWebhookSecrets seals the secrets of webhooks registered before
+// secrets were sealed, and returns how many it sealed.
+func (r *ProductRepository) SealWebhookSecrets(ctx context.Context) (int, error) {
+	if r.webhookSecrets == nil {
+		return 0, fmt.Errorf("webhook secret key: %w", ErrNotConfigured)
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (w:Webhook)
+			WHERE w.secret IS NOT NULL
+			RETURN w.id AS id, w.secret AS secret
+		`, nil)
+		if err != nil {
+			return nil, err
+		}
+		records, err := res.Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		sealed := 0
+		for _, record := range records {
+			id, _ := record.Values[0].(string)
+			secret, _ := record.Values[1].(string)
+			if secretbox.IsSealed(secret) {
+				continue
+			}
+			value, err := r.webhookSecrets.Seal(secret)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := tx.Run(ctx, `
+				MATCH (w:Webhook {id: $id})
+				SET w.secret = $secret
+			`, map[string]any{"id": id, "secret": value}); err != nil {
+				return nil, err
+			}
+			sealed++
+		}
+		return sealed, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.(int), nil
+}
+
+func (r *ProductRepository) CreateWebhook(ctx context.Context, endpoint, secret string, eventTypes []string) (string, error) {
+	sealed, err := r.prepareWebhook(ctx, endpoint, secret, eventTypes, events.IsKnownType)
+	if err != nil {
+		return "", err
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			CREATE (w:Webhook {
+				id: $id,
+				url: $url,
+				secret: $secret,
+				event_types: $event_types,
+				active: true,
+				created_at: $now,
+				updated_at: $now
+			})
+			RETURN w.id AS id
+		`, map[string]any{
+			"id":          newID(),
+			"url":         endpoint,
+			"secret":      sealed,
+			"event_types": eventTypes,
+			"now":         time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		id, _ := record.Get("id")
+		return id, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	id, _ := result.(string)
+	return id, nil
+}
+
+// EnqueueEvent fans an event out into one pending delivery per subscribed webhook.
+// Seller webhooks only receive events carrying their seller's id.
+func (r *ProductRepository) EnqueueEvent(ctx context.Context, eventType string, payload []byte) error {
+	var envelope struct {
+		SellerID string `json:"seller_id"`
+	}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return err
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			MATCH (w:Webhook {active: true})
+			WHERE ($event_type IN w.event_types OR $wildcard IN w.event_types)
+				AND coalesce(w.seller_id, '') IN ['', $seller_id]
+			CREATE (d:WebhookDelivery {
+				id: $event_id + ':' + w.id,
+				event_type: $event_type,
+				payload: $payload,
+				status: $status,
+				attempts: 0,
+				last_status_code: 0,
+				last_error: '',
+				created_at: $now,
+				updated_at: $now,
+				next_attempt_at: $now,
+				delivered_at: 0
+			})-[:FOR_WEBHOOK]->(w)
+		`, map[string]any{
+			"event_id":   newID(),
+			"event_type": eventType,
+			"wildcard":   events.Wildcard,
+			"seller_id":  envelope.SellerID,
+			"payload":    string(payload),
+			"status":     DeliveryPending,
+			"now":        time.Now().UnixMilli(),
+		})
+		return nil, err
+	})
+
+	return err
+}
+
+// CreateSellerWebhook registers a webhook for the seller's events of
+// eventTypes, which must be seller event types.
+func (r *ProductRepository) CreateSellerWebhook(ctx context.Context, sellerID, endpoint, secret string, eventTypes []string) (*SellerWebhook, error) {
+	if sellerID == "" {
+		return nil, fieldErrorf("seller_id", "seller id is required")
+	}
+	sealed, err := r.prepareWebhook(ctx, endpoint, secret, eventTypes, events.IsSellerType)
+	if err != nil {
+		return nil, err
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	w := SellerWebhook{
+		ID:         newID(),
+		SellerID:   sellerID,
+		URL:        endpoint,
+		EventTypes: eventTypes,
+		CreatedAt:  time.Now().UnixMilli(),
+	}
+	_, err = session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (sel:Seller {id: $seller_id})
+			OPTIONAL MATCH (w:Webhook {seller_id: $seller_id, active: true})
+			RETURN count(w) AS webhooks
+		`, map[string]any{"seller_id": sellerID})
+		if err != nil {
+			return nil, err
+		}
+		if !res.Next(ctx) {
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return nil, errSellerNotFound
+		}
+		if n := int(getInt64(res.Record().AsMap(), "webhooks")); n >= maxSellerWebhooks {
+			return nil, &LimitError{Field: "webhooks", Limit: maxSellerWebhooks, Actual: n + 1}
+		}
+
+		_, err = tx.Run(ctx, `
+			CREATE (w:Webhook {
+				id: $id,
+				seller_id: $seller_id,
+				url: $url,
+				secret: $secret,
+				event_types: $event_types,
+				active: true,
+				created_at: $now,
+				updated_at: $now
+			})
+		`, map[string]any{
+			"id":          w.ID,
+			"seller_id":   sellerID,
+			"url":         endpoint,
+			"secret":      sealed,
+			"event_types": eventTypes,
+			"now":         w.CreatedAt,
+		})
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// ListSellerWebhooks returns the seller's active webhooks, oldest first.
+func (r *ProductRepository) ListSellerWebhooks(ctx context.Context, sellerID string) ([]SellerWebhook, error) {
+	if sellerID == "" {
+		return nil, fieldErrorf("seller_id", "seller id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (w:Webhook {seller_id: $seller_id, active: true})
+			RETURN w
+			ORDER BY w.created_at, w.id
+		`, map[string]any{"seller_id": sellerID})
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks := []SellerWebhook{}
+		for res.Next(ctx) {
+			node, _ := res.Record().Values[0].(neo4j.Node)
+			webhooks = append(webhooks, SellerWebhook{
+				ID:         getString(node.Props, "id"),
+				SellerID:   getString(node.Props, "seller_id"),
+				URL:        getString(node.Props, "url"),
+				EventTypes: getStrings(node.Props, "event_types"),
+				CreatedAt:  getInt64(node.Props, "created_at"),
+			})
+		}
+		return webhooks, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]SellerWebhook), nil
+}
+
+// DeleteSellerWebhook deactivates the seller's webhook id. Its deliveries
+// are kept, and those already queued are still attempted.
+func (r *ProductRepository) DeleteSellerWebhook(ctx context.Context, sellerID, id string) error {
+	if sellerID == "" {
+		return fieldErrorf("seller_id", "seller id is required")
+	}
+	if id == "" {
+		return fieldErrorf("id", "webhook id is required")
+	}
+
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (w:Webhook {id: $id, seller_id: $seller_id, active: true})
+			SET w.active = false,
+				w.updated_at = $now
+			RETURN count(w) AS deleted
+		`, map[string]any{
+			"id":        id,
+			"seller_id": sellerID,
+			"now":       time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if deleted, _ := record.Values[0].(int64); deleted == 0 {
+			return nil, notFoundf("webhook not found")
+		}
+		return nil, nil
+	})
+
+	return err
+}
+
+// ClaimDueDeliveries leases up to limit pending deliveries whose next attempt is due.
+// The lease pushes next_attempt_at forward so a crashed dispatcher's work is retried.
+func (r *ProductRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]PendingDelivery, error) {
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	now := time.Now().UnixMilli()
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (d:WebhookDelivery {status: $status})-[:FOR_WEBHOOK]->(w:Webhook)
+			WHERE d.next_attempt_at <= $now
+			WITH d, w
+			ORDER BY d.next_attempt_at
+			LIMIT $limit
+			SET d.attempts = d.attempts + 1,
+				d.next_attempt_at = $now + $lease_ms,
+				d.updated_at = $now
+			RETURN d, w.id AS webhook_id, w.url AS url, w.secret AS secret
+		`, map[string]any{
+			"status":   DeliveryPending,
+			"now":      now,
+			"limit":    limit,
+			"lease_ms": lease.Milliseconds(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var claimed []PendingDelivery
+		for res.Next(ctx) {
+			record := res.Record()
+			dNode, _ := record.Values[0].(neo4j.Node)
+			webhookID, _ := record.Values[1].(string)
+			endpoint, _ := record.Values[2].(string)
+			secret, _ := record.Values[3].(string)
+
+			delivery := deliveryFromNode(dNode)
+			delivery.WebhookId = webhookID
+			claimed = append(claimed, PendingDelivery{
+				Delivery: delivery,
+				URL:      endpoint,
+				Secret:   secret,
+			})
+		}
+		return claimed, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]PendingDelivery), nil
+}
+
+func (r *ProductRepository) MarkDeliverySucceeded(ctx context.Context, id string, statusCode int) error {
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			MATCH (d:WebhookDelivery {id: $id})
+			SET d.status = $status,
+				d.last_status_code = $status_code,
+				d.last_error = '',
+				d.delivered_at = $now,
+				d.updated_at = $now
+		`, map[string]any{
+			"id":          id,
+			"status":      DeliveryDelivered,
+			"status_code": statusCode,
+			"now":         time.Now().UnixMilli(),
+		})
+		return nil, err
+	})
+
+	return err
+}
+
+// MarkDeliveryFailed records a failed attempt. When nextAttempt is zero the
+// delivery has exhausted its retries and is parked as failed.
+func (r *ProductRepository) MarkDeliveryFailed(ctx context.Context, id string, statusCode int, lastErr string, nextAttempt time.Time) error {
+	session := r.newSession(ctx, neo4j.AccessModeWrite)
+	defer r.closeSession(ctx, session)
+
+	status := DeliveryPending
+	var next int64
+	if nextAttempt.IsZero() {
+		status = DeliveryFailed
+	} else {
+		next = nextAttempt.UnixMilli()
+	}
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, `
+			MATCH (d:WebhookDelivery {id: $id})
+			SET d.status = $status,
+				d.last_status_code = $status_code,
+				d.last_error = $last_error,
+				d.next_attempt_at = $next_attempt_at,
+				d.updated_at = $now
+		`, map[string]any{
+			"id":              id,
+			"status":          status,
+			"status_code":     statusCode,
+			"last_error":      lastErr,
+			"next_attempt_at": next,
+			"now":             time.Now().UnixMilli(),
+		})
+		return nil, err
+	})
+
+	return err
+}
+
+// ListDeliveries returns deliveries newest first, resuming after the cursor.
+func (r *ProductRepository) ListDeliveries(ctx context.Context, webhookID, status string, after pagetoken.Cursor, limit int) ([]*pb.WebhookDelivery, error) {
+	session := r.newSession(ctx, neo4j.AccessModeRead)
+	defer r.closeSession(ctx, session)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, `
+			MATCH (d:WebhookDelivery)-[:FOR_WEBHOOK]->(w:Webhook)
+			WHERE ($webhook_id = '' OR w.id = $webhook_id)
+				AND ($status = '' OR d.status = $status)
+				AND ($after_id = ''
+					OR d.created_at < $after_time
+					OR (d.created_at = $after_time AND d.id < $after_id))
+			RETURN d, w.id AS webhook_id
+			ORDER BY d.created_at DESC, d.id DESC
+			LIMIT $limit
+		`, map[string]any{
+			"webhook_id": webhookID,
+			"status":     status,
+			"after_id":   after.LastID,
+			"after_time": after.LastTime,
+			"limit":      limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries := []*pb.WebhookDelivery{}
+		for res.Next(ctx) {
+			record := res.Record()
+			dNode, _ := record.Values[0].(neo4j.Node)
+			delivery := deliveryFromNode(dNode)
+			delivery.WebhookId, _ = record.Values[1].(string)
+			deliveries = append(deliveries, delivery)
+		}
+		return deliveries, res.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]*pb.WebhookDelivery), nil
+}
+
+func deliveryFromNode(node neo4j.Node) *pb.WebhookDelivery {
+	props := node.Props
+	return &pb.WebhookDelivery{
+		Id:             getString(props, "id"),
+		EventType:      getString(props, "event_type"),
+		Payload:        getString(props, "payload"),
+		Status:         getString(props, "status"),
+		Attempts:       int32(getInt64(props, "attempts")),
+		LastStatusCode: int32(getInt64(props, "last_status_code")),
+		LastError:      getString(props, "last_error"),
+		CreatedAt:      getInt64(props, "created_at"),
+		NextAttemptAt:  getInt64(props, "next_attempt_at"),
+		DeliveredAt:    getInt64(props, "delivered_at"),
+		UpdatedAt:      getInt64(props, "updated_at"),
+	}
+}
@@ -0,0 +1,70 @@
+// Package reservations returns the stock held by abandoned checkouts.
+package reservations
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	defaultInterval  = 30 * time.Second
+	defaultBatchSize = 100
+)
+
+// Store expires reservations past their TTL, returning their stock.
+type Store interface {
+	ExpireReservations(ctx context.Context, limit int) ([]string, error)
+}
+
+// Expirer periodically expires reservations that were neither committed
+// nor released in time. Each batch returns its stock and records the
+// reservation.expired events in one transaction, so replicas can run an
+// expirer each without returning stock twice.
+type Expirer struct {
+	store Store
+
+	Interval  time.Duration
+	BatchSize int
+}
+
+func NewExpirer(store Store) *Expirer {
+	return &Expirer{
+		store:     store,
+		Interval:  defaultInterval,
+		BatchSize: defaultBatchSize,
+	}
+}
+
+// Run expires reservations until ctx is cancelled.
+func (e *Expirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.Interval)
+	defer ticker.Stop()
+
+	for {
+		e.expireAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Expirer) expireAll(ctx context.Context) {
+	for ctx.Err() == nil {
+		ids, err := e.store.ExpireReservations(ctx, e.BatchSize)
+		if err != nil {
+			log.Printf("reservations: expire: %v", err)
+			return
+		}
+		if len(ids) > 0 {
+			log.Printf("reservations: expired %d reservations", len(ids))
+		}
+
+		if len(ids) < e.BatchSize {
+			return
+		}
+	}
+}
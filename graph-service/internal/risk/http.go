@@ -0,0 +1,81 @@
+package risk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const requestTimeout = 5 * time.Second
+
+// HTTP asks an external fraud service for a score. The order is POSTed as
+// JSON with snake_case field names, and the service answers
+// {"score": 0.42, "reasons": ["..."]}.
+type HTTP struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewHTTP scores orders with the service at url, sending token, if set, as
+// a bearer token.
+func NewHTTP(url, token string) *HTTP {
+	return &HTTP{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (h *HTTP) Score(ctx context.Context, o Order) (Assessment, error) {
+	body, err := json.Marshal(map[string]any{
+		"order_id":         o.ID,
+		"tenant_id":        o.TenantID,
+		"customer_id":      o.CustomerID,
+		"amount":           o.Amount,
+		"currency":         o.Currency,
+		"lines":            o.Lines,
+		"units":            o.Units,
+		"shipping_country": o.ShippingCountry,
+		"billing_country":  o.BillingCountry,
+		"client_country":   o.ClientCountry,
+	})
+	if err != nil {
+		return Assessment{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return Assessment{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return Assessment{}, fmt.Errorf("risk service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Assessment{}, fmt.Errorf("risk service: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return Assessment{}, fmt.Errorf("risk service: %d %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	var a struct {
+		Score   float64  `json:"score"`
+		Reasons []string `json:"reasons"`
+	}
+	if err := json.Unmarshal(respBody, &a); err != nil {
+		return Assessment{}, fmt.Errorf("risk service: %w", err)
+	}
+	return Assessment{Score: min(max(a.Score, 0), 1), Reasons: a.Reasons}, nil
+}
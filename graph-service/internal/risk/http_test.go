@@ -0,0 +1,75 @@
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestHTTP(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		status  int
+		body    string
+		want    Assessment
+		wantErr bool
+	}{
+		{name: "scored", status: 200, body: `{"score": 0.42, "reasons": ["new card"]}`, want: Assessment{Score: 0.42, Reasons: []string{"new card"}}},
+		{name: "with a token", token: "t0k", status: 200, body: `{"score": 0.1}`, want: Assessment{Score: 0.1}},
+		{name: "clamped high", status: 200, body: `{"score": 7}`, want: Assessment{Score: 1}},
+		{name: "clamped low", status: 200, body: `{"score": -1}`, want: Assessment{Score: 0}},
+		{name: "server error", status: 503, body: "down", wantErr: true},
+		{name: "not json", status: 200, body: "ok", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent map[string]any
+			var auth string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				auth = r.Header.Get("Authorization")
+				json.NewDecoder(r.Body).Decode(&sent)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			got, err := NewHTTP(srv.URL, tt.token).Score(context.Background(), Order{
+				ID:              "o1",
+				CustomerID:      "c1",
+				Amount:          1999,
+				Currency:        "USD",
+				ShippingCountry: "US",
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Score error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Score = %+v, want %+v", got, tt.want)
+			}
+
+			if want := map[string]any{"order_id": "o1", "customer_id": "c1", "amount": 1999.0, "shipping_country": "US"}; !contains(sent, want) {
+				t.Errorf("sent %v, want it to include %v", sent, want)
+			}
+			wantAuth := ""
+			if tt.token != "" {
+				wantAuth = "Bearer " + tt.token
+			}
+			if auth != wantAuth {
+				t.Errorf("Authorization = %q, want %q", auth, wantAuth)
+			}
+		})
+	}
+}
+
+func contains(got, want map[string]any) bool {
+	for k, v := range want {
+		if got[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+// Package risk scores orders for fraud before payment is taken and decides
+// whether they pass, are held for manual review or are declined.
+package risk
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+)
+
+// ErrDeclined reports an order the risk check refused.
+var ErrDeclined = errors.New("order declined by risk check")
+
+// Order is what scorers see of an order about to be paid for. Amounts are
+// in the currency's minor units, e.g. cents; countries are ISO 3166-1
+// alpha-2 codes and empty when unknown.
+type Order struct {
+	ID         string
+	TenantID   string
+	CustomerID string
+	Amount     int64
+	Currency   string
+	Lines      int
+	Units      int64
+	// ShippingCountry is where the goods go, BillingCountry the payment
+	// method's country and ClientCountry where the shopper connected from.
+	ShippingCountry string
+	BillingCountry  string
+	ClientCountry   string
+}
+
+// Assessment is a risk score between 0 (safe) and 1 (fraudulent) with the
+// signals that raised it.
+type Assessment struct {
+	Score   float64
+	Reasons []string
+}
+
+// Scorer assesses an order.
+type Scorer interface {
+	Score(ctx context.Context, o Order) (Assessment, error)
+}
+
+// ScorerFunc adapts a function to Scorer.
+type ScorerFunc func(ctx context.Context, o Order) (Assessment, error)
+
+func (f ScorerFunc) Score(ctx context.Context, o Order) (Assessment, error) {
+	return f(ctx, o)
+}
+
+// Multi adds up the scores of several scorers, capped at 1. A scorer that
+// fails is logged and skipped, so an unreachable external service never
+// blocks checkout.
+type Multi []Scorer
+
+func (m Multi) Score(ctx context.Context, o Order) (Assessment, error) {
+	var total Assessment
+	for _, scorer := range m {
+		a, err := scorer.Score(ctx, o)
+		if err != nil {
+			log.Printf("risk: score order %s: %v", o.ID, err)
+			continue
+		}
+		total.Score += a.Score
+		total.Reasons = append(total.Reasons, a.Reasons...)
+	}
+	total.Score = min(total.Score, 1)
+	return total, nil
+}
+
+// Region raises the score by Weight for each country on the order that
+// disagrees with where it ships.
+type Region struct {
+	Weight float64
+}
+
+func (r Region) Score(ctx context.Context, o Order) (Assessment, error) {
+	var a Assessment
+	if o.ShippingCountry == "" {
+		return a, nil
+	}
+	if o.BillingCountry != "" && !strings.EqualFold(o.BillingCountry, o.ShippingCountry) {
+		a.Score += r.Weight
+		a.Reasons = append(a.Reasons, "billing country differs from shipping country")
+	}
+	if o.ClientCountry != "" && !strings.EqualFold(o.ClientCountry, o.ShippingCountry) {
+		a.Score += r.Weight
+		a.Reasons = append(a.Reasons, "client country differs from shipping country")
+	}
+	return a, nil
+}
+
+// Decision is what happens to a scored order.
+type Decision int
+
+const (
+	Pass Decision = iota
+	Review
+	Decline
+)
+
+// Policy turns scores into decisions. A threshold of 0 disables its
+// decision.
+type Policy struct {
+	ReviewAt  float64
+	DeclineAt float64
+}
+
+// Decide returns the decision for a.
+func (p Policy) Decide(a Assessment) Decision {
+	switch {
+	case p.DeclineAt > 0 && a.Score >= p.DeclineAt:
+		return Decline
+	case p.ReviewAt > 0 && a.Score >= p.ReviewAt:
+		return Review
+	}
+	return Pass
+}
@@ -0,0 +1,100 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func fixed(score float64, reasons ...string) Scorer {
+	return ScorerFunc(func(context.Context, Order) (Assessment, error) {
+		return Assessment{Score: score, Reasons: reasons}, nil
+	})
+}
+
+var failing = ScorerFunc(func(context.Context, Order) (Assessment, error) {
+	return Assessment{Score: 1}, errors.New("unreachable")
+})
+
+func TestMulti(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Multi
+		want Assessment
+	}{
+		{"none", nil, Assessment{}},
+		{"adds up", Multi{fixed(0.2, "a"), fixed(0.3, "b")}, Assessment{Score: 0.5, Reasons: []string{"a", "b"}}},
+		{"capped at 1", Multi{fixed(0.7, "a"), fixed(0.6, "b")}, Assessment{Score: 1, Reasons: []string{"a", "b"}}},
+		{"failures skipped", Multi{fixed(0.2, "a"), failing, fixed(0.1)}, Assessment{Score: 0.3, Reasons: []string{"a"}}},
+		{"only failures", Multi{failing}, Assessment{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.Score(context.Background(), Order{ID: "o1"})
+			if err != nil {
+				t.Fatalf("Score = %v", err)
+			}
+			if !closeTo(got.Score, tt.want.Score) || !reflect.DeepEqual(got.Reasons, tt.want.Reasons) {
+				t.Errorf("Score = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegion(t *testing.T) {
+	tests := []struct {
+		name                      string
+		shipping, billing, client string
+		want                      float64
+	}{
+		{"all agree", "US", "US", "US", 0},
+		{"case differs", "us", "US", "Us", 0},
+		{"billing differs", "US", "GB", "US", 0.25},
+		{"client differs", "US", "US", "NG", 0.25},
+		{"both differ", "US", "GB", "NG", 0.5},
+		{"unknown billing and client", "US", "", "", 0},
+		{"unknown shipping", "", "GB", "NG", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := Region{Weight: 0.25}.Score(context.Background(), Order{
+				ShippingCountry: tt.shipping,
+				BillingCountry:  tt.billing,
+				ClientCountry:   tt.client,
+			})
+			if !closeTo(got.Score, tt.want) || len(got.Reasons) != int(tt.want/0.25) {
+				t.Errorf("Score = %+v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecide(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		score  float64
+		want   Decision
+	}{
+		{"below both", Policy{ReviewAt: 0.5, DeclineAt: 0.8}, 0.49, Pass},
+		{"at review", Policy{ReviewAt: 0.5, DeclineAt: 0.8}, 0.5, Review},
+		{"at decline", Policy{ReviewAt: 0.5, DeclineAt: 0.8}, 0.8, Decline},
+		{"review disabled", Policy{DeclineAt: 0.8}, 0.7, Pass},
+		{"decline disabled", Policy{ReviewAt: 0.5}, 1, Review},
+		{"both disabled", Policy{}, 1, Pass},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Decide(Assessment{Score: tt.score}); got != tt.want {
+				t.Errorf("Decide(%v) = %v, want %v", tt.score, got, tt.want)
+			}
+		})
+	}
+}
+
+// closeTo compares scores, which are sums of float weights.
+func closeTo(a, b float64) bool {
+	const epsilon = 1e-9
+	return a-b < epsilon && b-a < epsilon
+}
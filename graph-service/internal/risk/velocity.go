@@ -0,0 +1,43 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// History counts a customer's orders, whatever became of them, created
+// at or after since (unix milliseconds).
+type History interface {
+	CountRecentOrders(ctx context.Context, customerID string, since int64) (int, error)
+}
+
+// Velocity raises the score by Weight when the customer has placed at
+// least Limit other orders within Window, as card testing does. Guest
+// orders, without a customer, are not checked.
+type Velocity struct {
+	History History
+	Window  time.Duration
+	Limit   int
+	Weight  float64
+}
+
+func (v Velocity) Score(ctx context.Context, o Order) (Assessment, error) {
+	if o.CustomerID == "" || v.Limit <= 0 {
+		return Assessment{}, nil
+	}
+	since := time.Now().Add(-v.Window).UnixMilli()
+	count, err := v.History.CountRecentOrders(ctx, o.CustomerID, since)
+	if err != nil {
+		return Assessment{}, err
+	}
+	// The order being scored is already recorded
+	count--
+	if count < v.Limit {
+		return Assessment{}, nil
+	}
+	return Assessment{
+		Score:   v.Weight,
+		Reasons: []string{fmt.Sprintf("%d orders in the last %s", count, v.Window)},
+	}, nil
+}
@@ -0,0 +1,62 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type history struct {
+	count int
+	err   error
+	since int64
+	calls int
+}
+
+func (h *history) CountRecentOrders(ctx context.Context, customerID string, since int64) (int, error) {
+	h.calls++
+	h.since = since
+	return h.count, h.err
+}
+
+func TestVelocity(t *testing.T) {
+	tests := []struct {
+		name      string
+		customer  string
+		limit     int
+		count     int
+		err       error
+		want      float64
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "guest", customer: "", limit: 3, count: 10, want: 0},
+		{name: "disabled", customer: "c1", limit: 0, count: 10, want: 0},
+		{name: "first order", customer: "c1", limit: 3, count: 1, want: 0, wantCalls: 1},
+		{name: "under the limit", customer: "c1", limit: 3, count: 3, want: 0, wantCalls: 1},
+		{name: "at the limit", customer: "c1", limit: 3, count: 4, want: 0.4, wantCalls: 1},
+		{name: "history fails", customer: "c1", limit: 3, err: errors.New("down"), wantErr: true, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &history{count: tt.count, err: tt.err}
+			v := Velocity{History: h, Window: time.Hour, Limit: tt.limit, Weight: 0.4}
+
+			before := time.Now().Add(-time.Hour).UnixMilli()
+			got, err := v.Score(context.Background(), Order{ID: "o1", CustomerID: tt.customer})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Score error = %v, want error %v", err, tt.wantErr)
+			}
+			if got.Score != tt.want || (tt.want > 0) != (len(got.Reasons) > 0) {
+				t.Errorf("Score = %+v, want %v", got, tt.want)
+			}
+			if h.calls != tt.wantCalls {
+				t.Errorf("history asked %d times, want %d", h.calls, tt.wantCalls)
+			}
+			if h.calls > 0 && (h.since < before || h.since > before+1000) {
+				t.Errorf("history asked since %d, want an hour ago, about %d", h.since, before)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Package search turns free-text queries into fulltext index queries and
+// explains matches with highlighted fragments.
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+)
+
+const (
+	DefaultPreTag  = "<em>"
+	DefaultPostTag = "</em>"
+
+	// Characters of context kept either side of a match in a fragment.
+	fragmentContext = 40
+	maxFragments    = 3
+)
+
+var luceneOperators = map[string]bool{"and": true, "or": true, "not": true}
+
+// Terms splits free text into lower-cased search terms, dropping
+// punctuation, Lucene operators and duplicates.
+func Terms(text string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !isWordRune(r) }) {
+		word = strings.ToLower(word)
+		if luceneOperators[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// Query builds a fulltext index query matching any of terms. Terms contain
+// only letters and digits, so nothing needs escaping.
+func Query(terms []string) string {
+	return strings.Join(terms, " ")
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// HighlightProducts returns fragments of each product's indexed fields
+// (name, brand, description) with matched terms wrapped in pre and post.
+// Fields without a match are omitted.
+func HighlightProducts(products []*pb.Product, terms []string, pre, post string) []*pb.SearchHighlight {
+	if pre == "" && post == "" {
+		pre, post = DefaultPreTag, DefaultPostTag
+	}
+
+	var highlights []*pb.SearchHighlight
+	for _, p := range products {
+		for _, field := range []struct{ name, text string }{
+			{"name", p.Name},
+			{"brand", p.Brand},
+			{"description", p.Description},
+		} {
+			if fragments := Highlight(field.text, terms, pre, post); len(fragments) > 0 {
+				highlights = append(highlights, &pb.SearchHighlight{
+					ProductId: p.Id,
+					Field:     field.name,
+					Fragments: fragments,
+				})
+			}
+		}
+	}
+	return highlights
+}
+
+type span struct{ start, end int }
+
+// matchSpans returns the byte ranges of words in text that start with one
+// of terms, in order.
+func matchSpans(text string, terms []string) []span {
+	var spans []span
+	start := -1
+	check := func(end int) {
+		word := strings.ToLower(text[start:end])
+		for _, t := range terms {
+			if strings.HasPrefix(word, t) {
+				spans = append(spans, span{start, end})
+				return
+			}
+		}
+	}
+	for i, r := range text {
+		switch {
+		case isWordRune(r) && start < 0:
+			start = i
+		case !isWordRune(r) && start >= 0:
+			check(i)
+			start = -1
+		}
+	}
+	if start >= 0 {
+		check(len(text))
+	}
+	return spans
+}
+
+// Highlight returns up to three fragments of text around matches of terms,
+// with each match wrapped in pre and post. Fragments cut from the middle of
+// text are marked with "...". Fragments are HTML: text, which sellers
+// write, is always escaped, and only pre and post are inserted as given.
+func Highlight(text string, terms []string, pre, post string) []string {
+	spans := matchSpans(text, terms)
+
+	var fragments []string
+	prevEnd := 0
+	for i := 0; i < len(spans) && len(fragments) < maxFragments; {
+		start := max(fragmentStart(text, spans[i].start), prevEnd)
+		for start < spans[i].start && text[start] == ' ' {
+			start++
+		}
+		end := fragmentEnd(text, spans[i].end)
+		prevEnd = end
+
+		var b strings.Builder
+		if start > 0 {
+			b.WriteString("...")
+		}
+		pos := start
+		for ; i < len(spans) && spans[i].end <= end; i++ {
+			b.WriteString(html.EscapeString(text[pos:spans[i].start]))
+			b.WriteString(pre)
+			b.WriteString(html.EscapeString(text[spans[i].start:spans[i].end]))
+			b.WriteString(post)
+			pos = spans[i].end
+		}
+		b.WriteString(html.EscapeString(text[pos:end]))
+		if end < len(text) {
+			b.WriteString("...")
+		}
+		fragments = append(fragments, b.String())
+	}
+	return fragments
+}
+
+// fragmentStart backs up from a match by fragmentContext bytes, then moves
+// forward to a word boundary.
+func fragmentStart(text string, matchStart int) int {
+	i := matchStart - fragmentContext
+	if i <= 0 {
+		return 0
+	}
+	for i > 0 && !utf8.RuneStart(text[i]) {
+		i--
+	}
+	if j := strings.IndexFunc(text[i:matchStart], unicode.IsSpace); j >= 0 {
+		return i + j + 1
+	}
+	return matchStart
+}
+
+// fragmentEnd extends past a match by fragmentContext bytes, then backs up
+// to a word boundary.
+func fragmentEnd(text string, matchEnd int) int {
+	i := matchEnd + fragmentContext
+	if i >= len(text) {
+		return len(text)
+	}
+	for i < len(text) && !utf8.RuneStart(text[i]) {
+		i++
+	}
+	if j := strings.LastIndexFunc(text[matchEnd:i], unicode.IsSpace); j >= 0 {
+		return matchEnd + j
+	}
+	return matchEnd
+}
@@ -0,0 +1,60 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHighlightEscapes(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		pre, post string
+		want      []string
+	}{
+		{
+			name: "plain text",
+			text: "Red running shoes",
+			pre:  "<em>", post: "</em>",
+			want: []string{"Red <em>running</em> shoes"},
+		},
+		{
+			name: "markup in text",
+			text: `<script>alert(1)</script> running shoes`,
+			pre:  "<em>", post: "</em>",
+			want: []string{"&lt;script&gt;alert(1)&lt;/script&gt; <em>running</em> shoes"},
+		},
+		{
+			name: "markup in the match",
+			text: `running<img src=x onerror=alert(1)>`,
+			pre:  "<mark>", post: "</mark>",
+			want: []string{"<mark>running</mark>&lt;img src=x onerror=alert(1)&gt;"},
+		},
+		{
+			name: "escaped with non-HTML tags too",
+			text: `Tom & Jerry's "running" tee`,
+			pre:  "**", post: "**",
+			want: []string{"Tom &amp; Jerry&#39;s &#34;**running**&#34; tee"},
+		},
+		{
+			name: "tags inserted as given",
+			text: "running",
+			pre:  `<mark class="hit">`, post: "</mark>",
+			want: []string{`<mark class="hit">running</mark>`},
+		},
+		{
+			name: "no match",
+			text: "<b>walking</b>",
+			pre:  "<em>", post: "</em>",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Highlight(tt.text, []string{"running"}, tt.pre, tt.post)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Highlight(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package search
+
+import (
+	"strconv"
+	"strings"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+)
+
+// FuzzyQuery builds a fulltext index query matching any of terms within the
+// index's default edit distance.
+func FuzzyQuery(terms []string) string {
+	fuzzy := make([]string, len(terms))
+	for i, t := range terms {
+		fuzzy[i] = t + "~"
+	}
+	return strings.Join(fuzzy, " ")
+}
+
+// Boost restricts query to fields, each weighted by its entry in weights
+// (1 without one), so a match in a heavier field ranks above the same
+// match in a lighter one.
+func Boost(query string, fields []string, weights map[string]float64) string {
+	if query == "" || len(fields) == 0 {
+		return query
+	}
+	clauses := make([]string, 0, len(fields))
+	for _, field := range fields {
+		clause := field + ":(" + query + ")"
+		if weight, ok := weights[field]; ok && weight != 1 {
+			clause += "^" + strconv.FormatFloat(weight, 'g', -1, 64)
+		}
+		clauses = append(clauses, clause)
+	}
+	return strings.Join(clauses, " ")
+}
+
+// Suggest rewrites terms using the closest words found in products' indexed
+// fields. A term is replaced only by a word within two edits (one for terms
+// of four letters or fewer). It returns "" when no term changes.
+func Suggest(terms []string, products []*pb.Product) string {
+	vocabulary := map[string]bool{}
+	for _, p := range products {
+		for _, text := range []string{p.Name, p.Brand, p.Description} {
+			for _, word := range Terms(text) {
+				vocabulary[word] = true
+			}
+		}
+	}
+
+	changed := false
+	suggested := make([]string, len(terms))
+	for i, t := range terms {
+		suggested[i] = t
+		if vocabulary[t] {
+			continue
+		}
+
+		maxEdits := 2
+		if len([]rune(t)) <= 4 {
+			maxEdits = 1
+		}
+		best, bestDist := "", maxEdits+1
+		for word := range vocabulary {
+			d := editDistance(t, word)
+			if d < bestDist || (d == bestDist && word < best) {
+				best, bestDist = word, d
+			}
+		}
+		if best != "" {
+			suggested[i] = best
+			changed = true
+		}
+	}
+
+	if !changed {
+		return ""
+	}
+	return strings.Join(suggested, " ")
+}
+
+// editDistance is the optimal string alignment distance between a and b in
+// runes: Levenshtein plus adjacent transpositions, as Lucene's fuzzy
+// queries count them.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
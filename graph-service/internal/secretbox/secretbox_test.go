@@ -0,0 +1,101 @@
+package secretbox
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testKey = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+
+func TestNew(t *testing.T) {
+	raw := []byte("0123456789abcdef0123456789abcdef")
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"standard", base64.StdEncoding.EncodeToString(raw), false},
+		{"unpadded", base64.RawStdEncoding.EncodeToString(raw), false},
+		{"url alphabet", base64.URLEncoding.EncodeToString(raw), false},
+		{"surrounding space", " " + base64.StdEncoding.EncodeToString(raw) + "\n", false},
+		{"too short", base64.StdEncoding.EncodeToString(raw[:16]), true},
+		{"not base64", "not a key!", true},
+		{"empty", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New(%q) error = %v, want error %v", tt.key, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	box, err := New(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, secret := range []string{"whsec_123", "", strings.Repeat("x", 1024)} {
+		sealed, err := box.Seal(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsSealed(sealed) {
+			t.Errorf("Seal(%q) = %q, not marked sealed", secret, sealed)
+		}
+		if secret != "" && strings.Contains(sealed, secret) {
+			t.Errorf("Seal(%q) = %q, contains the secret", secret, sealed)
+		}
+		opened, err := box.Open(sealed)
+		if err != nil || opened != secret {
+			t.Errorf("Open(Seal(%q)) = %q, %v", secret, opened, err)
+		}
+	}
+
+	a, _ := box.Seal("whsec_123")
+	b, _ := box.Seal("whsec_123")
+	if a == b {
+		t.Errorf("Seal gave the same value twice: %q", a)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	box, _ := New(testKey)
+	other, _ := New(base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210")))
+	sealed, _ := box.Seal("whsec_123")
+	encoded := strings.TrimPrefix(sealed, prefix)
+	flipped := []byte(encoded)
+	if mid := len(flipped) / 2; flipped[mid] == 'A' {
+		flipped[mid] = 'B'
+	} else {
+		flipped[mid] = 'A'
+	}
+
+	tests := []struct {
+		name    string
+		box     *Box
+		value   string
+		want    string
+		wantErr error
+	}{
+		{"sealed", box, sealed, "whsec_123", nil},
+		{"legacy plaintext", box, "whsec_legacy", "whsec_legacy", nil},
+		{"other key", other, sealed, "", ErrMalformed},
+		{"tampered", box, prefix + string(flipped), "", ErrMalformed},
+		{"truncated", box, prefix + encoded[:8], "", ErrMalformed},
+		{"not base64", box, prefix + "!!!", "", ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.box.Open(tt.value)
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Open(%q) = %q, %v, want %q, %v", tt.value, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
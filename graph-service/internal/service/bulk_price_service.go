@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+// BulkUpdatePrices is audited like single-product updates, dry runs
+// included, so previews of large changes leave a trace too.
+func (s *V2Service) BulkUpdatePrices(ctx context.Context, req *pbv2.BulkUpdatePricesRequest) (*pbv2.BulkUpdatePricesResponse, error) {
+
+	if req.Filter == nil {
+		return nil, &repository.FieldError{Field: "filter", Description: "a filter is required; an empty one matches every product"}
+	}
+	var adj repository.PriceAdjustment
+	switch kind := req.GetAdjustment().GetKind().(type) {
+	case *pbv2.PriceAdjustment_Percent:
+		adj.Percent = kind.Percent
+	case *pbv2.PriceAdjustment_Amount:
+		adj.Amount = kind.Amount
+	}
+
+	f := req.Filter
+	update, err := s.v1.repo.BulkUpdatePrices(ctx, repository.ProductFilter{
+		Catalog:  catalogFilter(f),
+		Brands:   f.Brands,
+		Colors:   f.Colors,
+		Sizes:    f.Sizes,
+		MinPrice: f.MinPrice,
+		MaxPrice: f.MaxPrice,
+		InStock:  f.InStock,
+	}, adj, int(req.BatchSize), req.DryRun)
+	if update != nil {
+		log.Printf("audit: kind=bulk_price principal=%s filter=%q percent=%g amount=%g dry_run=%t affected=%d batches=%d failed=%t",
+			requestedBy(ctx), f.String(), adj.Percent, adj.Amount, req.DryRun, update.Affected, update.Batches, err != nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	preview := make([]*pbv2.PriceChange, 0, len(update.Preview))
+	for _, c := range update.Preview {
+		preview = append(preview, &pbv2.PriceChange{ProductId: c.ProductID, Name: c.Name, OldPrice: c.OldPrice, NewPrice: c.NewPrice})
+	}
+	return &pbv2.BulkUpdatePricesResponse{
+		Affected: int32(update.Affected),
+		Batches:  int32(update.Batches),
+		Preview:  preview,
+	}, nil
+}
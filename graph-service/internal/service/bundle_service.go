@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+func (s *ProductService) CreateBundle(ctx context.Context, req *pb.CreateBundleRequest) (*pb.CreateBundleResponse, error) {
+
+	if err := s.checkProducts(ctx, nil, componentSKUs(req.Bundle)...); err != nil {
+		return nil, err
+	}
+	id, err := s.repo.CreateBundle(ctx, req.Bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateBundleResponse{
+		Id: id,
+	}, nil
+}
+
+func (s *ProductService) GetBundle(ctx context.Context, req *pb.GetBundleRequest) (*pb.GetBundleResponse, error) {
+
+	bundle, err := s.repo.GetBundle(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.CheckTenant(ctx, "bundle", bundle.Id, bundle.TenantId); err != nil {
+		return nil, err
+	}
+
+	return &pb.GetBundleResponse{
+		Bundle: bundle,
+	}, nil
+}
+
+func (s *ProductService) UpdateBundle(ctx context.Context, req *pb.UpdateBundleRequest) (*pb.UpdateBundleResponse, error) {
+
+	if err := s.checkOwner(ctx, repository.BundleLabel, req.Bundle.GetId()); err != nil {
+		return nil, err
+	}
+	if err := s.checkProducts(ctx, nil, componentSKUs(req.Bundle)...); err != nil {
+		return nil, err
+	}
+	err := s.repo.UpdateBundle(ctx, req.Bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UpdateBundleResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *ProductService) DeleteBundle(ctx context.Context, req *pb.DeleteBundleRequest) (*pb.DeleteBundleResponse, error) {
+
+	if err := s.checkOwner(ctx, repository.BundleLabel, req.Id); err != nil {
+		return nil, err
+	}
+	err := s.repo.DeleteBundle(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteBundleResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *ProductService) OrderBundle(ctx context.Context, req *pb.OrderBundleRequest) (*pb.OrderBundleResponse, error) {
+
+	if err := s.checkOwner(ctx, repository.BundleLabel, req.BundleId); err != nil {
+		return nil, err
+	}
+	err := s.repo.OrderBundle(ctx, req.BundleId, req.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.OrderBundleResponse{
+		Success: true,
+	}, nil
+}
+
+func componentSKUs(b *pb.Bundle) []string {
+	skus := make([]string, 0, len(b.GetComponents()))
+	for _, c := range b.GetComponents() {
+		skus = append(skus, c.Sku)
+	}
+	return skus
+}
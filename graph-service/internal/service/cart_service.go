@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/payments"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/tax"
+)
+
+func (s *ProductService) PriceCart(ctx context.Context, req *pb.PriceCartRequest) (*pb.PriceCartResponse, error) {
+
+	currency, err := orderCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &pb.Order{Currency: currency}
+	for _, line := range req.Lines {
+		order.Lines = append(order.Lines, &pb.OrderLine{Sku: line.GetSku(), Quantity: line.GetQuantity()})
+	}
+	if err := s.repo.PriceOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	var coupon *pb.Coupon
+	if req.CouponCode != "" {
+		coupon, err = s.repo.ApplyCoupon(ctx, req.CouponCode, req.CustomerId, order)
+		if err != nil {
+			return nil, err
+		}
+		order.Total = float64(payments.MinorUnits(order.Subtotal)-orderDiscount(order)) / 100
+	}
+	if err := s.addTax(ctx, order, req.Destination); err != nil {
+		return nil, err
+	}
+
+	return &pb.PriceCartResponse{
+		Lines:    order.Lines,
+		Currency: order.Currency,
+		Subtotal: order.Subtotal,
+		Tax:      order.Tax,
+		Total:    order.Total,
+		Discount: float64(orderDiscount(order)) / 100,
+		Coupon:   coupon,
+	}, nil
+}
+
+// orderDiscount returns the discount on the order's lines in minor units.
+func orderDiscount(o *pb.Order) int64 {
+	var discount int64
+	for _, line := range o.Lines {
+		discount += payments.MinorUnits(line.Discount)
+	}
+	return discount
+}
+
+// orderCurrency normalizes a requested currency, defaulting to USD.
+func orderCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(currency)
+	if currency == "" {
+		currency = defaultCurrency
+	}
+	if !currencyPattern.MatchString(currency) {
+		return "", &repository.FieldError{Field: "currency", Description: "currency must be an ISO 4217 code"}
+	}
+	return currency, nil
+}
+
+// addTax sets the tax on the priced order's lines from the tax provider,
+// and its tax and total to match. Lines are taxed after their discount.
+// Without a provider tax stays zero.
+func (s *ProductService) addTax(ctx context.Context, o *pb.Order, dest *pb.ShippingAddress) error {
+	if s.tax == nil {
+		return nil
+	}
+
+	req := tax.Request{
+		Currency: o.Currency,
+		Destination: tax.Address{
+			Country:    strings.ToUpper(dest.GetCountry()),
+			Region:     dest.GetRegion(),
+			PostalCode: dest.GetPostalCode(),
+			City:       dest.GetCity(),
+		},
+	}
+	for _, line := range o.Lines {
+		req.Lines = append(req.Lines, tax.Line{
+			ID:       line.Sku,
+			Class:    line.TaxClass,
+			Quantity: int64(line.Quantity),
+			Amount:   payments.MinorUnits(line.Total) - payments.MinorUnits(line.Discount),
+		})
+	}
+
+	taxes, err := s.tax.Calculate(ctx, req)
+	if err != nil {
+		return fmt.Errorf("calculate tax: %w", err)
+	}
+	if len(taxes) != len(o.Lines) {
+		return fmt.Errorf("calculate tax: got %d lines back for %d", len(taxes), len(o.Lines))
+	}
+
+	var total int64
+	for i, line := range o.Lines {
+		line.Tax = float64(taxes[i].Amount) / 100
+		line.TaxRate = taxes[i].Rate
+		total += taxes[i].Amount
+	}
+	o.Tax = float64(total) / 100
+	o.Total = float64(payments.MinorUnits(o.Subtotal)-orderDiscount(o)+total) / 100
+	return nil
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+)
+
+func (s *ProductService) GetCategoryTree(ctx context.Context, req *pb.GetCategoryTreeRequest) (*pb.GetCategoryTreeResponse, error) {
+
+	categories, err := s.repo.GetCategoryTree(ctx, req.TenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetCategoryTreeResponse{
+		Categories: categories,
+	}, nil
+}
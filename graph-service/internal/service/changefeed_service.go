@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/events"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+// defaultSyncSettle is how old changes are before SyncChanges serves them.
+// Events are stamped before their transaction commits, so one can commit
+// after a later one has been served; waiting out the write timeouts keeps
+// a cursor from moving past it.
+const defaultSyncSettle = 5 * time.Second
+
+// SyncChanges pages through the outbox for product changes. The cursor is
+// always returned, so a caught up client keeps its place.
+func (s *V2Service) SyncChanges(ctx context.Context, req *pbv2.SyncChangesRequest) (*pbv2.SyncChangesResponse, error) {
+
+	const scope = "changes"
+
+	cursor, err := s.v1.decodePageToken(req.Cursor, scope)
+	if err != nil {
+		return nil, err
+	}
+	if req.Cursor == "" {
+		cursor.LastTime = req.Since
+	}
+
+	limit := pageSize(req.PageSize)
+	until := time.Now().Add(-s.v1.syncSettle).UnixMilli()
+
+	changes, err := s.v1.repo.ListProductChanges(ctx, cursor, until, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pbv2.SyncChangesResponse{}
+	if len(changes) > limit {
+		changes = changes[:limit]
+		resp.HasMore = true
+	}
+	if len(changes) > 0 {
+		last := changes[len(changes)-1]
+		cursor.LastID, cursor.LastTime = last.EventID, last.OccurredAt
+	}
+	resp.NextCursor = s.v1.tokens.EncodeKept(cursor)
+
+	// A product changed more than once is sent once, at its last change,
+	// and as created if the client can't have seen it yet
+	created := map[string]bool{}
+	lastChange := map[string]int{}
+	for i, c := range changes {
+		if c.Type == events.ProductCreated {
+			created[c.ProductID] = true
+		}
+		lastChange[c.ProductID] = i
+	}
+	var latest []repository.ProductChange
+	var products []*pb.Product
+	for i, c := range changes {
+		if lastChange[c.ProductID] != i {
+			continue
+		}
+		latest = append(latest, c)
+		if c.Product != nil {
+			products = append(products, c.Product)
+		}
+	}
+
+	if err := s.v1.repo.LocalizeProducts(ctx, products, req.Locale); err != nil {
+		return nil, err
+	}
+
+	resp.Changes = make([]*pbv2.ProductChange, 0, len(latest))
+	for _, c := range latest {
+		change := &pbv2.ProductChange{ProductId: c.ProductID, ChangedAt: c.OccurredAt}
+		switch {
+		case c.Product == nil:
+			change.Type = pbv2.ProductChangeType_PRODUCT_CHANGE_DELETED
+		case created[c.ProductID]:
+			change.Type = pbv2.ProductChangeType_PRODUCT_CHANGE_CREATED
+			change.Product = productToV2(c.Product)
+		default:
+			change.Type = pbv2.ProductChangeType_PRODUCT_CHANGE_UPDATED
+			change.Product = productToV2(c.Product)
+		}
+		resp.Changes = append(resp.Changes, change)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/idgen"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"google.golang.org/protobuf/proto"
+)
+
+func (s *ProductService) CloneProduct(ctx context.Context, req *pb.CloneProductRequest) (*pb.CloneProductResponse, error) {
+
+	if req.ProductId == "" {
+		return nil, &repository.FieldError{Field: "product_id", Description: "product id is required"}
+	}
+	source, err := s.catalog.GetProduct(ctx, req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.CheckTenant(ctx, "product", source.Id, source.TenantId); err != nil {
+		return nil, err
+	}
+
+	clone, err := cloneProduct(source, req, s.ids)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.catalog.CreateProduct(ctx, clone); err != nil {
+		return nil, err
+	}
+
+	return &pb.CloneProductResponse{
+		Product: clone,
+	}, nil
+}
+
+// cloneProduct returns the product req makes of source, ready to create,
+// with an id from ids unless req names one.
+func cloneProduct(source *pb.Product, req *pb.CloneProductRequest, ids idgen.Generator) (*pb.Product, error) {
+	clone := proto.Clone(source).(*pb.Product)
+	clone.Id = req.Id
+	if clone.Id == "" {
+		clone.Id = ids.NewID()
+	}
+	if req.Name != "" {
+		clone.Name = req.Name
+	}
+	clone.Slug = req.Slug
+	clone.Gtin, clone.ExternalId = "", ""
+	clone.CreatedAt, clone.UpdatedAt = 0, 0
+
+	// The end of the id, since generated ids begin with the time
+	suffix := clone.Id[max(0, len(clone.Id)-8):]
+	mapped := 0
+	for _, size := range clone.Sizes {
+		if sku, ok := req.Skus[size.Sku]; ok {
+			size.Sku = sku
+			mapped++
+		} else {
+			size.Sku += "-" + suffix
+		}
+		if req.ZeroStock {
+			size.Stock, size.InStock = 0, false
+		}
+		size.CreatedAt, size.UpdatedAt = 0, 0
+	}
+	if mapped < len(req.Skus) {
+		return nil, &repository.FieldError{
+			Field:       "skus",
+			Description: fmt.Sprintf("maps SKUs product %s doesn't have", source.Id),
+		}
+	}
+	return clone, nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+func (s *ProductService) CreateCollection(ctx context.Context, req *pb.CreateCollectionRequest) (*pb.CreateCollectionResponse, error) {
+
+	id, err := s.repo.CreateCollection(ctx, req.Collection)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateCollectionResponse{
+		Id: id,
+	}, nil
+}
+
+func (s *ProductService) GetCollection(ctx context.Context, req *pb.GetCollectionRequest) (*pb.GetCollectionResponse, error) {
+
+	collection, products, err := s.repo.GetCollection(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.CheckTenant(ctx, "collection", collection.Id, collection.TenantId); err != nil {
+		return nil, err
+	}
+
+	return &pb.GetCollectionResponse{
+		Collection: collection,
+		Products:   products,
+	}, nil
+}
+
+func (s *ProductService) UpdateCollection(ctx context.Context, req *pb.UpdateCollectionRequest) (*pb.UpdateCollectionResponse, error) {
+
+	if err := s.checkOwner(ctx, repository.CollectionLabel, req.Collection.GetId()); err != nil {
+		return nil, err
+	}
+	err := s.repo.UpdateCollection(ctx, req.Collection)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UpdateCollectionResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *ProductService) DeleteCollection(ctx context.Context, req *pb.DeleteCollectionRequest) (*pb.DeleteCollectionResponse, error) {
+
+	if err := s.checkOwner(ctx, repository.CollectionLabel, req.Id); err != nil {
+		return nil, err
+	}
+	err := s.repo.DeleteCollection(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteCollectionResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *ProductService) SetCollectionProducts(ctx context.Context, req *pb.SetCollectionProductsRequest) (*pb.SetCollectionProductsResponse, error) {
+
+	if err := s.checkOwner(ctx, repository.CollectionLabel, req.CollectionId); err != nil {
+		return nil, err
+	}
+	if err := s.checkProducts(ctx, req.ProductIds); err != nil {
+		return nil, err
+	}
+	err := s.repo.SetCollectionProducts(ctx, req.CollectionId, req.ProductIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SetCollectionProductsResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *ProductService) AddProductToCollection(ctx context.Context, req *pb.AddProductToCollectionRequest) (*pb.AddProductToCollectionResponse, error) {
+
+	if err := s.checkOwner(ctx, repository.CollectionLabel, req.CollectionId); err != nil {
+		return nil, err
+	}
+	if err := s.checkProducts(ctx, []string{req.ProductId}); err != nil {
+		return nil, err
+	}
+	err := s.repo.AddProductToCollection(ctx, req.CollectionId, req.ProductId, req.Position)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.AddProductToCollectionResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *ProductService) RemoveProductFromCollection(ctx context.Context, req *pb.RemoveProductFromCollectionRequest) (*pb.RemoveProductFromCollectionResponse, error) {
+
+	if err := s.checkOwner(ctx, repository.CollectionLabel, req.CollectionId); err != nil {
+		return nil, err
+	}
+	err := s.repo.RemoveProductFromCollection(ctx, req.CollectionId, req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RemoveProductFromCollectionResponse{
+		Success: true,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/payments"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+func (s *ProductService) CreateCoupon(ctx context.Context, req *pb.CreateCouponRequest) (*pb.CreateCouponResponse, error) {
+
+	if req.Coupon == nil {
+		return nil, &repository.FieldError{Field: "coupon", Description: "coupon is required"}
+	}
+	err := s.repo.CreateCoupon(ctx, req.Coupon)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateCouponResponse{
+		Coupon: req.Coupon,
+	}, nil
+}
+
+// ValidateCoupon prices the cart with the coupon. Coupons that don't apply,
+// including unknown codes, come back invalid with the reason rather than
+// as errors.
+func (s *ProductService) ValidateCoupon(ctx context.Context, req *pb.ValidateCouponRequest) (*pb.ValidateCouponResponse, error) {
+
+	return inReadUnit(ctx, s.repo, func(ctx context.Context) (*pb.ValidateCouponResponse, error) {
+		return s.validateCoupon(ctx, req)
+	})
+}
+
+func (s *ProductService) validateCoupon(ctx context.Context, req *pb.ValidateCouponRequest) (*pb.ValidateCouponResponse, error) {
+	order := &pb.Order{}
+	for _, line := range req.Lines {
+		order.Lines = append(order.Lines, &pb.OrderLine{Sku: line.GetSku(), Quantity: line.GetQuantity()})
+	}
+	if err := s.repo.PriceOrder(ctx, order); err != nil {
+		return nil, err
+	}
+
+	coupon, err := s.repo.ApplyCoupon(ctx, req.Code, req.CustomerId, order)
+	if errors.Is(err, repository.ErrCouponNotApplicable) || errors.Is(err, repository.ErrNotFound) {
+		return &pb.ValidateCouponResponse{
+			Reason: err.Error(),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ValidateCouponResponse{
+		Valid:    true,
+		Coupon:   coupon,
+		Discount: float64(orderDiscount(order)) / 100,
+	}
+	for _, line := range order.Lines {
+		if payments.MinorUnits(line.Discount) > 0 {
+			resp.EligibleSkus = append(resp.EligibleSkus, line.Sku)
+		}
+	}
+	return resp, nil
+}
+
+func (s *ProductService) RedeemCoupon(ctx context.Context, req *pb.RedeemCouponRequest) (*pb.RedeemCouponResponse, error) {
+
+	if err := s.checkShared(ctx, repository.CouponLabel, req.Code); err != nil {
+		return nil, err
+	}
+	coupon, err := s.repo.RedeemCoupon(ctx, req.Code, req.CustomerId, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RedeemCouponResponse{
+		Coupon: coupon,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+)
+
+func (s *ProductService) FindDuplicateProducts(ctx context.Context, req *pb.FindDuplicateProductsRequest) (*pb.FindDuplicateProductsResponse, error) {
+
+	clusters, err := s.repo.FindDuplicateProducts(ctx, req.TenantId, req.MinSimilarity, int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.FindDuplicateProductsResponse{
+		Clusters: clusters,
+	}, nil
+}
+
+// MergeProducts deletes the duplicates, so merges are audited.
+func (s *ProductService) MergeProducts(ctx context.Context, req *pb.MergeProductsRequest) (*pb.MergeProductsResponse, error) {
+
+	if err := s.checkProducts(ctx, append([]string{req.SurvivorId}, req.DuplicateIds...)); err != nil {
+		return nil, err
+	}
+	product, err := s.repo.MergeProducts(ctx, req.SurvivorId, req.DuplicateIds)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("audit: product=%s kind=merge principal=%s merged=%s", product.Id, requestedBy(ctx), strings.Join(req.DuplicateIds, ","))
+
+	return &pb.MergeProductsResponse{
+		Product: product,
+	}, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+)
+
+func (s *ProductService) ExportStockHistory(ctx context.Context, req *pb.ExportStockHistoryRequest) (*pb.ExportStockHistoryResponse, error) {
+
+	scope := "stock_history:" + req.TenantId + ":" + strconv.Itoa(int(req.Days))
+
+	cursor, err := s.decodePageToken(req.PageToken, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageSize(req.Limit)
+
+	skus, err := s.repo.ExportStockHistory(ctx, req.TenantId, int(req.Days), cursor.LastID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ExportStockHistoryResponse{}
+	if len(skus) > limit {
+		skus = skus[:limit]
+		resp.NextPageToken = s.tokens.Encode(pagetoken.Cursor{
+			Scope:  scope,
+			LastID: skus[limit-1].Sku,
+		})
+	}
+	resp.Skus = skus
+
+	return resp, nil
+}
+
+func (s *ProductService) ImportForecast(ctx context.Context, req *pb.ImportForecastRequest) (*pb.ImportForecastResponse, error) {
+
+	imported, unknown, err := s.repo.ImportForecast(ctx, req.Model, req.Forecasts)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("audit: forecast model=%s principal=%s imported=%d unknown=%d", req.Model, requestedBy(ctx), imported, len(unknown))
+
+	return &pb.ImportForecastResponse{
+		Imported:    int32(imported),
+		UnknownSkus: unknown,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+// SnapshotInventory carries the first page's taken_at in its page tokens,
+// so every page of a snapshot reports the same time.
+func (s *V2Service) SnapshotInventory(ctx context.Context, req *pbv2.SnapshotInventoryRequest) (*pbv2.SnapshotInventoryResponse, error) {
+
+	scope := "inventory:" + req.TenantId
+
+	cursor, err := s.v1.decodePageToken(req.PageToken, scope)
+	if err != nil {
+		return nil, err
+	}
+	takenAt := cursor.LastTime
+	if req.PageToken == "" {
+		takenAt = time.Now().UnixMilli()
+	}
+
+	limit := pageSize(req.Limit)
+
+	levels, err := s.v1.repo.SnapshotInventory(ctx, req.TenantId, cursor.LastID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pbv2.SnapshotInventoryResponse{TakenAt: takenAt}
+	if len(levels) > limit {
+		levels = levels[:limit]
+		resp.NextPageToken = s.v1.tokens.Encode(pagetoken.Cursor{
+			Scope:    scope,
+			LastID:   levels[limit-1].SKU,
+			LastTime: takenAt,
+		})
+	}
+	resp.Skus = make([]*pbv2.SkuStock, 0, len(levels))
+	for _, l := range levels {
+		resp.Skus = append(resp.Skus, &pbv2.SkuStock{
+			Sku:       l.SKU,
+			ProductId: l.ProductID,
+			Stock:     l.Stock,
+			Held:      l.Held,
+			UpdatedAt: l.UpdatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *V2Service) ReconcileInventory(ctx context.Context, req *pbv2.ReconcileInventoryRequest) (*pbv2.ReconcileInventoryResponse, error) {
+
+	counts := make([]repository.StockCount, 0, len(req.Counts))
+	for _, c := range req.Counts {
+		counts = append(counts, repository.StockCount{SKU: c.Sku, Counted: c.Counted})
+	}
+
+	rec, err := s.v1.repo.ReconcileInventory(ctx, counts, req.Reason, req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("audit: kind=stocktake principal=%s reason=%q dry_run=%t counted=%d discrepancies=%d adjusted=%d unknown=%d",
+		requestedBy(ctx), req.Reason, req.DryRun, len(counts), len(rec.Discrepancies), rec.Adjusted, len(rec.UnknownSKUs))
+
+	resp := &pbv2.ReconcileInventoryResponse{
+		Discrepancies: make([]*pbv2.StockDiscrepancy, 0, len(rec.Discrepancies)),
+		Adjusted:      int32(rec.Adjusted),
+		UnknownSkus:   rec.UnknownSKUs,
+	}
+	for _, d := range rec.Discrepancies {
+		resp.Discrepancies = append(resp.Discrepancies, &pbv2.StockDiscrepancy{
+			Sku:       d.SKU,
+			ProductId: d.ProductID,
+			Expected:  d.Expected,
+			Counted:   d.Counted,
+			Delta:     d.Counted - d.Expected,
+			Stock:     d.Stock,
+		})
+	}
+	return resp, nil
+}
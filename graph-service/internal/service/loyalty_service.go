@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+func (s *ProductService) GetLoyaltyBalance(ctx context.Context, req *pb.GetLoyaltyBalanceRequest) (*pb.GetLoyaltyBalanceResponse, error) {
+
+	if err := s.checkShared(ctx, repository.CustomerLabel, req.CustomerId); err != nil {
+		return nil, err
+	}
+	balance, err := s.repo.GetLoyaltyBalance(ctx, req.CustomerId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetLoyaltyBalanceResponse{
+		Balance: s.withNextTier(balance),
+	}, nil
+}
+
+func (s *ProductService) RedeemPoints(ctx context.Context, req *pb.RedeemPointsRequest) (*pb.RedeemPointsResponse, error) {
+
+	if err := s.checkShared(ctx, repository.CustomerLabel, req.CustomerId); err != nil {
+		return nil, err
+	}
+	balance, err := s.repo.RedeemPoints(ctx, req.CustomerId, req.Points, req.Reference)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RedeemPointsResponse{
+		Balance: s.withNextTier(balance),
+	}, nil
+}
+
+// awardPoints credits a placed order's points. The order stands even if
+// this fails; awarding is keyed on the order, so it can be retried.
+func (s *ProductService) awardPoints(ctx context.Context, orderID string) {
+	if s.loyalty == nil {
+		return
+	}
+	if _, err := s.repo.AwardOrderPoints(ctx, orderID, s.loyalty); err != nil {
+		log.Printf("loyalty: award points for order %s: %v", orderID, err)
+	}
+}
+
+func (s *ProductService) withNextTier(balance *pb.LoyaltyBalance) *pb.LoyaltyBalance {
+	if s.loyalty == nil {
+		return balance
+	}
+	_, next := s.loyalty.Tier(balance.LifetimePoints)
+	if next.Name != "" {
+		balance.NextTier = next.Name
+		balance.PointsToNextTier = next.Threshold - balance.LifetimePoints
+	}
+	return balance
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+)
+
+const defaultNewArrivalsDays = 30
+
+func (s *ProductService) GetNewArrivals(ctx context.Context, req *pb.GetNewArrivalsRequest) (*pb.GetNewArrivalsResponse, error) {
+
+	days := req.MaxAgeDays
+	if days <= 0 {
+		days = defaultNewArrivalsDays
+	}
+
+	scope := "new_arrivals:" + strconv.Itoa(int(days)) + ":" + filterKey(req.Filter)
+
+	cursor, err := s.decodePageToken(req.PageToken, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageSize(req.PageSize)
+
+	products, err := s.catalog.GetNewArrivals(ctx, req.Filter, time.Duration(days)*24*time.Hour, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetNewArrivalsResponse{}
+	if len(products) > limit {
+		products = products[:limit]
+		last := products[limit-1]
+		resp.NextPageToken = s.tokens.Encode(pagetoken.Cursor{
+			Scope:    scope,
+			LastID:   last.Id,
+			LastTime: last.CreatedAt,
+		})
+	}
+	resp.Products = products
+
+	return resp, nil
+}
+
+func (s *ProductService) GetDeals(ctx context.Context, req *pb.GetDealsRequest) (*pb.GetDealsResponse, error) {
+
+	scope := "deals:" + filterKey(req.Filter)
+
+	cursor, err := s.decodePageToken(req.PageToken, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageSize(req.PageSize)
+
+	deals, err := s.catalog.GetDeals(ctx, req.Filter, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetDealsResponse{}
+	if len(deals) > limit {
+		deals = deals[:limit]
+		last := deals[limit-1]
+		resp.NextPageToken = s.tokens.Encode(pagetoken.Cursor{
+			Scope:    scope,
+			LastID:   last.Product.Id,
+			LastRank: last.DiscountPercent,
+		})
+	}
+	resp.Deals = deals
+
+	return resp, nil
+}
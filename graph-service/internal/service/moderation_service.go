@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+// screeningModerator is who automated decisions are recorded as made by.
+const screeningModerator = "screening"
+
+// screenPost screens the new post id, of kind, with the configured
+// screener and returns its status. Flagged posts keep the categories for
+// the moderator; a screener that fails leaves the post to the moderator
+// too, so posting never waits on the moderation service being up.
+func (s *V2Service) screenPost(ctx context.Context, kind, id, text string) string {
+	if s.v1.screener == nil {
+		return repository.QAPending
+	}
+
+	verdict, err := s.v1.screener.Screen(ctx, text)
+	if err != nil {
+		log.Printf("moderation: screen %s %s: %v", kind, id, err)
+		return repository.QAPending
+	}
+	if verdict.Flagged {
+		if err := s.v1.repo.FlagPost(ctx, id, verdict.Categories); err != nil {
+			log.Printf("moderation: flag %s %s: %v", kind, id, err)
+		}
+		return repository.QAPending
+	}
+	if !s.v1.autoApprove {
+		return repository.QAPending
+	}
+
+	_, err = s.v1.repo.DecidePost(ctx, id, repository.ModerationDecision{
+		Approve:   true,
+		Reason:    "passed automated screening",
+		Moderator: screeningModerator,
+		Automated: true,
+	})
+	if err != nil {
+		log.Printf("moderation: approve %s %s: %v", kind, id, err)
+		return repository.QAPending
+	}
+	return repository.QAApproved
+}
+
+func (s *V2Service) ListPendingModeration(ctx context.Context, req *pbv2.ListPendingModerationRequest) (*pbv2.ListPendingModerationResponse, error) {
+
+	scope := "pending_moderation:" + req.TenantId
+
+	cursor, err := s.v1.decodePageToken(req.PageToken, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageSize(req.Limit)
+
+	posts, err := s.v1.repo.ListPendingModeration(ctx, req.TenantId, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pbv2.ListPendingModerationResponse{}
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[limit-1]
+		resp.NextPageToken = s.v1.tokens.Encode(pagetoken.Cursor{
+			Scope:    scope,
+			LastID:   last.ID,
+			LastTime: last.CreatedAt,
+		})
+	}
+	resp.Posts = make([]*pbv2.PendingPost, 0, len(posts))
+	for _, p := range posts {
+		post := &pbv2.PendingPost{
+			ProductId:         p.ProductID,
+			FlaggedCategories: p.FlaggedCategories,
+		}
+		if p.Kind == repository.QuestionPost {
+			post.Post = &pbv2.PendingPost_Question{Question: &pbv2.ProductQuestion{
+				Id:        p.ID,
+				ProductId: p.ProductID,
+				UserId:    p.AuthorID,
+				Text:      p.Text,
+				Status:    pbv2.QAStatus_QA_PENDING,
+				CreatedAt: p.CreatedAt,
+			}}
+		} else {
+			post.Post = &pbv2.PendingPost_Answer{Answer: &pbv2.ProductAnswer{
+				Id:         p.ID,
+				QuestionId: p.QuestionID,
+				UserId:     p.AuthorID,
+				Text:       p.Text,
+				Status:     pbv2.QAStatus_QA_PENDING,
+				CreatedAt:  p.CreatedAt,
+			}}
+		}
+		resp.Posts = append(resp.Posts, post)
+	}
+
+	return resp, nil
+}
+
+func (s *V2Service) ApprovePost(ctx context.Context, req *pbv2.ApprovePostRequest) (*pbv2.ApprovePostResponse, error) {
+
+	kind, err := s.decidePost(ctx, req.Id, true, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbv2.ApprovePostResponse{Kind: kind}, nil
+}
+
+func (s *V2Service) RejectPost(ctx context.Context, req *pbv2.RejectPostRequest) (*pbv2.RejectPostResponse, error) {
+
+	kind, err := s.decidePost(ctx, req.Id, false, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbv2.RejectPostResponse{Kind: kind}, nil
+}
+
+func (s *V2Service) decidePost(ctx context.Context, id string, approve bool, reason string) (string, error) {
+	caller := requestedBy(ctx)
+	kind, err := s.v1.repo.DecidePost(ctx, id, repository.ModerationDecision{
+		Approve:   approve,
+		Reason:    reason,
+		Moderator: caller,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("audit: kind=moderate_%s id=%s principal=%s approve=%t reason=%q", kind, id, caller, approve, reason)
+	return kind, nil
+}
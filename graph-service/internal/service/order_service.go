@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/payments"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/risk"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const defaultCurrency = "USD"
+
+// compensationTimeout bounds the calls that undo a failed order, which
+// run even when the request's own deadline is what failed it.
+const compensationTimeout = 10 * time.Second
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// IdempotencyKeyHeader optionally names a PlaceOrder attempt, so a retry
+// of an order placed without an id is not charged a second time.
+const IdempotencyKeyHeader = "x-idempotency-key"
+
+// PlaceOrder reserves the order's stock and adds tax, scores it for risk,
+// then authorizes and captures its payment, and only then commits the
+// stock. Each failure undoes the steps before it: the payment is voided or
+// refunded and the stock returned. Should the undo itself fail, the
+// reservation still expires on its own. A declined order never reaches
+// the payment provider; one flagged for review is authorized and held
+// without capture. A gift card pays first, within the same undo chain, and
+// the provider is charged only what the card leaves over.
+func (s *ProductService) PlaceOrder(ctx context.Context, req *pb.PlaceOrderRequest) (*pb.PlaceOrderResponse, error) {
+
+	order, _, err := s.placeOrder(ctx, req, "")
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PlaceOrderResponse{
+		Order: order,
+	}, nil
+}
+
+// placeOrder places the order as PlaceOrder describes. A couponCode, if
+// set, is redeemed as the order is created, before anything is charged,
+// and its discount comes off what is paid; a failed order gives the use
+// back. It returns the order and the coupon.
+func (s *ProductService) placeOrder(ctx context.Context, req *pb.PlaceOrderRequest, couponCode string) (*pb.Order, *pb.Coupon, error) {
+	if s.payments == nil && req.GiftCardCode == "" {
+		return nil, nil, status.Error(codes.Unimplemented, "no payment provider is configured")
+	}
+	if req.PaymentMethod == "" && req.GiftCardCode == "" {
+		return nil, nil, &repository.FieldError{Field: "payment_method", Description: "payment method is required"}
+	}
+	currency, err := orderCurrency(req.Currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	order := &pb.Order{
+		Id:         req.Id,
+		TenantId:   req.TenantId,
+		CustomerId: req.CustomerId,
+		Lines:      req.Lines,
+		Currency:   currency,
+	}
+	coupon, err := s.repo.CreateOrder(ctx, order, couponCode, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.tax != nil {
+		if err := s.addTax(ctx, order, req.Destination); err != nil {
+			return nil, nil, s.failOrder(ctx, order.Id, "", err, nil)
+		}
+		if err := s.repo.SetOrderTax(ctx, order); err != nil {
+			return nil, nil, s.failOrder(ctx, order.Id, "", err, nil)
+		}
+	}
+
+	decision, assessment, err := s.assessRisk(ctx, order, req)
+	if err != nil {
+		return nil, nil, s.failOrder(ctx, order.Id, "", err, nil)
+	}
+	if decision == risk.Decline {
+		return nil, nil, s.declineOrder(ctx, order.Id, assessment)
+	}
+
+	amount := payments.MinorUnits(order.Total)
+	if req.GiftCardCode != "" && amount > 0 {
+		_, redemption, err := s.repo.RedeemGiftCard(ctx, req.GiftCardCode, order.Id, order.Currency, order.Total, req.PaymentMethod != "")
+		if err != nil {
+			return nil, nil, s.failOrder(ctx, order.Id, "", err, nil)
+		}
+		order.GiftCardAmount = -redemption.Amount
+		amount -= payments.MinorUnits(order.GiftCardAmount)
+	}
+
+	var paymentID string
+	if amount > 0 {
+		switch {
+		case req.PaymentMethod == "":
+			err = &repository.FieldError{Field: "payment_method", Description: "the gift card doesn't cover the order, a payment method is required"}
+		case s.payments == nil:
+			err = status.Error(codes.Unimplemented, "no payment provider is configured")
+		default:
+			paymentID, err = s.payments.Authorize(ctx, payments.AuthorizeRequest{
+				OrderID:        order.Id,
+				Amount:         amount,
+				Currency:       order.Currency,
+				PaymentMethod:  req.PaymentMethod,
+				IdempotencyKey: authorizeKey(ctx, order),
+			})
+		}
+		if err != nil {
+			return nil, nil, s.failOrder(ctx, order.Id, "", err, nil)
+		}
+	}
+
+	if decision == risk.Review {
+		held, err := s.repo.HoldOrder(ctx, order.Id, paymentID, assessment.Score, assessment.Reasons)
+		if err != nil {
+			return nil, nil, s.failOrder(ctx, order.Id, paymentID, err, s.voidPayment(paymentID))
+		}
+		return held, coupon, nil
+	}
+
+	if paymentID != "" {
+		if err := s.payments.Capture(ctx, paymentID); err != nil {
+			return nil, nil, s.failOrder(ctx, order.Id, paymentID, err, s.voidPayment(paymentID))
+		}
+	}
+
+	if err := s.repo.ConfirmOrder(ctx, order.Id, paymentID); err != nil {
+		var settle func(context.Context) error
+		if paymentID != "" {
+			settle = func(ctx context.Context) error {
+				_, err := s.payments.Refund(ctx, payments.RefundRequest{
+					AuthorizationID: paymentID,
+					Amount:          amount,
+					IdempotencyKey:  "order-failed-" + order.Id,
+				})
+				return err
+			}
+		}
+		return nil, nil, s.failOrder(ctx, order.Id, paymentID, err, settle)
+	}
+
+	order.Status = pb.OrderStatus_PLACED
+	order.PaymentId = paymentID
+	s.awardPoints(ctx, order.Id)
+	s.settleOrder(ctx, order.Id)
+	return order, coupon, nil
+}
+
+// failOrder undoes a payment with settle, if any, and credits back any
+// gift card redemption, then returns the order's stock and coupon uses and
+// records cause.
+// It returns cause.
+func (s *ProductService) failOrder(ctx context.Context, orderID, paymentID string, cause error, settle func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), compensationTimeout)
+	defer cancel()
+
+	if settle != nil {
+		if err := settle(ctx); err != nil {
+			log.Printf("orders: undo payment %s for order %s: %v", paymentID, orderID, err)
+		}
+	}
+	s.reverseGiftCard(ctx, orderID)
+	if err := s.repo.FailOrder(ctx, orderID, paymentID, cause.Error()); err != nil {
+		log.Printf("orders: fail order %s: %v", orderID, err)
+	}
+	return cause
+}
+
+// authorizeKey is the idempotency key order's payment is authorized
+// under: the caller's IdempotencyKeyHeader, within the order's tenant, or
+// else the order id.
+func authorizeKey(ctx context.Context, order *pb.Order) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if keys := md.Get(IdempotencyKeyHeader); len(keys) > 0 && keys[0] != "" {
+			return "order-" + order.TenantId + "-" + keys[0]
+		}
+	}
+	return "order-" + order.Id
+}
+
+func (s *ProductService) voidPayment(paymentID string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		return s.payments.Void(ctx, paymentID)
+	}
+}
+
+// reverseGiftCard credits back whatever the order took from a gift card.
+// Orders paid without one are left alone.
+func (s *ProductService) reverseGiftCard(ctx context.Context, orderID string) {
+	if err := s.repo.RefundGiftCard(ctx, orderID, "order-reversed-"+orderID, 0); err != nil {
+		log.Printf("orders: reverse gift card redemption for order %s: %v", orderID, err)
+	}
+}
+
+func (s *ProductService) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.GetOrderResponse, error) {
+
+	order, err := s.repo.GetOrder(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.CheckTenant(ctx, "order", order.Id, order.TenantId); err != nil {
+		return nil, err
+	}
+
+	return &pb.GetOrderResponse{
+		Order: order,
+	}, nil
+}
+
+// PlaceOrder places the order as v1 does, redeeming the coupon as the
+// order is created so its discount comes off what is charged.
+func (s *V2Service) PlaceOrder(ctx context.Context, req *pbv2.PlaceOrderRequest) (*pbv2.PlaceOrderResponse, error) {
+
+	if req.CouponCode != "" {
+		if err := s.v1.checkShared(ctx, repository.CouponLabel, req.CouponCode); err != nil {
+			return nil, err
+		}
+	}
+	order, coupon, err := s.v1.placeOrder(ctx, placeOrderToV1(req), req.CouponCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbv2.PlaceOrderResponse{
+		Order: orderToV2(order, coupon),
+	}, nil
+}
+
+func placeOrderToV1(req *pbv2.PlaceOrderRequest) *pb.PlaceOrderRequest {
+	out := &pb.PlaceOrderRequest{
+		Id:             req.Id,
+		TenantId:       req.TenantId,
+		CustomerId:     req.CustomerId,
+		Currency:       req.Currency,
+		PaymentMethod:  req.PaymentMethod,
+		BillingCountry: req.BillingCountry,
+		ClientCountry:  req.ClientCountry,
+		GiftCardCode:   req.GiftCardCode,
+	}
+	for _, line := range req.Lines {
+		out.Lines = append(out.Lines, &pb.OrderLine{Sku: line.GetSku(), Quantity: line.GetQuantity()})
+	}
+	if dest := req.Destination; dest != nil {
+		out.Destination = &pb.ShippingAddress{
+			Country:    dest.Country,
+			Region:     dest.Region,
+			PostalCode: dest.PostalCode,
+			City:       dest.City,
+		}
+	}
+	return out
+}
+
+// orderToV2 converts the order, which the coupon, if any, was applied to.
+// Order statuses are numbered alike in both versions.
+func orderToV2(o *pb.Order, coupon *pb.Coupon) *pbv2.Order {
+	out := &pbv2.Order{
+		Id:             o.Id,
+		TenantId:       o.TenantId,
+		CustomerId:     o.CustomerId,
+		Currency:       o.Currency,
+		Subtotal:       o.Subtotal,
+		Total:          o.Total,
+		Status:         pbv2.OrderStatus(o.Status),
+		PaymentId:      o.PaymentId,
+		FailureReason:  o.FailureReason,
+		CreatedAt:      o.CreatedAt,
+		UpdatedAt:      o.UpdatedAt,
+		Tax:            o.Tax,
+		RiskScore:      o.RiskScore,
+		RiskReasons:    o.RiskReasons,
+		GiftCardAmount: o.GiftCardAmount,
+		Discount:       float64(orderDiscount(o)) / 100,
+		CouponCode:     coupon.GetCode(),
+	}
+	for _, line := range o.Lines {
+		out.Lines = append(out.Lines, &pbv2.OrderLine{
+			Sku:         line.Sku,
+			Quantity:    line.Quantity,
+			ProductId:   line.ProductId,
+			ProductName: line.ProductName,
+			UnitPrice:   line.UnitPrice,
+			Total:       line.Total,
+			Tax:         line.Tax,
+			TaxRate:     line.TaxRate,
+			TaxClass:    line.TaxClass,
+			Discount:    line.Discount,
+		})
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+)
+
+func (s *V2Service) SetGoesWith(ctx context.Context, req *pbv2.SetGoesWithRequest) (*pbv2.SetGoesWithResponse, error) {
+
+	if err := s.v1.repo.SetGoesWith(ctx, req.ProductId, req.RelatedProductIds); err != nil {
+		return nil, err
+	}
+
+	return &pbv2.SetGoesWithResponse{}, nil
+}
+
+func (s *V2Service) ListGoesWith(ctx context.Context, req *pbv2.ListGoesWithRequest) (*pbv2.ListGoesWithResponse, error) {
+
+	links, err := s.v1.repo.ListGoesWith(ctx, req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pbv2.ListGoesWithResponse{Links: make([]*pbv2.GoesWithLink, 0, len(links))}
+	for _, l := range links {
+		resp.Links = append(resp.Links, &pbv2.GoesWithLink{
+			Product: productToV2(l.Product),
+			Curated: l.Curated,
+			Score:   int32(l.Score),
+		})
+	}
+	return resp, nil
+}
+
+func (s *V2Service) InferGoesWith(ctx context.Context, req *pbv2.InferGoesWithRequest) (*pbv2.InferGoesWithResponse, error) {
+
+	linked, err := s.v1.repo.InferGoesWith(ctx, req.TenantId, int(req.MinCoPurchases))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("audit: kind=infer_goes_with principal=%s tenant=%s linked=%d", requestedBy(ctx), req.TenantId, linked)
+
+	return &pbv2.InferGoesWithResponse{
+		Linked: int32(linked),
+	}, nil
+}
+
+func (s *V2Service) GetOutfitSuggestions(ctx context.Context, req *pbv2.GetOutfitSuggestionsRequest) (*pbv2.GetOutfitSuggestionsResponse, error) {
+
+	product, items, err := s.v1.repo.GetOutfitSuggestions(ctx, req.ProductId, req.Sizes, req.Colors, int(req.Items))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pbv2.GetOutfitSuggestionsResponse{
+		Product: productToV2(product),
+		Items:   make([]*pbv2.OutfitItem, 0, len(items)),
+	}
+	for _, item := range items {
+		resp.Items = append(resp.Items, &pbv2.OutfitItem{
+			Product: productToV2(item.Product),
+			Sku:     item.SKU,
+			Curated: item.Curated,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+)
+
+func (s *V2Service) GetCatalogOverview(ctx context.Context, req *pbv2.GetCatalogOverviewRequest) (*pbv2.GetCatalogOverviewResponse, error) {
+
+	overview, err := s.v1.repo.CatalogOverview(ctx, req.TenantId, int(req.Months))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pbv2.GetCatalogOverviewResponse{
+		ActiveProducts:    int32(overview.ActiveProducts),
+		ArchivedProducts:  int32(overview.ArchivedProducts),
+		Skus:              int32(overview.SKUs),
+		OutOfStockPercent: overview.OutOfStockPercent,
+		Categories:        make([]*pbv2.CategoryPrices, 0, len(overview.Categories)),
+		Growth:            make([]*pbv2.CatalogMonth, 0, len(overview.Growth)),
+		ComputedAt:        overview.ComputedAt,
+	}
+	for _, c := range overview.Categories {
+		resp.Categories = append(resp.Categories, &pbv2.CategoryPrices{
+			MainCategory: c.Category,
+			Products:     int32(c.Products),
+			AveragePrice: c.AveragePrice,
+		})
+	}
+	for _, m := range overview.Growth {
+		resp.Growth = append(resp.Growth, &pbv2.CatalogMonth{
+			Month:   m.Month,
+			Created: int32(m.Created),
+			Total:   int32(m.Total),
+		})
+	}
+	return resp, nil
+}
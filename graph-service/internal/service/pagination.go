@@ -0,0 +1,43 @@
+package service
+
+import (
+	"strings"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pageSize clamps a requested page size to the supported range.
+func pageSize(requested int32) int {
+	if requested <= 0 {
+		return defaultPageSize
+	}
+	if requested > maxPageSize {
+		return maxPageSize
+	}
+	return int(requested)
+}
+
+func (s *ProductService) decodePageToken(token, scope string) (pagetoken.Cursor, error) {
+	cur, err := s.tokens.Decode(token, scope)
+	if err != nil {
+		return pagetoken.Cursor{}, &repository.FieldError{Field: "page_token", Description: err.Error()}
+	}
+	return cur, nil
+}
+
+// filterKey renders a filter into a page token scope component.
+func filterKey(f *pb.CatalogFilter) string {
+	return strings.Join([]string{
+		f.GetTenantId(),
+		f.GetMainCategory(),
+		f.GetSubcategory(),
+		f.GetSpecificType(),
+	}, "|")
+}
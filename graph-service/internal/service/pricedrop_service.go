@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+func (s *ProductService) SubscribeToPriceDrop(ctx context.Context, req *pb.SubscribeToPriceDropRequest) (*pb.SubscribeToPriceDropResponse, error) {
+
+	if err := s.checkShared(ctx, repository.CustomerLabel, req.UserId); err != nil {
+		return nil, err
+	}
+	if err := s.checkProducts(ctx, []string{req.ProductId}); err != nil {
+		return nil, err
+	}
+	sub, err := s.repo.SubscribeToPriceDrop(ctx, req.UserId, req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SubscribeToPriceDropResponse{
+		Subscription: sub,
+	}, nil
+}
+
+func (s *ProductService) UnsubscribeFromPriceDrop(ctx context.Context, req *pb.UnsubscribeFromPriceDropRequest) (*pb.UnsubscribeFromPriceDropResponse, error) {
+
+	if err := s.checkShared(ctx, repository.CustomerLabel, req.UserId); err != nil {
+		return nil, err
+	}
+	if err := s.checkProducts(ctx, []string{req.ProductId}); err != nil {
+		return nil, err
+	}
+	err := s.repo.UnsubscribeFromPriceDrop(ctx, req.UserId, req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UnsubscribeFromPriceDropResponse{
+		Success: true,
+	}, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+// requestedBy names the caller for the audit trail.
+func requestedBy(ctx context.Context) string {
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		return principal.Name
+	}
+	return "unauthenticated"
+}
+
+func (s *ProductService) ExportUserData(ctx context.Context, req *pb.ExportUserDataRequest) (*pb.ExportUserDataResponse, error) {
+
+	if err := s.checkShared(ctx, repository.CustomerLabel, req.UserId); err != nil {
+		return nil, err
+	}
+	caller := requestedBy(ctx)
+	bundle, requestID, err := s.repo.ExportUserData(ctx, req.UserId, caller)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("audit: data_request=%s kind=export principal=%s", requestID, caller)
+
+	return &pb.ExportUserDataResponse{
+		Bundle:    bundle,
+		RequestId: requestID,
+	}, nil
+}
+
+func (s *ProductService) DeleteUserData(ctx context.Context, req *pb.DeleteUserDataRequest) (*pb.DeleteUserDataResponse, error) {
+
+	if err := s.checkShared(ctx, repository.CustomerLabel, req.UserId); err != nil {
+		return nil, err
+	}
+	caller := requestedBy(ctx)
+	deletion, err := s.repo.DeleteUserData(ctx, req.UserId, caller, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("audit: data_request=%s kind=delete principal=%s nodes_deleted=%d orders_retained=%d",
+		deletion.RequestID, caller, deletion.NodesDeleted, deletion.OrdersRetained)
+
+	return &pb.DeleteUserDataResponse{
+		Pseudonym:      deletion.Pseudonym,
+		OrdersRetained: int32(deletion.OrdersRetained),
+		NodesDeleted:   int32(deletion.NodesDeleted),
+		RequestId:      deletion.RequestID,
+	}, nil
+}
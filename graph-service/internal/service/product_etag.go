@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/etag"
+	"google.golang.org/protobuf/proto"
+)
+
+// productTag tags a product as read in locale, through view, which names
+// the API version and read mask. updated_at moves with every write to the
+// product or its sizes; the name and description cover translations.
+func productTag(p *pb.Product, locale, view string) string {
+	return etag.Of("product", view, locale, p.Id, strconv.FormatInt(p.UpdatedAt, 10), p.Name, p.Description)
+}
+
+// stampTag tags a product from its stamp, without reading the product.
+// ok is false where stamps aren't served, can't be read or are another
+// tenant's, and the product must be read to tag it.
+func (s *ProductService) stampTag(ctx context.Context, id, locale, view string) (tag string, ok bool) {
+	if s.repo == nil {
+		return "", false
+	}
+	stamp, err := s.repo.ProductStamp(ctx, id)
+	if err != nil || auth.CheckTenant(ctx, "product", stamp.Id, stamp.TenantId) != nil {
+		return "", false
+	}
+	if err := s.repo.LocalizeProducts(ctx, []*pb.Product{stamp}, locale); err != nil {
+		return "", false
+	}
+	return productTag(stamp, locale, view), true
+}
+
+// extrasTag extends a product's tag over the questions and offers served
+// with it in resp.
+func extrasTag(tag string, resp *pbv2.GetProductResponse) string {
+	parts := []string{tag}
+	marshal := proto.MarshalOptions{Deterministic: true}
+	for _, q := range resp.Questions {
+		b, _ := marshal.Marshal(q)
+		parts = append(parts, "question", string(b))
+	}
+	for _, o := range resp.BuyBox {
+		b, _ := marshal.Marshal(o)
+		parts = append(parts, "offer", string(b))
+	}
+	return etag.Of(parts...)
+}
@@ -1,24 +1,179 @@
 package service
 
 import (
+	"cmp"
 	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/commission"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/etag"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/idgen"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/loyalty"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/moderation"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/payments"
 	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/risk"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/shipping"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/tax"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/tracing"
 )
 
 type ProductService struct {
 	pb.UnimplementedGraphServiceServer
-	repo *repository.ProductRepository
+	// catalog serves product operations on either storage backend; repo
+	// serves the Neo4j-only features and is nil on other backends.
+	catalog repository.Repository
+	repo    *repository.ProductRepository
+	tokens  *pagetoken.Codec
+	ids     idgen.Generator
+
+	payments payments.Provider
+	shipping shipping.Provider
+	tax      tax.Provider
+
+	risk       risk.Scorer
+	riskPolicy risk.Policy
+
+	loyalty    *loyalty.Program
+	commission *commission.RateCard
+
+	screener    moderation.Screener
+	autoApprove bool
+
+	testReset bool
+
+	syncSettle time.Duration
 }
 
-func NewProductService(repo *repository.ProductRepository) *ProductService {
-	return &ProductService{repo: repo}
+// Option configures a ProductService.
+type Option func(*ProductService)
+
+// WithIDs makes the ids of products and SKUs created without one with g,
+// rather than as UUIDv7s.
+func WithIDs(g idgen.Generator) Option {
+	return func(s *ProductService) {
+		s.ids = g
+	}
+}
+
+// WithPayments takes order payments through p. Without a provider
+// PlaceOrder is unavailable.
+func WithPayments(p payments.Provider) Option {
+	return func(s *ProductService) {
+		s.payments = p
+	}
+}
+
+// WithTax calculates the tax on carts and orders through p. Without a
+// provider no tax is charged.
+func WithTax(p tax.Provider) Option {
+	return func(s *ProductService) {
+		s.tax = p
+	}
+}
+
+// WithRisk scores orders with scorer before payment is taken and applies
+// policy to the score. Without a scorer every order passes.
+func WithRisk(scorer risk.Scorer, policy risk.Policy) Option {
+	return func(s *ProductService) {
+		s.risk = scorer
+		s.riskPolicy = policy
+	}
+}
+
+// WithLoyalty awards placed orders points under program. Without a
+// program no points are earned.
+func WithLoyalty(program *loyalty.Program) Option {
+	return func(s *ProductService) {
+		s.loyalty = program
+	}
+}
+
+// WithCommission settles lines sold by marketplace sellers at the rates
+// of card. Without a rate card no settlement is recorded.
+func WithCommission(card *commission.RateCard) Option {
+	return func(s *ProductService) {
+		s.commission = card
+	}
+}
+
+// WithShipping quotes shipping through p. Without a provider
+// EstimateShipping is unavailable.
+func WithShipping(p shipping.Provider) Option {
+	return func(s *ProductService) {
+		s.shipping = p
+	}
+}
+
+// WithModeration screens new questions and answers with screener. Flagged
+// posts wait for a moderator; clean ones are approved straight away when
+// autoApprove is set. Without a screener every post waits.
+func WithModeration(screener moderation.Screener, autoApprove bool) Option {
+	return func(s *ProductService) {
+		s.screener = screener
+		s.autoApprove = autoApprove
+	}
+}
+
+// WithTestReset serves ResetAndSeed, which wipes the graph. It's only for
+// test environments; without it the RPC is unimplemented.
+func WithTestReset() Option {
+	return func(s *ProductService) {
+		s.testReset = true
+	}
+}
+
+// WithSyncSettle makes SyncChanges serve changes once they are d old,
+// rather than after defaultSyncSettle.
+func WithSyncSettle(d time.Duration) Option {
+	return func(s *ProductService) {
+		s.syncSettle = d
+	}
+}
+
+// CatalogMethods are the RPCs served entirely through the storage-neutral
+// catalog. Only these are available when products live outside Neo4j.
+var CatalogMethods = []string{
+	pb.GraphService_CreateProduct_FullMethodName,
+	pb.GraphService_GetProduct_FullMethodName,
+	pb.GraphService_GetAvailability_FullMethodName,
+	pb.GraphService_ResolveProduct_FullMethodName,
+	pb.GraphService_GetProductBySlug_FullMethodName,
+	pb.GraphService_UpdateProduct_FullMethodName,
+	pb.GraphService_DeleteProduct_FullMethodName,
+	pb.GraphService_CloneProduct_FullMethodName,
+	pb.GraphService_SetProductsArchived_FullMethodName,
+	pb.GraphService_UpdateStock_FullMethodName,
+	pb.GraphService_AddProductSize_FullMethodName,
+	pb.GraphService_ListProducts_FullMethodName,
+	pb.GraphService_ListProductsUpdatedSince_FullMethodName,
+	pb.GraphService_GetNewArrivals_FullMethodName,
+	pb.GraphService_GetDeals_FullMethodName,
+	pb.GraphService_UpsertProductTranslation_FullMethodName,
+}
+
+func NewProductService(catalog repository.Repository, repo *repository.ProductRepository, tokens *pagetoken.Codec, opts ...Option) *ProductService {
+	s := &ProductService{catalog: catalog, repo: repo, tokens: tokens, ids: idgen.UUIDv7{}, syncSettle: defaultSyncSettle}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *ProductService) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.CreateProductResponse, error) {
 
-	err := s.repo.CreateProduct(ctx, req.Product)
+	if req.Product == nil {
+		return nil, &repository.FieldError{Field: "product", Description: "product is required"}
+	}
+	s.assignIDs(req.Product)
+
+	err := s.catalog.CreateProduct(ctx, req.Product)
 	if err != nil {
 		return nil, err
 	}
@@ -28,33 +183,198 @@ func (s *ProductService) CreateProduct(ctx context.Context, req *pb.CreateProduc
 	}, nil
 }
 
+// assignIDs gives p, and its sizes, ids where they have none.
+func (s *ProductService) assignIDs(p *pb.Product) {
+	if p.Id == "" {
+		p.Id = s.ids.NewID()
+	}
+	for _, size := range p.Sizes {
+		if size.Sku == "" {
+			size.Sku = s.ids.NewID()
+		}
+	}
+}
+
+// GetProduct answers a client sending back the product's ETag with an
+// empty response when the product is unchanged, reading only its stamp.
 func (s *ProductService) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.GetProductResponse, error) {
 
-	product, err := s.repo.GetProduct(ctx, req.Id)
+	if etag.Conditional(ctx) {
+		if tag, ok := s.stampTag(ctx, req.Id, req.Locale, "v1"); ok && etag.Check(ctx, tag) {
+			return &pb.GetProductResponse{}, nil
+		}
+	}
+
+	product, err := s.catalog.GetProduct(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.CheckTenant(ctx, "product", product.Id, product.TenantId); err != nil {
+		return nil, err
+	}
+
+	err = s.catalog.LocalizeProducts(ctx, []*pb.Product{product}, req.Locale)
 	if err != nil {
 		return nil, err
 	}
 
+	if etag.Check(ctx, productTag(product, req.Locale, "v1")) {
+		return &pb.GetProductResponse{}, nil
+	}
+
 	return &pb.GetProductResponse{
+		Product:      product,
+		SizesSummary: sizesSummary(product.Sizes),
+	}, nil
+}
+
+func (s *ProductService) GetAvailability(ctx context.Context, req *pb.GetAvailabilityRequest) (*pb.GetAvailabilityResponse, error) {
+
+	product, err := s.catalog.GetProduct(ctx, req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.CheckTenant(ctx, "product", product.Id, product.TenantId); err != nil {
+		return nil, err
+	}
+
+	return &pb.GetAvailabilityResponse{
+		ProductId: product.Id,
+		Sizes:     sizesSummary(product.Sizes),
+	}, nil
+}
+
+// lowStockThreshold is the most units a size shows as low stock with.
+const lowStockThreshold = 5
+
+// sizesSummary buckets each size's stock. Stock already excludes what
+// open reservations hold.
+func sizesSummary(sizes []*pb.ProductSize) []*pb.SizeAvailability {
+	summary := make([]*pb.SizeAvailability, 0, len(sizes))
+	for _, size := range sizes {
+		level := pb.StockLevel_IN_STOCK
+		switch {
+		case !size.InStock || size.Stock <= 0:
+			level = pb.StockLevel_OUT_OF_STOCK
+		case size.Stock <= lowStockThreshold:
+			level = pb.StockLevel_LOW_STOCK
+		}
+		summary = append(summary, &pb.SizeAvailability{
+			Size:    size.Size,
+			Sku:     size.Sku,
+			InStock: level != pb.StockLevel_OUT_OF_STOCK,
+			Level:   level,
+		})
+	}
+	return summary
+}
+
+// identifierKinds maps IdentifierType values to repository identifier kinds.
+var identifierKinds = map[pb.IdentifierType]string{
+	pb.IdentifierType_ANY_IDENTIFIER: "",
+	pb.IdentifierType_PRODUCT_ID:     repository.IdentifierID,
+	pb.IdentifierType_SLUG:           repository.IdentifierSlug,
+	pb.IdentifierType_GTIN:           repository.IdentifierGTIN,
+	pb.IdentifierType_EXTERNAL_ID:    repository.IdentifierExternalID,
+}
+
+func (s *ProductService) ResolveProduct(ctx context.Context, req *pb.ResolveProductRequest) (*pb.ResolveProductResponse, error) {
+
+	kind, ok := identifierKinds[req.Type]
+	if !ok {
+		return nil, &repository.FieldError{Field: "type", Description: "unknown identifier type"}
+	}
+
+	product, matched, err := s.catalog.ResolveProduct(ctx, kind, req.Value)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.CheckTenant(ctx, "product", product.Id, product.TenantId); err != nil {
+		return nil, err
+	}
+
+	err = s.catalog.LocalizeProducts(ctx, []*pb.Product{product}, req.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ResolveProductResponse{
+		Product: product,
+	}
+	for t, k := range identifierKinds {
+		if k == matched {
+			resp.MatchedType = t
+		}
+	}
+	return resp, nil
+}
+
+func (s *ProductService) GetProductBySlug(ctx context.Context, req *pb.GetProductBySlugRequest) (*pb.GetProductBySlugResponse, error) {
+
+	if req.Slug == "" {
+		return nil, &repository.FieldError{Field: "slug", Description: "slug is required"}
+	}
+
+	product, _, err := s.catalog.ResolveProduct(ctx, repository.IdentifierSlug, req.Slug)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.CheckTenant(ctx, "product", product.Id, product.TenantId); err != nil {
+		return nil, err
+	}
+
+	err = s.catalog.LocalizeProducts(ctx, []*pb.Product{product}, req.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetProductBySlugResponse{
 		Product: product,
 	}, nil
 }
 
 func (s *ProductService) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.UpdateProductResponse, error) {
 
-	err := s.repo.UpdateProduct(ctx, req.Product)
+	if err := s.checkProducts(ctx, []string{req.Product.GetId()}); err != nil {
+		return nil, err
+	}
+	changes, err := s.catalog.UpdateProduct(ctx, req.Product)
 	if err != nil {
 		return nil, err
 	}
+	fields := make([]string, 0, len(changes))
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+	log.Printf("audit: product=%s kind=update principal=%s changed=%s", req.Product.Id, requestedBy(ctx), strings.Join(fields, ","))
 
 	return &pb.UpdateProductResponse{
 		Success: true,
+		Changes: changes,
+	}, nil
+}
+
+func (s *ProductService) SetProductsArchived(ctx context.Context, req *pb.SetProductsArchivedRequest) (*pb.SetProductsArchivedResponse, error) {
+
+	if err := s.checkProducts(ctx, req.ProductIds); err != nil {
+		return nil, err
+	}
+	updated, err := s.catalog.SetProductsArchived(ctx, req.ProductIds, req.Archived)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SetProductsArchivedResponse{
+		Updated: int32(updated),
 	}, nil
 }
 
 func (s *ProductService) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
 
-	err := s.repo.DeleteProduct(ctx, req.Id)
+	if err := s.checkProducts(ctx, []string{req.Id}); err != nil {
+		return nil, err
+	}
+	err := s.catalog.DeleteProduct(ctx, req.Id)
 	if err != nil {
 		return nil, err
 	}
@@ -64,12 +384,105 @@ func (s *ProductService) DeleteProduct(ctx context.Context, req *pb.DeleteProduc
 	}, nil
 }
 
+func (s *ProductService) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
+
+	scope := "products"
+	if req.IncludeArchive {
+		scope = "products:archive"
+	}
+
+	cursor, err := s.decodePageToken(req.PageToken, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageSize(req.PageSize)
+
+	// Fetch one extra row to learn whether another page exists
+	tenant, _ := auth.TenantFrom(ctx)
+	products, err := s.catalog.ListProducts(ctx, tenant, cursor.LastID, limit+1, req.IncludeArchive)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListProductsResponse{}
+	if len(products) > limit {
+		products = products[:limit]
+		resp.NextPageToken = s.tokens.Encode(pagetoken.Cursor{
+			Scope:  scope,
+			LastID: products[limit-1].Id,
+		})
+	}
+	resp.Products = products
+
+	return resp, nil
+}
+
+func (s *ProductService) ListProductsUpdatedSince(ctx context.Context, req *pb.ListProductsUpdatedSinceRequest) (*pb.ListProductsUpdatedSinceResponse, error) {
+
+	scope := fmt.Sprintf("updated_since:%d", req.Since)
+
+	cursor, err := s.decodePageToken(req.PageToken, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageSize(req.PageSize)
+
+	tenant, _ := auth.TenantFrom(ctx)
+	products, err := s.catalog.ListProductsUpdatedSince(ctx, tenant, req.Since, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListProductsUpdatedSinceResponse{}
+	if len(products) > limit {
+		products = products[:limit]
+		last := products[limit-1]
+		resp.NextPageToken = s.tokens.Encode(pagetoken.Cursor{
+			Scope:    scope,
+			LastID:   last.Id,
+			LastTime: last.UpdatedAt,
+		})
+	}
+	resp.Products = products
+
+	return resp, nil
+}
+
 func (s *ProductService) SearchProducts(ctx context.Context, req *pb.SearchProductsRequest) (*pb.SearchProductsResponse, error) {
 
+	tracing.Annotate(ctx, tracing.QueryHash.String(tracing.HashText(req.Text+req.Query)))
+
+	return inReadUnit(ctx, s.repo, func(ctx context.Context) (*pb.SearchProductsResponse, error) {
+		return s.searchProducts(ctx, req)
+	})
+}
+
+func (s *ProductService) searchProducts(ctx context.Context, req *pb.SearchProductsRequest) (*pb.SearchProductsResponse, error) {
+	prefs, err := s.userPreferences(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Text != "" {
+		return s.textSearch(ctx, req, prefs)
+	}
+
+	// A raw query reads whatever it matches, whatever its tenant.
+	if err := auth.CheckAllTenants(ctx, "a Cypher query"); err != nil {
+		return nil, err
+	}
 	results, err := s.repo.SearchProducts(ctx, req.Query)
 	if err != nil {
 		return nil, err
 	}
+	favorBrands(results, prefs.GetFavoriteBrands())
+
+	err = s.repo.LocalizeProducts(ctx, results, cmp.Or(req.Locale, prefs.GetLocale()))
+	if err != nil {
+		return nil, err
+	}
 
 	return &pb.SearchProductsResponse{
 		Products: results,
@@ -78,7 +491,10 @@ func (s *ProductService) SearchProducts(ctx context.Context, req *pb.SearchProdu
 
 func (s *ProductService) UpdateStock(ctx context.Context, req *pb.UpdateStockRequest) (*pb.UpdateStockResponse, error) {
 
-	err := s.repo.UpdateStock(ctx, req.Sku, req.NewStock)
+	if err := s.checkProducts(ctx, nil, req.Sku); err != nil {
+		return nil, err
+	}
+	err := s.catalog.UpdateStock(ctx, req.Sku, req.NewStock)
 	if err != nil {
 		return nil, err
 	}
@@ -88,3 +504,18 @@ func (s *ProductService) UpdateStock(ctx context.Context, req *pb.UpdateStockReq
 	}, nil
 }
 
+func (s *ProductService) AddProductSize(ctx context.Context, req *pb.AddProductSizeRequest) (*pb.AddProductSizeResponse, error) {
+
+	if err := s.checkProducts(ctx, []string{req.ProductId}); err != nil {
+		return nil, err
+	}
+	err := s.catalog.AddProductSize(ctx, req.ProductId, req.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.AddProductSizeResponse{
+		Success: true,
+	}, nil
+}
+
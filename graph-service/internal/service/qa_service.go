@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+var qaStatuses = map[string]pbv2.QAStatus{
+	repository.QAPending:  pbv2.QAStatus_QA_PENDING,
+	repository.QAApproved: pbv2.QAStatus_QA_APPROVED,
+	repository.QARejected: pbv2.QAStatus_QA_REJECTED,
+}
+
+func (s *V2Service) PostQuestion(ctx context.Context, req *pbv2.PostQuestionRequest) (*pbv2.PostQuestionResponse, error) {
+
+	if err := s.v1.checkProducts(ctx, []string{req.ProductId}); err != nil {
+		return nil, err
+	}
+	if err := s.v1.checkShared(ctx, repository.CustomerLabel, req.UserId); err != nil {
+		return nil, err
+	}
+	q, err := s.v1.repo.PostQuestion(ctx, req.ProductId, req.UserId, req.Text)
+	if err != nil {
+		return nil, err
+	}
+	q.Status = s.screenPost(ctx, repository.QuestionPost, q.ID, q.Text)
+
+	return &pbv2.PostQuestionResponse{
+		Question: questionToV2(*q),
+	}, nil
+}
+
+func (s *V2Service) PostAnswer(ctx context.Context, req *pbv2.PostAnswerRequest) (*pbv2.PostAnswerResponse, error) {
+
+	if err := s.v1.checkOwner(ctx, repository.QuestionLabel, req.QuestionId); err != nil {
+		return nil, err
+	}
+	if err := s.v1.checkShared(ctx, repository.CustomerLabel, req.UserId); err != nil {
+		return nil, err
+	}
+	a, err := s.v1.repo.PostAnswer(ctx, req.QuestionId, req.UserId, req.Text)
+	if err != nil {
+		return nil, err
+	}
+	a.Status = s.screenPost(ctx, repository.AnswerPost, a.ID, a.Text)
+
+	return &pbv2.PostAnswerResponse{
+		Answer: answerToV2(*a),
+	}, nil
+}
+
+func (s *V2Service) ListQuestions(ctx context.Context, req *pbv2.ListQuestionsRequest) (*pbv2.ListQuestionsResponse, error) {
+
+	if err := s.v1.checkProducts(ctx, []string{req.ProductId}); err != nil {
+		return nil, err
+	}
+	scope := "questions:" + req.ProductId
+
+	cursor, err := s.v1.decodePageToken(req.PageToken, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageSize(req.Limit)
+
+	questions, err := s.v1.repo.ListQuestions(ctx, req.ProductId, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pbv2.ListQuestionsResponse{}
+	if len(questions) > limit {
+		questions = questions[:limit]
+		last := questions[limit-1]
+		resp.NextPageToken = s.v1.tokens.Encode(pagetoken.Cursor{
+			Scope:    scope,
+			LastID:   last.ID,
+			LastTime: last.CreatedAt,
+		})
+	}
+	resp.Questions = questionsToV2(questions)
+
+	return resp, nil
+}
+
+func questionsToV2(questions []repository.Question) []*pbv2.ProductQuestion {
+	out := make([]*pbv2.ProductQuestion, 0, len(questions))
+	for _, q := range questions {
+		out = append(out, questionToV2(q))
+	}
+	return out
+}
+
+func questionToV2(q repository.Question) *pbv2.ProductQuestion {
+	out := &pbv2.ProductQuestion{
+		Id:        q.ID,
+		ProductId: q.ProductID,
+		UserId:    q.AuthorID,
+		Text:      q.Text,
+		Status:    qaStatuses[q.Status],
+		CreatedAt: q.CreatedAt,
+	}
+	for _, a := range q.Answers {
+		out.Answers = append(out.Answers, answerToV2(a))
+	}
+	return out
+}
+
+func answerToV2(a repository.Answer) *pbv2.ProductAnswer {
+	return &pbv2.ProductAnswer{
+		Id:         a.ID,
+		QuestionId: a.QuestionID,
+		UserId:     a.AuthorID,
+		Text:       a.Text,
+		Status:     qaStatuses[a.Status],
+		CreatedAt:  a.CreatedAt,
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// readMask is the set of v2 Product fields a read_mask keeps. The nil mask
+// keeps them all.
+type readMask map[protoreflect.Name]bool
+
+// parseReadMask checks mask names top-level Product fields. id is always
+// kept.
+func parseReadMask(mask *fieldmaskpb.FieldMask) (readMask, error) {
+	if len(mask.GetPaths()) == 0 {
+		return nil, nil
+	}
+	fields := (&pbv2.Product{}).ProtoReflect().Descriptor().Fields()
+	keep := readMask{"id": true}
+	for _, path := range mask.Paths {
+		name := protoreflect.Name(path)
+		if !name.IsValid() || fields.ByName(name) == nil {
+			return nil, &repository.FieldError{Field: "read_mask", Description: fmt.Sprintf("%q is not a Product field", path)}
+		}
+		keep[name] = true
+	}
+	return keep, nil
+}
+
+// project returns ctx narrowing the product reads made with it to the
+// mask's fields, and to the v1 fields in needed, which later steps read.
+func (m readMask) project(ctx context.Context, needed ...string) context.Context {
+	if m == nil {
+		return ctx
+	}
+	fields := needed
+	for name := range m {
+		if name == "variants" {
+			fields = append(fields, "sizes")
+			continue
+		}
+		fields = append(fields, string(name))
+	}
+	return repository.WithProjection(ctx, fields)
+}
+
+// key names the mask in a fixed form, "" for the nil mask.
+func (m readMask) key() string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, string(name))
+	}
+	slices.Sort(names)
+	return strings.Join(names, ",")
+}
+
+// apply clears the fields the mask leaves out of p.
+func (m readMask) apply(p *pbv2.Product) {
+	if m == nil || p == nil {
+		return
+	}
+	msg := p.ProtoReflect()
+	var cleared []protoreflect.FieldDescriptor
+	msg.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !m[fd.Name()] {
+			cleared = append(cleared, fd)
+		}
+		return true
+	})
+	for _, fd := range cleared {
+		msg.Clear(fd)
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+const defaultRecommendationLimit = 10
+
+var recommendationRelTypes = map[pb.RecommendationType]string{
+	pb.RecommendationType_CROSS_SELL: repository.RelCrossSell,
+	pb.RecommendationType_UPSELL:     repository.RelUpsell,
+}
+
+func (s *ProductService) SetCrossSell(ctx context.Context, req *pb.SetCrossSellRequest) (*pb.SetCrossSellResponse, error) {
+
+	if err := s.checkProducts(ctx, append([]string{req.ProductId}, req.RelatedProductIds...)); err != nil {
+		return nil, err
+	}
+	err := s.repo.SetMerchandisingLinks(ctx, repository.RelCrossSell, req.ProductId, req.RelatedProductIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SetCrossSellResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *ProductService) SetUpsell(ctx context.Context, req *pb.SetUpsellRequest) (*pb.SetUpsellResponse, error) {
+
+	if err := s.checkProducts(ctx, append([]string{req.ProductId}, req.RelatedProductIds...)); err != nil {
+		return nil, err
+	}
+	err := s.repo.SetMerchandisingLinks(ctx, repository.RelUpsell, req.ProductId, req.RelatedProductIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SetUpsellResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *ProductService) GetMerchandisedRecommendations(ctx context.Context, req *pb.GetMerchandisedRecommendationsRequest) (*pb.GetMerchandisedRecommendationsResponse, error) {
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultRecommendationLimit
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	if err := s.checkProducts(ctx, []string{req.ProductId}); err != nil {
+		return nil, err
+	}
+	recs, err := s.repo.GetMerchandisedRecommendations(ctx, recommendationRelTypes[req.Type], req.ProductId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetMerchandisedRecommendationsResponse{
+		Recommendations: recs,
+	}, nil
+}
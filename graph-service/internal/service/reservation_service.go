@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+func (s *ProductService) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+
+	reservation := &pb.Reservation{
+		Id:       req.Id,
+		TenantId: req.TenantId,
+		Items:    req.Items,
+	}
+	err := s.repo.ReserveStock(ctx, reservation, time.Duration(req.TtlSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReserveStockResponse{
+		Reservation: reservation,
+	}, nil
+}
+
+func (s *ProductService) CommitReservation(ctx context.Context, req *pb.CommitReservationRequest) (*pb.CommitReservationResponse, error) {
+
+	if err := s.checkOwner(ctx, repository.ReservationLabel, req.Id); err != nil {
+		return nil, err
+	}
+	err := s.repo.CommitReservation(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CommitReservationResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *ProductService) ReleaseReservation(ctx context.Context, req *pb.ReleaseReservationRequest) (*pb.ReleaseReservationResponse, error) {
+
+	if err := s.checkOwner(ctx, repository.ReservationLabel, req.Id); err != nil {
+		return nil, err
+	}
+	err := s.repo.ReleaseReservation(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReleaseReservationResponse{
+		Success: true,
+	}, nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/fixtures"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ResetAndSeed wipes the graph and loads a fixture set, for test
+// environments only; see WithTestReset.
+func (s *V2Service) ResetAndSeed(ctx context.Context, req *pbv2.ResetAndSeedRequest) (*pbv2.ResetAndSeedResponse, error) {
+
+	if !s.v1.testReset {
+		return nil, status.Error(codes.Unimplemented, "ResetAndSeed is only served with ENABLE_TEST_RESET")
+	}
+	if err := auth.CheckAllTenants(ctx, "resetting the graph"); err != nil {
+		return nil, err
+	}
+
+	set, err := fixtures.Load(req.Fixture)
+	if errors.Is(err, fixtures.ErrUnknown) {
+		return nil, &repository.FieldError{Field: "fixture", Description: err.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.v1.repo.ResetGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("audit: kind=reset_graph fixture=%s deleted=%d principal=%s", set.Name, deleted, requestedBy(ctx))
+
+	for _, p := range set.Products {
+		if _, err := s.v1.CreateProduct(ctx, &pb.CreateProductRequest{Product: p}); err != nil {
+			return nil, fmt.Errorf("fixture %s: product %s: %w", set.Name, p.Id, err)
+		}
+	}
+	for _, seller := range set.Sellers {
+		_, err := s.v1.repo.CreateSeller(ctx, repository.Seller{
+			ID:       seller.ID,
+			TenantID: seller.TenantID,
+			Name:     seller.Name,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s: seller %s: %w", set.Name, seller.ID, err)
+		}
+	}
+	for _, o := range set.Offers {
+		_, err := s.v1.repo.SetOffer(ctx, repository.Offer{
+			SellerID: o.SellerID,
+			SKU:      o.SKU,
+			Price:    o.Price,
+			Stock:    o.Stock,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s: offer of %s by %s: %w", set.Name, o.SKU, o.SellerID, err)
+		}
+	}
+
+	return &pbv2.ResetAndSeedResponse{
+		DeletedNodes: deleted,
+		Products:     int32(len(set.Products)),
+		Sellers:      int32(len(set.Sellers)),
+		Offers:       int32(len(set.Offers)),
+	}, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/payments"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *ProductService) CreateReturn(ctx context.Context, req *pb.CreateReturnRequest) (*pb.CreateReturnResponse, error) {
+
+	ret := &pb.Return{
+		Id:      req.Id,
+		OrderId: req.OrderId,
+		Lines:   req.Lines,
+		Reason:  req.Reason,
+	}
+	if err := s.checkOwner(ctx, repository.OrderLabel, req.OrderId); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateReturn(ctx, ret); err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateReturnResponse{
+		OrderReturn: ret,
+	}, nil
+}
+
+func (s *ProductService) ApproveReturn(ctx context.Context, req *pb.ApproveReturnRequest) (*pb.ApproveReturnResponse, error) {
+
+	ret, err := s.repo.ApproveReturn(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ApproveReturnResponse{
+		OrderReturn: ret,
+	}, nil
+}
+
+// CompleteReturn refunds an approved return through the payment provider
+// and then restocks it. Orders paid partly by gift card are refunded to
+// the card and the provider in the same shares. The refund is keyed on the
+// return, so a retry after the restock failed doesn't pay out twice.
+func (s *ProductService) CompleteReturn(ctx context.Context, req *pb.CompleteReturnRequest) (*pb.CompleteReturnResponse, error) {
+
+	ret, err := s.repo.GetReturn(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	switch ret.Status {
+	case pb.ReturnStatus_RETURN_COMPLETED:
+		return &pb.CompleteReturnResponse{OrderReturn: ret}, nil
+	case pb.ReturnStatus_RETURN_APPROVED:
+	default:
+		return nil, fmt.Errorf("%w: return %s has not been approved", repository.ErrInvalidTransition, ret.Id)
+	}
+
+	var refundID string
+	if amount := payments.MinorUnits(ret.RefundAmount); amount > 0 {
+		order, err := s.repo.GetOrder(ctx, ret.OrderId)
+		if err != nil {
+			return nil, err
+		}
+		if card := payments.MinorUnits(order.GiftCardAmount); card > 0 {
+			share := min(amount, amount*card/max(payments.MinorUnits(order.Total), 1))
+			if order.PaymentId == "" {
+				share = amount
+			}
+			err := s.repo.RefundGiftCard(ctx, order.Id, "return-"+ret.Id, float64(share)/100)
+			if err != nil {
+				return nil, err
+			}
+			amount -= share
+		}
+		if amount > 0 {
+			if s.payments == nil {
+				return nil, status.Error(codes.Unimplemented, "no payment provider is configured")
+			}
+			refundID, err = s.payments.Refund(ctx, payments.RefundRequest{
+				AuthorizationID: order.PaymentId,
+				Amount:          amount,
+				IdempotencyKey:  "return-" + ret.Id,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	ret, err = s.repo.CompleteReturn(ctx, ret.Id, refundID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CompleteReturnResponse{
+		OrderReturn: ret,
+	}, nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/payments"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/risk"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// assessRisk scores the priced order. Without a scorer every order passes.
+func (s *ProductService) assessRisk(ctx context.Context, order *pb.Order, req *pb.PlaceOrderRequest) (risk.Decision, risk.Assessment, error) {
+	if s.risk == nil {
+		return risk.Pass, risk.Assessment{}, nil
+	}
+
+	check := risk.Order{
+		ID:              order.Id,
+		TenantID:        order.TenantId,
+		CustomerID:      order.CustomerId,
+		Amount:          payments.MinorUnits(order.Total),
+		Currency:        order.Currency,
+		Lines:           len(order.Lines),
+		ShippingCountry: req.GetDestination().GetCountry(),
+		BillingCountry:  req.BillingCountry,
+		ClientCountry:   req.ClientCountry,
+	}
+	for _, line := range order.Lines {
+		check.Units += int64(line.Quantity)
+	}
+
+	assessment, err := s.risk.Score(ctx, check)
+	if err != nil {
+		return risk.Pass, assessment, err
+	}
+	return s.riskPolicy.Decide(assessment), assessment, nil
+}
+
+// declineOrder returns a declined order's stock and records its
+// assessment. It returns risk.ErrDeclined.
+func (s *ProductService) declineOrder(ctx context.Context, orderID string, assessment risk.Assessment) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), compensationTimeout)
+	defer cancel()
+
+	if _, err := s.repo.DeclineOrder(ctx, orderID, risk.ErrDeclined.Error(), assessment.Score, assessment.Reasons); err != nil {
+		log.Printf("orders: decline order %s: %v", orderID, err)
+	}
+	return risk.ErrDeclined
+}
+
+// ReviewFlaggedOrders applies the reviews in order, then lists the orders
+// still waiting for one. A payment that can no longer be captured declines
+// its order rather than failing the call.
+func (s *ProductService) ReviewFlaggedOrders(ctx context.Context, req *pb.ReviewFlaggedOrdersRequest) (*pb.ReviewFlaggedOrdersResponse, error) {
+
+	reviewed := make([]*pb.Order, 0, len(req.Reviews))
+	for _, review := range req.Reviews {
+		order, err := s.reviewOrder(ctx, review)
+		if err != nil {
+			return nil, err
+		}
+		reviewed = append(reviewed, order)
+	}
+
+	flagged, err := s.repo.FlaggedOrders(ctx, req.TenantId, int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReviewFlaggedOrdersResponse{
+		Reviewed: reviewed,
+		Flagged:  flagged,
+	}, nil
+}
+
+func (s *ProductService) reviewOrder(ctx context.Context, review *pb.OrderReview) (*pb.Order, error) {
+	if review.GetOrderId() == "" {
+		return nil, &repository.FieldError{Field: "reviews.order_id", Description: "order id is required"}
+	}
+	order, err := s.repo.GetOrder(ctx, review.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != pb.OrderStatus_UNDER_REVIEW {
+		return nil, fmt.Errorf("%w: order %s is %s, not under review", repository.ErrInvalidTransition, order.Id, order.Status)
+	}
+	paymentID := order.PaymentId
+	if paymentID != "" && s.payments == nil {
+		return nil, status.Error(codes.Unimplemented, "no payment provider is configured")
+	}
+
+	if !review.Approve {
+		return s.rejectOrder(ctx, order.Id, paymentID, cmp.Or(review.Note, "declined on review"))
+	}
+
+	if paymentID != "" {
+		if err := s.payments.Capture(ctx, paymentID); err != nil {
+			return s.rejectOrder(ctx, order.Id, "", err.Error())
+		}
+	}
+
+	approved, err := s.repo.ApproveOrder(ctx, order.Id)
+	if err != nil && paymentID != "" {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), compensationTimeout)
+		defer cancel()
+		_, refundErr := s.payments.Refund(ctx, payments.RefundRequest{
+			AuthorizationID: paymentID,
+			Amount:          payments.MinorUnits(order.Total) - payments.MinorUnits(order.GiftCardAmount),
+			IdempotencyKey:  "order-review-failed-" + order.Id,
+		})
+		if refundErr != nil {
+			log.Printf("orders: undo payment %s for order %s: %v", paymentID, order.Id, refundErr)
+		}
+		return nil, err
+	}
+	if err == nil {
+		s.awardPoints(ctx, approved.Id)
+		s.settleOrder(ctx, approved.Id)
+	}
+	return approved, err
+}
+
+// rejectOrder declines an order held for review, then voids its payment,
+// if any, and credits back its gift card.
+func (s *ProductService) rejectOrder(ctx context.Context, orderID, paymentID, reason string) (*pb.Order, error) {
+	order, err := s.repo.RejectOrder(ctx, orderID, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), compensationTimeout)
+	defer cancel()
+	if paymentID != "" {
+		if err := s.payments.Void(ctx, paymentID); err != nil {
+			log.Printf("orders: void payment %s for declined order %s: %v", paymentID, orderID, err)
+		}
+	}
+	s.reverseGiftCard(ctx, orderID)
+	return order, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+func (s *ProductService) SaveSearch(ctx context.Context, req *pb.SaveSearchRequest) (*pb.SaveSearchResponse, error) {
+
+	if err := s.checkShared(ctx, repository.CustomerLabel, req.SavedSearch.GetUserId()); err != nil {
+		return nil, err
+	}
+	id, err := s.repo.SaveSearch(ctx, req.SavedSearch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SaveSearchResponse{
+		Id: id,
+	}, nil
+}
+
+func (s *ProductService) ListSavedSearches(ctx context.Context, req *pb.ListSavedSearchesRequest) (*pb.ListSavedSearchesResponse, error) {
+
+	if err := s.checkShared(ctx, repository.CustomerLabel, req.UserId); err != nil {
+		return nil, err
+	}
+	searches, err := s.repo.ListSavedSearches(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ListSavedSearchesResponse{
+		SavedSearches: searches,
+	}, nil
+}
+
+func (s *ProductService) DeleteSavedSearch(ctx context.Context, req *pb.DeleteSavedSearchRequest) (*pb.DeleteSavedSearchResponse, error) {
+
+	if err := s.checkShared(ctx, repository.CustomerLabel, req.UserId); err != nil {
+		return nil, err
+	}
+	err := s.repo.DeleteSavedSearch(ctx, req.UserId, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteSavedSearchResponse{
+		Success: true,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"cmp"
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/search"
+)
+
+// textSearch serves SearchProducts requests that carry free text instead of
+// Cypher, using the fulltext index for the locale and highlighting why each
+// product matched.
+func (s *ProductService) textSearch(ctx context.Context, req *pb.SearchProductsRequest, prefs *pb.UserPreferences) (*pb.SearchProductsResponse, error) {
+
+	terms := search.Terms(req.Text)
+	if len(terms) == 0 {
+		return nil, &repository.FieldError{Field: "text", Description: "search text has no searchable terms"}
+	}
+
+	locale := cmp.Or(req.Locale, prefs.GetLocale())
+	products, err := s.repo.FulltextSearch(ctx, search.Query(terms), locale, req.Filter, pageSize(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(products) == 0 {
+		suggestion, err := s.repo.SuggestQuery(ctx, terms, locale, req.Filter)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.SearchProductsResponse{
+			SuggestedQuery: suggestion,
+		}, nil
+	}
+
+	favorBrands(products, prefs.GetFavoriteBrands())
+
+	// Highlight the content that matched: the translations when the
+	// locale has its own index, otherwise the default content, before
+	// localizing replaces it
+	var highlights []*pb.SearchHighlight
+	localized := s.repo.LocalizedSearch(locale)
+	if !localized {
+		highlights = search.HighlightProducts(products, terms, req.HighlightPreTag, req.HighlightPostTag)
+	}
+
+	err = s.repo.LocalizeProducts(ctx, products, locale)
+	if err != nil {
+		return nil, err
+	}
+	if localized {
+		highlights = search.HighlightProducts(products, terms, req.HighlightPreTag, req.HighlightPostTag)
+	}
+
+	return &pb.SearchProductsResponse{
+		Products:   products,
+		Total:      int32(len(products)),
+		Highlights: highlights,
+	}, nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/tracing"
+	"google.golang.org/grpc"
+)
+
+// StreamSearchProducts streams the products of a v1-style Cypher search a
+// chunk at a time, translating each chunk as it goes. It isn't run in a
+// read unit: translating in the search's own transaction would make the
+// driver buffer the rest of its results.
+func (s *V2Service) StreamSearchProducts(req *pbv2.StreamSearchProductsRequest, stream grpc.ServerStreamingServer[pbv2.StreamSearchProductsResponse]) error {
+	ctx := stream.Context()
+
+	if req.Query == "" {
+		return &repository.FieldError{Field: "query", Description: "query is required"}
+	}
+	mask, err := parseReadMask(req.ReadMask)
+	if err != nil {
+		return err
+	}
+	if err := auth.CheckAllTenants(ctx, "a Cypher query"); err != nil {
+		return err
+	}
+	tracing.Annotate(ctx, tracing.QueryHash.String(tracing.HashText(req.Query)))
+
+	return s.v1.repo.StreamSearchProducts(ctx, req.Query, pageSize(req.ChunkSize), func(products []*pb.Product) error {
+		if err := s.v1.repo.LocalizeProducts(ctx, products, req.Locale); err != nil {
+			return err
+		}
+		chunk := make([]*pbv2.Product, 0, len(products))
+		for _, p := range products {
+			product := productToV2(p)
+			mask.apply(product)
+			chunk = append(chunk, product)
+		}
+		return stream.Send(&pbv2.StreamSearchProductsResponse{Products: chunk})
+	})
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+func (s *V2Service) RegisterSellerWebhook(ctx context.Context, req *pbv2.RegisterSellerWebhookRequest) (*pbv2.RegisterSellerWebhookResponse, error) {
+
+	if err := s.actAsSeller(ctx, req.SellerId); err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.v1.repo.CreateSellerWebhook(ctx, req.SellerId, req.Url, req.Secret, req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbv2.RegisterSellerWebhookResponse{
+		Webhook: sellerWebhookToV2(*webhook),
+	}, nil
+}
+
+func (s *V2Service) ListSellerWebhooks(ctx context.Context, req *pbv2.ListSellerWebhooksRequest) (*pbv2.ListSellerWebhooksResponse, error) {
+
+	if err := s.actAsSeller(ctx, req.SellerId); err != nil {
+		return nil, err
+	}
+
+	webhooks, err := s.v1.repo.ListSellerWebhooks(ctx, req.SellerId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pbv2.ListSellerWebhooksResponse{
+		Webhooks: make([]*pbv2.SellerWebhook, 0, len(webhooks)),
+	}
+	for _, w := range webhooks {
+		resp.Webhooks = append(resp.Webhooks, sellerWebhookToV2(w))
+	}
+
+	return resp, nil
+}
+
+func (s *V2Service) DeleteSellerWebhook(ctx context.Context, req *pbv2.DeleteSellerWebhookRequest) (*pbv2.DeleteSellerWebhookResponse, error) {
+
+	if err := s.actAsSeller(ctx, req.SellerId); err != nil {
+		return nil, err
+	}
+
+	if err := s.v1.repo.DeleteSellerWebhook(ctx, req.SellerId, req.Id); err != nil {
+		return nil, err
+	}
+
+	return &pbv2.DeleteSellerWebhookResponse{}, nil
+}
+
+func (s *V2Service) ReportPolicyViolation(ctx context.Context, req *pbv2.ReportPolicyViolationRequest) (*pbv2.ReportPolicyViolationResponse, error) {
+
+	if err := s.v1.checkOwner(ctx, repository.SellerLabel, req.SellerId); err != nil {
+		return nil, err
+	}
+	v, err := s.v1.repo.RecordPolicyViolation(ctx, repository.PolicyViolation{
+		SellerID:   req.SellerId,
+		SKU:        req.Sku,
+		Policy:     req.Policy,
+		Details:    req.Details,
+		ReportedBy: requestedBy(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("audit: kind=policy_violation id=%s seller=%s policy=%q principal=%s", v.ID, v.SellerID, v.Policy, v.ReportedBy)
+
+	return &pbv2.ReportPolicyViolationResponse{
+		Id: v.ID,
+	}, nil
+}
+
+func sellerWebhookToV2(w repository.SellerWebhook) *pbv2.SellerWebhook {
+	return &pbv2.SellerWebhook{
+		Id:         w.ID,
+		SellerId:   w.SellerID,
+		Url:        w.URL,
+		EventTypes: w.EventTypes,
+		CreatedAt:  w.CreatedAt,
+	}
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// actAsSeller refuses callers bound to a seller other than sellerID, and
+// those confined to a tenant other than the seller's.
+func (s *V2Service) actAsSeller(ctx context.Context, sellerID string) error {
+	if principal, ok := auth.PrincipalFrom(ctx); ok && !principal.ActsFor(sellerID) {
+		log.Printf("audit: deny principal=%s seller=%q bound_seller=%q reason=other_seller", principal.Name, sellerID, principal.Seller)
+		return status.Errorf(codes.PermissionDenied, "%s can only manage seller %s", principal.Name, principal.Seller)
+	}
+	return s.v1.checkOwner(ctx, repository.SellerLabel, sellerID)
+}
+
+func (s *V2Service) CreateSeller(ctx context.Context, req *pbv2.CreateSellerRequest) (*pbv2.CreateSellerResponse, error) {
+
+	seller, err := s.v1.repo.CreateSeller(ctx, sellerFromV2(req.Seller))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbv2.CreateSellerResponse{
+		Seller: sellerToV2(*seller),
+	}, nil
+}
+
+func (s *V2Service) GetSeller(ctx context.Context, req *pbv2.GetSellerRequest) (*pbv2.GetSellerResponse, error) {
+
+	seller, err := s.v1.repo.GetSeller(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.CheckTenant(ctx, "seller", seller.ID, seller.TenantID); err != nil {
+		return nil, err
+	}
+
+	return &pbv2.GetSellerResponse{
+		Seller: sellerToV2(*seller),
+	}, nil
+}
+
+func (s *V2Service) ListSellers(ctx context.Context, req *pbv2.ListSellersRequest) (*pbv2.ListSellersResponse, error) {
+
+	scope := "sellers:" + req.TenantId
+
+	cursor, err := s.v1.decodePageToken(req.PageToken, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageSize(req.Limit)
+
+	sellers, err := s.v1.repo.ListSellers(ctx, req.TenantId, cursor.LastID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pbv2.ListSellersResponse{}
+	if len(sellers) > limit {
+		sellers = sellers[:limit]
+		resp.NextPageToken = s.v1.tokens.Encode(pagetoken.Cursor{
+			Scope:  scope,
+			LastID: sellers[limit-1].ID,
+		})
+	}
+	resp.Sellers = make([]*pbv2.Seller, 0, len(sellers))
+	for _, seller := range sellers {
+		resp.Sellers = append(resp.Sellers, sellerToV2(seller))
+	}
+
+	return resp, nil
+}
+
+// UpdateSeller lets callers bound to the seller change its name, but not
+// lift or impose a suspension.
+func (s *V2Service) UpdateSeller(ctx context.Context, req *pbv2.UpdateSellerRequest) (*pbv2.UpdateSellerResponse, error) {
+
+	update := sellerFromV2(req.Seller)
+	if err := s.actAsSeller(ctx, update.ID); err != nil {
+		return nil, err
+	}
+	if principal, ok := auth.PrincipalFrom(ctx); ok && principal.Seller != "" {
+		current, err := s.v1.repo.GetSeller(ctx, update.ID)
+		if err != nil {
+			return nil, err
+		}
+		if current.Suspended != update.Suspended {
+			log.Printf("audit: deny principal=%s seller=%q reason=suspension", principal.Name, update.ID)
+			return nil, status.Errorf(codes.PermissionDenied, "%s can't change the suspension of seller %s", principal.Name, update.ID)
+		}
+	}
+
+	seller, err := s.v1.repo.UpdateSeller(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbv2.UpdateSellerResponse{
+		Seller: sellerToV2(*seller),
+	}, nil
+}
+
+func (s *V2Service) DeleteSeller(ctx context.Context, req *pbv2.DeleteSellerRequest) (*pbv2.DeleteSellerResponse, error) {
+
+	if err := s.v1.checkOwner(ctx, repository.SellerLabel, req.Id); err != nil {
+		return nil, err
+	}
+	if err := s.v1.repo.DeleteSeller(ctx, req.Id); err != nil {
+		return nil, err
+	}
+	log.Printf("audit: kind=delete_seller id=%s principal=%s", req.Id, requestedBy(ctx))
+
+	return &pbv2.DeleteSellerResponse{}, nil
+}
+
+func (s *V2Service) SetOffer(ctx context.Context, req *pbv2.SetOfferRequest) (*pbv2.SetOfferResponse, error) {
+
+	o := req.GetOffer()
+	if err := s.actAsSeller(ctx, o.GetSellerId()); err != nil {
+		return nil, err
+	}
+
+	offer, err := s.v1.repo.SetOffer(ctx, repository.Offer{
+		SellerID: o.GetSellerId(),
+		SKU:      o.GetSku(),
+		Price:    o.GetPrice(),
+		Stock:    o.GetStock(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbv2.SetOfferResponse{
+		Offer: offerToV2(*offer),
+	}, nil
+}
+
+func (s *V2Service) DeleteOffer(ctx context.Context, req *pbv2.DeleteOfferRequest) (*pbv2.DeleteOfferResponse, error) {
+
+	if err := s.actAsSeller(ctx, req.SellerId); err != nil {
+		return nil, err
+	}
+
+	if err := s.v1.repo.DeleteOffer(ctx, req.SellerId, req.Sku); err != nil {
+		return nil, err
+	}
+
+	return &pbv2.DeleteOfferResponse{}, nil
+}
+
+func (s *V2Service) ListSellerOffers(ctx context.Context, req *pbv2.ListSellerOffersRequest) (*pbv2.ListSellerOffersResponse, error) {
+
+	if err := s.v1.checkOwner(ctx, repository.SellerLabel, req.SellerId); err != nil {
+		return nil, err
+	}
+	scope := "seller_offers:" + req.SellerId
+
+	cursor, err := s.v1.decodePageToken(req.PageToken, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageSize(req.Limit)
+
+	offers, err := s.v1.repo.ListSellerOffers(ctx, req.SellerId, cursor.LastID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pbv2.ListSellerOffersResponse{}
+	if len(offers) > limit {
+		offers = offers[:limit]
+		resp.NextPageToken = s.v1.tokens.Encode(pagetoken.Cursor{
+			Scope:  scope,
+			LastID: offers[limit-1].SKU,
+		})
+	}
+	resp.Offers = offersToV2(offers)
+
+	return resp, nil
+}
+
+func sellerFromV2(s *pbv2.Seller) repository.Seller {
+	return repository.Seller{
+		ID:        s.GetId(),
+		TenantID:  s.GetTenantId(),
+		Name:      s.GetName(),
+		Suspended: s.GetSuspended(),
+	}
+}
+
+func sellerToV2(s repository.Seller) *pbv2.Seller {
+	return &pbv2.Seller{
+		Id:        s.ID,
+		TenantId:  s.TenantID,
+		Name:      s.Name,
+		Suspended: s.Suspended,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+func offersToV2(offers []repository.Offer) []*pbv2.Offer {
+	out := make([]*pbv2.Offer, 0, len(offers))
+	for _, o := range offers {
+		out = append(out, offerToV2(o))
+	}
+	return out
+}
+
+func offerToV2(o repository.Offer) *pbv2.Offer {
+	return &pbv2.Offer{
+		SellerId:   o.SellerID,
+		SellerName: o.SellerName,
+		Sku:        o.SKU,
+		ProductId:  o.ProductID,
+		Price:      o.Price,
+		Stock:      o.Stock,
+		UpdatedAt:  o.UpdatedAt,
+	}
+}
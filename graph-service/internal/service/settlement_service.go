@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+)
+
+// settleOrder records what a placed order's marketplace lines earn their
+// sellers. The order stands even if this fails.
+func (s *ProductService) settleOrder(ctx context.Context, orderID string) {
+	if s.commission == nil {
+		return
+	}
+	if _, err := s.repo.RecordSettlement(ctx, orderID, s.commission); err != nil {
+		log.Printf("settlement: settle order %s: %v", orderID, err)
+	}
+}
+
+func (s *V2Service) GetSellerSettlementReport(ctx context.Context, req *pbv2.GetSellerSettlementReportRequest) (*pbv2.GetSellerSettlementReportResponse, error) {
+
+	if err := s.actAsSeller(ctx, req.SellerId); err != nil {
+		return nil, err
+	}
+
+	scope := fmt.Sprintf("settlement:%s:%d:%d", req.SellerId, req.From, req.To)
+
+	cursor, err := s.v1.decodePageToken(req.PageToken, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageSize(req.Limit)
+
+	report, err := s.v1.repo.SellerSettlementReport(ctx, req.SellerId, req.From, req.To, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pbv2.GetSellerSettlementReportResponse{}
+	entries := report.Entries
+	if len(entries) > limit {
+		entries = entries[:limit]
+		last := entries[limit-1]
+		resp.NextPageToken = s.v1.tokens.Encode(pagetoken.Cursor{
+			Scope:    scope,
+			LastID:   last.ID,
+			LastTime: last.CreatedAt,
+		})
+	}
+	for _, t := range report.Totals {
+		resp.Totals = append(resp.Totals, &pbv2.SettlementTotal{
+			Currency:   t.Currency,
+			Lines:      int32(t.Lines),
+			Gross:      t.Gross,
+			Commission: t.Commission,
+			Payout:     t.Payout,
+		})
+	}
+	resp.Entries = make([]*pbv2.SettlementEntry, 0, len(entries))
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, &pbv2.SettlementEntry{
+			OrderId:    e.OrderID,
+			Sku:        e.SKU,
+			Category:   e.Category,
+			Currency:   e.Currency,
+			Quantity:   e.Quantity,
+			Gross:      e.Gross,
+			Rate:       e.Rate,
+			Commission: e.Commission,
+			Payout:     e.Payout,
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/shipping"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *ProductService) EstimateShipping(ctx context.Context, req *pb.EstimateShippingRequest) (*pb.EstimateShippingResponse, error) {
+
+	if s.shipping == nil {
+		return nil, status.Error(codes.Unimplemented, "no shipping provider is configured")
+	}
+	if len(req.Lines) == 0 {
+		return nil, &repository.FieldError{Field: "lines", Description: "cart needs at least one line"}
+	}
+	dest := req.GetDestination()
+	if dest.GetCountry() == "" {
+		return nil, &repository.FieldError{Field: "destination.country", Description: "destination country is required"}
+	}
+
+	skus := make([]string, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		if line.GetSku() == "" || line.GetQuantity() <= 0 {
+			return nil, &repository.FieldError{Field: "lines", Description: "cart lines need a sku and a positive quantity"}
+		}
+		skus = append(skus, line.Sku)
+	}
+	profiles, err := s.repo.ShippingProfiles(ctx, skus)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]shipping.Item, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		profile, ok := profiles[line.Sku]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "sku %s is not sold by any product", line.Sku)
+		}
+		if profile.WeightGrams <= 0 {
+			return nil, status.Errorf(codes.FailedPrecondition, "the product selling sku %s has no shipping weight", line.Sku)
+		}
+		items = append(items, shipping.Item{
+			WeightGrams: int64(profile.WeightGrams),
+			LengthMM:    int64(profile.LengthMm),
+			WidthMM:     int64(profile.WidthMm),
+			HeightMM:    int64(profile.HeightMm),
+			Quantity:    int64(line.Quantity),
+		})
+	}
+
+	parcel := shipping.Pack(items)
+	rates, err := s.shipping.Rates(ctx, shipping.Shipment{
+		Parcel: parcel,
+		Destination: shipping.Address{
+			Country:    strings.ToUpper(dest.Country),
+			Region:     dest.Region,
+			PostalCode: dest.PostalCode,
+			City:       dest.City,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.EstimateShippingResponse{
+		BillableWeightGrams: int32(parcel.BillableGrams()),
+	}
+	for _, rate := range rates {
+		resp.Rates = append(resp.Rates, &pb.ShippingRate{
+			Carrier:  rate.Carrier,
+			Service:  rate.Service,
+			Amount:   float64(rate.Amount) / 100,
+			Currency: rate.Currency,
+			MinDays:  int32(rate.MinDays),
+			MaxDays:  int32(rate.MaxDays),
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/sizing"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *ProductService) UpsertSizeChart(ctx context.Context, req *pb.UpsertSizeChartRequest) (*pb.UpsertSizeChartResponse, error) {
+
+	err := s.repo.UpsertSizeChart(ctx, req.Chart)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UpsertSizeChartResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *ProductService) GetSizeChart(ctx context.Context, req *pb.GetSizeChartRequest) (*pb.GetSizeChartResponse, error) {
+
+	return inReadUnit(ctx, s.repo, func(ctx context.Context) (*pb.GetSizeChartResponse, error) {
+		return s.getSizeChart(ctx, req)
+	})
+}
+
+func (s *ProductService) getSizeChart(ctx context.Context, req *pb.GetSizeChartRequest) (*pb.GetSizeChartResponse, error) {
+	brand, mainCategory, subcategory := req.Brand, req.MainCategory, req.Subcategory
+	if req.ProductId != "" {
+		product, err := s.repo.GetProduct(ctx, req.ProductId)
+		if err != nil {
+			return nil, err
+		}
+		brand = product.Brand
+		mainCategory = product.GetCategory().GetMainCategory()
+		subcategory = product.GetCategory().GetSubcategory()
+	}
+
+	chart, err := s.repo.GetSizeChart(ctx, brand, mainCategory, subcategory)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetSizeChartResponse{
+		Chart: chart,
+	}, nil
+}
+
+func (s *ProductService) SetCustomerMeasurements(ctx context.Context, req *pb.SetCustomerMeasurementsRequest) (*pb.SetCustomerMeasurementsResponse, error) {
+
+	// Reject units the size helper can't convert before storing them
+	if _, err := sizing.Convert(0, req.Unit, "cm"); err != nil {
+		return nil, &repository.FieldError{Field: "unit", Description: err.Error()}
+	}
+
+	if err := s.checkShared(ctx, repository.CustomerLabel, req.CustomerId); err != nil {
+		return nil, err
+	}
+	err := s.repo.SetCustomerMeasurements(ctx, req.CustomerId, req.Unit, req.Measurements)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SetCustomerMeasurementsResponse{
+		Success: true,
+	}, nil
+}
+
+// RecommendSize maps the customer's stored measurements onto the size chart
+// for the product's brand and category. Customers without measurements get
+// their preferred size for the category, if the product comes in it.
+func (s *ProductService) RecommendSize(ctx context.Context, req *pb.RecommendSizeRequest) (*pb.RecommendSizeResponse, error) {
+
+	return inReadUnit(ctx, s.repo, func(ctx context.Context) (*pb.RecommendSizeResponse, error) {
+		return s.recommendSize(ctx, req)
+	})
+}
+
+func (s *ProductService) recommendSize(ctx context.Context, req *pb.RecommendSizeRequest) (*pb.RecommendSizeResponse, error) {
+	if err := s.checkProducts(ctx, []string{req.ProductId}); err != nil {
+		return nil, err
+	}
+	if err := s.checkShared(ctx, repository.CustomerLabel, req.CustomerId); err != nil {
+		return nil, err
+	}
+	product, err := s.repo.GetProduct(ctx, req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	measurements, unit, err := s.repo.GetCustomerMeasurements(ctx, req.CustomerId)
+	if err != nil {
+		return nil, err
+	}
+	if len(measurements) == 0 {
+		return s.recommendPreferredSize(ctx, req.CustomerId, product)
+	}
+
+	chart, err := s.repo.GetSizeChart(ctx, product.Brand, product.GetCategory().GetMainCategory(), product.GetCategory().GetSubcategory())
+	if err != nil {
+		return nil, err
+	}
+
+	// Only consider sizes the product is actually sold in
+	offered := make(map[string]*pb.ProductSize, len(product.Sizes))
+	for _, size := range product.Sizes {
+		offered[size.Size] = size
+	}
+	candidates := &pb.SizeChart{Unit: chart.Unit}
+	for _, row := range chart.Rows {
+		if _, ok := offered[row.Size]; ok {
+			candidates.Rows = append(candidates.Rows, row)
+		}
+	}
+
+	row, distance, ok, err := sizing.Closest(candidates, measurements, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.RecommendSizeResponse{
+		FitNotes: chart.FitNotes,
+	}
+	if ok {
+		resp.Size = row.Size
+		resp.Distance = distance
+		resp.InStock = offered[row.Size].InStock
+	}
+
+	return resp, nil
+}
+
+func (s *ProductService) recommendPreferredSize(ctx context.Context, customerID string, product *pb.Product) (*pb.RecommendSizeResponse, error) {
+	user, err := s.repo.GetUser(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	size := preferredSize(user.Preferences, product.Category)
+	if size == "" {
+		return nil, status.Error(codes.FailedPrecondition, "customer has no stored measurements or size preference")
+	}
+
+	resp := &pb.RecommendSizeResponse{}
+	for _, offered := range product.Sizes {
+		if strings.EqualFold(offered.Size, size) {
+			resp.Size = offered.Size
+			resp.InStock = offered.InStock
+			resp.FromPreference = true
+			break
+		}
+	}
+	return resp, nil
+}
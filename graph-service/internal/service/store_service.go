@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+const (
+	defaultPickupRadiusKm = 25
+	maxPickupRadiusKm     = 500
+)
+
+func (s *V2Service) UpsertStore(ctx context.Context, req *pbv2.UpsertStoreRequest) (*pbv2.UpsertStoreResponse, error) {
+
+	if req.Store == nil {
+		return nil, &repository.FieldError{Field: "store", Description: "store is required"}
+	}
+	if err := s.v1.checkOwner(ctx, repository.StoreLabel, req.Store.GetId()); err != nil {
+		return nil, err
+	}
+	store, err := s.v1.repo.UpsertStore(ctx, storeFromV2(req.Store))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbv2.UpsertStoreResponse{
+		Store: storeToV2(*store),
+	}, nil
+}
+
+func (s *V2Service) SetStoreStock(ctx context.Context, req *pbv2.SetStoreStockRequest) (*pbv2.SetStoreStockResponse, error) {
+
+	if err := s.v1.checkOwner(ctx, repository.StoreLabel, req.StoreId); err != nil {
+		return nil, err
+	}
+	stock := make([]repository.StoreStock, 0, len(req.Stock))
+	for _, st := range req.Stock {
+		stock = append(stock, repository.StoreStock{SKU: st.GetSku(), Stock: st.GetStock()})
+	}
+	if err := s.v1.repo.SetStoreStock(ctx, req.StoreId, stock); err != nil {
+		return nil, err
+	}
+
+	return &pbv2.SetStoreStockResponse{}, nil
+}
+
+func (s *V2Service) FindNearbyAvailability(ctx context.Context, req *pbv2.FindNearbyAvailabilityRequest) (*pbv2.FindNearbyAvailabilityResponse, error) {
+
+	radius, err := searchRadius(req.Latitude, req.Longitude, req.RadiusKm)
+	if err != nil {
+		return nil, err
+	}
+
+	stores, err := s.v1.repo.NearbyAvailability(ctx, req.ProductId, req.Latitude, req.Longitude, radius, pageSize(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pbv2.FindNearbyAvailabilityResponse{
+		Stores: make([]*pbv2.StoreAvailability, 0, len(stores)),
+	}
+	for _, st := range stores {
+		availability := &pbv2.StoreAvailability{
+			Store:      storeToV2(st.Store),
+			DistanceKm: st.Distance / 1000,
+		}
+		for _, stock := range st.Stock {
+			availability.Stock = append(availability.Stock, &pbv2.StoreStock{Sku: stock.SKU, Size: stock.Size, Stock: stock.Stock})
+		}
+		resp.Stores = append(resp.Stores, availability)
+	}
+
+	return resp, nil
+}
+
+// CheckCartPickup adds up lines of the same SKU, so a store needs stock
+// for all of them.
+func (s *V2Service) CheckCartPickup(ctx context.Context, req *pbv2.CheckCartPickupRequest) (*pbv2.CheckCartPickupResponse, error) {
+
+	radius, err := searchRadius(req.Latitude, req.Longitude, req.RadiusKm)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Lines) == 0 {
+		return nil, &repository.FieldError{Field: "lines", Description: "cart has no lines"}
+	}
+
+	var lines []repository.PickupLine
+	index := map[string]int{}
+	for i, line := range req.Lines {
+		if line.GetSku() == "" {
+			return nil, &repository.FieldError{Field: fmt.Sprintf("lines[%d].sku", i), Description: "sku is required"}
+		}
+		if line.GetQuantity() <= 0 {
+			return nil, &repository.FieldError{Field: fmt.Sprintf("lines[%d].quantity", i), Description: "quantity must be positive"}
+		}
+		if j, ok := index[line.Sku]; ok {
+			lines[j].Quantity += line.Quantity
+			continue
+		}
+		index[line.Sku] = len(lines)
+		lines = append(lines, repository.PickupLine{SKU: line.Sku, Quantity: line.Quantity})
+	}
+
+	stores, err := s.v1.repo.PickupStores(ctx, lines, req.Latitude, req.Longitude, radius, pageSize(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pbv2.CheckCartPickupResponse{
+		Options: make([]*pbv2.PickupOption, 0, len(stores)),
+	}
+	for _, st := range stores {
+		option := &pbv2.PickupOption{
+			Store:      storeToV2(st.Store),
+			DistanceKm: st.Distance / 1000,
+			Eligible:   len(st.Short) == 0,
+			ShortSkus:  st.Short,
+		}
+		resp.Eligible = resp.Eligible || option.Eligible
+		resp.Options = append(resp.Options, option)
+	}
+
+	return resp, nil
+}
+
+// searchRadius checks the point searched from and returns the radius in
+// meters, defaulting and capping radiusKm.
+func searchRadius(latitude, longitude, radiusKm float64) (float64, error) {
+	if latitude < -90 || latitude > 90 {
+		return 0, &repository.FieldError{Field: "latitude", Description: "latitude must be between -90 and 90"}
+	}
+	if longitude < -180 || longitude > 180 {
+		return 0, &repository.FieldError{Field: "longitude", Description: "longitude must be between -180 and 180"}
+	}
+	switch {
+	case radiusKm < 0:
+		return 0, &repository.FieldError{Field: "radius_km", Description: "radius cannot be negative"}
+	case radiusKm == 0:
+		radiusKm = defaultPickupRadiusKm
+	case radiusKm > maxPickupRadiusKm:
+		radiusKm = maxPickupRadiusKm
+	}
+	return radiusKm * 1000, nil
+}
+
+func storeFromV2(s *pbv2.Store) repository.Store {
+	return repository.Store{
+		ID:        s.GetId(),
+		TenantID:  s.GetTenantId(),
+		Name:      s.GetName(),
+		Address:   s.GetAddress(),
+		Latitude:  s.GetLatitude(),
+		Longitude: s.GetLongitude(),
+		Pickup:    s.GetPickup(),
+	}
+}
+
+func storeToV2(s repository.Store) *pbv2.Store {
+	return &pbv2.Store{
+		Id:        s.ID,
+		TenantId:  s.TenantID,
+		Name:      s.Name,
+		Address:   s.Address,
+		Latitude:  s.Latitude,
+		Longitude: s.Longitude,
+		Pickup:    s.Pickup,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/graphexport"
+)
+
+func (s *ProductService) ExportSubgraph(ctx context.Context, req *pb.ExportSubgraphRequest) (*pb.ExportSubgraphResponse, error) {
+
+	if err := s.checkProducts(ctx, []string{req.ProductId}); err != nil {
+		return nil, err
+	}
+	tenant, _ := auth.TenantFrom(ctx)
+	graph, err := s.repo.ExportSubgraph(ctx, tenant, req.ProductId, req.Category, int(req.Depth), int(req.MaxNodes))
+	if err != nil {
+		return nil, err
+	}
+
+	encode, contentType := graphexport.CytoscapeJSON, "application/json"
+	if req.Format == pb.GraphFormat_GRAPHML {
+		encode, contentType = graphexport.GraphML, "application/graphml+xml"
+	}
+	data, err := encode(graph)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ExportSubgraphResponse{
+		Data:        data,
+		ContentType: contentType,
+		NodeCount:   int32(len(graph.Nodes)),
+		EdgeCount:   int32(len(graph.Edges)),
+		Truncated:   graph.Truncated,
+	}, nil
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"log"
+	"slices"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+var submissionStatuses = map[string]pbv2.SubmissionStatus{
+	repository.SubmissionPendingApproval: pbv2.SubmissionStatus_SUBMISSION_PENDING_APPROVAL,
+	repository.SubmissionAmbiguous:       pbv2.SubmissionStatus_SUBMISSION_AMBIGUOUS,
+	repository.SubmissionMatched:         pbv2.SubmissionStatus_SUBMISSION_MATCHED,
+	repository.SubmissionCreated:         pbv2.SubmissionStatus_SUBMISSION_CREATED,
+	repository.SubmissionRejected:        pbv2.SubmissionStatus_SUBMISSION_REJECTED,
+}
+
+func (s *V2Service) SubmitProduct(ctx context.Context, req *pbv2.SubmitProductRequest) (*pbv2.SubmitProductResponse, error) {
+
+	if err := s.actAsSeller(ctx, req.SellerId); err != nil {
+		return nil, err
+	}
+	product, err := productToV1(req.Product)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := s.v1.repo.SubmitProduct(ctx, req.SellerId, product)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbv2.SubmitProductResponse{
+		Submission: submissionToV2(*sub),
+	}, nil
+}
+
+func (s *V2Service) GetSubmission(ctx context.Context, req *pbv2.GetSubmissionRequest) (*pbv2.GetSubmissionResponse, error) {
+
+	sub, err := s.v1.repo.GetSubmission(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.actAsSeller(ctx, sub.SellerID); err != nil {
+		return nil, err
+	}
+
+	return &pbv2.GetSubmissionResponse{
+		Submission: submissionToV2(*sub),
+	}, nil
+}
+
+func (s *V2Service) ListSubmissions(ctx context.Context, req *pbv2.ListSubmissionsRequest) (*pbv2.ListSubmissionsResponse, error) {
+
+	if err := s.actAsSeller(ctx, req.SellerId); err != nil {
+		return nil, err
+	}
+
+	var statuses []string
+	for status, value := range submissionStatuses {
+		if slices.Contains(req.Statuses, value) {
+			statuses = append(statuses, status)
+		}
+	}
+	slices.Sort(statuses)
+
+	scope := "submissions:" + req.TenantId + ":" + req.SellerId
+	for _, status := range statuses {
+		scope += ":" + status
+	}
+
+	cursor, err := s.v1.decodePageToken(req.PageToken, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageSize(req.Limit)
+
+	submissions, err := s.v1.repo.ListSubmissions(ctx, req.TenantId, req.SellerId, statuses, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pbv2.ListSubmissionsResponse{}
+	if len(submissions) > limit {
+		submissions = submissions[:limit]
+		last := submissions[limit-1]
+		resp.NextPageToken = s.v1.tokens.Encode(pagetoken.Cursor{
+			Scope:    scope,
+			LastID:   last.ID,
+			LastTime: last.CreatedAt,
+		})
+	}
+	resp.Submissions = make([]*pbv2.ProductSubmission, 0, len(submissions))
+	for _, sub := range submissions {
+		resp.Submissions = append(resp.Submissions, submissionToV2(sub))
+	}
+
+	return resp, nil
+}
+
+// ReviewSubmission creates the submitted product before settling the
+// submission, so a submission is never marked created without one.
+func (s *V2Service) ReviewSubmission(ctx context.Context, req *pbv2.ReviewSubmissionRequest) (*pbv2.ReviewSubmissionResponse, error) {
+
+	if err := s.v1.checkOwner(ctx, repository.SubmissionLabel, req.Id); err != nil {
+		return nil, err
+	}
+	if err := s.v1.checkProducts(ctx, []string{req.ProductId}); err != nil {
+		return nil, err
+	}
+	caller := requestedBy(ctx)
+	status, productID := repository.SubmissionMatched, req.ProductId
+	switch req.Decision {
+	case pbv2.ReviewDecision_REVIEW_REJECT:
+		status = repository.SubmissionRejected
+	case pbv2.ReviewDecision_REVIEW_CREATE:
+		sub, err := s.v1.repo.GetSubmission(ctx, req.Id)
+		if err != nil {
+			return nil, err
+		}
+		if sub.Status != repository.SubmissionAmbiguous && sub.Status != repository.SubmissionPendingApproval {
+			// Let the review report the transition
+			break
+		}
+		created, err := s.v1.CreateProduct(ctx, &pb.CreateProductRequest{Product: sub.Product})
+		if err != nil {
+			return nil, err
+		}
+		status, productID = repository.SubmissionCreated, created.Id
+	}
+
+	sub, err := s.v1.repo.ReviewSubmission(ctx, req.Id, status, productID, caller, req.Reason)
+	if err != nil {
+		if status == repository.SubmissionCreated {
+			log.Printf("submissions: settle submission %s after creating product %s: %v", req.Id, productID, err)
+		}
+		return nil, err
+	}
+	log.Printf("audit: kind=review_submission id=%s principal=%s status=%s product=%s", req.Id, caller, status, productID)
+
+	return &pbv2.ReviewSubmissionResponse{
+		Submission: submissionToV2(*sub),
+	}, nil
+}
+
+func submissionToV2(sub repository.Submission) *pbv2.ProductSubmission {
+	out := &pbv2.ProductSubmission{
+		Id:         sub.ID,
+		SellerId:   sub.SellerID,
+		TenantId:   sub.TenantID,
+		Status:     submissionStatuses[sub.Status],
+		Product:    productToV2(sub.Product),
+		ProductId:  sub.ProductID,
+		Reason:     sub.Reason,
+		ReviewedBy: sub.ReviewedBy,
+		CreatedAt:  sub.CreatedAt,
+		UpdatedAt:  sub.UpdatedAt,
+	}
+	for _, c := range sub.Candidates {
+		out.Candidates = append(out.Candidates, &pbv2.MappingCandidate{
+			ProductId: c.ProductID,
+			Name:      c.Name,
+			Score:     c.Score,
+			Reason:    c.Reason,
+		})
+	}
+	return out
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+)
+
+func (s *ProductService) ListSyncRuns(ctx context.Context, req *pb.ListSyncRunsRequest) (*pb.ListSyncRunsResponse, error) {
+
+	runs, err := s.repo.ListSyncRuns(ctx, req.Source, int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ListSyncRunsResponse{
+		Runs: runs,
+	}, nil
+}
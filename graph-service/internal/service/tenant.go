@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+)
+
+// checkProducts refuses a call confined to a tenant acting on another
+// tenant's products, named by id or by one of their SKUs. Products that
+// don't exist are left for the call itself to report.
+func (s *ProductService) checkProducts(ctx context.Context, ids []string, skus ...string) error {
+	if _, ok := auth.TenantFrom(ctx); !ok {
+		return nil
+	}
+	tenants, err := s.catalog.ProductTenants(ctx, ids, skus)
+	if err != nil {
+		return err
+	}
+	for id, tenant := range tenants {
+		if err := auth.CheckTenant(ctx, "product", id, tenant); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkOwner refuses a call confined to a tenant acting on another
+// tenant's node, the one labeled label (a repository label constant) with
+// id. A node that doesn't exist is left for the call to report.
+func (s *ProductService) checkOwner(ctx context.Context, label, id string) error {
+	if _, ok := auth.TenantFrom(ctx); !ok || id == "" {
+		return nil
+	}
+	tenant, found, err := s.repo.TenantOf(ctx, label, id)
+	if err != nil || !found {
+		return err
+	}
+	return auth.CheckTenant(ctx, strings.ToLower(label), id, tenant)
+}
+
+// checkShared is checkOwner for nodes that may belong to no tenant and
+// are then shared by all: customers known only from orders or
+// measurements until they register, and coupons good in every tenant.
+func (s *ProductService) checkShared(ctx context.Context, label, id string) error {
+	if _, ok := auth.TenantFrom(ctx); !ok || id == "" {
+		return nil
+	}
+	tenant, found, err := s.repo.TenantOf(ctx, label, id)
+	if err != nil || !found || tenant == "" {
+		return err
+	}
+	return auth.CheckTenant(ctx, strings.ToLower(label), id, tenant)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+func (s *ProductService) AppendTranscript(ctx context.Context, req *pb.AppendTranscriptRequest) (*pb.AppendTranscriptResponse, error) {
+
+	if err := s.checkOwner(ctx, repository.SessionLabel, req.SessionId); err != nil {
+		return nil, err
+	}
+	lastSeq, err := s.repo.AppendTranscript(ctx, req.SessionId, req.TenantId, req.CustomerId, req.Entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.AppendTranscriptResponse{
+		LastSeq: lastSeq,
+	}, nil
+}
+
+func (s *ProductService) GetSessionTranscript(ctx context.Context, req *pb.GetSessionTranscriptRequest) (*pb.GetSessionTranscriptResponse, error) {
+
+	if err := s.checkOwner(ctx, repository.SessionLabel, req.SessionId); err != nil {
+		return nil, err
+	}
+	scope := "transcript:" + req.SessionId
+
+	cursor, err := s.decodePageToken(req.PageToken, scope)
+	if err != nil {
+		return nil, err
+	}
+	afterSeq, _ := strconv.ParseInt(cursor.LastID, 10, 64)
+
+	limit := pageSize(req.Limit)
+
+	transcript, err := s.repo.GetSessionTranscript(ctx, req.SessionId, afterSeq, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetSessionTranscriptResponse{}
+	if len(transcript.Entries) > limit {
+		transcript.Entries = transcript.Entries[:limit]
+		resp.NextPageToken = s.tokens.Encode(pagetoken.Cursor{
+			Scope:  scope,
+			LastID: strconv.FormatInt(transcript.Entries[limit-1].Seq, 10),
+		})
+	}
+	resp.Transcript = transcript
+
+	return resp, nil
+}
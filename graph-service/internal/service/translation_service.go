@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+)
+
+func (s *ProductService) UpsertProductTranslation(ctx context.Context, req *pb.UpsertProductTranslationRequest) (*pb.UpsertProductTranslationResponse, error) {
+
+	if err := s.checkProducts(ctx, []string{req.ProductId}); err != nil {
+		return nil, err
+	}
+	err := s.catalog.UpsertProductTranslation(ctx, req.ProductId, req.Locale, req.Name, req.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UpsertProductTranslationResponse{
+		Success: true,
+	}, nil
+}
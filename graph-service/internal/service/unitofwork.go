@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+// inReadUnit runs fn in one read transaction, so the repository calls it
+// makes share a session and see the graph as of one moment.
+func inReadUnit[T any](ctx context.Context, repo *repository.ProductRepository, fn func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	err := repo.InReadTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+func (s *ProductService) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+
+	if req.User == nil {
+		return nil, &repository.FieldError{Field: "user", Description: "user is required"}
+	}
+	err := s.repo.CreateUser(ctx, req.User)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateUserResponse{
+		User: req.User,
+	}, nil
+}
+
+func (s *ProductService) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+
+	user, err := s.repo.GetUser(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.CheckTenant(ctx, "customer", user.Id, user.TenantId); err != nil {
+		return nil, err
+	}
+
+	return &pb.GetUserResponse{
+		User: user,
+	}, nil
+}
+
+func (s *ProductService) UpdatePreferences(ctx context.Context, req *pb.UpdatePreferencesRequest) (*pb.UpdatePreferencesResponse, error) {
+
+	if err := s.checkShared(ctx, repository.CustomerLabel, req.UserId); err != nil {
+		return nil, err
+	}
+	user, err := s.repo.UpdatePreferences(ctx, req.UserId, req.Preferences)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UpdatePreferencesResponse{
+		User: user,
+	}, nil
+}
+
+// userPreferences returns the user's preferences, or nil for an empty id,
+// a user the graph doesn't know or one of another tenant, who get
+// unpersonalized results.
+func (s *ProductService) userPreferences(ctx context.Context, userID string) (*pb.UserPreferences, error) {
+	if userID == "" {
+		return nil, nil
+	}
+	user, err := s.repo.GetUser(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tenant, ok := auth.TenantFrom(ctx); ok && user.TenantId != "" && user.TenantId != tenant {
+		return nil, nil
+	}
+	return user.Preferences, nil
+}
+
+// favorBrands stably moves products of the favorite brands to the front.
+func favorBrands(products []*pb.Product, brands []string) {
+	if len(brands) == 0 {
+		return
+	}
+	favorite := make(map[string]bool, len(brands))
+	for _, brand := range brands {
+		favorite[strings.ToLower(brand)] = true
+	}
+	slices.SortStableFunc(products, func(a, b *pb.Product) int {
+		af, bf := favorite[strings.ToLower(a.Brand)], favorite[strings.ToLower(b.Brand)]
+		switch {
+		case af && !bf:
+			return -1
+		case bf && !af:
+			return 1
+		}
+		return 0
+	})
+}
+
+// preferredSize returns the user's preferred size for the category, the
+// subcategory's over the main category's.
+func preferredSize(prefs *pb.UserPreferences, category *pb.ProductCategory) string {
+	if size := prefs.GetSizes()[category.GetSubcategory()]; size != "" {
+		return size
+	}
+	return prefs.GetSizes()[category.GetMainCategory()]
+}
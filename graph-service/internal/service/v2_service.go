@@ -0,0 +1,399 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/etag"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+// V2Service serves API v2 on top of the v1 implementation, converting
+// requests and responses between the two.
+type V2Service struct {
+	pbv2.UnimplementedGraphServiceServer
+	v1 *ProductService
+}
+
+func NewV2Service(v1 *ProductService) *V2Service {
+	return &V2Service{v1: v1}
+}
+
+// V2CatalogMethods are the v2 RPCs served entirely through the catalog, as
+// CatalogMethods are for v1.
+var V2CatalogMethods = []string{
+	pbv2.GraphService_CreateProduct_FullMethodName,
+	pbv2.GraphService_GetProduct_FullMethodName,
+	pbv2.GraphService_UpdateProduct_FullMethodName,
+	pbv2.GraphService_ListProducts_FullMethodName,
+}
+
+func (s *V2Service) CreateProduct(ctx context.Context, req *pbv2.CreateProductRequest) (*pbv2.CreateProductResponse, error) {
+
+	product, err := productToV1(req.Product)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.v1.CreateProduct(ctx, &pb.CreateProductRequest{Product: product})
+	if err != nil {
+		return nil, err
+	}
+
+	skus := make([]string, 0, len(product.Sizes))
+	for _, size := range product.Sizes {
+		skus = append(skus, size.Sku)
+	}
+	return &pbv2.CreateProductResponse{
+		Id:   resp.Id,
+		Skus: skus,
+	}, nil
+}
+
+// GetProduct tags the product with its questions and offers, which change
+// without it, so a client sending back the tag is answered not_modified
+// while any of them is unchanged.
+func (s *V2Service) GetProduct(ctx context.Context, req *pbv2.GetProductRequest) (*pbv2.GetProductResponse, error) {
+
+	mask, err := parseReadMask(req.ReadMask)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &pbv2.GetProductResponse{}
+	// Questions and offers are extras: the product is served without them
+	// when they can't be read
+	if s.v1.repo != nil {
+		questions, err := s.v1.repo.TopQuestions(ctx, req.Id, 0)
+		if err != nil {
+			log.Printf("questions: read top questions of product %s: %v", req.Id, err)
+		}
+		out.Questions = questionsToV2(questions)
+
+		offers, err := s.v1.repo.BuyBox(ctx, req.Id)
+		if err != nil {
+			log.Printf("marketplace: read buy box of product %s: %v", req.Id, err)
+		}
+		out.BuyBox = offersToV2(offers)
+	}
+
+	view := "v2:" + mask.key()
+	if etag.Conditional(ctx) {
+		if tag, ok := s.v1.stampTag(ctx, req.Id, req.Locale, view); ok && etag.Check(ctx, extrasTag(tag, out)) {
+			return &pbv2.GetProductResponse{NotModified: true}, nil
+		}
+	}
+
+	// v1 would tag its own response; the tag here is over more
+	read := etag.Ignore(mask.project(ctx, "tenant_id", "updated_at", "name", "description"))
+	resp, err := s.v1.GetProduct(read, &pb.GetProductRequest{Id: req.Id, Locale: req.Locale})
+	if err != nil {
+		return nil, err
+	}
+	if etag.Check(ctx, extrasTag(productTag(resp.Product, req.Locale, view), out)) {
+		return &pbv2.GetProductResponse{NotModified: true}, nil
+	}
+
+	out.Product = productToV2(resp.Product)
+	mask.apply(out.Product)
+	return out, nil
+}
+
+func (s *V2Service) UpdateProduct(ctx context.Context, req *pbv2.UpdateProductRequest) (*pbv2.UpdateProductResponse, error) {
+
+	product, err := productToV1(req.Product)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.v1.UpdateProduct(ctx, &pb.UpdateProductRequest{Product: product})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*pbv2.FieldChange, 0, len(resp.Changes))
+	for _, c := range resp.Changes {
+		changes = append(changes, &pbv2.FieldChange{Field: c.Field, OldValue: c.OldValue, NewValue: c.NewValue})
+	}
+	return &pbv2.UpdateProductResponse{
+		Changes: changes,
+	}, nil
+}
+
+// ListProducts lists through v1, with its page tokens, so a listing can
+// move between versions.
+func (s *V2Service) ListProducts(ctx context.Context, req *pbv2.ListProductsRequest) (*pbv2.ListProductsResponse, error) {
+
+	mask, err := parseReadMask(req.ReadMask)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.v1.ListProducts(mask.project(ctx), &pb.ListProductsRequest{
+		PageSize:       req.PageSize,
+		PageToken:      req.PageToken,
+		IncludeArchive: req.IncludeArchive,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]*pbv2.Product, 0, len(resp.Products))
+	for _, p := range resp.Products {
+		product := productToV2(p)
+		mask.apply(product)
+		products = append(products, product)
+	}
+	return &pbv2.ListProductsResponse{
+		Products:      products,
+		NextPageToken: resp.NextPageToken,
+	}, nil
+}
+
+// SearchProducts runs a v1 text search with the filter's tenant and
+// category, then applies the rest of the filter to the results. When
+// there is more to filter it fetches a full page first, so the limit is
+// met from what's left.
+func (s *V2Service) SearchProducts(ctx context.Context, req *pbv2.SearchProductsRequest) (*pbv2.SearchProductsResponse, error) {
+
+	if strings.TrimSpace(req.Text) == "" {
+		return nil, &repository.FieldError{Field: "text", Description: "search text is required"}
+	}
+
+	mask, err := parseReadMask(req.ReadMask)
+	if err != nil {
+		return nil, err
+	}
+
+	f := req.Filter
+	v1req := &pb.SearchProductsRequest{
+		Text:             req.Text,
+		Locale:           req.Locale,
+		Filter:           catalogFilter(f),
+		Limit:            req.Limit,
+		UserId:           req.UserId,
+		HighlightPreTag:  req.HighlightPreTag,
+		HighlightPostTag: req.HighlightPostTag,
+	}
+	narrowed := len(f.GetBrands()) > 0 || len(f.GetColors()) > 0 || len(f.GetSizes()) > 0 ||
+		f.GetMinPrice() > 0 || f.GetMaxPrice() > 0 || f.GetInStock()
+	if narrowed {
+		v1req.Limit = maxPageSize
+	}
+	// Filtering here and favoring the user's brands read fields the mask
+	// may leave out
+	var needed []string
+	if narrowed {
+		needed = append(needed, "brand", "color", "price", "sizes")
+	}
+	if req.UserId != "" {
+		needed = append(needed, "brand")
+	}
+	resp, err := s.v1.SearchProducts(mask.project(ctx, needed...), v1req)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageSize(req.Limit)
+	kept := make(map[string]bool)
+	products := make([]*pbv2.Product, 0, min(limit, len(resp.Products)))
+	for _, p := range resp.Products {
+		if len(products) == limit {
+			break
+		}
+		if narrowed && !matchesSearchFilter(p, f) {
+			continue
+		}
+		kept[p.Id] = true
+		product := productToV2(p)
+		mask.apply(product)
+		products = append(products, product)
+	}
+	var highlights []*pbv2.SearchHighlight
+	for _, h := range resp.Highlights {
+		if kept[h.ProductId] {
+			highlights = append(highlights, &pbv2.SearchHighlight{ProductId: h.ProductId, Field: h.Field, Fragments: h.Fragments})
+		}
+	}
+
+	return &pbv2.SearchProductsResponse{
+		Products:       products,
+		Total:          int32(len(products)),
+		Highlights:     highlights,
+		SuggestedQuery: resp.SuggestedQuery,
+	}, nil
+}
+
+// catalogFilter is the part of f that v1 searches filter on.
+func catalogFilter(f *pbv2.SearchFilter) *pb.CatalogFilter {
+	if f == nil {
+		return nil
+	}
+	return &pb.CatalogFilter{
+		TenantId:       f.TenantId,
+		MainCategory:   f.GetCategory().GetMainCategory(),
+		Subcategory:    f.GetCategory().GetSubcategory(),
+		SpecificType:   f.GetCategory().GetSpecificType(),
+		IncludeArchive: f.IncludeArchive,
+	}
+}
+
+// matchesSearchFilter applies the brands, colors, sizes, price range and
+// stock of f, which v1 searches don't filter on, to p.
+func matchesSearchFilter(p *pb.Product, f *pbv2.SearchFilter) bool {
+	if len(f.Brands) > 0 && !slices.ContainsFunc(f.Brands, func(b string) bool { return strings.EqualFold(b, p.Brand) }) {
+		return false
+	}
+	if len(f.Colors) > 0 && !slices.ContainsFunc(f.Colors, func(c string) bool { return strings.EqualFold(c, p.Color) }) {
+		return false
+	}
+	if f.MinPrice > 0 && p.Price < f.MinPrice {
+		return false
+	}
+	if f.MaxPrice > 0 && p.Price > f.MaxPrice {
+		return false
+	}
+	if len(f.Sizes) == 0 && !f.InStock {
+		return true
+	}
+	return slices.ContainsFunc(p.Sizes, func(size *pb.ProductSize) bool {
+		if f.InStock && !size.InStock {
+			return false
+		}
+		return len(f.Sizes) == 0 || slices.ContainsFunc(f.Sizes, func(s string) bool { return strings.EqualFold(s, size.Size) })
+	})
+}
+
+// productToV2 converts a v1 product. A size becomes a variant with a
+// "size" option; its variants written as "name=value" become options too,
+// and the others labels.
+func productToV2(p *pb.Product) *pbv2.Product {
+	if p == nil {
+		return nil
+	}
+	out := &pbv2.Product{
+		Id:              p.Id,
+		TenantId:        p.TenantId,
+		Name:            p.Name,
+		Brand:           p.Brand,
+		Color:           p.Color,
+		Price:           p.Price,
+		OriginalPrice:   p.OriginalPrice,
+		Tags:            p.Tags,
+		Attributes:      p.Attributes,
+		Description:     p.Description,
+		Images:          p.Images,
+		Slug:            p.Slug,
+		Gtin:            p.Gtin,
+		ExternalId:      p.ExternalId,
+		TaxClass:        p.TaxClass,
+		Archived:        p.Archived,
+		MetaTitle:       p.MetaTitle,
+		MetaDescription: p.MetaDescription,
+		Locale:          p.Locale,
+		CreatedAt:       p.CreatedAt,
+		UpdatedAt:       p.UpdatedAt,
+	}
+	if c := p.Category; c != nil {
+		out.Category = &pbv2.ProductCategory{MainCategory: c.MainCategory, Subcategory: c.Subcategory, SpecificType: c.SpecificType}
+	}
+	if sh := p.Shipping; sh != nil {
+		out.Shipping = &pbv2.ShippingProfile{WeightGrams: sh.WeightGrams, LengthMm: sh.LengthMm, WidthMm: sh.WidthMm, HeightMm: sh.HeightMm}
+	}
+	for _, size := range p.Sizes {
+		v := &pbv2.Variant{
+			Sku:       size.Sku,
+			Options:   map[string]string{},
+			Stock:     size.Stock,
+			InStock:   size.InStock,
+			CreatedAt: size.CreatedAt,
+			UpdatedAt: size.UpdatedAt,
+		}
+		if size.Size != "" {
+			v.Options["size"] = size.Size
+		}
+		for _, label := range size.Variants {
+			if name, value, ok := strings.Cut(label, "="); ok && name != "" {
+				v.Options[name] = value
+			} else {
+				v.Labels = append(v.Labels, label)
+			}
+		}
+		out.Variants = append(out.Variants, v)
+	}
+	return out
+}
+
+// productToV1 converts a v2 product the other way from productToV2:
+// options other than size are stored as "name=value" variants, sorted
+// by name.
+func productToV1(p *pbv2.Product) (*pb.Product, error) {
+	if p == nil {
+		return nil, &repository.FieldError{Field: "product", Description: "product is required"}
+	}
+	out := &pb.Product{
+		Id:              p.Id,
+		TenantId:        p.TenantId,
+		Name:            p.Name,
+		Brand:           p.Brand,
+		Color:           p.Color,
+		Price:           p.Price,
+		OriginalPrice:   p.OriginalPrice,
+		Tags:            p.Tags,
+		Attributes:      p.Attributes,
+		Description:     p.Description,
+		Images:          p.Images,
+		Slug:            p.Slug,
+		Gtin:            p.Gtin,
+		ExternalId:      p.ExternalId,
+		TaxClass:        p.TaxClass,
+		Archived:        p.Archived,
+		MetaTitle:       p.MetaTitle,
+		MetaDescription: p.MetaDescription,
+		Locale:          p.Locale,
+		CreatedAt:       p.CreatedAt,
+		UpdatedAt:       p.UpdatedAt,
+	}
+	if c := p.Category; c != nil {
+		out.Category = &pb.ProductCategory{MainCategory: c.MainCategory, Subcategory: c.Subcategory, SpecificType: c.SpecificType}
+	}
+	if sh := p.Shipping; sh != nil {
+		out.Shipping = &pb.ShippingProfile{WeightGrams: sh.WeightGrams, LengthMm: sh.LengthMm, WidthMm: sh.WidthMm, HeightMm: sh.HeightMm}
+	}
+	for i, v := range p.Variants {
+		size := &pb.ProductSize{
+			Sku:       v.Sku,
+			Size:      v.Options["size"],
+			Stock:     v.Stock,
+			InStock:   v.InStock,
+			Variants:  slices.Clone(v.Labels),
+			CreatedAt: v.CreatedAt,
+			UpdatedAt: v.UpdatedAt,
+		}
+		for _, label := range v.Labels {
+			if strings.Contains(label, "=") {
+				return nil, &repository.FieldError{
+					Field:       fmt.Sprintf("variants[%d].labels", i),
+					Description: fmt.Sprintf("label %q can't contain '='; use an option", label),
+				}
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(v.Options)) {
+			if name == "size" {
+				continue
+			}
+			if name == "" || strings.Contains(name, "=") {
+				return nil, &repository.FieldError{
+					Field:       fmt.Sprintf("variants[%d].options", i),
+					Description: fmt.Sprintf("option name %q must be non-empty and can't contain '='", name),
+				}
+			}
+			size.Variants = append(size.Variants, name+"="+v.Options[name])
+		}
+		out.Sizes = append(out.Sizes, size)
+	}
+	return out, nil
+}
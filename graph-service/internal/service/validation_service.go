@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+)
+
+func (s *ProductService) ValidateCatalog(ctx context.Context, req *pb.ValidateCatalogRequest) (*pb.ValidateCatalogResponse, error) {
+
+	return s.repo.ValidateCatalog(ctx, req.TenantId, int(req.SampleSize))
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+)
+
+// RegisterWebhook subscribes to events of every tenant, so it takes an
+// admin of all of them.
+func (s *ProductService) RegisterWebhook(ctx context.Context, req *pb.RegisterWebhookRequest) (*pb.RegisterWebhookResponse, error) {
+
+	if err := auth.CheckAllTenants(ctx, "registering a webhook"); err != nil {
+		return nil, err
+	}
+	id, err := s.repo.CreateWebhook(ctx, req.Url, req.Secret, req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RegisterWebhookResponse{
+		Id: id,
+	}, nil
+}
+
+func (s *ProductService) ListDeliveries(ctx context.Context, req *pb.ListDeliveriesRequest) (*pb.ListDeliveriesResponse, error) {
+
+	if err := auth.CheckAllTenants(ctx, "listing webhook deliveries"); err != nil {
+		return nil, err
+	}
+	scope := "deliveries:" + req.WebhookId + ":" + req.Status
+
+	cursor, err := s.decodePageToken(req.PageToken, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageSize(req.Limit)
+
+	deliveries, err := s.repo.ListDeliveries(ctx, req.WebhookId, req.Status, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListDeliveriesResponse{}
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+		last := deliveries[limit-1]
+		resp.NextPageToken = s.tokens.Encode(pagetoken.Cursor{
+			Scope:    scope,
+			LastID:   last.Id,
+			LastTime: last.CreatedAt,
+		})
+	}
+	resp.Deliveries = deliveries
+
+	return resp, nil
+}
@@ -0,0 +1,39 @@
+package shipping
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// FlatRate quotes a single service at a base price plus a price for every
+// started kilogram of billable weight. It stands in for a carrier
+// integration, or models a store's own delivery.
+type FlatRate struct {
+	Carrier  string
+	Service  string
+	Currency string
+	// Base and PerKg are in the currency's minor units.
+	Base  int64
+	PerKg int64
+	// Countries the service delivers to; empty means everywhere.
+	Countries []string
+	MinDays   int
+	MaxDays   int
+}
+
+func (f *FlatRate) Rates(ctx context.Context, s Shipment) ([]Rate, error) {
+	if len(f.Countries) > 0 && !slices.Contains(f.Countries, strings.ToUpper(s.Destination.Country)) {
+		return nil, nil
+	}
+
+	kilos := (s.Parcel.BillableGrams() + 999) / 1000
+	return []Rate{{
+		Carrier:  f.Carrier,
+		Service:  f.Service,
+		Amount:   f.Base + f.PerKg*kilos,
+		Currency: f.Currency,
+		MinDays:  f.MinDays,
+		MaxDays:  f.MaxDays,
+	}}, nil
+}
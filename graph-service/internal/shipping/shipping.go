@@ -0,0 +1,123 @@
+// Package shipping quotes delivering a cart through carrier rate
+// providers.
+package shipping
+
+import (
+	"cmp"
+	"context"
+	"slices"
+)
+
+// volumetricDivisor converts a parcel's volume in cubic centimetres to the
+// kilograms carriers bill bulky parcels at.
+const volumetricDivisor = 5000
+
+// Address is where a shipment goes. Country is an ISO 3166-1 alpha-2
+// code.
+type Address struct {
+	Country    string
+	Region     string
+	PostalCode string
+	City       string
+}
+
+// Item is a cart line's product as packed: weight in grams and dimensions
+// in millimetres, per unit.
+type Item struct {
+	WeightGrams int64
+	LengthMM    int64
+	WidthMM     int64
+	HeightMM    int64
+	Quantity    int64
+}
+
+// Parcel is a whole shipment packed into one box.
+type Parcel struct {
+	WeightGrams int64
+	LengthMM    int64
+	WidthMM     int64
+	HeightMM    int64
+}
+
+// Pack packs items into one parcel by stacking every unit on its smallest
+// side. That overestimates the box for mixed carts, which errs towards
+// quoting too much rather than too little.
+func Pack(items []Item) Parcel {
+	var p Parcel
+	for _, item := range items {
+		dims := []int64{item.LengthMM, item.WidthMM, item.HeightMM}
+		slices.Sort(dims)
+		p.WeightGrams += item.WeightGrams * item.Quantity
+		p.LengthMM = max(p.LengthMM, dims[2])
+		p.WidthMM = max(p.WidthMM, dims[1])
+		p.HeightMM += dims[0] * item.Quantity
+	}
+	return p
+}
+
+// BillableGrams is the weight carriers charge for: the actual weight or
+// the volumetric weight, whichever is greater.
+func (p Parcel) BillableGrams() int64 {
+	// mm³ → cm³ is /1000, and the divisor gives kg, so ×1000 for grams
+	volumetric := p.LengthMM * p.WidthMM * p.HeightMM / volumetricDivisor
+	return max(p.WeightGrams, volumetric)
+}
+
+// Shipment is what a provider is asked to quote.
+type Shipment struct {
+	Parcel      Parcel
+	Destination Address
+}
+
+// Rate is one carrier service's quote. Amount is in the currency's minor
+// units, e.g. cents.
+type Rate struct {
+	Carrier  string
+	Service  string
+	Amount   int64
+	Currency string
+	// Business days in transit.
+	MinDays int
+	MaxDays int
+}
+
+// Provider quotes shipments for one or more carriers.
+type Provider interface {
+	// Rates returns the provider's quotes for the shipment, which may be
+	// none if it doesn't serve the destination.
+	Rates(ctx context.Context, s Shipment) ([]Rate, error)
+}
+
+// Multi asks every provider and returns all their rates, cheapest first.
+// It fails only if every provider fails, so one carrier's outage doesn't
+// stop checkout.
+func Multi(providers ...Provider) Provider {
+	return multi(providers)
+}
+
+type multi []Provider
+
+func (m multi) Rates(ctx context.Context, s Shipment) ([]Rate, error) {
+	var rates []Rate
+	var firstErr error
+	failed := 0
+	for _, p := range m {
+		r, err := p.Rates(ctx, s)
+		if err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		rates = append(rates, r...)
+	}
+	if len(m) > 0 && failed == len(m) {
+		return nil, firstErr
+	}
+
+	slices.SortStableFunc(rates, func(a, b Rate) int {
+		return cmp.Compare(a.Amount, b.Amount)
+	})
+	return rates, nil
+}
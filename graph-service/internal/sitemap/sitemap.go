@@ -0,0 +1,197 @@
+// Package sitemap publishes sitemap.xml files listing the storefront pages
+// of published products and of the categories they are in, so search
+// engines find them. A Generator periodically renders them from the
+// catalog into blob storage.
+package sitemap
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/blob"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/slug"
+)
+
+const (
+	defaultInterval  = 6 * time.Hour
+	defaultBatchSize = 500
+	// maxURLs is the most URLs the protocol allows in one sitemap.
+	maxURLs = 50000
+	// KeyPrefix is where sitemaps are put in blob storage. The index is
+	// KeyPrefix + "sitemap.xml".
+	KeyPrefix = "sitemaps/"
+)
+
+// Store lists the catalog.
+type Store interface {
+	ListProducts(ctx context.Context, tenantID, afterID string, limit int, includeArchive bool) ([]*pb.Product, error)
+}
+
+// Generator periodically writes a sitemap index and the sitemaps it lists:
+// products-N.xml for product pages, and categories.xml for category pages
+// when CategoryURL is set. Archived products and products without a slug
+// are left out, as are categories without published products.
+type Generator struct {
+	store Store
+	blobs blob.Store
+
+	// ProductURL is a product page's URL, with {slug} filled in.
+	ProductURL string
+	// CategoryURL is a category page's URL, with {path} filled in by the
+	// slugs of its levels joined by "/", e.g. "shoes/running".
+	CategoryURL string
+	// BaseURL is where the storefront serves KeyPrefix, which the index
+	// links to, e.g. "https://shop.example.com/".
+	BaseURL string
+	// TenantID, if set, limits the sitemaps to the tenant's products.
+	TenantID string
+
+	Interval  time.Duration
+	BatchSize int
+}
+
+func NewGenerator(store Store, blobs blob.Store, productURL, baseURL string) *Generator {
+	return &Generator{
+		store:      store,
+		blobs:      blobs,
+		ProductURL: productURL,
+		BaseURL:    baseURL,
+		Interval:   defaultInterval,
+		BatchSize:  defaultBatchSize,
+	}
+}
+
+// Run writes the sitemaps until ctx is cancelled.
+func (g *Generator) Run(ctx context.Context) {
+	ticker := time.NewTicker(g.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := g.GenerateSitemap(ctx); err != nil {
+			log.Printf("sitemap: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type entry struct {
+	loc     string
+	lastmod int64
+}
+
+// GenerateSitemap reads the catalog and writes the sitemaps. Sitemaps
+// left from a larger catalog are no longer listed by the index.
+func (g *Generator) GenerateSitemap(ctx context.Context) error {
+	var products []entry
+	categories := map[string]int64{}
+	afterID := ""
+	for {
+		batch, err := g.store.ListProducts(ctx, g.TenantID, afterID, g.BatchSize, false)
+		if err != nil {
+			return err
+		}
+		for _, p := range batch {
+			if p.Archived || p.Slug == "" {
+				continue
+			}
+			products = append(products, entry{strings.ReplaceAll(g.ProductURL, "{slug}", p.Slug), p.UpdatedAt})
+			for _, path := range categoryPaths(p.GetCategory()) {
+				categories[path] = max(categories[path], p.UpdatedAt)
+			}
+		}
+		if len(batch) < g.BatchSize {
+			break
+		}
+		afterID = batch[len(batch)-1].Id
+	}
+
+	var sitemaps []entry
+	for i := 0; i == 0 || i*maxURLs < len(products); i++ {
+		page := products[i*maxURLs : min((i+1)*maxURLs, len(products))]
+		key := fmt.Sprintf("%sproducts-%d.xml", KeyPrefix, i+1)
+		if err := g.blobs.Put(ctx, key, urlset(page)); err != nil {
+			return err
+		}
+		sitemaps = append(sitemaps, entry{g.BaseURL + key, lastmod(page)})
+	}
+	if g.CategoryURL != "" {
+		var page []entry
+		for _, path := range slices.Sorted(maps.Keys(categories)) {
+			page = append(page, entry{strings.ReplaceAll(g.CategoryURL, "{path}", path), categories[path]})
+		}
+		// Catalogs have far fewer categories than a sitemap holds
+		page = page[:min(len(page), maxURLs)]
+		key := KeyPrefix + "categories.xml"
+		if err := g.blobs.Put(ctx, key, urlset(page)); err != nil {
+			return err
+		}
+		sitemaps = append(sitemaps, entry{g.BaseURL + key, lastmod(page)})
+	}
+
+	if err := g.blobs.Put(ctx, KeyPrefix+"sitemap.xml", index(sitemaps)); err != nil {
+		return err
+	}
+	log.Printf("sitemap: wrote %d products and %d categories in %d sitemaps", len(products), len(categories), len(sitemaps))
+	return nil
+}
+
+// categoryPaths returns the paths of the category and of its parents.
+func categoryPaths(c *pb.ProductCategory) []string {
+	var paths []string
+	path := ""
+	for _, level := range []string{c.GetMainCategory(), c.GetSubcategory(), c.GetSpecificType()} {
+		s := slug.Make(level)
+		if s == "" {
+			break
+		}
+		path = strings.TrimPrefix(path+"/"+s, "/")
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func lastmod(entries []entry) int64 {
+	var latest int64
+	for _, e := range entries {
+		latest = max(latest, e.lastmod)
+	}
+	return latest
+}
+
+func urlset(entries []entry) []byte {
+	return render("urlset", "url", entries)
+}
+
+func index(entries []entry) []byte {
+	return render("sitemapindex", "sitemap", entries)
+}
+
+func render(root, element string, entries []entry) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<` + root + ` xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n")
+	for _, e := range entries {
+		buf.WriteString("<" + element + "><loc>")
+		xml.EscapeText(&buf, []byte(e.loc))
+		buf.WriteString("</loc>")
+		if e.lastmod > 0 {
+			buf.WriteString("<lastmod>" + time.UnixMilli(e.lastmod).UTC().Format(time.RFC3339) + "</lastmod>")
+		}
+		buf.WriteString("</" + element + ">\n")
+	}
+	buf.WriteString("</" + root + ">\n")
+	return buf.Bytes()
+}
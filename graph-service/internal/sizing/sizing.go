@@ -0,0 +1,77 @@
+// Package sizing maps a customer's body measurements onto size chart rows.
+package sizing
+
+import (
+	"fmt"
+	"math"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+)
+
+const cmPerInch = 2.54
+
+// Convert converts a length between "cm" and "in". An empty unit is
+// treated as "cm".
+func Convert(v float64, from, to string) (float64, error) {
+	from, to = normalizeUnit(from), normalizeUnit(to)
+	for _, u := range []string{from, to} {
+		if u != "cm" && u != "in" {
+			return 0, fmt.Errorf("unsupported unit %q", u)
+		}
+	}
+	switch {
+	case from == to:
+		return v, nil
+	case from == "in":
+		return v * cmPerInch, nil
+	default:
+		return v / cmPerInch, nil
+	}
+}
+
+func normalizeUnit(u string) string {
+	if u == "" {
+		return "cm"
+	}
+	return u
+}
+
+// Closest returns the row nearest to the customer's measurements, scored by
+// the root-mean-square relative difference over the measurements both sides
+// have. Rows sharing no measurements with the customer are skipped; ok is
+// false if every row is skipped.
+func Closest(chart *pb.SizeChart, measurements map[string]float64, unit string) (row *pb.SizeChartRow, distance float64, ok bool, err error) {
+	customer := make(map[string]float64, len(measurements))
+	for name, v := range measurements {
+		converted, err := Convert(v, unit, chart.GetUnit())
+		if err != nil {
+			return nil, 0, false, err
+		}
+		customer[name] = converted
+	}
+
+	best := math.Inf(1)
+	for _, r := range chart.GetRows() {
+		var sum float64
+		var n int
+		for name, want := range r.Measurements {
+			have, found := customer[name]
+			if !found || want == 0 {
+				continue
+			}
+			diff := (have - want) / want
+			sum += diff * diff
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		if d := math.Sqrt(sum / float64(n)); d < best {
+			best, row = d, r
+		}
+	}
+	if row == nil {
+		return nil, 0, false, nil
+	}
+	return row, best, true, nil
+}
@@ -0,0 +1,102 @@
+// Package slug builds URL-friendly product handles such as
+// "air-max-90-nike".
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxBaseLen leaves room under the 128-character slug limit for a
+// collision suffix.
+const MaxBaseLen = 100
+
+// Make joins parts into a slug: accents are stripped, anything other than
+// ASCII letters and digits becomes a single hyphen, and the result is cut
+// at a word boundary to MaxBaseLen. A part already contained in the words
+// before it is skipped, so "Nike Air Max" by "Nike" stays "nike-air-max".
+func Make(parts ...string) string {
+	var words []string
+	for _, part := range parts {
+		partWords := split(part)
+		if len(partWords) == 0 || containsRun(words, partWords) {
+			continue
+		}
+		words = append(words, partWords...)
+	}
+
+	var b strings.Builder
+	for _, w := range words {
+		if b.Len() > 0 && b.Len()+1+len(w) > MaxBaseLen {
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		if len(w) > MaxBaseLen {
+			w = w[:MaxBaseLen]
+		}
+		b.WriteString(w)
+	}
+	return b.String()
+}
+
+// Next returns base, or base with the lowest numeric suffix from 2 up,
+// that is not in taken.
+func Next(base string, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, t := range taken {
+		used[t] = true
+	}
+	if !used[base] {
+		return base
+	}
+	for n := 2; ; n++ {
+		candidate := base + "-" + strconv.Itoa(n)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
+
+func split(s string) []string {
+	var words []string
+	var word strings.Builder
+	for _, r := range norm.NFKD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining accent left over from decomposition
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word.WriteRune(unicode.ToLower(r))
+		default:
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+		}
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// containsRun reports whether run appears as consecutive words in words.
+func containsRun(words, run []string) bool {
+	for i := 0; i+len(run) <= len(words); i++ {
+		match := true
+		for j := range run {
+			if words[i+j] != run[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
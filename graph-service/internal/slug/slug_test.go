@@ -0,0 +1,62 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	long := strings.Repeat("word ", 30)
+
+	tests := []struct {
+		name  string
+		parts []string
+		want  string
+	}{
+		{"name and brand", []string{"Air Max 90", "Nike"}, "air-max-90-nike"},
+		{"brand already in the name", []string{"Nike Air Max", "Nike"}, "nike-air-max"},
+		{"brand in the middle of the name", []string{"The Nike Air", "nike"}, "the-nike-air"},
+		{"brand only partly in the name", []string{"Nike Air", "Nike Sportswear"}, "nike-air-nike-sportswear"},
+		{"accents stripped", []string{"Crème Brûlée Candle"}, "creme-brulee-candle"},
+		{"punctuation collapsed", []string{"  T-Shirt (Men's) -- XL!! "}, "t-shirt-men-s-xl"},
+		{"non-latin dropped", []string{"Tシャツ Basic"}, "t-basic"},
+		{"compatibility forms", []string{"Ｆｕｌｌ ｗｉｄｔｈ ½"}, "full-width-1-2"},
+		{"empty parts skipped", []string{"", "Nike", "  "}, "nike"},
+		{"nothing usable", []string{"★ ☆"}, ""},
+		{"cut at a word boundary", []string{long}, strings.TrimSuffix(strings.Repeat("word-", 20), "-")},
+		{"one overlong word", []string{strings.Repeat("a", 150)}, strings.Repeat("a", MaxBaseLen)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Make(tt.parts...)
+			if got != tt.want {
+				t.Errorf("Make(%q) = %q, want %q", tt.parts, got, tt.want)
+			}
+			if len(got) > MaxBaseLen {
+				t.Errorf("Make(%q) is %d long, over %d", tt.parts, len(got), MaxBaseLen)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name  string
+		base  string
+		taken []string
+		want  string
+	}{
+		{"free", "air-max", nil, "air-max"},
+		{"free, others taken", "air-max", []string{"air-max-2"}, "air-max"},
+		{"taken", "air-max", []string{"air-max"}, "air-max-2"},
+		{"lowest free suffix", "air-max", []string{"air-max", "air-max-2", "air-max-4"}, "air-max-3"},
+		{"unordered", "air-max", []string{"air-max-3", "air-max-2", "air-max"}, "air-max-4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Next(tt.base, tt.taken); got != tt.want {
+				t.Errorf("Next(%q, %q) = %q, want %q", tt.base, tt.taken, got, tt.want)
+			}
+		})
+	}
+}
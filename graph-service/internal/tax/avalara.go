@@ -0,0 +1,116 @@
+package tax
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const avalaraAPI = "https://rest.avatax.com"
+
+// Avalara calculates tax with AvaTax. Quotes are SalesOrder transactions,
+// which AvaTax never records, so calculating is free of side effects.
+// Line classes are passed as Avalara tax codes.
+type Avalara struct {
+	accountID   string
+	licenseKey  string
+	companyCode string
+	origin      Address
+	client      *http.Client
+	baseURL     string
+}
+
+// NewAvalara calculates tax for the company on goods shipped from origin.
+func NewAvalara(accountID, licenseKey, companyCode string, origin Address) *Avalara {
+	return &Avalara{
+		accountID:   accountID,
+		licenseKey:  licenseKey,
+		companyCode: companyCode,
+		origin:      origin,
+		client:      &http.Client{Timeout: requestTimeout},
+		baseURL:     avalaraAPI,
+	}
+}
+
+type avalaraAddress struct {
+	City       string `json:"city,omitempty"`
+	Region     string `json:"region,omitempty"`
+	Country    string `json:"country"`
+	PostalCode string `json:"postalCode,omitempty"`
+}
+
+type avalaraLine struct {
+	Number   string  `json:"number"`
+	Quantity int64   `json:"quantity"`
+	Amount   float64 `json:"amount"`
+	TaxCode  string  `json:"taxCode,omitempty"`
+	ItemCode string  `json:"itemCode,omitempty"`
+}
+
+func (a *Avalara) Calculate(ctx context.Context, req Request) ([]LineTax, error) {
+	lines := make([]avalaraLine, 0, len(req.Lines))
+	for i, line := range req.Lines {
+		lines = append(lines, avalaraLine{
+			Number:   strconv.Itoa(i),
+			Quantity: line.Quantity,
+			Amount:   float64(line.Amount) / 100,
+			TaxCode:  line.Class,
+			ItemCode: line.ID,
+		})
+	}
+
+	body := map[string]any{
+		"type":         "SalesOrder",
+		"companyCode":  a.companyCode,
+		"date":         time.Now().UTC().Format(time.DateOnly),
+		"customerCode": "graph-service",
+		"currencyCode": req.Currency,
+		"addresses": map[string]avalaraAddress{
+			"shipFrom": avalaraAddressFrom(a.origin),
+			"shipTo":   avalaraAddressFrom(req.Destination),
+		},
+		"lines": lines,
+	}
+
+	var resp struct {
+		Lines []struct {
+			LineNumber string  `json:"lineNumber"`
+			Tax        float64 `json:"tax"`
+			Details    []struct {
+				Rate float64 `json:"rate"`
+			} `json:"details"`
+		} `json:"lines"`
+	}
+	err := postJSON(ctx, a.client, "avalara", a.baseURL+"/api/v2/transactions/create", func(r *http.Request) {
+		r.SetBasicAuth(a.accountID, a.licenseKey)
+	}, body, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	taxes := make([]LineTax, len(req.Lines))
+	for i, line := range req.Lines {
+		taxes[i].ID = line.ID
+	}
+	for _, line := range resp.Lines {
+		i, err := strconv.Atoi(line.LineNumber)
+		if err != nil || i < 0 || i >= len(taxes) {
+			continue
+		}
+		taxes[i].Amount = minorUnits(line.Tax)
+		for _, d := range line.Details {
+			taxes[i].Rate += d.Rate
+		}
+	}
+	return taxes, nil
+}
+
+func avalaraAddressFrom(a Address) avalaraAddress {
+	return avalaraAddress{
+		City:       a.City,
+		Region:     a.Region,
+		Country:    a.Country,
+		PostalCode: a.PostalCode,
+	}
+}
@@ -0,0 +1,43 @@
+package tax
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const requestTimeout = 15 * time.Second
+
+// postJSON sends in to url as JSON, with authorize adding credentials,
+// and decodes the response into out. provider names the API in errors.
+func postJSON(ctx context.Context, client *http.Client, provider, url string, authorize func(*http.Request), in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	authorize(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("%s: %w", provider, err)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %d %s", provider, resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return json.Unmarshal(respBody, out)
+}
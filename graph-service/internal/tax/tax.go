@@ -0,0 +1,79 @@
+// Package tax calculates sales tax on cart and order lines through a tax
+// provider.
+package tax
+
+import (
+	"context"
+	"math"
+)
+
+// Address is where the goods are delivered, which decides the tax owed.
+// Country is an ISO 3166-1 alpha-2 code.
+type Address struct {
+	Country    string
+	Region     string
+	PostalCode string
+	City       string
+}
+
+// Line is one taxable line. Amounts throughout are in the currency's
+// minor units, e.g. cents.
+type Line struct {
+	// ID identifies the line in the result, e.g. its SKU.
+	ID string
+	// Class is the product's tax class: a rate name for the flat provider
+	// or the provider's product tax code. Empty is the standard rate.
+	Class    string
+	Quantity int64
+	// Amount is the line total before tax.
+	Amount int64
+}
+
+// Request asks for the tax on lines shipped to Destination.
+type Request struct {
+	Currency    string
+	Destination Address
+	Lines       []Line
+}
+
+// LineTax is the tax owed on one line.
+type LineTax struct {
+	ID     string
+	Amount int64
+	// Rate is the combined rate applied, e.g. 0.0825.
+	Rate float64
+}
+
+// Provider calculates tax. Calculate returns one LineTax per request line,
+// in the same order.
+type Provider interface {
+	Calculate(ctx context.Context, req Request) ([]LineTax, error)
+}
+
+// Flat charges a fixed rate per tax class, wherever the goods go.
+type Flat struct {
+	// Rate applies to lines whose class has no rate in Classes.
+	Rate    float64
+	Classes map[string]float64
+}
+
+func (f *Flat) Calculate(ctx context.Context, req Request) ([]LineTax, error) {
+	taxes := make([]LineTax, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		rate, ok := f.Classes[line.Class]
+		if !ok {
+			rate = f.Rate
+		}
+		taxes = append(taxes, LineTax{
+			ID:     line.ID,
+			Amount: int64(math.Round(float64(line.Amount) * rate)),
+			Rate:   rate,
+		})
+	}
+	return taxes, nil
+}
+
+// minorUnits converts a provider's decimal amount to minor units.
+func minorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
@@ -0,0 +1,97 @@
+package tax
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+const taxJarAPI = "https://api.taxjar.com"
+
+// TaxJar calculates tax with TaxJar's SmartCalcs API. Line classes are
+// passed as TaxJar product tax codes. Destinations where the business has
+// no nexus owe no tax.
+type TaxJar struct {
+	apiKey  string
+	origin  Address
+	client  *http.Client
+	baseURL string
+}
+
+// NewTaxJar calculates tax on goods shipped from origin.
+func NewTaxJar(apiKey string, origin Address) *TaxJar {
+	return &TaxJar{
+		apiKey:  apiKey,
+		origin:  origin,
+		client:  &http.Client{Timeout: requestTimeout},
+		baseURL: taxJarAPI,
+	}
+}
+
+type taxJarLineItem struct {
+	ID             string  `json:"id"`
+	Quantity       int64   `json:"quantity"`
+	ProductTaxCode string  `json:"product_tax_code,omitempty"`
+	UnitPrice      float64 `json:"unit_price"`
+}
+
+func (t *TaxJar) Calculate(ctx context.Context, req Request) ([]LineTax, error) {
+	items := make([]taxJarLineItem, 0, len(req.Lines))
+	for i, line := range req.Lines {
+		items = append(items, taxJarLineItem{
+			// Line ids are positional so duplicate IDs can't collide
+			ID:             strconv.Itoa(i),
+			Quantity:       line.Quantity,
+			ProductTaxCode: line.Class,
+			UnitPrice:      float64(line.Amount) / 100 / float64(max(line.Quantity, 1)),
+		})
+	}
+
+	body := map[string]any{
+		"from_country": t.origin.Country,
+		"from_state":   t.origin.Region,
+		"from_zip":     t.origin.PostalCode,
+		"from_city":    t.origin.City,
+		"to_country":   req.Destination.Country,
+		"to_state":     req.Destination.Region,
+		"to_zip":       req.Destination.PostalCode,
+		"to_city":      req.Destination.City,
+		"shipping":     0,
+		"line_items":   items,
+	}
+
+	var resp struct {
+		Tax struct {
+			Breakdown *struct {
+				LineItems []struct {
+					ID              string  `json:"id"`
+					TaxCollectable  float64 `json:"tax_collectable"`
+					CombinedTaxRate float64 `json:"combined_tax_rate"`
+				} `json:"line_items"`
+			} `json:"breakdown"`
+		} `json:"tax"`
+	}
+	err := postJSON(ctx, t.client, "taxjar", t.baseURL+"/v2/taxes", func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}, body, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	taxes := make([]LineTax, len(req.Lines))
+	for i, line := range req.Lines {
+		taxes[i].ID = line.ID
+	}
+	if resp.Tax.Breakdown == nil {
+		return taxes, nil
+	}
+	for _, item := range resp.Tax.Breakdown.LineItems {
+		i, err := strconv.Atoi(item.ID)
+		if err != nil || i < 0 || i >= len(taxes) {
+			continue
+		}
+		taxes[i].Amount = minorUnits(item.TaxCollectable)
+		taxes[i].Rate = item.CombinedTaxRate
+	}
+	return taxes, nil
+}
@@ -0,0 +1,87 @@
+// Package tracing sets up OpenTelemetry tracing and the span attributes
+// shared by the orchestrator and graph-service.
+package tracing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/redact"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SessionHeader carries the voice session id from the orchestrator.
+const SessionHeader = "x-session-id"
+
+// Span attribute keys. The orchestrator uses the same names.
+const (
+	SessionID   = attribute.Key("session.id")
+	QueryHash   = attribute.Key("query.text_hash")
+	ResultCount = attribute.Key("result.count")
+)
+
+const instrumentation = "github.com/navi-prem/ecom-tts/graph-service"
+
+// Setup installs the W3C trace-context propagator and, when endpoint is
+// set, a tracer provider exporting to that OTLP/gRPC collector. Without an
+// endpoint incoming trace context is still honoured but nothing is
+// exported. The returned function flushes pending spans.
+func Setup(ctx context.Context, serviceName, endpoint string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracegrpc.New(ctx,
+		otlptracegrpc.WithEndpoint(endpoint),
+		otlptracegrpc.WithInsecure(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.AlwaysSample())),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the service's tracer from the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// HashText returns a short stable hash of query text, so spans can group
+// identical searches without recording what customers said.
+func HashText(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Annotate adds attributes to the span in ctx, if any, with email
+// addresses masked in string values.
+func Annotate(ctx context.Context, attrs ...attribute.KeyValue) {
+	for i, attr := range attrs {
+		if attr.Value.Type() == attribute.STRING {
+			attrs[i] = attr.Key.String(redact.String(attr.Value.AsString()))
+		}
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+}
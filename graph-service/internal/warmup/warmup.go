@@ -0,0 +1,100 @@
+// Package warmup readies a freshly started server for traffic, so the
+// first requests after a deploy don't pay for cold connections, caches
+// and indexes.
+package warmup
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+)
+
+const (
+	defaultProducts      = 100
+	defaultRetryInterval = 5 * time.Second
+)
+
+// Store is what a warm-up reads from.
+type Store interface {
+	VerifyConnectivity(ctx context.Context) error
+	PopularProductIDs(ctx context.Context, limit int) ([]string, error)
+	GetProduct(ctx context.Context, id string) (*pb.Product, error)
+	PrimeSearch(ctx context.Context) error
+}
+
+// Warmer runs the warm-up: it waits until the database answers, reads the
+// Products most ordered products, which fills the query cache if there
+// is one and the database's page cache either way, and runs a search to
+// load the fulltext index. Only connectivity is required; the other steps
+// log their failures and move on.
+type Warmer struct {
+	store Store
+
+	Products      int
+	RetryInterval time.Duration
+}
+
+func NewWarmer(store Store) *Warmer {
+	return &Warmer{
+		store:         store,
+		Products:      defaultProducts,
+		RetryInterval: defaultRetryInterval,
+	}
+}
+
+// Run warms up, then calls ready. It returns early, without calling
+// ready, if ctx is cancelled first.
+func (w *Warmer) Run(ctx context.Context, ready func()) {
+	start := time.Now()
+	for {
+		err := w.store.VerifyConnectivity(ctx)
+		if err == nil {
+			break
+		}
+		log.Printf("warmup: database not reachable, retrying in %s: %v", w.RetryInterval, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.RetryInterval):
+		}
+	}
+
+	preloaded := w.preload(ctx)
+
+	if err := w.store.PrimeSearch(ctx); err != nil && !errors.Is(err, repository.ErrUnsupported) {
+		log.Printf("warmup: prime search: %v", err)
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+	log.Printf("Warmed up in %s, with %d popular products preloaded", time.Since(start).Round(time.Millisecond), preloaded)
+	ready()
+}
+
+// preload reads the most popular products and returns how many it read.
+func (w *Warmer) preload(ctx context.Context) int {
+	if w.Products <= 0 {
+		return 0
+	}
+	ids, err := w.store.PopularProductIDs(ctx, w.Products)
+	if err != nil {
+		log.Printf("warmup: popular products: %v", err)
+		return 0
+	}
+	n := 0
+	for _, id := range ids {
+		if _, err := w.store.GetProduct(ctx, id); err != nil {
+			if !errors.Is(err, repository.ErrNotFound) {
+				log.Printf("warmup: preload %s: %v", id, err)
+			}
+			continue
+		}
+		n++
+	}
+	return n
+}
@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/egress"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/secretbox"
+)
+
+const (
+	defaultPollInterval = 2 * time.Second
+	defaultBatchSize    = 25
+	defaultLease        = 30 * time.Second
+	defaultMaxAttempts  = 8
+	defaultBaseBackoff  = 5 * time.Second
+	defaultMaxBackoff   = 30 * time.Minute
+	requestTimeout      = 10 * time.Second
+)
+
+// Store is the durable delivery queue the dispatcher drains.
+type Store interface {
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]repository.PendingDelivery, error)
+	MarkDeliverySucceeded(ctx context.Context, id string, statusCode int) error
+	MarkDeliveryFailed(ctx context.Context, id string, statusCode int, lastErr string, nextAttempt time.Time) error
+}
+
+// Dispatcher polls the delivery queue and POSTs signed payloads to subscribers,
+// retrying failures with exponential backoff until MaxAttempts is reached.
+// A claimed batch is delivered concurrently, so all of it is done well
+// within the lease however many endpoints are slow. Endpoints are only
+// dialed at public addresses.
+type Dispatcher struct {
+	store   Store
+	secrets *secretbox.Box
+	client  *http.Client
+
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+}
+
+// NewDispatcher returns a dispatcher that opens webhook secrets with secrets.
+func NewDispatcher(store Store, secrets *secretbox.Box) *Dispatcher {
+	return &Dispatcher{
+		store:        store,
+		secrets:      secrets,
+		client:       egress.NewClient(requestTimeout),
+		PollInterval: defaultPollInterval,
+		BatchSize:    defaultBatchSize,
+		MaxAttempts:  defaultMaxAttempts,
+		BaseBackoff:  defaultBaseBackoff,
+		MaxBackoff:   defaultMaxBackoff,
+	}
+}
+
+// Run drains the queue until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		batch, err := d.store.ClaimDueDeliveries(ctx, d.BatchSize, defaultLease)
+		if err != nil {
+			log.Printf("webhook: claim deliveries: %v", err)
+			return
+		}
+		if len(batch) == 0 {
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, pending := range batch {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.deliver(ctx, pending)
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, pending repository.PendingDelivery) {
+	delivery := pending.Delivery
+
+	statusCode, err := d.post(ctx, pending)
+	if err == nil {
+		if err := d.store.MarkDeliverySucceeded(ctx, delivery.Id, statusCode); err != nil {
+			log.Printf("webhook: mark delivery %s succeeded: %v", delivery.Id, err)
+		}
+		return
+	}
+
+	var next time.Time
+	if int(delivery.Attempts) < d.MaxAttempts {
+		next = time.Now().Add(d.backoff(int(delivery.Attempts)))
+	} else {
+		log.Printf("webhook: delivery %s to %s gave up after %d attempts: %v",
+			delivery.Id, pending.URL, delivery.Attempts, err)
+	}
+
+	if err := d.store.MarkDeliveryFailed(ctx, delivery.Id, statusCode, err.Error(), next); err != nil {
+		log.Printf("webhook: mark delivery %s failed: %v", delivery.Id, err)
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, pending repository.PendingDelivery) (int, error) {
+	body := []byte(pending.Delivery.Payload)
+
+	secret, err := d.secrets.Open(pending.Secret)
+	if err != nil {
+		return 0, fmt.Errorf("open webhook secret: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pending.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, pending.Delivery.EventType)
+	req.Header.Set(HeaderDelivery, pending.Delivery.Id)
+	req.Header.Set(HeaderSignature, Sign(secret, time.Now().Unix(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before retry number attempt (1-based).
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.BaseBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= d.MaxBackoff {
+			return d.MaxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Headers set on every delivery request.
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+// Sign computes the signature header value for a payload sent at timestamp
+// (unix seconds). Receivers recompute HMAC-SHA256 over "<timestamp>.<body>"
+// with their shared secret and compare it to v1.
+func Sign(secret string, timestamp int64, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac(secret, timestamp, body)))
+}
+
+// Verify checks a signature header produced by Sign.
+func Verify(secret, header string, body []byte) bool {
+	var timestamp int64
+	var sig []byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return false
+		}
+		switch key {
+		case "t":
+			ts, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return false
+			}
+			timestamp = ts
+		case "v1":
+			decoded, err := hex.DecodeString(value)
+			if err != nil {
+				return false
+			}
+			sig = decoded
+		}
+	}
+	if timestamp == 0 || sig == nil {
+		return false
+	}
+	return hmac.Equal(sig, mac(secret, timestamp, body))
+}
+
+func mac(secret string, timestamp int64, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
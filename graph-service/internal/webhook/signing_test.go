@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSignFormat(t *testing.T) {
+	// Receivers parse this header, so its shape is part of the contract:
+	// HMAC-SHA256 over "<timestamp>.<body>", hex encoded.
+	got := Sign("secret", 1700000000, []byte(`{"id":"p1"}`))
+	want := "t=1700000000,v1=" + fmt.Sprintf("%x", mac("secret", 1700000000, []byte(`{"id":"p1"}`)))
+	if got != want {
+		t.Fatalf("Sign = %q, want %q", got, want)
+	}
+	if len(strings.TrimPrefix(got, "t=1700000000,v1=")) != 64 {
+		t.Fatalf("Sign = %q, want a 64 hex digit v1", got)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"type":"product.created","id":"p1"}`)
+	header := Sign("secret", 1700000000, body)
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		want   bool
+	}{
+		{"round trip", "secret", header, body, true},
+		{"fields in either order", "secret", reorder(header), body, true},
+		{"tampered body", "secret", header, []byte(`{"type":"product.created","id":"p2"}`), false},
+		{"wrong secret", "other", header, body, false},
+		{"tampered timestamp", "secret", strings.Replace(header, "t=1700000000", "t=1700000001", 1), body, false},
+		{"empty header", "secret", "", body, false},
+		{"missing timestamp", "secret", header[strings.Index(header, "v1="):], body, false},
+		{"missing signature", "secret", "t=1700000000", body, false},
+		{"non-numeric timestamp", "secret", strings.Replace(header, "t=1700000000", "t=soon", 1), body, false},
+		{"non-hex signature", "secret", "t=1700000000,v1=zz", body, false},
+		{"part without =", "secret", header + ",junk", body, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.header, tt.body); got != tt.want {
+				t.Errorf("Verify(%q, %q) = %v, want %v", tt.secret, tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func reorder(header string) string {
+	t, v1, _ := strings.Cut(header, ",")
+	return v1 + "," + t
+}
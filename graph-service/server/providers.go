@@ -0,0 +1,261 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/navi-prem/ecom-tts/graph-service/internal/breaker"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/commission"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/config"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/graphdb"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/loyalty"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/payments"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/repository"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/risk"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/secretbox"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/shipping"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/tax"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// openStorage connects to the configured storage backend. The Neo4j
+// repository serves every feature and is returned as both values; other
+// backends only provide the catalog, and repo is nil.
+func openStorage(ctx context.Context, cfg config.Config, secrets *secretbox.Box) (repository.Repository, *repository.ProductRepository, func(), error) {
+	switch cfg.StorageBackend {
+	case "neo4j":
+		driver, opts, err := graphdb.Open(graphdb.SettingsFrom(cfg))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		opts = append(opts,
+			repository.WithBreaker(breaker.New("neo4j_breaker", cfg.BreakerThreshold, cfg.BreakerCooldown)),
+			repository.WithReservationTTL(cfg.ReservationTTL),
+			repository.WithQueryLimits(repository.QueryLimits{
+				MaxDepth:       cfg.QueryMaxDepth,
+				MaxRows:        cfg.QueryMaxRows,
+				MaxStreamRows:  cfg.QueryMaxStreamRows,
+				MaxResultBytes: cfg.QueryMaxResultBytes,
+				MaxCost:        cfg.QueryMaxCost,
+				MaxTraversals:  cfg.QueryMaxTraversals,
+			}),
+			repository.WithOverviewTTL(cfg.CatalogOverviewTTL),
+		)
+		var outfitCategories []string
+		for _, c := range strings.Split(cfg.OutfitCategories, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				outfitCategories = append(outfitCategories, c)
+			}
+		}
+		if len(outfitCategories) > 0 {
+			opts = append(opts, repository.WithOutfitCategories(outfitCategories))
+		}
+		if cfg.QueryCacheTTL > 0 {
+			opts = append(opts, repository.WithQueryCache(repository.NewQueryCache(cfg.QueryCacheTTL, cfg.QueryCacheMaxEntries)))
+		}
+		if secrets != nil {
+			opts = append(opts, repository.WithWebhookSecrets(secrets))
+		}
+		repo := repository.NewProductRepository(driver, opts...)
+		if cfg.GraphProfile != graphdb.ProfileNeo4j {
+			log.Printf("Using the %s graph backend profile", cfg.GraphProfile)
+		}
+		return repo, repo, func() { driver.Close(context.Background()) }, nil
+
+	case "postgres":
+		pool, err := pgxpool.New(ctx, cfg.PostgresURL)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		pg := repository.NewPostgresRepository(pool)
+		if err := pg.EnsureSchema(ctx); err != nil {
+			pool.Close()
+			return nil, nil, nil, fmt.Errorf("postgres schema: %w", err)
+		}
+		log.Printf("Using the postgres storage backend: only product operations are available")
+		return pg, nil, pool.Close, nil
+
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown STORAGE_BACKEND %q (want neo4j or postgres)", cfg.StorageBackend)
+	}
+}
+
+// webhookSecrets returns the box sealing webhook secrets, or nil when
+// webhooks are disabled.
+func webhookSecrets(cfg config.Config) (*secretbox.Box, error) {
+	if cfg.WebhookSecretKey == "" {
+		log.Printf("WEBHOOK_SECRET_KEY not set: webhooks are disabled")
+		return nil, nil
+	}
+	box, err := secretbox.New(cfg.WebhookSecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("WEBHOOK_SECRET_KEY: %w", err)
+	}
+	return box, nil
+}
+
+// paymentProvider returns the configured payment provider, or nil when
+// payments are disabled.
+func paymentProvider(cfg config.Config) (payments.Provider, error) {
+	switch cfg.PaymentProvider {
+	case "":
+		log.Printf("PAYMENT_PROVIDER not set: PlaceOrder is disabled")
+		return nil, nil
+	case "mock":
+		log.Printf("Using the mock payment provider: no payment is really taken")
+		return payments.NewMock(), nil
+	case "stripe":
+		if cfg.StripeSecretKey == "" {
+			return nil, fmt.Errorf("PAYMENT_PROVIDER=stripe needs STRIPE_SECRET_KEY")
+		}
+		return payments.NewStripe(cfg.StripeSecretKey), nil
+	default:
+		return nil, fmt.Errorf("unknown PAYMENT_PROVIDER %q (want stripe or mock)", cfg.PaymentProvider)
+	}
+}
+
+// shippingProvider returns the configured shipping provider, or nil when
+// shipping quotes are disabled.
+func shippingProvider(cfg config.Config) (shipping.Provider, error) {
+	switch cfg.ShippingProvider {
+	case "":
+		return nil, nil
+	case "flat":
+		var countries []string
+		for _, c := range strings.Split(cfg.ShippingCountries, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				countries = append(countries, strings.ToUpper(c))
+			}
+		}
+		return &shipping.FlatRate{
+			Carrier:   "flat",
+			Service:   "standard",
+			Currency:  strings.ToUpper(cfg.ShippingCurrency),
+			Base:      int64(cfg.ShippingFlatBase),
+			PerKg:     int64(cfg.ShippingFlatPerKg),
+			Countries: countries,
+			MinDays:   3,
+			MaxDays:   5,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown SHIPPING_PROVIDER %q (want flat)", cfg.ShippingProvider)
+	}
+}
+
+// riskScorer combines the built-in fraud heuristics with the external
+// scorer, if one is configured.
+func riskScorer(cfg config.Config, history risk.History) risk.Scorer {
+	scorers := risk.Multi{
+		risk.Velocity{
+			History: history,
+			Window:  cfg.RiskVelocityWindow,
+			Limit:   cfg.RiskVelocityLimit,
+			Weight:  cfg.RiskVelocityWeight,
+		},
+		risk.Region{Weight: cfg.RiskRegionWeight},
+	}
+	if cfg.RiskScorerURL != "" {
+		scorers = append(scorers, risk.NewHTTP(cfg.RiskScorerURL, cfg.RiskScorerToken))
+	}
+	return scorers
+}
+
+// loyaltyProgram returns the configured loyalty program, or nil when
+// orders earn no points.
+func loyaltyProgram(cfg config.Config) (*loyalty.Program, error) {
+	program := &loyalty.Program{Rate: cfg.LoyaltyEarnRate, Categories: map[string]float64{}}
+	for _, pair := range strings.Split(cfg.LoyaltyCategoryRates, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		category, rate, ok := strings.Cut(pair, "=")
+		r, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+		if !ok || err != nil || r < 0 {
+			return nil, fmt.Errorf("LOYALTY_CATEGORY_RATES: %q is not category=rate", pair)
+		}
+		program.Categories[strings.TrimSpace(category)] = r
+	}
+	for _, pair := range strings.Split(cfg.LoyaltyTiers, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, threshold, ok := strings.Cut(pair, "=")
+		t, err := strconv.ParseInt(strings.TrimSpace(threshold), 10, 64)
+		if !ok || err != nil || t <= 0 || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("LOYALTY_TIERS: %q is not name=points", pair)
+		}
+		program.Tiers = append(program.Tiers, loyalty.Tier{Name: strings.TrimSpace(name), Threshold: t})
+	}
+	if program.Rate == 0 && len(program.Categories) == 0 {
+		return nil, nil
+	}
+	return program, nil
+}
+
+func rateCard(cfg config.Config) (*commission.RateCard, error) {
+	card := &commission.RateCard{Rate: cfg.CommissionRate, Categories: map[string]float64{}}
+	if card.Rate < 0 || card.Rate > 1 {
+		return nil, fmt.Errorf("COMMISSION_RATE: %v is not between 0 and 1", card.Rate)
+	}
+	for _, pair := range strings.Split(cfg.CommissionCategoryRates, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		category, rate, ok := strings.Cut(pair, "=")
+		r, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+		if !ok || err != nil || r < 0 || r > 1 {
+			return nil, fmt.Errorf("COMMISSION_CATEGORY_RATES: %q is not category=rate with a rate from 0 to 1", pair)
+		}
+		card.Categories[strings.TrimSpace(category)] = r
+	}
+	return card, nil
+}
+
+// taxProvider returns the configured tax provider, or nil when no tax is
+// charged.
+func taxProvider(cfg config.Config) (tax.Provider, error) {
+	origin := tax.Address{
+		Country:    strings.ToUpper(cfg.TaxOriginCountry),
+		Region:     cfg.TaxOriginRegion,
+		PostalCode: cfg.TaxOriginPostal,
+		City:       cfg.TaxOriginCity,
+	}
+
+	switch cfg.TaxProvider {
+	case "":
+		return nil, nil
+	case "flat":
+		classes := map[string]float64{}
+		for _, pair := range strings.Split(cfg.TaxClassRates, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			class, rate, ok := strings.Cut(pair, "=")
+			r, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+			if !ok || err != nil || r < 0 {
+				return nil, fmt.Errorf("TAX_CLASS_RATES: %q is not class=rate", pair)
+			}
+			classes[strings.TrimSpace(class)] = r
+		}
+		return &tax.Flat{Rate: cfg.TaxRate, Classes: classes}, nil
+	case "taxjar":
+		if cfg.TaxJarAPIKey == "" {
+			return nil, fmt.Errorf("TAX_PROVIDER=taxjar needs TAXJAR_API_KEY")
+		}
+		return tax.NewTaxJar(cfg.TaxJarAPIKey, origin), nil
+	case "avalara":
+		if cfg.AvalaraAccountID == "" || cfg.AvalaraLicenseKey == "" || cfg.AvalaraCompanyCode == "" {
+			return nil, fmt.Errorf("TAX_PROVIDER=avalara needs AVALARA_ACCOUNT_ID, AVALARA_LICENSE_KEY and AVALARA_COMPANY_CODE")
+		}
+		if origin.Country == "" {
+			return nil, fmt.Errorf("TAX_PROVIDER=avalara needs TAX_ORIGIN_COUNTRY")
+		}
+		return tax.NewAvalara(cfg.AvalaraAccountID, cfg.AvalaraLicenseKey, cfg.AvalaraCompanyCode, origin), nil
+	default:
+		return nil, fmt.Errorf("unknown TAX_PROVIDER %q (want flat, taxjar or avalara)", cfg.TaxProvider)
+	}
+}
@@ -0,0 +1,462 @@
+// Package server assembles graph-service from its configuration: storage,
+// providers, background workers and the gRPC server serving both API
+// versions. The server binary runs it on a TCP port; other programs and
+// tests can embed it in-process instead:
+//
+//	srv, err := server.New(server.LoadConfig())
+//	...
+//	defer srv.Stop()
+//	conn, err := srv.StartInProcess()
+//	client := pb.NewGraphServiceClient(conn)
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/navi-prem/ecom-tts/graph-service/api"
+	pbv2 "github.com/navi-prem/ecom-tts/graph-service/api/v2"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/accesslog"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/alerts"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/apicompat"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/auth"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/blob"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/catalogsync"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/config"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/feeds"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/idgen"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/interceptors"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/metrics"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/moderation"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/notify"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/outbox"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/pagetoken"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/quota"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/reservations"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/risk"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/service"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/sitemap"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/tracing"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/warmup"
+	"github.com/navi-prem/ecom-tts/graph-service/internal/webhook"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/encoding/gzip" // accept and answer gzip-compressed calls
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Config is the server's configuration, as the binary reads it from the
+// environment.
+type Config = config.Config
+
+// LoadConfig reads the configuration from the environment, with the
+// defaults for anything unset.
+func LoadConfig() Config {
+	return config.Load()
+}
+
+// Server is a configured graph-service. New connects it to storage; Start
+// or Serve start its workers and serve RPCs until Stop.
+type Server struct {
+	// GRPC serves both API versions. Register more services on it before
+	// starting, if embedding needs them.
+	GRPC *grpc.Server
+
+	health  *health.Server
+	warmUp  func(ctx context.Context, ready func())
+	workers []func(context.Context)
+	servers []sideServer
+	closers []func()
+
+	cancel   context.CancelFunc
+	stopOnce sync.Once
+}
+
+// New builds the server cfg describes: it checks the API against its
+// baseline, connects to storage and sets up the providers, workers and
+// RPC handlers, but runs nothing yet.
+func New(cfg Config) (*Server, error) {
+	// Refuse to serve an API that would break released clients
+	if err := apicompat.Verify(); err != nil {
+		return nil, err
+	}
+
+	s := &Server{}
+	fail := func(err error) (*Server, error) {
+		s.close()
+		return nil, err
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "graph-service", cfg.OTLPEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	s.closers = append(s.closers, func() { shutdownTracing(context.Background()) })
+
+	secrets, err := webhookSecrets(cfg)
+	if err != nil {
+		return fail(err)
+	}
+
+	catalog, repo, closeStorage, err := openStorage(context.Background(), cfg, secrets)
+	if err != nil {
+		return fail(err)
+	}
+	s.closers = append(s.closers, closeStorage)
+
+	provider, err := paymentProvider(cfg)
+	if err != nil {
+		return fail(err)
+	}
+
+	shipper, err := shippingProvider(cfg)
+	if err != nil {
+		return fail(err)
+	}
+
+	taxes, err := taxProvider(cfg)
+	if err != nil {
+		return fail(err)
+	}
+
+	program, err := loyaltyProgram(cfg)
+	if err != nil {
+		return fail(err)
+	}
+
+	card, err := rateCard(cfg)
+	if err != nil {
+		return fail(err)
+	}
+
+	ids, err := idgen.New(cfg.IDGenerator, cfg.SnowflakeNode)
+	if err != nil {
+		return fail(fmt.Errorf("ID_GENERATOR: %w", err))
+	}
+
+	opts := []service.Option{
+		service.WithIDs(ids),
+		service.WithPayments(provider),
+		service.WithShipping(shipper),
+		service.WithTax(taxes),
+		service.WithLoyalty(program),
+	}
+	if repo != nil {
+		opts = append(opts, service.WithRisk(riskScorer(cfg, repo), risk.Policy{
+			ReviewAt:  cfg.RiskReviewThreshold,
+			DeclineAt: cfg.RiskDeclineThreshold,
+		}))
+		opts = append(opts, service.WithCommission(card))
+		opts = append(opts, service.WithSyncSettle(cfg.SyncSettle))
+		if cfg.ModerationURL != "" {
+			opts = append(opts, service.WithModeration(moderation.NewHTTP(cfg.ModerationURL, cfg.ModerationTimeout), cfg.ModerationAutoApprove))
+		}
+		if cfg.EnableTestReset {
+			log.Printf("ENABLE_TEST_RESET set: ResetAndSeed can wipe the graph")
+			opts = append(opts, service.WithTestReset())
+		}
+	}
+	productService := service.NewProductService(catalog, repo, pagetoken.NewCodec(cfg.PageTokenSecret), opts...)
+
+	if cfg.MetricsAddr != "" {
+		s.serveHTTP("Metrics on %s/debug/vars", cfg.MetricsAddr, metrics.Handler())
+	}
+
+	// The background workers all run on the graph
+	if repo != nil {
+		// Get connections, caches and indexes going before taking traffic
+		warmer := warmup.NewWarmer(repo)
+		warmer.Products = cfg.WarmUpProducts
+		s.warmUp = warmer.Run
+
+		// Relay committed outbox events into the webhook delivery queue
+		s.workers = append(s.workers, outbox.NewRelay(repo, outbox.PublisherFunc(repo.EnqueueEvent)).Run)
+
+		// Deliver queued catalog events to registered webhooks, first
+		// sealing any secrets stored before they were sealed
+		if secrets != nil {
+			dispatcher := webhook.NewDispatcher(repo, secrets)
+			s.workers = append(s.workers, func(ctx context.Context) {
+				if n, err := repo.SealWebhookSecrets(ctx); err != nil {
+					log.Printf("webhook: seal stored secrets: %v", err)
+				} else if n > 0 {
+					log.Printf("webhook: sealed %d stored secrets", n)
+				}
+				dispatcher.Run(ctx)
+			})
+		}
+
+		// Alert users whose saved searches match newly created products
+		notifier := notify.Multi(notify.Log{}, notify.NotifierFunc(repo.EnqueueNotification))
+		s.workers = append(s.workers, alerts.NewEvaluator(repo, notifier).Run)
+
+		// Alert subscribers when a watched price drops
+		s.workers = append(s.workers, alerts.NewPriceWatcher(repo, notifier).Run)
+
+		// Raise stock.low for sizes running low or forecast to sell out
+		lowStock := alerts.NewLowStockWatcher(repo)
+		lowStock.Threshold = int32(cfg.LowStockThreshold)
+		lowStock.Horizon = cfg.LowStockHorizon
+		s.workers = append(s.workers, lowStock.Run)
+
+		// Return stock held by checkouts that never completed
+		expirer := reservations.NewExpirer(repo)
+		expirer.Interval = cfg.ReservationSweepInterval
+		s.workers = append(s.workers, expirer.Run)
+
+		// Pull catalog changes from the external PIM, if one is configured
+		syncer, err := catalogsync.FromConfig(cfg, repo)
+		if err != nil {
+			return fail(err)
+		}
+		if syncer != nil {
+			log.Printf("Syncing the catalog from %s every %s", syncer.Source().Name(), syncer.Interval)
+			s.workers = append(s.workers, syncer.Run)
+
+			// Take Shopify's product webhooks between runs
+			if shop, ok := syncer.Source().(*catalogsync.Shopify); ok && cfg.SyncWebhookAddr != "" {
+				if cfg.ShopifyWebhookSecret == "" {
+					return fail(errors.New("SYNC_WEBHOOK_ADDR needs SHOPIFY_WEBHOOK_SECRET"))
+				}
+				mux := http.NewServeMux()
+				mux.Handle("/webhooks/shopify", catalogsync.NewShopifyWebhook(shop, syncer, cfg.ShopifyWebhookSecret))
+				s.serveHTTP("Shopify webhooks on %s/webhooks/shopify", cfg.SyncWebhookAddr, mux)
+			}
+		}
+	}
+
+	// Publish product feeds for shopping channels and sitemaps for search
+	// engines, if configured
+	blobs := blob.Dir(cfg.BlobDir)
+	if cfg.FeedsConfig != "" {
+		productFeeds, err := feeds.Load(cfg.FeedsConfig)
+		if err != nil {
+			return fail(err)
+		}
+		generator := feeds.NewGenerator(catalog, blobs, productFeeds)
+		generator.Interval = cfg.FeedsInterval
+		s.workers = append(s.workers, generator.Run)
+	}
+	if cfg.SitemapProductURL != "" {
+		baseURL := cfg.SitemapBaseURL
+		if baseURL == "" {
+			u, err := url.Parse(cfg.SitemapProductURL)
+			if err != nil {
+				return fail(fmt.Errorf("SITEMAP_PRODUCT_URL: %w", err))
+			}
+			baseURL = u.Scheme + "://" + u.Host
+		}
+		generator := sitemap.NewGenerator(catalog, blobs, cfg.SitemapProductURL, strings.TrimSuffix(baseURL, "/")+"/")
+		generator.CategoryURL = cfg.SitemapCategoryURL
+		generator.TenantID = cfg.SitemapTenant
+		generator.Interval = cfg.SitemapInterval
+		s.workers = append(s.workers, generator.Run)
+	}
+	if cfg.BlobAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/"+feeds.KeyPrefix, blob.Handler(blobs, feeds.KeyPrefix))
+		mux.Handle("/"+sitemap.KeyPrefix, blob.Handler(blobs, sitemap.KeyPrefix))
+		s.serveHTTP("Feeds and sitemaps on %s", cfg.BlobAddr, mux)
+	}
+
+	var unary []grpc.UnaryServerInterceptor
+	if cfg.AccessLogPath != "" {
+		sink, err := s.accessLogSink(cfg)
+		if err != nil {
+			return fail(err)
+		}
+		unary = append(unary, interceptors.AccessLog(accesslog.NewLogger(sink, cfg.AccessLogSampleRate)))
+	}
+	if cfg.AuthKeyring != "" {
+		keys, err := auth.LoadKeyring(cfg.AuthKeyring)
+		if err != nil {
+			return fail(err)
+		}
+		unary = append(unary, interceptors.Authorize(keys))
+	} else {
+		log.Printf("AUTH_KEYRING not set: authentication is disabled")
+	}
+	if repo == nil {
+		unary = append(unary, interceptors.Only(cfg.StorageBackend, append(service.CatalogMethods, service.V2CatalogMethods...)))
+	}
+	unary = append(unary, interceptors.Trace(), interceptors.Errors())
+	if repo != nil && (cfg.TenantMaxInFlight > 0 || cfg.TenantNeo4jBudget > 0) {
+		unary = append(unary, interceptors.Quota(quota.New("tenant_quota", quota.Limits{
+			MaxInFlight: cfg.TenantMaxInFlight,
+			QueueWait:   cfg.TenantQueueWait,
+			Budget:      cfg.TenantNeo4jBudget,
+			Window:      cfg.TenantQuotaWindow,
+		})))
+	}
+
+	// Health checks answer anyone, on any backend, whatever the quotas;
+	// so does reflection. Streams go through the same chain as unary calls
+	exempt := []string{
+		healthpb.Health_Check_FullMethodName,
+		healthpb.Health_Watch_FullMethodName,
+		reflectionv1.ServerReflection_ServerReflectionInfo_FullMethodName,
+		reflectionv1alpha.ServerReflection_ServerReflectionInfo_FullMethodName,
+	}
+	var stream []grpc.StreamServerInterceptor
+	for i, interceptor := range unary {
+		unary[i] = interceptors.Except(exempt, interceptor)
+		stream = append(stream, interceptors.Stream(unary[i]))
+	}
+	unary = append(unary, interceptors.Bookmarks(), interceptors.ETags())
+	stream = append(stream, interceptors.StreamBookmarks())
+
+	s.GRPC = grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+		grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(cfg.MaxSendMsgSize),
+	)
+
+	// v1 is frozen; v2 runs on it through conversion shims
+	pb.RegisterGraphServiceServer(s.GRPC, productService)
+	pbv2.RegisterGraphServiceServer(s.GRPC, service.NewV2Service(productService))
+
+	// Report NOT_SERVING until warmed up, and again once stopping
+	s.health = health.NewServer()
+	for _, name := range servingNames {
+		s.health.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	healthpb.RegisterHealthServer(s.GRPC, s.health)
+
+	// Enable gRPC reflection for grpcurl
+	if cfg.Reflection {
+		reflection.Register(s.GRPC)
+	}
+
+	return s, nil
+}
+
+// Start starts the workers and side HTTP servers, and serves RPCs on lis
+// in the background.
+func (s *Server) Start(lis net.Listener) {
+	s.run()
+	go func() {
+		if err := s.GRPC.Serve(lis); err != nil {
+			log.Printf("grpc server: %v", err)
+		}
+	}()
+}
+
+// Serve is Start, but serves RPCs on lis until Stop, when it returns nil.
+func (s *Server) Serve(lis net.Listener) error {
+	s.run()
+	return s.GRPC.Serve(lis)
+}
+
+// StartInProcess is Start on an in-memory listener, for embedding without
+// a network port; it returns a client connection to the server.
+func (s *Server) StartInProcess() (*grpc.ClientConn, error) {
+	lis := bufconn.Listen(1 << 20)
+	s.Start(lis)
+	return grpc.NewClient("passthrough:///graph-service",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+}
+
+// Stop lets RPCs in flight finish, then stops the workers and side
+// servers and disconnects from storage. It is safe to call more than once.
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		s.health.Shutdown()
+		s.GRPC.GracefulStop()
+		if s.cancel != nil {
+			s.cancel()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		for _, side := range s.servers {
+			side.server.Shutdown(ctx)
+		}
+		s.close()
+	})
+}
+
+func (s *Server) run() {
+	var ctx context.Context
+	ctx, s.cancel = context.WithCancel(context.Background())
+	if s.warmUp != nil {
+		go s.warmUp(ctx, s.ready)
+	} else {
+		s.ready()
+	}
+	for _, worker := range s.workers {
+		go worker(ctx)
+	}
+	for _, side := range s.servers {
+		go func() {
+			log.Printf(side.banner, side.server.Addr)
+			if err := side.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("http server on %s: %v", side.server.Addr, err)
+			}
+		}()
+	}
+}
+
+// servingNames are the names health checks report on: the server as a
+// whole and each API version.
+var servingNames = []string{"", pb.GraphService_ServiceDesc.ServiceName, pbv2.GraphService_ServiceDesc.ServiceName}
+
+// ready reports the server SERVING, unless it is already stopping.
+func (s *Server) ready() {
+	for _, name := range servingNames {
+		s.health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+}
+
+// sideServer is an HTTP server run beside the gRPC one, and the line it
+// logs, with its address, on starting.
+type sideServer struct {
+	server *http.Server
+	banner string
+}
+
+func (s *Server) serveHTTP(banner, addr string, handler http.Handler) {
+	s.servers = append(s.servers, sideServer{server: &http.Server{Addr: addr, Handler: handler}, banner: banner})
+}
+
+// accessLogSink opens the access log cfg names, closing it with the
+// server.
+func (s *Server) accessLogSink(cfg Config) (accesslog.Sink, error) {
+	if cfg.AccessLogPath == "-" {
+		return accesslog.Writer(os.Stdout), nil
+	}
+	file, err := accesslog.OpenFile(cfg.AccessLogPath, int64(cfg.AccessLogMaxBytes), cfg.AccessLogBackups)
+	if err != nil {
+		return nil, fmt.Errorf("ACCESS_LOG_PATH: %w", err)
+	}
+	s.closers = append(s.closers, func() { file.Close() })
+	return file, nil
+}
+
+// close releases what New acquired, most recent first.
+func (s *Server) close() {
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		s.closers[i]()
+	}
+	s.closers = nil
+}
@@ -1,26 +1,106 @@
+import collections
 import grpc
-from typing import List, Dict, Any, Optional
+from typing import List, Dict, Any, Optional, Tuple
 import logging
 import sys
 import os
+import threading
+import time
 
 sys.path.insert(0, os.path.dirname(os.path.abspath(__file__)))
 
 import graph_pb2
 import graph_pb2_grpc
+from resilience import (
+    CallCancelledError,
+    CircuitOpenError,
+    Deadline,
+    DeadlineExceededError,
+    FAILURE_CODES,
+    RETRYABLE_CODES,
+    backoff,
+    breaker_for,
+    hedged,
+)
+from app.tracing import QUERY_HASH, RESULT_COUNT, SESSION_ID, hash_text, tracer
 
 logger = logging.getLogger(__name__)
 
 
+class _CallDetails(
+    collections.namedtuple(
+        "_CallDetails",
+        ("method", "timeout", "metadata", "credentials", "wait_for_ready", "compression"),
+    ),
+    grpc.ClientCallDetails,
+):
+    pass
+
+
+class _HeadersInterceptor(grpc.UnaryUnaryClientInterceptor):
+    """Attaches fixed metadata, such as the API key, to every call."""
+
+    def __init__(self, headers: List[Tuple[str, str]]):
+        self.headers = headers
+
+    def intercept_unary_unary(self, continuation, client_call_details, request):
+        metadata = list(client_call_details.metadata or [])
+        metadata.extend(self.headers)
+        details = _CallDetails(
+            client_call_details.method,
+            client_call_details.timeout,
+            metadata,
+            client_call_details.credentials,
+            getattr(client_call_details, "wait_for_ready", None),
+            getattr(client_call_details, "compression", None),
+        )
+        return continuation(details, request)
+
+
 class GraphServiceClient:
-    def __init__(self, target: str = "localhost:50051"):
+    def __init__(
+        self,
+        target: str = "localhost:50051",
+        api_key: Optional[str] = None,
+        deadline: Optional[Deadline] = None,
+        session_id: Optional[str] = None,
+        tenant_id: Optional[str] = None,
+        env_key: bool = True,
+    ):
         self.target = target
+        # The service's own GRAPH_API_KEY stands in for a missing key unless
+        # env_key is off, for calls made on a caller's behalf with its key
+        self.api_key = api_key or (os.getenv("GRAPH_API_KEY") if env_key else None)
+        self.session_id = session_id
+        # The tenant the API key's role is checked against
+        self.tenant_id = tenant_id
         self.channel = None
         self.stub = None
+
+        # Futures of calls in flight, so cancel() can abandon them
+        self._lock = threading.Lock()
+        self._inflight = set()
+        self.cancelled = False
+
+        # Every call made through this client shares the caller's deadline
+        self.deadline = deadline
+        self.attempt_timeout = float(os.getenv("GRAPH_ATTEMPT_TIMEOUT", "2.0"))
+        self.max_attempts = int(os.getenv("GRAPH_MAX_ATTEMPTS", "3"))
+        self.hedge_delay = float(os.getenv("GRAPH_HEDGE_DELAY", "0.15"))
+        self.breaker = breaker_for(
+            target,
+            threshold=int(os.getenv("GRAPH_BREAKER_THRESHOLD", "5")),
+            cooldown=float(os.getenv("GRAPH_BREAKER_COOLDOWN", "10.0")),
+        )
     
     def connect(self):
         try:
             self.channel = grpc.insecure_channel(self.target)
+            headers = [("x-api-key", self.api_key)] if self.api_key else []
+            if self.tenant_id:
+                headers.append(("x-tenant-id", self.tenant_id))
+            if headers:
+                self.channel = grpc.intercept_channel(self.channel, _HeadersInterceptor(headers))
             self.stub = graph_pb2_grpc.GraphServiceStub(self.channel)
             return True
         except Exception as e:
@@ -39,6 +119,111 @@ class GraphServiceClient:
             logger.error(f"Graph service health check failed: {e}")
             return False
     
+    def cancel(self):
+        """Cancels the calls in flight and fails any made later.
+
+        The graph service sees the cancellation and stops its queries.
+        Safe to call from any thread.
+        """
+        with self._lock:
+            self.cancelled = True
+            inflight = list(self._inflight)
+        for future in inflight:
+            future.cancel()
+
+    def _track(self, future):
+        with self._lock:
+            if self.cancelled:
+                future.cancel()
+                return
+            self._inflight.add(future)
+        future.add_done_callback(self._untrack)
+
+    def _untrack(self, future):
+        with self._lock:
+            self._inflight.discard(future)
+
+    def _timeout(self) -> float:
+        if self.deadline is None:
+            return self.attempt_timeout
+        return self.deadline.timeout(self.attempt_timeout)
+
+    def _call(self, method: str, request, idempotent: bool = False, hedge: bool = False):
+        """Calls a GraphService method through the circuit breaker.
+
+        Idempotent calls are retried with jittered backoff on transient
+        errors; hedged calls also send a second copy if the first is slow.
+        Writes get a single attempt, since a timed-out write may have landed.
+        """
+        if not self.stub:
+            self.connect()
+        call = getattr(self.stub, method)
+        attempts = self.max_attempts if idempotent else 1
+        metadata = [("x-session-id", self.session_id)] if self.session_id else None
+
+        for attempt in range(1, attempts + 1):
+            if self.cancelled:
+                raise CallCancelledError(f"graph {method} cancelled")
+            timeout = self._timeout()
+            self.breaker.allow()
+            try:
+                if hedge:
+                    response = hedged(
+                        call, request, timeout=timeout, delay=self.hedge_delay, metadata=metadata, track=self._track
+                    )
+                else:
+                    future = call.future(request, timeout=timeout, metadata=metadata)
+                    self._track(future)
+                    response = future.result()
+            except grpc.FutureCancelledError:
+                self.breaker.abandon()
+                raise CallCancelledError(f"graph {method} cancelled")
+            except grpc.RpcError as e:
+                if e.code() == grpc.StatusCode.CANCELLED and self.cancelled:
+                    self.breaker.abandon()
+                    raise CallCancelledError(f"graph {method} cancelled")
+                code = e.code()
+                self.breaker.record(code in FAILURE_CODES)
+                if attempt == attempts or code not in RETRYABLE_CODES:
+                    raise
+                if self.deadline is not None and self.deadline.remaining() <= 0:
+                    raise
+                logger.warning(f"Graph {method} failed with {code.name}, retrying (attempt {attempt})")
+                time.sleep(backoff(attempt))
+                continue
+
+            self.breaker.record(False)
+            return response
+
+    def _product_to_dict(self, product) -> Dict[str, Any]:
+        return {
+            "id": product.id,
+            "name": product.name,
+            "brand": product.brand,
+            "price": product.price,
+            "original_price": product.original_price,
+            "color": product.color,
+            "description": product.description,
+            # Locale name and description are in; empty for the default
+            "locale": product.locale,
+            "category": {
+                "main_category": product.category.main_category,
+                "subcategory": product.category.subcategory,
+                "specific_type": product.category.specific_type
+            } if product.category else None,
+            "tags": list(product.tags),
+            "sizes": [
+                {
+                    "size": size.size,
+                    "stock": size.stock,
+                    "in_stock": size.in_stock,
+                    "sku": size.sku,
+                    "variants": list(size.variants)
+                }
+                for size in product.sizes
+            ]
+        }
+
     def _build_product(self, product_data: Dict[str, Any]) -> graph_pb2.Product:
         category_data = product_data.get("category", {})
         category = graph_pb2.ProductCategory(
@@ -75,55 +260,190 @@ class GraphServiceClient:
             images=product_data.get("images", [])
         )
     
-    def search_products(self, cypher_query: str) -> List[Dict[str, Any]]:
-        """Execute raw Cypher query on Graph Service."""
-        try:
-            if not self.stub:
-                self.connect()
-            
-            request = graph_pb2.SearchProductsRequest(query=cypher_query)
-            response = self.stub.SearchProducts(request)
-            
-            results = []
-            for product in response.products:
-                results.append({
-                    "id": product.id,
-                    "name": product.name,
-                    "brand": product.brand,
-                    "price": product.price,
-                    "original_price": product.original_price,
-                    "color": product.color,
-                    "description": product.description,
-                    "category": {
-                        "main_category": product.category.main_category,
-                        "subcategory": product.category.subcategory,
-                        "specific_type": product.category.specific_type
-                    } if product.category else None,
-                    "tags": list(product.tags),
-                    "sizes": [
-                        {
-                            "size": size.size,
-                            "stock": size.stock,
-                            "in_stock": size.in_stock,
-                            "sku": size.sku,
-                            "variants": list(size.variants)
-                        }
-                        for size in product.sizes
-                    ]
-                })
+    def search_products(self, cypher_query: str, locale: str = "") -> List[Dict[str, Any]]:
+        """Execute raw Cypher query on Graph Service, with product content in locale."""
+        with tracer.start_as_current_span("graph.search_products") as span:
+            span.set_attribute(QUERY_HASH, hash_text(cypher_query))
+            if self.session_id:
+                span.set_attribute(SESSION_ID, self.session_id)
+            results = self._search_products(cypher_query, locale)
+            span.set_attribute(RESULT_COUNT, len(results))
             return results
+
+    def _search_products(self, cypher_query: str, locale: str) -> List[Dict[str, Any]]:
+        try:
+            request = graph_pb2.SearchProductsRequest(query=cypher_query, locale=locale)
+            response = self._call("SearchProducts", request, idempotent=True, hedge=True)
+            return [self._product_to_dict(product) for product in response.products]
+        except CallCancelledError as e:
+            logger.info(f"Graph search abandoned: {e}")
+            return []
+        except (CircuitOpenError, DeadlineExceededError) as e:
+            logger.warning(f"Graph search skipped: {e}")
+            return []
         except Exception as e:
             logger.error(f"Graph search failed: {e}")
             return []
     
+    def search_text(self, text: str, locale: str = "", limit: int = 10) -> List[Dict[str, Any]]:
+        """Fulltext search for text, best match first, with product content
+        in locale. Unlike search_products, failures are raised."""
+        request = graph_pb2.SearchProductsRequest(
+            text=text,
+            locale=locale,
+            limit=limit,
+            filter=graph_pb2.CatalogFilter(tenant_id=self.tenant_id or ""),
+        )
+        response = self._call("SearchProducts", request, idempotent=True)
+        return [self._product_to_dict(product) for product in response.products]
+
+    def check_access(self) -> bool:
+        """Whether the API key may read the catalog, for the tenant if set.
+
+        Looks up a product that doesn't exist: the graph service checks the
+        key before finding nothing. Other failures are raised.
+        """
+        try:
+            self._call("GetProduct", graph_pb2.GetProductRequest(id=""), idempotent=True)
+        except grpc.RpcError as e:
+            if e.code() in (grpc.StatusCode.UNAUTHENTICATED, grpc.StatusCode.PERMISSION_DENIED):
+                return False
+            if e.code() != grpc.StatusCode.NOT_FOUND:
+                raise
+        return True
+
+    def get_product(self, product_id: str, locale: str = "") -> Optional[Dict[str, Any]]:
+        try:
+            request = graph_pb2.GetProductRequest(id=product_id, locale=locale)
+            response = self._call("GetProduct", request, idempotent=True, hedge=True)
+            return self._product_to_dict(response.product)
+        except grpc.RpcError as e:
+            if e.code() != grpc.StatusCode.NOT_FOUND:
+                logger.error(f"Failed to get product from graph: {e}")
+            return None
+        except Exception as e:
+            logger.error(f"Failed to get product from graph: {e}")
+            return None
+    
+    def price_cart(
+        self, lines: List[Tuple[str, int]], currency: str = "", destination: Optional[Dict[str, str]] = None
+    ) -> Dict[str, Any]:
+        """Prices (sku, quantity) lines with tax, as placing them would.
+
+        Raises grpc.RpcError when the graph service can't price them.
+        """
+        request = graph_pb2.PriceCartRequest(
+            lines=[graph_pb2.CartLine(sku=sku, quantity=quantity) for sku, quantity in lines],
+            currency=currency,
+            destination=graph_pb2.ShippingAddress(**destination) if destination else None,
+        )
+        response = self._call("PriceCart", request, idempotent=True)
+        return {
+            "currency": response.currency,
+            "subtotal": response.subtotal,
+            "discount": response.discount,
+            "tax": response.tax,
+            "total": response.total,
+        }
+
+    def place_order(
+        self,
+        order_id: str,
+        customer_id: str,
+        lines: List[Tuple[str, int]],
+        payment_method: str,
+        currency: str = "",
+        destination: Optional[Dict[str, str]] = None,
+        tenant_id: str = "",
+    ) -> Dict[str, Any]:
+        """Places an order for (sku, quantity) lines and takes payment.
+
+        Placing an order_id again fails rather than charging twice. Raises
+        grpc.RpcError when the order isn't placed, e.g. FAILED_PRECONDITION
+        for a declined payment.
+        """
+        request = graph_pb2.PlaceOrderRequest(
+            id=order_id,
+            tenant_id=tenant_id,
+            customer_id=customer_id,
+            lines=[graph_pb2.OrderLine(sku=sku, quantity=quantity) for sku, quantity in lines],
+            currency=currency,
+            payment_method=payment_method,
+            destination=graph_pb2.ShippingAddress(**destination) if destination else None,
+        )
+        order = self._call("PlaceOrder", request).order
+        return {
+            "id": order.id,
+            "status": graph_pb2.OrderStatus.Name(order.status),
+            "currency": order.currency,
+            "subtotal": order.subtotal,
+            "tax": order.tax,
+            "total": order.total,
+        }
+
+    def append_transcript(
+        self,
+        session_id: str,
+        entries: List[Dict[str, Any]],
+        tenant_id: str = "",
+        customer_id: str = "",
+    ) -> int:
+        """Appends entries ({kind, text, data, occurred_at}) to the session's
+        transcript and returns the seq of the last one.
+
+        Not retried, so a timed-out append never records entries twice.
+        Raises grpc.RpcError when they aren't appended.
+        """
+        request = graph_pb2.AppendTranscriptRequest(
+            session_id=session_id,
+            tenant_id=tenant_id,
+            customer_id=customer_id,
+            entries=[graph_pb2.TranscriptEntry(**entry) for entry in entries],
+        )
+        return self._call("AppendTranscript", request).last_seq
+
+    def get_session_transcript(self, session_id: str, page_size: int = 100) -> Dict[str, Any]:
+        """The session's whole transcript, read a page at a time.
+
+        Raises grpc.RpcError, NOT_FOUND when the session has none.
+        """
+        transcript: Dict[str, Any] = {}
+        entries: List[Dict[str, Any]] = []
+        page_token = ""
+        while True:
+            request = graph_pb2.GetSessionTranscriptRequest(
+                session_id=session_id, limit=page_size, page_token=page_token
+            )
+            response = self._call("GetSessionTranscript", request, idempotent=True)
+            page = response.transcript
+            transcript = {
+                "session_id": page.session_id,
+                "tenant_id": page.tenant_id,
+                "customer_id": page.customer_id,
+                "started_at": page.started_at,
+                "updated_at": page.updated_at,
+            }
+            entries.extend(
+                {
+                    "seq": entry.seq,
+                    "kind": entry.kind,
+                    "text": entry.text,
+                    "data": entry.data,
+                    "occurred_at": entry.occurred_at,
+                }
+                for entry in page.entries
+            )
+            page_token = response.next_page_token
+            if not page_token:
+                break
+        transcript["entries"] = entries
+        return transcript
+
     def create_product(self, product_data: Dict[str, Any]) -> Optional[str]:
         try:
-            if not self.stub:
-                self.connect()
-            
             product = self._build_product(product_data)
             request = graph_pb2.CreateProductRequest(product=product)
-            response = self.stub.CreateProduct(request)
+            response = self._call("CreateProduct", request)
             return response.id
         except Exception as e:
             logger.error(f"Failed to create product in graph: {e}")
@@ -24,7 +24,7 @@ _sym_db = _symbol_database.Default()
 
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0bgraph.proto\x12\x05graph\"T\n\x0fProductCategory\x12\x15\n\rmain_category\x18\x01 \x01(\t\x12\x13\n\x0bsubcategory\x18\x02 \x01(\t\x12\x15\n\rspecific_type\x18\x03 \x01(\t\"[\n\x0bProductSize\x12\x0c\n\x04size\x18\x01 \x01(\t\x12\r\n\x05stock\x18\x02 \x01(\x05\x12\x10\n\x08in_stock\x18\x03 \x01(\x08\x12\x10\n\x08variants\x18\x04 \x03(\t\x12\x0b\n\x03sku\x18\x05 \x01(\t\"\xcf\x02\n\x07Product\x12\n\n\x02id\x18\x01 \x01(\t\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\r\n\x05\x62rand\x18\x03 \x01(\t\x12(\n\x08\x63\x61tegory\x18\x04 \x01(\x0b\x32\x16.graph.ProductCategory\x12\r\n\x05\x63olor\x18\x05 \x01(\t\x12\r\n\x05price\x18\x06 \x01(\x01\x12\x16\n\x0eoriginal_price\x18\x07 \x01(\x01\x12!\n\x05sizes\x18\x08 \x03(\x0b\x32\x12.graph.ProductSize\x12\x0c\n\x04tags\x18\t \x03(\t\x12\x32\n\nattributes\x18\n \x03(\x0b\x32\x1e.graph.Product.AttributesEntry\x12\x13\n\x0b\x64\x65scription\x18\x0b \x01(\t\x12\x0e\n\x06images\x18\x0c \x03(\t\x1a\x31\n\x0f\x41ttributesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"7\n\x14\x43reateProductRequest\x12\x1f\n\x07product\x18\x01 \x01(\x0b\x32\x0e.graph.Product\"#\n\x15\x43reateProductResponse\x12\n\n\x02id\x18\x01 \x01(\t\"\x1f\n\x11GetProductRequest\x12\n\n\x02id\x18\x01 \x01(\t\"5\n\x12GetProductResponse\x12\x1f\n\x07product\x18\x01 \x01(\x0b\x32\x0e.graph.Product\"7\n\x14UpdateProductRequest\x12\x1f\n\x07product\x18\x01 \x01(\x0b\x32\x0e.graph.Product\"(\n\x15UpdateProductResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"H\n\x12UpdateStockRequest\x12\x12\n\nproduct_id\x18\x01 \x01(\t\x12\x0b\n\x03sku\x18\x02 \x01(\t\x12\x11\n\tnew_stock\x18\x03 \x01(\x05\"&\n\x13UpdateStockResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"\"\n\x14\x44\x65leteProductRequest\x12\n\n\x02id\x18\x01 \x01(\t\"(\n\x15\x44\x65leteProductResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"&\n\x15SearchProductsRequest\x12\r\n\x05query\x18\x01 \x01(\t\"I\n\x16SearchProductsResponse\x12 \n\x08products\x18\x01 \x03(\x0b\x32\x0e.graph.Product\x12\r\n\x05total\x18\x02 \x01(\x05\x32\xca\x03\n\x0cGraphService\x12J\n\rCreateProduct\x12\x1b.graph.CreateProductRequest\x1a\x1c.graph.CreateProductResponse\x12\x41\n\nGetProduct\x12\x18.graph.GetProductRequest\x1a\x19.graph.GetProductResponse\x12J\n\rUpdateProduct\x12\x1b.graph.UpdateProductRequest\x1a\x1c.graph.UpdateProductResponse\x12J\n\rDeleteProduct\x12\x1b.graph.DeleteProductRequest\x1a\x1c.graph.DeleteProductResponse\x12\x44\n\x0bUpdateStock\x12\x19.graph.UpdateStockRequest\x1a\x1a.graph.UpdateStockResponse\x12M\n\x0eSearchProducts\x12\x1c.graph.SearchProductsRequest\x1a\x1d.graph.SearchProductsResponseB5Z3github.com/navi-prem/ecom-tts/graph-service/api;apib\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0bgraph.proto\x12\x05graph\"T\n\x0fProductCategory\x12\x15\n\rmain_category\x18\x01 \x01(\t\x12\x13\n\x0bsubcategory\x18\x02 \x01(\t\x12\x15\n\rspecific_type\x18\x03 \x01(\t\"\x83\x01\n\x0bProductSize\x12\x0c\n\x04size\x18\x01 \x01(\t\x12\r\n\x05stock\x18\x02 \x01(\x05\x12\x10\n\x08in_stock\x18\x03 \x01(\x08\x12\x10\n\x08variants\x18\x04 \x03(\t\x12\x0b\n\x03sku\x18\x05 \x01(\t\x12\x12\n\ncreated_at\x18\x06 \x01(\x03\x12\x12\n\nupdated_at\x18\x07 \x01(\x03\"\xc8\x04\n\x07Product\x12\n\n\x02id\x18\x01 \x01(\t\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\r\n\x05\x62rand\x18\x03 \x01(\t\x12(\n\x08\x63\x61tegory\x18\x04 \x01(\x0b\x32\x16.graph.ProductCategory\x12\r\n\x05\x63olor\x18\x05 \x01(\t\x12\r\n\x05price\x18\x06 \x01(\x01\x12\x16\n\x0eoriginal_price\x18\x07 \x01(\x01\x12!\n\x05sizes\x18\x08 \x03(\x0b\x32\x12.graph.ProductSize\x12\x0c\n\x04tags\x18\t \x03(\t\x12\x32\n\nattributes\x18\n \x03(\x0b\x32\x1e.graph.Product.AttributesEntry\x12\x13\n\x0b\x64\x65scription\x18\x0b \x01(\t\x12\x0e\n\x06images\x18\x0c \x03(\t\x12\x12\n\ncreated_at\x18\r \x01(\x03\x12\x12\n\nupdated_at\x18\x0e \x01(\x03\x12\x11\n\ttenant_id\x18\x0f \x01(\t\x12\x0e\n\x06locale\x18\x10 \x01(\t\x12\x0c\n\x04slug\x18\x11 \x01(\t\x12\x0c\n\x04gtin\x18\x12 \x01(\t\x12\x13\n\x0b\x65xternal_id\x18\x13 \x01(\t\x12(\n\x08shipping\x18\x14 \x01(\x0b\x32\x16.graph.ShippingProfile\x12\x11\n\ttax_class\x18\x15 \x01(\t\x12\x10\n\x08\x61rchived\x18\x16 \x01(\x08\x12\x12\n\nmeta_title\x18\x17 \x01(\t\x12\x18\n\x10meta_description\x18\x18 \x01(\t\x1a\x31\n\x0f\x41ttributesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"_\n\x0fShippingProfile\x12\x14\n\x0cweight_grams\x18\x01 \x01(\x05\x12\x11\n\tlength_mm\x18\x02 \x01(\x05\x12\x10\n\x08width_mm\x18\x03 \x01(\x05\x12\x11\n\theight_mm\x18\x04 \x01(\x05\"~\n\rCatalogFilter\x12\x11\n\ttenant_id\x18\x01 \x01(\t\x12\x15\n\rmain_category\x18\x02 \x01(\t\x12\x13\n\x0bsubcategory\x18\x03 \x01(\t\x12\x15\n\rspecific_type\x18\x04 \x01(\t\x12\x17\n\x0finclude_archive\x18\x05 \x01(\x08\"7\n\x14\x43reateProductRequest\x12\x1f\n\x07product\x18\x01 \x01(\x0b\x32\x0e.graph.Product\"#\n\x15\x43reateProductResponse\x12\n\n\x02id\x18\x01 \x01(\t\"/\n\x11GetProductRequest\x12\n\n\x02id\x18\x01 \x01(\t\x12\x0e\n\x06locale\x18\x02 \x01(\t\"e\n\x12GetProductResponse\x12\x1f\n\x07product\x18\x01 \x01(\x0b\x32\x0e.graph.Product\x12.\n\rsizes_summary\x18\x02 \x03(\x0b\x32\x17.graph.SizeAvailability\"a\n\x10SizeAvailability\x12\x0c\n\x04size\x18\x01 \x01(\t\x12\x0b\n\x03sku\x18\x02 \x01(\t\x12\x10\n\x08in_stock\x18\x03 \x01(\x08\x12 \n\x05level\x18\x04 \x01(\x0e\x32\x11.graph.StockLevel\",\n\x16GetAvailabilityRequest\x12\x12\n\nproduct_id\x18\x01 \x01(\t\"U\n\x17GetAvailabilityResponse\x12\x12\n\nproduct_id\x18\x01 \x01(\t\x12&\n\x05sizes\x18\x02 \x03(\x0b\x32\x17.graph.SizeAvailability\"7\n\x17GetProductBySlugRequest\x12\x0c\n\x04slug\x18\x01 \x01(\t\x12\x0e\n\x06locale\x18\x02 \x01(\t\";\n\x18GetProductBySlugResponse\x12\x1f\n\x07product\x18\x01 \x01(\x0b\x32\x0e.graph.Product\"[\n\x15ResolveProductRequest\x12#\n\x04type\x18\x01 \x01(\x0e\x32\x15.graph.IdentifierType\x12\r\n\x05value\x18\x02 \x01(\t\x12\x0e\n\x06locale\x18\x03 \x01(\t\"f\n\x16ResolveProductResponse\x12\x1f\n\x07product\x18\x01 \x01(\x0b\x32\x0e.graph.Product\x12+\n\x0cmatched_type\x18\x02 \x01(\x0e\x32\x15.graph.IdentifierType\"h\n\x1fUpsertProductTranslationRequest\x12\x12\n\nproduct_id\x18\x01 \x01(\t\x12\x0e\n\x06locale\x18\x02 \x01(\t\x12\x0c\n\x04name\x18\x03 \x01(\t\x12\x13\n\x0b\x64\x65scription\x18\x04 \x01(\t\"3\n UpsertProductTranslationResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"7\n\x14UpdateProductRequest\x12\x1f\n\x07product\x18\x01 \x01(\x0b\x32\x0e.graph.Product\"M\n\x15UpdateProductResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12#\n\x07\x63hanges\x18\x02 \x03(\x0b\x32\x12.graph.FieldChange\"B\n\x0b\x46ieldChange\x12\r\n\x05\x66ield\x18\x01 \x01(\t\x12\x11\n\told_value\x18\x02 \x01(\t\x12\x11\n\tnew_value\x18\x03 \x01(\t\"H\n\x12UpdateStockRequest\x12\x12\n\nproduct_id\x18\x01 \x01(\t\x12\x0b\n\x03sku\x18\x02 \x01(\t\x12\x11\n\tnew_stock\x18\x03 \x01(\x05\"&\n\x13UpdateStockResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"M\n\x15\x41\x64\x64ProductSizeRequest\x12\x12\n\nproduct_id\x18\x01 \x01(\t\x12 \n\x04size\x18\x02 \x01(\x0b\x32\x12.graph.ProductSize\")\n\x16\x41\x64\x64ProductSizeResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"\"\n\x14\x44\x65leteProductRequest\x12\n\n\x02id\x18\x01 \x01(\t\"(\n\x15\x44\x65leteProductResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"\xc6\x01\n\x13\x43loneProductRequest\x12\x12\n\nproduct_id\x18\x01 \x01(\t\x12\n\n\x02id\x18\x02 \x01(\t\x12\x0c\n\x04slug\x18\x03 \x01(\t\x12\x0c\n\x04name\x18\x04 \x01(\t\x12\x32\n\x04skus\x18\x05 \x03(\x0b\x32$.graph.CloneProductRequest.SkusEntry\x12\x12\n\nzero_stock\x18\x06 \x01(\x08\x1a+\n\tSkusEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"7\n\x14\x43loneProductResponse\x12\x1f\n\x07product\x18\x01 \x01(\x0b\x32\x0e.graph.Product\"C\n\x1aSetProductsArchivedRequest\x12\x13\n\x0bproduct_ids\x18\x01 \x03(\t\x12\x10\n\x08\x61rchived\x18\x02 \x01(\x08\".\n\x1bSetProductsArchivedResponse\x12\x0f\n\x07updated\x18\x01 \x01(\x05\"X\n\x1c\x46indDuplicateProductsRequest\x12\x11\n\ttenant_id\x18\x01 \x01(\t\x12\x16\n\x0emin_similarity\x18\x02 \x01(\x01\x12\r\n\x05limit\x18\x03 \x01(\x05\";\n\x10\x44uplicateCluster\x12\x13\n\x0bproduct_ids\x18\x01 \x03(\t\x12\x12\n\nsimilarity\x18\x02 \x01(\x01\"J\n\x1d\x46indDuplicateProductsResponse\x12)\n\x08\x63lusters\x18\x01 \x03(\x0b\x32\x17.graph.DuplicateCluster\"B\n\x14MergeProductsRequest\x12\x13\n\x0bsurvivor_id\x18\x01 \x01(\t\x12\x15\n\rduplicate_ids\x18\x02 \x03(\t\"8\n\x15MergeProductsResponse\x12\x1f\n\x07product\x18\x01 \x01(\x0b\x32\x0e.graph.Product\"U\n\x13ListProductsRequest\x12\x11\n\tpage_size\x18\x01 \x01(\x05\x12\x12\n\npage_token\x18\x02 \x01(\t\x12\x17\n\x0finclude_archive\x18\x03 \x01(\x08\"Q\n\x14ListProductsResponse\x12 \n\x08products\x18\x01 \x03(\x0b\x32\x0e.graph.Product\x12\x17\n\x0fnext_page_token\x18\x02 \x01(\t\"W\n\x1fListProductsUpdatedSinceRequest\x12\r\n\x05since\x18\x01 \x01(\x03\x12\x11\n\tpage_size\x18\x02 \x01(\x05\x12\x12\n\npage_token\x18\x03 \x01(\t\"]\n ListProductsUpdatedSinceResponse\x12 \n\x08products\x18\x01 \x03(\x0b\x32\x0e.graph.Product\x12\x17\n\x0fnext_page_token\x18\x02 \x01(\t\"\xc1\x01\n\x15SearchProductsRequest\x12\r\n\x05query\x18\x01 \x01(\t\x12\x0e\n\x06locale\x18\x02 \x01(\t\x12\x0c\n\x04text\x18\x03 \x01(\t\x12$\n\x06\x66ilter\x18\x04 \x01(\x0b\x32\x14.graph.CatalogFilter\x12\r\n\x05limit\x18\x05 \x01(\x05\x12\x19\n\x11highlight_pre_tag\x18\x06 \x01(\t\x12\x1a\n\x12highlight_post_tag\x18\x07 \x01(\t\x12\x0f\n\x07user_id\x18\x08 \x01(\t\"G\n\x0fSearchHighlight\x12\x12\n\nproduct_id\x18\x01 \x01(\t\x12\r\n\x05\x66ield\x18\x02 \x01(\t\x12\x11\n\tfragments\x18\x03 \x03(\t\"\x8e\x01\n\x16SearchProductsResponse\x12 \n\x08products\x18\x01 \x03(\x0b\x32\x0e.graph.Product\x12\r\n\x05total\x18\x02 \x01(\x05\x12*\n\nhighlights\x18\x03 \x03(\x0b\x32\x16.graph.SearchHighlight\x12\x17\n\x0fsuggested_query\x18\x04 \x01(\t\"z\n\x15GetNewArrivalsRequest\x12$\n\x06\x66ilter\x18\x01 \x01(\x0b\x32\x14.graph.CatalogFilter\x12\x14\n\x0cmax_age_days\x18\x02 \x01(\x05\x12\x11\n\tpage_size\x18\x03 \x01(\x05\x12\x12\n\npage_token\x18\x04 \x01(\t\"S\n\x16GetNewArrivalsResponse\x12 \n\x08products\x18\x01 \x03(\x0b\x32\x0e.graph.Product\x12\x17\n\x0fnext_page_token\x18\x02 \x01(\t\"^\n\x0fGetDealsRequest\x12$\n\x06\x66ilter\x18\x01 \x01(\x0b\x32\x14.graph.CatalogFilter\x12\x11\n\tpage_size\x18\x02 \x01(\x05\x12\x12\n\npage_token\x18\x03 \x01(\t\"A\n\x04\x44\x65\x61l\x12\x1f\n\x07product\x18\x01 \x01(\x0b\x32\x0e.graph.Product\x12\x18\n\x10\x64iscount_percent\x18\x02 \x01(\x01\"G\n\x10GetDealsResponse\x12\x1a\n\x05\x64\x65\x61ls\x18\x01 \x03(\x0b\x32\x0b.graph.Deal\x12\x17\n\x0fnext_page_token\x18\x02 \x01(\t\"v\n\nCollection\x12\n\n\x02id\x18\x01 \x01(\t\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x13\n\x0b\x64\x65scription\x18\x03 \x01(\t\x12\x11\n\ttenant_id\x18\x04 \x01(\t\x12\x12\n\ncreated_at\x18\x05 \x01(\x03\x12\x12\n\nupdated_at\x18\x06 \x01(\x03\"@\n\x17\x43reateCollectionRequest\x12%\n\ncollection\x18\x01 \x01(\x0b\x32\x11.graph.Collection\"&\n\x18\x43reateCollectionResponse\x12\n\n\x02id\x18\x01 \x01(\t\"\"\n\x14GetCollectionRequest\x12\n\n\x02id\x18\x01 \x01(\t\"`\n\x15GetCollectionResponse\x12%\n\ncollection\x18\x01 \x01(\x0b\x32\x11.graph.Collection\x12 \n\x08products\x18\x02 \x03(\x0b\x32\x0e.graph.Product\"@\n\x17UpdateCollectionRequest\x12%\n\ncollection\x18\x01 \x01(\x0b\x32\x11.graph.Collection\"+\n\x18UpdateCollectionResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"%\n\x17\x44\x65leteCollectionRequest\x12\n\n\x02id\x18\x01 \x01(\t\"+\n\x18\x44\x65leteCollectionResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"J\n\x1cSetCollectionProductsRequest\x12\x15\n\rcollection_id\x18\x01 \x01(\t\x12\x13\n\x0bproduct_ids\x18\x02 \x03(\t\"0\n\x1dSetCollectionProductsResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"\\\n\x1d\x41\x64\x64ProductToCollectionRequest\x12\x15\n\rcollection_id\x18\x01 \x01(\t\x12\x12\n\nproduct_id\x18\x02 \x01(\t\x12\x10\n\x08position\x18\x03 \x01(\x05\"1\n\x1e\x41\x64\x64ProductToCollectionResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"O\n\"RemoveProductFromCollectionRequest\x12\x15\n\rcollection_id\x18\x01 \x01(\t\x12\x12\n\nproduct_id\x18\x02 \x01(\t\"6\n#RemoveProductFromCollectionResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"0\n\x0f\x42undleComponent\x12\x0b\n\x03sku\x18\x01 \x01(\t\x12\x10\n\x08quantity\x18\x02 \x01(\x05\"\xdc\x01\n\x06\x42undle\x12\n\n\x02id\x18\x01 \x01(\t\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x13\n\x0b\x64\x65scription\x18\x03 \x01(\t\x12\x11\n\ttenant_id\x18\x04 \x01(\t\x12\r\n\x05price\x18\x05 \x01(\x01\x12*\n\ncomponents\x18\x06 \x03(\x0b\x32\x16.graph.BundleComponent\x12\x1a\n\x12\x61vailable_quantity\x18\x07 \x01(\x05\x12\x11\n\tavailable\x18\x08 \x01(\x08\x12\x12\n\ncreated_at\x18\t \x01(\x03\x12\x12\n\nupdated_at\x18\n \x01(\x03\"4\n\x13\x43reateBundleRequest\x12\x1d\n\x06\x62undle\x18\x01 \x01(\x0b\x32\r.graph.Bundle\"\"\n\x14\x43reateBundleResponse\x12\n\n\x02id\x18\x01 \x01(\t\"\x1e\n\x10GetBundleRequest\x12\n\n\x02id\x18\x01 \x01(\t\"2\n\x11GetBundleResponse\x12\x1d\n\x06\x62undle\x18\x01 \x01(\x0b\x32\r.graph.Bundle\"4\n\x13UpdateBundleRequest\x12\x1d\n\x06\x62undle\x18\x01 \x01(\x0b\x32\r.graph.Bundle\"\'\n\x14UpdateBundleResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"!\n\x13\x44\x65leteBundleRequest\x12\n\n\x02id\x18\x01 \x01(\t\"\'\n\x14\x44\x65leteBundleResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"9\n\x12OrderBundleRequest\x12\x11\n\tbundle_id\x18\x01 \x01(\t\x12\x10\n\x08quantity\x18\x02 \x01(\x05\"&\n\x13OrderBundleResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"0\n\x0fReservationItem\x12\x0b\n\x03sku\x18\x01 \x01(\t\x12\x10\n\x08quantity\x18\x02 \x01(\x05\"\xb9\x01\n\x0bReservation\x12\n\n\x02id\x18\x01 \x01(\t\x12\x11\n\ttenant_id\x18\x02 \x01(\t\x12%\n\x05items\x18\x03 \x03(\x0b\x32\x16.graph.ReservationItem\x12(\n\x06status\x18\x04 \x01(\x0e\x32\x18.graph.ReservationStatus\x12\x12\n\nexpires_at\x18\x05 \x01(\x03\x12\x12\n\ncreated_at\x18\x06 \x01(\x03\x12\x12\n\nupdated_at\x18\x07 \x01(\x03\"p\n\x13ReserveStockRequest\x12\n\n\x02id\x18\x01 \x01(\t\x12\x11\n\ttenant_id\x18\x02 \x01(\t\x12%\n\x05items\x18\x03 \x03(\x0b\x32\x16.graph.ReservationItem\x12\x13\n\x0bttl_seconds\x18\x04 \x01(\x05\"?\n\x14ReserveStockResponse\x12\'\n\x0breservation\x18\x01 \x01(\x0b\x32\x12.graph.Reservation\"&\n\x18\x43ommitReservationRequest\x12\n\n\x02id\x18\x01 \x01(\t\",\n\x19\x43ommitReservationResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"\'\n\x19ReleaseReservationRequest\x12\n\n\x02id\x18\x01 \x01(\t\"-\n\x1aReleaseReservationResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"\xbb\x01\n\tOrderLine\x12\x0b\n\x03sku\x18\x01 \x01(\t\x12\x10\n\x08quantity\x18\x02 \x01(\x05\x12\x12\n\nproduct_id\x18\x03 \x01(\t\x12\x14\n\x0cproduct_name\x18\x04 \x01(\t\x12\x12\n\nunit_price\x18\x05 \x01(\x01\x12\r\n\x05total\x18\x06 \x01(\x01\x12\x0b\n\x03tax\x18\x07 \x01(\x01\x12\x10\n\x08tax_rate\x18\x08 \x01(\x01\x12\x11\n\ttax_class\x18\t \x01(\t\x12\x10\n\x08\x64iscount\x18\n \x01(\x01\"\xd8\x02\n\x05Order\x12\n\n\x02id\x18\x01 \x01(\t\x12\x11\n\ttenant_id\x18\x02 \x01(\t\x12\x13\n\x0b\x63ustomer_id\x18\x03 \x01(\t\x12\x1f\n\x05lines\x18\x04 \x03(\x0b\x32\x10.graph.OrderLine\x12\x10\n\x08\x63urrency\x18\x05 \x01(\t\x12\x10\n\x08subtotal\x18\x06 \x01(\x01\x12\r\n\x05total\x18\x07 \x01(\x01\x12\"\n\x06status\x18\x08 \x01(\x0e\x32\x12.graph.OrderStatus\x12\x12\n\npayment_id\x18\t \x01(\t\x12\x16\n\x0e\x66\x61ilure_reason\x18\n \x01(\t\x12\x12\n\ncreated_at\x18\x0b \x01(\x03\x12\x12\n\nupdated_at\x18\x0c \x01(\x03\x12\x0b\n\x03tax\x18\r \x01(\x01\x12\x12\n\nrisk_score\x18\x0e \x01(\x01\x12\x14\n\x0crisk_reasons\x18\x0f \x03(\t\x12\x18\n\x10gift_card_amount\x18\x10 \x01(\x01\"\x8d\x02\n\x11PlaceOrderRequest\x12\n\n\x02id\x18\x01 \x01(\t\x12\x11\n\ttenant_id\x18\x02 \x01(\t\x12\x13\n\x0b\x63ustomer_id\x18\x03 \x01(\t\x12\x1f\n\x05lines\x18\x04 \x03(\x0b\x32\x10.graph.OrderLine\x12\x10\n\x08\x63urrency\x18\x05 \x01(\t\x12\x16\n\x0epayment_method\x18\x06 \x01(\t\x12+\n\x0b\x64\x65stination\x18\x07 \x01(\x0b\x32\x16.graph.ShippingAddress\x12\x17\n\x0f\x62illing_country\x18\x08 \x01(\t\x12\x16\n\x0e\x63lient_country\x18\t \x01(\t\x12\x1b\n\x0egift_card_code\x18\n \x01(\tB\x03\x80\x01\x01\"1\n\x12PlaceOrderResponse\x12\x1b\n\x05order\x18\x01 \x01(\x0b\x32\x0c.graph.Order\"\x1d\n\x0fGetOrderRequest\x12\n\n\x02id\x18\x01 \x01(\t\"/\n\x10GetOrderResponse\x12\x1b\n\x05order\x18\x01 \x01(\x0b\x32\x0c.graph.Order\">\n\x0bOrderReview\x12\x10\n\x08order_id\x18\x01 \x01(\t\x12\x0f\n\x07\x61pprove\x18\x02 \x01(\x08\x12\x0c\n\x04note\x18\x03 \x01(\t\"c\n\x1aReviewFlaggedOrdersRequest\x12#\n\x07reviews\x18\x01 \x03(\x0b\x32\x12.graph.OrderReview\x12\x11\n\ttenant_id\x18\x02 \x01(\t\x12\r\n\x05limit\x18\x03 \x01(\x05\"\\\n\x1bReviewFlaggedOrdersResponse\x12\x1e\n\x08reviewed\x18\x01 \x03(\x0b\x32\x0c.graph.Order\x12\x1d\n\x07\x66lagged\x18\x02 \x03(\x0b\x32\x0c.graph.Order\"+\n\nReturnLine\x12\x0b\n\x03sku\x18\x01 \x01(\t\x12\x10\n\x08quantity\x18\x02 \x01(\x05\"\xd4\x01\n\x06Return\x12\n\n\x02id\x18\x01 \x01(\t\x12\x10\n\x08order_id\x18\x02 \x01(\t\x12 \n\x05lines\x18\x03 \x03(\x0b\x32\x11.graph.ReturnLine\x12\x13\n\x06reason\x18\x04 \x01(\tB\x03\x80\x01\x01\x12#\n\x06status\x18\x05 \x01(\x0e\x32\x13.graph.ReturnStatus\x12\x15\n\rrefund_amount\x18\x06 \x01(\x01\x12\x11\n\trefund_id\x18\x07 \x01(\t\x12\x12\n\ncreated_at\x18\x08 \x01(\x03\x12\x12\n\nupdated_at\x18\t \x01(\x03\"j\n\x13\x43reateReturnRequest\x12\n\n\x02id\x18\x01 \x01(\t\x12\x10\n\x08order_id\x18\x02 \x01(\t\x12 \n\x05lines\x18\x03 \x03(\x0b\x32\x11.graph.ReturnLine\x12\x13\n\x06reason\x18\x04 \x01(\tB\x03\x80\x01\x01\";\n\x14\x43reateReturnResponse\x12#\n\x0corder_return\x18\x01 \x01(\x0b\x32\r.graph.Return\"\"\n\x14\x41pproveReturnRequest\x12\n\n\x02id\x18\x01 \x01(\t\"<\n\x15\x41pproveReturnResponse\x12#\n\x0corder_return\x18\x01 \x01(\x0b\x32\r.graph.Return\"#\n\x15\x43ompleteReturnRequest\x12\n\n\x02id\x18\x01 \x01(\t\"=\n\x16\x43ompleteReturnResponse\x12#\n\x0corder_return\x18\x01 \x01(\x0b\x32\r.graph.Return\")\n\x08\x43\x61rtLine\x12\x0b\n\x03sku\x18\x01 \x01(\t\x12\x10\n\x08quantity\x18\x02 \x01(\x05\"_\n\x0fShippingAddress\x12\x0f\n\x07\x63ountry\x18\x01 \x01(\t\x12\x0e\n\x06region\x18\x02 \x01(\t\x12\x18\n\x0bpostal_code\x18\x03 \x01(\tB\x03\x80\x01\x01\x12\x11\n\x04\x63ity\x18\x04 \x01(\tB\x03\x80\x01\x01\"v\n\x0cShippingRate\x12\x0f\n\x07\x63\x61rrier\x18\x01 \x01(\t\x12\x0f\n\x07service\x18\x02 \x01(\t\x12\x0e\n\x06\x61mount\x18\x03 \x01(\x01\x12\x10\n\x08\x63urrency\x18\x04 \x01(\t\x12\x10\n\x08min_days\x18\x05 \x01(\x05\x12\x10\n\x08max_days\x18\x06 \x01(\x05\"f\n\x17\x45stimateShippingRequest\x12\x1e\n\x05lines\x18\x01 \x03(\x0b\x32\x0f.graph.CartLine\x12+\n\x0b\x64\x65stination\x18\x02 \x01(\x0b\x32\x16.graph.ShippingAddress\"]\n\x18\x45stimateShippingResponse\x12\"\n\x05rates\x18\x01 \x03(\x0b\x32\x13.graph.ShippingRate\x12\x1d\n\x15\x62illable_weight_grams\x18\x02 \x01(\x05\"\x9b\x01\n\x10PriceCartRequest\x12\x1e\n\x05lines\x18\x01 \x03(\x0b\x32\x0f.graph.CartLine\x12\x10\n\x08\x63urrency\x18\x02 \x01(\t\x12+\n\x0b\x64\x65stination\x18\x03 \x01(\x0b\x32\x16.graph.ShippingAddress\x12\x13\n\x0b\x63oupon_code\x18\x04 \x01(\t\x12\x13\n\x0b\x63ustomer_id\x18\x05 \x01(\t\"\xa5\x01\n\x11PriceCartResponse\x12\x1f\n\x05lines\x18\x01 \x03(\x0b\x32\x10.graph.OrderLine\x12\x10\n\x08\x63urrency\x18\x02 \x01(\t\x12\x10\n\x08subtotal\x18\x03 \x01(\x01\x12\x0b\n\x03tax\x18\x04 \x01(\x01\x12\r\n\x05total\x18\x05 \x01(\x01\x12\x10\n\x08\x64iscount\x18\x06 \x01(\x01\x12\x1d\n\x06\x63oupon\x18\x07 \x01(\x0b\x32\r.graph.Coupon\"\x9f\x02\n\x06\x43oupon\x12\x0c\n\x04\x63ode\x18\x01 \x01(\t\x12\x11\n\ttenant_id\x18\x02 \x01(\t\x12\x13\n\x0bpercent_off\x18\x03 \x01(\x01\x12\x12\n\namount_off\x18\x04 \x01(\x01\x12\x10\n\x08max_uses\x18\x05 \x01(\x05\x12\x1d\n\x15max_uses_per_customer\x18\x06 \x01(\x05\x12\x0c\n\x04uses\x18\x07 \x01(\x05\x12\x11\n\tstarts_at\x18\x08 \x01(\x03\x12\x12\n\nexpires_at\x18\t \x01(\x03\x12\x13\n\x0bproduct_ids\x18\n \x03(\t\x12\x12\n\ncategories\x18\x0b \x03(\t\x12\x14\n\x0cmin_subtotal\x18\x0c \x01(\x01\x12\x12\n\ncreated_at\x18\r \x01(\x03\x12\x12\n\nupdated_at\x18\x0e \x01(\x03\"4\n\x13\x43reateCouponRequest\x12\x1d\n\x06\x63oupon\x18\x01 \x01(\x0b\x32\r.graph.Coupon\"5\n\x14\x43reateCouponResponse\x12\x1d\n\x06\x63oupon\x18\x01 \x01(\x0b\x32\r.graph.Coupon\"Z\n\x15ValidateCouponRequest\x12\x0c\n\x04\x63ode\x18\x01 \x01(\t\x12\x1e\n\x05lines\x18\x02 \x03(\x0b\x32\x0f.graph.CartLine\x12\x13\n\x0b\x63ustomer_id\x18\x03 \x01(\t\"\x7f\n\x16ValidateCouponResponse\x12\r\n\x05valid\x18\x01 \x01(\x08\x12\x0e\n\x06reason\x18\x02 \x01(\t\x12\x1d\n\x06\x63oupon\x18\x03 \x01(\x0b\x32\r.graph.Coupon\x12\x10\n\x08\x64iscount\x18\x04 \x01(\x01\x12\x15\n\religible_skus\x18\x05 \x03(\t\"J\n\x13RedeemCouponRequest\x12\x0c\n\x04\x63ode\x18\x01 \x01(\t\x12\x13\n\x0b\x63ustomer_id\x18\x02 \x01(\t\x12\x10\n\x08order_id\x18\x03 \x01(\t\"5\n\x14RedeemCouponResponse\x12\x1d\n\x06\x63oupon\x18\x01 \x01(\x0b\x32\r.graph.Coupon\"\xb4\x01\n\x08GiftCard\x12\n\n\x02id\x18\x01 \x01(\t\x12\x11\n\x04\x63ode\x18\x02 \x01(\tB\x03\x80\x01\x01\x12\x11\n\ttenant_id\x18\x03 \x01(\t\x12\x10\n\x08\x63urrency\x18\x04 \x01(\t\x12\x17\n\x0finitial_balance\x18\x05 \x01(\x01\x12\x0f\n\x07\x62\x61lance\x18\x06 \x01(\x01\x12\x12\n\nexpires_at\x18\x07 \x01(\x03\x12\x12\n\ncreated_at\x18\x08 \x01(\x03\x12\x12\n\nupdated_at\x18\t \x01(\x03\"\x96\x01\n\x13GiftCardTransaction\x12\n\n\x02id\x18\x01 \x01(\t\x12,\n\x04kind\x18\x02 \x01(\x0e\x32\x1e.graph.GiftCardTransactionKind\x12\x0e\n\x06\x61mount\x18\x03 \x01(\x01\x12\x0f\n\x07\x62\x61lance\x18\x04 \x01(\x01\x12\x10\n\x08order_id\x18\x05 \x01(\t\x12\x12\n\ncreated_at\x18\x06 \x01(\x03\"5\n\x14IssueGiftCardRequest\x12\x1d\n\x04\x63\x61rd\x18\x01 \x01(\x0b\x32\x0f.graph.GiftCard\"6\n\x15IssueGiftCardResponse\x12\x1d\n\x04\x63\x61rd\x18\x01 \x01(\x0b\x32\x0f.graph.GiftCard\"&\n\x11GetBalanceRequest\x12\x11\n\x04\x63ode\x18\x01 \x01(\tB\x03\x80\x01\x01\"e\n\x12GetBalanceResponse\x12\x1d\n\x04\x63\x61rd\x18\x01 \x01(\x0b\x32\x0f.graph.GiftCard\x12\x30\n\x0ctransactions\x18\x02 \x03(\x0b\x32\x1a.graph.GiftCardTransaction\"^\n\x15RedeemGiftCardRequest\x12\x11\n\x04\x63ode\x18\x01 \x01(\tB\x03\x80\x01\x01\x12\x10\n\x08order_id\x18\x02 \x01(\t\x12\x0e\n\x06\x61mount\x18\x03 \x01(\x01\x12\x10\n\x08\x63urrency\x18\x04 \x01(\t\"h\n\x16RedeemGiftCardResponse\x12\x1d\n\x04\x63\x61rd\x18\x01 \x01(\x0b\x32\x0f.graph.GiftCard\x12/\n\x0btransaction\x18\x02 \x01(\x0b\x32\x1a.graph.GiftCardTransaction\"\x8c\x01\n\x0eLoyaltyBalance\x12\x13\n\x0b\x63ustomer_id\x18\x01 \x01(\t\x12\x0e\n\x06points\x18\x02 \x01(\x03\x12\x17\n\x0flifetime_points\x18\x03 \x01(\x03\x12\x0c\n\x04tier\x18\x04 \x01(\t\x12\x11\n\tnext_tier\x18\x05 \x01(\t\x12\x1b\n\x13points_to_next_tier\x18\x06 \x01(\x03\"/\n\x18GetLoyaltyBalanceRequest\x12\x13\n\x0b\x63ustomer_id\x18\x01 \x01(\t\"C\n\x19GetLoyaltyBalanceResponse\x12&\n\x07\x62\x61lance\x18\x01 \x01(\x0b\x32\x15.graph.LoyaltyBalance\"M\n\x13RedeemPointsRequest\x12\x13\n\x0b\x63ustomer_id\x18\x01 \x01(\t\x12\x0e\n\x06points\x18\x02 \x01(\x03\x12\x11\n\treference\x18\x03 \x01(\t\">\n\x14RedeemPointsResponse\x12&\n\x07\x62\x61lance\x18\x01 \x01(\x0b\x32\x15.graph.LoyaltyBalance\"F\n\x13SetCrossSellRequest\x12\x12\n\nproduct_id\x18\x01 \x01(\t\x12\x1b\n\x13related_product_ids\x18\x02 \x03(\t\"\'\n\x14SetCrossSellResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"C\n\x10SetUpsellRequest\x12\x12\n\nproduct_id\x18\x01 \x01(\t\x12\x1b\n\x13related_product_ids\x18\x02 \x03(\t\"$\n\x11SetUpsellResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"s\n%GetMerchandisedRecommendationsRequest\x12\x12\n\nproduct_id\x18\x01 \x01(\t\x12\'\n\x04type\x18\x02 \x01(\x0e\x32\x19.graph.RecommendationType\x12\r\n\x05limit\x18\x03 \x01(\x05\"B\n\x0eRecommendation\x12\x1f\n\x07product\x18\x01 \x01(\x0b\x32\x0e.graph.Product\x12\x0f\n\x07\x63urated\x18\x02 \x01(\x08\"X\n&GetMerchandisedRecommendationsResponse\x12.\n\x0frecommendations\x18\x01 \x03(\x0b\x32\x15.graph.Recommendation\"\x8e\x01\n\x0cSizeChartRow\x12\x0c\n\x04size\x18\x01 \x01(\t\x12;\n\x0cmeasurements\x18\x02 \x03(\x0b\x32%.graph.SizeChartRow.MeasurementsEntry\x1a\x33\n\x11MeasurementsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x01:\x02\x38\x01\"\xb2\x01\n\tSizeChart\x12\r\n\x05\x62rand\x18\x01 \x01(\t\x12\x15\n\rmain_category\x18\x02 \x01(\t\x12\x13\n\x0bsubcategory\x18\x03 \x01(\t\x12\x0c\n\x04unit\x18\x04 \x01(\t\x12!\n\x04rows\x18\x05 \x03(\x0b\x32\x13.graph.SizeChartRow\x12\x11\n\tfit_notes\x18\x06 \x01(\t\x12\x12\n\ncreated_at\x18\x07 \x01(\x03\x12\x12\n\nupdated_at\x18\x08 \x01(\x03\"9\n\x16UpsertSizeChartRequest\x12\x1f\n\x05\x63hart\x18\x01 \x01(\x0b\x32\x10.graph.SizeChart\"*\n\x17UpsertSizeChartResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"d\n\x13GetSizeChartRequest\x12\x12\n\nproduct_id\x18\x01 \x01(\t\x12\r\n\x05\x62rand\x18\x02 \x01(\t\x12\x15\n\rmain_category\x18\x03 \x01(\t\x12\x13\n\x0bsubcategory\x18\x04 \x01(\t\"7\n\x14GetSizeChartResponse\x12\x1f\n\x05\x63hart\x18\x01 \x01(\x0b\x32\x10.graph.SizeChart\"\xcc\x01\n\x1eSetCustomerMeasurementsRequest\x12\x13\n\x0b\x63ustomer_id\x18\x01 \x01(\t\x12\x0c\n\x04unit\x18\x02 \x01(\t\x12R\n\x0cmeasurements\x18\x03 \x03(\x0b\x32\x37.graph.SetCustomerMeasurementsRequest.MeasurementsEntryB\x03\x80\x01\x01\x1a\x33\n\x11MeasurementsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x01:\x02\x38\x01\"2\n\x1fSetCustomerMeasurementsResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"?\n\x14RecommendSizeRequest\x12\x13\n\x0b\x63ustomer_id\x18\x01 \x01(\t\x12\x12\n\nproduct_id\x18\x02 \x01(\t\"u\n\x15RecommendSizeResponse\x12\x0c\n\x04size\x18\x01 \x01(\t\x12\x10\n\x08\x64istance\x18\x02 \x01(\x01\x12\x10\n\x08in_stock\x18\x03 \x01(\x08\x12\x11\n\tfit_notes\x18\x04 \x01(\t\x12\x17\n\x0f\x66rom_preference\x18\x05 \x01(\x08\"\x9a\x01\n\x0fUserPreferences\x12\x30\n\x05sizes\x18\x01 \x03(\x0b\x32!.graph.UserPreferences.SizesEntry\x12\x17\n\x0f\x66\x61vorite_brands\x18\x02 \x03(\t\x12\x0e\n\x06locale\x18\x03 \x01(\t\x1a,\n\nSizesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"\xa1\x01\n\x04User\x12\n\n\x02id\x18\x01 \x01(\t\x12\x11\n\ttenant_id\x18\x02 \x01(\t\x12\x12\n\x05\x65mail\x18\x03 \x01(\tB\x03\x80\x01\x01\x12\x11\n\x04name\x18\x04 \x01(\tB\x03\x80\x01\x01\x12+\n\x0bpreferences\x18\x05 \x01(\x0b\x32\x16.graph.UserPreferences\x12\x12\n\ncreated_at\x18\x06 \x01(\x03\x12\x12\n\nupdated_at\x18\x07 \x01(\x03\".\n\x11\x43reateUserRequest\x12\x19\n\x04user\x18\x01 \x01(\x0b\x32\x0b.graph.User\"/\n\x12\x43reateUserResponse\x12\x19\n\x04user\x18\x01 \x01(\x0b\x32\x0b.graph.User\"\x1c\n\x0eGetUserRequest\x12\n\n\x02id\x18\x01 \x01(\t\",\n\x0fGetUserResponse\x12\x19\n\x04user\x18\x01 \x01(\x0b\x32\x0b.graph.User\"X\n\x18UpdatePreferencesRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\t\x12+\n\x0bpreferences\x18\x02 \x01(\x0b\x32\x16.graph.UserPreferences\"6\n\x19UpdatePreferencesResponse\x12\x19\n\x04user\x18\x01 \x01(\x0b\x32\x0b.graph.User\"(\n\x15\x45xportUserDataRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\t\"<\n\x16\x45xportUserDataResponse\x12\x0e\n\x06\x62undle\x18\x01 \x01(\x0c\x12\x12\n\nrequest_id\x18\x02 \x01(\t\"8\n\x15\x44\x65leteUserDataRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\t\x12\x0e\n\x06reason\x18\x02 \x01(\t\"o\n\x16\x44\x65leteUserDataResponse\x12\x11\n\tpseudonym\x18\x01 \x01(\t\x12\x17\n\x0forders_retained\x18\x02 \x01(\x05\x12\x15\n\rnodes_deleted\x18\x03 \x01(\x05\x12\x12\n\nrequest_id\x18\x04 \x01(\t\"\xaf\x01\n\x0bSavedSearch\x12\n\n\x02id\x18\x01 \x01(\t\x12\x0f\n\x07user_id\x18\x02 \x01(\t\x12\x0c\n\x04name\x18\x03 \x01(\t\x12\x0c\n\x04text\x18\x04 \x01(\t\x12$\n\x06\x66ilter\x18\x05 \x01(\x0b\x32\x14.graph.CatalogFilter\x12\x12\n\ncreated_at\x18\x06 \x01(\x03\x12\x12\n\nupdated_at\x18\x07 \x01(\x03\x12\x19\n\x11last_evaluated_at\x18\x08 \x01(\x03\"=\n\x11SaveSearchRequest\x12(\n\x0csaved_search\x18\x01 \x01(\x0b\x32\x12.graph.SavedSearch\" \n\x12SaveSearchResponse\x12\n\n\x02id\x18\x01 \x01(\t\"+\n\x18ListSavedSearchesRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\t\"G\n\x19ListSavedSearchesResponse\x12*\n\x0esaved_searches\x18\x01 \x03(\x0b\x32\x12.graph.SavedSearch\"7\n\x18\x44\x65leteSavedSearchRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\t\x12\n\n\x02id\x18\x02 \x01(\t\",\n\x19\x44\x65leteSavedSearchResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"\xa1\x01\n\x15PriceDropSubscription\x12\x0f\n\x07user_id\x18\x01 \x01(\t\x12\x12\n\nproduct_id\x18\x02 \x01(\t\x12\r\n\x05price\x18\x03 \x01(\x01\x12\x15\n\rcurrent_price\x18\x04 \x01(\x01\x12\x12\n\ncreated_at\x18\x05 \x01(\x03\x12\x13\n\x0bnotified_at\x18\x06 \x01(\x03\x12\x14\n\x0cproduct_name\x18\x07 \x01(\t\"B\n\x1bSubscribeToPriceDropRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\t\x12\x12\n\nproduct_id\x18\x02 \x01(\t\"R\n\x1cSubscribeToPriceDropResponse\x12\x32\n\x0csubscription\x18\x01 \x01(\x0b\x32\x1c.graph.PriceDropSubscription\"F\n\x1fUnsubscribeFromPriceDropRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\t\x12\x12\n\nproduct_id\x18\x02 \x01(\t\"3\n UnsubscribeFromPriceDropResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\"J\n\x16RegisterWebhookRequest\x12\x0b\n\x03url\x18\x01 \x01(\t\x12\x0e\n\x06secret\x18\x02 \x01(\t\x12\x13\n\x0b\x65vent_types\x18\x03 \x03(\t\"%\n\x17RegisterWebhookResponse\x12\n\n\x02id\x18\x01 \x01(\t\"\xfd\x01\n\x0fWebhookDelivery\x12\n\n\x02id\x18\x01 \x01(\t\x12\x12\n\nwebhook_id\x18\x02 \x01(\t\x12\x12\n\nevent_type\x18\x03 \x01(\t\x12\x0f\n\x07payload\x18\x04 \x01(\t\x12\x0e\n\x06status\x18\x05 \x01(\t\x12\x10\n\x08\x61ttempts\x18\x06 \x01(\x05\x12\x18\n\x10last_status_code\x18\x07 \x01(\x05\x12\x12\n\nlast_error\x18\x08 \x01(\t\x12\x12\n\ncreated_at\x18\t \x01(\x03\x12\x17\n\x0fnext_attempt_at\x18\n \x01(\x03\x12\x14\n\x0c\x64\x65livered_at\x18\x0b \x01(\x03\x12\x12\n\nupdated_at\x18\x0c \x01(\x03\"^\n\x15ListDeliveriesRequest\x12\x12\n\nwebhook_id\x18\x01 \x01(\t\x12\x0e\n\x06status\x18\x02 \x01(\t\x12\r\n\x05limit\x18\x03 \x01(\x05\x12\x12\n\npage_token\x18\x04 \x01(\t\"]\n\x16ListDeliveriesResponse\x12*\n\ndeliveries\x18\x01 \x03(\x0b\x32\x16.graph.WebhookDelivery\x12\x17\n\x0fnext_page_token\x18\x02 \x01(\t\"Z\n\x0c\x43\x61tegoryNode\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x15\n\rproduct_count\x18\x02 \x01(\x05\x12%\n\x08\x63hildren\x18\x03 \x03(\x0b\x32\x13.graph.CategoryNode\"+\n\x16GetCategoryTreeRequest\x12\x11\n\ttenant_id\x18\x01 \x01(\t\"B\n\x17GetCategoryTreeResponse\x12\'\n\ncategories\x18\x01 \x03(\x0b\x32\x13.graph.CategoryNode\"\x9b\x01\n\x15\x45xportSubgraphRequest\x12\x12\n\nproduct_id\x18\x01 \x01(\t\x12(\n\x08\x63\x61tegory\x18\x02 \x01(\x0b\x32\x16.graph.ProductCategory\x12\r\n\x05\x64\x65pth\x18\x03 \x01(\x05\x12\x11\n\tmax_nodes\x18\x04 \x01(\x05\x12\"\n\x06\x66ormat\x18\x05 \x01(\x0e\x32\x12.graph.GraphFormat\"w\n\x16\x45xportSubgraphResponse\x12\x0c\n\x04\x64\x61ta\x18\x01 \x01(\x0c\x12\x14\n\x0c\x63ontent_type\x18\x02 \x01(\t\x12\x12\n\nnode_count\x18\x03 \x01(\x05\x12\x12\n\nedge_count\x18\x04 \x01(\x05\x12\x11\n\ttruncated\x18\x05 \x01(\x08\"@\n\x0c\x43\x61talogIssue\x12\r\n\x05\x63heck\x18\x01 \x01(\t\x12\r\n\x05\x63ount\x18\x02 \x01(\x03\x12\x12\n\nsample_ids\x18\x03 \x03(\t\"@\n\x16ValidateCatalogRequest\x12\x11\n\ttenant_id\x18\x01 \x01(\t\x12\x13\n\x0bsample_size\x18\x02 \x01(\x05\"X\n\x17ValidateCatalogResponse\x12\x18\n\x10products_checked\x18\x01 \x01(\x03\x12#\n\x06issues\x18\x02 \x03(\x0b\x32\x13.graph.CatalogIssue\"\x81\x02\n\x07SyncRun\x12\n\n\x02id\x18\x01 \x01(\t\x12\x0e\n\x06source\x18\x02 \x01(\t\x12$\n\x06status\x18\x03 \x01(\x0e\x32\x14.graph.SyncRunStatus\x12\x12\n\nstarted_at\x18\x04 \x01(\x03\x12\x13\n\x0b\x66inished_at\x18\x05 \x01(\x03\x12\x13\n\x0b\x63ursor_from\x18\x06 \x01(\x03\x12\x11\n\tcursor_to\x18\x07 \x01(\x03\x12\x0f\n\x07\x63reated\x18\x08 \x01(\x05\x12\x0f\n\x07updated\x18\t \x01(\x05\x12\x11\n\tunchanged\x18\n \x01(\x05\x12\x0f\n\x07skipped\x18\x0b \x01(\x05\x12\x0e\n\x06\x66\x61iled\x18\x0c \x01(\x05\x12\r\n\x05\x65rror\x18\r \x01(\t\"4\n\x13ListSyncRunsRequest\x12\x0e\n\x06source\x18\x01 \x01(\t\x12\r\n\x05limit\x18\x02 \x01(\x05\"4\n\x14ListSyncRunsResponse\x12\x1c\n\x04runs\x18\x01 \x03(\x0b\x32\x0e.graph.SyncRun\"I\n\x0fStockHistoryDay\x12\x0b\n\x03\x64\x61y\x18\x01 \x01(\t\x12\x12\n\nunits_sold\x18\x02 \x01(\x05\x12\x15\n\rclosing_stock\x18\x03 \x01(\x05\"X\n\x0fSkuStockHistory\x12\x0b\n\x03sku\x18\x01 \x01(\t\x12\x12\n\nproduct_id\x18\x02 \x01(\t\x12$\n\x04\x64\x61ys\x18\x03 \x03(\x0b\x32\x16.graph.StockHistoryDay\"_\n\x19\x45xportStockHistoryRequest\x12\x11\n\ttenant_id\x18\x01 \x01(\t\x12\x0c\n\x04\x64\x61ys\x18\x02 \x01(\x05\x12\r\n\x05limit\x18\x03 \x01(\x05\x12\x12\n\npage_token\x18\x04 \x01(\t\"[\n\x1a\x45xportStockHistoryResponse\x12$\n\x04skus\x18\x01 \x03(\x0b\x32\x16.graph.SkuStockHistory\x12\x17\n\x0fnext_page_token\x18\x02 \x01(\t\"C\n\x0bSkuForecast\x12\x0b\n\x03sku\x18\x01 \x01(\t\x12\x11\n\tstart_day\x18\x02 \x01(\t\x12\x14\n\x0c\x64\x61ily_demand\x18\x03 \x03(\x01\"M\n\x15ImportForecastRequest\x12\r\n\x05model\x18\x01 \x01(\t\x12%\n\tforecasts\x18\x02 \x03(\x0b\x32\x12.graph.SkuForecast\"@\n\x16ImportForecastResponse\x12\x10\n\x08imported\x18\x01 \x01(\x05\x12\x14\n\x0cunknown_skus\x18\x02 \x03(\t\"g\n\x0fTranscriptEntry\x12\x0b\n\x03seq\x18\x01 \x01(\x03\x12\x0c\n\x04kind\x18\x02 \x01(\t\x12\x11\n\x04text\x18\x03 \x01(\tB\x03\x80\x01\x01\x12\x11\n\x04\x64\x61ta\x18\x04 \x01(\tB\x03\x80\x01\x01\x12\x13\n\x0boccurred_at\x18\x05 \x01(\x03\"\xa0\x01\n\x11SessionTranscript\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x11\n\ttenant_id\x18\x02 \x01(\t\x12\x13\n\x0b\x63ustomer_id\x18\x03 \x01(\t\x12\x12\n\nstarted_at\x18\x04 \x01(\x03\x12\x12\n\nupdated_at\x18\x05 \x01(\x03\x12\'\n\x07\x65ntries\x18\x06 \x03(\x0b\x32\x16.graph.TranscriptEntry\"~\n\x17\x41ppendTranscriptRequest\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x11\n\ttenant_id\x18\x02 \x01(\t\x12\x13\n\x0b\x63ustomer_id\x18\x03 \x01(\t\x12\'\n\x07\x65ntries\x18\x04 \x03(\x0b\x32\x16.graph.TranscriptEntry\",\n\x18\x41ppendTranscriptResponse\x12\x10\n\x08last_seq\x18\x01 \x01(\x03\"T\n\x1bGetSessionTranscriptRequest\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\r\n\x05limit\x18\x02 \x01(\x05\x12\x12\n\npage_token\x18\x03 \x01(\t\"e\n\x1cGetSessionTranscriptResponse\x12,\n\ntranscript\x18\x01 \x01(\x0b\x32\x18.graph.SessionTranscript\x12\x17\n\x0fnext_page_token\x18\x02 \x01(\t*;\n\nStockLevel\x12\x10\n\x0cOUT_OF_STOCK\x10\x00\x12\r\n\tLOW_STOCK\x10\x01\x12\x0c\n\x08IN_STOCK\x10\x02*Y\n\x0eIdentifierType\x12\x12\n\x0e\x41NY_IDENTIFIER\x10\x00\x12\x0e\n\nPRODUCT_ID\x10\x01\x12\x08\n\x04SLUG\x10\x02\x12\x08\n\x04GTIN\x10\x03\x12\x0f\n\x0b\x45XTERNAL_ID\x10\x04*K\n\x11ReservationStatus\x12\x0c\n\x08RESERVED\x10\x00\x12\r\n\tCOMMITTED\x10\x01\x12\x0c\n\x08RELEASED\x10\x02\x12\x0b\n\x07\x45XPIRED\x10\x03*\xa0\x01\n\x0bOrderStatus\x12\x13\n\x0fPENDING_PAYMENT\x10\x00\x12\n\n\x06PLACED\x10\x01\x12\x12\n\x0ePAYMENT_FAILED\x10\x02\x12\x16\n\x12RETURN_IN_PROGRESS\x10\x03\x12\x16\n\x12PARTIALLY_RETURNED\x10\x04\x12\x0c\n\x08RETURNED\x10\x05\x12\x10\n\x0cUNDER_REVIEW\x10\x06\x12\x0c\n\x08\x44\x45\x43LINED\x10\x07*O\n\x0cReturnStatus\x12\x14\n\x10RETURN_REQUESTED\x10\x00\x12\x13\n\x0fRETURN_APPROVED\x10\x01\x12\x14\n\x10RETURN_COMPLETED\x10\x02*@\n\x17GiftCardTransactionKind\x12\t\n\x05ISSUE\x10\x00\x12\x0e\n\nREDEMPTION\x10\x01\x12\n\n\x06REFUND\x10\x02*0\n\x12RecommendationType\x12\x0e\n\nCROSS_SELL\x10\x00\x12\n\n\x06UPSELL\x10\x01*.\n\x0bGraphFormat\x12\x12\n\x0e\x43YTOSCAPE_JSON\x10\x00\x12\x0b\n\x07GRAPHML\x10\x01*F\n\rSyncRunStatus\x12\x10\n\x0cSYNC_RUNNING\x10\x00\x12\x12\n\x0eSYNC_SUCCEEDED\x10\x01\x12\x0f\n\x0bSYNC_FAILED\x10\x02\x32\xff\x30\n\x0cGraphService\x12J\n\rCreateProduct\x12\x1b.graph.CreateProductRequest\x1a\x1c.graph.CreateProductResponse\x12\x41\n\nGetProduct\x12\x18.graph.GetProductRequest\x1a\x19.graph.GetProductResponse\x12P\n\x0fGetAvailability\x12\x1d.graph.GetAvailabilityRequest\x1a\x1e.graph.GetAvailabilityResponse\x12M\n\x0eResolveProduct\x12\x1c.graph.ResolveProductRequest\x1a\x1d.graph.ResolveProductResponse\x12S\n\x10GetProductBySlug\x12\x1e.graph.GetProductBySlugRequest\x1a\x1f.graph.GetProductBySlugResponse\x12k\n\x18UpsertProductTranslation\x12&.graph.UpsertProductTranslationRequest\x1a\'.graph.UpsertProductTranslationResponse\x12J\n\rUpdateProduct\x12\x1b.graph.UpdateProductRequest\x1a\x1c.graph.UpdateProductResponse\x12J\n\rDeleteProduct\x12\x1b.graph.DeleteProductRequest\x1a\x1c.graph.DeleteProductResponse\x12G\n\x0c\x43loneProduct\x12\x1a.graph.CloneProductRequest\x1a\x1b.graph.CloneProductResponse\x12\\\n\x13SetProductsArchived\x12!.graph.SetProductsArchivedRequest\x1a\".graph.SetProductsArchivedResponse\x12\x62\n\x15\x46indDuplicateProducts\x12#.graph.FindDuplicateProductsRequest\x1a$.graph.FindDuplicateProductsResponse\x12J\n\rMergeProducts\x12\x1b.graph.MergeProductsRequest\x1a\x1c.graph.MergeProductsResponse\x12\x44\n\x0bUpdateStock\x12\x19.graph.UpdateStockRequest\x1a\x1a.graph.UpdateStockResponse\x12M\n\x0e\x41\x64\x64ProductSize\x12\x1c.graph.AddProductSizeRequest\x1a\x1d.graph.AddProductSizeResponse\x12G\n\x0cListProducts\x12\x1a.graph.ListProductsRequest\x1a\x1b.graph.ListProductsResponse\x12k\n\x18ListProductsUpdatedSince\x12&.graph.ListProductsUpdatedSinceRequest\x1a\'.graph.ListProductsUpdatedSinceResponse\x12M\n\x0eSearchProducts\x12\x1c.graph.SearchProductsRequest\x1a\x1d.graph.SearchProductsResponse\x12M\n\x0eGetNewArrivals\x12\x1c.graph.GetNewArrivalsRequest\x1a\x1d.graph.GetNewArrivalsResponse\x12;\n\x08GetDeals\x12\x16.graph.GetDealsRequest\x1a\x17.graph.GetDealsResponse\x12S\n\x10\x43reateCollection\x12\x1e.graph.CreateCollectionRequest\x1a\x1f.graph.CreateCollectionResponse\x12J\n\rGetCollection\x12\x1b.graph.GetCollectionRequest\x1a\x1c.graph.GetCollectionResponse\x12S\n\x10UpdateCollection\x12\x1e.graph.UpdateCollectionRequest\x1a\x1f.graph.UpdateCollectionResponse\x12S\n\x10\x44\x65leteCollection\x12\x1e.graph.DeleteCollectionRequest\x1a\x1f.graph.DeleteCollectionResponse\x12\x62\n\x15SetCollectionProducts\x12#.graph.SetCollectionProductsRequest\x1a$.graph.SetCollectionProductsResponse\x12\x65\n\x16\x41\x64\x64ProductToCollection\x12$.graph.AddProductToCollectionRequest\x1a%.graph.AddProductToCollectionResponse\x12t\n\x1bRemoveProductFromCollection\x12).graph.RemoveProductFromCollectionRequest\x1a*.graph.RemoveProductFromCollectionResponse\x12G\n\x0c\x43reateBundle\x12\x1a.graph.CreateBundleRequest\x1a\x1b.graph.CreateBundleResponse\x12>\n\tGetBundle\x12\x17.graph.GetBundleRequest\x1a\x18.graph.GetBundleResponse\x12G\n\x0cUpdateBundle\x12\x1a.graph.UpdateBundleRequest\x1a\x1b.graph.UpdateBundleResponse\x12G\n\x0c\x44\x65leteBundle\x12\x1a.graph.DeleteBundleRequest\x1a\x1b.graph.DeleteBundleResponse\x12\x44\n\x0bOrderBundle\x12\x19.graph.OrderBundleRequest\x1a\x1a.graph.OrderBundleResponse\x12G\n\x0cReserveStock\x12\x1a.graph.ReserveStockRequest\x1a\x1b.graph.ReserveStockResponse\x12V\n\x11\x43ommitReservation\x12\x1f.graph.CommitReservationRequest\x1a .graph.CommitReservationResponse\x12Y\n\x12ReleaseReservation\x12 .graph.ReleaseReservationRequest\x1a!.graph.ReleaseReservationResponse\x12\x41\n\nPlaceOrder\x12\x18.graph.PlaceOrderRequest\x1a\x19.graph.PlaceOrderResponse\x12;\n\x08GetOrder\x12\x16.graph.GetOrderRequest\x1a\x17.graph.GetOrderResponse\x12\\\n\x13ReviewFlaggedOrders\x12!.graph.ReviewFlaggedOrdersRequest\x1a\".graph.ReviewFlaggedOrdersResponse\x12G\n\x0c\x43reateReturn\x12\x1a.graph.CreateReturnRequest\x1a\x1b.graph.CreateReturnResponse\x12J\n\rApproveReturn\x12\x1b.graph.ApproveReturnRequest\x1a\x1c.graph.ApproveReturnResponse\x12M\n\x0e\x43ompleteReturn\x12\x1c.graph.CompleteReturnRequest\x1a\x1d.graph.CompleteReturnResponse\x12S\n\x10\x45stimateShipping\x12\x1e.graph.EstimateShippingRequest\x1a\x1f.graph.EstimateShippingResponse\x12>\n\tPriceCart\x12\x17.graph.PriceCartRequest\x1a\x18.graph.PriceCartResponse\x12G\n\x0c\x43reateCoupon\x12\x1a.graph.CreateCouponRequest\x1a\x1b.graph.CreateCouponResponse\x12M\n\x0eValidateCoupon\x12\x1c.graph.ValidateCouponRequest\x1a\x1d.graph.ValidateCouponResponse\x12G\n\x0cRedeemCoupon\x12\x1a.graph.RedeemCouponRequest\x1a\x1b.graph.RedeemCouponResponse\x12J\n\rIssueGiftCard\x12\x1b.graph.IssueGiftCardRequest\x1a\x1c.graph.IssueGiftCardResponse\x12\x41\n\nGetBalance\x12\x18.graph.GetBalanceRequest\x1a\x19.graph.GetBalanceResponse\x12M\n\x0eRedeemGiftCard\x12\x1c.graph.RedeemGiftCardRequest\x1a\x1d.graph.RedeemGiftCardResponse\x12V\n\x11GetLoyaltyBalance\x12\x1f.graph.GetLoyaltyBalanceRequest\x1a .graph.GetLoyaltyBalanceResponse\x12G\n\x0cRedeemPoints\x12\x1a.graph.RedeemPointsRequest\x1a\x1b.graph.RedeemPointsResponse\x12G\n\x0cSetCrossSell\x12\x1a.graph.SetCrossSellRequest\x1a\x1b.graph.SetCrossSellResponse\x12>\n\tSetUpsell\x12\x17.graph.SetUpsellRequest\x1a\x18.graph.SetUpsellResponse\x12}\n\x1eGetMerchandisedRecommendations\x12,.graph.GetMerchandisedRecommendationsRequest\x1a-.graph.GetMerchandisedRecommendationsResponse\x12P\n\x0fUpsertSizeChart\x12\x1d.graph.UpsertSizeChartRequest\x1a\x1e.graph.UpsertSizeChartResponse\x12G\n\x0cGetSizeChart\x12\x1a.graph.GetSizeChartRequest\x1a\x1b.graph.GetSizeChartResponse\x12h\n\x17SetCustomerMeasurements\x12%.graph.SetCustomerMeasurementsRequest\x1a&.graph.SetCustomerMeasurementsResponse\x12J\n\rRecommendSize\x12\x1b.graph.RecommendSizeRequest\x1a\x1c.graph.RecommendSizeResponse\x12\x41\n\nCreateUser\x12\x18.graph.CreateUserRequest\x1a\x19.graph.CreateUserResponse\x12\x38\n\x07GetUser\x12\x15.graph.GetUserRequest\x1a\x16.graph.GetUserResponse\x12V\n\x11UpdatePreferences\x12\x1f.graph.UpdatePreferencesRequest\x1a .graph.UpdatePreferencesResponse\x12M\n\x0e\x45xportUserData\x12\x1c.graph.ExportUserDataRequest\x1a\x1d.graph.ExportUserDataResponse\x12M\n\x0e\x44\x65leteUserData\x12\x1c.graph.DeleteUserDataRequest\x1a\x1d.graph.DeleteUserDataResponse\x12\x41\n\nSaveSearch\x12\x18.graph.SaveSearchRequest\x1a\x19.graph.SaveSearchResponse\x12V\n\x11ListSavedSearches\x12\x1f.graph.ListSavedSearchesRequest\x1a .graph.ListSavedSearchesResponse\x12V\n\x11\x44\x65leteSavedSearch\x12\x1f.graph.DeleteSavedSearchRequest\x1a .graph.DeleteSavedSearchResponse\x12_\n\x14SubscribeToPriceDrop\x12\".graph.SubscribeToPriceDropRequest\x1a#.graph.SubscribeToPriceDropResponse\x12k\n\x18UnsubscribeFromPriceDrop\x12&.graph.UnsubscribeFromPriceDropRequest\x1a\'.graph.UnsubscribeFromPriceDropResponse\x12P\n\x0fRegisterWebhook\x12\x1d.graph.RegisterWebhookRequest\x1a\x1e.graph.RegisterWebhookResponse\x12M\n\x0eListDeliveries\x12\x1c.graph.ListDeliveriesRequest\x1a\x1d.graph.ListDeliveriesResponse\x12P\n\x0fGetCategoryTree\x12\x1d.graph.GetCategoryTreeRequest\x1a\x1e.graph.GetCategoryTreeResponse\x12M\n\x0e\x45xportSubgraph\x12\x1c.graph.ExportSubgraphRequest\x1a\x1d.graph.ExportSubgraphResponse\x12G\n\x0cListSyncRuns\x12\x1a.graph.ListSyncRunsRequest\x1a\x1b.graph.ListSyncRunsResponse\x12P\n\x0fValidateCatalog\x12\x1d.graph.ValidateCatalogRequest\x1a\x1e.graph.ValidateCatalogResponse\x12Y\n\x12\x45xportStockHistory\x12 .graph.ExportStockHistoryRequest\x1a!.graph.ExportStockHistoryResponse\x12M\n\x0eImportForecast\x12\x1c.graph.ImportForecastRequest\x1a\x1d.graph.ImportForecastResponse\x12S\n\x10\x41ppendTranscript\x12\x1e.graph.AppendTranscriptRequest\x1a\x1f.graph.AppendTranscriptResponse\x12_\n\x14GetSessionTranscript\x12\".graph.GetSessionTranscriptRequest\x1a#.graph.GetSessionTranscriptResponseB5Z3github.com/navi-prem/ecom-tts/graph-service/api;apib\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -33,39 +33,463 @@ if not _descriptor._USE_C_DESCRIPTORS:
   _globals['DESCRIPTOR']._loaded_options = None
   _globals['DESCRIPTOR']._serialized_options = b'Z3github.com/navi-prem/ecom-tts/graph-service/api;api'
   _globals['_PRODUCT_ATTRIBUTESENTRY']._loaded_options = None
-  _globals['_PRODUCT_ATTRIBUTESENTRY']._serialized_options = b'8\001'
+  _globals['_PRODUCT_ATTRIBUTESENTRY']._serialized_options = b'8\x01'
+  _globals['_CLONEPRODUCTREQUEST_SKUSENTRY']._loaded_options = None
+  _globals['_CLONEPRODUCTREQUEST_SKUSENTRY']._serialized_options = b'8\x01'
+  _globals['_PLACEORDERREQUEST'].fields_by_name['gift_card_code']._loaded_options = None
+  _globals['_PLACEORDERREQUEST'].fields_by_name['gift_card_code']._serialized_options = b'\x80\x01\x01'
+  _globals['_RETURN'].fields_by_name['reason']._loaded_options = None
+  _globals['_RETURN'].fields_by_name['reason']._serialized_options = b'\x80\x01\x01'
+  _globals['_CREATERETURNREQUEST'].fields_by_name['reason']._loaded_options = None
+  _globals['_CREATERETURNREQUEST'].fields_by_name['reason']._serialized_options = b'\x80\x01\x01'
+  _globals['_SHIPPINGADDRESS'].fields_by_name['postal_code']._loaded_options = None
+  _globals['_SHIPPINGADDRESS'].fields_by_name['postal_code']._serialized_options = b'\x80\x01\x01'
+  _globals['_SHIPPINGADDRESS'].fields_by_name['city']._loaded_options = None
+  _globals['_SHIPPINGADDRESS'].fields_by_name['city']._serialized_options = b'\x80\x01\x01'
+  _globals['_GIFTCARD'].fields_by_name['code']._loaded_options = None
+  _globals['_GIFTCARD'].fields_by_name['code']._serialized_options = b'\x80\x01\x01'
+  _globals['_GETBALANCEREQUEST'].fields_by_name['code']._loaded_options = None
+  _globals['_GETBALANCEREQUEST'].fields_by_name['code']._serialized_options = b'\x80\x01\x01'
+  _globals['_REDEEMGIFTCARDREQUEST'].fields_by_name['code']._loaded_options = None
+  _globals['_REDEEMGIFTCARDREQUEST'].fields_by_name['code']._serialized_options = b'\x80\x01\x01'
+  _globals['_SIZECHARTROW_MEASUREMENTSENTRY']._loaded_options = None
+  _globals['_SIZECHARTROW_MEASUREMENTSENTRY']._serialized_options = b'8\x01'
+  _globals['_SETCUSTOMERMEASUREMENTSREQUEST'].fields_by_name['measurements']._loaded_options = None
+  _globals['_SETCUSTOMERMEASUREMENTSREQUEST'].fields_by_name['measurements']._serialized_options = b'\x80\x01\x01'
+  _globals['_SETCUSTOMERMEASUREMENTSREQUEST_MEASUREMENTSENTRY']._loaded_options = None
+  _globals['_SETCUSTOMERMEASUREMENTSREQUEST_MEASUREMENTSENTRY']._serialized_options = b'8\x01'
+  _globals['_USERPREFERENCES_SIZESENTRY']._loaded_options = None
+  _globals['_USERPREFERENCES_SIZESENTRY']._serialized_options = b'8\x01'
+  _globals['_USER'].fields_by_name['email']._loaded_options = None
+  _globals['_USER'].fields_by_name['email']._serialized_options = b'\x80\x01\x01'
+  _globals['_USER'].fields_by_name['name']._loaded_options = None
+  _globals['_USER'].fields_by_name['name']._serialized_options = b'\x80\x01\x01'
+  _globals['_TRANSCRIPTENTRY'].fields_by_name['text']._loaded_options = None
+  _globals['_TRANSCRIPTENTRY'].fields_by_name['text']._serialized_options = b'\x80\x01\x01'
+  _globals['_TRANSCRIPTENTRY'].fields_by_name['data']._loaded_options = None
+  _globals['_TRANSCRIPTENTRY'].fields_by_name['data']._serialized_options = b'\x80\x01\x01'
+  _globals['_STOCKLEVEL']._serialized_start=17508
+  _globals['_STOCKLEVEL']._serialized_end=17567
+  _globals['_IDENTIFIERTYPE']._serialized_start=17569
+  _globals['_IDENTIFIERTYPE']._serialized_end=17658
+  _globals['_RESERVATIONSTATUS']._serialized_start=17660
+  _globals['_RESERVATIONSTATUS']._serialized_end=17735
+  _globals['_ORDERSTATUS']._serialized_start=17738
+  _globals['_ORDERSTATUS']._serialized_end=17898
+  _globals['_RETURNSTATUS']._serialized_start=17900
+  _globals['_RETURNSTATUS']._serialized_end=17979
+  _globals['_GIFTCARDTRANSACTIONKIND']._serialized_start=17981
+  _globals['_GIFTCARDTRANSACTIONKIND']._serialized_end=18045
+  _globals['_RECOMMENDATIONTYPE']._serialized_start=18047
+  _globals['_RECOMMENDATIONTYPE']._serialized_end=18095
+  _globals['_GRAPHFORMAT']._serialized_start=18097
+  _globals['_GRAPHFORMAT']._serialized_end=18143
+  _globals['_SYNCRUNSTATUS']._serialized_start=18145
+  _globals['_SYNCRUNSTATUS']._serialized_end=18215
   _globals['_PRODUCTCATEGORY']._serialized_start=22
   _globals['_PRODUCTCATEGORY']._serialized_end=106
-  _globals['_PRODUCTSIZE']._serialized_start=108
-  _globals['_PRODUCTSIZE']._serialized_end=199
-  _globals['_PRODUCT']._serialized_start=202
-  _globals['_PRODUCT']._serialized_end=537
-  _globals['_PRODUCT_ATTRIBUTESENTRY']._serialized_start=488
-  _globals['_PRODUCT_ATTRIBUTESENTRY']._serialized_end=537
-  _globals['_CREATEPRODUCTREQUEST']._serialized_start=539
-  _globals['_CREATEPRODUCTREQUEST']._serialized_end=594
-  _globals['_CREATEPRODUCTRESPONSE']._serialized_start=596
-  _globals['_CREATEPRODUCTRESPONSE']._serialized_end=631
-  _globals['_GETPRODUCTREQUEST']._serialized_start=633
-  _globals['_GETPRODUCTREQUEST']._serialized_end=664
-  _globals['_GETPRODUCTRESPONSE']._serialized_start=666
-  _globals['_GETPRODUCTRESPONSE']._serialized_end=719
-  _globals['_UPDATEPRODUCTREQUEST']._serialized_start=721
-  _globals['_UPDATEPRODUCTREQUEST']._serialized_end=776
-  _globals['_UPDATEPRODUCTRESPONSE']._serialized_start=778
-  _globals['_UPDATEPRODUCTRESPONSE']._serialized_end=818
-  _globals['_UPDATESTOCKREQUEST']._serialized_start=820
-  _globals['_UPDATESTOCKREQUEST']._serialized_end=892
-  _globals['_UPDATESTOCKRESPONSE']._serialized_start=894
-  _globals['_UPDATESTOCKRESPONSE']._serialized_end=932
-  _globals['_DELETEPRODUCTREQUEST']._serialized_start=934
-  _globals['_DELETEPRODUCTREQUEST']._serialized_end=968
-  _globals['_DELETEPRODUCTRESPONSE']._serialized_start=970
-  _globals['_DELETEPRODUCTRESPONSE']._serialized_end=1010
-  _globals['_SEARCHPRODUCTSREQUEST']._serialized_start=1012
-  _globals['_SEARCHPRODUCTSREQUEST']._serialized_end=1050
-  _globals['_SEARCHPRODUCTSRESPONSE']._serialized_start=1052
-  _globals['_SEARCHPRODUCTSRESPONSE']._serialized_end=1125
-  _globals['_GRAPHSERVICE']._serialized_start=1128
-  _globals['_GRAPHSERVICE']._serialized_end=1586
+  _globals['_PRODUCTSIZE']._serialized_start=109
+  _globals['_PRODUCTSIZE']._serialized_end=240
+  _globals['_PRODUCT']._serialized_start=243
+  _globals['_PRODUCT']._serialized_end=827
+  _globals['_PRODUCT_ATTRIBUTESENTRY']._serialized_start=778
+  _globals['_PRODUCT_ATTRIBUTESENTRY']._serialized_end=827
+  _globals['_SHIPPINGPROFILE']._serialized_start=829
+  _globals['_SHIPPINGPROFILE']._serialized_end=924
+  _globals['_CATALOGFILTER']._serialized_start=926
+  _globals['_CATALOGFILTER']._serialized_end=1052
+  _globals['_CREATEPRODUCTREQUEST']._serialized_start=1054
+  _globals['_CREATEPRODUCTREQUEST']._serialized_end=1109
+  _globals['_CREATEPRODUCTRESPONSE']._serialized_start=1111
+  _globals['_CREATEPRODUCTRESPONSE']._serialized_end=1146
+  _globals['_GETPRODUCTREQUEST']._serialized_start=1148
+  _globals['_GETPRODUCTREQUEST']._serialized_end=1195
+  _globals['_GETPRODUCTRESPONSE']._serialized_start=1197
+  _globals['_GETPRODUCTRESPONSE']._serialized_end=1298
+  _globals['_SIZEAVAILABILITY']._serialized_start=1300
+  _globals['_SIZEAVAILABILITY']._serialized_end=1397
+  _globals['_GETAVAILABILITYREQUEST']._serialized_start=1399
+  _globals['_GETAVAILABILITYREQUEST']._serialized_end=1443
+  _globals['_GETAVAILABILITYRESPONSE']._serialized_start=1445
+  _globals['_GETAVAILABILITYRESPONSE']._serialized_end=1530
+  _globals['_GETPRODUCTBYSLUGREQUEST']._serialized_start=1532
+  _globals['_GETPRODUCTBYSLUGREQUEST']._serialized_end=1587
+  _globals['_GETPRODUCTBYSLUGRESPONSE']._serialized_start=1589
+  _globals['_GETPRODUCTBYSLUGRESPONSE']._serialized_end=1648
+  _globals['_RESOLVEPRODUCTREQUEST']._serialized_start=1650
+  _globals['_RESOLVEPRODUCTREQUEST']._serialized_end=1741
+  _globals['_RESOLVEPRODUCTRESPONSE']._serialized_start=1743
+  _globals['_RESOLVEPRODUCTRESPONSE']._serialized_end=1845
+  _globals['_UPSERTPRODUCTTRANSLATIONREQUEST']._serialized_start=1847
+  _globals['_UPSERTPRODUCTTRANSLATIONREQUEST']._serialized_end=1951
+  _globals['_UPSERTPRODUCTTRANSLATIONRESPONSE']._serialized_start=1953
+  _globals['_UPSERTPRODUCTTRANSLATIONRESPONSE']._serialized_end=2004
+  _globals['_UPDATEPRODUCTREQUEST']._serialized_start=2006
+  _globals['_UPDATEPRODUCTREQUEST']._serialized_end=2061
+  _globals['_UPDATEPRODUCTRESPONSE']._serialized_start=2063
+  _globals['_UPDATEPRODUCTRESPONSE']._serialized_end=2140
+  _globals['_FIELDCHANGE']._serialized_start=2142
+  _globals['_FIELDCHANGE']._serialized_end=2208
+  _globals['_UPDATESTOCKREQUEST']._serialized_start=2210
+  _globals['_UPDATESTOCKREQUEST']._serialized_end=2282
+  _globals['_UPDATESTOCKRESPONSE']._serialized_start=2284
+  _globals['_UPDATESTOCKRESPONSE']._serialized_end=2322
+  _globals['_ADDPRODUCTSIZEREQUEST']._serialized_start=2324
+  _globals['_ADDPRODUCTSIZEREQUEST']._serialized_end=2401
+  _globals['_ADDPRODUCTSIZERESPONSE']._serialized_start=2403
+  _globals['_ADDPRODUCTSIZERESPONSE']._serialized_end=2444
+  _globals['_DELETEPRODUCTREQUEST']._serialized_start=2446
+  _globals['_DELETEPRODUCTREQUEST']._serialized_end=2480
+  _globals['_DELETEPRODUCTRESPONSE']._serialized_start=2482
+  _globals['_DELETEPRODUCTRESPONSE']._serialized_end=2522
+  _globals['_CLONEPRODUCTREQUEST']._serialized_start=2525
+  _globals['_CLONEPRODUCTREQUEST']._serialized_end=2723
+  _globals['_CLONEPRODUCTREQUEST_SKUSENTRY']._serialized_start=2680
+  _globals['_CLONEPRODUCTREQUEST_SKUSENTRY']._serialized_end=2723
+  _globals['_CLONEPRODUCTRESPONSE']._serialized_start=2725
+  _globals['_CLONEPRODUCTRESPONSE']._serialized_end=2780
+  _globals['_SETPRODUCTSARCHIVEDREQUEST']._serialized_start=2782
+  _globals['_SETPRODUCTSARCHIVEDREQUEST']._serialized_end=2849
+  _globals['_SETPRODUCTSARCHIVEDRESPONSE']._serialized_start=2851
+  _globals['_SETPRODUCTSARCHIVEDRESPONSE']._serialized_end=2897
+  _globals['_FINDDUPLICATEPRODUCTSREQUEST']._serialized_start=2899
+  _globals['_FINDDUPLICATEPRODUCTSREQUEST']._serialized_end=2987
+  _globals['_DUPLICATECLUSTER']._serialized_start=2989
+  _globals['_DUPLICATECLUSTER']._serialized_end=3048
+  _globals['_FINDDUPLICATEPRODUCTSRESPONSE']._serialized_start=3050
+  _globals['_FINDDUPLICATEPRODUCTSRESPONSE']._serialized_end=3124
+  _globals['_MERGEPRODUCTSREQUEST']._serialized_start=3126
+  _globals['_MERGEPRODUCTSREQUEST']._serialized_end=3192
+  _globals['_MERGEPRODUCTSRESPONSE']._serialized_start=3194
+  _globals['_MERGEPRODUCTSRESPONSE']._serialized_end=3250
+  _globals['_LISTPRODUCTSREQUEST']._serialized_start=3252
+  _globals['_LISTPRODUCTSREQUEST']._serialized_end=3337
+  _globals['_LISTPRODUCTSRESPONSE']._serialized_start=3339
+  _globals['_LISTPRODUCTSRESPONSE']._serialized_end=3420
+  _globals['_LISTPRODUCTSUPDATEDSINCEREQUEST']._serialized_start=3422
+  _globals['_LISTPRODUCTSUPDATEDSINCEREQUEST']._serialized_end=3509
+  _globals['_LISTPRODUCTSUPDATEDSINCERESPONSE']._serialized_start=3511
+  _globals['_LISTPRODUCTSUPDATEDSINCERESPONSE']._serialized_end=3604
+  _globals['_SEARCHPRODUCTSREQUEST']._serialized_start=3607
+  _globals['_SEARCHPRODUCTSREQUEST']._serialized_end=3800
+  _globals['_SEARCHHIGHLIGHT']._serialized_start=3802
+  _globals['_SEARCHHIGHLIGHT']._serialized_end=3873
+  _globals['_SEARCHPRODUCTSRESPONSE']._serialized_start=3876
+  _globals['_SEARCHPRODUCTSRESPONSE']._serialized_end=4018
+  _globals['_GETNEWARRIVALSREQUEST']._serialized_start=4020
+  _globals['_GETNEWARRIVALSREQUEST']._serialized_end=4142
+  _globals['_GETNEWARRIVALSRESPONSE']._serialized_start=4144
+  _globals['_GETNEWARRIVALSRESPONSE']._serialized_end=4227
+  _globals['_GETDEALSREQUEST']._serialized_start=4229
+  _globals['_GETDEALSREQUEST']._serialized_end=4323
+  _globals['_DEAL']._serialized_start=4325
+  _globals['_DEAL']._serialized_end=4390
+  _globals['_GETDEALSRESPONSE']._serialized_start=4392
+  _globals['_GETDEALSRESPONSE']._serialized_end=4463
+  _globals['_COLLECTION']._serialized_start=4465
+  _globals['_COLLECTION']._serialized_end=4583
+  _globals['_CREATECOLLECTIONREQUEST']._serialized_start=4585
+  _globals['_CREATECOLLECTIONREQUEST']._serialized_end=4649
+  _globals['_CREATECOLLECTIONRESPONSE']._serialized_start=4651
+  _globals['_CREATECOLLECTIONRESPONSE']._serialized_end=4689
+  _globals['_GETCOLLECTIONREQUEST']._serialized_start=4691
+  _globals['_GETCOLLECTIONREQUEST']._serialized_end=4725
+  _globals['_GETCOLLECTIONRESPONSE']._serialized_start=4727
+  _globals['_GETCOLLECTIONRESPONSE']._serialized_end=4823
+  _globals['_UPDATECOLLECTIONREQUEST']._serialized_start=4825
+  _globals['_UPDATECOLLECTIONREQUEST']._serialized_end=4889
+  _globals['_UPDATECOLLECTIONRESPONSE']._serialized_start=4891
+  _globals['_UPDATECOLLECTIONRESPONSE']._serialized_end=4934
+  _globals['_DELETECOLLECTIONREQUEST']._serialized_start=4936
+  _globals['_DELETECOLLECTIONREQUEST']._serialized_end=4973
+  _globals['_DELETECOLLECTIONRESPONSE']._serialized_start=4975
+  _globals['_DELETECOLLECTIONRESPONSE']._serialized_end=5018
+  _globals['_SETCOLLECTIONPRODUCTSREQUEST']._serialized_start=5020
+  _globals['_SETCOLLECTIONPRODUCTSREQUEST']._serialized_end=5094
+  _globals['_SETCOLLECTIONPRODUCTSRESPONSE']._serialized_start=5096
+  _globals['_SETCOLLECTIONPRODUCTSRESPONSE']._serialized_end=5144
+  _globals['_ADDPRODUCTTOCOLLECTIONREQUEST']._serialized_start=5146
+  _globals['_ADDPRODUCTTOCOLLECTIONREQUEST']._serialized_end=5238
+  _globals['_ADDPRODUCTTOCOLLECTIONRESPONSE']._serialized_start=5240
+  _globals['_ADDPRODUCTTOCOLLECTIONRESPONSE']._serialized_end=5289
+  _globals['_REMOVEPRODUCTFROMCOLLECTIONREQUEST']._serialized_start=5291
+  _globals['_REMOVEPRODUCTFROMCOLLECTIONREQUEST']._serialized_end=5370
+  _globals['_REMOVEPRODUCTFROMCOLLECTIONRESPONSE']._serialized_start=5372
+  _globals['_REMOVEPRODUCTFROMCOLLECTIONRESPONSE']._serialized_end=5426
+  _globals['_BUNDLECOMPONENT']._serialized_start=5428
+  _globals['_BUNDLECOMPONENT']._serialized_end=5476
+  _globals['_BUNDLE']._serialized_start=5479
+  _globals['_BUNDLE']._serialized_end=5699
+  _globals['_CREATEBUNDLEREQUEST']._serialized_start=5701
+  _globals['_CREATEBUNDLEREQUEST']._serialized_end=5753
+  _globals['_CREATEBUNDLERESPONSE']._serialized_start=5755
+  _globals['_CREATEBUNDLERESPONSE']._serialized_end=5789
+  _globals['_GETBUNDLEREQUEST']._serialized_start=5791
+  _globals['_GETBUNDLEREQUEST']._serialized_end=5821
+  _globals['_GETBUNDLERESPONSE']._serialized_start=5823
+  _globals['_GETBUNDLERESPONSE']._serialized_end=5873
+  _globals['_UPDATEBUNDLEREQUEST']._serialized_start=5875
+  _globals['_UPDATEBUNDLEREQUEST']._serialized_end=5927
+  _globals['_UPDATEBUNDLERESPONSE']._serialized_start=5929
+  _globals['_UPDATEBUNDLERESPONSE']._serialized_end=5968
+  _globals['_DELETEBUNDLEREQUEST']._serialized_start=5970
+  _globals['_DELETEBUNDLEREQUEST']._serialized_end=6003
+  _globals['_DELETEBUNDLERESPONSE']._serialized_start=6005
+  _globals['_DELETEBUNDLERESPONSE']._serialized_end=6044
+  _globals['_ORDERBUNDLEREQUEST']._serialized_start=6046
+  _globals['_ORDERBUNDLEREQUEST']._serialized_end=6103
+  _globals['_ORDERBUNDLERESPONSE']._serialized_start=6105
+  _globals['_ORDERBUNDLERESPONSE']._serialized_end=6143
+  _globals['_RESERVATIONITEM']._serialized_start=6145
+  _globals['_RESERVATIONITEM']._serialized_end=6193
+  _globals['_RESERVATION']._serialized_start=6196
+  _globals['_RESERVATION']._serialized_end=6381
+  _globals['_RESERVESTOCKREQUEST']._serialized_start=6383
+  _globals['_RESERVESTOCKREQUEST']._serialized_end=6495
+  _globals['_RESERVESTOCKRESPONSE']._serialized_start=6497
+  _globals['_RESERVESTOCKRESPONSE']._serialized_end=6560
+  _globals['_COMMITRESERVATIONREQUEST']._serialized_start=6562
+  _globals['_COMMITRESERVATIONREQUEST']._serialized_end=6600
+  _globals['_COMMITRESERVATIONRESPONSE']._serialized_start=6602
+  _globals['_COMMITRESERVATIONRESPONSE']._serialized_end=6646
+  _globals['_RELEASERESERVATIONREQUEST']._serialized_start=6648
+  _globals['_RELEASERESERVATIONREQUEST']._serialized_end=6687
+  _globals['_RELEASERESERVATIONRESPONSE']._serialized_start=6689
+  _globals['_RELEASERESERVATIONRESPONSE']._serialized_end=6734
+  _globals['_ORDERLINE']._serialized_start=6737
+  _globals['_ORDERLINE']._serialized_end=6924
+  _globals['_ORDER']._serialized_start=6927
+  _globals['_ORDER']._serialized_end=7271
+  _globals['_PLACEORDERREQUEST']._serialized_start=7274
+  _globals['_PLACEORDERREQUEST']._serialized_end=7543
+  _globals['_PLACEORDERRESPONSE']._serialized_start=7545
+  _globals['_PLACEORDERRESPONSE']._serialized_end=7594
+  _globals['_GETORDERREQUEST']._serialized_start=7596
+  _globals['_GETORDERREQUEST']._serialized_end=7625
+  _globals['_GETORDERRESPONSE']._serialized_start=7627
+  _globals['_GETORDERRESPONSE']._serialized_end=7674
+  _globals['_ORDERREVIEW']._serialized_start=7676
+  _globals['_ORDERREVIEW']._serialized_end=7738
+  _globals['_REVIEWFLAGGEDORDERSREQUEST']._serialized_start=7740
+  _globals['_REVIEWFLAGGEDORDERSREQUEST']._serialized_end=7839
+  _globals['_REVIEWFLAGGEDORDERSRESPONSE']._serialized_start=7841
+  _globals['_REVIEWFLAGGEDORDERSRESPONSE']._serialized_end=7933
+  _globals['_RETURNLINE']._serialized_start=7935
+  _globals['_RETURNLINE']._serialized_end=7978
+  _globals['_RETURN']._serialized_start=7981
+  _globals['_RETURN']._serialized_end=8193
+  _globals['_CREATERETURNREQUEST']._serialized_start=8195
+  _globals['_CREATERETURNREQUEST']._serialized_end=8301
+  _globals['_CREATERETURNRESPONSE']._serialized_start=8303
+  _globals['_CREATERETURNRESPONSE']._serialized_end=8362
+  _globals['_APPROVERETURNREQUEST']._serialized_start=8364
+  _globals['_APPROVERETURNREQUEST']._serialized_end=8398
+  _globals['_APPROVERETURNRESPONSE']._serialized_start=8400
+  _globals['_APPROVERETURNRESPONSE']._serialized_end=8460
+  _globals['_COMPLETERETURNREQUEST']._serialized_start=8462
+  _globals['_COMPLETERETURNREQUEST']._serialized_end=8497
+  _globals['_COMPLETERETURNRESPONSE']._serialized_start=8499
+  _globals['_COMPLETERETURNRESPONSE']._serialized_end=8560
+  _globals['_CARTLINE']._serialized_start=8562
+  _globals['_CARTLINE']._serialized_end=8603
+  _globals['_SHIPPINGADDRESS']._serialized_start=8605
+  _globals['_SHIPPINGADDRESS']._serialized_end=8700
+  _globals['_SHIPPINGRATE']._serialized_start=8702
+  _globals['_SHIPPINGRATE']._serialized_end=8820
+  _globals['_ESTIMATESHIPPINGREQUEST']._serialized_start=8822
+  _globals['_ESTIMATESHIPPINGREQUEST']._serialized_end=8924
+  _globals['_ESTIMATESHIPPINGRESPONSE']._serialized_start=8926
+  _globals['_ESTIMATESHIPPINGRESPONSE']._serialized_end=9019
+  _globals['_PRICECARTREQUEST']._serialized_start=9022
+  _globals['_PRICECARTREQUEST']._serialized_end=9177
+  _globals['_PRICECARTRESPONSE']._serialized_start=9180
+  _globals['_PRICECARTRESPONSE']._serialized_end=9345
+  _globals['_COUPON']._serialized_start=9348
+  _globals['_COUPON']._serialized_end=9635
+  _globals['_CREATECOUPONREQUEST']._serialized_start=9637
+  _globals['_CREATECOUPONREQUEST']._serialized_end=9689
+  _globals['_CREATECOUPONRESPONSE']._serialized_start=9691
+  _globals['_CREATECOUPONRESPONSE']._serialized_end=9744
+  _globals['_VALIDATECOUPONREQUEST']._serialized_start=9746
+  _globals['_VALIDATECOUPONREQUEST']._serialized_end=9836
+  _globals['_VALIDATECOUPONRESPONSE']._serialized_start=9838
+  _globals['_VALIDATECOUPONRESPONSE']._serialized_end=9965
+  _globals['_REDEEMCOUPONREQUEST']._serialized_start=9967
+  _globals['_REDEEMCOUPONREQUEST']._serialized_end=10041
+  _globals['_REDEEMCOUPONRESPONSE']._serialized_start=10043
+  _globals['_REDEEMCOUPONRESPONSE']._serialized_end=10096
+  _globals['_GIFTCARD']._serialized_start=10099
+  _globals['_GIFTCARD']._serialized_end=10279
+  _globals['_GIFTCARDTRANSACTION']._serialized_start=10282
+  _globals['_GIFTCARDTRANSACTION']._serialized_end=10432
+  _globals['_ISSUEGIFTCARDREQUEST']._serialized_start=10434
+  _globals['_ISSUEGIFTCARDREQUEST']._serialized_end=10487
+  _globals['_ISSUEGIFTCARDRESPONSE']._serialized_start=10489
+  _globals['_ISSUEGIFTCARDRESPONSE']._serialized_end=10543
+  _globals['_GETBALANCEREQUEST']._serialized_start=10545
+  _globals['_GETBALANCEREQUEST']._serialized_end=10583
+  _globals['_GETBALANCERESPONSE']._serialized_start=10585
+  _globals['_GETBALANCERESPONSE']._serialized_end=10686
+  _globals['_REDEEMGIFTCARDREQUEST']._serialized_start=10688
+  _globals['_REDEEMGIFTCARDREQUEST']._serialized_end=10782
+  _globals['_REDEEMGIFTCARDRESPONSE']._serialized_start=10784
+  _globals['_REDEEMGIFTCARDRESPONSE']._serialized_end=10888
+  _globals['_LOYALTYBALANCE']._serialized_start=10891
+  _globals['_LOYALTYBALANCE']._serialized_end=11031
+  _globals['_GETLOYALTYBALANCEREQUEST']._serialized_start=11033
+  _globals['_GETLOYALTYBALANCEREQUEST']._serialized_end=11080
+  _globals['_GETLOYALTYBALANCERESPONSE']._serialized_start=11082
+  _globals['_GETLOYALTYBALANCERESPONSE']._serialized_end=11149
+  _globals['_REDEEMPOINTSREQUEST']._serialized_start=11151
+  _globals['_REDEEMPOINTSREQUEST']._serialized_end=11228
+  _globals['_REDEEMPOINTSRESPONSE']._serialized_start=11230
+  _globals['_REDEEMPOINTSRESPONSE']._serialized_end=11292
+  _globals['_SETCROSSSELLREQUEST']._serialized_start=11294
+  _globals['_SETCROSSSELLREQUEST']._serialized_end=11364
+  _globals['_SETCROSSSELLRESPONSE']._serialized_start=11366
+  _globals['_SETCROSSSELLRESPONSE']._serialized_end=11405
+  _globals['_SETUPSELLREQUEST']._serialized_start=11407
+  _globals['_SETUPSELLREQUEST']._serialized_end=11474
+  _globals['_SETUPSELLRESPONSE']._serialized_start=11476
+  _globals['_SETUPSELLRESPONSE']._serialized_end=11512
+  _globals['_GETMERCHANDISEDRECOMMENDATIONSREQUEST']._serialized_start=11514
+  _globals['_GETMERCHANDISEDRECOMMENDATIONSREQUEST']._serialized_end=11629
+  _globals['_RECOMMENDATION']._serialized_start=11631
+  _globals['_RECOMMENDATION']._serialized_end=11697
+  _globals['_GETMERCHANDISEDRECOMMENDATIONSRESPONSE']._serialized_start=11699
+  _globals['_GETMERCHANDISEDRECOMMENDATIONSRESPONSE']._serialized_end=11787
+  _globals['_SIZECHARTROW']._serialized_start=11790
+  _globals['_SIZECHARTROW']._serialized_end=11932
+  _globals['_SIZECHARTROW_MEASUREMENTSENTRY']._serialized_start=11881
+  _globals['_SIZECHARTROW_MEASUREMENTSENTRY']._serialized_end=11932
+  _globals['_SIZECHART']._serialized_start=11935
+  _globals['_SIZECHART']._serialized_end=12113
+  _globals['_UPSERTSIZECHARTREQUEST']._serialized_start=12115
+  _globals['_UPSERTSIZECHARTREQUEST']._serialized_end=12172
+  _globals['_UPSERTSIZECHARTRESPONSE']._serialized_start=12174
+  _globals['_UPSERTSIZECHARTRESPONSE']._serialized_end=12216
+  _globals['_GETSIZECHARTREQUEST']._serialized_start=12218
+  _globals['_GETSIZECHARTREQUEST']._serialized_end=12318
+  _globals['_GETSIZECHARTRESPONSE']._serialized_start=12320
+  _globals['_GETSIZECHARTRESPONSE']._serialized_end=12375
+  _globals['_SETCUSTOMERMEASUREMENTSREQUEST']._serialized_start=12378
+  _globals['_SETCUSTOMERMEASUREMENTSREQUEST']._serialized_end=12582
+  _globals['_SETCUSTOMERMEASUREMENTSREQUEST_MEASUREMENTSENTRY']._serialized_start=12531
+  _globals['_SETCUSTOMERMEASUREMENTSREQUEST_MEASUREMENTSENTRY']._serialized_end=12582
+  _globals['_SETCUSTOMERMEASUREMENTSRESPONSE']._serialized_start=12584
+  _globals['_SETCUSTOMERMEASUREMENTSRESPONSE']._serialized_end=12634
+  _globals['_RECOMMENDSIZEREQUEST']._serialized_start=12636
+  _globals['_RECOMMENDSIZEREQUEST']._serialized_end=12699
+  _globals['_RECOMMENDSIZERESPONSE']._serialized_start=12701
+  _globals['_RECOMMENDSIZERESPONSE']._serialized_end=12818
+  _globals['_USERPREFERENCES']._serialized_start=12821
+  _globals['_USERPREFERENCES']._serialized_end=12975
+  _globals['_USERPREFERENCES_SIZESENTRY']._serialized_start=12931
+  _globals['_USERPREFERENCES_SIZESENTRY']._serialized_end=12975
+  _globals['_USER']._serialized_start=12978
+  _globals['_USER']._serialized_end=13139
+  _globals['_CREATEUSERREQUEST']._serialized_start=13141
+  _globals['_CREATEUSERREQUEST']._serialized_end=13187
+  _globals['_CREATEUSERRESPONSE']._serialized_start=13189
+  _globals['_CREATEUSERRESPONSE']._serialized_end=13236
+  _globals['_GETUSERREQUEST']._serialized_start=13238
+  _globals['_GETUSERREQUEST']._serialized_end=13266
+  _globals['_GETUSERRESPONSE']._serialized_start=13268
+  _globals['_GETUSERRESPONSE']._serialized_end=13312
+  _globals['_UPDATEPREFERENCESREQUEST']._serialized_start=13314
+  _globals['_UPDATEPREFERENCESREQUEST']._serialized_end=13402
+  _globals['_UPDATEPREFERENCESRESPONSE']._serialized_start=13404
+  _globals['_UPDATEPREFERENCESRESPONSE']._serialized_end=13458
+  _globals['_EXPORTUSERDATAREQUEST']._serialized_start=13460
+  _globals['_EXPORTUSERDATAREQUEST']._serialized_end=13500
+  _globals['_EXPORTUSERDATARESPONSE']._serialized_start=13502
+  _globals['_EXPORTUSERDATARESPONSE']._serialized_end=13562
+  _globals['_DELETEUSERDATAREQUEST']._serialized_start=13564
+  _globals['_DELETEUSERDATAREQUEST']._serialized_end=13620
+  _globals['_DELETEUSERDATARESPONSE']._serialized_start=13622
+  _globals['_DELETEUSERDATARESPONSE']._serialized_end=13733
+  _globals['_SAVEDSEARCH']._serialized_start=13736
+  _globals['_SAVEDSEARCH']._serialized_end=13911
+  _globals['_SAVESEARCHREQUEST']._serialized_start=13913
+  _globals['_SAVESEARCHREQUEST']._serialized_end=13974
+  _globals['_SAVESEARCHRESPONSE']._serialized_start=13976
+  _globals['_SAVESEARCHRESPONSE']._serialized_end=14008
+  _globals['_LISTSAVEDSEARCHESREQUEST']._serialized_start=14010
+  _globals['_LISTSAVEDSEARCHESREQUEST']._serialized_end=14053
+  _globals['_LISTSAVEDSEARCHESRESPONSE']._serialized_start=14055
+  _globals['_LISTSAVEDSEARCHESRESPONSE']._serialized_end=14126
+  _globals['_DELETESAVEDSEARCHREQUEST']._serialized_start=14128
+  _globals['_DELETESAVEDSEARCHREQUEST']._serialized_end=14183
+  _globals['_DELETESAVEDSEARCHRESPONSE']._serialized_start=14185
+  _globals['_DELETESAVEDSEARCHRESPONSE']._serialized_end=14229
+  _globals['_PRICEDROPSUBSCRIPTION']._serialized_start=14232
+  _globals['_PRICEDROPSUBSCRIPTION']._serialized_end=14393
+  _globals['_SUBSCRIBETOPRICEDROPREQUEST']._serialized_start=14395
+  _globals['_SUBSCRIBETOPRICEDROPREQUEST']._serialized_end=14461
+  _globals['_SUBSCRIBETOPRICEDROPRESPONSE']._serialized_start=14463
+  _globals['_SUBSCRIBETOPRICEDROPRESPONSE']._serialized_end=14545
+  _globals['_UNSUBSCRIBEFROMPRICEDROPREQUEST']._serialized_start=14547
+  _globals['_UNSUBSCRIBEFROMPRICEDROPREQUEST']._serialized_end=14617
+  _globals['_UNSUBSCRIBEFROMPRICEDROPRESPONSE']._serialized_start=14619
+  _globals['_UNSUBSCRIBEFROMPRICEDROPRESPONSE']._serialized_end=14670
+  _globals['_REGISTERWEBHOOKREQUEST']._serialized_start=14672
+  _globals['_REGISTERWEBHOOKREQUEST']._serialized_end=14746
+  _globals['_REGISTERWEBHOOKRESPONSE']._serialized_start=14748
+  _globals['_REGISTERWEBHOOKRESPONSE']._serialized_end=14785
+  _globals['_WEBHOOKDELIVERY']._serialized_start=14788
+  _globals['_WEBHOOKDELIVERY']._serialized_end=15041
+  _globals['_LISTDELIVERIESREQUEST']._serialized_start=15043
+  _globals['_LISTDELIVERIESREQUEST']._serialized_end=15137
+  _globals['_LISTDELIVERIESRESPONSE']._serialized_start=15139
+  _globals['_LISTDELIVERIESRESPONSE']._serialized_end=15232
+  _globals['_CATEGORYNODE']._serialized_start=15234
+  _globals['_CATEGORYNODE']._serialized_end=15324
+  _globals['_GETCATEGORYTREEREQUEST']._serialized_start=15326
+  _globals['_GETCATEGORYTREEREQUEST']._serialized_end=15369
+  _globals['_GETCATEGORYTREERESPONSE']._serialized_start=15371
+  _globals['_GETCATEGORYTREERESPONSE']._serialized_end=15437
+  _globals['_EXPORTSUBGRAPHREQUEST']._serialized_start=15440
+  _globals['_EXPORTSUBGRAPHREQUEST']._serialized_end=15595
+  _globals['_EXPORTSUBGRAPHRESPONSE']._serialized_start=15597
+  _globals['_EXPORTSUBGRAPHRESPONSE']._serialized_end=15716
+  _globals['_CATALOGISSUE']._serialized_start=15718
+  _globals['_CATALOGISSUE']._serialized_end=15782
+  _globals['_VALIDATECATALOGREQUEST']._serialized_start=15784
+  _globals['_VALIDATECATALOGREQUEST']._serialized_end=15848
+  _globals['_VALIDATECATALOGRESPONSE']._serialized_start=15850
+  _globals['_VALIDATECATALOGRESPONSE']._serialized_end=15938
+  _globals['_SYNCRUN']._serialized_start=15941
+  _globals['_SYNCRUN']._serialized_end=16198
+  _globals['_LISTSYNCRUNSREQUEST']._serialized_start=16200
+  _globals['_LISTSYNCRUNSREQUEST']._serialized_end=16252
+  _globals['_LISTSYNCRUNSRESPONSE']._serialized_start=16254
+  _globals['_LISTSYNCRUNSRESPONSE']._serialized_end=16306
+  _globals['_STOCKHISTORYDAY']._serialized_start=16308
+  _globals['_STOCKHISTORYDAY']._serialized_end=16381
+  _globals['_SKUSTOCKHISTORY']._serialized_start=16383
+  _globals['_SKUSTOCKHISTORY']._serialized_end=16471
+  _globals['_EXPORTSTOCKHISTORYREQUEST']._serialized_start=16473
+  _globals['_EXPORTSTOCKHISTORYREQUEST']._serialized_end=16568
+  _globals['_EXPORTSTOCKHISTORYRESPONSE']._serialized_start=16570
+  _globals['_EXPORTSTOCKHISTORYRESPONSE']._serialized_end=16661
+  _globals['_SKUFORECAST']._serialized_start=16663
+  _globals['_SKUFORECAST']._serialized_end=16730
+  _globals['_IMPORTFORECASTREQUEST']._serialized_start=16732
+  _globals['_IMPORTFORECASTREQUEST']._serialized_end=16809
+  _globals['_IMPORTFORECASTRESPONSE']._serialized_start=16811
+  _globals['_IMPORTFORECASTRESPONSE']._serialized_end=16875
+  _globals['_TRANSCRIPTENTRY']._serialized_start=16877
+  _globals['_TRANSCRIPTENTRY']._serialized_end=16980
+  _globals['_SESSIONTRANSCRIPT']._serialized_start=16983
+  _globals['_SESSIONTRANSCRIPT']._serialized_end=17143
+  _globals['_APPENDTRANSCRIPTREQUEST']._serialized_start=17145
+  _globals['_APPENDTRANSCRIPTREQUEST']._serialized_end=17271
+  _globals['_APPENDTRANSCRIPTRESPONSE']._serialized_start=17273
+  _globals['_APPENDTRANSCRIPTRESPONSE']._serialized_end=17317
+  _globals['_GETSESSIONTRANSCRIPTREQUEST']._serialized_start=17319
+  _globals['_GETSESSIONTRANSCRIPTREQUEST']._serialized_end=17403
+  _globals['_GETSESSIONTRANSCRIPTRESPONSE']._serialized_start=17405
+  _globals['_GETSESSIONTRANSCRIPTRESPONSE']._serialized_end=17506
+  _globals['_GRAPHSERVICE']._serialized_start=18218
+  _globals['_GRAPHSERVICE']._serialized_end=24489
 # @@protoc_insertion_point(module_scope)
@@ -44,6 +44,26 @@ class GraphServiceStub(object):
                 request_serializer=graph__pb2.GetProductRequest.SerializeToString,
                 response_deserializer=graph__pb2.GetProductResponse.FromString,
                 _registered_method=True)
+        self.GetAvailability = channel.unary_unary(
+                '/graph.GraphService/GetAvailability',
+                request_serializer=graph__pb2.GetAvailabilityRequest.SerializeToString,
+                response_deserializer=graph__pb2.GetAvailabilityResponse.FromString,
+                _registered_method=True)
+        self.ResolveProduct = channel.unary_unary(
+                '/graph.GraphService/ResolveProduct',
+                request_serializer=graph__pb2.ResolveProductRequest.SerializeToString,
+                response_deserializer=graph__pb2.ResolveProductResponse.FromString,
+                _registered_method=True)
+        self.GetProductBySlug = channel.unary_unary(
+                '/graph.GraphService/GetProductBySlug',
+                request_serializer=graph__pb2.GetProductBySlugRequest.SerializeToString,
+                response_deserializer=graph__pb2.GetProductBySlugResponse.FromString,
+                _registered_method=True)
+        self.UpsertProductTranslation = channel.unary_unary(
+                '/graph.GraphService/UpsertProductTranslation',
+                request_serializer=graph__pb2.UpsertProductTranslationRequest.SerializeToString,
+                response_deserializer=graph__pb2.UpsertProductTranslationResponse.FromString,
+                _registered_method=True)
         self.UpdateProduct = channel.unary_unary(
                 '/graph.GraphService/UpdateProduct',
                 request_serializer=graph__pb2.UpdateProductRequest.SerializeToString,
@@ -54,16 +74,351 @@ class GraphServiceStub(object):
                 request_serializer=graph__pb2.DeleteProductRequest.SerializeToString,
                 response_deserializer=graph__pb2.DeleteProductResponse.FromString,
                 _registered_method=True)
+        self.CloneProduct = channel.unary_unary(
+                '/graph.GraphService/CloneProduct',
+                request_serializer=graph__pb2.CloneProductRequest.SerializeToString,
+                response_deserializer=graph__pb2.CloneProductResponse.FromString,
+                _registered_method=True)
+        self.SetProductsArchived = channel.unary_unary(
+                '/graph.GraphService/SetProductsArchived',
+                request_serializer=graph__pb2.SetProductsArchivedRequest.SerializeToString,
+                response_deserializer=graph__pb2.SetProductsArchivedResponse.FromString,
+                _registered_method=True)
+        self.FindDuplicateProducts = channel.unary_unary(
+                '/graph.GraphService/FindDuplicateProducts',
+                request_serializer=graph__pb2.FindDuplicateProductsRequest.SerializeToString,
+                response_deserializer=graph__pb2.FindDuplicateProductsResponse.FromString,
+                _registered_method=True)
+        self.MergeProducts = channel.unary_unary(
+                '/graph.GraphService/MergeProducts',
+                request_serializer=graph__pb2.MergeProductsRequest.SerializeToString,
+                response_deserializer=graph__pb2.MergeProductsResponse.FromString,
+                _registered_method=True)
         self.UpdateStock = channel.unary_unary(
                 '/graph.GraphService/UpdateStock',
                 request_serializer=graph__pb2.UpdateStockRequest.SerializeToString,
                 response_deserializer=graph__pb2.UpdateStockResponse.FromString,
                 _registered_method=True)
+        self.AddProductSize = channel.unary_unary(
+                '/graph.GraphService/AddProductSize',
+                request_serializer=graph__pb2.AddProductSizeRequest.SerializeToString,
+                response_deserializer=graph__pb2.AddProductSizeResponse.FromString,
+                _registered_method=True)
+        self.ListProducts = channel.unary_unary(
+                '/graph.GraphService/ListProducts',
+                request_serializer=graph__pb2.ListProductsRequest.SerializeToString,
+                response_deserializer=graph__pb2.ListProductsResponse.FromString,
+                _registered_method=True)
+        self.ListProductsUpdatedSince = channel.unary_unary(
+                '/graph.GraphService/ListProductsUpdatedSince',
+                request_serializer=graph__pb2.ListProductsUpdatedSinceRequest.SerializeToString,
+                response_deserializer=graph__pb2.ListProductsUpdatedSinceResponse.FromString,
+                _registered_method=True)
         self.SearchProducts = channel.unary_unary(
                 '/graph.GraphService/SearchProducts',
                 request_serializer=graph__pb2.SearchProductsRequest.SerializeToString,
                 response_deserializer=graph__pb2.SearchProductsResponse.FromString,
                 _registered_method=True)
+        self.GetNewArrivals = channel.unary_unary(
+                '/graph.GraphService/GetNewArrivals',
+                request_serializer=graph__pb2.GetNewArrivalsRequest.SerializeToString,
+                response_deserializer=graph__pb2.GetNewArrivalsResponse.FromString,
+                _registered_method=True)
+        self.GetDeals = channel.unary_unary(
+                '/graph.GraphService/GetDeals',
+                request_serializer=graph__pb2.GetDealsRequest.SerializeToString,
+                response_deserializer=graph__pb2.GetDealsResponse.FromString,
+                _registered_method=True)
+        self.CreateCollection = channel.unary_unary(
+                '/graph.GraphService/CreateCollection',
+                request_serializer=graph__pb2.CreateCollectionRequest.SerializeToString,
+                response_deserializer=graph__pb2.CreateCollectionResponse.FromString,
+                _registered_method=True)
+        self.GetCollection = channel.unary_unary(
+                '/graph.GraphService/GetCollection',
+                request_serializer=graph__pb2.GetCollectionRequest.SerializeToString,
+                response_deserializer=graph__pb2.GetCollectionResponse.FromString,
+                _registered_method=True)
+        self.UpdateCollection = channel.unary_unary(
+                '/graph.GraphService/UpdateCollection',
+                request_serializer=graph__pb2.UpdateCollectionRequest.SerializeToString,
+                response_deserializer=graph__pb2.UpdateCollectionResponse.FromString,
+                _registered_method=True)
+        self.DeleteCollection = channel.unary_unary(
+                '/graph.GraphService/DeleteCollection',
+                request_serializer=graph__pb2.DeleteCollectionRequest.SerializeToString,
+                response_deserializer=graph__pb2.DeleteCollectionResponse.FromString,
+                _registered_method=True)
+        self.SetCollectionProducts = channel.unary_unary(
+                '/graph.GraphService/SetCollectionProducts',
+                request_serializer=graph__pb2.SetCollectionProductsRequest.SerializeToString,
+                response_deserializer=graph__pb2.SetCollectionProductsResponse.FromString,
+                _registered_method=True)
+        self.AddProductToCollection = channel.unary_unary(
+                '/graph.GraphService/AddProductToCollection',
+                request_serializer=graph__pb2.AddProductToCollectionRequest.SerializeToString,
+                response_deserializer=graph__pb2.AddProductToCollectionResponse.FromString,
+                _registered_method=True)
+        self.RemoveProductFromCollection = channel.unary_unary(
+                '/graph.GraphService/RemoveProductFromCollection',
+                request_serializer=graph__pb2.RemoveProductFromCollectionRequest.SerializeToString,
+                response_deserializer=graph__pb2.RemoveProductFromCollectionResponse.FromString,
+                _registered_method=True)
+        self.CreateBundle = channel.unary_unary(
+                '/graph.GraphService/CreateBundle',
+                request_serializer=graph__pb2.CreateBundleRequest.SerializeToString,
+                response_deserializer=graph__pb2.CreateBundleResponse.FromString,
+                _registered_method=True)
+        self.GetBundle = channel.unary_unary(
+                '/graph.GraphService/GetBundle',
+                request_serializer=graph__pb2.GetBundleRequest.SerializeToString,
+                response_deserializer=graph__pb2.GetBundleResponse.FromString,
+                _registered_method=True)
+        self.UpdateBundle = channel.unary_unary(
+                '/graph.GraphService/UpdateBundle',
+                request_serializer=graph__pb2.UpdateBundleRequest.SerializeToString,
+                response_deserializer=graph__pb2.UpdateBundleResponse.FromString,
+                _registered_method=True)
+        self.DeleteBundle = channel.unary_unary(
+                '/graph.GraphService/DeleteBundle',
+                request_serializer=graph__pb2.DeleteBundleRequest.SerializeToString,
+                response_deserializer=graph__pb2.DeleteBundleResponse.FromString,
+                _registered_method=True)
+        self.OrderBundle = channel.unary_unary(
+                '/graph.GraphService/OrderBundle',
+                request_serializer=graph__pb2.OrderBundleRequest.SerializeToString,
+                response_deserializer=graph__pb2.OrderBundleResponse.FromString,
+                _registered_method=True)
+        self.ReserveStock = channel.unary_unary(
+                '/graph.GraphService/ReserveStock',
+                request_serializer=graph__pb2.ReserveStockRequest.SerializeToString,
+                response_deserializer=graph__pb2.ReserveStockResponse.FromString,
+                _registered_method=True)
+        self.CommitReservation = channel.unary_unary(
+                '/graph.GraphService/CommitReservation',
+                request_serializer=graph__pb2.CommitReservationRequest.SerializeToString,
+                response_deserializer=graph__pb2.CommitReservationResponse.FromString,
+                _registered_method=True)
+        self.ReleaseReservation = channel.unary_unary(
+                '/graph.GraphService/ReleaseReservation',
+                request_serializer=graph__pb2.ReleaseReservationRequest.SerializeToString,
+                response_deserializer=graph__pb2.ReleaseReservationResponse.FromString,
+                _registered_method=True)
+        self.PlaceOrder = channel.unary_unary(
+                '/graph.GraphService/PlaceOrder',
+                request_serializer=graph__pb2.PlaceOrderRequest.SerializeToString,
+                response_deserializer=graph__pb2.PlaceOrderResponse.FromString,
+                _registered_method=True)
+        self.GetOrder = channel.unary_unary(
+                '/graph.GraphService/GetOrder',
+                request_serializer=graph__pb2.GetOrderRequest.SerializeToString,
+                response_deserializer=graph__pb2.GetOrderResponse.FromString,
+                _registered_method=True)
+        self.ReviewFlaggedOrders = channel.unary_unary(
+                '/graph.GraphService/ReviewFlaggedOrders',
+                request_serializer=graph__pb2.ReviewFlaggedOrdersRequest.SerializeToString,
+                response_deserializer=graph__pb2.ReviewFlaggedOrdersResponse.FromString,
+                _registered_method=True)
+        self.CreateReturn = channel.unary_unary(
+                '/graph.GraphService/CreateReturn',
+                request_serializer=graph__pb2.CreateReturnRequest.SerializeToString,
+                response_deserializer=graph__pb2.CreateReturnResponse.FromString,
+                _registered_method=True)
+        self.ApproveReturn = channel.unary_unary(
+                '/graph.GraphService/ApproveReturn',
+                request_serializer=graph__pb2.ApproveReturnRequest.SerializeToString,
+                response_deserializer=graph__pb2.ApproveReturnResponse.FromString,
+                _registered_method=True)
+        self.CompleteReturn = channel.unary_unary(
+                '/graph.GraphService/CompleteReturn',
+                request_serializer=graph__pb2.CompleteReturnRequest.SerializeToString,
+                response_deserializer=graph__pb2.CompleteReturnResponse.FromString,
+                _registered_method=True)
+        self.EstimateShipping = channel.unary_unary(
+                '/graph.GraphService/EstimateShipping',
+                request_serializer=graph__pb2.EstimateShippingRequest.SerializeToString,
+                response_deserializer=graph__pb2.EstimateShippingResponse.FromString,
+                _registered_method=True)
+        self.PriceCart = channel.unary_unary(
+                '/graph.GraphService/PriceCart',
+                request_serializer=graph__pb2.PriceCartRequest.SerializeToString,
+                response_deserializer=graph__pb2.PriceCartResponse.FromString,
+                _registered_method=True)
+        self.CreateCoupon = channel.unary_unary(
+                '/graph.GraphService/CreateCoupon',
+                request_serializer=graph__pb2.CreateCouponRequest.SerializeToString,
+                response_deserializer=graph__pb2.CreateCouponResponse.FromString,
+                _registered_method=True)
+        self.ValidateCoupon = channel.unary_unary(
+                '/graph.GraphService/ValidateCoupon',
+                request_serializer=graph__pb2.ValidateCouponRequest.SerializeToString,
+                response_deserializer=graph__pb2.ValidateCouponResponse.FromString,
+                _registered_method=True)
+        self.RedeemCoupon = channel.unary_unary(
+                '/graph.GraphService/RedeemCoupon',
+                request_serializer=graph__pb2.RedeemCouponRequest.SerializeToString,
+                response_deserializer=graph__pb2.RedeemCouponResponse.FromString,
+                _registered_method=True)
+        self.IssueGiftCard = channel.unary_unary(
+                '/graph.GraphService/IssueGiftCard',
+                request_serializer=graph__pb2.IssueGiftCardRequest.SerializeToString,
+                response_deserializer=graph__pb2.IssueGiftCardResponse.FromString,
+                _registered_method=True)
+        self.GetBalance = channel.unary_unary(
+                '/graph.GraphService/GetBalance',
+                request_serializer=graph__pb2.GetBalanceRequest.SerializeToString,
+                response_deserializer=graph__pb2.GetBalanceResponse.FromString,
+                _registered_method=True)
+        self.RedeemGiftCard = channel.unary_unary(
+                '/graph.GraphService/RedeemGiftCard',
+                request_serializer=graph__pb2.RedeemGiftCardRequest.SerializeToString,
+                response_deserializer=graph__pb2.RedeemGiftCardResponse.FromString,
+                _registered_method=True)
+        self.GetLoyaltyBalance = channel.unary_unary(
+                '/graph.GraphService/GetLoyaltyBalance',
+                request_serializer=graph__pb2.GetLoyaltyBalanceRequest.SerializeToString,
+                response_deserializer=graph__pb2.GetLoyaltyBalanceResponse.FromString,
+                _registered_method=True)
+        self.RedeemPoints = channel.unary_unary(
+                '/graph.GraphService/RedeemPoints',
+                request_serializer=graph__pb2.RedeemPointsRequest.SerializeToString,
+                response_deserializer=graph__pb2.RedeemPointsResponse.FromString,
+                _registered_method=True)
+        self.SetCrossSell = channel.unary_unary(
+                '/graph.GraphService/SetCrossSell',
+                request_serializer=graph__pb2.SetCrossSellRequest.SerializeToString,
+                response_deserializer=graph__pb2.SetCrossSellResponse.FromString,
+                _registered_method=True)
+        self.SetUpsell = channel.unary_unary(
+                '/graph.GraphService/SetUpsell',
+                request_serializer=graph__pb2.SetUpsellRequest.SerializeToString,
+                response_deserializer=graph__pb2.SetUpsellResponse.FromString,
+                _registered_method=True)
+        self.GetMerchandisedRecommendations = channel.unary_unary(
+                '/graph.GraphService/GetMerchandisedRecommendations',
+                request_serializer=graph__pb2.GetMerchandisedRecommendationsRequest.SerializeToString,
+                response_deserializer=graph__pb2.GetMerchandisedRecommendationsResponse.FromString,
+                _registered_method=True)
+        self.UpsertSizeChart = channel.unary_unary(
+                '/graph.GraphService/UpsertSizeChart',
+                request_serializer=graph__pb2.UpsertSizeChartRequest.SerializeToString,
+                response_deserializer=graph__pb2.UpsertSizeChartResponse.FromString,
+                _registered_method=True)
+        self.GetSizeChart = channel.unary_unary(
+                '/graph.GraphService/GetSizeChart',
+                request_serializer=graph__pb2.GetSizeChartRequest.SerializeToString,
+                response_deserializer=graph__pb2.GetSizeChartResponse.FromString,
+                _registered_method=True)
+        self.SetCustomerMeasurements = channel.unary_unary(
+                '/graph.GraphService/SetCustomerMeasurements',
+                request_serializer=graph__pb2.SetCustomerMeasurementsRequest.SerializeToString,
+                response_deserializer=graph__pb2.SetCustomerMeasurementsResponse.FromString,
+                _registered_method=True)
+        self.RecommendSize = channel.unary_unary(
+                '/graph.GraphService/RecommendSize',
+                request_serializer=graph__pb2.RecommendSizeRequest.SerializeToString,
+                response_deserializer=graph__pb2.RecommendSizeResponse.FromString,
+                _registered_method=True)
+        self.CreateUser = channel.unary_unary(
+                '/graph.GraphService/CreateUser',
+                request_serializer=graph__pb2.CreateUserRequest.SerializeToString,
+                response_deserializer=graph__pb2.CreateUserResponse.FromString,
+                _registered_method=True)
+        self.GetUser = channel.unary_unary(
+                '/graph.GraphService/GetUser',
+                request_serializer=graph__pb2.GetUserRequest.SerializeToString,
+                response_deserializer=graph__pb2.GetUserResponse.FromString,
+                _registered_method=True)
+        self.UpdatePreferences = channel.unary_unary(
+                '/graph.GraphService/UpdatePreferences',
+                request_serializer=graph__pb2.UpdatePreferencesRequest.SerializeToString,
+                response_deserializer=graph__pb2.UpdatePreferencesResponse.FromString,
+                _registered_method=True)
+        self.ExportUserData = channel.unary_unary(
+                '/graph.GraphService/ExportUserData',
+                request_serializer=graph__pb2.ExportUserDataRequest.SerializeToString,
+                response_deserializer=graph__pb2.ExportUserDataResponse.FromString,
+                _registered_method=True)
+        self.DeleteUserData = channel.unary_unary(
+                '/graph.GraphService/DeleteUserData',
+                request_serializer=graph__pb2.DeleteUserDataRequest.SerializeToString,
+                response_deserializer=graph__pb2.DeleteUserDataResponse.FromString,
+                _registered_method=True)
+        self.SaveSearch = channel.unary_unary(
+                '/graph.GraphService/SaveSearch',
+                request_serializer=graph__pb2.SaveSearchRequest.SerializeToString,
+                response_deserializer=graph__pb2.SaveSearchResponse.FromString,
+                _registered_method=True)
+        self.ListSavedSearches = channel.unary_unary(
+                '/graph.GraphService/ListSavedSearches',
+                request_serializer=graph__pb2.ListSavedSearchesRequest.SerializeToString,
+                response_deserializer=graph__pb2.ListSavedSearchesResponse.FromString,
+                _registered_method=True)
+        self.DeleteSavedSearch = channel.unary_unary(
+                '/graph.GraphService/DeleteSavedSearch',
+                request_serializer=graph__pb2.DeleteSavedSearchRequest.SerializeToString,
+                response_deserializer=graph__pb2.DeleteSavedSearchResponse.FromString,
+                _registered_method=True)
+        self.SubscribeToPriceDrop = channel.unary_unary(
+                '/graph.GraphService/SubscribeToPriceDrop',
+                request_serializer=graph__pb2.SubscribeToPriceDropRequest.SerializeToString,
+                response_deserializer=graph__pb2.SubscribeToPriceDropResponse.FromString,
+                _registered_method=True)
+        self.UnsubscribeFromPriceDrop = channel.unary_unary(
+                '/graph.GraphService/UnsubscribeFromPriceDrop',
+                request_serializer=graph__pb2.UnsubscribeFromPriceDropRequest.SerializeToString,
+                response_deserializer=graph__pb2.UnsubscribeFromPriceDropResponse.FromString,
+                _registered_method=True)
+        self.RegisterWebhook = channel.unary_unary(
+                '/graph.GraphService/RegisterWebhook',
+                request_serializer=graph__pb2.RegisterWebhookRequest.SerializeToString,
+                response_deserializer=graph__pb2.RegisterWebhookResponse.FromString,
+                _registered_method=True)
+        self.ListDeliveries = channel.unary_unary(
+                '/graph.GraphService/ListDeliveries',
+                request_serializer=graph__pb2.ListDeliveriesRequest.SerializeToString,
+                response_deserializer=graph__pb2.ListDeliveriesResponse.FromString,
+                _registered_method=True)
+        self.GetCategoryTree = channel.unary_unary(
+                '/graph.GraphService/GetCategoryTree',
+                request_serializer=graph__pb2.GetCategoryTreeRequest.SerializeToString,
+                response_deserializer=graph__pb2.GetCategoryTreeResponse.FromString,
+                _registered_method=True)
+        self.ExportSubgraph = channel.unary_unary(
+                '/graph.GraphService/ExportSubgraph',
+                request_serializer=graph__pb2.ExportSubgraphRequest.SerializeToString,
+                response_deserializer=graph__pb2.ExportSubgraphResponse.FromString,
+                _registered_method=True)
+        self.ListSyncRuns = channel.unary_unary(
+                '/graph.GraphService/ListSyncRuns',
+                request_serializer=graph__pb2.ListSyncRunsRequest.SerializeToString,
+                response_deserializer=graph__pb2.ListSyncRunsResponse.FromString,
+                _registered_method=True)
+        self.ValidateCatalog = channel.unary_unary(
+                '/graph.GraphService/ValidateCatalog',
+                request_serializer=graph__pb2.ValidateCatalogRequest.SerializeToString,
+                response_deserializer=graph__pb2.ValidateCatalogResponse.FromString,
+                _registered_method=True)
+        self.ExportStockHistory = channel.unary_unary(
+                '/graph.GraphService/ExportStockHistory',
+                request_serializer=graph__pb2.ExportStockHistoryRequest.SerializeToString,
+                response_deserializer=graph__pb2.ExportStockHistoryResponse.FromString,
+                _registered_method=True)
+        self.ImportForecast = channel.unary_unary(
+                '/graph.GraphService/ImportForecast',
+                request_serializer=graph__pb2.ImportForecastRequest.SerializeToString,
+                response_deserializer=graph__pb2.ImportForecastResponse.FromString,
+                _registered_method=True)
+        self.AppendTranscript = channel.unary_unary(
+                '/graph.GraphService/AppendTranscript',
+                request_serializer=graph__pb2.AppendTranscriptRequest.SerializeToString,
+                response_deserializer=graph__pb2.AppendTranscriptResponse.FromString,
+                _registered_method=True)
+        self.GetSessionTranscript = channel.unary_unary(
+                '/graph.GraphService/GetSessionTranscript',
+                request_serializer=graph__pb2.GetSessionTranscriptRequest.SerializeToString,
+                response_deserializer=graph__pb2.GetSessionTranscriptResponse.FromString,
+                _registered_method=True)
 
 
 class GraphServiceServicer(object):
@@ -81,76 +436,2747 @@ class GraphServiceServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def GetAvailability(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ResolveProduct(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetProductBySlug(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def UpsertProductTranslation(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def UpdateProduct(self, request, context):
         """Missing associated documentation comment in .proto file."""
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
-    def DeleteProduct(self, request, context):
-        """Missing associated documentation comment in .proto file."""
-        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
-        context.set_details('Method not implemented!')
-        raise NotImplementedError('Method not implemented!')
+    def DeleteProduct(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CloneProduct(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SetProductsArchived(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def FindDuplicateProducts(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def MergeProducts(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def UpdateStock(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def AddProductSize(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListProducts(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListProductsUpdatedSince(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SearchProducts(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetNewArrivals(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetDeals(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CreateCollection(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetCollection(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def UpdateCollection(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DeleteCollection(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SetCollectionProducts(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def AddProductToCollection(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RemoveProductFromCollection(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CreateBundle(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetBundle(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def UpdateBundle(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DeleteBundle(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def OrderBundle(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ReserveStock(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CommitReservation(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ReleaseReservation(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def PlaceOrder(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetOrder(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ReviewFlaggedOrders(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CreateReturn(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ApproveReturn(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CompleteReturn(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def EstimateShipping(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def PriceCart(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CreateCoupon(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ValidateCoupon(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RedeemCoupon(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def IssueGiftCard(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetBalance(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RedeemGiftCard(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetLoyaltyBalance(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RedeemPoints(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SetCrossSell(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SetUpsell(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetMerchandisedRecommendations(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def UpsertSizeChart(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetSizeChart(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SetCustomerMeasurements(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RecommendSize(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CreateUser(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetUser(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def UpdatePreferences(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ExportUserData(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DeleteUserData(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SaveSearch(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListSavedSearches(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DeleteSavedSearch(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SubscribeToPriceDrop(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def UnsubscribeFromPriceDrop(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RegisterWebhook(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListDeliveries(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetCategoryTree(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ExportSubgraph(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListSyncRuns(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ValidateCatalog(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ExportStockHistory(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ImportForecast(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def AppendTranscript(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetSessionTranscript(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+
+def add_GraphServiceServicer_to_server(servicer, server):
+    rpc_method_handlers = {
+            'CreateProduct': grpc.unary_unary_rpc_method_handler(
+                    servicer.CreateProduct,
+                    request_deserializer=graph__pb2.CreateProductRequest.FromString,
+                    response_serializer=graph__pb2.CreateProductResponse.SerializeToString,
+            ),
+            'GetProduct': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetProduct,
+                    request_deserializer=graph__pb2.GetProductRequest.FromString,
+                    response_serializer=graph__pb2.GetProductResponse.SerializeToString,
+            ),
+            'GetAvailability': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetAvailability,
+                    request_deserializer=graph__pb2.GetAvailabilityRequest.FromString,
+                    response_serializer=graph__pb2.GetAvailabilityResponse.SerializeToString,
+            ),
+            'ResolveProduct': grpc.unary_unary_rpc_method_handler(
+                    servicer.ResolveProduct,
+                    request_deserializer=graph__pb2.ResolveProductRequest.FromString,
+                    response_serializer=graph__pb2.ResolveProductResponse.SerializeToString,
+            ),
+            'GetProductBySlug': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetProductBySlug,
+                    request_deserializer=graph__pb2.GetProductBySlugRequest.FromString,
+                    response_serializer=graph__pb2.GetProductBySlugResponse.SerializeToString,
+            ),
+            'UpsertProductTranslation': grpc.unary_unary_rpc_method_handler(
+                    servicer.UpsertProductTranslation,
+                    request_deserializer=graph__pb2.UpsertProductTranslationRequest.FromString,
+                    response_serializer=graph__pb2.UpsertProductTranslationResponse.SerializeToString,
+            ),
+            'UpdateProduct': grpc.unary_unary_rpc_method_handler(
+                    servicer.UpdateProduct,
+                    request_deserializer=graph__pb2.UpdateProductRequest.FromString,
+                    response_serializer=graph__pb2.UpdateProductResponse.SerializeToString,
+            ),
+            'DeleteProduct': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteProduct,
+                    request_deserializer=graph__pb2.DeleteProductRequest.FromString,
+                    response_serializer=graph__pb2.DeleteProductResponse.SerializeToString,
+            ),
+            'CloneProduct': grpc.unary_unary_rpc_method_handler(
+                    servicer.CloneProduct,
+                    request_deserializer=graph__pb2.CloneProductRequest.FromString,
+                    response_serializer=graph__pb2.CloneProductResponse.SerializeToString,
+            ),
+            'SetProductsArchived': grpc.unary_unary_rpc_method_handler(
+                    servicer.SetProductsArchived,
+                    request_deserializer=graph__pb2.SetProductsArchivedRequest.FromString,
+                    response_serializer=graph__pb2.SetProductsArchivedResponse.SerializeToString,
+            ),
+            'FindDuplicateProducts': grpc.unary_unary_rpc_method_handler(
+                    servicer.FindDuplicateProducts,
+                    request_deserializer=graph__pb2.FindDuplicateProductsRequest.FromString,
+                    response_serializer=graph__pb2.FindDuplicateProductsResponse.SerializeToString,
+            ),
+            'MergeProducts': grpc.unary_unary_rpc_method_handler(
+                    servicer.MergeProducts,
+                    request_deserializer=graph__pb2.MergeProductsRequest.FromString,
+                    response_serializer=graph__pb2.MergeProductsResponse.SerializeToString,
+            ),
+            'UpdateStock': grpc.unary_unary_rpc_method_handler(
+                    servicer.UpdateStock,
+                    request_deserializer=graph__pb2.UpdateStockRequest.FromString,
+                    response_serializer=graph__pb2.UpdateStockResponse.SerializeToString,
+            ),
+            'AddProductSize': grpc.unary_unary_rpc_method_handler(
+                    servicer.AddProductSize,
+                    request_deserializer=graph__pb2.AddProductSizeRequest.FromString,
+                    response_serializer=graph__pb2.AddProductSizeResponse.SerializeToString,
+            ),
+            'ListProducts': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListProducts,
+                    request_deserializer=graph__pb2.ListProductsRequest.FromString,
+                    response_serializer=graph__pb2.ListProductsResponse.SerializeToString,
+            ),
+            'ListProductsUpdatedSince': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListProductsUpdatedSince,
+                    request_deserializer=graph__pb2.ListProductsUpdatedSinceRequest.FromString,
+                    response_serializer=graph__pb2.ListProductsUpdatedSinceResponse.SerializeToString,
+            ),
+            'SearchProducts': grpc.unary_unary_rpc_method_handler(
+                    servicer.SearchProducts,
+                    request_deserializer=graph__pb2.SearchProductsRequest.FromString,
+                    response_serializer=graph__pb2.SearchProductsResponse.SerializeToString,
+            ),
+            'GetNewArrivals': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetNewArrivals,
+                    request_deserializer=graph__pb2.GetNewArrivalsRequest.FromString,
+                    response_serializer=graph__pb2.GetNewArrivalsResponse.SerializeToString,
+            ),
+            'GetDeals': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetDeals,
+                    request_deserializer=graph__pb2.GetDealsRequest.FromString,
+                    response_serializer=graph__pb2.GetDealsResponse.SerializeToString,
+            ),
+            'CreateCollection': grpc.unary_unary_rpc_method_handler(
+                    servicer.CreateCollection,
+                    request_deserializer=graph__pb2.CreateCollectionRequest.FromString,
+                    response_serializer=graph__pb2.CreateCollectionResponse.SerializeToString,
+            ),
+            'GetCollection': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetCollection,
+                    request_deserializer=graph__pb2.GetCollectionRequest.FromString,
+                    response_serializer=graph__pb2.GetCollectionResponse.SerializeToString,
+            ),
+            'UpdateCollection': grpc.unary_unary_rpc_method_handler(
+                    servicer.UpdateCollection,
+                    request_deserializer=graph__pb2.UpdateCollectionRequest.FromString,
+                    response_serializer=graph__pb2.UpdateCollectionResponse.SerializeToString,
+            ),
+            'DeleteCollection': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteCollection,
+                    request_deserializer=graph__pb2.DeleteCollectionRequest.FromString,
+                    response_serializer=graph__pb2.DeleteCollectionResponse.SerializeToString,
+            ),
+            'SetCollectionProducts': grpc.unary_unary_rpc_method_handler(
+                    servicer.SetCollectionProducts,
+                    request_deserializer=graph__pb2.SetCollectionProductsRequest.FromString,
+                    response_serializer=graph__pb2.SetCollectionProductsResponse.SerializeToString,
+            ),
+            'AddProductToCollection': grpc.unary_unary_rpc_method_handler(
+                    servicer.AddProductToCollection,
+                    request_deserializer=graph__pb2.AddProductToCollectionRequest.FromString,
+                    response_serializer=graph__pb2.AddProductToCollectionResponse.SerializeToString,
+            ),
+            'RemoveProductFromCollection': grpc.unary_unary_rpc_method_handler(
+                    servicer.RemoveProductFromCollection,
+                    request_deserializer=graph__pb2.RemoveProductFromCollectionRequest.FromString,
+                    response_serializer=graph__pb2.RemoveProductFromCollectionResponse.SerializeToString,
+            ),
+            'CreateBundle': grpc.unary_unary_rpc_method_handler(
+                    servicer.CreateBundle,
+                    request_deserializer=graph__pb2.CreateBundleRequest.FromString,
+                    response_serializer=graph__pb2.CreateBundleResponse.SerializeToString,
+            ),
+            'GetBundle': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetBundle,
+                    request_deserializer=graph__pb2.GetBundleRequest.FromString,
+                    response_serializer=graph__pb2.GetBundleResponse.SerializeToString,
+            ),
+            'UpdateBundle': grpc.unary_unary_rpc_method_handler(
+                    servicer.UpdateBundle,
+                    request_deserializer=graph__pb2.UpdateBundleRequest.FromString,
+                    response_serializer=graph__pb2.UpdateBundleResponse.SerializeToString,
+            ),
+            'DeleteBundle': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteBundle,
+                    request_deserializer=graph__pb2.DeleteBundleRequest.FromString,
+                    response_serializer=graph__pb2.DeleteBundleResponse.SerializeToString,
+            ),
+            'OrderBundle': grpc.unary_unary_rpc_method_handler(
+                    servicer.OrderBundle,
+                    request_deserializer=graph__pb2.OrderBundleRequest.FromString,
+                    response_serializer=graph__pb2.OrderBundleResponse.SerializeToString,
+            ),
+            'ReserveStock': grpc.unary_unary_rpc_method_handler(
+                    servicer.ReserveStock,
+                    request_deserializer=graph__pb2.ReserveStockRequest.FromString,
+                    response_serializer=graph__pb2.ReserveStockResponse.SerializeToString,
+            ),
+            'CommitReservation': grpc.unary_unary_rpc_method_handler(
+                    servicer.CommitReservation,
+                    request_deserializer=graph__pb2.CommitReservationRequest.FromString,
+                    response_serializer=graph__pb2.CommitReservationResponse.SerializeToString,
+            ),
+            'ReleaseReservation': grpc.unary_unary_rpc_method_handler(
+                    servicer.ReleaseReservation,
+                    request_deserializer=graph__pb2.ReleaseReservationRequest.FromString,
+                    response_serializer=graph__pb2.ReleaseReservationResponse.SerializeToString,
+            ),
+            'PlaceOrder': grpc.unary_unary_rpc_method_handler(
+                    servicer.PlaceOrder,
+                    request_deserializer=graph__pb2.PlaceOrderRequest.FromString,
+                    response_serializer=graph__pb2.PlaceOrderResponse.SerializeToString,
+            ),
+            'GetOrder': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetOrder,
+                    request_deserializer=graph__pb2.GetOrderRequest.FromString,
+                    response_serializer=graph__pb2.GetOrderResponse.SerializeToString,
+            ),
+            'ReviewFlaggedOrders': grpc.unary_unary_rpc_method_handler(
+                    servicer.ReviewFlaggedOrders,
+                    request_deserializer=graph__pb2.ReviewFlaggedOrdersRequest.FromString,
+                    response_serializer=graph__pb2.ReviewFlaggedOrdersResponse.SerializeToString,
+            ),
+            'CreateReturn': grpc.unary_unary_rpc_method_handler(
+                    servicer.CreateReturn,
+                    request_deserializer=graph__pb2.CreateReturnRequest.FromString,
+                    response_serializer=graph__pb2.CreateReturnResponse.SerializeToString,
+            ),
+            'ApproveReturn': grpc.unary_unary_rpc_method_handler(
+                    servicer.ApproveReturn,
+                    request_deserializer=graph__pb2.ApproveReturnRequest.FromString,
+                    response_serializer=graph__pb2.ApproveReturnResponse.SerializeToString,
+            ),
+            'CompleteReturn': grpc.unary_unary_rpc_method_handler(
+                    servicer.CompleteReturn,
+                    request_deserializer=graph__pb2.CompleteReturnRequest.FromString,
+                    response_serializer=graph__pb2.CompleteReturnResponse.SerializeToString,
+            ),
+            'EstimateShipping': grpc.unary_unary_rpc_method_handler(
+                    servicer.EstimateShipping,
+                    request_deserializer=graph__pb2.EstimateShippingRequest.FromString,
+                    response_serializer=graph__pb2.EstimateShippingResponse.SerializeToString,
+            ),
+            'PriceCart': grpc.unary_unary_rpc_method_handler(
+                    servicer.PriceCart,
+                    request_deserializer=graph__pb2.PriceCartRequest.FromString,
+                    response_serializer=graph__pb2.PriceCartResponse.SerializeToString,
+            ),
+            'CreateCoupon': grpc.unary_unary_rpc_method_handler(
+                    servicer.CreateCoupon,
+                    request_deserializer=graph__pb2.CreateCouponRequest.FromString,
+                    response_serializer=graph__pb2.CreateCouponResponse.SerializeToString,
+            ),
+            'ValidateCoupon': grpc.unary_unary_rpc_method_handler(
+                    servicer.ValidateCoupon,
+                    request_deserializer=graph__pb2.ValidateCouponRequest.FromString,
+                    response_serializer=graph__pb2.ValidateCouponResponse.SerializeToString,
+            ),
+            'RedeemCoupon': grpc.unary_unary_rpc_method_handler(
+                    servicer.RedeemCoupon,
+                    request_deserializer=graph__pb2.RedeemCouponRequest.FromString,
+                    response_serializer=graph__pb2.RedeemCouponResponse.SerializeToString,
+            ),
+            'IssueGiftCard': grpc.unary_unary_rpc_method_handler(
+                    servicer.IssueGiftCard,
+                    request_deserializer=graph__pb2.IssueGiftCardRequest.FromString,
+                    response_serializer=graph__pb2.IssueGiftCardResponse.SerializeToString,
+            ),
+            'GetBalance': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetBalance,
+                    request_deserializer=graph__pb2.GetBalanceRequest.FromString,
+                    response_serializer=graph__pb2.GetBalanceResponse.SerializeToString,
+            ),
+            'RedeemGiftCard': grpc.unary_unary_rpc_method_handler(
+                    servicer.RedeemGiftCard,
+                    request_deserializer=graph__pb2.RedeemGiftCardRequest.FromString,
+                    response_serializer=graph__pb2.RedeemGiftCardResponse.SerializeToString,
+            ),
+            'GetLoyaltyBalance': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetLoyaltyBalance,
+                    request_deserializer=graph__pb2.GetLoyaltyBalanceRequest.FromString,
+                    response_serializer=graph__pb2.GetLoyaltyBalanceResponse.SerializeToString,
+            ),
+            'RedeemPoints': grpc.unary_unary_rpc_method_handler(
+                    servicer.RedeemPoints,
+                    request_deserializer=graph__pb2.RedeemPointsRequest.FromString,
+                    response_serializer=graph__pb2.RedeemPointsResponse.SerializeToString,
+            ),
+            'SetCrossSell': grpc.unary_unary_rpc_method_handler(
+                    servicer.SetCrossSell,
+                    request_deserializer=graph__pb2.SetCrossSellRequest.FromString,
+                    response_serializer=graph__pb2.SetCrossSellResponse.SerializeToString,
+            ),
+            'SetUpsell': grpc.unary_unary_rpc_method_handler(
+                    servicer.SetUpsell,
+                    request_deserializer=graph__pb2.SetUpsellRequest.FromString,
+                    response_serializer=graph__pb2.SetUpsellResponse.SerializeToString,
+            ),
+            'GetMerchandisedRecommendations': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetMerchandisedRecommendations,
+                    request_deserializer=graph__pb2.GetMerchandisedRecommendationsRequest.FromString,
+                    response_serializer=graph__pb2.GetMerchandisedRecommendationsResponse.SerializeToString,
+            ),
+            'UpsertSizeChart': grpc.unary_unary_rpc_method_handler(
+                    servicer.UpsertSizeChart,
+                    request_deserializer=graph__pb2.UpsertSizeChartRequest.FromString,
+                    response_serializer=graph__pb2.UpsertSizeChartResponse.SerializeToString,
+            ),
+            'GetSizeChart': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetSizeChart,
+                    request_deserializer=graph__pb2.GetSizeChartRequest.FromString,
+                    response_serializer=graph__pb2.GetSizeChartResponse.SerializeToString,
+            ),
+            'SetCustomerMeasurements': grpc.unary_unary_rpc_method_handler(
+                    servicer.SetCustomerMeasurements,
+                    request_deserializer=graph__pb2.SetCustomerMeasurementsRequest.FromString,
+                    response_serializer=graph__pb2.SetCustomerMeasurementsResponse.SerializeToString,
+            ),
+            'RecommendSize': grpc.unary_unary_rpc_method_handler(
+                    servicer.RecommendSize,
+                    request_deserializer=graph__pb2.RecommendSizeRequest.FromString,
+                    response_serializer=graph__pb2.RecommendSizeResponse.SerializeToString,
+            ),
+            'CreateUser': grpc.unary_unary_rpc_method_handler(
+                    servicer.CreateUser,
+                    request_deserializer=graph__pb2.CreateUserRequest.FromString,
+                    response_serializer=graph__pb2.CreateUserResponse.SerializeToString,
+            ),
+            'GetUser': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetUser,
+                    request_deserializer=graph__pb2.GetUserRequest.FromString,
+                    response_serializer=graph__pb2.GetUserResponse.SerializeToString,
+            ),
+            'UpdatePreferences': grpc.unary_unary_rpc_method_handler(
+                    servicer.UpdatePreferences,
+                    request_deserializer=graph__pb2.UpdatePreferencesRequest.FromString,
+                    response_serializer=graph__pb2.UpdatePreferencesResponse.SerializeToString,
+            ),
+            'ExportUserData': grpc.unary_unary_rpc_method_handler(
+                    servicer.ExportUserData,
+                    request_deserializer=graph__pb2.ExportUserDataRequest.FromString,
+                    response_serializer=graph__pb2.ExportUserDataResponse.SerializeToString,
+            ),
+            'DeleteUserData': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteUserData,
+                    request_deserializer=graph__pb2.DeleteUserDataRequest.FromString,
+                    response_serializer=graph__pb2.DeleteUserDataResponse.SerializeToString,
+            ),
+            'SaveSearch': grpc.unary_unary_rpc_method_handler(
+                    servicer.SaveSearch,
+                    request_deserializer=graph__pb2.SaveSearchRequest.FromString,
+                    response_serializer=graph__pb2.SaveSearchResponse.SerializeToString,
+            ),
+            'ListSavedSearches': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListSavedSearches,
+                    request_deserializer=graph__pb2.ListSavedSearchesRequest.FromString,
+                    response_serializer=graph__pb2.ListSavedSearchesResponse.SerializeToString,
+            ),
+            'DeleteSavedSearch': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteSavedSearch,
+                    request_deserializer=graph__pb2.DeleteSavedSearchRequest.FromString,
+                    response_serializer=graph__pb2.DeleteSavedSearchResponse.SerializeToString,
+            ),
+            'SubscribeToPriceDrop': grpc.unary_unary_rpc_method_handler(
+                    servicer.SubscribeToPriceDrop,
+                    request_deserializer=graph__pb2.SubscribeToPriceDropRequest.FromString,
+                    response_serializer=graph__pb2.SubscribeToPriceDropResponse.SerializeToString,
+            ),
+            'UnsubscribeFromPriceDrop': grpc.unary_unary_rpc_method_handler(
+                    servicer.UnsubscribeFromPriceDrop,
+                    request_deserializer=graph__pb2.UnsubscribeFromPriceDropRequest.FromString,
+                    response_serializer=graph__pb2.UnsubscribeFromPriceDropResponse.SerializeToString,
+            ),
+            'RegisterWebhook': grpc.unary_unary_rpc_method_handler(
+                    servicer.RegisterWebhook,
+                    request_deserializer=graph__pb2.RegisterWebhookRequest.FromString,
+                    response_serializer=graph__pb2.RegisterWebhookResponse.SerializeToString,
+            ),
+            'ListDeliveries': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListDeliveries,
+                    request_deserializer=graph__pb2.ListDeliveriesRequest.FromString,
+                    response_serializer=graph__pb2.ListDeliveriesResponse.SerializeToString,
+            ),
+            'GetCategoryTree': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetCategoryTree,
+                    request_deserializer=graph__pb2.GetCategoryTreeRequest.FromString,
+                    response_serializer=graph__pb2.GetCategoryTreeResponse.SerializeToString,
+            ),
+            'ExportSubgraph': grpc.unary_unary_rpc_method_handler(
+                    servicer.ExportSubgraph,
+                    request_deserializer=graph__pb2.ExportSubgraphRequest.FromString,
+                    response_serializer=graph__pb2.ExportSubgraphResponse.SerializeToString,
+            ),
+            'ListSyncRuns': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListSyncRuns,
+                    request_deserializer=graph__pb2.ListSyncRunsRequest.FromString,
+                    response_serializer=graph__pb2.ListSyncRunsResponse.SerializeToString,
+            ),
+            'ValidateCatalog': grpc.unary_unary_rpc_method_handler(
+                    servicer.ValidateCatalog,
+                    request_deserializer=graph__pb2.ValidateCatalogRequest.FromString,
+                    response_serializer=graph__pb2.ValidateCatalogResponse.SerializeToString,
+            ),
+            'ExportStockHistory': grpc.unary_unary_rpc_method_handler(
+                    servicer.ExportStockHistory,
+                    request_deserializer=graph__pb2.ExportStockHistoryRequest.FromString,
+                    response_serializer=graph__pb2.ExportStockHistoryResponse.SerializeToString,
+            ),
+            'ImportForecast': grpc.unary_unary_rpc_method_handler(
+                    servicer.ImportForecast,
+                    request_deserializer=graph__pb2.ImportForecastRequest.FromString,
+                    response_serializer=graph__pb2.ImportForecastResponse.SerializeToString,
+            ),
+            'AppendTranscript': grpc.unary_unary_rpc_method_handler(
+                    servicer.AppendTranscript,
+                    request_deserializer=graph__pb2.AppendTranscriptRequest.FromString,
+                    response_serializer=graph__pb2.AppendTranscriptResponse.SerializeToString,
+            ),
+            'GetSessionTranscript': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetSessionTranscript,
+                    request_deserializer=graph__pb2.GetSessionTranscriptRequest.FromString,
+                    response_serializer=graph__pb2.GetSessionTranscriptResponse.SerializeToString,
+            ),
+    }
+    generic_handler = grpc.method_handlers_generic_handler(
+            'graph.GraphService', rpc_method_handlers)
+    server.add_generic_rpc_handlers((generic_handler,))
+    server.add_registered_method_handlers('graph.GraphService', rpc_method_handlers)
+
+
+ # This class is part of an EXPERIMENTAL API.
+class GraphService(object):
+    """Missing associated documentation comment in .proto file."""
+
+    @staticmethod
+    def CreateProduct(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/CreateProduct',
+            graph__pb2.CreateProductRequest.SerializeToString,
+            graph__pb2.CreateProductResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetProduct(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/GetProduct',
+            graph__pb2.GetProductRequest.SerializeToString,
+            graph__pb2.GetProductResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetAvailability(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/GetAvailability',
+            graph__pb2.GetAvailabilityRequest.SerializeToString,
+            graph__pb2.GetAvailabilityResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ResolveProduct(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/ResolveProduct',
+            graph__pb2.ResolveProductRequest.SerializeToString,
+            graph__pb2.ResolveProductResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetProductBySlug(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/GetProductBySlug',
+            graph__pb2.GetProductBySlugRequest.SerializeToString,
+            graph__pb2.GetProductBySlugResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def UpsertProductTranslation(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/UpsertProductTranslation',
+            graph__pb2.UpsertProductTranslationRequest.SerializeToString,
+            graph__pb2.UpsertProductTranslationResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def UpdateProduct(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/UpdateProduct',
+            graph__pb2.UpdateProductRequest.SerializeToString,
+            graph__pb2.UpdateProductResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def DeleteProduct(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/DeleteProduct',
+            graph__pb2.DeleteProductRequest.SerializeToString,
+            graph__pb2.DeleteProductResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def CloneProduct(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/CloneProduct',
+            graph__pb2.CloneProductRequest.SerializeToString,
+            graph__pb2.CloneProductResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def SetProductsArchived(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/SetProductsArchived',
+            graph__pb2.SetProductsArchivedRequest.SerializeToString,
+            graph__pb2.SetProductsArchivedResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def FindDuplicateProducts(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/FindDuplicateProducts',
+            graph__pb2.FindDuplicateProductsRequest.SerializeToString,
+            graph__pb2.FindDuplicateProductsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def MergeProducts(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/MergeProducts',
+            graph__pb2.MergeProductsRequest.SerializeToString,
+            graph__pb2.MergeProductsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def UpdateStock(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/UpdateStock',
+            graph__pb2.UpdateStockRequest.SerializeToString,
+            graph__pb2.UpdateStockResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def AddProductSize(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/AddProductSize',
+            graph__pb2.AddProductSizeRequest.SerializeToString,
+            graph__pb2.AddProductSizeResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ListProducts(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/ListProducts',
+            graph__pb2.ListProductsRequest.SerializeToString,
+            graph__pb2.ListProductsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ListProductsUpdatedSince(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/ListProductsUpdatedSince',
+            graph__pb2.ListProductsUpdatedSinceRequest.SerializeToString,
+            graph__pb2.ListProductsUpdatedSinceResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def SearchProducts(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/SearchProducts',
+            graph__pb2.SearchProductsRequest.SerializeToString,
+            graph__pb2.SearchProductsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetNewArrivals(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/GetNewArrivals',
+            graph__pb2.GetNewArrivalsRequest.SerializeToString,
+            graph__pb2.GetNewArrivalsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetDeals(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/GetDeals',
+            graph__pb2.GetDealsRequest.SerializeToString,
+            graph__pb2.GetDealsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def CreateCollection(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/CreateCollection',
+            graph__pb2.CreateCollectionRequest.SerializeToString,
+            graph__pb2.CreateCollectionResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetCollection(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/GetCollection',
+            graph__pb2.GetCollectionRequest.SerializeToString,
+            graph__pb2.GetCollectionResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def UpdateCollection(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/UpdateCollection',
+            graph__pb2.UpdateCollectionRequest.SerializeToString,
+            graph__pb2.UpdateCollectionResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def DeleteCollection(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/DeleteCollection',
+            graph__pb2.DeleteCollectionRequest.SerializeToString,
+            graph__pb2.DeleteCollectionResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def SetCollectionProducts(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/SetCollectionProducts',
+            graph__pb2.SetCollectionProductsRequest.SerializeToString,
+            graph__pb2.SetCollectionProductsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def AddProductToCollection(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/AddProductToCollection',
+            graph__pb2.AddProductToCollectionRequest.SerializeToString,
+            graph__pb2.AddProductToCollectionResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def RemoveProductFromCollection(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/RemoveProductFromCollection',
+            graph__pb2.RemoveProductFromCollectionRequest.SerializeToString,
+            graph__pb2.RemoveProductFromCollectionResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def CreateBundle(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/CreateBundle',
+            graph__pb2.CreateBundleRequest.SerializeToString,
+            graph__pb2.CreateBundleResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetBundle(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/GetBundle',
+            graph__pb2.GetBundleRequest.SerializeToString,
+            graph__pb2.GetBundleResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def UpdateBundle(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/UpdateBundle',
+            graph__pb2.UpdateBundleRequest.SerializeToString,
+            graph__pb2.UpdateBundleResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def DeleteBundle(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/DeleteBundle',
+            graph__pb2.DeleteBundleRequest.SerializeToString,
+            graph__pb2.DeleteBundleResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def OrderBundle(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/OrderBundle',
+            graph__pb2.OrderBundleRequest.SerializeToString,
+            graph__pb2.OrderBundleResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ReserveStock(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/ReserveStock',
+            graph__pb2.ReserveStockRequest.SerializeToString,
+            graph__pb2.ReserveStockResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def CommitReservation(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/CommitReservation',
+            graph__pb2.CommitReservationRequest.SerializeToString,
+            graph__pb2.CommitReservationResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ReleaseReservation(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/ReleaseReservation',
+            graph__pb2.ReleaseReservationRequest.SerializeToString,
+            graph__pb2.ReleaseReservationResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def PlaceOrder(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/PlaceOrder',
+            graph__pb2.PlaceOrderRequest.SerializeToString,
+            graph__pb2.PlaceOrderResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetOrder(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/GetOrder',
+            graph__pb2.GetOrderRequest.SerializeToString,
+            graph__pb2.GetOrderResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ReviewFlaggedOrders(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/ReviewFlaggedOrders',
+            graph__pb2.ReviewFlaggedOrdersRequest.SerializeToString,
+            graph__pb2.ReviewFlaggedOrdersResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def CreateReturn(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/CreateReturn',
+            graph__pb2.CreateReturnRequest.SerializeToString,
+            graph__pb2.CreateReturnResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ApproveReturn(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/ApproveReturn',
+            graph__pb2.ApproveReturnRequest.SerializeToString,
+            graph__pb2.ApproveReturnResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def CompleteReturn(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/CompleteReturn',
+            graph__pb2.CompleteReturnRequest.SerializeToString,
+            graph__pb2.CompleteReturnResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def EstimateShipping(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/EstimateShipping',
+            graph__pb2.EstimateShippingRequest.SerializeToString,
+            graph__pb2.EstimateShippingResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def PriceCart(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/PriceCart',
+            graph__pb2.PriceCartRequest.SerializeToString,
+            graph__pb2.PriceCartResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def CreateCoupon(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/CreateCoupon',
+            graph__pb2.CreateCouponRequest.SerializeToString,
+            graph__pb2.CreateCouponResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ValidateCoupon(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/ValidateCoupon',
+            graph__pb2.ValidateCouponRequest.SerializeToString,
+            graph__pb2.ValidateCouponResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def RedeemCoupon(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/RedeemCoupon',
+            graph__pb2.RedeemCouponRequest.SerializeToString,
+            graph__pb2.RedeemCouponResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def IssueGiftCard(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/IssueGiftCard',
+            graph__pb2.IssueGiftCardRequest.SerializeToString,
+            graph__pb2.IssueGiftCardResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetBalance(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/GetBalance',
+            graph__pb2.GetBalanceRequest.SerializeToString,
+            graph__pb2.GetBalanceResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def RedeemGiftCard(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/RedeemGiftCard',
+            graph__pb2.RedeemGiftCardRequest.SerializeToString,
+            graph__pb2.RedeemGiftCardResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetLoyaltyBalance(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/GetLoyaltyBalance',
+            graph__pb2.GetLoyaltyBalanceRequest.SerializeToString,
+            graph__pb2.GetLoyaltyBalanceResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def RedeemPoints(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/RedeemPoints',
+            graph__pb2.RedeemPointsRequest.SerializeToString,
+            graph__pb2.RedeemPointsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def SetCrossSell(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/SetCrossSell',
+            graph__pb2.SetCrossSellRequest.SerializeToString,
+            graph__pb2.SetCrossSellResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def SetUpsell(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/SetUpsell',
+            graph__pb2.SetUpsellRequest.SerializeToString,
+            graph__pb2.SetUpsellResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetMerchandisedRecommendations(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/GetMerchandisedRecommendations',
+            graph__pb2.GetMerchandisedRecommendationsRequest.SerializeToString,
+            graph__pb2.GetMerchandisedRecommendationsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def UpsertSizeChart(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/UpsertSizeChart',
+            graph__pb2.UpsertSizeChartRequest.SerializeToString,
+            graph__pb2.UpsertSizeChartResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetSizeChart(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/GetSizeChart',
+            graph__pb2.GetSizeChartRequest.SerializeToString,
+            graph__pb2.GetSizeChartResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def SetCustomerMeasurements(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/SetCustomerMeasurements',
+            graph__pb2.SetCustomerMeasurementsRequest.SerializeToString,
+            graph__pb2.SetCustomerMeasurementsResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def RecommendSize(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/RecommendSize',
+            graph__pb2.RecommendSizeRequest.SerializeToString,
+            graph__pb2.RecommendSizeResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def CreateUser(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/CreateUser',
+            graph__pb2.CreateUserRequest.SerializeToString,
+            graph__pb2.CreateUserResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetUser(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/GetUser',
+            graph__pb2.GetUserRequest.SerializeToString,
+            graph__pb2.GetUserResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def UpdatePreferences(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/UpdatePreferences',
+            graph__pb2.UpdatePreferencesRequest.SerializeToString,
+            graph__pb2.UpdatePreferencesResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ExportUserData(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/ExportUserData',
+            graph__pb2.ExportUserDataRequest.SerializeToString,
+            graph__pb2.ExportUserDataResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def DeleteUserData(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/DeleteUserData',
+            graph__pb2.DeleteUserDataRequest.SerializeToString,
+            graph__pb2.DeleteUserDataResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def SaveSearch(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/SaveSearch',
+            graph__pb2.SaveSearchRequest.SerializeToString,
+            graph__pb2.SaveSearchResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ListSavedSearches(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/ListSavedSearches',
+            graph__pb2.ListSavedSearchesRequest.SerializeToString,
+            graph__pb2.ListSavedSearchesResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
 
-    def UpdateStock(self, request, context):
-        """Missing associated documentation comment in .proto file."""
-        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
-        context.set_details('Method not implemented!')
-        raise NotImplementedError('Method not implemented!')
+    @staticmethod
+    def DeleteSavedSearch(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/DeleteSavedSearch',
+            graph__pb2.DeleteSavedSearchRequest.SerializeToString,
+            graph__pb2.DeleteSavedSearchResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
 
-    def SearchProducts(self, request, context):
-        """Missing associated documentation comment in .proto file."""
-        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
-        context.set_details('Method not implemented!')
-        raise NotImplementedError('Method not implemented!')
+    @staticmethod
+    def SubscribeToPriceDrop(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/SubscribeToPriceDrop',
+            graph__pb2.SubscribeToPriceDropRequest.SerializeToString,
+            graph__pb2.SubscribeToPriceDropResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
 
+    @staticmethod
+    def UnsubscribeFromPriceDrop(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/UnsubscribeFromPriceDrop',
+            graph__pb2.UnsubscribeFromPriceDropRequest.SerializeToString,
+            graph__pb2.UnsubscribeFromPriceDropResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
 
-def add_GraphServiceServicer_to_server(servicer, server):
-    rpc_method_handlers = {
-            'CreateProduct': grpc.unary_unary_rpc_method_handler(
-                    servicer.CreateProduct,
-                    request_deserializer=graph__pb2.CreateProductRequest.FromString,
-                    response_serializer=graph__pb2.CreateProductResponse.SerializeToString,
-            ),
-            'GetProduct': grpc.unary_unary_rpc_method_handler(
-                    servicer.GetProduct,
-                    request_deserializer=graph__pb2.GetProductRequest.FromString,
-                    response_serializer=graph__pb2.GetProductResponse.SerializeToString,
-            ),
-            'UpdateProduct': grpc.unary_unary_rpc_method_handler(
-                    servicer.UpdateProduct,
-                    request_deserializer=graph__pb2.UpdateProductRequest.FromString,
-                    response_serializer=graph__pb2.UpdateProductResponse.SerializeToString,
-            ),
-            'DeleteProduct': grpc.unary_unary_rpc_method_handler(
-                    servicer.DeleteProduct,
-                    request_deserializer=graph__pb2.DeleteProductRequest.FromString,
-                    response_serializer=graph__pb2.DeleteProductResponse.SerializeToString,
-            ),
-            'UpdateStock': grpc.unary_unary_rpc_method_handler(
-                    servicer.UpdateStock,
-                    request_deserializer=graph__pb2.UpdateStockRequest.FromString,
-                    response_serializer=graph__pb2.UpdateStockResponse.SerializeToString,
-            ),
-            'SearchProducts': grpc.unary_unary_rpc_method_handler(
-                    servicer.SearchProducts,
-                    request_deserializer=graph__pb2.SearchProductsRequest.FromString,
-                    response_serializer=graph__pb2.SearchProductsResponse.SerializeToString,
-            ),
-    }
-    generic_handler = grpc.method_handlers_generic_handler(
-            'graph.GraphService', rpc_method_handlers)
-    server.add_generic_rpc_handlers((generic_handler,))
-    server.add_registered_method_handlers('graph.GraphService', rpc_method_handlers)
+    @staticmethod
+    def RegisterWebhook(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/RegisterWebhook',
+            graph__pb2.RegisterWebhookRequest.SerializeToString,
+            graph__pb2.RegisterWebhookResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
 
+    @staticmethod
+    def ListDeliveries(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/ListDeliveries',
+            graph__pb2.ListDeliveriesRequest.SerializeToString,
+            graph__pb2.ListDeliveriesResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
 
- # This class is part of an EXPERIMENTAL API.
-class GraphService(object):
-    """Missing associated documentation comment in .proto file."""
+    @staticmethod
+    def GetCategoryTree(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/GetCategoryTree',
+            graph__pb2.GetCategoryTreeRequest.SerializeToString,
+            graph__pb2.GetCategoryTreeResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
 
     @staticmethod
-    def CreateProduct(request,
+    def ExportSubgraph(request,
             target,
             options=(),
             channel_credentials=None,
@@ -163,9 +3189,9 @@ class GraphService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/graph.GraphService/CreateProduct',
-            graph__pb2.CreateProductRequest.SerializeToString,
-            graph__pb2.CreateProductResponse.FromString,
+            '/graph.GraphService/ExportSubgraph',
+            graph__pb2.ExportSubgraphRequest.SerializeToString,
+            graph__pb2.ExportSubgraphResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -177,7 +3203,7 @@ class GraphService(object):
             _registered_method=True)
 
     @staticmethod
-    def GetProduct(request,
+    def ListSyncRuns(request,
             target,
             options=(),
             channel_credentials=None,
@@ -190,9 +3216,9 @@ class GraphService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/graph.GraphService/GetProduct',
-            graph__pb2.GetProductRequest.SerializeToString,
-            graph__pb2.GetProductResponse.FromString,
+            '/graph.GraphService/ListSyncRuns',
+            graph__pb2.ListSyncRunsRequest.SerializeToString,
+            graph__pb2.ListSyncRunsResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -204,7 +3230,7 @@ class GraphService(object):
             _registered_method=True)
 
     @staticmethod
-    def UpdateProduct(request,
+    def ValidateCatalog(request,
             target,
             options=(),
             channel_credentials=None,
@@ -217,9 +3243,9 @@ class GraphService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/graph.GraphService/UpdateProduct',
-            graph__pb2.UpdateProductRequest.SerializeToString,
-            graph__pb2.UpdateProductResponse.FromString,
+            '/graph.GraphService/ValidateCatalog',
+            graph__pb2.ValidateCatalogRequest.SerializeToString,
+            graph__pb2.ValidateCatalogResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -231,7 +3257,7 @@ class GraphService(object):
             _registered_method=True)
 
     @staticmethod
-    def DeleteProduct(request,
+    def ExportStockHistory(request,
             target,
             options=(),
             channel_credentials=None,
@@ -244,9 +3270,9 @@ class GraphService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/graph.GraphService/DeleteProduct',
-            graph__pb2.DeleteProductRequest.SerializeToString,
-            graph__pb2.DeleteProductResponse.FromString,
+            '/graph.GraphService/ExportStockHistory',
+            graph__pb2.ExportStockHistoryRequest.SerializeToString,
+            graph__pb2.ExportStockHistoryResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -258,7 +3284,7 @@ class GraphService(object):
             _registered_method=True)
 
     @staticmethod
-    def UpdateStock(request,
+    def ImportForecast(request,
             target,
             options=(),
             channel_credentials=None,
@@ -271,9 +3297,9 @@ class GraphService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/graph.GraphService/UpdateStock',
-            graph__pb2.UpdateStockRequest.SerializeToString,
-            graph__pb2.UpdateStockResponse.FromString,
+            '/graph.GraphService/ImportForecast',
+            graph__pb2.ImportForecastRequest.SerializeToString,
+            graph__pb2.ImportForecastResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -285,7 +3311,7 @@ class GraphService(object):
             _registered_method=True)
 
     @staticmethod
-    def SearchProducts(request,
+    def AppendTranscript(request,
             target,
             options=(),
             channel_credentials=None,
@@ -298,9 +3324,36 @@ class GraphService(object):
         return grpc.experimental.unary_unary(
             request,
             target,
-            '/graph.GraphService/SearchProducts',
-            graph__pb2.SearchProductsRequest.SerializeToString,
-            graph__pb2.SearchProductsResponse.FromString,
+            '/graph.GraphService/AppendTranscript',
+            graph__pb2.AppendTranscriptRequest.SerializeToString,
+            graph__pb2.AppendTranscriptResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetSessionTranscript(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/graph.GraphService/GetSessionTranscript',
+            graph__pb2.GetSessionTranscriptRequest.SerializeToString,
+            graph__pb2.GetSessionTranscriptResponse.FromString,
             options,
             channel_credentials,
             insecure,
@@ -0,0 +1,186 @@
+import random
+import threading
+import time
+import logging
+from typing import Callable, Dict, Optional
+
+import grpc
+
+logger = logging.getLogger(__name__)
+
+# Codes worth another attempt. DEADLINE_EXCEEDED only counts when the
+# attempt hit its own per-attempt timeout, not the overall deadline.
+RETRYABLE_CODES = {
+    grpc.StatusCode.UNAVAILABLE,
+    grpc.StatusCode.DEADLINE_EXCEEDED,
+    grpc.StatusCode.ABORTED,
+}
+
+# Codes that say the graph service itself is unhealthy.
+FAILURE_CODES = {
+    grpc.StatusCode.UNAVAILABLE,
+    grpc.StatusCode.DEADLINE_EXCEEDED,
+}
+
+
+class CircuitOpenError(Exception):
+    """Raised instead of calling a dependency while its breaker is open."""
+
+
+class DeadlineExceededError(Exception):
+    """Raised when the caller's deadline has no time left for another attempt."""
+
+
+class CallCancelledError(Exception):
+    """Raised when the caller gave up on a call, e.g. because the user barged in."""
+
+
+class Deadline:
+    """An absolute deadline shared by every call made for one request."""
+
+    def __init__(self, seconds: float):
+        self.expires_at = time.monotonic() + seconds
+
+    def remaining(self) -> float:
+        return max(0.0, self.expires_at - time.monotonic())
+
+    def timeout(self, cap: float) -> float:
+        """Per-attempt timeout: cap, or less if the deadline is closer."""
+        remaining = self.remaining()
+        if remaining <= 0:
+            raise DeadlineExceededError("request deadline exceeded")
+        return min(cap, remaining)
+
+
+class CircuitBreaker:
+    """Consecutive-failure breaker shared by every client for a target.
+
+    Closed lets calls through; after `threshold` failures in a row it opens
+    and rejects calls for `cooldown` seconds, then lets a single probe through.
+    """
+
+    def __init__(self, threshold: int = 5, cooldown: float = 10.0, name: str = "Graph service"):
+        self.threshold = threshold
+        self.cooldown = cooldown
+        self.name = name
+        self._lock = threading.Lock()
+        self._failures = 0
+        self._opened_at: Optional[float] = None
+        self._probing = False
+
+    @property
+    def state(self) -> str:
+        with self._lock:
+            if self._opened_at is None:
+                return "closed"
+            if self._probing or time.monotonic() - self._opened_at >= self.cooldown:
+                return "half-open"
+            return "open"
+
+    def allow(self):
+        with self._lock:
+            if self._opened_at is None:
+                return
+            if self._probing or time.monotonic() - self._opened_at < self.cooldown:
+                raise CircuitOpenError(f"{self.name} circuit breaker is open")
+            self._probing = True
+
+    def abandon(self):
+        """Forgets a call that was cancelled before it could tell whether
+        the dependency is healthy, so a cancelled probe doesn't keep the
+        breaker open."""
+        with self._lock:
+            self._probing = False
+
+    def record(self, failed: bool):
+        with self._lock:
+            if not failed:
+                if self._opened_at is not None:
+                    logger.info(f"{self.name} circuit breaker closed")
+                self._failures = 0
+                self._opened_at = None
+                self._probing = False
+                return
+
+            self._failures += 1
+            if self._probing or self._failures >= self.threshold:
+                if self._opened_at is None or self._probing:
+                    logger.warning(
+                        f"{self.name} circuit breaker opened after {self._failures} failures"
+                    )
+                self._opened_at = time.monotonic()
+                self._probing = False
+
+
+_breakers: Dict[str, CircuitBreaker] = {}
+_breakers_lock = threading.Lock()
+
+
+def breaker_for(target: str, threshold: int = 5, cooldown: float = 10.0, name: str = "Graph service") -> CircuitBreaker:
+    """Returns the process-wide breaker for target, creating it if needed.
+
+    Clients are created per request, so the breaker has to outlive them.
+    """
+    with _breakers_lock:
+        breaker = _breakers.get(target)
+        if breaker is None:
+            breaker = CircuitBreaker(threshold=threshold, cooldown=cooldown, name=name)
+            _breakers[target] = breaker
+        return breaker
+
+
+def backoff(attempt: int, base: float = 0.05, cap: float = 1.0) -> float:
+    """Full-jitter exponential backoff for the given retry attempt (1-based)."""
+    return random.uniform(0, min(cap, base * (2 ** (attempt - 1))))
+
+
+def hedged(call: Callable, request, timeout: float, delay: float, metadata=None, track: Optional[Callable] = None):
+    """Runs call and, if it hasn't finished after delay seconds, a second copy.
+
+    Returns the first successful response and cancels the other attempt. If
+    both fail, the last error is raised. track, if given, is passed each
+    attempt's future, so the caller can cancel it.
+    """
+    done = threading.Condition()
+    finished = []
+
+    def on_done(future):
+        with done:
+            finished.append(future)
+            done.notify_all()
+
+    primary = call.future(request, timeout=timeout, metadata=metadata)
+    if track is not None:
+        track(primary)
+    primary.add_done_callback(on_done)
+    attempts = [primary]
+
+    deadline = time.monotonic() + timeout
+    with done:
+        done.wait_for(lambda: finished, timeout=min(delay, timeout))
+        if finished:
+            # Finished before the hedge delay: no second attempt, and
+            # errors are left to the caller's retry policy
+            return primary.result()
+
+        remaining = deadline - time.monotonic()
+        if remaining > 0:
+            hedge = call.future(request, timeout=remaining, metadata=metadata)
+            if track is not None:
+                track(hedge)
+            hedge.add_done_callback(on_done)
+            attempts.append(hedge)
+
+        seen = 0
+        while True:
+            done.wait_for(lambda: len(finished) > seen)
+            while seen < len(finished):
+                future = finished[seen]
+                seen += 1
+                if future.exception() is None:
+                    for other in attempts:
+                        if other is not future:
+                            other.cancel()
+                    return future.result()
+            if seen == len(attempts):
+                raise finished[-1].exception()
@@ -1,4 +1,4 @@
-from typing import List, Dict, Any, Optional
+from typing import List, Dict, Any, Literal, Optional
 from pydantic import BaseModel, Field
 
 
@@ -63,10 +63,48 @@ class RecommendationResult(BaseModel):
     category: Optional[ProductCategory] = None
 
 
+class SearchRefinement(BaseModel):
+    """Filters, sort and paging applied on top of a base search."""
+    brand: Optional[str] = None
+    color: Optional[str] = None
+    main_category: Optional[str] = None
+    subcategory: Optional[str] = None
+    min_price: Optional[float] = None
+    max_price: Optional[float] = None
+    # A shoe size with its system, as the catalog writes it: "US 10.5"
+    size: Optional[str] = None
+    sort: Literal["relevance", "price_asc", "price_desc"] = "relevance"
+    offset: int = Field(default=0, ge=0)
+
+
+class SpokenFilters(BaseModel):
+    """Filter values read out of spoken numbers in the query."""
+    min_price: Optional[float] = None
+    max_price: Optional[float] = None
+    # ISO 4217 code of the prices
+    currency: Optional[str] = None
+    size: Optional[str] = None
+    size_system: Optional[Literal["US", "UK", "EU"]] = None
+
+
 class ProductQueryRequest(BaseModel):
     query: str
     limit: int = 10
     min_semantic_score: float = 0.3
+    session_id: Optional[str] = None
+    refine: Optional[SearchRefinement] = None
+    # Select prompt overrides (see PromptStore)
+    tenant_id: Optional[str] = None
+    experiment: Optional[str] = None
+    # BCP 47 tag ("en-GB", "de-DE") for the language the query is
+    # understood and answered in, product content, and reading spoken
+    # numbers, prices and sizes. Detected from the query when not set.
+    locale: Optional[str] = None
+    # Streamed searches: the voice to speak in (see PersonaStore), and
+    # overrides of its speaking rate and pitch
+    persona: Optional[str] = None
+    speaking_rate: Optional[float] = Field(default=None, ge=0.5, le=2.0)
+    pitch: Optional[float] = Field(default=None, ge=-12.0, le=12.0)
 
 
 class ProductQueryResponse(BaseModel):
@@ -76,9 +114,163 @@ class ProductQueryResponse(BaseModel):
     semantic_results_count: int
     graph_results_count: int
     recommendations: List[RecommendationResult]
+    from_cache: bool = False
+    # "llm", or "keyword" when the LLM was unavailable and the query was
+    # analyzed into a fulltext search instead
+    search_path: Literal["llm", "keyword"] = "llm"
+    fallback_reason: Optional[str] = None
+    # The query as searched, when spoken numbers were written as digits or
+    # brand and product names respelled to match the catalog
+    rewritten_query: Optional[str] = None
+    # Prices and size read out of the query, applied as filters
+    spoken_filters: Optional[SpokenFilters] = None
+    # Language the query was handled in: "en", "de" or "es"
+    language: str = "en"
+    # Why the safety filter refused the query; nothing was searched
+    blocked: Optional[List[str]] = None
+
+
+class SearchStreamEvent(BaseModel):
+    """One line of a streamed search (application/x-ndjson).
+    
+    results: the top results so far; stage "first" once the most promising
+        candidates are ranked, "refined" once all of them are
+    speech: what to say about the first results, as text and SSML
+    audio: a chunk of that speech, base64, numbered from 0 by seq
+    done: the full response, ending the stream
+    error: the search failed, ending the stream
+    cancelled: the user barged in, ending the stream
+    """
+    type: Literal["results", "speech", "audio", "done", "error", "cancelled"]
+    stage: Optional[Literal["first", "refined"]] = None
+    recommendations: Optional[List[RecommendationResult]] = None
+    text: Optional[str] = None
+    seq: Optional[int] = None
+    # The speech as SSML, when the persona speaks SSML
+    ssml: Optional[str] = None
+    audio: Optional[str] = None
+    audio_format: Optional[str] = None
+    response: Optional[ProductQueryResponse] = None
+    detail: Optional[str] = None
+
+
+class VoicePersona(BaseModel):
+    name: str
+    # Voice name the TTS service knows; None for its default
+    voice: Optional[str] = None
+    # Voices for other languages, e.g. {"de": "de-DE-KatjaNeural"}
+    voices: Dict[str, str] = Field(default_factory=dict)
+    # 1.0 is the voice's normal rate
+    speaking_rate: float = Field(default=1.0, ge=0.5, le=2.0)
+    # Semitones up or down from the voice's normal pitch
+    pitch: float = Field(default=0.0, ge=-12.0, le=12.0)
+    # Speak SSML: emphasis on brand names, pauses after prices
+    ssml: bool = True
+    price_pause_ms: int = Field(default=300, ge=0, le=2000)
+
+
+class VoiceGatewayStart(BaseModel):
+    """The first frame on a voice gateway WebSocket."""
+    type: Literal["start"]
+    session_id: str
+    # Graph-service API key, checked for the tenant; browsers can't set
+    # headers on a WebSocket. Starts without one are refused rather than
+    # run on the service's own key
+    api_key: Optional[str] = None
+    tenant_id: Optional[str] = None
+    # Defaults for the session's searches
+    locale: Optional[str] = None
+    persona: Optional[str] = None
+    speaking_rate: Optional[float] = Field(default=None, ge=0.5, le=2.0)
+    pitch: Optional[float] = Field(default=None, ge=-12.0, le=12.0)
+    # The client's audio frames are 16-bit little-endian mono PCM at this rate
+    sample_rate: int = Field(default=16000, ge=8000, le=48000)
+
+
+class PromptRenderRequest(BaseModel):
+    query: str
+    tenant_id: Optional[str] = None
+    experiment: Optional[str] = None
+    # Picks the language's prompts; detected from the query when not set
+    locale: Optional[str] = None
+    # Also renders the relevance prompt for this product
+    product: Optional[Dict[str, Any]] = None
+
+
+class RenderedPromptResult(BaseModel):
+    name: str
+    source: str
+    text: str
+
+
+class PromptRenderResponse(BaseModel):
+    prompts: List[RenderedPromptResult]
+
+
+class ShippingAddress(BaseModel):
+    # ISO 3166-1 alpha-2 code
+    country: str
+    region: str = ""
+    postal_code: str = ""
+    city: str = ""
+
+
+class VoiceAction(BaseModel):
+    """A cart or order change asked for by voice.
+    
+    add_to_cart takes product_id with size, or sku. remove_from_cart takes
+    either too, and removes every unit unless quantity is set. place_order
+    orders the whole cart.
+    """
+    type: Literal["add_to_cart", "remove_from_cart", "clear_cart", "place_order"]
+    product_id: Optional[str] = None
+    size: Optional[str] = None
+    sku: Optional[str] = None
+    quantity: Optional[int] = Field(default=None, ge=1)
+    # place_order
+    customer_id: Optional[str] = None
+    payment_method: Optional[str] = None
+    currency: Optional[str] = None
+    destination: Optional[ShippingAddress] = None
+    tenant_id: Optional[str] = None
+
+
+class ConfirmActionRequest(BaseModel):
+    # False declines the proposed action
+    confirm: bool = True
+
+
+class CartItem(BaseModel):
+    sku: str
+    product_id: str
+    name: str
+    size: Optional[str] = None
+    unit_price: float
+    quantity: int
+
+
+class ActionResponse(BaseModel):
+    """The outcome of a voice action, confirmation or undo.
+    
+    needs_confirmation: nothing changed yet; confirm action_id within
+        expires_in seconds
+    done: the action ran; undone: the last action was reverted
+    cancelled: the proposal was declined; expired: it timed out or was
+        replaced
+    failed: nothing changed, see prompt
+    """
+    status: Literal["needs_confirmation", "done", "undone", "cancelled", "expired", "failed"]
+    # What to say to the user
+    prompt: str
+    action_id: Optional[str] = None
+    expires_in: Optional[float] = None
+    cart: List[CartItem] = Field(default_factory=list)
+    order: Optional[Dict[str, Any]] = None
 
 
 class HealthResponse(BaseModel):
     status: str
     semantic_engine_connected: bool
     graph_service_connected: bool
+    # Share of recent searches that fell back to the keyword path
+    llm_fallback_rate: float = 0.0
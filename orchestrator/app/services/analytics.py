@@ -0,0 +1,254 @@
+import json
+import logging
+import os
+import re
+import threading
+from collections import Counter, OrderedDict
+from dataclasses import dataclass, field
+from datetime import datetime, timezone
+from typing import Any, Dict, List, Optional, Set, Tuple
+
+from app.models.schemas import ActionResponse, ProductQueryRequest, ProductQueryResponse, VoiceAction
+
+logger = logging.getLogger(__name__)
+
+# The voice conversion funnel, in order
+FUNNEL_STAGES = ("search", "product_detail", "add_to_cart", "order")
+
+
+@dataclass
+class _Day:
+    date: str
+    # Session -> (tenant, stages it reached)
+    sessions: "OrderedDict[str, Tuple[str, Set[str]]]" = field(default_factory=OrderedDict)
+    # Tenant -> sessions seen
+    visitors: Counter = field(default_factory=Counter)
+    # (tenant, stage) -> sessions that reached it
+    stages: Counter = field(default_factory=Counter)
+    # (tenant, intent) -> requests
+    intents: Counter = field(default_factory=Counter)
+
+
+class FunnelTracker:
+    """Voice conversion: how far sessions get through the funnel search →
+    product detail → add to cart → order, and what users ask for.
+
+    Counts are kept per tenant and UTC day; a session counts once per stage
+    and day, whether or not it reached the stages before. The last
+    report_days days' reports are kept, and also written to report_dir as
+    funnel-YYYY-MM-DD.json when it's set. Totals since start are exported
+    in the Prometheus text format. Only max_sessions sessions are followed
+    at once; the least recently seen are forgotten.
+    """
+
+    def __init__(self, report_dir: Optional[str] = None, report_days: int = 30, max_sessions: int = 10000):
+        self.report_dir = report_dir
+        self.report_days = report_days
+        self.max_sessions = max_sessions
+        self._lock = threading.Lock()
+        self._day = _Day(date=_today())
+        self._reports: "OrderedDict[str, Dict[str, Any]]" = OrderedDict()
+        self._tenants: "OrderedDict[str, str]" = OrderedDict()
+        self._stage_totals: Counter = Counter()
+        self._intent_totals: Counter = Counter()
+        self._session_totals: Counter = Counter()
+
+    def search(self, request: ProductQueryRequest, response: Optional[ProductQueryResponse]):
+        """Counts a search, and the search stage once it found something."""
+        self.intent("refine" if request.refine else "search", request.session_id, request.tenant_id)
+        if response is not None and response.recommendations:
+            self.reached("search", request.session_id, request.tenant_id)
+
+    def action(
+        self,
+        session_id: str,
+        step: str,
+        response: ActionResponse,
+        action: Optional[VoiceAction] = None,
+        tenant_id: Optional[str] = None,
+    ):
+        """Counts a voice action, confirmation or undo; step is as in the
+        transcript. Adding to the cart and ordering count once they're done."""
+        self.intent(action.type if action is not None else step, session_id, tenant_id)
+        if response.status != "done":
+            return
+        if action is not None and action.type == "add_to_cart":
+            self.reached("add_to_cart", session_id, tenant_id)
+        elif response.order:
+            self.reached("order", session_id, tenant_id)
+
+    def intent(self, intent: str, session_id: Optional[str] = None, tenant_id: Optional[str] = None):
+        with self._lock:
+            day = self._current()
+            tenant = self._tenant(session_id, tenant_id)
+            if session_id:
+                self._session(day, session_id, tenant)
+            day.intents[(tenant, intent)] += 1
+            self._intent_totals[(tenant, intent)] += 1
+
+    def reached(self, stage: str, session_id: Optional[str], tenant_id: Optional[str] = None):
+        if not session_id:
+            return
+        with self._lock:
+            day = self._current()
+            tenant = self._tenant(session_id, tenant_id)
+            stages = self._session(day, session_id, tenant)
+            if stage in stages:
+                return
+            stages.add(stage)
+            day.stages[(tenant, stage)] += 1
+            self._stage_totals[(tenant, stage)] += 1
+
+    def report(self, date: Optional[str] = None) -> Optional[Dict[str, Any]]:
+        """The day's report (YYYY-MM-DD), today's so far when date is None;
+        None if there's none for that day."""
+        if date is not None and not re.fullmatch(r"\d{4}-\d{2}-\d{2}", date):
+            return None
+        with self._lock:
+            day = self._current()
+            if date is None or date == day.date:
+                return _report(day)
+            if date in self._reports:
+                return self._reports[date]
+        if self.report_dir is None:
+            return None
+        try:
+            with open(os.path.join(self.report_dir, f"funnel-{date}.json")) as f:
+                return json.load(f)
+        except (OSError, ValueError):
+            return None
+
+    def roll_over(self):
+        """Closes yesterday's report if nothing has since."""
+        with self._lock:
+            self._current()
+
+    def prometheus(self) -> str:
+        """Totals since start in the Prometheus text exposition format."""
+        with self._lock:
+            self._current()
+            lines = [
+                "# HELP voice_sessions_total Voice sessions seen, once per session and day.",
+                "# TYPE voice_sessions_total counter",
+            ]
+            for tenant, count in sorted(self._session_totals.items()):
+                lines.append(f'voice_sessions_total{{tenant="{_label(tenant)}"}} {count}')
+            lines += [
+                "# HELP voice_funnel_sessions_total Voice sessions reaching a funnel stage, once per session and day.",
+                "# TYPE voice_funnel_sessions_total counter",
+            ]
+            for (tenant, stage), count in sorted(self._stage_totals.items()):
+                lines.append(f'voice_funnel_sessions_total{{tenant="{_label(tenant)}",stage="{stage}"}} {count}')
+            lines += [
+                "# HELP voice_intents_total Voice requests by intent.",
+                "# TYPE voice_intents_total counter",
+            ]
+            for (tenant, intent), count in sorted(self._intent_totals.items()):
+                lines.append(f'voice_intents_total{{tenant="{_label(tenant)}",intent="{_label(intent)}"}} {count}')
+        return "\n".join(lines) + "\n"
+
+    def _current(self) -> _Day:
+        """Today's counts, closing the previous day's on the first call of a new one."""
+        today = _today()
+        if self._day.date != today:
+            report = _report(self._day)
+            self._reports[self._day.date] = report
+            while len(self._reports) > self.report_days:
+                self._reports.popitem(last=False)
+            self._write(report)
+            self._day = _Day(date=today)
+        return self._day
+
+    def _write(self, report: Dict[str, Any]):
+        total = report["total"]
+        summary = ", ".join(f"{stage['stage']} {stage['sessions']}" for stage in total["funnel"])
+        logger.info(f"Voice funnel for {report['date']}: {total['sessions']} sessions; {summary}")
+        if self.report_dir is None:
+            return
+        try:
+            os.makedirs(self.report_dir, exist_ok=True)
+            with open(os.path.join(self.report_dir, f"funnel-{report['date']}.json"), "w") as f:
+                json.dump(report, f, indent=2)
+        except OSError as e:
+            logger.error(f"Failed to write the funnel report for {report['date']}: {e}")
+
+    def _tenant(self, session_id: Optional[str], tenant_id: Optional[str]) -> str:
+        """The tenant to count under: the one given, else the one the
+        session was last seen with, since confirmations don't say."""
+        if not session_id:
+            return tenant_id or ""
+        if tenant_id:
+            self._tenants[session_id] = tenant_id
+        tenant = self._tenants.get(session_id, "")
+        if session_id in self._tenants:
+            self._tenants.move_to_end(session_id)
+        while len(self._tenants) > self.max_sessions:
+            self._tenants.popitem(last=False)
+        return tenant
+
+    def _session(self, day: _Day, session_id: str, tenant: str) -> Set[str]:
+        if session_id not in day.sessions:
+            day.sessions[session_id] = (tenant, set())
+            day.visitors[tenant] += 1
+            self._session_totals[tenant] += 1
+        day.sessions.move_to_end(session_id)
+        while len(day.sessions) > self.max_sessions:
+            day.sessions.popitem(last=False)
+        return day.sessions[session_id][1]
+
+
+def _report(day: _Day) -> Dict[str, Any]:
+    tenants = sorted(set(day.visitors) | {tenant for tenant, _ in day.intents})
+    return {
+        "date": day.date,
+        "total": _funnel(
+            sum(day.visitors.values()),
+            {stage: sum(c for (_, s), c in day.stages.items() if s == stage) for stage in FUNNEL_STAGES},
+            _sum_by_intent(day.intents, None),
+        ),
+        "tenants": {
+            tenant: _funnel(
+                day.visitors[tenant],
+                {stage: day.stages[(tenant, stage)] for stage in FUNNEL_STAGES},
+                _sum_by_intent(day.intents, tenant),
+            )
+            for tenant in tenants
+        },
+    }
+
+
+def _funnel(sessions: int, reached: Dict[str, int], intents: Dict[str, int]) -> Dict[str, Any]:
+    """Sessions at each stage, with the share of the previous stage's and
+    of the searching sessions that got there."""
+    stages: List[Dict[str, Any]] = []
+    previous = None
+    for stage in FUNNEL_STAGES:
+        count = reached.get(stage, 0)
+        stages.append({
+            "stage": stage,
+            "sessions": count,
+            "from_previous": _rate(count, previous) if previous is not None else None,
+            "from_search": _rate(count, reached.get("search", 0)),
+        })
+        previous = count
+    return {"sessions": sessions, "funnel": stages, "intents": intents}
+
+
+def _sum_by_intent(intents: Counter, tenant: Optional[str]) -> Dict[str, int]:
+    totals: Counter = Counter()
+    for (t, intent), count in intents.items():
+        if tenant is None or t == tenant:
+            totals[intent] += count
+    return dict(totals.most_common())
+
+
+def _rate(count: int, base: int) -> Optional[float]:
+    return round(count / base, 4) if base else None
+
+
+def _label(value: str) -> str:
+    return value.replace("\\", "\\\\").replace('"', '\\"').replace("\n", "\\n")
+
+
+def _today() -> str:
+    return datetime.now(timezone.utc).strftime("%Y-%m-%d")
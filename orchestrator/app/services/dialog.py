@@ -0,0 +1,352 @@
+import asyncio
+import hashlib
+import hmac
+import logging
+import time
+import uuid
+from collections import OrderedDict, deque
+from dataclasses import dataclass, field
+from typing import Deque, List, Optional
+
+import grpc
+
+from app.clients.graph_client import GraphServiceClient
+from app.models.schemas import ActionResponse, CartItem, VoiceAction
+
+logger = logging.getLogger(__name__)
+
+# Actions that remove things or spend money only run once confirmed
+CONFIRMED_ACTIONS = {"remove_from_cart", "clear_cart", "place_order"}
+
+
+class ActionError(Exception):
+    """An action that can't run; the message is said to the user."""
+
+
+@dataclass
+class _Proposal:
+    id: str
+    action: VoiceAction
+    expires_at: float
+    # The cart the user was asked about, and for orders the order id, so
+    # a retried confirmation never orders twice
+    cart: List[CartItem]
+    order_id: Optional[str] = None
+
+
+@dataclass
+class _Done:
+    action: VoiceAction
+    summary: str
+    cart_before: List[CartItem]
+    at: float
+    undoable: bool = True
+
+
+@dataclass
+class _Session:
+    cart: List[CartItem] = field(default_factory=list)
+    pending: Optional[_Proposal] = None
+    history: Deque[_Done] = field(default_factory=deque)
+    touched: float = field(default_factory=time.monotonic)
+    # Digest of the credential that first used the session
+    owner: Optional[str] = None
+
+
+class DialogManager:
+    """The voice cart of each session, and the actions that change it.
+
+    Adding to the cart runs straight away. Removing from it, emptying it
+    and placing an order are proposed first and only run when confirmed
+    within confirm_timeout seconds; any other action drops the proposal.
+    The last undo_window seconds of actions can be undone one at a time,
+    except placing an order. Only used from the event loop.
+    """
+
+    def __init__(
+        self,
+        confirm_timeout: float = 30.0,
+        undo_window: float = 300.0,
+        max_history: int = 10,
+        ttl: float = 1800.0,
+        max_sessions: int = 1000,
+    ):
+        self.confirm_timeout = confirm_timeout
+        self.undo_window = undo_window
+        self.max_history = max_history
+        self.ttl = ttl
+        self.max_sessions = max_sessions
+        self._sessions: "OrderedDict[str, _Session]" = OrderedDict()
+
+    def _session(self, session_id: str) -> _Session:
+        now = time.monotonic()
+        session = self._sessions.get(session_id)
+        if session is None or now - session.touched > self.ttl:
+            session = _Session(history=deque(maxlen=self.max_history))
+            self._sessions[session_id] = session
+        session.touched = now
+        self._sessions.move_to_end(session_id)
+        while len(self._sessions) > self.max_sessions:
+            self._sessions.popitem(last=False)
+        return session
+
+    def claim(self, session_id: str, credential: str) -> bool:
+        """Binds the session to credential the first time it's used; false
+        when another credential holds it."""
+        session = self._session(session_id)
+        digest = hashlib.sha256(credential.encode()).hexdigest()
+        if session.owner is None:
+            session.owner = digest
+        return hmac.compare_digest(session.owner, digest)
+
+    def cart(self, session_id: str) -> List[CartItem]:
+        return list(self._session(session_id).cart)
+
+    async def propose(self, session_id: str, action: VoiceAction, graph_client: GraphServiceClient) -> ActionResponse:
+        """Runs the action, or asks for confirmation first if it needs one."""
+        session = self._session(session_id)
+        if session.pending is not None:
+            logger.info(f"Dropping unconfirmed {session.pending.action.type} for session {session_id}")
+            session.pending = None
+
+        try:
+            if action.type not in CONFIRMED_ACTIONS:
+                return await self._run(session, action, graph_client, list(session.cart))
+
+            proposal = _Proposal(
+                id=uuid.uuid4().hex,
+                action=action,
+                expires_at=time.monotonic() + self.confirm_timeout,
+                cart=list(session.cart),
+            )
+            if action.type == "place_order":
+                _check_order(action, session.cart)
+                proposal.order_id = str(uuid.uuid4())
+                prompt = await self._order_prompt(action, session.cart, graph_client)
+            else:
+                _apply(session.cart, action)
+                prompt = f"{_describe(action, session.cart)}?"
+        except ActionError as e:
+            return ActionResponse(status="failed", prompt=str(e), cart=session.cart)
+
+        session.pending = proposal
+        logger.info(f"Proposed {action.type} for session {session_id}, awaiting confirmation")
+        return ActionResponse(
+            status="needs_confirmation",
+            prompt=prompt,
+            action_id=proposal.id,
+            expires_in=self.confirm_timeout,
+            cart=session.cart,
+        )
+
+    async def confirm(
+        self, session_id: str, action_id: str, confirmed: bool, graph_client: GraphServiceClient
+    ) -> ActionResponse:
+        """Runs or declines a proposed action."""
+        session = self._session(session_id)
+        proposal = session.pending
+        if proposal is None or proposal.id != action_id:
+            return ActionResponse(
+                status="expired", prompt="That request is no longer pending. Please ask again.", cart=session.cart
+            )
+        # Taken before running, so a second confirmation can't run it twice
+        session.pending = None
+        if time.monotonic() > proposal.expires_at:
+            return ActionResponse(
+                status="expired", prompt="That took too long to confirm. Please ask again.", cart=session.cart
+            )
+        if not confirmed:
+            logger.info(f"Session {session_id} declined {proposal.action.type}")
+            return ActionResponse(status="cancelled", prompt="Okay, I won't do that.", cart=session.cart)
+
+        try:
+            return await self._run(session, proposal.action, graph_client, proposal.cart, proposal.order_id)
+        except ActionError as e:
+            return ActionResponse(status="failed", prompt=str(e), cart=session.cart)
+
+    def undo_last_action(self, session_id: str) -> ActionResponse:
+        """Reverts the session's last action, if it's recent and reversible."""
+        session = self._session(session_id)
+        session.pending = None
+        if not session.history or time.monotonic() - session.history[-1].at > self.undo_window:
+            return ActionResponse(status="failed", prompt="There's nothing to undo.", cart=session.cart)
+        last = session.history[-1]
+        if not last.undoable:
+            return ActionResponse(
+                status="failed",
+                prompt="Your order has already been placed, so I can't undo it. You can return it instead.",
+                cart=session.cart,
+            )
+        session.history.pop()
+        session.cart = last.cart_before
+        logger.info(f"Session {session_id} undid {last.action.type}")
+        return ActionResponse(status="undone", prompt=f"Undone: {last.summary}.", cart=session.cart)
+
+    async def _run(
+        self,
+        session: _Session,
+        action: VoiceAction,
+        graph_client: GraphServiceClient,
+        cart: List[CartItem],
+        order_id: Optional[str] = None,
+    ) -> ActionResponse:
+        # The cart the action was judged against must still be the cart
+        if cart != session.cart:
+            raise ActionError("Your cart changed since I asked. Please ask again.")
+
+        if action.type == "add_to_cart":
+            item = await _resolve_item(action, graph_client)
+            after = _add(session.cart, item)
+            summary = f"added {_item_name(item)} to your cart"
+        elif action.type == "place_order":
+            try:
+                order = await asyncio.to_thread(
+                    graph_client.place_order,
+                    order_id or str(uuid.uuid4()),
+                    action.customer_id,
+                    [(item.sku, item.quantity) for item in session.cart],
+                    action.payment_method,
+                    action.currency or "",
+                    action.destination.model_dump() if action.destination else None,
+                    action.tenant_id or "",
+                )
+            except grpc.RpcError as e:
+                logger.error(f"Voice order failed: {e}")
+                raise ActionError(f"I couldn't place your order: {e.details()}")
+            session.history.append(
+                _Done(action=action, summary="placed your order", cart_before=session.cart, at=time.monotonic(), undoable=False)
+            )
+            session.cart = []
+            return ActionResponse(
+                status="done",
+                prompt=f"Your order is placed. The total was {_money(order['total'], order['currency'])}.",
+                cart=session.cart,
+                order=order,
+            )
+        else:
+            after = _apply(session.cart, action)
+            summary = _describe(action, session.cart, past=True)
+
+        session.history.append(_Done(action=action, summary=summary, cart_before=session.cart, at=time.monotonic()))
+        session.cart = after
+        return ActionResponse(status="done", prompt=f"Okay, {summary}.", cart=session.cart)
+
+    async def _order_prompt(self, action: VoiceAction, cart: List[CartItem], graph_client: GraphServiceClient) -> str:
+        """Asks to confirm the order, with its total including tax."""
+        count = sum(item.quantity for item in cart)
+        try:
+            priced = await asyncio.to_thread(
+                graph_client.price_cart,
+                [(item.sku, item.quantity) for item in cart],
+                action.currency or "",
+                action.destination.model_dump() if action.destination else None,
+            )
+            total = f"{_money(priced['total'], priced['currency'])} including tax"
+        except grpc.RpcError as e:
+            # Still worth confirming on the catalog prices alone
+            logger.warning(f"Couldn't price voice cart: {e}")
+            total = f"{_money(sum(item.unit_price * item.quantity for item in cart), action.currency)} before tax"
+        items = "1 item" if count == 1 else f"{count} items"
+        return f"Place your order for {items}, {total}?"
+
+
+def _check_order(action: VoiceAction, cart: List[CartItem]):
+    if not cart:
+        raise ActionError("Your cart is empty.")
+    if not action.customer_id or not action.payment_method:
+        raise ActionError("I need your account and payment method to place the order.")
+
+
+async def _resolve_item(action: VoiceAction, graph_client: GraphServiceClient) -> CartItem:
+    """Finds the catalog size the action names."""
+    if not action.product_id:
+        raise ActionError("Which product should I add?")
+    product = await asyncio.to_thread(graph_client.get_product, action.product_id)
+    if product is None:
+        raise ActionError("I couldn't find that product.")
+    sizes = product.get("sizes") or []
+    if action.sku:
+        matches = [s for s in sizes if s.get("sku") == action.sku]
+    elif action.size:
+        matches = [s for s in sizes if _same_size(s.get("size") or "", action.size)]
+    else:
+        matches = sizes if len(sizes) == 1 else []
+        if not matches:
+            raise ActionError(f"Which size of {product['name']} would you like?")
+    if not matches:
+        raise ActionError(f"{product['name']} doesn't come in that size.")
+    size = matches[0]
+    if not size.get("in_stock", True):
+        raise ActionError(f"{product['name']} in {size.get('size')} is out of stock.")
+    return CartItem(
+        sku=size["sku"],
+        product_id=product["id"],
+        name=product["name"],
+        size=size.get("size"),
+        unit_price=float(product.get("price") or 0.0),
+        quantity=action.quantity or 1,
+    )
+
+
+def _same_size(catalog: str, spoken: str) -> bool:
+    """Compares "US 10" with "us 10", or "10" when no system is said."""
+    catalog_parts, spoken_parts = catalog.upper().split(), spoken.upper().split()
+    if len(spoken_parts) == 1:
+        return catalog_parts[-1:] == spoken_parts
+    return catalog_parts == spoken_parts
+
+
+def _add(cart: List[CartItem], item: CartItem) -> List[CartItem]:
+    for i, line in enumerate(cart):
+        if line.sku == item.sku:
+            return cart[:i] + [line.model_copy(update={"quantity": line.quantity + item.quantity})] + cart[i + 1:]
+    return cart + [item]
+
+
+def _matching(cart: List[CartItem], action: VoiceAction) -> List[CartItem]:
+    if action.sku:
+        return [line for line in cart if line.sku == action.sku]
+    return [
+        line for line in cart
+        if line.product_id == action.product_id and (not action.size or _same_size(line.size or "", action.size))
+    ]
+
+
+def _apply(cart: List[CartItem], action: VoiceAction) -> List[CartItem]:
+    """The cart after removing from or emptying it."""
+    if action.type == "clear_cart":
+        if not cart:
+            raise ActionError("Your cart is already empty.")
+        return []
+    removed = _matching(cart, action)
+    if not removed:
+        raise ActionError("That isn't in your cart.")
+    after = []
+    for line in cart:
+        if line in removed and action.quantity and line.quantity > action.quantity:
+            line = line.model_copy(update={"quantity": line.quantity - action.quantity})
+        elif line in removed:
+            continue
+        after.append(line)
+    return after
+
+
+def _item_name(item: CartItem) -> str:
+    name = f"{item.quantity} × {item.name}" if item.quantity > 1 else item.name
+    return f"{name} in {item.size}" if item.size else name
+
+
+def _describe(action: VoiceAction, cart: List[CartItem], past: bool = False) -> str:
+    """Says what removing from or emptying the cart does."""
+    if action.type == "clear_cart":
+        return "emptied your cart" if past else "Empty your cart"
+    removed = _matching(cart, action)
+    if action.quantity:
+        removed = [line.model_copy(update={"quantity": min(line.quantity, action.quantity)}) for line in removed]
+    names = ", ".join(_item_name(line) for line in removed)
+    return f"removed {names} from your cart" if past else f"Remove {names} from your cart"
+
+
+def _money(amount: float, currency: Optional[str]) -> str:
+    symbol = {"USD": "$", "GBP": "£", "EUR": "€"}.get((currency or "USD").upper())
+    return f"{symbol}{amount:.2f}" if symbol else f"{amount:.2f} {currency}"
@@ -0,0 +1,85 @@
+import asyncio
+import time
+import threading
+import logging
+from collections import deque
+from typing import Deque, Optional, Tuple
+
+import httpx
+
+logger = logging.getLogger(__name__)
+
+
+class FallbackMonitor:
+    """Tracks how many searches fell back to the keyword path.
+
+    The alert fires when at least `threshold` of the searches in the last
+    `window` seconds fell back, once there are `min_searches` of them, and
+    resolves when the rate drops below it again. Both are logged and, when
+    webhook_url is set, posted there as JSON.
+    """
+
+    def __init__(
+        self,
+        window: float = 300.0,
+        threshold: float = 0.5,
+        min_searches: int = 10,
+        webhook_url: Optional[str] = None,
+    ):
+        self.window = window
+        self.threshold = threshold
+        self.min_searches = min_searches
+        self.webhook_url = webhook_url
+        self._lock = threading.Lock()
+        self._searches: Deque[Tuple[float, bool]] = deque()
+        self.firing = False
+
+    def record(self, fell_back: bool):
+        with self._lock:
+            now = time.monotonic()
+            self._searches.append((now, fell_back))
+            rate, total = self._rate(now)
+            if total < self.min_searches or (rate >= self.threshold) == self.firing:
+                return
+            self.firing = not self.firing
+
+        if self.firing:
+            logger.error(
+                f"ALERT llm_fallback_rate firing: {rate:.0%} of {total} searches "
+                f"in the last {self.window:.0f}s used the keyword path"
+            )
+        else:
+            logger.info(f"ALERT llm_fallback_rate resolved: {rate:.0%} of {total} searches")
+        if self.webhook_url:
+            payload = {
+                "alert": "llm_fallback_rate",
+                "status": "firing" if self.firing else "resolved",
+                "rate": rate,
+                "searches": total,
+                "window_seconds": self.window,
+            }
+            try:
+                asyncio.get_running_loop().create_task(self._post(payload))
+            except RuntimeError:
+                logger.warning("No event loop to post the fallback alert from")
+
+    def rate(self) -> float:
+        """Share of the window's searches that fell back."""
+        with self._lock:
+            return self._rate(time.monotonic())[0]
+
+    def _rate(self, now: float) -> Tuple[float, int]:
+        while self._searches and now - self._searches[0][0] > self.window:
+            self._searches.popleft()
+        total = len(self._searches)
+        if total == 0:
+            return 0.0, 0
+        return sum(1 for _, fell_back in self._searches if fell_back) / total, total
+
+    async def _post(self, payload: dict):
+        try:
+            async with httpx.AsyncClient(timeout=5.0) as client:
+                response = await client.post(self.webhook_url, json=payload)
+                response.raise_for_status()
+        except Exception as e:
+            logger.error(f"Failed to post fallback alert: {e}")
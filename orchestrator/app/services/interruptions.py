@@ -0,0 +1,39 @@
+import asyncio
+import logging
+from typing import Dict
+
+logger = logging.getLogger(__name__)
+
+
+class Interruptions:
+    """The in-flight streamed search of each voice session.
+
+    The user barging in stops it: either the client says so, or a new
+    search starts for the same session. Only used from the event loop.
+    """
+
+    def __init__(self):
+        self._active: Dict[str, asyncio.Event] = {}
+
+    def begin(self, session_id: str) -> asyncio.Event:
+        """Registers a search for the session, interrupting the one before.
+
+        The returned event is set when this search is interrupted.
+        """
+        self.interrupt(session_id)
+        event = asyncio.Event()
+        self._active[session_id] = event
+        return event
+
+    def end(self, session_id: str, event: asyncio.Event):
+        if self._active.get(session_id) is event:
+            del self._active[session_id]
+
+    def interrupt(self, session_id: str) -> bool:
+        """Interrupts the session's search; reports whether one was running."""
+        event = self._active.pop(session_id, None)
+        if event is None:
+            return False
+        logger.info(f"Interrupting search for session {session_id}")
+        event.set()
+        return True
@@ -0,0 +1,125 @@
+import re
+import logging
+from dataclasses import dataclass, field
+from typing import Any, Dict, List, Optional
+
+logger = logging.getLogger(__name__)
+
+FULLTEXT_INDEX = "productSearch"
+
+STOPWORDS = {
+    "a", "an", "the", "i", "im", "i'm", "me", "my", "we", "you", "want", "wanna", "need",
+    "looking", "look", "search", "searching", "find", "show", "get", "buy", "give", "some",
+    "any", "for", "with", "in", "of", "on", "to", "and", "or", "that", "is", "are", "please",
+    "can", "could", "would", "like", "something", "dollars", "dollar", "bucks", "usd",
+}
+
+_COLORS = {
+    "black", "white", "red", "blue", "green", "yellow", "orange", "purple", "pink",
+    "brown", "grey", "gray", "beige", "navy", "silver", "gold",
+}
+
+_PRICE = r"[$£€₹]?\s*(\d+(?:\.\d+)?)"
+# In English, German and Spanish
+_BETWEEN = re.compile(r"\b(?:between|zwischen|entre)\s*" + _PRICE + r"\s*(?:and|to|und|bis|y|-)\s*" + _PRICE)
+_MAX_PRICE = re.compile(
+    r"\b(?:under|below|less than|cheaper than|up to|at most|max(?:imum)?"
+    r"|unter|bis|höchstens|weniger als|menos de|por debajo de|hasta|como máximo)\s*" + _PRICE
+)
+_MIN_PRICE = re.compile(
+    r"\b(?:over|above|more than|at least|min(?:imum)?"
+    r"|über|ab|mindestens|mehr als|más de|por encima de|desde|como mínimo)\s*" + _PRICE
+)
+_TOKEN = re.compile(r"[a-z0-9][a-z0-9'.\-]*")
+
+# Characters with a meaning in Lucene query syntax
+_LUCENE_SPECIAL = re.compile(r'([+\-!(){}\[\]^"~*?:\\/&|])')
+
+
+@dataclass
+class KeywordQuery:
+    """A query as the keyword analyzer reads it, without the LLM."""
+    terms: List[str] = field(default_factory=list)
+    color: Optional[str] = None
+    min_price: Optional[float] = None
+    max_price: Optional[float] = None
+
+    @property
+    def text(self) -> str:
+        """Terms for semantic search."""
+        return " ".join(([self.color] if self.color else []) + self.terms)
+
+    def lucene(self) -> str:
+        """The terms as a Lucene query, any of which may match."""
+        return " ".join(_LUCENE_SPECIAL.sub(r"\\\1", term) for term in self.terms)
+
+    def cypher(self, limit: int = 10) -> str:
+        """A fulltext search on the product index with the filters applied."""
+        conditions = []
+        if self.color:
+            conditions.append(f"toLower(node.color) = {_literal(self.color)}")
+        if self.min_price is not None:
+            conditions.append(f"node.price >= {self.min_price:g}")
+        if self.max_price is not None:
+            conditions.append(f"node.price <= {self.max_price:g}")
+        where = f" WHERE {' AND '.join(conditions)}" if conditions else ""
+
+        if not self.terms:
+            return f"MATCH (node:Product){where} RETURN node LIMIT {limit}"
+        return (
+            f"CALL db.index.fulltext.queryNodes({_literal(FULLTEXT_INDEX)}, {_literal(self.lucene())}) "
+            f"YIELD node{where} RETURN node LIMIT {limit}"
+        )
+
+
+def _literal(value: str) -> str:
+    """A Cypher string literal."""
+    return "'" + value.replace("\\", "\\\\").replace("'", "\\'") + "'"
+
+
+def analyze(query: str) -> KeywordQuery:
+    """Reads keywords, a color and a price range out of a query."""
+    text = query.lower()
+    result = KeywordQuery()
+
+    match = _BETWEEN.search(text)
+    if match:
+        low, high = sorted([float(match.group(1)), float(match.group(2))])
+        result.min_price, result.max_price = low, high
+        text = text[:match.start()] + " " + text[match.end():]
+    for pattern, attr in ((_MAX_PRICE, "max_price"), (_MIN_PRICE, "min_price")):
+        match = pattern.search(text)
+        if match:
+            setattr(result, attr, float(match.group(1)))
+            text = text[:match.start()] + " " + text[match.end():]
+
+    for token in _TOKEN.findall(text):
+        token = token.strip(".-'")
+        if not token or token in STOPWORDS or token in result.terms:
+            continue
+        if token in _COLORS and result.color is None:
+            result.color = token
+            continue
+        result.terms.append(token)
+    return result
+
+
+class KeywordScorer:
+    """Scores relevance by keyword overlap; stands in for the LLM scorer."""
+
+    async def score_relevance(self, product: Dict[str, Any], user_query: str) -> float:
+        keywords = analyze(user_query)
+        wanted = keywords.terms + ([keywords.color] if keywords.color else [])
+        if not wanted:
+            return 0.5
+        category = product.get("category") or {}
+        haystack = " ".join([
+            str(product.get("name", "")),
+            str(product.get("brand", "")),
+            str(product.get("color", "")),
+            str(product.get("description", "")),
+            " ".join(product.get("tags", []) or []),
+            " ".join(str(v) for v in category.values()) if isinstance(category, dict) else "",
+        ]).lower()
+        found = set(_TOKEN.findall(haystack))
+        return sum(1 for term in wanted if term in found) / len(wanted)
@@ -0,0 +1,65 @@
+import re
+from typing import Dict, Optional, Tuple
+
+SUPPORTED_LANGUAGES = ("en", "de", "es")
+DEFAULT_LANGUAGE = "en"
+
+# The locale a shopper gets when only their language is known
+DEFAULT_LOCALES = {"en": "en-US", "de": "de-DE", "es": "es-ES"}
+
+# Short words that give a language away in a shopping query
+_MARKERS: Dict[str, set] = {
+    "en": {
+        "the", "and", "for", "with", "under", "over", "below", "than", "i", "want", "need", "show", "me",
+        "some", "looking", "find", "please", "shoes", "trainers", "sneakers", "size", "cheap", "red", "blue",
+        "black", "white", "mens", "womens", "of", "to", "a", "an", "any",
+    },
+    "de": {
+        "der", "die", "das", "den", "und", "für", "mit", "unter", "über", "bis", "ich", "möchte", "suche",
+        "zeig", "zeige", "mir", "bitte", "schuhe", "laufschuhe", "turnschuhe", "größe", "rot", "rote", "roten",
+        "blau", "blaue", "schwarz", "schwarze", "weiß", "weiße", "günstig", "günstige", "billig", "ein",
+        "eine", "einen", "herren", "damen", "euro", "nach",
+    },
+    "es": {
+        "el", "la", "los", "las", "y", "para", "con", "menos", "más", "de", "del", "quiero", "busco",
+        "necesito", "muéstrame", "por", "favor", "zapatos", "zapatillas", "talla", "rojo", "roja", "rojas",
+        "rojos", "azul", "azules", "negro", "negras", "negros", "blanco", "blancas", "blancos", "barato",
+        "baratas", "baratos", "un", "una", "unas", "unos", "hombre", "mujer", "euros",
+    },
+}
+# Letters only one of the languages writes
+_LETTERS = {"de": set("äöüß"), "es": set("ñ¿¡áíóú")}
+
+_WORD = re.compile(r"[^\W\d_]+")
+
+
+def detect_language(text: str) -> Optional[str]:
+    """The language of a spoken query, or None when it can't tell.
+
+    Counts the query's words that are typical of each language, and
+    letters only one of them uses; a tie is no answer.
+    """
+    lowered = text.lower()
+    words = _WORD.findall(lowered)
+    scores = {language: sum(word in markers for word in words) for language, markers in _MARKERS.items()}
+    for language, letters in _LETTERS.items():
+        scores[language] += sum(1 for c in lowered if c in letters)
+    best = max(scores.values())
+    if best == 0:
+        return None
+    leaders = [language for language, score in scores.items() if score == best]
+    return leaders[0] if len(leaders) == 1 else None
+
+
+def resolve_language(text: str, locale: Optional[str] = None) -> Tuple[str, str]:
+    """The language and locale a query is handled in.
+
+    An explicit locale wins; a language this service doesn't speak falls
+    back to the default language, keeping the locale for numbers and
+    sizes. Otherwise the language is detected from the query.
+    """
+    if locale:
+        language = re.split(r"[-_]", locale)[0].lower()
+        return (language if language in SUPPORTED_LANGUAGES else DEFAULT_LANGUAGE), locale
+    language = detect_language(text) or DEFAULT_LANGUAGE
+    return language, DEFAULT_LOCALES[language]
@@ -1,31 +1,63 @@
+import asyncio
 import os
 from typing import Dict, Any, Optional
 import logging
 import httpx
 
+from app.clients.resilience import CircuitOpenError, breaker_for
+from app.services.prompt_store import PromptStore, RenderedPrompt
+
 logger = logging.getLogger(__name__)
 
 
+class LLMUnavailableError(RuntimeError):
+    """Raised when Ollama errors, times out or returns nothing, or its breaker is open."""
+
+
 class LLMService:
     """LLM Service using Ollama (local LLM) for query generation and scoring."""
     
-    def __init__(self, base_url: str = "http://localhost:11434", model: str = "llama3.2"):
+    def __init__(
+        self,
+        prompts: PromptStore,
+        base_url: str = "http://localhost:11434",
+        model: str = "llama3.2",
+        timeout: float = 60.0,
+    ):
         """
         Initialize Ollama client.
         
         Args:
+            prompts: Prompt templates (see PromptStore)
             base_url: Ollama API URL (default: http://localhost:11434)
             model: Ollama model name (default: llama3.2 - fast and good for this use case)
                    Other options: mistral, phi3, gemma2, etc.
+            timeout: Seconds to wait for each generation
         """
+        self.prompts = prompts
         self.base_url = base_url
         self.model = model
-        self.client = httpx.AsyncClient(timeout=60.0)
+        # Pick the prompt overrides; set per request
+        self.tenant_id: Optional[str] = None
+        self.experiment: Optional[str] = None
+        self.language: Optional[str] = None
+        self.client = httpx.AsyncClient(timeout=timeout)
+        # Shared by every request, so a down Ollama fails fast
+        self.breaker = breaker_for(
+            f"llm:{base_url}",
+            threshold=int(os.getenv("LLM_BREAKER_THRESHOLD", "3")),
+            cooldown=float(os.getenv("LLM_BREAKER_COOLDOWN", "30.0")),
+            name="LLM",
+        )
         
         logger.info(f"Initialized Ollama client: {model} at {base_url}")
     
     async def _generate(self, prompt: str) -> str:
         """Generate text using Ollama."""
+        try:
+            self.breaker.allow()
+        except CircuitOpenError as e:
+            raise LLMUnavailableError(str(e))
         try:
             response = await self.client.post(
                 f"{self.base_url}/api/generate",
@@ -36,86 +68,59 @@ class LLMService:
                 }
             )
             response.raise_for_status()
-            data = response.json()
-            return data.get("response", "").strip()
+            text = response.json().get("response", "").strip()
+            if not text:
+                raise ValueError("empty response")
+        except asyncio.CancelledError:
+            # The caller went away; says nothing about Ollama's health
+            self.breaker.abandon()
+            raise
         except Exception as e:
+            self.breaker.record(True)
             logger.error(f"Ollama generation failed: {e}")
-            raise RuntimeError(f"Ollama failed: {e}")
+            raise LLMUnavailableError(f"Ollama failed: {e}")
+        self.breaker.record(False)
+        return text
+    
+    def render_prompt(self, name: str, **variables) -> RenderedPrompt:
+        """Renders a prompt for this request's tenant, experiment and language, without calling Ollama."""
+        return self.prompts.render(
+            name, variables, tenant_id=self.tenant_id, experiment=self.experiment, language=self.language
+        )
     
     async def generate_cypher(self, user_query: str) -> str:
-        """Generate Cypher query from natural language using Ollama."""
-        prompt = f"""You are a Cypher query generator for Neo4j.
-
-Database Schema:
-- Node: Product with properties: id, name, brand, color, price, original_price, description, tags (list), category (object with main_category, subcategory, specific_type)
-- Full-text index: 'productSearch' on [p.name, p.description, p.brand]
-
-Rules:
-1. Use full-text search: CALL db.index.fulltext.queryNodes('productSearch', '<search_terms>') YIELD node RETURN node LIMIT 10
-2. For brand/color filters, add WHERE clauses after the fulltext call
-3. For price ranges, use WHERE node.price <= <amount> or node.price >= <amount>
-4. Always use the fulltext index as the starting point for text search
-5. Return the product node as 'node' (not 'p')
-6. Return ONLY the Cypher query, no explanations or markdown
-
-Examples:
-- "red nike shoes under $100" -> CALL db.index.fulltext.queryNodes('productSearch', 'nike shoes') YIELD node WHERE node.color = 'Red' AND node.price <= 100 RETURN node LIMIT 10
-- "apple laptop" -> CALL db.index.fulltext.queryNodes('productSearch', 'apple laptop') YIELD node RETURN node LIMIT 10
-- "samsung phone blue" -> CALL db.index.fulltext.queryNodes('productSearch', 'samsung phone') YIELD node WHERE node.color = 'Blue' RETURN node LIMIT 10
-- "macbook pro under $3000" -> CALL db.index.fulltext.queryNodes('productSearch', 'macbook pro') YIELD node WHERE node.price <= 3000 RETURN node LIMIT 10
-
-Convert to Cypher: {user_query}
-
-Cypher query:"""
-
-        try:
-            cypher = await self._generate(prompt)
-            
-            # Clean up any markdown code blocks
-            if cypher.startswith("```"):
-                lines = cypher.split("\n")
-                if len(lines) > 2:
-                    cypher = "\n".join(lines[1:-1])
-                else:
-                    cypher = cypher.replace("```cypher", "").replace("```", "").strip()
-            
-            logger.info(f"Generated Cypher: {cypher}")
-            return cypher
-            
-        except Exception as e:
-            logger.error(f"Failed to generate Cypher: {e}")
-            # Fallback to simple query
-            return f"CALL db.index.fulltext.queryNodes('productSearch', '{user_query}') YIELD node RETURN node LIMIT 10"
+        """Generate Cypher query from natural language using Ollama.
+        
+        Raises LLMUnavailableError; callers fall back to the keyword path.
+        """
+        prompt = self.render_prompt("cypher", query=user_query)
+        cypher = await self._generate(prompt.text)
+        
+        # Clean up any markdown code blocks
+        if cypher.startswith("```"):
+            lines = cypher.split("\n")
+            if len(lines) > 2:
+                cypher = "\n".join(lines[1:-1])
+            else:
+                cypher = cypher.replace("```cypher", "").replace("```", "").strip()
+        
+        logger.info(f"Generated Cypher: {cypher}")
+        return cypher
     
     async def generate_search_terms(self, user_query: str) -> str:
-        """Generate optimized search terms for semantic search using Ollama."""
-        prompt = f"""Extract the core product search terms from the user query.
-Remove filler words, keep only important keywords for semantic similarity search.
-
-Examples:
-- "I want red nike running shoes under $100" -> red nike running shoes
-- "Looking for a comfortable leather jacket" -> comfortable leather jacket
-- "Apple MacBook Pro 16 inch under $2000" -> Apple MacBook Pro
-- "samsung galaxy phone blue" -> samsung galaxy phone
-- "kitchen mixer stainless steel" -> kitchen mixer stainless steel
-
-Query: {user_query}
-
-Search terms (just the cleaned terms, nothing else):"""
-
-        try:
-            search_terms = await self._generate(prompt)
-            logger.info(f"Generated search terms: {search_terms}")
-            return search_terms
-            
-        except Exception as e:
-            logger.error(f"Failed to generate search terms: {e}")
-            # Fallback to original query
-            return user_query
+        """Generate optimized search terms for semantic search using Ollama.
+        
+        Raises LLMUnavailableError; callers fall back to the keyword path.
+        """
+        prompt = self.render_prompt("search_terms", query=user_query)
+        search_terms = await self._generate(prompt.text)
+        logger.info(f"Generated search terms: {search_terms}")
+        return search_terms
     
-    async def score_relevance(self, product: Dict[str, Any], user_query: str) -> float:
-        """Score how relevant a product is to the user query using Ollama."""
-        product_summary = f"""
+    @staticmethod
+    def product_summary(product: Dict[str, Any]) -> str:
+        """The product as the relevance prompt describes it."""
+        return f"""
 Name: {product.get('name', 'N/A')}
 Brand: {product.get('brand', 'N/A')}
 Color: {product.get('color', 'N/A')}
@@ -124,28 +129,12 @@ Category: {product.get('category', {}).get('specific_type', 'N/A') if product.ge
 Description: {product.get('description', 'N/A')[:100]}...
 Tags: {', '.join(product.get('tags', []))}
 """
-
-        prompt = f"""Rate how well this product matches the user's search query.
-
-Rate from 0.0 to 1.0 where:
-- 1.0 = Perfect match (exactly what user wants)
-- 0.7-0.9 = Good match (meets most criteria)
-- 0.4-0.6 = Partial match (some criteria match)
-- 0.1-0.3 = Weak match (few criteria match)
-- 0.0 = No match
-
-Consider: brand, color, product type, price, description, category, tags.
-
-User query: {user_query}
-
-Product:{product_summary}
-
-Return ONLY a number between 0.0 and 1.0, no explanation.
-
-Relevance score:"""
-
+    
+    async def score_relevance(self, product: Dict[str, Any], user_query: str) -> float:
+        """Score how relevant a product is to the user query using Ollama."""
         try:
-            score_text = await self._generate(prompt)
+            prompt = self.render_prompt("relevance", query=user_query, product=self.product_summary(product))
+            score_text = await self._generate(prompt.text)
             
             # Extract number from response
             import re
@@ -0,0 +1,138 @@
+import json
+import os
+import threading
+import time
+import logging
+from dataclasses import dataclass
+from typing import Dict, List, Optional
+
+from app.models.schemas import VoicePersona
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_PERSONA = "default"
+
+
+class PersonaError(Exception):
+    pass
+
+
+@dataclass
+class _TenantPersonas:
+    default: str
+    # None: every persona
+    allowed: Optional[List[str]]
+
+
+@dataclass
+class _PersonaSet:
+    default: str
+    personas: Dict[str, VoicePersona]
+    tenants: Dict[str, _TenantPersonas]
+
+
+class PersonaStore:
+    """The voices streamed searches can speak in, read from a JSON file:
+
+        {
+          "default": "ava",
+          "personas": {
+            "ava": {"voice": "en-US-AvaNeural", "voices": {"de": "de-DE-KatjaNeural"}},
+            "max": {"voice": "en-US-GuyNeural", "speaking_rate": 1.1, "pitch": -2, "ssml": false}
+          },
+          "tenants": {"acme": {"default": "max", "personas": ["max", "ava"]}}
+        }
+
+    A tenant may be limited to some personas and have its own default.
+    A persona speaks other languages in its voices for them, or else in
+    default_voices. Without a file there is one persona, "default",
+    speaking in default_voice. The file is reloaded when it changes; a change that
+    fails to load is logged and the previous personas are kept.
+    """
+
+    def __init__(
+        self,
+        path: Optional[str] = None,
+        default_voice: Optional[str] = None,
+        default_voices: Optional[Dict[str, str]] = None,
+        reload_interval: float = 5.0,
+    ):
+        self.path = path
+        self.default_voice = default_voice
+        self.default_voices = default_voices or {}
+        self.reload_interval = reload_interval
+        self._lock = threading.Lock()
+        self._checked_at = 0.0
+        self._mtime = self._stat()
+        self._set = self._load()
+        logger.info(f"Loaded {len(self._set.personas)} voice personas")
+
+    def resolve(self, tenant_id: Optional[str] = None, name: Optional[str] = None) -> VoicePersona:
+        """The named persona, or the tenant's default when name is None."""
+        personas = self._current()
+        tenant = personas.tenants.get(tenant_id or "")
+        if name is None:
+            name = tenant.default if tenant is not None else personas.default
+        if name not in personas.personas or (tenant is not None and tenant.allowed is not None and name not in tenant.allowed):
+            raise PersonaError(f"unknown persona {name!r}")
+        return personas.personas[name]
+
+    def available(self, tenant_id: Optional[str] = None) -> List[VoicePersona]:
+        personas = self._current()
+        tenant = personas.tenants.get(tenant_id or "")
+        names = tenant.allowed if tenant is not None and tenant.allowed is not None else sorted(personas.personas)
+        return [personas.personas[name] for name in names]
+
+    def _current(self) -> _PersonaSet:
+        with self._lock:
+            now = time.monotonic()
+            if self.path is None or now - self._checked_at < self.reload_interval:
+                return self._set
+            self._checked_at = now
+
+            mtime = self._stat()
+            if mtime != self._mtime:
+                self._mtime = mtime
+                try:
+                    self._set = self._load()
+                    logger.info(f"Reloaded voice personas from {self.path}")
+                except (OSError, ValueError, PersonaError) as e:
+                    logger.error(f"Keeping previous voice personas, reload failed: {e}")
+            return self._set
+
+    def _stat(self) -> Optional[float]:
+        if self.path is None:
+            return None
+        try:
+            return os.stat(self.path).st_mtime
+        except OSError:
+            return None
+
+    def _load(self) -> _PersonaSet:
+        if self.path is None:
+            persona = VoicePersona(name=DEFAULT_PERSONA, voice=self.default_voice, voices=self.default_voices)
+            return _PersonaSet(default=DEFAULT_PERSONA, personas={DEFAULT_PERSONA: persona}, tenants={})
+
+        with open(self.path) as f:
+            config = json.load(f)
+        if not isinstance(config.get("personas"), dict) or not config["personas"]:
+            raise PersonaError(f"{self.path}: no personas")
+        personas = {}
+        for name, settings in config["personas"].items():
+            voices = {**self.default_voices, **settings.get("voices", {})}
+            personas[name] = VoicePersona(name=name, **{"voice": self.default_voice, **settings, "voices": voices})
+        default = config.get("default") or next(iter(personas))
+        if default not in personas:
+            raise PersonaError(f"{self.path}: unknown default persona {default!r}")
+
+        tenants: Dict[str, _TenantPersonas] = {}
+        for tenant_id, settings in config.get("tenants", {}).items():
+            allowed = settings.get("personas") or None
+            unknown = [name for name in allowed or [] if name not in personas]
+            if unknown:
+                raise PersonaError(f"{self.path}: tenant {tenant_id}: unknown personas {', '.join(unknown)}")
+            tenant_default = settings.get("default") or (allowed[0] if allowed else default)
+            if tenant_default not in personas or (allowed and tenant_default not in allowed):
+                raise PersonaError(f"{self.path}: tenant {tenant_id}: default persona {tenant_default!r} isn't one of its personas")
+            tenants[tenant_id] = _TenantPersonas(default=tenant_default, allowed=allowed)
+        return _PersonaSet(default=default, personas=personas, tenants=tenants)
@@ -0,0 +1,221 @@
+import re
+import threading
+import logging
+from collections import Counter
+from dataclasses import dataclass, field
+from difflib import SequenceMatcher
+from typing import Any, Dict, Iterable, List, Optional, Tuple
+
+from app.services.keyword_search import STOPWORDS
+
+logger = logging.getLogger(__name__)
+
+_VOWELS = set("AEIOU")
+_FRONT = set("EIY")
+_WORD = re.compile(r"[A-Za-z]+")
+
+# Longest run of spoken words tried as one entity, e.g. "un der arm our"
+MAX_WORDS = 3
+# How alike the heard and catalog spellings must be besides sounding alike
+BRAND_SIMILARITY = 0.6
+NAME_SIMILARITY = 0.75
+
+
+def metaphone(word: str) -> str:
+    """The Metaphone code of a word: letters that sound alike map alike, so
+    "nikey" and "nike" are both NK, and "addydas" and "adidas" both ATTS."""
+    w = "".join(c for c in word.upper() if c.isalpha())
+    if not w:
+        return ""
+    # Doubled letters sound single, except CC as in "accent"
+    w = "".join(c for i, c in enumerate(w) if i == 0 or c != w[i - 1] or c == "C")
+
+    if w[:2] in ("AE", "GN", "KN", "PN", "WR"):
+        w = w[1:]
+    elif w[0] == "X":
+        w = "S" + w[1:]
+    elif w[:2] == "WH":
+        w = "W" + w[2:]
+
+    def at(i: int) -> str:
+        return w[i] if 0 <= i < len(w) else ""
+
+    code = []
+    for i, c in enumerate(w):
+        prev, nxt, after = at(i - 1), at(i + 1), at(i + 2)
+        if c in _VOWELS:
+            if i == 0:
+                code.append(c)
+        elif c == "B":
+            if not (prev == "M" and i == len(w) - 1):
+                code.append("B")
+        elif c == "C":
+            if nxt == "I" and after == "A" or nxt == "H":
+                code.append("K" if prev == "S" else "X")
+            elif nxt in _FRONT:
+                if prev != "S":
+                    code.append("S")
+            else:
+                code.append("K")
+        elif c == "D":
+            code.append("J" if nxt == "G" and after in _FRONT else "T")
+        elif c == "G":
+            if nxt == "H" and after and after not in _VOWELS:
+                continue
+            if nxt == "N" and (i + 2 == len(w) or w[i + 2:] == "ED"):
+                continue
+            code.append("J" if nxt in _FRONT else "K")
+        elif c == "H":
+            if prev not in "CGPST" and (nxt in _VOWELS or not prev) and not (prev in _VOWELS and nxt not in _VOWELS):
+                code.append("H")
+        elif c == "K":
+            if prev != "C":
+                code.append("K")
+        elif c == "P":
+            code.append("F" if nxt == "H" else "P")
+        elif c == "Q":
+            code.append("K")
+        elif c == "S":
+            if nxt == "H" or (nxt == "I" and after in ("O", "A")):
+                code.append("X")
+            else:
+                code.append("S")
+        elif c == "T":
+            if nxt == "I" and after in ("O", "A"):
+                code.append("X")
+            elif nxt == "H":
+                code.append("0")
+            elif not (nxt == "C" and after == "H"):
+                code.append("T")
+        elif c == "V":
+            code.append("F")
+        elif c in "WY":
+            if nxt in _VOWELS:
+                code.append(c)
+        elif c == "X":
+            code.append("KS")
+        elif c == "Z":
+            code.append("S")
+        else:
+            code.append(c)
+    return "".join(code)
+
+
+def _key(text: str) -> str:
+    return "".join(_WORD.findall(text.lower()))
+
+
+@dataclass
+class _Entry:
+    text: str
+    brand: bool
+    count: int
+
+
+@dataclass
+class Rewrite:
+    text: str
+    # (heard, catalog) pairs, e.g. ("Addy das", "Adidas")
+    corrections: List[Tuple[str, str]] = field(default_factory=list)
+
+
+class CatalogVocabulary:
+    """Brand names and product-name words, indexed by how they sound.
+
+    Rewrites spoken queries so words the speech-to-text engine misspelled
+    ("Addy das", "Nikey") resolve to the catalog's spelling ("Adidas",
+    "Nike"). Shared by every request and reloaded from the catalog.
+    """
+
+    def __init__(self):
+        self._lock = threading.Lock()
+        self._known: Dict[str, _Entry] = {}
+        self._by_code: Dict[str, List[_Entry]] = {}
+
+    def load(self, products: Iterable[Dict[str, Any]]):
+        brands: Counter = Counter()
+        names: Counter = Counter()
+        spelling: Dict[str, str] = {}
+        for product in products:
+            brand = (product.get("brand") or "").strip()
+            if brand:
+                brands[_key(brand)] += 1
+                spelling.setdefault(_key(brand), brand)
+            for word in _WORD.findall(product.get("name") or ""):
+                if len(word) >= 4 and word.lower() not in STOPWORDS:
+                    names[word.lower()] += 1
+
+        known: Dict[str, _Entry] = {}
+        for key, count in names.items():
+            known[key] = _Entry(text=key, brand=False, count=count)
+        for key, count in brands.items():
+            known[key] = _Entry(text=spelling[key], brand=True, count=count)
+        by_code: Dict[str, List[_Entry]] = {}
+        for key, entry in known.items():
+            by_code.setdefault(metaphone(key), []).append(entry)
+
+        with self._lock:
+            self._known, self._by_code = known, by_code
+        logger.info(f"Loaded phonetic vocabulary: {len(brands)} brands, {len(names)} product-name words")
+
+    def __len__(self) -> int:
+        return len(self._known)
+
+    def rewrite(self, query: str) -> Rewrite:
+        """Replaces runs of words that sound like a catalog entry but aren't
+        spelled like one. Longer runs win, so "addy das" becomes "Adidas"
+        rather than two separate guesses."""
+        with self._lock:
+            known, by_code = self._known, self._by_code
+        if not known:
+            return Rewrite(text=query)
+
+        words = list(_WORD.finditer(query))
+        result = Rewrite(text=query)
+        replacements = []
+        i = 0
+        while i < len(words):
+            for n in range(min(MAX_WORDS, len(words) - i), 0, -1):
+                run = words[i:i + n]
+                # Only words written next to each other form a run
+                if any(query[a.end():b.start()].strip() for a, b in zip(run, run[1:])):
+                    continue
+                if any(w.group().lower() in STOPWORDS for w in run):
+                    continue
+                heard = query[run[0].start():run[-1].end()]
+                key = _key(heard)
+                if key in known:
+                    if n > 1 and known[key].text.lower() != heard.lower():
+                        replacements.append((run[0].start(), run[-1].end(), known[key].text))
+                        result.corrections.append((heard, known[key].text))
+                    break
+                match = self._match(key, n, by_code)
+                if match is not None:
+                    replacements.append((run[0].start(), run[-1].end(), match.text))
+                    result.corrections.append((heard, match.text))
+                    break
+            else:
+                n = 1
+            i += n
+
+        text = query
+        for start, end, replacement in reversed(replacements):
+            text = text[:start] + replacement + text[end:]
+        result.text = text
+        return result
+
+    def _match(self, key: str, words: int, by_code: Dict[str, List[_Entry]]) -> Optional[_Entry]:
+        if len(key) < 3:
+            return None
+        best, best_score = None, 0.0
+        for entry in by_code.get(metaphone(key), []):
+            # A run of several words only ever resolves to a brand
+            if words > 1 and not entry.brand:
+                continue
+            similarity = SequenceMatcher(None, key, _key(entry.text)).ratio()
+            if similarity < (BRAND_SIMILARITY if entry.brand else NAME_SIMILARITY):
+                continue
+            score = (entry.brand, similarity, entry.count)
+            if best is None or score > best_score:
+                best, best_score = entry, score
+        return best
@@ -0,0 +1,186 @@
+import json
+import os
+import re
+import threading
+import time
+import logging
+from dataclasses import dataclass, field
+from typing import Dict, Optional, Tuple
+
+logger = logging.getLogger(__name__)
+
+CONFIG_FILE = "prompts.json"
+
+# Variables each prompt is rendered with by the LLM service; templates may
+# also use the variables defined in the config
+PROMPT_VARIABLES = {
+    "cypher": {"query"},
+    "search_terms": {"query"},
+    "relevance": {"query", "product"},
+}
+
+# {{ name }}, so Cypher's own braces and $params need no escaping
+_VARIABLE = re.compile(r"\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}")
+
+
+class PromptError(Exception):
+    pass
+
+
+@dataclass
+class RenderedPrompt:
+    name: str
+    # Template file the prompt came from, relative to the prompts
+    # directory, e.g. "cypher/v2.txt"
+    source: str
+    text: str
+
+
+@dataclass
+class _Override:
+    prompts: Dict[str, str] = field(default_factory=dict)
+    variables: Dict[str, str] = field(default_factory=dict)
+
+
+@dataclass
+class _PromptSet:
+    default: _Override
+    tenants: Dict[str, _Override]
+    languages: Dict[str, _Override]
+    experiments: Dict[str, _Override]
+    templates: Dict[str, str]
+
+
+class PromptStore:
+    """Versioned prompt templates for the LLM service, read from a directory.
+
+    The directory holds prompts.json and the template files it names:
+
+        {
+          "variables": {"fulltext_index": "productSearch"},
+          "prompts": {"cypher": "cypher/v1.txt", ...},
+          "tenants": {"acme": {"prompts": {"cypher": "cypher/v2.txt"}}},
+          "languages": {"de": {"prompts": {"cypher": "cypher/de/v1.txt"}}},
+          "experiments": {"terse-terms": {"prompts": {"search_terms": "search_terms/v2.txt"}}}
+        }
+
+    A request's experiment overrides its query's language, which overrides
+    its tenant, which overrides the defaults, prompt by prompt and variable
+    by variable. The files are
+    reloaded when they change; a change that fails to load is logged and
+    the previous prompts are kept.
+    """
+
+    def __init__(self, directory: str, reload_interval: float = 5.0):
+        self.directory = directory
+        self.reload_interval = reload_interval
+        self._lock = threading.Lock()
+        self._checked_at = 0.0
+        self._signature = self._scan()
+        self._set = self._load()
+        logger.info(f"Loaded {len(self._set.templates)} prompt templates from {directory}")
+
+    def render(
+        self,
+        name: str,
+        variables: Dict[str, str],
+        tenant_id: Optional[str] = None,
+        experiment: Optional[str] = None,
+        language: Optional[str] = None,
+    ) -> RenderedPrompt:
+        """Renders the named prompt as the tenant and experiment see it, for
+        a query in language."""
+        prompts = self._current()
+        source = prompts.default.prompts.get(name)
+        values = dict(prompts.default.variables)
+        overrides = (
+            prompts.tenants.get(tenant_id or ""),
+            prompts.languages.get(language or ""),
+            prompts.experiments.get(experiment or ""),
+        )
+        for override in overrides:
+            if override is not None:
+                source = override.prompts.get(name, source)
+                values.update(override.variables)
+        if source is None:
+            raise PromptError(f"unknown prompt {name!r}")
+        values.update(variables)
+
+        def substitute(match):
+            key = match.group(1)
+            if key not in values:
+                raise PromptError(f"{source}: no value for {{{{{key}}}}}")
+            return str(values[key])
+
+        return RenderedPrompt(name=name, source=source, text=_VARIABLE.sub(substitute, prompts.templates[source]))
+
+    def _current(self) -> _PromptSet:
+        with self._lock:
+            now = time.monotonic()
+            if now - self._checked_at < self.reload_interval:
+                return self._set
+            self._checked_at = now
+
+            signature = self._scan()
+            if signature != self._signature:
+                self._signature = signature
+                try:
+                    self._set = self._load()
+                    logger.info(f"Reloaded prompt templates from {self.directory}")
+                except (OSError, ValueError, PromptError) as e:
+                    logger.error(f"Keeping previous prompts, reload failed: {e}")
+            return self._set
+
+    def _scan(self) -> Tuple[Tuple[str, float], ...]:
+        """Paths and modification times of every file in the directory."""
+        files = []
+        for root, _, names in os.walk(self.directory):
+            for name in names:
+                path = os.path.join(root, name)
+                try:
+                    files.append((path, os.stat(path).st_mtime))
+                except OSError:
+                    pass
+        return tuple(sorted(files))
+
+    def _load(self) -> _PromptSet:
+        with open(os.path.join(self.directory, CONFIG_FILE)) as f:
+            config = json.load(f)
+
+        default = _override(config, "defaults")
+        missing = set(PROMPT_VARIABLES) - set(default.prompts)
+        if missing:
+            raise PromptError(f"{CONFIG_FILE}: no default for {', '.join(sorted(missing))}")
+        tenants = {tenant: _override(o, f"tenant {tenant}") for tenant, o in config.get("tenants", {}).items()}
+        languages = {lang: _override(o, f"language {lang}") for lang, o in config.get("languages", {}).items()}
+        experiments = {exp: _override(o, f"experiment {exp}") for exp, o in config.get("experiments", {}).items()}
+
+        templates: Dict[str, str] = {}
+        for scope in [default, *tenants.values(), *languages.values(), *experiments.values()]:
+            variables = {**default.variables, **scope.variables}
+            for name, source in scope.prompts.items():
+                if name not in PROMPT_VARIABLES:
+                    raise PromptError(f"{CONFIG_FILE}: unknown prompt {name!r}")
+                if source not in templates:
+                    templates[source] = self._read(source)
+                for key in _VARIABLE.findall(templates[source]):
+                    if key not in PROMPT_VARIABLES[name] and key not in variables:
+                        raise PromptError(f"{source}: unknown variable {{{{{key}}}}} in the {name} prompt")
+        return _PromptSet(
+            default=default, tenants=tenants, languages=languages, experiments=experiments, templates=templates
+        )
+
+    def _read(self, source: str) -> str:
+        path = os.path.abspath(os.path.join(self.directory, source))
+        if os.path.commonpath([path, os.path.abspath(self.directory)]) != os.path.abspath(self.directory):
+            raise PromptError(f"{source}: outside the prompts directory")
+        with open(path) as f:
+            return f.read().rstrip("\n")
+
+
+def _override(config: dict, what: str) -> _Override:
+    prompts = config.get("prompts", {})
+    variables = config.get("variables", {})
+    if not isinstance(prompts, dict) or not isinstance(variables, dict):
+        raise PromptError(f"{CONFIG_FILE}: {what}: prompts and variables must be objects")
+    return _Override(prompts=prompts, variables={k: str(v) for k, v in variables.items()})
@@ -1,4 +1,4 @@
-from typing import List, Dict, Any
+from typing import AsyncIterator, List, Dict, Any
 import logging
 from collections import defaultdict
 import asyncio
@@ -26,6 +26,26 @@ class RecommendationService:
         limit: int = 10
     ) -> List[Dict[str, Any]]:
         """Combine results from both services using LLM scoring."""
+        ranked: List[Dict[str, Any]] = []
+        async for ranked in self.rank_incrementally(semantic_results, graph_results, query, llm_service):
+            pass
+        return ranked[:limit]
+    
+    async def rank_incrementally(
+        self,
+        semantic_results: List[Dict[str, Any]],
+        graph_results: List[Dict[str, Any]],
+        query: str,
+        llm_service,
+        first_batch: int = 0
+    ) -> AsyncIterator[List[Dict[str, Any]]]:
+        """Rank the combined results, yielding the ranking so far after each batch.
+        
+        With first_batch set, the first_batch most promising candidates (found
+        by both services, then by semantic score) are scored and ranked first,
+        then the rest; otherwise everything is ranked at once. Each ranking
+        covers only the candidates scored so far.
+        """
         # Use regular dict instead of defaultdict to avoid type issues
         product_scores: Dict[str, Dict[str, Any]] = {}
         
@@ -61,60 +81,68 @@ class RecommendationService:
                 else:
                     entry["data"].update(result)
         
-        # Score products with LLM (Ollama - no rate limits)
-        scored_products = []
-        product_ids = list(product_scores.keys())
+        product_ids = sorted(
+            product_scores,
+            key=lambda pid: (len(product_scores[pid]["sources"]), product_scores[pid]["semantic_score"]),
+            reverse=True
+        )
+        batches = [product_ids]
+        if 0 < first_batch < len(product_ids):
+            batches = [product_ids[:first_batch], product_ids[first_batch:]]
         
-        for product_id in product_ids:
-            scores = product_scores[product_id]
-            
-            try:
-                # Score with LLM (no delays needed for Ollama)
-                graph_score_value = await llm_service.score_relevance(scores["data"], query)
-            except Exception as e:
-                logger.warning(f"LLM scoring failed for {product_id}: {e}")
-                graph_score_value = 0.5  # Default score
-            
-            scores["graph_score"] = graph_score_value
-            
-            # Calculate combined score
-            combined_score = self._calculate_combined_score(
-                float(scores["semantic_score"]),
-                graph_score_value,
-                scores["sources"]
-            )
+        # Score products with LLM (Ollama - no rate limits), a batch at a time
+        scored_products = []
+        for batch in batches:
+            graph_scores = await asyncio.gather(*[
+                self._score(product_id, product_scores[product_id]["data"], query, llm_service)
+                for product_id in batch
+            ])
+            for product_id, graph_score_value in zip(batch, graph_scores):
+                scores = product_scores[product_id]
+                scores["graph_score"] = graph_score_value
+                
+                # Calculate combined score
+                combined_score = self._calculate_combined_score(
+                    float(scores["semantic_score"]),
+                    graph_score_value,
+                    scores["sources"]
+                )
+                
+                scored_products.append({
+                    "product_id": product_id,
+                    "semantic_score": scores["semantic_score"],
+                    "graph_score": graph_score_value,
+                    "combined_score": combined_score,
+                    "data": scores["data"],
+                    "sources": list(scores["sources"])
+                })
             
-            scored_products.append({
-                "product_id": product_id,
-                "semantic_score": scores["semantic_score"],
-                "graph_score": graph_score_value,
-                "combined_score": combined_score,
-                "data": scores["data"],
-                "sources": list(scores["sources"])
-            })
-        
-        # Sort by combined score
-        scored_products.sort(key=lambda x: x["combined_score"], reverse=True)
-        
-        # Build final results
-        final_results = []
-        for item in scored_products[:limit]:
-            result = {
-                "product_id": item["product_id"],
-                "name": item["data"].get("name", ""),
-                "brand": item["data"].get("brand", ""),
-                "price": item["data"].get("price", 0.0),
-                "semantic_score": round(item["semantic_score"], 4),
-                "graph_score": round(item["graph_score"], 4),
-                "combined_score": round(item["combined_score"], 4),
-                "description": item["data"].get("description", ""),
-                "color": item["data"].get("color", ""),
-                "category": item["data"].get("category"),
-                "sources": item["sources"]
-            }
-            final_results.append(result)
-        
-        return final_results
+            # Sort by combined score
+            scored_products.sort(key=lambda x: x["combined_score"], reverse=True)
+            yield [self._result(item) for item in scored_products]
+    
+    async def _score(self, product_id: str, product: Dict[str, Any], query: str, llm_service) -> float:
+        try:
+            # Score with LLM (no delays needed for Ollama)
+            return await llm_service.score_relevance(product, query)
+        except Exception as e:
+            logger.warning(f"LLM scoring failed for {product_id}: {e}")
+            return 0.5  # Default score
+    
+    def _result(self, item: Dict[str, Any]) -> Dict[str, Any]:
+        return {
+            "product_id": item["product_id"],
+            "name": item["data"].get("name", ""),
+            "brand": item["data"].get("brand", ""),
+            "price": item["data"].get("price", 0.0),
+            "semantic_score": round(item["semantic_score"], 4),
+            "graph_score": round(item["graph_score"], 4),
+            "combined_score": round(item["combined_score"], 4),
+            "description": item["data"].get("description", ""),
+            "color": item["data"].get("color", ""),
+            "category": item["data"].get("category"),
+            "sources": item["sources"]
+        }
     
     def _calculate_combined_score(
         self,
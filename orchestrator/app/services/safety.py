@@ -0,0 +1,219 @@
+import importlib
+import json
+import logging
+import os
+import re
+import threading
+import time
+from dataclasses import dataclass, field
+from typing import List, Optional, Pattern
+
+import httpx
+
+logger = logging.getLogger(__name__)
+
+
+@dataclass
+class Verdict:
+    """What screening found in a text.
+
+    flagged: the text mustn't be used; categories says why
+    text: the text with masked words taken out
+    """
+    flagged: bool
+    text: str
+    categories: List[str] = field(default_factory=list)
+
+
+class UnsafeContentError(Exception):
+    """Generated text that failed screening."""
+
+
+class ModerationProvider:
+    """Classifies text as unsafe. Subclass it to plug in a moderation
+    service; SafetyFilter takes any of them."""
+
+    async def moderate(self, text: str) -> List[str]:
+        """The categories the text falls foul of; empty when it's fine."""
+        raise NotImplementedError
+
+    async def close(self):
+        pass
+
+
+class HTTPModerationProvider(ModerationProvider):
+    """A moderation service that takes POST {"text"} and answers
+    {"flagged": bool, "categories": [...]}."""
+
+    def __init__(self, url: str, timeout: float = 2.0):
+        self.url = url
+        self.client = httpx.AsyncClient(timeout=timeout)
+
+    async def moderate(self, text: str) -> List[str]:
+        response = await self.client.post(self.url, json={"text": text})
+        response.raise_for_status()
+        result = response.json()
+        if not result.get("flagged"):
+            return []
+        return list(result.get("categories") or ["flagged"])
+
+    async def close(self):
+        await self.client.aclose()
+
+
+def load_provider(spec: Optional[str], url: Optional[str] = None, timeout: float = 2.0) -> Optional[ModerationProvider]:
+    """The moderation provider spec names: "http" for url, or the import
+    path of a ModerationProvider subclass, "package.module:Class", made
+    with no arguments. None without either."""
+    if not spec:
+        spec = "http" if url else None
+    if spec is None:
+        return None
+    if spec == "http":
+        if not url:
+            raise ValueError("the http moderation provider needs a URL")
+        return HTTPModerationProvider(url, timeout=timeout)
+    module, _, name = spec.partition(":")
+    provider = getattr(importlib.import_module(module), name)()
+    if not isinstance(provider, ModerationProvider):
+        raise ValueError(f"{spec} isn't a ModerationProvider")
+    return provider
+
+
+@dataclass
+class _WordLists:
+    # Texts with these words are refused
+    block: Optional[Pattern]
+    # These words are taken out of what users say and what is spoken, and
+    # make generated queries unusable
+    mask: Optional[Pattern]
+
+
+class SafetyFilter:
+    """Screens what users say, what the LLM writes and what is spoken.
+
+    Word lists come from a JSON file, reloaded when it changes:
+
+        {"block": ["some phrase"], "mask": ["damn"]}
+
+    Words match whole and case-insensitively; phrases match across any
+    spacing. The provider, when set, screens transcripts and generated
+    text too. A provider that fails is logged and only the word lists
+    apply, unless fail_closed, when the text is flagged.
+    """
+
+    def __init__(
+        self,
+        path: Optional[str] = None,
+        provider: Optional[ModerationProvider] = None,
+        fail_closed: bool = False,
+        reload_interval: float = 5.0,
+    ):
+        self.path = path
+        self.provider = provider
+        self.fail_closed = fail_closed
+        self.reload_interval = reload_interval
+        self._lock = threading.Lock()
+        self._checked_at = 0.0
+        self._mtime = self._stat()
+        self._lists = self._load()
+
+    async def screen_transcript(self, text: str) -> Verdict:
+        """Blocked words or a provider flag refuse the text; masked words
+        are taken out."""
+        lists = self._current()
+        if lists.block is not None and lists.block.search(text):
+            return Verdict(flagged=True, text=text, categories=["blocked_word"])
+        categories = await self._moderate(text)
+        return Verdict(flagged=bool(categories), text=_mask(lists.mask, text), categories=categories)
+
+    async def check_generated(self, *texts: str):
+        """Raises UnsafeContentError if generated text has any listed word
+        or the provider flags it."""
+        lists = self._current()
+        combined = "\n".join(t for t in texts if t)
+        for name, pattern in (("blocked_word", lists.block), ("masked_word", lists.mask)):
+            if pattern is not None and pattern.search(combined):
+                raise UnsafeContentError(f"unsafe LLM output: {name}")
+        categories = await self._moderate(combined)
+        if categories:
+            raise UnsafeContentError(f"unsafe LLM output: {', '.join(categories)}")
+
+    def clean_speech(self, text: str) -> Optional[str]:
+        """The text to speak with masked words taken out, or None if it
+        has a blocked word and mustn't be spoken."""
+        lists = self._current()
+        if lists.block is not None and lists.block.search(text):
+            return None
+        return _mask(lists.mask, text)
+
+    async def close(self):
+        if self.provider is not None:
+            await self.provider.close()
+
+    async def _moderate(self, text: str) -> List[str]:
+        if self.provider is None or not text.strip():
+            return []
+        try:
+            return await self.provider.moderate(text)
+        except Exception as e:
+            logger.error(f"Moderation failed: {e}")
+            return ["moderation_unavailable"] if self.fail_closed else []
+
+    def _current(self) -> _WordLists:
+        with self._lock:
+            now = time.monotonic()
+            if self.path is None or now - self._checked_at < self.reload_interval:
+                return self._lists
+            self._checked_at = now
+
+            mtime = self._stat()
+            if mtime != self._mtime:
+                self._mtime = mtime
+                try:
+                    self._lists = self._load()
+                    logger.info(f"Reloaded safety word lists from {self.path}")
+                except (OSError, ValueError) as e:
+                    logger.error(f"Keeping previous safety word lists, reload failed: {e}")
+            return self._lists
+
+    def _stat(self) -> Optional[float]:
+        if self.path is None:
+            return None
+        try:
+            return os.stat(self.path).st_mtime
+        except OSError:
+            return None
+
+    def _load(self) -> _WordLists:
+        if self.path is None:
+            return _WordLists(block=None, mask=None)
+        with open(self.path) as f:
+            config = json.load(f)
+        lists = _WordLists(block=_pattern(config.get("block", [])), mask=_pattern(config.get("mask", [])))
+        logger.info(
+            f"Loaded safety word lists: {len(config.get('block', []))} blocked, {len(config.get('mask', []))} masked"
+        )
+        return lists
+
+
+def _pattern(words: List[str]) -> Optional[Pattern]:
+    if not isinstance(words, list) or not all(isinstance(w, str) for w in words):
+        raise ValueError("word lists must be lists of strings")
+    phrases = sorted({w.strip().lower() for w in words if w.strip()}, key=len, reverse=True)
+    if not phrases:
+        return None
+    alternatives = "|".join(r"\s+".join(re.escape(part) for part in phrase.split()) for phrase in phrases)
+    return re.compile(rf"(?<!\w)(?:{alternatives})(?!\w)", re.IGNORECASE)
+
+
+def _mask(pattern: Optional[Pattern], text: str) -> str:
+    """Takes the pattern's words out, and the commas they leave dangling."""
+    if pattern is None:
+        return text
+    masked = pattern.sub("", text)
+    if masked == text:
+        return text
+    masked = re.sub(r"\s+([,.!?;:])", r"\1", " ".join(masked.split()))
+    masked = re.sub(r",+([,.!?;:])", r"\1", masked)
+    return masked.lstrip(",;: ")
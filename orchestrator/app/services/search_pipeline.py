@@ -0,0 +1,471 @@
+import asyncio
+import base64
+import logging
+import time
+from dataclasses import dataclass
+from typing import Any, AsyncIterator, Dict, List, Optional
+
+from app.clients.graph_client import GraphServiceClient
+from app.clients.semantic_client import SemanticEngineClient
+from app.models.schemas import (
+    ProductQueryRequest, ProductQueryResponse, RecommendationResult, SearchRefinement, SearchStreamEvent,
+    SpokenFilters, VoicePersona
+)
+from app.services.fallback_monitor import FallbackMonitor
+from app.services.keyword_search import KeywordScorer, analyze
+from app.services.language import DEFAULT_LANGUAGE, detect_language, resolve_language
+from app.services.llm_service import LLMService, LLMUnavailableError
+from app.services.phonetic import CatalogVocabulary
+from app.services.prompt_store import PromptError
+from app.services.recommendation_service import RecommendationService
+from app.services.safety import SafetyFilter, UnsafeContentError
+from app.services.shadow import ShadowSearch
+from app.services.session_cache import (
+    CachedSearch, SessionCache, apply_refinement, describe_refinement, refetch_reason
+)
+from app.services.spoken_numbers import SpokenQuery, normalize
+from app.services.tts import TTSClient, describe_results, phrase, to_ssml
+from app.tracing import QUERY_HASH, RESULT_COUNT, SESSION_ID, annotate, hash_text
+
+logger = logging.getLogger(__name__)
+
+
+@dataclass
+class Retrieval:
+    """Candidates for a base search, before ranking."""
+    query: str
+    cypher_query: str
+    search_terms: str
+    semantic_results: List[Dict[str, Any]]
+    graph_results: List[Dict[str, Any]]
+    semantic_limit: int
+    search_path: str
+    fallback_reason: Optional[str]
+    rewritten_query: Optional[str]
+    # Scores relevance: the LLM, or keyword overlap on the keyword path
+    scorer: Any
+
+
+class SearchPipeline:
+    """One search request: cache lookup, query generation, retrieval and ranking."""
+
+    def __init__(
+        self,
+        semantic_client: SemanticEngineClient,
+        graph_client: GraphServiceClient,
+        llm_service: LLMService,
+        recommendation_service: RecommendationService,
+        session_cache: SessionCache,
+        fallback_monitor: FallbackMonitor,
+        vocabulary: Optional[CatalogVocabulary] = None,
+        safety: Optional[SafetyFilter] = None,
+        shadow: Optional[ShadowSearch] = None,
+    ):
+        self.semantic_client = semantic_client
+        self.graph_client = graph_client
+        self.llm_service = llm_service
+        self.recommendation_service = recommendation_service
+        self.session_cache = session_cache
+        self.fallback_monitor = fallback_monitor
+        self.vocabulary = vocabulary
+        self.safety = safety
+        self.shadow = shadow
+        # The request's query with spoken numbers written out, and the
+        # language and locale it's handled in; set by start
+        self.spoken: Optional[SpokenQuery] = None
+        self.language = DEFAULT_LANGUAGE
+        self.locale: Optional[str] = None
+        # Products by id in the locale, or None when they have no
+        # translation in it
+        self._localized: Dict[str, Optional[Dict[str, Any]]] = {}
+
+    def start(self, request: ProductQueryRequest) -> SearchRefinement:
+        logger.info(f"Processing search query: {request.query}")
+        annotate({SESSION_ID: request.session_id, QUERY_HASH: hash_text(request.query)})
+        self.graph_client.session_id = request.session_id
+        self.llm_service.tenant_id = request.tenant_id
+        self.llm_service.experiment = request.experiment
+        # A query that gives no language away, like "Nike Air Max", keeps
+        # the one the session was speaking
+        locale = request.locale
+        if not locale and request.session_id and detect_language(request.query) is None:
+            cached = self.session_cache.get(request.session_id)
+            locale = cached.locale if cached is not None else None
+        self.language, self.locale = resolve_language(request.query, locale)
+        self.llm_service.language = self.language
+        annotate({"query.language": self.language})
+        # Spoken prices and sizes filter the results, unless the client
+        # set those filters itself
+        self.spoken = normalize(request.query, self.locale)
+        return self.spoken.refine(request.refine or SearchRefinement())
+
+    async def screen(self, request: ProductQueryRequest) -> Optional[ProductQueryResponse]:
+        """Screens what the user said: a refusal if it's blocked. Masked
+        words are left out of the search."""
+        if self.safety is None:
+            return None
+        verdict = await self.safety.screen_transcript(self.spoken.text)
+        if verdict.flagged:
+            logger.warning(f"Refused query for session {request.session_id}: {', '.join(verdict.categories)}")
+            annotate({"safety.blocked": True})
+            return ProductQueryResponse(
+                query=request.query,
+                semantic_results_count=0,
+                graph_results_count=0,
+                recommendations=[],
+                language=self.language,
+                blocked=verdict.categories
+            )
+        self.spoken.text = verdict.text
+        return None
+
+    def _speakable(self, text: str) -> str:
+        """Speech with masked words taken out, or a neutral line instead of
+        one with blocked words."""
+        if self.safety is None:
+            return text
+        cleaned = self.safety.clean_speech(text)
+        return cleaned if cleaned is not None else phrase("neutral", self.language)
+
+    def _spoken_filters(self) -> Optional[SpokenFilters]:
+        filters = self.spoken.filters
+        return filters if filters.model_dump(exclude_none=True) else None
+
+    def cached(self, request: ProductQueryRequest, refinement: SearchRefinement) -> Optional[ProductQueryResponse]:
+        """Answers refinements of the session's last search locally."""
+        if not request.session_id:
+            return None
+        cached = self.session_cache.get(request.session_id)
+        reason = refetch_reason(cached, request.query, refinement, request.limit)
+        if reason is not None:
+            logger.info(f"Searching for session {request.session_id}: {reason}")
+            return None
+
+        recommendations = apply_refinement(cached.results, refinement, refinement.offset, request.limit)
+        logger.info(f"Refined cached search for session {request.session_id}: {len(recommendations)} results")
+        annotate({RESULT_COUNT: len(recommendations), "cache.hit": True})
+        return ProductQueryResponse(
+            query=request.query,
+            cypher_query=cached.cypher_query,
+            search_terms=cached.search_terms,
+            semantic_results_count=cached.semantic_results_count,
+            graph_results_count=cached.graph_results_count,
+            recommendations=recommendations,
+            from_cache=True,
+            search_path=cached.search_path,
+            fallback_reason=cached.fallback_reason,
+            rewritten_query=cached.rewritten_query,
+            spoken_filters=self._spoken_filters(),
+            language=self.language
+        )
+
+    async def retrieve(self, request: ProductQueryRequest, refinement: SearchRefinement) -> Retrieval:
+        # Brand and product names misheard by speech-to-text are respelled
+        # the way the catalog spells them
+        query = self.spoken.text
+        if self.vocabulary is not None:
+            rewrite = self.vocabulary.rewrite(query)
+            if rewrite.corrections:
+                logger.info(f"Rewrote query: {rewrite.corrections}")
+                query = rewrite.text
+        rewritten_query = query if query != request.query else None
+        annotate({"query.rewritten": rewritten_query is not None})
+
+        # Filters the cache can't serve are folded into the query itself.
+        # Spoken ones are in it already.
+        query = describe_refinement(query, request.refine or SearchRefinement())
+
+        # Step 1: Generate both queries in parallel via LLM, or analyze the
+        # query into a fulltext search when the LLM is unavailable
+        search_path, fallback_reason, scorer = "llm", None, self.llm_service
+        try:
+            cypher_query, search_terms = await asyncio.gather(
+                self.llm_service.generate_cypher(query),
+                self.llm_service.generate_search_terms(query)
+            )
+            # Generated text is screened before it's run
+            if self.safety is not None:
+                await self.safety.check_generated(cypher_query, search_terms)
+        except (LLMUnavailableError, PromptError, UnsafeContentError) as e:
+            logger.warning(f"LLM output unusable, using the keyword path: {e}")
+            keywords = analyze(query)
+            cypher_query, search_terms = keywords.cypher(), keywords.text or query
+            search_path, fallback_reason, scorer = "keyword", str(e), KeywordScorer()
+        self.fallback_monitor.record(search_path == "keyword")
+        annotate({"search.path": search_path})
+        logger.info(f"Generated Cypher: {cypher_query}")
+        logger.info(f"Generated search terms: {search_terms}")
+
+        # Step 2: Execute searches (semantic is async, graph is sync)
+        semantic_limit = (request.limit + refinement.offset) * 2
+        semantic_results = await self.semantic_client.search(
+            query=search_terms,
+            limit=semantic_limit,
+            min_score=request.min_semantic_score
+        )
+
+        started = time.monotonic()
+        graph_results = await self._search_graph(cypher_query)
+        if self.shadow is not None:
+            # Try the structured path on the same terms, off the request
+            self.shadow.submit(
+                search_terms,
+                graph_results,
+                time.monotonic() - started,
+                locale=self.locale or "",
+                limit=max(len(graph_results), request.limit),
+                api_key=self.graph_client.api_key,
+                tenant_id=self.graph_client.tenant_id,
+                context={"search_path": search_path, "session_id": request.session_id},
+            )
+        for product in graph_results:
+            self._localized[product["id"]] = product if product.get("locale") else None
+
+        logger.info(f"Semantic search returned {len(semantic_results)} results")
+        logger.info(f"Graph search returned {len(graph_results)} results")
+
+        return Retrieval(
+            query=query,
+            cypher_query=cypher_query,
+            search_terms=search_terms,
+            semantic_results=semantic_results,
+            graph_results=graph_results,
+            semantic_limit=semantic_limit,
+            search_path=search_path,
+            fallback_reason=fallback_reason,
+            rewritten_query=rewritten_query,
+            scorer=scorer
+        )
+
+    async def _search_graph(self, cypher_query: str) -> List[Dict[str, Any]]:
+        """Runs the blocking graph search off the event loop, cancelling the
+        graph-service call if the search is cancelled."""
+        try:
+            return await asyncio.to_thread(self.graph_client.search_products, cypher_query, self.locale or "")
+        except asyncio.CancelledError:
+            self.graph_client.cancel()
+            raise
+
+    async def _localize(self, recommendations: List[Dict[str, Any]]) -> List[Dict[str, Any]]:
+        """Swaps in product names and descriptions in the request's locale.
+
+        Semantic results are in the catalog's default language; products
+        the graph search didn't return are looked up one by one.
+        """
+        if self.language == DEFAULT_LANGUAGE or not recommendations:
+            return recommendations
+        missing = [r["product_id"] for r in recommendations if r["product_id"] not in self._localized]
+        if missing:
+            try:
+                products = await asyncio.gather(*(
+                    asyncio.to_thread(self.graph_client.get_product, product_id, self.locale)
+                    for product_id in missing
+                ))
+            except asyncio.CancelledError:
+                self.graph_client.cancel()
+                raise
+            for product_id, product in zip(missing, products):
+                self._localized[product_id] = product if product and product.get("locale") else None
+
+        localized = []
+        for r in recommendations:
+            product = self._localized.get(r["product_id"])
+            if product is not None:
+                r = {**r, "name": product["name"], "description": product["description"]}
+            localized.append(r)
+        return localized
+
+    async def _localize_response(self, response: ProductQueryResponse) -> ProductQueryResponse:
+        if self.language == DEFAULT_LANGUAGE:
+            return response
+        recommendations = await self._localize([r.model_dump() for r in response.recommendations])
+        return response.model_copy(update={"recommendations": [RecommendationResult(**r) for r in recommendations]})
+
+    def finish(
+        self,
+        request: ProductQueryRequest,
+        refinement: SearchRefinement,
+        retrieval: Retrieval,
+        scored: List[Dict[str, Any]],
+    ) -> ProductQueryResponse:
+        """Pages the ranked results and caches them for the session."""
+        recommendations = apply_refinement(scored, refinement, refinement.offset, request.limit)
+        logger.info(f"Generated {len(recommendations)} recommendations")
+        annotate({RESULT_COUNT: len(recommendations), "cache.hit": False})
+
+        if request.session_id:
+            self.session_cache.put(request.session_id, CachedSearch(
+                query=request.query,
+                cypher_query=retrieval.cypher_query,
+                search_terms=retrieval.search_terms,
+                semantic_results_count=len(retrieval.semantic_results),
+                graph_results_count=len(retrieval.graph_results),
+                results=scored,
+                refinement=refinement,
+                complete=len(retrieval.semantic_results) < retrieval.semantic_limit,
+                search_path=retrieval.search_path,
+                fallback_reason=retrieval.fallback_reason,
+                rewritten_query=retrieval.rewritten_query,
+                locale=self.locale
+            ))
+
+        return ProductQueryResponse(
+            query=request.query,
+            cypher_query=retrieval.cypher_query,
+            search_terms=retrieval.search_terms,
+            semantic_results_count=len(retrieval.semantic_results),
+            graph_results_count=len(retrieval.graph_results),
+            recommendations=recommendations,
+            search_path=retrieval.search_path,
+            fallback_reason=retrieval.fallback_reason,
+            rewritten_query=retrieval.rewritten_query,
+            spoken_filters=self._spoken_filters(),
+            language=self.language
+        )
+
+    async def search(self, request: ProductQueryRequest) -> ProductQueryResponse:
+        refinement = self.start(request)
+        refusal = await self.screen(request)
+        if refusal is not None:
+            return refusal
+        response = self.cached(request, refinement)
+        if response is not None:
+            return await self._localize_response(response)
+
+        retrieval = await self.retrieve(request, refinement)
+
+        # Step 3: Combine results with LLM scoring. Every candidate is
+        # scored anyway, so keep them all for later refinements.
+        scored = await self.recommendation_service.combine_results(
+            semantic_results=retrieval.semantic_results,
+            graph_results=retrieval.graph_results,
+            query=retrieval.query,
+            llm_service=retrieval.scorer,
+            limit=len(retrieval.semantic_results) + len(retrieval.graph_results)
+        )
+        return await self._localize_response(self.finish(request, refinement, retrieval, scored))
+
+    async def stream(
+        self,
+        request: ProductQueryRequest,
+        tts: Optional[TTSClient] = None,
+        first_batch: int = 5,
+        interrupted: Optional[asyncio.Event] = None,
+        persona: Optional[VoicePersona] = None,
+    ) -> AsyncIterator[SearchStreamEvent]:
+        """Streams a search for voice clients.
+
+        The top results come as soon as the first_batch most promising
+        candidates are ranked, then again once all of them are. Speech for
+        the first results is synthesized meanwhile, in the persona's voice,
+        so its audio chunks are interleaved with the later results. A done event with the full
+        response ends the stream, or an error event. A query the safety filter
+        refuses gets a spoken refusal instead of results.
+
+        Setting interrupted, or closing the stream, cancels the work in
+        flight: LLM requests, the graph-service call and speech synthesis.
+        An interrupted stream ends with a cancelled event.
+        """
+        events: asyncio.Queue = asyncio.Queue()
+
+        async def speak(recommendations: List[Dict[str, Any]], text: Optional[str] = None):
+            text = self._speakable(text or describe_results(recommendations, self.language))
+            ssml = None
+            if persona is not None and persona.ssml:
+                brands = [r.get("brand") for r in recommendations]
+                ssml = to_ssml(text, brands, persona.price_pause_ms, self.locale)
+            await events.put(SearchStreamEvent(type="speech", text=text, ssml=ssml))
+            if tts is None:
+                return
+            try:
+                seq = 0
+                async for chunk in tts.synthesize(text, persona=persona, ssml=ssml, language=self.language):
+                    await events.put(SearchStreamEvent(
+                        type="audio",
+                        seq=seq,
+                        audio=base64.b64encode(chunk).decode("ascii"),
+                        audio_format=tts.audio_format
+                    ))
+                    seq += 1
+            except Exception as e:
+                # The results are still worth having without the audio
+                logger.error(f"Speech synthesis failed: {e}")
+
+        async def produce():
+            speech = None
+            try:
+                refinement = self.start(request)
+                refusal = await self.screen(request)
+                response = refusal or self.cached(request, refinement)
+                if refusal is not None:
+                    # Said so, without searching
+                    speech = asyncio.create_task(speak([], phrase("refusal", self.language)))
+                elif response is None:
+                    retrieval = await self.retrieve(request, refinement)
+                    scored: List[Dict[str, Any]] = []
+                    async for scored in self.recommendation_service.rank_incrementally(
+                        semantic_results=retrieval.semantic_results,
+                        graph_results=retrieval.graph_results,
+                        query=retrieval.query,
+                        llm_service=retrieval.scorer,
+                        first_batch=first_batch
+                    ):
+                        recommendations = await self._localize(
+                            apply_refinement(scored, refinement, refinement.offset, request.limit)
+                        )
+                        await events.put(SearchStreamEvent(
+                            type="results",
+                            stage="first" if speech is None else "refined",
+                            recommendations=recommendations
+                        ))
+                        if speech is None:
+                            speech = asyncio.create_task(speak(recommendations))
+                    response = await self._localize_response(self.finish(request, refinement, retrieval, scored))
+                else:
+                    response = await self._localize_response(response)
+                    await events.put(SearchStreamEvent(
+                        type="results", stage="first", recommendations=response.recommendations
+                    ))
+                    speech = asyncio.create_task(speak([r.model_dump() for r in response.recommendations]))
+
+                if speech is not None:
+                    await speech
+                await events.put(SearchStreamEvent(type="done", response=response))
+            except Exception as e:
+                logger.error(f"Streaming search failed: {e}")
+                await events.put(SearchStreamEvent(type="error", detail=f"Search failed: {e}"))
+            finally:
+                if speech is not None:
+                    await _cancel(speech)
+                await events.put(None)
+
+        async def watch():
+            await interrupted.wait()
+            producer.cancel()
+
+        producer = asyncio.create_task(produce())
+        watcher = asyncio.create_task(watch()) if interrupted is not None else None
+        try:
+            while True:
+                event = await events.get()
+                if event is None or (interrupted is not None and interrupted.is_set()):
+                    break
+                yield event
+            if interrupted is not None and interrupted.is_set():
+                logger.info(f"Search for session {request.session_id} interrupted")
+                yield SearchStreamEvent(type="cancelled")
+        finally:
+            await _cancel(producer)
+            if watcher is not None:
+                await _cancel(watcher)
+
+    async def close(self):
+        await self.semantic_client.close()
+        self.graph_client.close()
+        await self.llm_service.close()
+
+
+async def _cancel(task: asyncio.Task):
+    """Cancels the task and waits for it to finish cleaning up."""
+    task.cancel()
+    await asyncio.gather(task, return_exceptions=True)
@@ -0,0 +1,148 @@
+import time
+import threading
+import logging
+from collections import OrderedDict
+from dataclasses import dataclass, field
+from typing import Any, Dict, List, Optional
+
+from app.models.schemas import SearchRefinement
+
+logger = logging.getLogger(__name__)
+
+
+@dataclass
+class CachedSearch:
+    """The full scored result set of a session's last base search."""
+    query: str
+    cypher_query: Optional[str]
+    search_terms: Optional[str]
+    semantic_results_count: int
+    graph_results_count: int
+    results: List[Dict[str, Any]]
+    # Filters the base search was run with; the cached set only covers
+    # refinements at least this narrow
+    refinement: SearchRefinement = field(default_factory=SearchRefinement)
+    # False when a backend hit its result cap, so more matches may exist
+    complete: bool = True
+    search_path: str = "llm"
+    fallback_reason: Optional[str] = None
+    rewritten_query: Optional[str] = None
+    # Locale the search was handled in
+    locale: Optional[str] = None
+    created_at: float = field(default_factory=time.monotonic)
+
+
+class SessionCache:
+    """In-memory LRU of the last search per voice session, with a TTL."""
+
+    def __init__(self, ttl: float = 600.0, max_sessions: int = 1000):
+        self.ttl = ttl
+        self.max_sessions = max_sessions
+        self._lock = threading.Lock()
+        self._entries: "OrderedDict[str, CachedSearch]" = OrderedDict()
+
+    def get(self, session_id: str) -> Optional[CachedSearch]:
+        with self._lock:
+            entry = self._entries.get(session_id)
+            if entry is None:
+                return None
+            if time.monotonic() - entry.created_at > self.ttl:
+                del self._entries[session_id]
+                return None
+            self._entries.move_to_end(session_id)
+            return entry
+
+    def put(self, session_id: str, entry: CachedSearch):
+        with self._lock:
+            self._entries[session_id] = entry
+            self._entries.move_to_end(session_id)
+            while len(self._entries) > self.max_sessions:
+                self._entries.popitem(last=False)
+
+
+def normalize_query(query: str) -> str:
+    return " ".join(query.lower().split())
+
+
+def _narrows(new: SearchRefinement, base: SearchRefinement) -> bool:
+    """Reports whether every product matching new also matches base."""
+    for name in ("brand", "color", "main_category", "subcategory"):
+        base_value = getattr(base, name)
+        if base_value and (getattr(new, name) or "").lower() != base_value.lower():
+            return False
+    if base.min_price is not None and (new.min_price is None or new.min_price < base.min_price):
+        return False
+    if base.max_price is not None and (new.max_price is None or new.max_price > base.max_price):
+        return False
+    if base.size and (new.size or "").upper() != base.size.upper():
+        return False
+    return True
+
+
+def refetch_reason(entry: Optional[CachedSearch], query: str, refinement: SearchRefinement, limit: int) -> Optional[str]:
+    """Returns why the cached set can't serve this request, or None if it can."""
+    if entry is None:
+        return "no cached search"
+    if normalize_query(query) != normalize_query(entry.query):
+        return "query changed"
+    if not _narrows(refinement, entry.refinement):
+        return "filter widened beyond the cached search"
+    if not entry.complete and len(apply_refinement(entry.results, refinement, 0, limit + refinement.offset)) < limit + refinement.offset:
+        return "too few cached matches for the filter"
+    return None
+
+
+def _matches(result: Dict[str, Any], refinement: SearchRefinement) -> bool:
+    category = result.get("category") or {}
+    for name, actual in (
+        ("brand", result.get("brand")),
+        ("color", result.get("color")),
+        ("main_category", category.get("main_category")),
+        ("subcategory", category.get("subcategory")),
+    ):
+        wanted = getattr(refinement, name)
+        if wanted and (actual or "").lower() != wanted.lower():
+            return False
+    price = float(result.get("price") or 0.0)
+    if refinement.min_price is not None and price < refinement.min_price:
+        return False
+    if refinement.max_price is not None and price > refinement.max_price:
+        return False
+    if refinement.size and not _has_size(result.get("sizes"), refinement.size):
+        return False
+    return True
+
+
+def _has_size(sizes: Optional[List[Dict[str, Any]]], wanted: str) -> bool:
+    """Reports whether the size is in stock. Products without sizes in the
+    wanted size system are kept, as they can't be ruled out."""
+    wanted_parts = wanted.upper().split()
+    offered = [s for s in sizes or [] if (s.get("size") or "").upper().split()[:1] == wanted_parts[:1]]
+    if not offered:
+        return True
+    return any((s.get("size") or "").upper().split() == wanted_parts and s.get("in_stock", True) for s in offered)
+
+
+def apply_refinement(results: List[Dict[str, Any]], refinement: SearchRefinement, offset: int, limit: int) -> List[Dict[str, Any]]:
+    """Filters, sorts and pages a result set locally."""
+    matched = [r for r in results if _matches(r, refinement)]
+    if refinement.sort == "price_asc":
+        matched.sort(key=lambda r: float(r.get("price") or 0.0))
+    elif refinement.sort == "price_desc":
+        matched.sort(key=lambda r: float(r.get("price") or 0.0), reverse=True)
+    return matched[offset:offset + limit]
+
+
+def describe_refinement(query: str, refinement: SearchRefinement) -> str:
+    """Folds refinement filters into the query text for a fresh base search."""
+    parts = [query]
+    for value in (refinement.color, refinement.brand, refinement.subcategory or refinement.main_category):
+        if value:
+            parts.append(value)
+    if refinement.min_price is not None:
+        parts.append(f"over {refinement.min_price:g}")
+    if refinement.max_price is not None:
+        parts.append(f"under {refinement.max_price:g}")
+    if refinement.size:
+        parts.append(f"size {refinement.size}")
+    return " ".join(parts)
@@ -0,0 +1,156 @@
+import asyncio
+import json
+import logging
+import random
+import time
+from datetime import datetime, timezone
+from typing import Any, Dict, List, Optional, Set
+
+from app.clients.graph_client import GraphServiceClient
+
+logger = logging.getLogger(__name__)
+
+
+class ShadowSearch:
+    """Tries the structured search path on a share of live searches.
+
+    For `percent` of searches, the search terms are also sent to the graph
+    service's fulltext search in the background: the shadow's results are
+    never shown, and it never holds up or fails the search. Its
+    product ids and latency are compared with the Cypher search's, and a
+    search whose results overlap less than min_overlap (the share of ids
+    in both, out of all ids) is logged as a divergence. Every comparison
+    is appended to log_path, one JSON object a line, when it's set, for
+    offline analysis. At most max_pending shadows run at once; searches
+    beyond that aren't shadowed. Only used from the event loop.
+    """
+
+    def __init__(
+        self,
+        target: str,
+        percent: float = 0.0,
+        log_path: Optional[str] = None,
+        min_overlap: float = 0.5,
+        max_pending: int = 16,
+    ):
+        self.target = target
+        self.percent = percent
+        self.log_path = log_path
+        self.min_overlap = min_overlap
+        self.max_pending = max_pending
+        self._tasks: Set[asyncio.Task] = set()
+        self._totals = {"searches": 0, "divergences": 0, "errors": 0, "skipped": 0}
+        self._latency = {"primary": 0.0, "shadow": 0.0}
+
+    def submit(
+        self,
+        search_terms: str,
+        primary: List[Dict[str, Any]],
+        primary_latency: float,
+        locale: str = "",
+        limit: int = 10,
+        api_key: Optional[str] = None,
+        tenant_id: Optional[str] = None,
+        context: Optional[Dict[str, Any]] = None,
+    ):
+        """Shadows a search whose Cypher path returned primary in
+        primary_latency seconds, if it's sampled. context is logged with
+        the comparison."""
+        if self.percent <= 0 or not search_terms or random.random() * 100 >= self.percent:
+            return
+        if len(self._tasks) >= self.max_pending:
+            self._totals["skipped"] += 1
+            return
+        task = asyncio.get_running_loop().create_task(self._shadow(
+            search_terms, [p["id"] for p in primary], primary_latency, locale, limit, api_key, tenant_id, context or {}
+        ))
+        self._tasks.add(task)
+        task.add_done_callback(self._tasks.discard)
+
+    async def _shadow(
+        self,
+        search_terms: str,
+        primary_ids: List[str],
+        primary_latency: float,
+        locale: str,
+        limit: int,
+        api_key: Optional[str],
+        tenant_id: Optional[str],
+        context: Dict[str, Any],
+    ):
+        client = GraphServiceClient(target=self.target, api_key=api_key, tenant_id=tenant_id)
+        client.connect()
+        started = time.monotonic()
+        try:
+            shadow = await asyncio.to_thread(client.search_text, search_terms, locale, limit)
+        except Exception as e:
+            logger.warning(f"Shadow search failed for {search_terms!r}: {e}")
+            self._totals["errors"] += 1
+            return
+        finally:
+            client.close()
+        shadow_latency = time.monotonic() - started
+
+        comparison = compare(primary_ids, [p["id"] for p in shadow])
+        diverged = comparison["overlap"] < self.min_overlap
+        self._totals["searches"] += 1
+        self._totals["divergences"] += diverged
+        self._latency["primary"] += primary_latency
+        self._latency["shadow"] += shadow_latency
+        if diverged:
+            logger.warning(
+                f"Shadow search diverged for {search_terms!r}: {comparison['overlap']:.0%} overlap, "
+                f"{len(primary_ids)} Cypher vs {comparison['shadow_count']} structured results, "
+                f"{primary_latency * 1000:.0f}ms vs {shadow_latency * 1000:.0f}ms"
+            )
+        if self.log_path:
+            record = {
+                "at": datetime.now(timezone.utc).isoformat(),
+                "search_terms": search_terms,
+                "locale": locale,
+                **context,
+                **comparison,
+                "primary_ids": primary_ids,
+                "shadow_ids": [p["id"] for p in shadow],
+                "primary_ms": round(primary_latency * 1000, 1),
+                "shadow_ms": round(shadow_latency * 1000, 1),
+                "diverged": diverged,
+            }
+            await asyncio.to_thread(self._append, record)
+
+    def _append(self, record: Dict[str, Any]):
+        try:
+            with open(self.log_path, "a", encoding="utf-8") as f:
+                f.write(json.dumps(record) + "\n")
+        except OSError as e:
+            logger.error(f"Failed to write shadow comparison to {self.log_path}: {e}")
+
+    def prometheus(self) -> str:
+        """Shadow totals since start in the Prometheus text exposition format."""
+        totals, latency = self._totals, self._latency
+        return "\n".join([
+            "# HELP search_shadow_total Searches shadowed on the structured path, by outcome.",
+            "# TYPE search_shadow_total counter",
+            f'search_shadow_total{{outcome="compared"}} {totals["searches"]}',
+            f'search_shadow_total{{outcome="diverged"}} {totals["divergences"]}',
+            f'search_shadow_total{{outcome="failed"}} {totals["errors"]}',
+            f'search_shadow_total{{outcome="skipped"}} {totals["skipped"]}',
+            "# HELP search_shadow_seconds_total Graph search time of compared searches, by path.",
+            "# TYPE search_shadow_seconds_total counter",
+            f'search_shadow_seconds_total{{path="cypher"}} {latency["primary"]:.3f}',
+            f'search_shadow_seconds_total{{path="structured"}} {latency["shadow"]:.3f}',
+        ]) + "\n"
+
+
+def compare(primary_ids: List[str], shadow_ids: List[str]) -> Dict[str, Any]:
+    """How alike two result lists are: the share of ids in both out of all
+    ids (1.0 when both are empty), whether they agree on the top result,
+    and how many each has."""
+    primary, shadow = set(primary_ids), set(shadow_ids)
+    union = primary | shadow
+    return {
+        "overlap": len(primary & shadow) / len(union) if union else 1.0,
+        "same_top": bool(primary_ids) and bool(shadow_ids) and primary_ids[0] == shadow_ids[0],
+        "primary_count": len(primary_ids),
+        "shadow_count": len(shadow_ids),
+    }
@@ -0,0 +1,240 @@
+import re
+from dataclasses import dataclass
+from typing import List, Optional, Tuple
+
+from app.models.schemas import SearchRefinement, SpokenFilters
+from app.services.keyword_search import analyze
+
+DEFAULT_LOCALE = "en-US"
+
+_UNITS = {
+    "zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+    "seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+    "thirteen": 13, "fourteen": 14, "fifteen": 15, "sixteen": 16, "seventeen": 17,
+    "eighteen": 18, "nineteen": 19,
+}
+_TENS = {
+    "twenty": 20, "thirty": 30, "forty": 40, "fifty": 50, "sixty": 60, "seventy": 70,
+    "eighty": 80, "ninety": 90,
+}
+_SCALES = {"hundred": 100, "thousand": 1000, "grand": 1000}
+_FRACTIONS = {"half": 0.5, "quarter": 0.25}
+
+# Languages writing 1.234,5 rather than 1,234.5
+_DECIMAL_COMMA = {"de", "fr", "es", "it", "nl", "pt", "pl", "sv", "da", "fi", "nb", "ru", "tr"}
+_EURO_REGIONS = {"DE", "FR", "ES", "IT", "NL", "PT", "IE", "AT", "BE", "FI", "GR", "LU"}
+_REGION_CURRENCY = {"US": "USD", "CA": "CAD", "AU": "AUD", "NZ": "NZD", "GB": "GBP", "IN": "INR"}
+_SYMBOLS = {"USD": "$", "CAD": "$", "AUD": "$", "NZD": "$", "GBP": "£", "EUR": "€", "INR": "₹"}
+_SYMBOL_CURRENCY = {"$": None, "£": "GBP", "€": "EUR", "₹": "INR"}
+# Shoe size systems by region; the rest of Europe uses EU sizes
+_REGION_SIZES = {"US": "US", "CA": "US", "GB": "UK", "IE": "UK", "AU": "UK", "NZ": "UK", "IN": "UK"}
+
+# None: the locale's dollar
+_CURRENCY_WORDS = {
+    "dollar": None, "dollars": None, "buck": None, "bucks": None, "usd": "USD",
+    "pound": "GBP", "pounds": "GBP", "quid": "GBP", "gbp": "GBP",
+    "euro": "EUR", "euros": "EUR", "eur": "EUR",
+    "rupee": "INR", "rupees": "INR", "inr": "INR",
+}
+_SUBUNITS = {"cent", "cents", "pence", "penny", "p"}
+_SIZE_SYSTEMS = {"us": "US", "uk": "UK", "eu": "EU", "euro": "EU", "european": "EU"}
+
+_TOKEN = re.compile(r"[$£€₹]?\d+(?:[.,]\d+)*|[a-z]+")
+_SIZE = re.compile(r"\b(?:(us|uk|eu|euro|european)\s+)?size\s+(?:(us|uk|eu)\s+)?(\d+(?:\.\d+)?)\b")
+
+
+@dataclass
+class Locale:
+    decimal: str
+    currency: str
+    size_system: str
+
+
+def parse_locale(locale: Optional[str]) -> Locale:
+    """Number format, currency and shoe sizes for a BCP 47 tag like "en-GB"."""
+    parts = re.split(r"[-_]", locale or DEFAULT_LOCALE)
+    language = parts[0].lower()
+    region = parts[1].upper() if len(parts) > 1 else ""
+    if region in _EURO_REGIONS or (not region and language in _DECIMAL_COMMA):
+        currency, sizes = "EUR", "EU"
+    else:
+        currency = _REGION_CURRENCY.get(region, "USD")
+        sizes = _REGION_SIZES.get(region, "EU" if language in _DECIMAL_COMMA else "US")
+    return Locale(decimal="," if language in _DECIMAL_COMMA else ".", currency=currency, size_system=sizes)
+
+
+@dataclass
+class SpokenQuery:
+    """A query with spoken numbers written as digits, and the filters they name."""
+    text: str
+    filters: SpokenFilters
+
+    def refine(self, refinement: SearchRefinement) -> SearchRefinement:
+        """Adds the spoken filters the client didn't set explicitly."""
+        update = {}
+        for name in ("min_price", "max_price", "size"):
+            value = getattr(self.filters, name)
+            if value is not None and getattr(refinement, name) is None:
+                update[name] = value
+        return refinement.model_copy(update=update) if update else refinement
+
+
+def _format(value: float) -> str:
+    return str(int(value)) if value == int(value) else f"{value:g}"
+
+
+def _money(value: float) -> str:
+    return str(int(value)) if value == int(value) else f"{value:.2f}"
+
+
+def _digits(token: str, locale: Locale) -> Optional[float]:
+    """Parses written digits in the locale's format: 1,234.5 or 1.234,5."""
+    thousands = "." if locale.decimal == "," else ","
+    whole, _, fraction = token.rpartition(locale.decimal)
+    if not whole:
+        whole, fraction = fraction, ""
+    whole = whole.replace(thousands, "")
+    try:
+        return float(f"{whole}.{fraction}" if fraction else whole)
+    except ValueError:
+        return None
+
+
+class _Tokens:
+    def __init__(self, text: str):
+        self.matches = list(_TOKEN.finditer(text))
+
+    def word(self, i: int) -> str:
+        return self.matches[i].group() if 0 <= i < len(self.matches) else ""
+
+
+def _read_number(tokens: _Tokens, i: int, locale: Locale) -> Tuple[Optional[float], int]:
+    """Reads a number starting at token i, spoken or in digits.
+
+    Returns the value and the index after it, or None when there is no
+    number there.
+    """
+    word = tokens.word(i)
+    if word and (word[0].isdigit() or word[0] in _SYMBOL_CURRENCY):
+        value = _digits(word.lstrip("$£€₹"), locale)
+        if value is None:
+            return None, i
+        return _read_fraction(tokens, i + 1, value)
+
+    # last: the kind of word before, so "fifty and eighty" and "ten eleven"
+    # read as two numbers
+    total, current, j, last = 0.0, 0.0, i, None
+    while True:
+        word = tokens.word(j)
+        seen = last is not None
+        if word in _UNITS and (not seen or last in ("scale", "and") or (last == "ten" and _UNITS[word] < 10)):
+            current += _UNITS[word]
+            kind = "unit"
+        elif word in _TENS and (not seen or last in ("scale", "and")):
+            current += _TENS[word]
+            kind = "ten"
+        elif word in _SCALES and (seen or word != "grand") and (last != "scale" or _SCALES[word] > 100):
+            kind = "scale"
+            scale = _SCALES[word]
+            if scale >= 1000:
+                total += max(current, 1) * scale
+                current = 0
+            else:
+                current = max(current, 1) * scale
+        elif word == "a" and tokens.word(j + 1) in ("hundred", "thousand") and not seen:
+            current = 1
+            kind = "unit"
+        elif word == "and" and last == "scale" and (tokens.word(j + 1) in _UNITS or tokens.word(j + 1) in _TENS):
+            kind = "and"
+        elif word == "point" and seen and tokens.word(j + 1) in _UNITS:
+            decimals = ""
+            j += 1
+            while tokens.word(j) in _UNITS and _UNITS[tokens.word(j)] < 10:
+                decimals += str(_UNITS[tokens.word(j)])
+                j += 1
+            return total + current + float("0." + decimals), j
+        else:
+            break
+        last = kind
+        j += 1
+    if last is None:
+        return None, i
+    return _read_fraction(tokens, j, total + current)
+
+
+def _read_fraction(tokens: _Tokens, j: int, value: float) -> Tuple[float, int]:
+    """Reads a trailing "and a half"."""
+    if tokens.word(j) == "and" and tokens.word(j + 1) == "a" and tokens.word(j + 2) in _FRACTIONS:
+        return value + _FRACTIONS[tokens.word(j + 2)], j + 3
+    return value, j
+
+
+def _symbol(currency: str) -> str:
+    return _SYMBOLS.get(currency, currency + " ")
+
+
+def normalize(query: str, locale: Optional[str] = None) -> SpokenQuery:
+    """Writes spoken numbers, prices and sizes in a query as digits.
+
+    "under one hundred and twenty dollars" becomes "under $120", and
+    "size ten and a half" becomes "size 10.5". Prices and the size are also
+    returned as filters, so they can be applied exactly rather than left to
+    the query's interpretation.
+    """
+    settings = parse_locale(locale)
+    text = query
+    tokens = _Tokens(query.lower())
+    replacements: List[Tuple[int, int, str]] = []
+    currency = None
+
+    i = 0
+    while i < len(tokens.matches):
+        value, j = _read_number(tokens, i, settings)
+        if value is None:
+            i += 1
+            continue
+        start, end = tokens.matches[i].start(), tokens.matches[j - 1].end()
+        word = tokens.word(i)
+        written = _format(value)
+        prefix = word[0] if word[0] in _SYMBOL_CURRENCY else None
+        unit = tokens.word(j)
+        if prefix or unit in _CURRENCY_WORDS:
+            if prefix:
+                amount_currency = _SYMBOL_CURRENCY[prefix] or settings.currency
+            else:
+                amount_currency = _CURRENCY_WORDS[unit] or (settings.currency if _SYMBOLS.get(settings.currency) == "$" else "USD")
+                end = tokens.matches[j].end()
+                j += 1
+                # "twenty dollars and fifty cents", "twenty pounds fifty"
+                k = j + 1 if tokens.word(j) == "and" else j
+                cents, after = _read_number(tokens, k, settings)
+                if cents is not None and cents < 100 and (tokens.word(after) in _SUBUNITS or k == j):
+                    value += cents / 100
+                    end = tokens.matches[after - 1].end()
+                    j = after
+                    if tokens.word(j) in _SUBUNITS:
+                        end = tokens.matches[j].end()
+                        j += 1
+            currency = currency or amount_currency
+            written = _symbol(amount_currency) + _money(value)
+        elif word in ("one", "a") and j == i + 1 and tokens.word(i - 1) != "size":
+            # "the blue one" isn't a number
+            i = j
+            continue
+        replacements.append((start, end, written))
+        i = j
+
+    for start, end, written in reversed(replacements):
+        text = text[:start] + written + text[end:]
+
+    filters = SpokenFilters(currency=currency)
+    keywords = analyze(text)
+    filters.min_price, filters.max_price = keywords.min_price, keywords.max_price
+    match = _SIZE.search(text.lower())
+    if match:
+        system = match.group(1) or match.group(2)
+        filters.size_system = _SIZE_SYSTEMS[system] if system else settings.size_system
+        filters.size = f"{filters.size_system} {match.group(3)}"
+    if filters.currency is None and (filters.min_price is not None or filters.max_price is not None):
+        filters.currency = settings.currency
+    return SpokenQuery(text=text, filters=filters)
@@ -0,0 +1,30 @@
+import logging
+from typing import Optional
+
+import httpx
+
+logger = logging.getLogger(__name__)
+
+
+class STTClient:
+    """Transcribes speech with an HTTP speech-to-text service.
+
+    The service takes POST audio (by Content-Type, e.g. audio/wav), with
+    the expected language as ?language=, and answers {"text": "..."}.
+    """
+
+    def __init__(self, url: str, timeout: float = 10.0):
+        self.url = url
+        self.client = httpx.AsyncClient(timeout=timeout)
+
+    async def transcribe(self, audio: bytes, content_type: str = "audio/wav", language: Optional[str] = None) -> str:
+        """What was said in the audio; empty when nothing was."""
+        params = {"language": language} if language else None
+        response = await self.client.post(
+            self.url, content=audio, params=params, headers={"Content-Type": content_type}
+        )
+        response.raise_for_status()
+        return (response.json().get("text") or "").strip()
+
+    async def close(self):
+        await self.client.aclose()
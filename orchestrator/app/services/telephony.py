@@ -0,0 +1,324 @@
+import asyncio
+import base64
+import hashlib
+import hmac
+import io
+import json
+import logging
+import math
+import re
+import wave
+from array import array
+from collections import deque
+from dataclasses import dataclass, field
+from typing import AsyncIterator, Callable, Deque, Dict, List, Optional
+from xml.sax.saxutils import quoteattr
+
+from starlette.websockets import WebSocket, WebSocketDisconnect
+
+from app.services.spoken_numbers import normalize
+from app.services.stt import STTClient
+
+logger = logging.getLogger(__name__)
+
+# Twilio Media Streams carry 8 kHz mono μ-law, 20 ms to a message
+SAMPLE_RATE = 8000
+FRAME_MS = 20
+# What phone calls ask the TTS service for
+AUDIO_FORMAT = "audio/x-mulaw;rate=8000"
+
+
+def _ulaw_sample(byte: int) -> int:
+    u = ~byte & 0xFF
+    magnitude = (((u & 0x0F) << 3) + 0x84) << ((u & 0x70) >> 4)
+    return 0x84 - magnitude if u & 0x80 else magnitude - 0x84
+
+
+_ULAW = [_ulaw_sample(b) for b in range(256)]
+
+
+def ulaw_to_pcm16(data: bytes) -> array:
+    """Decodes G.711 μ-law to 16-bit linear samples."""
+    return array("h", (_ULAW[b] for b in data))
+
+
+def pcm16_to_wav(samples, sample_rate: int = SAMPLE_RATE) -> bytes:
+    """Wraps mono 16-bit samples, as an array or little-endian bytes, in WAV."""
+    buffer = io.BytesIO()
+    with wave.open(buffer, "wb") as f:
+        f.setnchannels(1)
+        f.setsampwidth(2)
+        f.setframerate(sample_rate)
+        f.writeframes(samples.tobytes() if isinstance(samples, array) else bytes(samples))
+    return buffer.getvalue()
+
+
+class UtteranceDetector:
+    """Splits a caller's audio into utterances by how loud it is.
+
+    Speech starts once frames stay above threshold (RMS of 16-bit samples)
+    for min_speech_ms, and ends after silence_ms of quiet or max_ms of
+    speech. A little audio from before the start is kept, so the first
+    syllable isn't cut off.
+    """
+
+    def __init__(self, threshold: float = 500.0, silence_ms: int = 700, min_speech_ms: int = 200, max_ms: int = 15000):
+        self.threshold = threshold
+        self.silence_ms = silence_ms
+        self.min_speech_ms = min_speech_ms
+        self.max_ms = max_ms
+        self.speaking = False
+        self._loud_ms = 0
+        self._quiet_ms = 0
+        self._lead: Deque[array] = deque(maxlen=max(1, (min_speech_ms + 200) // FRAME_MS))
+        self._frames: List[array] = []
+
+    def feed(self, samples: array) -> Optional[array]:
+        """Takes one frame; returns the utterance's samples when it ends."""
+        loud = bool(samples) and math.sqrt(sum(s * s for s in samples) / len(samples)) >= self.threshold
+        if not self.speaking:
+            self._lead.append(samples)
+            self._loud_ms = self._loud_ms + FRAME_MS if loud else 0
+            if self._loud_ms >= self.min_speech_ms:
+                self.speaking = True
+                self._frames = list(self._lead)
+                self._lead.clear()
+                self._quiet_ms = 0
+            return None
+
+        self._frames.append(samples)
+        self._quiet_ms = 0 if loud else self._quiet_ms + FRAME_MS
+        if self._quiet_ms < self.silence_ms and len(self._frames) * FRAME_MS < self.max_ms:
+            return None
+        utterance = array("h")
+        for frame in self._frames:
+            utterance.extend(frame)
+        self.speaking = False
+        self._loud_ms = 0
+        self._frames = []
+        return utterance
+
+
+@dataclass
+class PhoneCommand:
+    """A cart or order request said on the phone.
+
+    kind: confirm or decline the proposed action, undo, add (the index-th
+    result read out, from 1), clear the cart, or order.
+    """
+    kind: str
+    index: int = 1
+    size: Optional[str] = None
+
+
+_ORDINALS = {
+    "first": 1, "1st": 1, "1": 1, "one": 1,
+    "second": 2, "2nd": 2, "2": 2, "two": 2,
+    "third": 3, "3rd": 3, "3": 3, "three": 3,
+    "fourth": 4, "4th": 4, "4": 4, "four": 4,
+    "fifth": 5, "5th": 5, "5": 5, "five": 5,
+}
+# Said on their own, so "okay show me red shoes" is still a search
+_CONFIRM = re.compile(r"^(?:yes|yeah|yep|sure|confirm|correct|go ahead|do it|okay|ok)(?: please| do it| go ahead)?$")
+_DECLINE = re.compile(r"^(?:no|nope|cancel|don't|do not|never mind)(?: thanks| thank you| don't| cancel)?$")
+_UNDO = re.compile(r"\b(?:undo|take (?:that|it) back)\b")
+_CLEAR = re.compile(r"\b(?:clear|empty) (?:my |the )?(?:cart|basket)\b")
+_ORDER = re.compile(r"\b(?:place (?:my |the |an )?order|check ?out|order (?:it|them|everything))\b")
+_ADD = re.compile(r"\badd (?:it|that|this|them|(?:the )?(?:number )?(\w+)(?: one)?)\b")
+
+
+def parse_command(text: str, locale: Optional[str] = None) -> Optional[PhoneCommand]:
+    """The cart command in what the caller said, or None for a search.
+
+    Phone callers have no buttons, so "yes", "undo", "add the second one
+    in size 10" and "place my order" do what the voice action endpoints do.
+    English only, like the cart prompts.
+    """
+    spoken = normalize(text, locale)
+    words = " ".join(re.sub(r"[^\w\s']", " ", spoken.text.lower()).split())
+    if _CONFIRM.search(words):
+        return PhoneCommand(kind="confirm")
+    if _DECLINE.search(words):
+        return PhoneCommand(kind="decline")
+    if _UNDO.search(words):
+        return PhoneCommand(kind="undo")
+    if _CLEAR.search(words):
+        return PhoneCommand(kind="clear")
+    if _ORDER.search(words):
+        return PhoneCommand(kind="order")
+    match = _ADD.search(words)
+    if match is None or (match.group(1) and match.group(1) not in _ORDINALS):
+        return None
+    return PhoneCommand(kind="add", index=_ORDINALS.get(match.group(1) or "", 1), size=spoken.filters.size)
+
+
+def twilio_signature(auth_token: str, url: str, params: Optional[Dict[str, str]] = None) -> str:
+    """The X-Twilio-Signature Twilio sends with a request to url: HMAC-SHA1,
+    keyed with the account's auth token, of url followed by each POST
+    parameter's name and value, sorted by name."""
+    data = url + "".join(name + value for name, value in sorted((params or {}).items()))
+    return base64.b64encode(hmac.new(auth_token.encode(), data.encode(), hashlib.sha1).digest()).decode("ascii")
+
+
+def valid_twilio_signature(auth_token: str, signature: str, url: str, params: Optional[Dict[str, str]] = None) -> bool:
+    return bool(signature) and hmac.compare_digest(twilio_signature(auth_token, url, params), signature)
+
+
+def sign_parameters(secret: str, call_sid: str, parameters: Dict[str, str]) -> str:
+    """Signs a call's stream parameters for that call, so the media stream
+    can trust the buyer and payment method they name."""
+    data = json.dumps({"call_sid": call_sid, "parameters": {k: v for k, v in parameters.items() if v}}, sort_keys=True)
+    return hmac.new(secret.encode(), data.encode(), hashlib.sha256).hexdigest()
+
+
+@dataclass
+class PhoneCall:
+    """One call, as the turns of the conversation see it."""
+    call_sid: str
+    stream_sid: str
+    # The <Parameter>s of the TwiML <Stream>: tenant_id, locale, persona,
+    # customer_id, payment_method, checked against their signature
+    parameters: Dict[str, str] = field(default_factory=dict)
+    # Product ids of the last results, for "add the second one"
+    results: List[str] = field(default_factory=list)
+    # The proposed action waiting for a yes or no
+    pending_action: Optional[str] = None
+
+    @property
+    def session_id(self) -> str:
+        return f"call-{self.call_sid}"
+
+
+# Answers what the caller said with audio in AUDIO_FORMAT
+Responder = Callable[[PhoneCall, str], AsyncIterator[bytes]]
+
+
+class TwilioMediaStream:
+    """Bridges a phone call on a Twilio Media Stream WebSocket to the
+    orchestrator.
+
+    The caller's audio is split into utterances and transcribed; each one
+    is answered by respond, whose audio is played back on the call. Talking
+    over an answer stops it: its turn is cancelled, which cancels the work
+    behind it, and Twilio is told to drop the audio it hasn't played yet.
+
+    The call's parameters must come signed with secret by sign_parameters
+    for its CallSid; a stream whose start doesn't is hung up on.
+    """
+
+    def __init__(
+        self,
+        websocket: WebSocket,
+        stt: STTClient,
+        respond: Responder,
+        secret: str,
+        detector: Optional[UtteranceDetector] = None,
+    ):
+        self.websocket = websocket
+        self.secret = secret
+        self.stt = stt
+        self.respond = respond
+        self.detector = detector or UtteranceDetector()
+        self.call: Optional[PhoneCall] = None
+        self._turn: Optional[asyncio.Task] = None
+        self._turns = 0
+        # Marks sent after answers Twilio hasn't finished playing
+        self._playing: set = set()
+
+    async def run(self):
+        """Serves the call until it hangs up; the WebSocket is accepted."""
+        try:
+            while True:
+                message = json.loads(await self.websocket.receive_text())
+                event = message.get("event")
+                if event == "start":
+                    start = message["start"]
+                    call_sid = start.get("callSid", "")
+                    parameters = dict(start.get("customParameters") or {})
+                    signature = parameters.pop("signature", "")
+                    if not call_sid or not hmac.compare_digest(
+                        sign_parameters(self.secret, call_sid, parameters), str(signature)
+                    ):
+                        logger.warning(f"Call {call_sid!r} started without signed parameters; hanging up")
+                        await self.websocket.close(code=1008)
+                        return
+                    self.call = PhoneCall(
+                        call_sid=call_sid,
+                        stream_sid=message.get("streamSid") or start.get("streamSid", ""),
+                        parameters=parameters,
+                    )
+                    logger.info(f"Call {self.call.call_sid} connected")
+                elif event == "media" and self.call is not None:
+                    await self._hear(base64.b64decode(message["media"]["payload"]))
+                elif event == "mark":
+                    self._playing.discard(message.get("mark", {}).get("name"))
+                elif event == "stop":
+                    break
+        except WebSocketDisconnect:
+            pass
+        except Exception as e:
+            logger.error(f"Media stream failed: {e}")
+        finally:
+            await self._stop_turn()
+            if self.call is not None:
+                logger.info(f"Call {self.call.call_sid} ended")
+
+    async def _hear(self, payload: bytes):
+        was_speaking = self.detector.speaking
+        utterance = self.detector.feed(ulaw_to_pcm16(payload))
+        if self.detector.speaking and not was_speaking and (self._busy() or self._playing):
+            logger.info(f"Caller barged in on call {self.call.call_sid}")
+            await self._stop_turn()
+            await self._send({"event": "clear"})
+            self._playing.clear()
+        if utterance is not None:
+            await self._stop_turn()
+            self._turns += 1
+            self._turn = asyncio.create_task(self._answer(utterance, f"turn-{self._turns}"))
+
+    async def _answer(self, utterance: array, mark: str):
+        call = self.call
+        try:
+            text = await self.stt.transcribe(pcm16_to_wav(utterance), language=call.parameters.get("locale"))
+        except Exception as e:
+            logger.error(f"Transcription failed on call {call.call_sid}: {e}")
+            return
+        if not text:
+            return
+        logger.info(f"Call {call.call_sid} said: {text}")
+        try:
+            async for chunk in self.respond(call, text):
+                await self._send({"event": "media", "media": {"payload": base64.b64encode(chunk).decode("ascii")}})
+        except asyncio.CancelledError:
+            raise
+        except Exception as e:
+            logger.error(f"Answering call {call.call_sid} failed: {e}")
+        self._playing.add(mark)
+        await self._send({"event": "mark", "mark": {"name": mark}})
+
+    def _busy(self) -> bool:
+        return self._turn is not None and not self._turn.done()
+
+    async def _stop_turn(self):
+        if self._busy():
+            self._turn.cancel()
+            try:
+                await self._turn
+            except asyncio.CancelledError:
+                pass
+        self._turn = None
+
+    async def _send(self, message: Dict):
+        message["streamSid"] = self.call.stream_sid
+        await self.websocket.send_text(json.dumps(message))
+
+
+def twiml_stream(url: str, parameters: Dict[str, str]) -> str:
+    """TwiML that connects a call to the media stream at url (wss://)."""
+    tags = "".join(
+        f"<Parameter name={quoteattr(name)} value={quoteattr(value)}/>" for name, value in parameters.items() if value
+    )
+    return (
+        '<?xml version="1.0" encoding="UTF-8"?>'
+        f"<Response><Connect><Stream url={quoteattr(url)}>{tags}</Stream></Connect></Response>"
+    )
@@ -0,0 +1,120 @@
+import asyncio
+import json
+import logging
+import time
+from typing import Any, Dict, List, Optional
+
+from app.clients.graph_client import GraphServiceClient
+from app.models.schemas import ActionResponse, ProductQueryRequest, ProductQueryResponse, VoiceAction
+
+logger = logging.getLogger(__name__)
+
+
+def entry(kind: str, text: str = "", data: Optional[Dict[str, Any]] = None) -> Dict[str, Any]:
+    """A transcript entry as AppendTranscript takes it, stamped with now."""
+    return {
+        "kind": kind,
+        "text": text,
+        "data": json.dumps(data, default=str) if data else "",
+        "occurred_at": int(time.time() * 1000),
+    }
+
+
+def search_entries(
+    request: ProductQueryRequest,
+    response: Optional[ProductQueryResponse],
+    speech: Optional[str] = None,
+    failure: Optional[str] = None,
+) -> List[Dict[str, Any]]:
+    """Entries for one search: what was said, how it was searched, what
+    was shown and what the user was told.
+
+    The utterance keeps the request's options so a replay can send the
+    same request again. Without a response the results entry records why:
+    failure, or "cancelled".
+    """
+    options = request.model_dump(exclude_none=True)
+    options.pop("query", None)
+    options.pop("session_id", None)
+    entries = [entry("utterance", request.query, options)]
+    if response is None:
+        entries.append(entry("results", data={"failed": failure or "cancelled"}))
+        return entries
+
+    entries.append(entry("query", response.rewritten_query or response.query, {
+        "cypher_query": response.cypher_query,
+        "search_terms": response.search_terms,
+        "search_path": response.search_path,
+        "language": response.language,
+        "fallback_reason": response.fallback_reason,
+        "rewritten_query": response.rewritten_query,
+        "spoken_filters": response.spoken_filters.model_dump(exclude_none=True) if response.spoken_filters else None,
+        "from_cache": response.from_cache,
+    }))
+    entries.append(entry("results", data={
+        "results": [
+            {"product_id": r.product_id, "name": r.name, "combined_score": r.combined_score}
+            for r in response.recommendations
+        ],
+    }))
+    if speech:
+        entries.append(entry("speech", speech))
+    return entries
+
+
+def action_entries(step: str, response: ActionResponse, action: Optional[VoiceAction] = None) -> List[Dict[str, Any]]:
+    """Entries for a voice action, its confirmation or an undo; step says which."""
+    data: Dict[str, Any] = {
+        "step": step,
+        "status": response.status,
+        "action_id": response.action_id,
+        "cart": [{"sku": item.sku, "quantity": item.quantity} for item in response.cart],
+    }
+    if action is not None:
+        data["action"] = action.model_dump(exclude_none=True)
+    if response.order:
+        data["order_id"] = response.order.get("id")
+    return [entry("action", data=data), entry("speech", response.prompt)]
+
+
+class TranscriptRecorder:
+    """Persists voice-session transcripts to the graph service.
+
+    Endpoints hand over each answered request's entries; one background
+    writer appends them in the order they came, so a session's transcript
+    keeps the order things happened in. Recording never holds up or fails
+    a request: entries that can't be appended are logged and dropped, as
+    are new ones once max_pending batches are waiting. Only used from the
+    event loop.
+    """
+
+    def __init__(self, target: str, max_pending: int = 1000):
+        self.target = target
+        self._pending: asyncio.Queue = asyncio.Queue(maxsize=max_pending)
+
+    def record(
+        self,
+        session_id: Optional[str],
+        entries: List[Dict[str, Any]],
+        tenant_id: Optional[str] = None,
+        customer_id: Optional[str] = None,
+    ):
+        if not session_id or not entries:
+            return
+        try:
+            self._pending.put_nowait((session_id, entries, tenant_id or "", customer_id or ""))
+        except asyncio.QueueFull:
+            logger.warning(f"Transcript backlog full, dropping {len(entries)} entries for session {session_id}")
+
+    async def run(self):
+        client = GraphServiceClient(target=self.target)
+        client.connect()
+        try:
+            while True:
+                session_id, entries, tenant_id, customer_id = await self._pending.get()
+                try:
+                    await asyncio.to_thread(client.append_transcript, session_id, entries, tenant_id, customer_id)
+                except Exception as e:
+                    logger.error(f"Failed to append transcript for session {session_id}: {e}")
+        finally:
+            client.close()
@@ -0,0 +1,160 @@
+import logging
+import re
+from typing import Any, AsyncIterator, Dict, Iterable, List, Optional
+from xml.sax.saxutils import escape, quoteattr
+
+import httpx
+
+from app.models.schemas import VoicePersona
+
+logger = logging.getLogger(__name__)
+
+# A price as describe_results and the dialog prompts write it: "$120.00",
+# or "120,00 $" in German and Spanish
+_PRICE = r"[$£€₹]\d[\d,]*(?:\.\d+)?|\d[\d.]*(?:,\d+)? [$£€₹]"
+
+# A BCP 47 language tag, "en" or "en-GB"; anything else isn't put in SSML
+_LOCALE = re.compile(r"[A-Za-z]{2,8}(?:[-_][A-Za-z0-9]{1,8})*")
+
+# What describe_results says, by language
+_PHRASES = {
+    "en": {
+        "none": "I couldn't find anything matching that.",
+        "product": "a product",
+        "top": "The top match is {name}",
+        "brand": " by {brand}",
+        "price": ", for {price}",
+        "others": " I also found {others}.",
+        "refusal": "I can't help with that, but I can help you find a product.",
+        "neutral": "Here's what I found.",
+    },
+    "de": {
+        "none": "Dazu habe ich leider nichts gefunden.",
+        "product": "ein Produkt",
+        "top": "Der beste Treffer ist {name}",
+        "brand": " von {brand}",
+        "price": ", für {price}",
+        "others": " Außerdem habe ich {others} gefunden.",
+        "refusal": "Dabei kann ich nicht helfen, aber gern helfe ich dir, ein Produkt zu finden.",
+        "neutral": "Das habe ich gefunden.",
+    },
+    "es": {
+        "none": "No encontré nada que coincida.",
+        "product": "un producto",
+        "top": "El mejor resultado es {name}",
+        "brand": " de {brand}",
+        "price": ", por {price}",
+        "others": " También encontré {others}.",
+        "refusal": "No puedo ayudarte con eso, pero sí a encontrar un producto.",
+        "neutral": "Esto es lo que encontré.",
+    },
+}
+
+
+class TTSClient:
+    """Streams speech from an HTTP text-to-speech service.
+
+    The service takes POST {"text", "voice", "format", "speaking_rate",
+    "pitch"} and streams back audio, labelled by its Content-Type. format
+    is "ssml" when text is SSML; speaking_rate is a multiple of the voice's
+    normal rate and pitch is in semitones. With audio_format set, the
+    request also names the audio wanted, e.g. "audio/x-mulaw;rate=8000"
+    for phone calls.
+    """
+
+    def __init__(
+        self,
+        url: str,
+        voice: Optional[str] = None,
+        chunk_size: int = 8192,
+        timeout: float = 30.0,
+        audio_format: Optional[str] = None,
+    ):
+        self.url = url
+        self.voice = voice
+        self.requested_format = audio_format
+        self.chunk_size = chunk_size
+        self.client = httpx.AsyncClient(timeout=timeout)
+        # Content-Type of the last synthesis
+        self.audio_format = "application/octet-stream"
+
+    async def synthesize(
+        self,
+        text: str,
+        persona: Optional[VoicePersona] = None,
+        ssml: Optional[str] = None,
+        language: Optional[str] = None,
+    ) -> AsyncIterator[bytes]:
+        """Yields audio chunks of the spoken text as they arrive, in the
+        persona's voice for the language. ssml, when given, is spoken
+        instead of text."""
+        payload: Dict[str, Any] = {"text": ssml or text, "format": "ssml" if ssml else "text"}
+        voice = self.voice
+        if persona is not None:
+            voice = persona.voices.get(language or "") or persona.voice or voice
+        if voice:
+            payload["voice"] = voice
+        if self.requested_format:
+            payload["audio_format"] = self.requested_format
+        if persona is not None:
+            payload["speaking_rate"] = persona.speaking_rate
+            payload["pitch"] = persona.pitch
+        async with self.client.stream("POST", self.url, json=payload) as response:
+            response.raise_for_status()
+            self.audio_format = response.headers.get("content-type", self.audio_format)
+            async for chunk in response.aiter_bytes(self.chunk_size):
+                yield chunk
+
+    async def close(self):
+        await self.client.aclose()
+
+
+def to_ssml(text: str, brands: Iterable[str] = (), price_pause_ms: int = 300, locale: Optional[str] = None) -> str:
+    """Marks up speech as SSML: brand names are emphasized and each price
+    is followed by a pause, so it isn't run into the next words."""
+    names = sorted({escape(b) for b in brands if b and b.strip()}, key=len, reverse=True)
+    pattern = f"(?P<price>{_PRICE})(?P<punct>[.,;:!?]?)"
+    if names:
+        pattern += "|(?<!\\w)(?P<brand>" + "|".join(re.escape(n) for n in names) + ")(?!\\w)"
+
+    def mark(match: "re.Match") -> str:
+        if match.group("price"):
+            pause = f'<break time="{price_pause_ms}ms"/>' if price_pause_ms else ""
+            return match.group("price") + match.group("punct") + pause
+        return f'<emphasis level="moderate">{match.group("brand")}</emphasis>'
+
+    lang = f" xml:lang={quoteattr(locale)}" if locale and _LOCALE.fullmatch(locale) else ""
+    return f"<speak{lang}>{re.sub(pattern, mark, escape(text))}</speak>"
+
+
+def describe_results(recommendations: List[Dict[str, Any]], language: str = "en") -> str:
+    """What a voice client says about the top results, in language."""
+    phrases = _PHRASES.get(language, _PHRASES["en"])
+    if not recommendations:
+        return phrases["none"]
+    top = recommendations[0]
+    text = phrases["top"].format(name=top.get("name") or phrases["product"])
+    if top.get("brand"):
+        text += phrases["brand"].format(brand=top["brand"])
+    if top.get("price"):
+        text += phrases["price"].format(price=_price(float(top["price"]), language))
+    text += "."
+    if len(recommendations) > 1:
+        others = ", ".join(r.get("name", "") for r in recommendations[1:3] if r.get("name"))
+        if others:
+            text += phrases["others"].format(others=others)
+    return text
+
+
+def phrase(name: str, language: str = "en") -> str:
+    """A fixed line in language: "refusal" for a refused query, "neutral"
+    for results whose description can't be spoken."""
+    return _PHRASES.get(language, _PHRASES["en"])[name]
+
+
+def _price(amount: float, language: str) -> str:
+    if language == "en":
+        return f"${amount:.2f}"
+    # German and Spanish write 1.234,50 $
+    digits = f"{amount:,.2f}".replace(",", " ").replace(".", ",").replace(" ", ".")
+    return f"{digits} $"
@@ -0,0 +1,80 @@
+import asyncio
+import json
+from typing import Any, Dict, Optional, Tuple
+
+from starlette.websockets import WebSocket
+
+from app.services.telephony import pcm16_to_wav
+
+
+class SlowClientError(Exception):
+    """The client stopped reading, so its frames have nowhere to go."""
+
+
+class FrameSender:
+    """Sends a WebSocket's frames, in order, through a bounded queue.
+
+    Senders wait while max_pending frames are queued, so a client that
+    reads slowly slows down the search feeding it instead of piling up
+    audio in memory. One that reads nothing for send_timeout seconds is
+    given up on with SlowClientError.
+    """
+
+    def __init__(self, websocket: WebSocket, max_pending: int = 32, send_timeout: float = 10.0):
+        self.websocket = websocket
+        self.send_timeout = send_timeout
+        self._queue: "asyncio.Queue[Tuple[str, Any]]" = asyncio.Queue(maxsize=max_pending)
+
+    async def send_json(self, message: Dict[str, Any]):
+        await self._put(("text", json.dumps(message, default=str)))
+
+    async def send_text(self, text: str):
+        await self._put(("text", text))
+
+    async def send_bytes(self, data: bytes):
+        await self._put(("bytes", data))
+
+    async def _put(self, frame: Tuple[str, Any]):
+        try:
+            await asyncio.wait_for(self._queue.put(frame), timeout=self.send_timeout)
+        except asyncio.TimeoutError:
+            raise SlowClientError(f"client read nothing for {self.send_timeout}s")
+
+    async def run(self):
+        """Writes queued frames to the socket until cancelled."""
+        while True:
+            kind, data = await self._queue.get()
+            if kind == "text":
+                await self.websocket.send_text(data)
+            else:
+                await self.websocket.send_bytes(data)
+
+
+class AudioBuffer:
+    """A browser's audio for one utterance: 16-bit mono PCM frames, up to
+    max_seconds of them."""
+
+    def __init__(self, sample_rate: int, max_seconds: float = 30.0):
+        self.sample_rate = sample_rate
+        self.max_bytes = int(sample_rate * 2 * max_seconds)
+        self._data = bytearray()
+
+    def __len__(self) -> int:
+        return len(self._data)
+
+    def add(self, data: bytes) -> bool:
+        """Appends a frame; False, keeping nothing, if it makes the
+        utterance too long."""
+        if len(self._data) + len(data) > self.max_bytes:
+            self._data.clear()
+            return False
+        self._data.extend(data)
+        return True
+
+    def take(self) -> Optional[bytes]:
+        """The utterance as WAV, or None if nothing was said; starts the next."""
+        if not self._data:
+            return None
+        wav = pcm16_to_wav(bytes(self._data), self.sample_rate)
+        self._data.clear()
+        return wav
@@ -0,0 +1,51 @@
+import hashlib
+import logging
+import os
+
+from opentelemetry import trace
+from opentelemetry.instrumentation.fastapi import FastAPIInstrumentor
+from opentelemetry.instrumentation.grpc import GrpcInstrumentorClient
+from opentelemetry.sdk.resources import Resource
+from opentelemetry.sdk.trace import TracerProvider
+from opentelemetry.sdk.trace.export import BatchSpanProcessor
+
+logger = logging.getLogger(__name__)
+
+# Span attribute keys, shared with graph-service
+SESSION_ID = "session.id"
+QUERY_HASH = "query.text_hash"
+RESULT_COUNT = "result.count"
+
+tracer = trace.get_tracer("orchestrator")
+
+
+def setup_tracing(app, service_name: str = "orchestrator"):
+    """Instruments FastAPI and outgoing gRPC calls.
+
+    Trace context is always propagated to graph-service; spans are only
+    exported when OTEL_EXPORTER_OTLP_ENDPOINT is set.
+    """
+    endpoint = os.getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+    if endpoint:
+        from opentelemetry.exporter.otlp.proto.grpc.trace_exporter import OTLPSpanExporter
+
+        provider = TracerProvider(resource=Resource.create({"service.name": service_name}))
+        provider.add_span_processor(BatchSpanProcessor(OTLPSpanExporter(endpoint=endpoint, insecure=True)))
+        trace.set_tracer_provider(provider)
+        logger.info(f"Exporting traces to {endpoint}")
+
+    FastAPIInstrumentor.instrument_app(app)
+    GrpcInstrumentorClient().instrument()
+
+
+def hash_text(text: str) -> str:
+    """Short stable hash of query text; matches graph-service's HashText."""
+    return hashlib.sha256(text.encode("utf-8")).hexdigest()[:16]
+
+
+def annotate(attributes: dict):
+    """Adds attributes to the current span, skipping empty values."""
+    span = trace.get_current_span()
+    for key, value in attributes.items():
+        if value is not None and value != "":
+            span.set_attribute(key, value)
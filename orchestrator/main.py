@@ -1,18 +1,47 @@
-from fastapi import FastAPI, HTTPException, Depends
+from fastapi import FastAPI, HTTPException, Depends, Header, Request, WebSocket, WebSocketDisconnect
 from fastapi.middleware.cors import CORSMiddleware
-from typing import List, Dict, Any
+from fastapi.responses import PlainTextResponse, Response, StreamingResponse
+from typing import List, Dict, Any, AsyncIterator, Optional
 import logging
 import os
+import re
 import asyncio
+import base64
+import json
+from urllib.parse import parse_qsl
 
 from app.models.schemas import (
     ProductQueryRequest, ProductQueryResponse,
-    RecommendationResult, HealthResponse
+    RecommendationResult, HealthResponse, SearchRefinement,
+    PromptRenderRequest, PromptRenderResponse, RenderedPromptResult,
+    VoiceAction, ConfirmActionRequest, ActionResponse, CartItem, VoicePersona, SearchStreamEvent,
+    VoiceGatewayStart
 )
 from app.clients.semantic_client import SemanticEngineClient
-from app.clients.graph_client import GraphServiceClient
+from app.clients.graph_client import GraphServiceClient, Deadline
+from app.services.analytics import FunnelTracker
+from app.services.dialog import DialogManager
+from app.services.fallback_monitor import FallbackMonitor
+from app.services.interruptions import Interruptions
+from app.services.language import resolve_language
 from app.services.llm_service import LLMService
+from app.services.personas import PersonaError, PersonaStore
+from app.services.phonetic import CatalogVocabulary
+from app.services.prompt_store import PromptError, PromptStore
 from app.services.recommendation_service import RecommendationService
+from app.services.safety import SafetyFilter, load_provider
+from app.services.session_cache import SessionCache
+from app.services.shadow import ShadowSearch
+from app.services.search_pipeline import SearchPipeline
+from app.services.stt import STTClient
+from app.services.telephony import (
+    AUDIO_FORMAT, PhoneCall, PhoneCommand, TwilioMediaStream, UtteranceDetector, parse_command, sign_parameters,
+    twiml_stream, valid_twilio_signature
+)
+from app.services.transcripts import TranscriptRecorder, action_entries, search_entries
+from app.services.tts import TTSClient
+from app.services.voice_gateway import AudioBuffer, FrameSender, SlowClientError
+from app.tracing import setup_tracing
 
 logging.basicConfig(level=logging.INFO)
 logger = logging.getLogger(__name__)
@@ -31,9 +60,139 @@ app.add_middleware(
     allow_headers=["*"],
 )
 
+setup_tracing(app)
+
 SEMANTIC_ENGINE_URL = os.getenv("SEMANTIC_ENGINE_URL", "http://localhost:8000")
 GRAPH_SERVICE_TARGET = os.getenv("GRAPH_SERVICE_TARGET", "localhost:50051")
-GOOGLE_API_KEY = os.getenv("GOOGLE_API_KEY")
+GRAPH_REQUEST_BUDGET = float(os.getenv("GRAPH_REQUEST_BUDGET", "5.0"))
+SESSION_CACHE_TTL = float(os.getenv("SESSION_CACHE_TTL", "600"))
+OLLAMA_URL = os.getenv("OLLAMA_URL", "http://localhost:11434")
+OLLAMA_MODEL = os.getenv("OLLAMA_MODEL", "llama3.2")
+PROMPTS_DIR = os.getenv("PROMPTS_DIR", os.path.join(os.path.dirname(os.path.abspath(__file__)), "prompts"))
+PROMPTS_RELOAD_INTERVAL = float(os.getenv("PROMPTS_RELOAD_INTERVAL", "5"))
+LLM_TIMEOUT = float(os.getenv("LLM_TIMEOUT", "10"))
+TTS_URL = os.getenv("TTS_URL")
+TTS_VOICE = os.getenv("TTS_VOICE")
+# Voices by language, "de=de-DE-KatjaNeural,es=es-ES-ElviraNeural"
+TTS_VOICES = dict(
+    pair.strip().split("=", 1) for pair in os.getenv("TTS_VOICES", "").split(",") if "=" in pair
+)
+VOICE_PERSONAS_FILE = os.getenv("VOICE_PERSONAS_FILE")
+STREAM_FIRST_BATCH = int(os.getenv("STREAM_FIRST_BATCH", "5"))
+VOCABULARY_REFRESH_INTERVAL = float(os.getenv("VOCABULARY_REFRESH_INTERVAL", "600"))
+VOCABULARY_MAX_PRODUCTS = int(os.getenv("VOCABULARY_MAX_PRODUCTS", "10000"))
+ACTION_CONFIRM_TIMEOUT = float(os.getenv("ACTION_CONFIRM_TIMEOUT", "30"))
+ACTION_UNDO_WINDOW = float(os.getenv("ACTION_UNDO_WINDOW", "300"))
+RECORD_TRANSCRIPTS = os.getenv("RECORD_TRANSCRIPTS", "true").lower() == "true"
+FUNNEL_REPORT_DIR = os.getenv("FUNNEL_REPORT_DIR")
+SAFETY_WORDLIST_FILE = os.getenv(
+    "SAFETY_WORDLIST_FILE", os.path.join(os.path.dirname(os.path.abspath(__file__)), "safety", "wordlists.json")
+)
+# "http" for SAFETY_MODERATION_URL, or "package.module:Class"
+SAFETY_MODERATION_PROVIDER = os.getenv("SAFETY_MODERATION_PROVIDER")
+SAFETY_MODERATION_URL = os.getenv("SAFETY_MODERATION_URL")
+SAFETY_MODERATION_TIMEOUT = float(os.getenv("SAFETY_MODERATION_TIMEOUT", "2.0"))
+SAFETY_FAIL_CLOSED = os.getenv("SAFETY_FAIL_CLOSED", "false").lower() == "true"
+STT_URL = os.getenv("STT_URL")
+# Share of searches, in percent, also run on the structured search path
+# and compared with the Cypher path's results, and where comparisons go
+SEARCH_SHADOW_PERCENT = float(os.getenv("SEARCH_SHADOW_PERCENT", "0"))
+SEARCH_SHADOW_LOG = os.getenv("SEARCH_SHADOW_LOG")
+SEARCH_SHADOW_MIN_OVERLAP = float(os.getenv("SEARCH_SHADOW_MIN_OVERLAP", "0.5"))
+# Base URL Twilio reaches this service at, e.g. "https://shop.example.com";
+# the request's own host when not set
+TELEPHONY_PUBLIC_URL = os.getenv("TELEPHONY_PUBLIC_URL")
+# The Twilio account's auth token: Twilio's requests are checked against
+# it, and the call parameters handed to media streams are signed with it
+TWILIO_AUTH_TOKEN = os.getenv("TWILIO_AUTH_TOKEN")
+TELEPHONY_SPEECH_THRESHOLD = float(os.getenv("TELEPHONY_SPEECH_THRESHOLD", "500"))
+TELEPHONY_SILENCE_MS = int(os.getenv("TELEPHONY_SILENCE_MS", "700"))
+# Frames queued for a voice gateway client before its search waits, and
+# how long it may read nothing before it's disconnected
+VOICE_WS_MAX_PENDING = int(os.getenv("VOICE_WS_MAX_PENDING", "32"))
+VOICE_WS_SEND_TIMEOUT = float(os.getenv("VOICE_WS_SEND_TIMEOUT", "10"))
+VOICE_WS_MAX_UTTERANCE = float(os.getenv("VOICE_WS_MAX_UTTERANCE", "30"))
+
+# Last search per voice session, shared across requests
+session_cache = SessionCache(ttl=SESSION_CACHE_TTL)
+
+# Prompt templates, reloaded when their files change
+prompt_store = PromptStore(PROMPTS_DIR, reload_interval=PROMPTS_RELOAD_INTERVAL)
+
+# Voices streamed searches speak in, per tenant
+personas = PersonaStore(
+    VOICE_PERSONAS_FILE, default_voice=TTS_VOICE, default_voices=TTS_VOICES, reload_interval=PROMPTS_RELOAD_INTERVAL
+)
+
+# Screens queries, LLM output and speech
+safety = SafetyFilter(
+    SAFETY_WORDLIST_FILE or None,
+    provider=load_provider(SAFETY_MODERATION_PROVIDER, SAFETY_MODERATION_URL, SAFETY_MODERATION_TIMEOUT),
+    fail_closed=SAFETY_FAIL_CLOSED,
+    reload_interval=PROMPTS_RELOAD_INTERVAL,
+)
+
+# Brand and product-name vocabulary for respelling spoken queries
+vocabulary = CatalogVocabulary()
+
+# In-flight streamed search per voice session, for barge-in
+interruptions = Interruptions()
+
+# Voice cart per session, with confirmation and undo for its actions
+dialogs = DialogManager(confirm_timeout=ACTION_CONFIRM_TIMEOUT, undo_window=ACTION_UNDO_WINDOW)
+
+# Voice-session transcripts, appended to the graph service in the background
+transcripts = TranscriptRecorder(target=GRAPH_SERVICE_TARGET)
+
+# Voice conversion funnel and intents, for /metrics and daily reports
+funnel = FunnelTracker(report_dir=FUNNEL_REPORT_DIR)
+
+# Alerts when searches keep falling back to the keyword path
+fallback_monitor = FallbackMonitor(
+    window=float(os.getenv("FALLBACK_ALERT_WINDOW", "300")),
+    threshold=float(os.getenv("FALLBACK_ALERT_RATE", "0.5")),
+    min_searches=int(os.getenv("FALLBACK_ALERT_MIN_SEARCHES", "10")),
+    webhook_url=os.getenv("FALLBACK_ALERT_WEBHOOK"),
+)
+
+# Compares the structured search path with the Cypher path on live traffic
+shadow = ShadowSearch(
+    GRAPH_SERVICE_TARGET,
+    percent=SEARCH_SHADOW_PERCENT,
+    log_path=SEARCH_SHADOW_LOG,
+    min_overlap=SEARCH_SHADOW_MIN_OVERLAP,
+)
+
+
+def record_transcript(session_id: Optional[str], entries: List[Dict[str, Any]], **kwargs):
+    if RECORD_TRANSCRIPTS:
+        transcripts.record(session_id, entries, **kwargs)
+
+
+def record_search(
+    request: ProductQueryRequest,
+    response: Optional[ProductQueryResponse],
+    speech: Optional[str] = None,
+    failure: Optional[str] = None
+):
+    funnel.search(request, response)
+    record_transcript(
+        request.session_id, search_entries(request, response, speech, failure), tenant_id=request.tenant_id
+    )
+
+
+def record_action(
+    session_id: str,
+    step: str,
+    response: ActionResponse,
+    action: Optional[VoiceAction] = None,
+    tenant_id: Optional[str] = None,
+    customer_id: Optional[str] = None
+):
+    funnel.action(session_id, step, response, action, tenant_id=tenant_id)
+    record_transcript(
+        session_id, action_entries(step, response, action), tenant_id=tenant_id, customer_id=customer_id
+    )
 
 
 def get_semantic_client():
@@ -41,13 +200,60 @@ def get_semantic_client():
 
 
 def get_graph_client():
-    client = GraphServiceClient(target=GRAPH_SERVICE_TARGET)
+    return connect_graph_client()
+
+
+def connect_graph_client(api_key: Optional[str] = None, tenant_id: Optional[str] = None, env_key: bool = True):
+    # All graph calls made while serving one request share its time budget
+    client = GraphServiceClient(
+        target=GRAPH_SERVICE_TARGET,
+        api_key=api_key,
+        tenant_id=tenant_id,
+        deadline=Deadline(GRAPH_REQUEST_BUDGET),
+        env_key=env_key,
+    )
     client.connect()
     return client
 
 
+def get_caller_key(x_api_key: Optional[str] = Header(default=None)) -> str:
+    """The caller's graph-service key, which owns its voice sessions and
+    runs their actions; never the service's GRAPH_API_KEY."""
+    if not x_api_key:
+        raise HTTPException(status_code=401, detail="X-API-Key is required")
+    return x_api_key
+
+
+def get_caller_graph_client(
+    api_key: str = Depends(get_caller_key),
+    x_tenant_id: Optional[str] = Header(default=None),
+):
+    return connect_graph_client(api_key, x_tenant_id, env_key=False)
+
+
+def claim_session(session_id: str, api_key: str):
+    """Refuses callers other than the one whose key first used the session."""
+    if not dialogs.claim(session_id, api_key):
+        raise HTTPException(status_code=403, detail="Session belongs to another caller")
+
+
 def get_llm_service():
-    return LLMService(api_key=GOOGLE_API_KEY)
+    return LLMService(prompts=prompt_store, base_url=OLLAMA_URL, model=OLLAMA_MODEL, timeout=LLM_TIMEOUT)
+
+
+def get_tts_client():
+    # Streamed searches only send speech text when no TTS service is set
+    if not TTS_URL:
+        return None
+    return TTSClient(url=TTS_URL, voice=TTS_VOICE)
+
+
+def get_pipeline(api_key: Optional[str] = None, tenant_id: Optional[str] = None, env_key: bool = True):
+    return SearchPipeline(
+        get_semantic_client(), connect_graph_client(api_key, tenant_id, env_key), get_llm_service(),
+        get_recommendation_service(), session_cache, fallback_monitor, vocabulary=vocabulary, safety=safety,
+        shadow=shadow
+    )
 
 
 def get_recommendation_service():
@@ -58,6 +264,53 @@ def get_recommendation_service():
     )
 
 
+async def refresh_vocabulary():
+    """Reloads the phonetic vocabulary from the catalog in the background.
+
+    A failed load keeps the previous vocabulary; until the first load
+    succeeds, queries are searched as heard.
+    """
+    cypher_query = f"MATCH (node:Product) RETURN node LIMIT {VOCABULARY_MAX_PRODUCTS}"
+    while True:
+        client = GraphServiceClient(target=GRAPH_SERVICE_TARGET)
+        try:
+            client.connect()
+            products = await asyncio.to_thread(client.search_products, cypher_query)
+            if products:
+                vocabulary.load(products)
+            else:
+                logger.warning("Phonetic vocabulary not refreshed: no products returned")
+        except Exception as e:
+            logger.error(f"Phonetic vocabulary refresh failed: {e}")
+        finally:
+            client.close()
+        await asyncio.sleep(VOCABULARY_REFRESH_INTERVAL)
+
+
+@app.on_event("startup")
+async def start_vocabulary_refresh():
+    asyncio.create_task(refresh_vocabulary())
+
+
+@app.on_event("startup")
+async def start_transcript_recorder():
+    if RECORD_TRANSCRIPTS:
+        asyncio.create_task(transcripts.run())
+
+
+async def close_funnel_days():
+    """Writes each day's funnel report soon after midnight UTC, even
+    without traffic to notice the day changed."""
+    while True:
+        await asyncio.sleep(300)
+        funnel.roll_over()
+
+
+@app.on_event("startup")
+async def start_funnel_reports():
+    asyncio.create_task(close_funnel_days())
+
+
 @app.get("/", tags=["Health"])
 async def root():
     return {"message": "Product Search Orchestrator", "version": "1.0.0"}
@@ -83,15 +336,32 @@ async def health_check(
     await semantic_client.close()
     graph_client.close()
     
-    status = "healthy" if (semantic_healthy and graph_healthy) else "degraded"
+    healthy = semantic_healthy and graph_healthy and not fallback_monitor.firing
+    status = "healthy" if healthy else "degraded"
     
     return HealthResponse(
         status=status,
         semantic_engine_connected=semantic_healthy,
-        graph_service_connected=graph_healthy
+        graph_service_connected=graph_healthy,
+        llm_fallback_rate=fallback_monitor.rate()
     )
 
 
+@app.get("/metrics", response_class=PlainTextResponse, tags=["Analytics"])
+async def metrics():
+    """Voice funnel, intent and search shadowing counters for Prometheus."""
+    return PlainTextResponse(funnel.prometheus() + shadow.prometheus(), media_type="text/plain; version=0.0.4")
+
+
+@app.get("/api/v1/analytics/funnel", tags=["Analytics"])
+async def funnel_report(date: Optional[str] = None):
+    """A day's voice funnel and intents (YYYY-MM-DD, UTC), today's so far by default."""
+    report = funnel.report(date)
+    if report is None:
+        raise HTTPException(status_code=404, detail=f"No funnel report for {date}")
+    return report
+
+
 @app.post("/api/v1/search", response_model=ProductQueryResponse, tags=["Search"])
 async def search_products(
     request: ProductQueryRequest,
@@ -100,56 +370,556 @@ async def search_products(
     llm_service: LLMService = Depends(get_llm_service),
     recommendation_service: RecommendationService = Depends(get_recommendation_service)
 ):
+    pipeline = SearchPipeline(
+        semantic_client, graph_client, llm_service, recommendation_service, session_cache, fallback_monitor,
+        vocabulary=vocabulary, safety=safety, shadow=shadow
+    )
+    response, failure = None, None
     try:
-        logger.info(f"Processing search query: {request.query}")
-        
-        # Step 1: Generate both queries in parallel via LLM
-        cypher_task = llm_service.generate_cypher(request.query)
-        terms_task = llm_service.generate_search_terms(request.query)
-        
-        cypher_query, search_terms = await asyncio.gather(cypher_task, terms_task)
-        logger.info(f"Generated Cypher: {cypher_query}")
-        logger.info(f"Generated search terms: {search_terms}")
-        
-        # Step 2: Execute searches (semantic is async, graph is sync)
-        semantic_results = await semantic_client.search(
-            query=search_terms,
-            limit=request.limit * 2,
-            min_score=request.min_semantic_score
-        )
-        
-        graph_results = graph_client.search_products(cypher_query)
-        
-        logger.info(f"Semantic search returned {len(semantic_results)} results")
-        logger.info(f"Graph search returned {len(graph_results)} results")
-        
-        # Step 3: Combine results with LLM scoring
-        recommendations = await recommendation_service.combine_results(
-            semantic_results=semantic_results,
-            graph_results=graph_results,
-            query=request.query,
-            llm_service=llm_service,
-            limit=request.limit
-        )
-        logger.info(f"Generated {len(recommendations)} recommendations")
-        
-        await semantic_client.close()
-        graph_client.close()
-        
-        return ProductQueryResponse(
-            query=request.query,
-            cypher_query=cypher_query,
-            search_terms=search_terms,
-            semantic_results_count=len(semantic_results),
-            graph_results_count=len(graph_results),
-            recommendations=recommendations
-        )
-        
+        response = await pipeline.search(request)
+        return response
     except Exception as e:
         logger.error(f"Search failed: {e}")
-        await semantic_client.close()
-        graph_client.close()
+        failure = str(e)
         raise HTTPException(status_code=500, detail=f"Search failed: {str(e)}")
+    finally:
+        await pipeline.close()
+        record_search(request, response, failure=failure)
+
+
+@app.post("/api/v1/search/stream", tags=["Search"])
+async def stream_search(
+    request: ProductQueryRequest,
+    semantic_client: SemanticEngineClient = Depends(get_semantic_client),
+    graph_client: GraphServiceClient = Depends(get_graph_client),
+    llm_service: LLMService = Depends(get_llm_service),
+    recommendation_service: RecommendationService = Depends(get_recommendation_service),
+    tts: Optional[TTSClient] = Depends(get_tts_client)
+):
+    """Search for voice clients, streamed as newline-delimited SearchStreamEvents."""
+    try:
+        persona = resolve_persona(request)
+    except PersonaError as e:
+        if tts is not None:
+            await tts.close()
+        raise HTTPException(status_code=400, detail=str(e))
+
+    pipeline = SearchPipeline(
+        semantic_client, graph_client, llm_service, recommendation_service, session_cache, fallback_monitor,
+        vocabulary=vocabulary, safety=safety, shadow=shadow
+    )
+    
+    async def lines():
+        try:
+            async for event in search_events(request, pipeline, tts, persona):
+                yield event.model_dump_json(exclude_none=True) + "\n"
+        finally:
+            if tts is not None:
+                await tts.close()
+    
+    return StreamingResponse(lines(), media_type="application/x-ndjson")
+
+
+def resolve_persona(request: ProductQueryRequest) -> VoicePersona:
+    """The request's persona with its rate and pitch overrides."""
+    persona = personas.resolve(request.tenant_id, request.persona)
+    overrides = {"speaking_rate": request.speaking_rate, "pitch": request.pitch}
+    return persona.model_copy(update={k: v for k, v in overrides.items() if v is not None})
+
+
+async def search_events(
+    request: ProductQueryRequest,
+    pipeline: SearchPipeline,
+    tts: Optional[TTSClient],
+    persona: VoicePersona,
+) -> AsyncIterator[SearchStreamEvent]:
+    """Runs a streamed search as the session's current one, then closes
+    the pipeline and records the transcript."""
+    # A new search for the session interrupts the one before
+    interrupted = interruptions.begin(request.session_id) if request.session_id else None
+    response, speech, failure = None, None, None
+    try:
+        async for event in pipeline.stream(
+            request, tts=tts, first_batch=STREAM_FIRST_BATCH, interrupted=interrupted, persona=persona
+        ):
+            if event.type == "speech":
+                speech = event.text
+            elif event.type == "done":
+                response = event.response
+            elif event.type == "error":
+                failure = event.detail
+            yield event
+    finally:
+        if interrupted is not None:
+            interruptions.end(request.session_id, interrupted)
+        await pipeline.close()
+        record_search(request, response, speech, failure)
+
+
+@app.post("/api/v1/sessions/{session_id}/interrupt", tags=["Search"])
+async def interrupt_session(session_id: str):
+    """Barge-in: stops the session's streamed search and the work behind it."""
+    return {"session_id": session_id, "interrupted": interruptions.interrupt(session_id)}
+
+
+@app.get("/api/v1/personas", response_model=List[VoicePersona], tags=["Search"])
+async def list_personas(tenant_id: Optional[str] = None):
+    """The voice personas a tenant's streamed searches can use."""
+    return personas.available(tenant_id)
+
+
+@app.get("/api/v1/sessions/{session_id}/cart", response_model=List[CartItem], tags=["Voice Actions"])
+async def get_cart(session_id: str, api_key: str = Depends(get_caller_key)):
+    claim_session(session_id, api_key)
+    return dialogs.cart(session_id)
+
+
+@app.post("/api/v1/sessions/{session_id}/actions", response_model=ActionResponse, tags=["Voice Actions"])
+async def propose_action(
+    session_id: str,
+    action: VoiceAction,
+    api_key: str = Depends(get_caller_key),
+    graph_client: GraphServiceClient = Depends(get_caller_graph_client)
+):
+    """Runs a cart action, or proposes one that removes things or spends money.
+    
+    Proposed actions come back needs_confirmation and only run once confirmed.
+    Graph calls, orders included, are made with the caller's X-API-Key.
+    """
+    try:
+        claim_session(session_id, api_key)
+        response = await dialogs.propose(session_id, action, graph_client)
+    finally:
+        graph_client.close()
+    record_action(
+        session_id, "propose", response, action, tenant_id=action.tenant_id, customer_id=action.customer_id
+    )
+    return response
+
+
+@app.post(
+    "/api/v1/sessions/{session_id}/actions/{action_id}/confirm",
+    response_model=ActionResponse,
+    tags=["Voice Actions"]
+)
+async def confirm_action(
+    session_id: str,
+    action_id: str,
+    request: ConfirmActionRequest,
+    api_key: str = Depends(get_caller_key),
+    graph_client: GraphServiceClient = Depends(get_caller_graph_client)
+):
+    try:
+        claim_session(session_id, api_key)
+        response = await dialogs.confirm(session_id, action_id, request.confirm, graph_client)
+    finally:
+        graph_client.close()
+    record_action(session_id, "confirm" if request.confirm else "decline", response)
+    return response
+
+
+@app.post("/api/v1/sessions/{session_id}/undo", response_model=ActionResponse, tags=["Voice Actions"])
+async def undo_last_action(session_id: str, api_key: str = Depends(get_caller_key)):
+    claim_session(session_id, api_key)
+    response = dialogs.undo_last_action(session_id)
+    record_action(session_id, "undo", response)
+    return response
+
+
+@app.api_route("/api/v1/telephony/twilio/voice", methods=["GET", "POST"], tags=["Telephony"])
+async def twilio_voice(
+    request: Request,
+    tenant_id: Optional[str] = None,
+    locale: Optional[str] = None,
+    persona: Optional[str] = None,
+    customer_id: Optional[str] = None,
+    payment_method: Optional[str] = None
+):
+    """Twilio's voice webhook: answers a call by streaming it to the media
+    endpoint, with the call's settings from the webhook URL.
+
+    Only requests Twilio signed are answered, so the settings are the ones
+    configured on the number; they're signed for the call in the TwiML.
+    """
+    if not STT_URL or not TTS_URL or not TWILIO_AUTH_TOKEN:
+        raise HTTPException(status_code=503, detail="Phone calls need STT_URL, TTS_URL and TWILIO_AUTH_TOKEN")
+    form = {}
+    if request.method == "POST":
+        form = dict(parse_qsl((await request.body()).decode(), keep_blank_values=True))
+    signature = request.headers.get("X-Twilio-Signature", "")
+    if not valid_twilio_signature(TWILIO_AUTH_TOKEN, signature, public_url(request.url), form):
+        logger.warning("Twilio voice webhook called without a valid signature")
+        raise HTTPException(status_code=403, detail="Invalid Twilio signature")
+    call_sid = form.get("CallSid") or request.query_params.get("CallSid")
+    if not call_sid:
+        raise HTTPException(status_code=400, detail="CallSid is required")
+
+    url = re.sub(r"^http", "ws", public_url(request.url.replace(path="/api/v1/telephony/twilio/media", query="")))
+    parameters = {
+        "tenant_id": tenant_id, "locale": locale, "persona": persona,
+        "customer_id": customer_id, "payment_method": payment_method,
+    }
+    parameters = {name: value for name, value in parameters.items() if value}
+    parameters["signature"] = sign_parameters(TWILIO_AUTH_TOKEN, call_sid, parameters)
+    return Response(twiml_stream(url, parameters), media_type="application/xml")
+
+
+@app.websocket("/api/v1/telephony/twilio/media")
+async def twilio_media(websocket: WebSocket):
+    """A call's Twilio Media Stream: what the caller says is searched for,
+    or runs as a cart command, and the answer is spoken back. Twilio signs
+    the upgrade request; others are refused."""
+    signature = websocket.headers.get("X-Twilio-Signature", "")
+    if not TWILIO_AUTH_TOKEN or not valid_twilio_signature(TWILIO_AUTH_TOKEN, signature, public_url(websocket.url)):
+        logger.warning("Twilio media stream opened without a valid signature")
+        await websocket.close(code=1008)
+        return
+    await websocket.accept()
+    if not STT_URL or not TTS_URL:
+        await websocket.close(code=1011)
+        return
+    stt = STTClient(url=STT_URL)
+    detector = UtteranceDetector(threshold=TELEPHONY_SPEECH_THRESHOLD, silence_ms=TELEPHONY_SILENCE_MS)
+    try:
+        await TwilioMediaStream(websocket, stt, answer_call, TWILIO_AUTH_TOKEN, detector).run()
+    finally:
+        await stt.close()
+
+
+def public_url(url) -> str:
+    """url as Twilio addresses it, which is what it signs: on
+    TELEPHONY_PUBLIC_URL when set, in ws(s) for WebSockets."""
+    base = TELEPHONY_PUBLIC_URL or f"{url.scheme}://{url.netloc}"
+    if url.scheme in ("ws", "wss"):
+        base = re.sub(r"^http", "ws", base)
+    else:
+        base = re.sub(r"^ws", "http", base)
+    return base.rstrip("/") + url.path + (f"?{url.query}" if url.query else "")
+
+
+async def answer_call(call: PhoneCall, text: str) -> AsyncIterator[bytes]:
+    """One turn of a phone call, as audio for the call."""
+    params = call.parameters
+    request = ProductQueryRequest(
+        query=text, limit=5, session_id=call.session_id, tenant_id=params.get("tenant_id"),
+        locale=params.get("locale"), persona=params.get("persona")
+    )
+    persona = resolve_persona(request)
+    tts = TTSClient(url=TTS_URL, voice=TTS_VOICE, audio_format=AUDIO_FORMAT)
+    try:
+        command = parse_command(text, request.locale)
+        if command is not None:
+            response = await run_phone_command(call, command)
+            prompt = safety.clean_speech(response.prompt) or "Okay."
+            async for chunk in tts.synthesize(prompt, persona=persona):
+                yield chunk
+            return
+
+        async for event in search_events(request, get_pipeline(), tts, persona):
+            if event.type == "results" and event.stage == "first":
+                # What the speech reads out, so "the second one" means the same
+                call.results = [r.product_id for r in event.recommendations]
+            elif event.type == "audio":
+                yield base64.b64decode(event.audio)
+            elif event.type == "error":
+                async for chunk in tts.synthesize("Sorry, I couldn't search for that. Please try again.", persona=persona):
+                    yield chunk
+    finally:
+        await tts.close()
+
+
+async def run_phone_command(call: PhoneCall, command: PhoneCommand) -> ActionResponse:
+    """Runs a cart command said on the phone, like the voice action endpoints."""
+    params = call.parameters
+    action, step = None, command.kind
+    if command.kind == "undo":
+        response = dialogs.undo_last_action(call.session_id)
+    else:
+        graph_client = get_graph_client()
+        try:
+            if command.kind in ("confirm", "decline"):
+                response = await dialogs.confirm(
+                    call.session_id, call.pending_action or "", command.kind == "confirm", graph_client
+                )
+            else:
+                if command.kind == "add":
+                    product_id = call.results[command.index - 1] if command.index <= len(call.results) else None
+                    action = VoiceAction(type="add_to_cart", product_id=product_id, size=command.size)
+                elif command.kind == "clear":
+                    action = VoiceAction(type="clear_cart")
+                else:
+                    action = VoiceAction(
+                        type="place_order", customer_id=params.get("customer_id"),
+                        payment_method=params.get("payment_method"), tenant_id=params.get("tenant_id")
+                    )
+                step = "propose"
+                response = await dialogs.propose(call.session_id, action, graph_client)
+        finally:
+            graph_client.close()
+    call.pending_action = response.action_id if response.status == "needs_confirmation" else None
+    record_action(
+        call.session_id, step, response, action,
+        tenant_id=params.get("tenant_id"), customer_id=params.get("customer_id")
+    )
+    return response
+
+
+@app.websocket("/api/v1/voice/ws")
+async def voice_gateway(websocket: WebSocket):
+    """Voice sessions for browsers: streamed searches, voice actions and
+    barge-in, as over HTTP, with speech going both ways on one WebSocket."""
+    await websocket.accept()
+    try:
+        start = VoiceGatewayStart(**json.loads(await websocket.receive_text()))
+    except WebSocketDisconnect:
+        return
+    except Exception as e:
+        await websocket.send_json({"type": "error", "detail": f"Expected a start frame: {e}"})
+        await websocket.close(code=1008)
+        return
+    # The session acts with the client's key alone, never the service's
+    if not start.api_key:
+        await websocket.send_json({"type": "error", "detail": "api_key is required"})
+        await websocket.close(code=1008)
+        return
+
+    try:
+        checker = connect_graph_client(start.api_key, start.tenant_id, env_key=False)
+        try:
+            allowed = await asyncio.to_thread(checker.check_access)
+        finally:
+            checker.close()
+    except Exception as e:
+        logger.error(f"Voice gateway couldn't check access for session {start.session_id}: {e}")
+        await websocket.send_json({"type": "error", "detail": "Access check failed"})
+        await websocket.close(code=1011)
+        return
+    if not allowed:
+        await websocket.send_json({"type": "error", "detail": "Invalid API key"})
+        await websocket.close(code=1008)
+        return
+    if not dialogs.claim(start.session_id, start.api_key):
+        await websocket.send_json({"type": "error", "detail": "Session belongs to another caller"})
+        await websocket.close(code=1008)
+        return
+
+    sender = FrameSender(websocket, max_pending=VOICE_WS_MAX_PENDING, send_timeout=VOICE_WS_SEND_TIMEOUT)
+    sending = asyncio.create_task(sender.run())
+    session = VoiceGatewaySession(websocket, start, sender)
+    try:
+        await sender.send_json({"type": "ready", "session_id": start.session_id})
+        await session.serve()
+    except (WebSocketDisconnect, SlowClientError):
+        pass
+    finally:
+        await session.stop_turn()
+        sending.cancel()
+        await asyncio.gather(sending, return_exceptions=True)
+
+
+class VoiceGatewaySession:
+    """One browser on the voice gateway. Client frames:
+
+    query: {"type": "query", "query": ...}, with any ProductQueryRequest
+        fields; answered with SearchStreamEvents
+    audio: binary PCM frames, then {"type": "end_of_utterance"}; answered
+        with {"type": "transcript", "text"} and the search for it
+    action / confirm / undo: {"type": "action", "action": VoiceAction},
+        {"type": "confirm", "action_id", "confirm"}, {"type": "undo"};
+        answered with {"type": "action", "response": ActionResponse}
+    interrupt: {"type": "interrupt"}, barge-in
+
+    Each audio event is sent without its audio, followed by the audio as
+    a binary frame. One search runs at a time; a new one, or speaking
+    over it, interrupts it.
+    """
+
+    def __init__(self, websocket: WebSocket, start: VoiceGatewayStart, sender: FrameSender):
+        self.websocket = websocket
+        self.start = start
+        self.sender = sender
+        self.audio = AudioBuffer(start.sample_rate, VOICE_WS_MAX_UTTERANCE)
+        self.turn: Optional[asyncio.Task] = None
+
+    async def serve(self):
+        while True:
+            message = await self.websocket.receive()
+            if message["type"] == "websocket.disconnect":
+                return
+            if message.get("bytes") is not None:
+                await self.hear(message["bytes"])
+                continue
+            try:
+                await self.handle(json.loads(message.get("text") or "{}"))
+            except SlowClientError:
+                raise
+            except Exception as e:
+                await self.sender.send_json({"type": "error", "detail": str(e)})
+
+    async def hear(self, data: bytes):
+        if not len(self.audio):
+            # Speaking over an answer stops it
+            await self.stop_turn()
+        if not self.audio.add(data):
+            await self.sender.send_json({"type": "error", "detail": "Utterance too long"})
+
+    async def handle(self, frame: Dict[str, Any]):
+        kind = frame.pop("type", None)
+        if kind == "query":
+            await self.begin_turn(self.search(self.query_request(frame)))
+        elif kind == "end_of_utterance":
+            wav = self.audio.take()
+            if wav is not None:
+                await self.begin_turn(self.transcribe(wav))
+        elif kind == "interrupt":
+            await self.stop_turn()
+        elif kind in ("action", "confirm", "undo"):
+            response = await self.act(kind, frame)
+            await self.sender.send_json({"type": "action", "response": json.loads(response.model_dump_json())})
+        else:
+            raise ValueError(f"Unknown frame type {kind!r}")
+
+    def query_request(self, frame: Dict[str, Any]) -> ProductQueryRequest:
+        defaults = {
+            "locale": self.start.locale, "persona": self.start.persona,
+            "speaking_rate": self.start.speaking_rate, "pitch": self.start.pitch,
+        }
+        for name, value in defaults.items():
+            if frame.get(name) is None:
+                frame[name] = value
+        return ProductQueryRequest(**{**frame, "session_id": self.start.session_id, "tenant_id": self.start.tenant_id})
+
+    async def begin_turn(self, work):
+        await self.stop_turn()
+        self.turn = asyncio.create_task(self.run_turn(work))
+
+    async def run_turn(self, work):
+        try:
+            await work
+        except SlowClientError:
+            logger.warning(f"Disconnecting voice gateway session {self.start.session_id}: client too slow")
+            await self.websocket.close(code=1013)
+        except Exception as e:
+            logger.error(f"Voice gateway turn failed for session {self.start.session_id}: {e}")
+
+    async def stop_turn(self):
+        """Interrupts the running search, so it ends with a cancelled event;
+        anything else still running is cancelled."""
+        if self.turn is None or self.turn.done():
+            return
+        interruptions.interrupt(self.start.session_id)
+        done, _ = await asyncio.wait({self.turn}, timeout=1.0)
+        if not done:
+            self.turn.cancel()
+            await asyncio.gather(self.turn, return_exceptions=True)
+
+    async def transcribe(self, wav: bytes):
+        if not STT_URL:
+            await self.sender.send_json({"type": "error", "detail": "Speech input needs STT_URL"})
+            return
+        stt = STTClient(url=STT_URL)
+        try:
+            text = await stt.transcribe(wav, language=self.start.locale)
+        except Exception as e:
+            logger.error(f"Transcription failed for session {self.start.session_id}: {e}")
+            await self.sender.send_json({"type": "error", "detail": "Transcription failed"})
+            return
+        finally:
+            await stt.close()
+        await self.sender.send_json({"type": "transcript", "text": text})
+        if text:
+            await self.search(self.query_request({"query": text}))
+
+    async def search(self, request: ProductQueryRequest):
+        try:
+            persona = resolve_persona(request)
+        except PersonaError as e:
+            await self.sender.send_json({"type": "error", "detail": str(e)})
+            return
+        tts = get_tts_client()
+        pipeline = get_pipeline(self.start.api_key, self.start.tenant_id, env_key=False)
+        try:
+            async for event in search_events(request, pipeline, tts, persona):
+                if event.type == "audio":
+                    audio = base64.b64decode(event.audio)
+                    await self.sender.send_text(event.model_copy(update={"audio": None}).model_dump_json(exclude_none=True))
+                    await self.sender.send_bytes(audio)
+                else:
+                    await self.sender.send_text(event.model_dump_json(exclude_none=True))
+        finally:
+            if tts is not None:
+                await tts.close()
+
+    async def act(self, kind: str, frame: Dict[str, Any]) -> ActionResponse:
+        session_id, action = self.start.session_id, None
+        if kind == "undo":
+            response = dialogs.undo_last_action(session_id)
+        else:
+            graph_client = connect_graph_client(self.start.api_key, self.start.tenant_id, env_key=False)
+            try:
+                if kind == "action":
+                    action = VoiceAction(**{"tenant_id": self.start.tenant_id, **frame.get("action", {})})
+                    response = await dialogs.propose(session_id, action, graph_client)
+                else:
+                    request = ConfirmActionRequest(confirm=frame.get("confirm", True))
+                    response = await dialogs.confirm(session_id, frame.get("action_id", ""), request.confirm, graph_client)
+                    kind = "confirm" if request.confirm else "decline"
+            finally:
+                graph_client.close()
+        step = "propose" if kind == "action" else kind
+        record_action(
+            session_id, step, response, action,
+            tenant_id=self.start.tenant_id, customer_id=action.customer_id if action else None
+        )
+        return response
+
+
+@app.post("/api/v1/prompts/render", response_model=PromptRenderResponse, tags=["Prompts"])
+async def render_prompts(
+    request: PromptRenderRequest,
+    llm_service: LLMService = Depends(get_llm_service)
+):
+    """Dry run: the prompts a search would send, without calling the LLM."""
+    llm_service.tenant_id = request.tenant_id
+    llm_service.experiment = request.experiment
+    llm_service.language, _ = resolve_language(request.query, request.locale)
+    try:
+        rendered = [
+            llm_service.render_prompt("cypher", query=request.query),
+            llm_service.render_prompt("search_terms", query=request.query),
+        ]
+        if request.product is not None:
+            rendered.append(llm_service.render_prompt(
+                "relevance", query=request.query, product=llm_service.product_summary(request.product)
+            ))
+    except PromptError as e:
+        raise HTTPException(status_code=400, detail=str(e))
+    finally:
+        await llm_service.close()
+    
+    return PromptRenderResponse(prompts=[
+        RenderedPromptResult(name=p.name, source=p.source, text=p.text) for p in rendered
+    ])
+
+
+@app.get("/api/v1/products/{product_id}", tags=["Products"])
+async def get_product(
+    product_id: str,
+    session_id: Optional[str] = None,
+    tenant_id: Optional[str] = None,
+    locale: Optional[str] = None,
+    graph_client: GraphServiceClient = Depends(get_graph_client)
+):
+    """A product's details, for when a voice user asks about one of the results."""
+    try:
+        product = await asyncio.to_thread(graph_client.get_product, product_id, locale or "")
+    finally:
+        graph_client.close()
+    if product is None:
+        raise HTTPException(status_code=404, detail="Product not found")
+    funnel.intent("product_detail", session_id, tenant_id)
+    funnel.reached("product_detail", session_id, tenant_id)
+    return product
 
 
 @app.post("/api/v1/products", tags=["Products"])
@@ -0,0 +1,126 @@
+#!/usr/bin/env python3
+"""
+Replays a recorded voice session against the current search pipeline.
+
+Every utterance of the session is searched again, in order and under one
+new session id, so refinements hit the session cache as they did live.
+The products found now are compared with the ones shown then:
+    cd orchestrator && python3 replay_transcript.py call-42
+    python3 replay_transcript.py call-42 --orchestrator http://localhost:6969
+
+Reads the transcript with GetSessionTranscript, which needs an admin
+GRAPH_API_KEY. Cart and order actions are listed but never re-run. Exits 1
+when any search's results changed.
+"""
+
+import argparse
+import json
+import os
+import sys
+import uuid
+from typing import Any, Dict, List, Optional
+
+import grpc
+import httpx
+
+from app.clients.graph_client import GraphServiceClient
+
+
+def _data(entry: Dict[str, Any]) -> Dict[str, Any]:
+    return json.loads(entry["data"]) if entry["data"] else {}
+
+
+def _turns(entries: List[Dict[str, Any]]) -> List[Dict[str, Any]]:
+    """Groups entries by the utterance they followed."""
+    turns: List[Dict[str, Any]] = []
+    for entry in entries:
+        if entry["kind"] == "utterance":
+            turns.append({"utterance": entry, "query": None, "results": None, "actions": []})
+        elif entry["kind"] == "action":
+            if not turns:
+                turns.append({"utterance": None, "query": None, "results": None, "actions": []})
+            turns[-1]["actions"].append(entry)
+        elif entry["kind"] in ("query", "results") and turns:
+            turns[-1][entry["kind"]] = entry
+    return turns
+
+
+def _compare(then: List[str], now: List[str]) -> str:
+    if then == now:
+        return "same"
+    if sorted(then) == sorted(now):
+        return "reordered"
+    return "changed"
+
+
+def replay(transcript: Dict[str, Any], orchestrator: str) -> int:
+    """Prints how each search differs now; returns how many changed."""
+    session_id = f"replay-{transcript['session_id']}-{uuid.uuid4().hex[:8]}"
+    changed = 0
+    with httpx.Client(base_url=orchestrator, timeout=60.0) as client:
+        for turn in _turns(transcript["entries"]):
+            for action in turn["actions"]:
+                data = _data(action)
+                kind = data.get("action", {}).get("type", "")
+                print(f"  action {data.get('step')} {kind}: {data.get('status')} (not re-run)")
+            utterance = turn["utterance"]
+            if utterance is None:
+                continue
+
+            print(f"#{utterance['seq']} {utterance['text']!r}")
+            request = dict(_data(utterance), query=utterance["text"], session_id=session_id)
+            recorded = _data(turn["results"]) if turn["results"] else {}
+            then = [r["product_id"] for r in recorded.get("results", [])]
+            response = client.post("/api/v1/search", json=request)
+            if response.status_code != 200:
+                print(f"  failed now: {response.status_code} {response.text}")
+                if "failed" not in recorded:
+                    changed += 1
+                continue
+            result = response.json()
+            now = [r["product_id"] for r in result["recommendations"]]
+
+            if "failed" in recorded:
+                print(f"  failed then ({recorded['failed']}), {len(now)} results now")
+                continue
+            verdict = _compare(then, now)
+            print(f"  results {verdict}")
+            if verdict != "same":
+                changed += 1
+                print(f"    then: {', '.join(then) or '-'}")
+                print(f"    now:  {', '.join(now) or '-'}")
+                print(f"    added: {', '.join(p for p in now if p not in then) or '-'}; "
+                      f"dropped: {', '.join(p for p in then if p not in now) or '-'}")
+
+            query = _data(turn["query"]) if turn["query"] else {}
+            for field in ("rewritten_query", "search_path", "cypher_query", "search_terms"):
+                if query and query.get(field) != result.get(field):
+                    print(f"  {field}: {query.get(field)!r} -> {result.get(field)!r}")
+    return changed
+
+
+def main(argv: Optional[List[str]] = None) -> int:
+    parser = argparse.ArgumentParser(description=__doc__.strip().splitlines()[0])
+    parser.add_argument("session_id")
+    parser.add_argument("--orchestrator", default=os.getenv("ORCHESTRATOR_URL", "http://localhost:6969"))
+    parser.add_argument("--graph", default=os.getenv("GRAPH_SERVICE_TARGET", "localhost:50051"))
+    args = parser.parse_args(argv)
+
+    graph_client = GraphServiceClient(target=args.graph)
+    graph_client.connect()
+    try:
+        transcript = graph_client.get_session_transcript(args.session_id)
+    except grpc.RpcError as e:
+        print(f"Couldn't read the transcript: {e.details()}", file=sys.stderr)
+        return 2
+    finally:
+        graph_client.close()
+
+    print(f"Replaying {len(transcript['entries'])} entries of session {args.session_id}")
+    changed = replay(transcript, args.orchestrator)
+    print(f"{changed} search(es) changed" if changed else "No searches changed")
+    return 1 if changed else 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
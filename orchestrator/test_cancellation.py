@@ -0,0 +1,213 @@
+#!/usr/bin/env python3
+"""
+Barge-in tests for streamed searches.
+
+Runs against fake backends, so no services are needed:
+    cd orchestrator && python3 test_cancellation.py
+
+Each test interrupts a stream while one backend is busy and checks that the
+backend saw the cancellation and that no task or blocked thread outlives the
+stream.
+"""
+
+import asyncio
+import threading
+import unittest
+
+from app.models.schemas import ProductQueryRequest
+from app.services.fallback_monitor import FallbackMonitor
+from app.services.interruptions import Interruptions
+from app.services.recommendation_service import RecommendationService
+from app.services.search_pipeline import SearchPipeline
+from app.services.session_cache import SessionCache
+
+PRODUCTS = [
+    {"product_id": f"p{i}", "id": f"p{i}", "name": f"Shoe {i}", "brand": "Nike", "price": 50.0 + i, "score": 0.9 - i / 10}
+    for i in range(6)
+]
+
+
+class FakeSemanticClient:
+    async def search(self, query, limit, min_score):
+        return list(PRODUCTS)
+
+    async def close(self):
+        pass
+
+
+class FakeGraphClient:
+    """Blocks in search_products until cancelled, like a slow graph service.
+
+    search_products runs on a worker thread; started is set from there.
+    """
+
+    def __init__(self, block: bool = False):
+        self.block = block
+        self.session_id = None
+        self.loop = asyncio.get_running_loop()
+        self.started = asyncio.Event()
+        self.cancelled = threading.Event()
+        self.returned = threading.Event()
+
+    def search_products(self, cypher_query, locale=""):
+        self.loop.call_soon_threadsafe(self.started.set)
+        if self.block:
+            self.cancelled.wait(timeout=10)
+        self.returned.set()
+        return []
+
+    def cancel(self):
+        self.cancelled.set()
+
+    def close(self):
+        pass
+
+
+class FakeLLMService:
+    """Hangs in generate_cypher when slow, like an unresponsive LLM."""
+
+    def __init__(self, slow: bool = False):
+        self.slow = slow
+        self.tenant_id = None
+        self.experiment = None
+        self.started = asyncio.Event()
+        self.cancelled = False
+
+    async def generate_cypher(self, query):
+        self.started.set()
+        if self.slow:
+            try:
+                await asyncio.sleep(60)
+            except asyncio.CancelledError:
+                self.cancelled = True
+                raise
+        return "MATCH (node:Product) RETURN node LIMIT 10"
+
+    async def generate_search_terms(self, query):
+        return query
+
+    async def score_relevance(self, product, query):
+        return 0.5
+
+    async def close(self):
+        pass
+
+
+class FakeTTSClient:
+    """Sends one chunk, then stalls mid-sentence."""
+
+    audio_format = "audio/wav"
+
+    def __init__(self):
+        self.started = asyncio.Event()
+        self.cancelled = False
+
+    async def synthesize(self, text, persona=None, ssml=None, language=None):
+        yield b"RIFF"
+        self.started.set()
+        try:
+            await asyncio.sleep(60)
+        except asyncio.CancelledError:
+            self.cancelled = True
+            raise
+        yield b"more"
+
+
+class CancellationTest(unittest.IsolatedAsyncioTestCase):
+    def pipeline(self, graph=None, llm=None):
+        return SearchPipeline(
+            FakeSemanticClient(),
+            graph or FakeGraphClient(),
+            llm or FakeLLMService(),
+            RecommendationService(),
+            SessionCache(),
+            FallbackMonitor(),
+        )
+
+    async def assert_no_leaks(self):
+        # Let cancelled tasks wind down
+        for _ in range(50):
+            if not self.other_tasks():
+                break
+            await asyncio.sleep(0.02)
+        self.assertEqual(self.other_tasks(), [])
+
+    def other_tasks(self):
+        return [t for t in asyncio.all_tasks() if t is not asyncio.current_task()]
+
+    async def collect(self, stream, until):
+        """Reads the stream in the background until the until event is set."""
+        events = []
+
+        async def read():
+            async for event in stream:
+                events.append(event)
+
+        reader = asyncio.create_task(read())
+        await asyncio.wait_for(until.wait(), timeout=5)
+        return reader, events
+
+    async def test_interrupt_cancels_llm_request(self):
+        llm = FakeLLMService(slow=True)
+        interrupted = asyncio.Event()
+        stream = self.pipeline(llm=llm).stream(
+            ProductQueryRequest(query="red shoes", session_id="s1"), interrupted=interrupted
+        )
+
+        reader, events = await self.collect(stream, llm.started)
+        interrupted.set()
+        await asyncio.wait_for(reader, timeout=5)
+
+        self.assertTrue(llm.cancelled)
+        self.assertEqual([e.type for e in events], ["cancelled"])
+        await self.assert_no_leaks()
+
+    async def test_interrupt_cancels_graph_call(self):
+        graph = FakeGraphClient(block=True)
+        interrupted = asyncio.Event()
+        stream = self.pipeline(graph=graph).stream(
+            ProductQueryRequest(query="red shoes", session_id="s1"), interrupted=interrupted
+        )
+
+        reader, events = await self.collect(stream, graph.started)
+        interrupted.set()
+        await asyncio.wait_for(reader, timeout=5)
+
+        self.assertTrue(graph.cancelled.is_set())
+        # The worker thread isn't left blocked on the graph service
+        self.assertTrue(await asyncio.to_thread(graph.returned.wait, 5))
+        self.assertEqual(events[-1].type, "cancelled")
+        await self.assert_no_leaks()
+
+    async def test_closing_stream_cancels_speech(self):
+        tts = FakeTTSClient()
+        stream = self.pipeline().stream(ProductQueryRequest(query="red shoes"), tts=tts, first_batch=2)
+
+        seen = []
+        async for event in stream:
+            seen.append(event.type)
+            if event.type == "audio":
+                await asyncio.wait_for(tts.started.wait(), timeout=5)
+                break
+        await stream.aclose()
+
+        self.assertTrue(tts.cancelled)
+        self.assertIn("results", seen)
+        self.assertNotIn("done", seen)
+        await self.assert_no_leaks()
+
+    async def test_new_search_interrupts_previous(self):
+        interruptions = Interruptions()
+        first = interruptions.begin("s1")
+        second = interruptions.begin("s1")
+
+        self.assertTrue(first.is_set())
+        self.assertFalse(second.is_set())
+        interruptions.end("s1", first)
+        self.assertTrue(interruptions.interrupt("s1"))
+        self.assertTrue(second.is_set())
+        self.assertFalse(interruptions.interrupt("s1"))
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,103 @@
+#!/usr/bin/env python3
+"""
+Tests for reading spoken numbers, prices and sizes out of queries.
+
+Pure functions, so no services are needed:
+    cd orchestrator && python3 test_spoken_numbers.py
+"""
+
+import unittest
+
+from app.models.schemas import SearchRefinement
+from app.services.spoken_numbers import Locale, normalize, parse_locale
+
+
+class ParseLocaleTest(unittest.TestCase):
+    def test_locales(self):
+        cases = [
+            (None, Locale(decimal=".", currency="USD", size_system="US")),
+            ("", Locale(decimal=".", currency="USD", size_system="US")),
+            ("en-US", Locale(decimal=".", currency="USD", size_system="US")),
+            ("en_GB", Locale(decimal=".", currency="GBP", size_system="UK")),
+            ("en-IN", Locale(decimal=".", currency="INR", size_system="UK")),
+            ("en-IE", Locale(decimal=".", currency="EUR", size_system="EU")),
+            ("de-DE", Locale(decimal=",", currency="EUR", size_system="EU")),
+            ("de", Locale(decimal=",", currency="EUR", size_system="EU")),
+            ("de-CH", Locale(decimal=",", currency="USD", size_system="EU")),
+            ("fr-CA", Locale(decimal=",", currency="CAD", size_system="US")),
+            ("ja-JP", Locale(decimal=".", currency="USD", size_system="US")),
+        ]
+        for tag, want in cases:
+            with self.subTest(tag=tag):
+                self.assertEqual(parse_locale(tag), want)
+
+
+class NormalizeTest(unittest.TestCase):
+    def test_text(self):
+        cases = [
+            # (query, locale, normalized text)
+            ("running shoes", None, "running shoes"),
+            ("twenty five", None, "25"),
+            ("one hundred and twenty", None, "120"),
+            ("a hundred", None, "100"),
+            ("two thousand three hundred", None, "2300"),
+            ("three point five", None, "3.5"),
+            ("ten and a half", None, "10.5"),
+            ("fifty and eighty", None, "50 and 80"),
+            ("ten eleven", None, "10 11"),
+            ("the blue one", None, "the blue one"),
+            ("under one hundred and twenty dollars", None, "under $120"),
+            ("under twenty dollars and fifty cents", None, "under $20.50"),
+            ("under twenty pounds fifty", "en-GB", "under £20.50"),
+            ("under fifty bucks", "en-GB", "under $50"),
+            ("under fifty euros", None, "under €50"),
+            ("under €1.234,50", "de-DE", "under €1234.50"),
+            ("under $1,234.50", None, "under $1234.50"),
+            ("size ten and a half", None, "size 10.5"),
+            ("size one", None, "size 1"),
+            ("trainers size nine under forty quid", "en-GB", "trainers size 9 under £40"),
+        ]
+        for query, locale, want in cases:
+            with self.subTest(query=query, locale=locale):
+                self.assertEqual(normalize(query, locale).text, want)
+
+    def test_filters(self):
+        cases = [
+            # (query, locale, min_price, max_price, currency, size)
+            ("running shoes", None, None, None, None, None),
+            ("under one hundred dollars", None, None, 100, "USD", None),
+            ("over fifty pounds", None, 50, None, "GBP", None),
+            ("between twenty and forty dollars", None, 20, 40, "USD", None),
+            ("under fifty", "en-GB", None, 50, "GBP", None),
+            ("under fifty", "de-DE", None, 50, "EUR", None),
+            ("size ten and a half", None, None, None, None, "US 10.5"),
+            ("size nine", "en-GB", None, None, None, "UK 9"),
+            ("size forty two", "de-DE", None, None, None, "EU 42"),
+            ("uk size eight", None, None, None, None, "UK 8"),
+            ("size eu forty", None, None, None, None, "EU 40"),
+            ("trainers size nine under forty quid", "en-GB", None, 40, "GBP", "UK 9"),
+        ]
+        for query, locale, min_price, max_price, currency, size in cases:
+            with self.subTest(query=query, locale=locale):
+                filters = normalize(query, locale).filters
+                self.assertEqual(filters.min_price, min_price)
+                self.assertEqual(filters.max_price, max_price)
+                self.assertEqual(filters.currency, currency)
+                self.assertEqual(filters.size, size)
+
+    def test_refine_keeps_explicit_filters(self):
+        spoken = normalize("size ten under eighty dollars")
+        cases = [
+            (SearchRefinement(), {"max_price": 80, "size": "US 10"}),
+            (SearchRefinement(max_price=60), {"max_price": 60, "size": "US 10"}),
+            (SearchRefinement(size="US 9"), {"max_price": 80, "size": "US 9"}),
+        ]
+        for refinement, want in cases:
+            with self.subTest(refinement=refinement):
+                refined = spoken.refine(refinement)
+                self.assertEqual({"max_price": refined.max_price, "size": refined.size}, want)
+                self.assertIsNone(refined.min_price)
+
+
+if __name__ == "__main__":
+    unittest.main()